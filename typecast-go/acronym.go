@@ -0,0 +1,208 @@
+package typecast
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// AcronymMode decides how an acronym is read.
+type AcronymMode string
+
+const (
+	// AcronymAsIs sends the acronym as written, leaving it to the voice.
+	AcronymAsIs AcronymMode = ""
+	// AcronymSpell spells the acronym letter by letter: "API" becomes
+	// "A P I".
+	AcronymSpell AcronymMode = "spell"
+	// AcronymWord reads the acronym as a word: "NASA" becomes "Nasa".
+	AcronymWord AcronymMode = "word"
+	// AcronymExpand replaces the acronym with its phrase in
+	// AcronymRules.Expansions.
+	AcronymExpand AcronymMode = "expand"
+)
+
+// romanNumeral matches the small Roman numerals of titles and lists, such
+// as "II" or "XIV", which are not acronyms.
+var romanNumeral = regexp.MustCompile(`^X{0,3}(?:IX|IV|V?I{0,3})$`)
+
+// AcronymRules decides how acronyms, words of two or more capital letters
+// such as "API", "KPIs", or "MP3", are read. Runs of capitalized words,
+// such as "WARNING: DO NOT ENTER", and Roman numerals such as "II" are left as
+// written unless an override or expansion names them. The maps must not
+// be modified while the rules are in use.
+type AcronymRules struct {
+	// Default is the mode of acronyms without an override or expansion
+	// (optional, defaults to AcronymAsIs)
+	Default AcronymMode
+	// Overrides maps acronyms to the mode they are read in (optional)
+	Overrides map[string]AcronymMode
+	// Expansions maps acronyms to the phrase read in their place, such as
+	// "KPI": "key performance indicator". Acronyms with an expansion are
+	// expanded unless Overrides names another mode (optional)
+	Expansions map[string]string
+}
+
+// Apply rewrites the acronyms in text according to the rules. A plural
+// acronym, such as "KPIs", is read like its singular with an "s" added.
+func (r *AcronymRules) Apply(text string) string {
+	words := latinWords(text)
+	if len(words) == 0 {
+		return text
+	}
+	shouted := shoutedWords(text, words)
+	var b strings.Builder
+	last := 0
+	for i, w := range words {
+		acronym, plural := acronymOf(text[w[0]:w[1]])
+		if acronym == "" {
+			continue
+		}
+		mode, named := r.mode(acronym)
+		if !named && (romanNumeral.MatchString(acronym) || shouted[i]) {
+			continue
+		}
+		var spoken string
+		switch mode {
+		case AcronymSpell:
+			spoken = spellAcronym(acronym, plural)
+		case AcronymWord:
+			spoken = acronym[:1] + strings.ToLower(acronym[1:])
+			if plural {
+				spoken += "s"
+			}
+		case AcronymExpand:
+			phrase, ok := r.Expansions[acronym]
+			if !ok {
+				continue
+			}
+			spoken = phrase
+			if plural {
+				spoken += "s"
+			}
+		default:
+			continue
+		}
+		b.WriteString(text[last:w[0]])
+		b.WriteString(spoken)
+		last = w[1]
+	}
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// mode returns the mode of acronym and whether an override or expansion
+// names it.
+func (r *AcronymRules) mode(acronym string) (AcronymMode, bool) {
+	if mode, ok := r.Overrides[acronym]; ok {
+		return mode, true
+	}
+	if _, ok := r.Expansions[acronym]; ok {
+		return AcronymExpand, true
+	}
+	return r.Default, false
+}
+
+// latinWords returns the byte ranges of the runs of Latin letters and
+// digits in text.
+func latinWords(text string) [][2]int {
+	var words [][2]int
+	start := -1
+	for i, r := range text {
+		inWord := unicode.Is(unicode.Latin, r) || unicode.IsDigit(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			words = append(words, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, [2]int{start, len(text)})
+	}
+	return words
+}
+
+// acronymOf returns word without a plural "s" when it is an acronym: at
+// least two capital ASCII letters, with digits allowed. It returns "" for
+// other words.
+func acronymOf(word string) (string, bool) {
+	plural := strings.HasSuffix(word, "s")
+	if plural {
+		word = word[:len(word)-1]
+	}
+	letters := 0
+	for _, r := range word {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			letters++
+		case r < '0' || r > '9':
+			return "", false
+		}
+	}
+	if letters < 2 {
+		return "", false
+	}
+	return word, plural
+}
+
+// shoutedWords marks the words of shouted text, such as "DO NOT ENTER": runs
+// of capitalized words, separated only by spaces, that have three or more
+// words or a word longer than any acronym.
+func shoutedWords(text string, words [][2]int) []bool {
+	shouted := make([]bool, len(words))
+	for start := 0; start < len(words); {
+		end, long := start, false
+		for end < len(words) && isCapsWord(text[words[end][0]:words[end][1]]) &&
+			(end == start || strings.TrimSpace(text[words[end-1][1]:words[end][0]]) == "") {
+			long = long || words[end][1]-words[end][0] > 5
+			end++
+		}
+		if end-start >= 3 || long {
+			for i := start; i < end; i++ {
+				shouted[i] = true
+			}
+		}
+		if end == start {
+			end++
+		}
+		start = end
+	}
+	return shouted
+}
+
+// isCapsWord reports whether word has two or more letters, all capitals.
+func isCapsWord(word string) bool {
+	letters := 0
+	for _, r := range word {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters >= 2
+}
+
+// spellAcronym separates the letters of acronym with spaces, keeping runs
+// of digits together: "MP3" becomes "M P 3".
+func spellAcronym(acronym string, plural bool) string {
+	var b strings.Builder
+	prev := rune(-1)
+	for _, r := range acronym {
+		if prev >= 0 && !(unicode.IsDigit(r) && unicode.IsDigit(prev)) {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	if plural {
+		b.WriteByte('s')
+	}
+	return b.String()
+}
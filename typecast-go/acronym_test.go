@@ -0,0 +1,79 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAcronymRules(t *testing.T) {
+	rules := &AcronymRules{
+		Default: AcronymSpell,
+		Overrides: map[string]AcronymMode{
+			"NASA": AcronymWord,
+			"GIF":  AcronymWord,
+			"FAQ":  AcronymAsIs,
+			"ROI":  AcronymExpand,
+			"IV":   AcronymSpell,
+		},
+		Expansions: map[string]string{
+			"KPI": "key performance indicator",
+			"ETA": "estimated time of arrival",
+		},
+	}
+	tests := []struct{ in, want string }{
+		{"Call the API.", "Call the A P I."},
+		{"Track KPIs and the ETA.", "Track key performance indicators and the estimated time of arrival."},
+		{"NASA shares GIFs", "Nasa shares Gifs"},
+		{"Read the FAQ about MP3 and B2B APIs.", "Read the FAQ about M P 3 and B 2 B A P Is."},
+		{"The ROI is high", "The ROI is high"},
+		{"API를 호출합니다", "A P I를 호출합니다"},
+		{"World War II and chapter XIV", "World War II and chapter XIV"},
+		{"Give an IV drip", "Give an I V drip"},
+		{"WARNING: DO NOT ENTER", "WARNING: DO NOT ENTER"},
+		{"USE THE API NOW", "USE THE API NOW"},
+		{"The FBI, the CIA.", "The F B I, the C I A."},
+		{"NASA LAUNCHES", "Nasa LAUNCHES"},
+		{"AWS EC2", "A W S E C 2"},
+		{"I am OK with iPhone and PhD", "I am O K with iPhone and PhD"},
+		{"CAFÉ 2024", "CAFÉ 2024"},
+		{"no acronyms here", "no acronyms here"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := rules.Apply(tt.in); got != tt.want {
+			t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	asIs := &AcronymRules{Expansions: map[string]string{"KPI": "key performance indicator"}}
+	if got := asIs.Apply("KPI of the API"); got != "key performance indicator of the API" {
+		t.Fatalf("default mode: %q", got)
+	}
+}
+
+func TestAcronymsInRequests(t *testing.T) {
+	var text string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		text = body.Text
+		_, _ = w.Write([]byte("RIFF"))
+	}))
+	defer srv.Close()
+	lexicon, _ := NewPronunciationLexicon(map[string]string{"SQL": "sequel"})
+	c := NewClient(&ClientConfig{
+		APIKey:         "k",
+		BaseURL:        srv.URL,
+		Pronunciations: lexicon,
+		Acronyms:       &AcronymRules{Default: AcronymSpell},
+	})
+	if _, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "SQL over HTTP", Model: ModelSSFMV30}); err != nil {
+		t.Fatal(err)
+	}
+	if text != "sequel over H T T P" {
+		t.Fatalf("sent %q", text)
+	}
+}
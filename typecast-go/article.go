@@ -0,0 +1,241 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrNoArticle is returned by ExtractArticle when a page has no readable
+// text.
+var ErrNoArticle = errors.New("typecast: no article text found")
+
+// htmlInlineElements are the elements whose text runs on with the text
+// around them.
+var htmlInlineElements = tagSet("a abbr b bdi bdo cite code data dfn em font i kbd mark q s samp small " +
+	"span strong sub sup time u var")
+
+// articleSkippedElements never hold article text.
+var articleSkippedElements = tagSet("aside audio button canvas dialog figure footer form header iframe " +
+	"input menu nav noscript object pre script select style svg template textarea video")
+
+var (
+	articleSentences = tagSet("h1 h2 h3 h4 h5 h6 li dt dd th td caption")
+	boilerplateClass = regexp.MustCompile(`(?i)\b(?:ads?|advert\w*|banner|breadcrumbs?|comments?|cookies?|` +
+		`footer|masthead|menu|modal|nav\w*|newsletter|pagination|popup|promo\w*|related|share|sharing|` +
+		`sidebar|social|sponsor\w*|subscribe|widget)\b`)
+	contentClass    = regexp.MustCompile(`(?i)\b(?:article|body|content|entry|main|post|story)\b`)
+	boilerplateRole = tagSet("banner complementary contentinfo dialog navigation search")
+)
+
+// Article is the readable text extracted from an HTML page.
+type Article struct {
+	// Title is the article's headline
+	Title string
+	// Paragraphs holds the article's paragraphs, subheadings, and list
+	// items in order, each read as one or more sentences
+	Paragraphs []string
+}
+
+// Text returns the title and paragraphs separated by blank lines, ready for
+// LongFormRequest.Text.
+func (a *Article) Text() string {
+	parts := a.Paragraphs
+	if a.Title != "" {
+		parts = append([]string{asSentence(a.Title)}, parts...)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// ExtractArticle reads an HTML page and extracts its article: the
+// headline and the text of the element holding most of the page's prose.
+// Navigation, headers, footers, sidebars, forms, comments, ads, code
+// listings, and link lists are dropped.
+func ExtractArticle(r io.Reader) (*Article, error) {
+	doc, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	root := parseHTML(string(doc))
+	article := &Article{Title: articleTitle(root)}
+	collector := articleCollector{title: article.Title, skip: isBoilerplate}
+	for _, node := range articleContent(root) {
+		collector.walk(node, false)
+	}
+	collector.flush("p")
+	if len(collector.blocks) == 0 {
+		return nil, ErrNoArticle
+	}
+	article.Paragraphs = collector.blocks
+	return article, nil
+}
+
+// NarrateArticle extracts the article from an HTML page and narrates it
+// with LongFormSynthesize. The article's text replaces request.Text.
+func (c *Client) NarrateArticle(ctx context.Context, page io.Reader, request LongFormRequest) (*LongFormResult, error) {
+	article, err := ExtractArticle(page)
+	if err != nil {
+		return nil, err
+	}
+	request.Text = article.Text()
+	return c.LongFormSynthesize(ctx, request)
+}
+
+// articleTitle returns the page's og:title, its first <h1>, or its <title>
+// without a trailing " | Site name".
+func articleTitle(root *htmlNode) string {
+	if meta := root.find(func(n *htmlNode) bool {
+		return n.tag == "meta" && n.attrs["property"] == "og:title" && strings.TrimSpace(n.attrs["content"]) != ""
+	}); meta != nil {
+		return strings.Join(strings.Fields(meta.attrs["content"]), " ")
+	}
+	if h1 := root.find(func(n *htmlNode) bool { return n.tag == "h1" && n.textContent() != "" }); h1 != nil {
+		return h1.textContent()
+	}
+	if title := root.find(func(n *htmlNode) bool { return n.tag == "title" }); title != nil {
+		text := title.textContent()
+		if i := strings.LastIndex(text, " | "); i > 0 {
+			text = text[:i]
+		}
+		return text
+	}
+	return ""
+}
+
+// isBoilerplate reports whether n is an element that never holds article
+// text, by its name, role, visibility, or class and id.
+func isBoilerplate(n *htmlNode) bool {
+	if articleSkippedElements[n.tag] || boilerplateRole[n.attrs["role"]] {
+		return true
+	}
+	if _, hidden := n.attrs["hidden"]; hidden || n.attrs["aria-hidden"] == "true" {
+		return true
+	}
+	names := n.attrs["class"] + " " + n.attrs["id"]
+	return boilerplateClass.MatchString(names) && !contentClass.MatchString(names)
+}
+
+// articleContent returns the element with the best prose score, and those
+// of its siblings scoring at least a fifth as well. A paragraph of 25 or
+// more characters scores one point, one more per comma and per 100
+// characters up to three, credited to its parent and half to its
+// grandparent. Pages without such paragraphs use the whole <body>.
+func articleContent(root *htmlNode) []*htmlNode {
+	scores := map[*htmlNode]float64{}
+	var candidates []*htmlNode // in the order first credited, so ties pick the same one every run
+	credit := func(n *htmlNode, points float64) {
+		if _, ok := scores[n]; !ok {
+			candidates = append(candidates, n)
+		}
+		scores[n] += points
+	}
+	var score func(n *htmlNode)
+	score = func(n *htmlNode) {
+		for _, child := range n.children {
+			if child.tag == "" || isBoilerplate(child) {
+				continue
+			}
+			if child.tag == "p" {
+				text := child.textContent()
+				if length := utf8.RuneCountInString(text); length >= 25 && n != root {
+					points := 1 + float64(strings.Count(text, ",")) + float64(minInt(length/100, 3))
+					credit(n, points)
+					if n.parent != root {
+						credit(n.parent, points/2)
+					}
+				}
+			}
+			score(child)
+		}
+	}
+	score(root)
+	var best *htmlNode
+	for _, node := range candidates {
+		if best == nil || scores[node] > scores[best] {
+			best = node
+		}
+	}
+	if best == nil {
+		if body := root.find(func(n *htmlNode) bool { return n.tag == "body" }); body != nil {
+			return []*htmlNode{body}
+		}
+		return []*htmlNode{root}
+	}
+	var content []*htmlNode
+	for _, sibling := range best.parent.children {
+		if sibling == best || scores[sibling] >= 3 && scores[sibling] >= scores[best]/5 {
+			content = append(content, sibling)
+		}
+	}
+	return content
+}
+
+// articleCollector gathers the text blocks of article content.
+type articleCollector struct {
+	title     string               // dropped when it is the first block
+	skip      func(*htmlNode) bool // elements whose text is not read
+	blocks    []string
+	text      strings.Builder
+	linkChars int
+	tags      []string // enclosing block elements
+}
+
+func (c *articleCollector) walk(n *htmlNode, inLink bool) {
+	if n.tag == "" {
+		c.text.WriteString(n.text)
+		if inLink {
+			c.linkChars += len(strings.TrimSpace(n.text))
+		}
+		return
+	}
+	if c.skip(n) || htmlRawTextElements[n.tag] {
+		return
+	}
+	if htmlInlineElements[n.tag] || n.tag == "br" || n.tag == "img" {
+		if n.tag == "br" {
+			c.text.WriteByte(' ')
+		}
+		for _, child := range n.children {
+			c.walk(child, inLink || n.tag == "a")
+		}
+		return
+	}
+	c.flush(c.enclosing())
+	c.tags = append(c.tags, n.tag)
+	for _, child := range n.children {
+		c.walk(child, inLink)
+	}
+	c.flush(n.tag)
+	c.tags = c.tags[:len(c.tags)-1]
+	c.text.WriteByte(' ')
+}
+
+func (c *articleCollector) enclosing() string {
+	if len(c.tags) == 0 {
+		return "p"
+	}
+	return c.tags[len(c.tags)-1]
+}
+
+// flush ends the current block of text, read as a sentence when tag is a
+// heading, list item, or table cell. Blocks that are mostly link text, and
+// a repeat of the title, are dropped.
+func (c *articleCollector) flush(tag string) {
+	text := strings.Join(strings.Fields(c.text.String()), " ")
+	linkChars := c.linkChars
+	c.text.Reset()
+	c.linkChars = 0
+	if text == "" || linkChars*2 > len(text) {
+		return
+	}
+	if strings.EqualFold(text, c.title) && len(c.blocks) == 0 {
+		return
+	}
+	if articleSentences[tag] {
+		text = asSentence(text)
+	}
+	c.blocks = append(c.blocks, text)
+}
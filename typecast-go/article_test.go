@@ -0,0 +1,156 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testArticlePage = `<!DOCTYPE html>
+<html lang=en><head><title>Rain returns | Daily News</title>
+<script>if (a < b) { document.write("<p>") }</script></head>
+<body><header><nav><a href=/>Home</a> <a href=/world>World</a></nav></header>
+<div class="layout">
+<div class="sidebar-widget"><p>Sign up for our newsletter, today, for weekly, curated news.</p></div>
+<article class="post">
+<h1>Rain returns</h1>
+<p>After months of drought, rain fell across the region on Monday, bringing relief to farmers &amp; towns.
+<p>Officials said reservoirs, which had fallen to record lows, would recover <em>slowly</em>.</p>
+<h2>What comes next</h2>
+<ul><li>More rain on Tuesday<li>A dry weekend</ul>
+<figure><img src=map.jpg><figcaption>Rainfall map</figcaption></figure>
+<pre>rainfall_mm = 42</pre>
+<p><a href=/more>Read more stories</a></p>
+<div class="comments"><p>Great article, thanks, loved it, very much indeed.</p></div>
+</article></div>
+<footer>Copyright Daily News</footer></body></html>`
+
+func TestExtractArticle(t *testing.T) {
+	article, err := ExtractArticle(strings.NewReader(testArticlePage))
+	if err != nil {
+		t.Fatalf("ExtractArticle() error = %v", err)
+	}
+	want := &Article{
+		Title: "Rain returns",
+		Paragraphs: []string{
+			"After months of drought, rain fell across the region on Monday, bringing relief to farmers & towns.",
+			"Officials said reservoirs, which had fallen to record lows, would recover slowly.",
+			"What comes next.",
+			"More rain on Tuesday.",
+			"A dry weekend.",
+		},
+	}
+	if !reflect.DeepEqual(article, want) {
+		t.Fatalf("ExtractArticle() = %#v, want %#v", article, want)
+	}
+	if !strings.HasPrefix(article.Text(), "Rain returns.\n\nAfter months") {
+		t.Errorf("Text() = %q", article.Text())
+	}
+}
+
+func TestExtractArticle_Title(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want string
+	}{
+		{"og:title", `<meta property="og:title" content=" Shared  title "><h1>Headline</h1><p>Text</p>`, "Shared title"},
+		{"h1", `<title>Page</title><h1></h1><h1>Headline</h1><p>Text</p>`, "Headline"},
+		{"title", `<title>Page | Site</title><p>Text</p>`, "Page"},
+		{"none", `<p>Text</p>`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article, err := ExtractArticle(strings.NewReader(tt.page))
+			if err != nil {
+				t.Fatalf("ExtractArticle() error = %v", err)
+			}
+			if article.Title != tt.want {
+				t.Errorf("Title = %q, want %q", article.Title, tt.want)
+			}
+			if tt.want == "" && article.Text() != "Text" {
+				t.Errorf("Text() = %q, want %q", article.Text(), "Text")
+			}
+		})
+	}
+}
+
+func TestExtractArticle_Content(t *testing.T) {
+	long := "This paragraph is long enough to be scored as prose"
+	tests := []struct {
+		name string
+		page string
+		want []string
+	}{
+		{"body without prose", `<body><div>Short note</div><div hidden>Hidden</div></body>`, []string{"Short note"}},
+		{"sibling sections", `<body><div><section><p>` + long + `.</p><p>` + long + `, too, indeed.</p></section>` +
+			`<section><p>` + long + `, again, and again.</p></section><section role=navigation><p>` + long + `!</p></section>` +
+			`<div class=related-posts><a href=/a>` + long + `?</a></div></div></body>`,
+			[]string{long + ".", long + ", too, indeed.", long + ", again, and again."}},
+		{"repeated title", `<h1>Title</h1><div><h1>Title</h1><p>` + long + `.</p></div>`, []string{long + "."}},
+		{"loose text", `<div class="main-content"><p>` + long + `.</p>Loose<br>text <span aria-hidden=true>x</span></div>`,
+			[]string{long + ".", "Loose text"}},
+		{"table", `<div><p>` + long + `.</p><table><tr><th>Plan</th><td>Free</td></tr></table></div>`,
+			[]string{long + ".", "Plan.", "Free."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article, err := ExtractArticle(strings.NewReader(tt.page))
+			if err != nil {
+				t.Fatalf("ExtractArticle() error = %v", err)
+			}
+			if !reflect.DeepEqual(article.Paragraphs, tt.want) {
+				t.Errorf("Paragraphs = %q, want %q", article.Paragraphs, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractArticle_Errors(t *testing.T) {
+	if _, err := ExtractArticle(strings.NewReader(`<nav><a href=/>Home</a></nav>`)); !errors.Is(err, ErrNoArticle) {
+		t.Errorf("error = %v, want ErrNoArticle", err)
+	}
+	readErr := errors.New("read failed")
+	if _, err := ExtractArticle(&failingReader{err: readErr}); !errors.Is(err, readErr) {
+		t.Errorf("error = %v, want %v", err, readErr)
+	}
+}
+
+type failingReader struct{ err error }
+
+func (r *failingReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestNarrateArticle(t *testing.T) {
+	var texts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		texts = append(texts, body.Text)
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write(makeTestWAV(make([]byte, 1600), 8000))
+	}))
+	defer srv.Close()
+	client := newTestClient(srv, "k")
+
+	result, err := client.NarrateArticle(context.Background(), strings.NewReader(testArticlePage), LongFormRequest{
+		Profile:       VoiceProfile{VoiceID: "tc_narrator"},
+		Text:          "ignored",
+		MaxChunkChars: 120,
+	})
+	if err != nil {
+		t.Fatalf("NarrateArticle() error = %v", err)
+	}
+	if len(result.Segments) != len(texts) || !strings.HasPrefix(texts[0], "Rain returns.\n\nAfter months") {
+		t.Fatalf("unexpected chunks: %q", texts)
+	}
+	if _, err := client.NarrateArticle(context.Background(), strings.NewReader(""), LongFormRequest{}); !errors.Is(err, ErrNoArticle) {
+		t.Errorf("error = %v, want ErrNoArticle", err)
+	}
+}
@@ -0,0 +1,177 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// defaultPendingPollInterval is the delay between polls of a pending job
+// when the API does not send Retry-After.
+const defaultPendingPollInterval = time.Second
+
+// PendingJob is a synthesis the API accepted with 202 Accepted to finish
+// later, as it may do for heavy requests. By default TextToSpeech polls for
+// the result itself; with ClientConfig.ReturnPendingJobs it returns the job
+// as its error instead, so the caller can await it when convenient:
+//
+//	resp, err := client.TextToSpeech(ctx, request)
+//	var job *typecast.PendingJob
+//	if errors.As(err, &job) {
+//		resp, err = job.Await(ctx)
+//	}
+type PendingJob struct {
+	// ID is the job ID the API returned, if any
+	ID string
+	// Location is the URL that returns the audio once the job is done
+	Location string
+	// RetryAfter is the delay the API asked for before polling, if any
+	RetryAfter time.Duration
+
+	client *Client
+	// settle refunds the job's quota or records its usage, once its
+	// outcome is known; nil for a job TextToSpeech awaits itself
+	settle func(response *TTSResponse, err error)
+}
+
+// Error describes the job, so an unhandled PendingJob reads as an error.
+func (j *PendingJob) Error() string {
+	if j.ID != "" {
+		return fmt.Sprintf("typecast: synthesis job %s is pending at %s", j.ID, j.Location)
+	}
+	return "typecast: synthesis job is pending at " + j.Location
+}
+
+// Await polls Location until the audio is ready and returns it, waiting
+// RetryAfter, or one second when the API does not say, between polls. It
+// returns the API's error if the job fails, and ctx's error once ctx is
+// done.
+//
+// A job TextToSpeech returned refunds its characters to the QuotaGuard
+// when it fails with the API's error, and records its usage with the
+// UsageSink when it succeeds. Other errors, such as ctx's, leave the
+// characters reserved, since the job may still finish and be awaited
+// again.
+func (j *PendingJob) Await(ctx context.Context) (*TTSResponse, error) {
+	buf := getAudioBuffer()
+	defer putAudioBuffer(buf)
+	response, err := j.await(ctx, buf)
+	var apiErr *APIError
+	if j.settle != nil && (err == nil || errors.As(err, &apiErr)) {
+		j.settle(response, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	response.AudioData = make([]byte, buf.Len())
+	copy(response.AudioData, buf.Bytes())
+	return response, nil
+}
+
+// await polls for the audio and reads it into buf.
+func (j *PendingJob) await(ctx context.Context, buf *bytes.Buffer) (*TTSResponse, error) {
+	delay := j.RetryAfter
+	for {
+		if delay <= 0 {
+			delay = defaultPendingPollInterval
+		}
+		if err := sleepContext(ctx, j.client.clock, delay); err != nil {
+			return nil, err
+		}
+		response, retryAfter, err := j.poll(ctx, buf)
+		if response != nil || err != nil {
+			return response, err
+		}
+		delay = retryAfter
+	}
+}
+
+// poll requests Location once. While the job is pending it returns neither
+// a response nor an error, and the Retry-After the API sent.
+func (j *PendingJob) poll(ctx context.Context, buf *bytes.Buffer) (*TTSResponse, time.Duration, error) {
+	c := j.client
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.Location, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	// The API key is only sent to the API's own host, so a Location on
+	// another host, such as a storage bucket, does not receive it.
+	if c.sameOrigin(req.URL) {
+		if err := c.setAuthHeader(req.Header); err != nil {
+			return nil, 0, err
+		}
+	}
+	c.setUserAgent(req.Header)
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After"), c.serverNow(resp.Header)), nil
+	case http.StatusOK:
+		response, err := c.readTTSAudio(resp, buf)
+		return response, 0, err
+	default:
+		return nil, 0, c.handleErrorResponse(resp)
+	}
+}
+
+// pendingJob reads the job of a 202 Accepted response. The job's URL is
+// the Location header, or the status_url of the JSON body, resolved against
+// the request URL.
+func (c *Client) pendingJob(resp *http.Response) (*PendingJob, error) {
+	var body struct {
+		JobID     string `json:"job_id"`
+		StatusURL string `json:"status_url"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	location := resp.Header.Get("Location")
+	if location == "" {
+		location = body.StatusURL
+	}
+	if location == "" {
+		return nil, fmt.Errorf("failed to follow pending job: 202 Accepted without a Location header or status_url")
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to follow pending job: %w", err)
+	}
+	return &PendingJob{
+		ID:         body.JobID,
+		Location:   resp.Request.URL.ResolveReference(ref).String(),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), c.serverNow(resp.Header)),
+		client:     c,
+	}, nil
+}
+
+// settlePendingJob returns the settle func of a job returned to the
+// caller of TextToSpeech, which refunds the quota of request or records
+// its usage only the first time it is called.
+func (c *Client) settlePendingJob(ctx context.Context, request *TTSRequest, refund func()) func(*TTSResponse, error) {
+	var once sync.Once
+	return func(response *TTSResponse, err error) {
+		once.Do(func() {
+			if err != nil {
+				refund()
+				return
+			}
+			c.recordUsage(ctx, "/v1/text-to-speech", request.VoiceID, request.Model, response.Duration, request.Text)
+		})
+	}
+}
+
+// sameOrigin reports whether u is on the scheme and host of the base URL.
+func (c *Client) sameOrigin(u *url.URL) bool {
+	base, err := url.Parse(c.baseURL)
+	return err == nil && base.Scheme == u.Scheme && base.Host == u.Host
+}
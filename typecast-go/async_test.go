@@ -0,0 +1,236 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTextToSpeech_FollowsPendingJob(t *testing.T) {
+	var polls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/text-to-speech":
+			w.Header().Set("Location", "/v1/jobs/j1")
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, `{"job_id":"j1"}`)
+		case "/v1/jobs/j1":
+			if r.Header.Get("X-API-KEY") != "k" || r.Header.Get(CorrelationIDHeader) != "req-1" {
+				t.Errorf("poll without the API key or correlation ID")
+			}
+			if atomic.AddInt32(&polls, 1) == 1 {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			w.Header().Set("Content-Type", "audio/mpeg")
+			w.Header().Set("X-Audio-Duration", "1.5")
+			fmt.Fprint(w, "mp3")
+		}
+	}))
+	defer srv.Close()
+	clock := NewFakeClock(time.Unix(0, 0))
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Clock: clock})
+	go func() {
+		// The API's Retry-After, then the default interval. Closing the
+		// client does not stop the polls of a synthesis in flight.
+		clock.WaitForTimers(1)
+		c.Close()
+		clock.Advance(2 * time.Second)
+		clock.WaitForTimers(1)
+		clock.Advance(time.Second)
+	}()
+
+	resp, err := c.TextToSpeech(WithCorrelationID(context.Background(), "req-1"), &TTSRequest{VoiceID: "tc_1", Text: "hi", Model: ModelSSFMV21})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.AudioData) != "mp3" || resp.Format != AudioFormatMP3 || resp.Duration != 1.5 || polls != 2 {
+		t.Fatalf("unexpected response %+v after %d polls", resp, polls)
+	}
+}
+
+func TestTextToSpeech_ReturnPendingJobs(t *testing.T) {
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, header := range []string{"X-API-KEY", CorrelationIDHeader, "traceparent", TagHeader} {
+			if r.Header.Get(header) != "" {
+				t.Errorf("%s sent to another host", header)
+			}
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		fmt.Fprint(w, "wav")
+	}))
+	defer storage.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, `{"job_id":"j2","status_url":%q}`, storage.URL+"/result")
+	}))
+	defer srv.Close()
+	clock := NewFakeClock(time.Unix(0, 0))
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Clock: clock, ReturnPendingJobs: true})
+
+	_, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "tc_1", Text: "hi", Model: ModelSSFMV21})
+	var job *PendingJob
+	if !errors.As(err, &job) || job.ID != "j2" || job.Location != storage.URL+"/result" || job.RetryAfter != 0 {
+		t.Fatalf("expected a pending job, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "job j2 is pending at "+storage.URL) {
+		t.Fatalf("unexpected message %q", err)
+	}
+	go func() {
+		clock.WaitForTimers(1)
+		clock.Advance(time.Second)
+	}()
+	ctx := WithTag(WithCorrelationID(context.Background(), "req-1"), "team=voice")
+	ctx = WithTraceContext(ctx, TraceContext{TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"})
+	resp, err := job.Await(ctx)
+	if err != nil || string(resp.AudioData) != "wav" || resp.Format != AudioFormatWAV {
+		t.Fatalf("unexpected await result %+v, %v", resp, err)
+	}
+}
+
+func TestPendingJob_AwaitSettlesQuotaAndUsage(t *testing.T) {
+	var polls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/text-to-speech":
+			var body TTSRequest
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			w.Header().Set("Location", "/v1/jobs/"+body.Text)
+			w.WriteHeader(http.StatusAccepted)
+		case "/v1/jobs/fail":
+			w.WriteHeader(http.StatusBadRequest)
+		case "/v1/jobs/later":
+			if atomic.AddInt32(&polls, 1) == 1 {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			fallthrough
+		default:
+			w.Header().Set("Content-Type", "audio/wav")
+			_, _ = w.Write(makeTestWAV(make([]byte, 1600), 8000))
+		}
+	}))
+	defer srv.Close()
+	clock := NewFakeClock(time.Unix(0, 0))
+	guard := NewQuotaGuard(QuotaConfig{HardLimit: 100})
+	sink := NewMemoryUsageSink()
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Clock: clock, QuotaGuard: guard, UsageSink: sink, ReturnPendingJobs: true})
+	pending := func(text string) *PendingJob {
+		_, err := c.TextToSpeech(WithTenant(context.Background(), "acme"), &TTSRequest{VoiceID: "tc_1", Text: text, Model: ModelSSFMV21})
+		var job *PendingJob
+		if !errors.As(err, &job) {
+			t.Fatalf("expected a pending job, got %v", err)
+		}
+		return job
+	}
+	await := func(ctx context.Context, job *PendingJob) (*TTSResponse, error) {
+		go func() {
+			clock.WaitForTimers(1)
+			clock.Advance(time.Second)
+		}()
+		return job.Await(ctx)
+	}
+
+	// A failed job refunds its characters.
+	job := pending("fail")
+	if used := guard.Usage().Used; used != 4 {
+		t.Fatalf("expected 4 characters reserved, got %d", used)
+	}
+	if _, err := await(context.Background(), job); err == nil {
+		t.Fatal("expected the job's error")
+	}
+	if used := guard.Usage().Used; used != 0 || len(sink.Records()) != 0 {
+		t.Fatalf("expected the quota refunded and no usage, got %d and %+v", used, sink.Records())
+	}
+
+	// A job whose wait is cut short stays reserved until it finishes, and
+	// is recorded once however often it is awaited.
+	job = pending("later")
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for atomic.LoadInt32(&polls) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+	if _, err := await(ctx, job); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the context error, got %v", err)
+	}
+	if used := guard.Usage().Used; used != 5 || len(sink.Records()) != 0 {
+		t.Fatalf("expected the quota kept and no usage, got %d and %+v", used, sink.Records())
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := await(context.Background(), job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records := sink.Records()
+	if used := guard.Usage().Used; used != 5 || len(records) != 1 || records[0].Tenant != "acme" || records[0].Characters != 5 {
+		t.Fatalf("expected the usage recorded once, got %d and %+v", used, records)
+	}
+}
+
+func TestPendingJob_Errors(t *testing.T) {
+	var location string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/text-to-speech":
+			w.Header().Set("Location", location)
+			w.WriteHeader(http.StatusAccepted)
+		case "/v1/jobs/failed":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"detail":"synthesis failed"}`)
+		}
+	}))
+	defer srv.Close()
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, ReturnPendingJobs: true})
+	accept := func(l string) error {
+		location = l
+		_, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "tc_1", Text: "hi", Model: ModelSSFMV21})
+		return err
+	}
+	if err := accept(""); err == nil || !strings.Contains(err.Error(), "without a Location header") {
+		t.Fatalf("expected a missing location error, got %v", err)
+	}
+	if err := accept(":bad"); err == nil || !strings.Contains(err.Error(), "failed to follow pending job") {
+		t.Fatalf("expected an invalid location error, got %v", err)
+	}
+
+	job := &PendingJob{Location: srv.URL + "/v1/jobs/failed", client: c}
+	if job.Error() != "typecast: synthesis job is pending at "+job.Location {
+		t.Fatalf("unexpected message %q", job.Error())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := job.Await(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the context error, got %v", err)
+	}
+	var apiErr *APIError
+	if _, _, err := job.poll(context.Background(), getAudioBuffer()); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected the job's error, got %v", err)
+	}
+	job.Location = ":bad"
+	if _, _, err := job.poll(context.Background(), getAudioBuffer()); err == nil || !strings.Contains(err.Error(), "failed to create request") {
+		t.Fatalf("expected a request error, got %v", err)
+	}
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	job.Location = closed.URL
+	if _, _, err := job.poll(context.Background(), getAudioBuffer()); err == nil {
+		t.Fatal("expected a send error")
+	}
+
+	t.Setenv("TYPECAST_API_KEY", "")
+	job = &PendingJob{Location: DefaultBaseURL + "/v1/jobs/j3", client: NewClient(nil)}
+	if _, _, err := job.poll(context.Background(), getAudioBuffer()); err == nil || !strings.Contains(err.Error(), "API key is required") {
+		t.Fatalf("expected the API key error, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package typecast
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// wavAudio is a decoded RIFF/WAVE file with its format chunk and PCM data.
+type wavAudio struct {
+	// format is the raw body of the "fmt " chunk
+	format []byte
+	// data is the body of the "data" chunk
+	data []byte
+}
+
+// decodeWAV parses the fmt and data chunks of a RIFF/WAVE file. Other chunks
+// are skipped. A data chunk whose declared size overruns the file, as written
+// by streaming encoders, is truncated to the bytes present.
+func decodeWAV(b []byte) (*wavAudio, error) {
+	if len(b) < 12 || string(b[:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("invalid wav: missing RIFF/WAVE header")
+	}
+	wav := &wavAudio{}
+	for pos := 12; pos+8 <= len(b); {
+		id := string(b[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(b[pos+4 : pos+8]))
+		body := pos + 8
+		end := body + size
+		if end > len(b) || end < body {
+			end = len(b)
+		}
+		switch id {
+		case "fmt ":
+			wav.format = b[body:end]
+		case "data":
+			wav.data = b[body:end]
+		}
+		pos = end + size%2
+	}
+	if len(wav.format) < 16 {
+		return nil, fmt.Errorf("invalid wav: missing fmt chunk")
+	}
+	if wav.data == nil {
+		return nil, fmt.Errorf("invalid wav: missing data chunk")
+	}
+	return wav, nil
+}
+
+func (w *wavAudio) sampleRate() int {
+	return int(binary.LittleEndian.Uint32(w.format[4:8]))
+}
+
+func (w *wavAudio) channels() int {
+	return int(binary.LittleEndian.Uint16(w.format[2:4]))
+}
+
+func (w *wavAudio) bitsPerSample() int {
+	return int(binary.LittleEndian.Uint16(w.format[14:16]))
+}
+
+func (w *wavAudio) blockAlign() int {
+	return int(binary.LittleEndian.Uint16(w.format[12:14]))
+}
+
+// duration returns the length of the PCM data in seconds.
+func (w *wavAudio) duration() float64 {
+	bytesPerSecond := w.sampleRate() * w.blockAlign()
+	if bytesPerSecond == 0 {
+		return 0
+	}
+	return float64(len(w.data)) / float64(bytesPerSecond)
+}
+
+// pcmLength returns the size of seconds of PCM data, in whole blocks.
+func (w *wavAudio) pcmLength(seconds float64) int {
+	return int(seconds*float64(w.sampleRate())) * w.blockAlign()
+}
+
+// encode writes w as a canonical RIFF/WAVE file.
+func (w *wavAudio) encode() []byte {
+	var buf bytes.Buffer
+	riffSize := 4 + 8 + len(w.format) + len(w.format)%2 + 8 + len(w.data) + len(w.data)%2
+	buf.Grow(8 + riffSize)
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(riffSize))
+	buf.WriteString("WAVE")
+	writeRIFFChunk(&buf, "fmt ", w.format)
+	writeRIFFChunk(&buf, "data", w.data)
+	return buf.Bytes()
+}
+
+func writeRIFFChunk(buf *bytes.Buffer, id string, body []byte) {
+	buf.WriteString(id)
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(body)))
+	buf.Write(body)
+	if len(body)%2 == 1 {
+		buf.WriteByte(0)
+	}
+}
+
+// concatAudio joins audio segments of one format into a single file. WAV
+// segments must share the same sample format; MP3 segments are joined frame
+// to frame with the ID3v2 tags of all but the first segment removed.
+func concatAudio(format AudioFormat, parts [][]byte) ([]byte, error) {
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("no audio to concatenate")
+	}
+	if format == AudioFormatMP3 {
+		var buf bytes.Buffer
+		for i, part := range parts {
+			if i > 0 {
+				part = stripID3v2(part)
+			}
+			buf.Write(part)
+		}
+		return buf.Bytes(), nil
+	}
+
+	var joined *wavAudio
+	var pcm bytes.Buffer
+	for i, part := range parts {
+		wav, err := decodeWAV(part)
+		if err != nil {
+			return nil, fmt.Errorf("segment %d: %w", i, err)
+		}
+		if joined == nil {
+			joined = &wavAudio{format: wav.format}
+		} else if !bytes.Equal(joined.format, wav.format) {
+			return nil, fmt.Errorf("segment %d: wav format differs from the first segment", i)
+		}
+		pcm.Write(wav.data)
+	}
+	joined.data = pcm.Bytes()
+	return joined.encode(), nil
+}
+
+// stripID3v2 removes a leading ID3v2 tag from MP3 data.
+func stripID3v2(b []byte) []byte {
+	if len(b) < 10 || string(b[:3]) != "ID3" {
+		return b
+	}
+	size := int(b[6]&0x7f)<<21 | int(b[7]&0x7f)<<14 | int(b[8]&0x7f)<<7 | int(b[9]&0x7f)
+	end := 10 + size
+	if b[5]&0x10 != 0 {
+		end += 10 // footer
+	}
+	if end > len(b) {
+		return nil
+	}
+	return b[end:]
+}
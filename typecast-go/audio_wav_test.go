@@ -0,0 +1,116 @@
+package typecast
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// makeTestWAV builds a 16-bit mono PCM WAV file around pcm.
+func makeTestWAV(pcm []byte, sampleRate int) []byte {
+	format := make([]byte, 16)
+	binary.LittleEndian.PutUint16(format[0:2], 1)
+	binary.LittleEndian.PutUint16(format[2:4], 1)
+	binary.LittleEndian.PutUint32(format[4:8], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(format[8:12], uint32(sampleRate*2))
+	binary.LittleEndian.PutUint16(format[12:14], 2)
+	binary.LittleEndian.PutUint16(format[14:16], 16)
+	return (&wavAudio{format: format, data: pcm}).encode()
+}
+
+func TestDecodeWAV_SkipsUnknownChunks(t *testing.T) {
+	wav := makeTestWAV([]byte{1, 2, 3, 4}, 8000)
+	// Insert an odd-sized LIST chunk (with pad byte) before fmt.
+	withList := append(append(append([]byte{}, wav[:12]...), []byte("LIST\x03\x00\x00\x00abc\x00")...), wav[12:]...)
+	decoded, err := decodeWAV(withList)
+	if err != nil {
+		t.Fatalf("decodeWAV() error = %v", err)
+	}
+	if !bytes.Equal(decoded.data, []byte{1, 2, 3, 4}) || decoded.sampleRate() != 8000 {
+		t.Fatalf("unexpected decode: %+v", decoded)
+	}
+	if d := decoded.duration(); d != 4.0/16000 {
+		t.Fatalf("duration() = %v", d)
+	}
+}
+
+func TestDecodeWAV_TruncatedDataChunk(t *testing.T) {
+	wav := makeTestWAV(make([]byte, 100), 8000)
+	decoded, err := decodeWAV(wav[:len(wav)-40])
+	if err != nil {
+		t.Fatalf("decodeWAV() error = %v", err)
+	}
+	if len(decoded.data) != 60 {
+		t.Fatalf("expected 60 bytes of data, got %d", len(decoded.data))
+	}
+}
+
+func TestDecodeWAV_Errors(t *testing.T) {
+	wav := makeTestWAV([]byte{0, 0}, 8000)
+	cases := map[string][]byte{
+		"missing RIFF/WAVE header": []byte("not a wav"),
+		"missing fmt chunk":        []byte("RIFF\x00\x00\x00\x00WAVEdata\x00\x00\x00\x00"),
+		"missing data chunk":       wav[:36],
+	}
+	for want, input := range cases {
+		if _, err := decodeWAV(input); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+	zero := &wavAudio{format: make([]byte, 16)}
+	if zero.duration() != 0 {
+		t.Fatal("expected zero duration for an empty format")
+	}
+}
+
+func TestConcatAudio_WAV(t *testing.T) {
+	joined, err := concatAudio(AudioFormatWAV, [][]byte{makeTestWAV([]byte{1, 2}, 8000), makeTestWAV([]byte{3, 4, 5, 6}, 8000)})
+	if err != nil {
+		t.Fatalf("concatAudio() error = %v", err)
+	}
+	if !bytes.Equal(joined, makeTestWAV([]byte{1, 2, 3, 4, 5, 6}, 8000)) {
+		t.Fatalf("unexpected joined wav: % x", joined)
+	}
+
+	if _, err := concatAudio(AudioFormatWAV, [][]byte{makeTestWAV(nil, 8000), makeTestWAV(nil, 16000)}); err == nil || !strings.Contains(err.Error(), "segment 1: wav format differs") {
+		t.Fatalf("expected format mismatch, got %v", err)
+	}
+	if _, err := concatAudio(AudioFormatWAV, [][]byte{[]byte("junk")}); err == nil || !strings.Contains(err.Error(), "segment 0") {
+		t.Fatalf("expected decode error, got %v", err)
+	}
+	if _, err := concatAudio(AudioFormatWAV, nil); err == nil || !strings.Contains(err.Error(), "no audio") {
+		t.Fatalf("expected empty error, got %v", err)
+	}
+}
+
+func TestConcatAudio_MP3StripsLaterID3Tags(t *testing.T) {
+	tag := []byte("ID3\x04\x00\x00\x00\x00\x00\x02ab")
+	footerTag := []byte("ID3\x04\x00\x10\x00\x00\x00\x00" + "3DI\x04\x00\x10\x00\x00\x00\x00")
+	frame := []byte{0xFF, 0xFB, 0x90}
+	joined, err := concatAudio(AudioFormatMP3, [][]byte{
+		append(append([]byte{}, tag...), frame...),
+		append(append([]byte{}, tag...), frame...),
+		append(append([]byte{}, footerTag...), frame...),
+		frame,
+		[]byte("ID3\x04\x00\x00\x00\x00\x00\x7f"),
+	})
+	if err != nil {
+		t.Fatalf("concatAudio() error = %v", err)
+	}
+	want := append(append([]byte{}, tag...), bytes.Repeat(frame, 4)...)
+	if !bytes.Equal(joined, want) {
+		t.Fatalf("joined = % x, want % x", joined, want)
+	}
+}
+
+func TestWAVEncode_PadsOddChunks(t *testing.T) {
+	wav := makeTestWAV([]byte{1, 2, 3}, 8000)
+	if len(wav)%2 != 0 {
+		t.Fatalf("expected even file length, got %d", len(wav))
+	}
+	decoded, err := decodeWAV(wav)
+	if err != nil || !bytes.Equal(decoded.data, []byte{1, 2, 3}) {
+		t.Fatalf("round trip failed: %v %+v", err, decoded)
+	}
+}
@@ -0,0 +1,159 @@
+// Package audiotest compares synthesized audio against stored golden files
+// by duration and loudness envelope rather than byte equality, so regression
+// tests tolerate the benign nondeterminism of speech synthesis.
+//
+// Golden files are WAV files. Set TYPECAST_UPDATE_GOLDEN=1 to write the
+// audio under test as the new golden file instead of comparing.
+package audiotest
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// UpdateEnv is the environment variable that makes AssertGolden rewrite
+// golden files.
+const UpdateEnv = "TYPECAST_UPDATE_GOLDEN"
+
+// Tolerance bounds how far audio may drift from its golden file.
+type Tolerance struct {
+	// DurationRatio is the allowed relative duration difference (optional, defaults to 0.1)
+	DurationRatio float64
+	// MinCorrelation is the lowest accepted Pearson correlation between the
+	// loudness envelopes, from -1 to 1 (optional, defaults to 0.8)
+	MinCorrelation float64
+	// Window is the envelope resolution (optional, defaults to 50ms)
+	Window time.Duration
+}
+
+func (t Tolerance) withDefaults() Tolerance {
+	if t.DurationRatio <= 0 {
+		t.DurationRatio = 0.1
+	}
+	if t.MinCorrelation == 0 {
+		t.MinCorrelation = 0.8
+	}
+	if t.Window <= 0 {
+		t.Window = 50 * time.Millisecond
+	}
+	return t
+}
+
+// Comparison reports how two audio files relate.
+type Comparison struct {
+	// GotDuration is the duration of the audio under test, in seconds
+	GotDuration float64
+	// WantDuration is the duration of the golden audio, in seconds
+	WantDuration float64
+	// Correlation is the Pearson correlation of the loudness envelopes
+	Correlation float64
+}
+
+// Compare decodes two WAV files and checks that their durations and
+// loudness envelopes agree within tol. The returned Comparison is set even
+// when the audio is outside the tolerance.
+func Compare(got, want []byte, tol Tolerance) (*Comparison, error) {
+	tol = tol.withDefaults()
+	gotPCM, err := decodeWAV(got)
+	if err != nil {
+		return nil, fmt.Errorf("audio under test: %w", err)
+	}
+	wantPCM, err := decodeWAV(want)
+	if err != nil {
+		return nil, fmt.Errorf("golden audio: %w", err)
+	}
+	cmp := &Comparison{
+		GotDuration:  gotPCM.duration(),
+		WantDuration: wantPCM.duration(),
+		Correlation:  correlation(envelope(gotPCM, tol.Window), envelope(wantPCM, tol.Window)),
+	}
+	if diff := math.Abs(cmp.GotDuration - cmp.WantDuration); diff > tol.DurationRatio*cmp.WantDuration {
+		return cmp, fmt.Errorf("duration %.3fs differs from golden %.3fs by more than %.0f%%", cmp.GotDuration, cmp.WantDuration, tol.DurationRatio*100)
+	}
+	if cmp.Correlation < tol.MinCorrelation {
+		return cmp, fmt.Errorf("loudness envelope correlation %.3f is below %.3f", cmp.Correlation, tol.MinCorrelation)
+	}
+	return cmp, nil
+}
+
+// AssertGolden compares got with the golden WAV file at path and fails t
+// when they differ beyond tol. With TYPECAST_UPDATE_GOLDEN=1 it writes got
+// to path instead.
+func AssertGolden(t testing.TB, got []byte, path string, tol Tolerance) {
+	t.Helper()
+	if os.Getenv(UpdateEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("audiotest: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("audiotest: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("audiotest: reading golden file (set %s=1 to create it): %v", UpdateEnv, err)
+		return
+	}
+	if _, err := Compare(got, want, tol); err != nil {
+		t.Errorf("audiotest: %s: %v", path, err)
+	}
+}
+
+// envelope returns the RMS loudness of each window of p.
+func envelope(p *pcm, window time.Duration) []float64 {
+	size := int(window.Seconds() * float64(p.sampleRate))
+	if size < 1 {
+		size = 1
+	}
+	var env []float64
+	for start := 0; start < len(p.samples); start += size {
+		end := start + size
+		if end > len(p.samples) {
+			end = len(p.samples)
+		}
+		var sum float64
+		for _, s := range p.samples[start:end] {
+			sum += s * s
+		}
+		env = append(env, math.Sqrt(sum/float64(end-start)))
+	}
+	return env
+}
+
+// correlation returns the Pearson correlation of a and b over their common
+// length. Two flat envelopes (e.g. silence) correlate perfectly.
+func correlation(a, b []float64) float64 {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	if n == 0 {
+		return 1
+	}
+	var meanA, meanB float64
+	for i := 0; i < n; i++ {
+		meanA += a[i]
+		meanB += b[i]
+	}
+	meanA /= float64(n)
+	meanB /= float64(n)
+	var cov, varA, varB float64
+	for i := 0; i < n; i++ {
+		da, db := a[i]-meanA, b[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 && varB == 0 {
+		return 1
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
@@ -0,0 +1,224 @@
+package audiotest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildWAV encodes samples in [-1, 1] as a WAV file with the given encoding.
+func buildWAV(samples []float64, sampleRate, channels, bits int, encoding uint16) []byte {
+	var data bytes.Buffer
+	for _, s := range samples {
+		for ch := 0; ch < channels; ch++ {
+			switch {
+			case encoding == 3:
+				_ = binary.Write(&data, binary.LittleEndian, math.Float32bits(float32(s)))
+			case bits == 8:
+				data.WriteByte(byte(s*127 + 128))
+			case bits == 16:
+				_ = binary.Write(&data, binary.LittleEndian, int16(s*32767))
+			case bits == 24:
+				v := int32(s * 8388607)
+				data.Write([]byte{byte(v), byte(v >> 8), byte(v >> 16)})
+			case bits == 32:
+				_ = binary.Write(&data, binary.LittleEndian, int32(s*2147483647))
+			}
+		}
+	}
+	format := make([]byte, 16)
+	binary.LittleEndian.PutUint16(format[0:], encoding)
+	binary.LittleEndian.PutUint16(format[2:], uint16(channels))
+	binary.LittleEndian.PutUint32(format[4:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(format[8:], uint32(sampleRate*channels*bits/8))
+	binary.LittleEndian.PutUint16(format[12:], uint16(channels*bits/8))
+	binary.LittleEndian.PutUint16(format[14:], uint16(bits))
+
+	var out bytes.Buffer
+	out.WriteString("RIFF")
+	_ = binary.Write(&out, binary.LittleEndian, uint32(4+8+len(format)+8+data.Len()))
+	out.WriteString("WAVEfmt ")
+	_ = binary.Write(&out, binary.LittleEndian, uint32(len(format)))
+	out.Write(format)
+	out.WriteString("data")
+	_ = binary.Write(&out, binary.LittleEndian, uint32(data.Len()))
+	out.Write(data.Bytes())
+	return out.Bytes()
+}
+
+// speech synthesizes a tone whose loudness follows bursts, a rough stand-in
+// for syllables. gain scales the whole signal; phase shifts the tone.
+func speech(seconds float64, bursts []float64, gain, phase float64) []float64 {
+	const rate = 8000
+	samples := make([]float64, int(seconds*rate))
+	for i := range samples {
+		t := float64(i) / rate
+		amp := bursts[int(t/seconds*float64(len(bursts)))%len(bursts)]
+		samples[i] = gain * amp * math.Sin(2*math.Pi*220*t+phase)
+	}
+	return samples
+}
+
+var syllables = []float64{0.1, 0.9, 0.3, 0.8, 0.0, 0.7, 0.2, 0.95, 0.4, 0.1}
+
+func TestCompare_ToleratesBenignDifferences(t *testing.T) {
+	golden := buildWAV(speech(1, syllables, 0.8, 0), 8000, 1, 16, 1)
+	got := buildWAV(speech(1.03, syllables, 0.6, 1.2), 8000, 1, 16, 1)
+	cmp, err := Compare(got, golden, Tolerance{})
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if cmp.Correlation < 0.9 || math.Abs(cmp.WantDuration-1) > 1e-9 {
+		t.Fatalf("unexpected comparison: %+v", cmp)
+	}
+}
+
+func TestCompare_DetectsRegressions(t *testing.T) {
+	golden := buildWAV(speech(1, syllables, 0.8, 0), 8000, 1, 16, 1)
+
+	reversed := make([]float64, len(syllables))
+	for i, v := range syllables {
+		reversed[len(syllables)-1-i] = v
+	}
+	if _, err := Compare(buildWAV(speech(1, reversed, 0.8, 0), 8000, 1, 16, 1), golden, Tolerance{}); err == nil || !strings.Contains(err.Error(), "correlation") {
+		t.Fatalf("expected envelope error, got %v", err)
+	}
+	if _, err := Compare(buildWAV(speech(0.5, syllables, 0.8, 0), 8000, 1, 16, 1), golden, Tolerance{}); err == nil || !strings.Contains(err.Error(), "duration") {
+		t.Fatalf("expected duration error, got %v", err)
+	}
+}
+
+func TestCompare_DecodesEncodings(t *testing.T) {
+	samples := speech(0.5, syllables, 0.8, 0)
+	golden := buildWAV(samples, 8000, 1, 16, 1)
+	cases := []struct {
+		bits, channels int
+		encoding       uint16
+	}{
+		{8, 1, 1}, {24, 2, 1}, {32, 1, 1}, {32, 2, 3},
+	}
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%d-bit/%dch/enc%d", tc.bits, tc.channels, tc.encoding), func(t *testing.T) {
+			if _, err := Compare(buildWAV(samples, 8000, tc.channels, tc.bits, tc.encoding), golden, Tolerance{MinCorrelation: 0.99}); err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+		})
+	}
+
+	// A data chunk cut short, as left by an interrupted stream.
+	if decoded, err := decodeWAV(golden[:len(golden)-10]); err != nil || len(decoded.samples) != len(samples)-5 {
+		t.Fatalf("truncated decode failed: %v", err)
+	}
+
+	// WAVE_FORMAT_EXTENSIBLE carrying 16-bit PCM.
+	ext := buildWAV(samples, 8000, 1, 16, 0xFFFE)
+	decoded, err := decodeWAV(extensible(ext))
+	if err != nil || len(decoded.samples) != len(samples) {
+		t.Fatalf("extensible decode failed: %v", err)
+	}
+}
+
+// extensible grows the fmt chunk of a 0xFFFE wav to 40 bytes with a PCM
+// sub-format GUID.
+func extensible(wav []byte) []byte {
+	format := append(append([]byte{}, wav[20:36]...), make([]byte, 24)...)
+	binary.LittleEndian.PutUint16(format[16:], 22)
+	binary.LittleEndian.PutUint16(format[24:], 1)
+	var out bytes.Buffer
+	out.WriteString("RIFF")
+	_ = binary.Write(&out, binary.LittleEndian, uint32(len(wav)-8+24))
+	out.WriteString("WAVEfmt ")
+	_ = binary.Write(&out, binary.LittleEndian, uint32(len(format)))
+	out.Write(format)
+	out.Write(wav[36:])
+	return out.Bytes()
+}
+
+func TestCompare_Errors(t *testing.T) {
+	good := buildWAV(speech(0.1, syllables, 0.5, 0), 8000, 1, 16, 1)
+	cases := map[string][2][]byte{
+		"audio under test: not a wav file": {[]byte("nope"), good},
+		"golden audio: not a wav file":     {good, []byte("nope")},
+		"missing its fmt or data chunk":    {[]byte("RIFF\x00\x00\x00\x00WAVEjunk\x02\x00\x00\x00ab"), good},
+		"unsupported wav encoding 2":       {buildWAV(nil, 8000, 1, 16, 2), good},
+		"has 0 channels":                   {buildWAV(nil, 8000, 0, 16, 1), good},
+	}
+	for want, input := range cases {
+		if _, err := Compare(input[0], input[1], Tolerance{}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+}
+
+func TestCorrelationEdgeCases(t *testing.T) {
+	if correlation(nil, []float64{1}) != 1 {
+		t.Fatal("empty envelopes correlate")
+	}
+	if correlation([]float64{0, 0}, []float64{0, 0}) != 1 {
+		t.Fatal("two silences correlate")
+	}
+	if correlation([]float64{0, 0}, []float64{0, 1}) != 0 {
+		t.Fatal("silence does not correlate with sound")
+	}
+	p := &pcm{sampleRate: 10, samples: []float64{1, 1, 1}}
+	if env := envelope(p, 0); len(env) != 3 {
+		t.Fatalf("expected one window per sample, got %v", env)
+	}
+}
+
+// recorder captures failures reported through testing.TB.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+func TestAssertGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golden", "hello.wav")
+	audio := buildWAV(speech(0.5, syllables, 0.8, 0), 8000, 1, 16, 1)
+
+	rec := &recorder{TB: t}
+	AssertGolden(rec, audio, path, Tolerance{})
+	if !rec.fatal || !strings.Contains(rec.errors[0], UpdateEnv) {
+		t.Fatalf("expected missing golden failure, got %v", rec.errors)
+	}
+
+	t.Setenv(UpdateEnv, "1")
+	AssertGolden(t, audio, path, Tolerance{})
+	if written, _ := os.ReadFile(path); !bytes.Equal(written, audio) {
+		t.Fatal("golden file was not written")
+	}
+	blocked := filepath.Join(path, "nested.wav")
+	rec = &recorder{TB: t}
+	AssertGolden(rec, audio, blocked, Tolerance{})
+	if !rec.fatal {
+		t.Fatal("expected failure creating a directory under a file")
+	}
+	rec = &recorder{TB: t}
+	AssertGolden(rec, audio, filepath.Dir(path), Tolerance{})
+	if !rec.fatal {
+		t.Fatal("expected failure writing over a directory")
+	}
+
+	t.Setenv(UpdateEnv, "")
+	AssertGolden(t, audio, path, Tolerance{})
+	rec = &recorder{TB: t}
+	AssertGolden(rec, buildWAV(speech(0.1, syllables, 0.8, 0), 8000, 1, 16, 1), path, Tolerance{})
+	if rec.fatal || len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "duration") {
+		t.Fatalf("expected a comparison error, got %v", rec.errors)
+	}
+}
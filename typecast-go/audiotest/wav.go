@@ -0,0 +1,90 @@
+package audiotest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// pcm is mono audio decoded from a WAV file, with samples in [-1, 1].
+type pcm struct {
+	sampleRate int
+	samples    []float64
+}
+
+func (p *pcm) duration() float64 {
+	return float64(len(p.samples)) / float64(p.sampleRate)
+}
+
+// decodeWAV decodes integer PCM (8, 16, 24 or 32 bit) and 32-bit float WAV
+// data, mixing channels down to mono.
+func decodeWAV(b []byte) (*pcm, error) {
+	if len(b) < 12 || string(b[:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("not a wav file")
+	}
+	var format, data []byte
+	for pos := 12; pos+8 <= len(b); {
+		size := int(binary.LittleEndian.Uint32(b[pos+4 : pos+8]))
+		end := pos + 8 + size
+		if end > len(b) || end < pos+8 {
+			end = len(b)
+		}
+		switch string(b[pos : pos+4]) {
+		case "fmt ":
+			format = b[pos+8 : end]
+		case "data":
+			data = b[pos+8 : end]
+		}
+		pos = end + size%2
+	}
+	if len(format) < 16 || data == nil {
+		return nil, fmt.Errorf("wav file is missing its fmt or data chunk")
+	}
+	encoding := binary.LittleEndian.Uint16(format[0:2])
+	channels := int(binary.LittleEndian.Uint16(format[2:4]))
+	sampleRate := int(binary.LittleEndian.Uint32(format[4:8]))
+	bits := int(binary.LittleEndian.Uint16(format[14:16]))
+	if channels < 1 || sampleRate < 1 {
+		return nil, fmt.Errorf("wav file has %d channels at %d Hz", channels, sampleRate)
+	}
+	if encoding == 0xFFFE && len(format) >= 26 {
+		// WAVE_FORMAT_EXTENSIBLE: the real encoding starts the sub-format GUID.
+		encoding = binary.LittleEndian.Uint16(format[24:26])
+	}
+	sample, err := sampleDecoder(encoding, bits)
+	if err != nil {
+		return nil, err
+	}
+
+	width := bits / 8
+	frames := len(data) / (width * channels)
+	out := &pcm{sampleRate: sampleRate, samples: make([]float64, frames)}
+	for i := 0; i < frames; i++ {
+		var sum float64
+		for ch := 0; ch < channels; ch++ {
+			offset := (i*channels + ch) * width
+			sum += sample(data[offset : offset+width])
+		}
+		out.samples[i] = sum / float64(channels)
+	}
+	return out, nil
+}
+
+func sampleDecoder(encoding uint16, bits int) (func([]byte) float64, error) {
+	switch {
+	case encoding == 1 && bits == 8:
+		return func(b []byte) float64 { return (float64(b[0]) - 128) / 128 }, nil
+	case encoding == 1 && bits == 16:
+		return func(b []byte) float64 { return float64(int16(binary.LittleEndian.Uint16(b))) / 32768 }, nil
+	case encoding == 1 && bits == 24:
+		return func(b []byte) float64 {
+			v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+			return float64(v) / 8388608
+		}, nil
+	case encoding == 1 && bits == 32:
+		return func(b []byte) float64 { return float64(int32(binary.LittleEndian.Uint32(b))) / 2147483648 }, nil
+	case encoding == 3 && bits == 32:
+		return func(b []byte) float64 { return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))) }, nil
+	}
+	return nil, fmt.Errorf("unsupported wav encoding %d with %d bits per sample", encoding, bits)
+}
@@ -0,0 +1,167 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// WithIdentity returns a context that attributes the requests made with it
+// to identity, such as the end user or service account on whose behalf
+// they are made, in the records sent to ClientConfig.AuditSink.
+func WithIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, identityContextKey, identity)
+}
+
+// IdentityFromContext returns the identity attached to ctx with
+// WithIdentity, or "".
+func IdentityFromContext(ctx context.Context) string {
+	identity, _ := ctx.Value(identityContextKey).(string)
+	return identity
+}
+
+// AuditSink receives an AuditRecord for every API call, once it has its
+// response or has failed, from the goroutine that made it. Implementations
+// must be safe for concurrent use and should not block.
+type AuditSink interface {
+	RecordAudit(record AuditRecord)
+}
+
+// AuditRecord records who made an API call, what it asked for, when, and
+// how it ended. The text synthesized is only recorded as a hash, and as an
+// excerpt with ClientConfig.AuditTextExcerpt.
+type AuditRecord struct {
+	// Time is when the call was made
+	Time time.Time `json:"time"`
+	// Identity is the identity from WithIdentity, or ""
+	Identity string `json:"identity,omitempty"`
+	// Tenant is the tenant from WithTenant, or ""
+	Tenant string `json:"tenant,omitempty"`
+	// CorrelationID is the ID from WithCorrelationID, or ""
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// Method is the HTTP method
+	Method string `json:"method"`
+	// Endpoint is the request path, such as /v1/text-to-speech
+	Endpoint string `json:"endpoint"`
+	// VoiceID is the voice of a synthesis, or ""
+	VoiceID string `json:"voice_id,omitempty"`
+	// Model is the model of a synthesis, or ""
+	Model TTSModel `json:"model,omitempty"`
+	// TextSHA256 is the hex SHA-256 of the text of a synthesis, or "";
+	// composed speech hashes its texts joined by newlines
+	TextSHA256 string `json:"text_sha256,omitempty"`
+	// TextExcerpt is the start of the text, with AuditTextExcerpt
+	TextExcerpt string `json:"text_excerpt,omitempty"`
+	// Status is the HTTP status of the response, or 0 without one
+	Status int `json:"status,omitempty"`
+	// Error is the error of a call that got no response
+	Error string `json:"error,omitempty"`
+	// Duration is the time the call took, including retries, in seconds
+	Duration float64 `json:"duration"`
+}
+
+// JSONAuditSink is an AuditSink that writes each record as a line of JSON,
+// for example to an append-only file.
+type JSONAuditSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewJSONAuditSink creates a JSONAuditSink writing to w.
+func NewJSONAuditSink(w io.Writer) *JSONAuditSink {
+	return &JSONAuditSink{enc: json.NewEncoder(w)}
+}
+
+// RecordAudit writes record.
+func (s *JSONAuditSink) RecordAudit(record AuditRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.enc.Encode(record); err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// Err returns the first error writing a record, if any.
+func (s *JSONAuditSink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// audit sends the record of the call req, made at started, to the
+// AuditSink.
+func (c *Client) audit(req *http.Request, started time.Time, resp *http.Response, err error) {
+	ctx := req.Context()
+	record := AuditRecord{
+		Time:          started,
+		Identity:      IdentityFromContext(ctx),
+		Tenant:        TenantFromContext(ctx),
+		CorrelationID: CorrelationIDFromContext(ctx),
+		Method:        req.Method,
+		Endpoint:      req.URL.Path,
+		Duration:      c.clock.Now().Sub(started).Seconds(),
+	}
+	if resp != nil {
+		record.Status = resp.StatusCode
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if req.GetBody != nil {
+		c.auditBody(&record, req)
+	}
+	c.auditSink.RecordAudit(record)
+}
+
+// auditBody adds the voice, model, and text of the synthesis request req
+// to record.
+func (c *Client) auditBody(record *AuditRecord, req *http.Request) {
+	body, err := req.GetBody()
+	if err != nil {
+		return
+	}
+	var request struct {
+		VoiceID  string   `json:"voice_id"`
+		Model    TTSModel `json:"model"`
+		Text     string   `json:"text"`
+		Segments []struct {
+			Text string `json:"text"`
+		} `json:"segments"`
+	}
+	if json.NewDecoder(body).Decode(&request) != nil {
+		return
+	}
+	record.VoiceID, record.Model = request.VoiceID, request.Model
+	text := request.Text
+	if len(request.Segments) > 0 {
+		var texts []string
+		for _, segment := range request.Segments {
+			if segment.Text != "" {
+				texts = append(texts, segment.Text)
+			}
+		}
+		text = strings.Join(texts, "\n")
+	}
+	if text == "" {
+		return
+	}
+	record.TextSHA256 = sha256Hex([]byte(text))
+	if c.auditTextExcerpt > 0 {
+		record.TextExcerpt = excerpt(text, c.auditTextExcerpt)
+	}
+}
+
+// excerpt returns the first n characters of text, marking a cut with an
+// ellipsis.
+func excerpt(text string, n int) string {
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	return string([]rune(text)[:n]) + "…"
+}
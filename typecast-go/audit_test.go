@@ -0,0 +1,123 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordingAuditSink struct{ records []AuditRecord }
+
+func (s *recordingAuditSink) RecordAudit(record AuditRecord) {
+	s.records = append(s.records, record)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestAuditSink_RecordsEveryCall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/voices":
+			fmt.Fprint(w, `[]`)
+		case "/v1/users/me/subscription":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			_, _ = w.Write([]byte("audio"))
+		}
+	}))
+	defer srv.Close()
+	clock := NewFakeClock(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+	sink := &recordingAuditSink{}
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Clock: clock, AuditSink: sink, AuditTextExcerpt: 5})
+	ctx := WithCorrelationID(WithTenant(WithIdentity(context.Background(), "user-42"), "acme"), "req-1")
+
+	if _, err := c.TextToSpeech(ctx, &TTSRequest{VoiceID: "tc_1", Text: "Hello, Jane Doe", Model: ModelSSFMV30}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ComposeSpeech().Defaults(ComposerSettings{VoiceID: "tc_1", Model: ModelSSFMV30}).Say("one").Pause(1).Say("two").Generate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetVoicesV2(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetMySubscription(ctx); err == nil {
+		t.Fatal("expected a 401")
+	}
+	srv.Close()
+	if _, err := c.GetVoicesV2(ctx, nil); err == nil {
+		t.Fatal("expected a transport error")
+	}
+
+	if len(sink.records) != 5 {
+		t.Fatalf("expected 5 records, got %+v", sink.records)
+	}
+	tts := sink.records[0]
+	if tts.Identity != "user-42" || tts.Tenant != "acme" || tts.CorrelationID != "req-1" || tts.Method != http.MethodPost ||
+		tts.Endpoint != "/v1/text-to-speech" || tts.VoiceID != "tc_1" || tts.Model != ModelSSFMV30 ||
+		tts.TextSHA256 != sha256Hex([]byte("Hello, Jane Doe")) || tts.TextExcerpt != "Hello…" || tts.Status != 200 || !tts.Time.Equal(clock.Now()) {
+		t.Fatalf("unexpected record %+v", tts)
+	}
+	if composed := sink.records[1]; composed.Endpoint != "/v1/text-to-speech/compose" || composed.TextSHA256 != sha256Hex([]byte("one\ntwo")) || composed.TextExcerpt != "one\nt…" {
+		t.Fatalf("unexpected composed record %+v", composed)
+	}
+	if voices := sink.records[2]; voices.Method != http.MethodGet || voices.TextSHA256 != "" || voices.Status != 200 {
+		t.Fatalf("unexpected voices record %+v", voices)
+	}
+	if sub := sink.records[3]; sub.Status != http.StatusUnauthorized || sub.Error != "" {
+		t.Fatalf("unexpected subscription record %+v", sub)
+	}
+	if failed := sink.records[4]; failed.Status != 0 || failed.Error == "" {
+		t.Fatalf("unexpected failed record %+v", failed)
+	}
+	if IdentityFromContext(context.Background()) != "" {
+		t.Fatal("expected no identity")
+	}
+}
+
+func TestAuditSink_HashOnlyByDefault(t *testing.T) {
+	sink := &recordingAuditSink{}
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://example.invalid", AuditSink: sink})
+	for _, body := range []string{`{"text":"short"}`, `not json`, `{}`} {
+		req, _ := http.NewRequest(http.MethodPost, "http://example.invalid/v1/text-to-speech", strings.NewReader(body))
+		c.audit(req, time.Now(), nil, nil)
+	}
+	req, _ := http.NewRequest(http.MethodPost, "http://example.invalid/v1/text-to-speech", nil)
+	req.GetBody = func() (io.ReadCloser, error) { return nil, errors.New("gone") }
+	c.audit(req, time.Now(), nil, nil)
+	if len(sink.records) != 4 || sink.records[0].TextSHA256 == "" || sink.records[0].TextExcerpt != "" ||
+		sink.records[1].TextSHA256 != "" || sink.records[2].TextSHA256 != "" || sink.records[3].TextSHA256 != "" {
+		t.Fatalf("unexpected records %+v", sink.records)
+	}
+	if got := excerpt("short", 5); got != "short" {
+		t.Fatalf("excerpt = %q", got)
+	}
+}
+
+func TestJSONAuditSink(t *testing.T) {
+	var b bytes.Buffer
+	sink := NewJSONAuditSink(&b)
+	sink.RecordAudit(AuditRecord{Method: http.MethodGet, Endpoint: "/v2/voices", Status: 200})
+	sink.RecordAudit(AuditRecord{Method: http.MethodPost, Endpoint: "/v1/text-to-speech", Error: "boom"})
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	var record AuditRecord
+	if len(lines) != 2 || json.Unmarshal([]byte(lines[1]), &record) != nil || record.Error != "boom" || sink.Err() != nil {
+		t.Fatalf("unexpected output %q", b.String())
+	}
+
+	failing := NewJSONAuditSink(failingWriter{})
+	failing.RecordAudit(AuditRecord{})
+	failing.RecordAudit(AuditRecord{})
+	if err := failing.Err(); err == nil || err.Error() != "disk full" {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package typecast
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultAuditionText is the line AuditionVoices reads when no text is given.
+const DefaultAuditionText = "Hello! This is a short preview of how I sound when reading your script."
+
+// AuditionOptions configures AuditionVoices.
+type AuditionOptions struct {
+	// Text is the line every voice reads (optional, defaults to
+	// DefaultAuditionText)
+	Text string
+	// UseSampleText reads each voice's own SampleText when it has one,
+	// falling back to Text (optional)
+	UseSampleText bool
+	// Model is the model to use; voices that do not support it use their
+	// BestModel (optional, defaults to each voice's BestModel)
+	Model TTSModel
+	// Language is the ISO 639-3 language code (optional, auto-detected if not provided)
+	Language string
+	// AudioFormat is the output format (optional, defaults to wav)
+	AudioFormat AudioFormat
+}
+
+// Audition is one voice's reading of the audition line.
+type Audition struct {
+	// Voice is the auditioned voice
+	Voice VoiceV2
+	// Text is the line that was read
+	Text string
+	// Model is the model that was used
+	Model TTSModel
+	// Duration is the audio duration in seconds
+	Duration float64
+	// Format is the audio format
+	Format AudioFormat
+	// AudioData is the synthesized line
+	AudioData []byte
+}
+
+// AuditionVoices synthesizes the same line with each candidate voice so they
+// can be compared side by side. Auditions are requested concurrently, within
+// the client's concurrency limit, and returned in the order of voices.
+func (c *Client) AuditionVoices(ctx context.Context, voices []VoiceV2, opts *AuditionOptions) ([]Audition, error) {
+	if len(voices) == 0 {
+		return nil, newValidationError("voices", "at least one voice is required")
+	}
+	if opts == nil {
+		opts = &AuditionOptions{}
+	}
+	text := opts.Text
+	if strings.TrimSpace(text) == "" {
+		text = DefaultAuditionText
+	}
+
+	auditions := make([]Audition, len(voices))
+	errs := make([]error, len(voices))
+	var wg sync.WaitGroup
+	for i, voice := range voices {
+		line := text
+		if opts.UseSampleText && strings.TrimSpace(voice.SampleText) != "" {
+			line = voice.SampleText
+		}
+		model := opts.Model
+		if model == "" || !voice.SupportsModel(model) {
+			model = voice.BestModel()
+		}
+		request := &TTSRequest{VoiceID: voice.VoiceID, Text: line, Model: model, Language: opts.Language}
+		if opts.AudioFormat != "" {
+			request.Output = &Output{AudioFormat: opts.AudioFormat}
+		}
+
+		wg.Add(1)
+		go func(i int, voice VoiceV2, request *TTSRequest) {
+			defer wg.Done()
+			resp, err := c.TextToSpeech(ctx, request)
+			if err != nil {
+				errs[i] = fmt.Errorf("voice %s: %w", voice.VoiceID, err)
+				return
+			}
+			auditions[i] = Audition{
+				Voice:     voice,
+				Text:      request.Text,
+				Model:     request.Model,
+				Duration:  resp.Duration,
+				Format:    resp.Format,
+				AudioData: resp.AudioData,
+			}
+		}(i, voice, request)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return auditions, nil
+}
@@ -0,0 +1,104 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func auditionServer(t *testing.T) (*httptest.Server, map[string]map[string]interface{}) {
+	var mu sync.Mutex
+	bodies := map[string]map[string]interface{}{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		bodies[body["voice_id"].(string)] = body
+		mu.Unlock()
+		if body["voice_id"] == "broken" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write([]byte(body["voice_id"].(string)))
+	}))
+	return srv, bodies
+}
+
+func TestAuditionVoices_SameLineForEveryVoice(t *testing.T) {
+	srv, bodies := auditionServer(t)
+	defer srv.Close()
+
+	voices := []VoiceV2{
+		{VoiceID: "a", Models: []ModelInfo{{Version: ModelSSFMV21}, {Version: ModelSSFMV30}}},
+		{VoiceID: "b", Models: []ModelInfo{{Version: ModelSSFMV21}}, SampleText: "Welcome aboard."},
+	}
+	auditions, err := newTestClient(srv, "k").AuditionVoices(context.Background(), voices, &AuditionOptions{
+		Model:       ModelSSFMV21,
+		AudioFormat: AudioFormatMP3,
+	})
+	if err != nil {
+		t.Fatalf("AuditionVoices() error = %v", err)
+	}
+	for i, audition := range auditions {
+		if audition.Voice.VoiceID != voices[i].VoiceID || string(audition.AudioData) != voices[i].VoiceID {
+			t.Fatalf("audition %d out of order: %+v", i, audition)
+		}
+		if audition.Text != DefaultAuditionText || audition.Model != ModelSSFMV21 || audition.Format != AudioFormatMP3 {
+			t.Fatalf("unexpected audition %d: %+v", i, audition)
+		}
+	}
+	if output := bodies["a"]["output"].(map[string]interface{}); output["audio_format"] != "mp3" {
+		t.Fatalf("unexpected output: %v", output)
+	}
+}
+
+func TestAuditionVoices_SampleTextAndModelFallback(t *testing.T) {
+	srv, bodies := auditionServer(t)
+	defer srv.Close()
+
+	voices := []VoiceV2{
+		{VoiceID: "a", Models: []ModelInfo{{Version: ModelSSFMV21}}},
+		{VoiceID: "b", Models: []ModelInfo{{Version: ModelSSFMV30}}, SampleText: "Welcome aboard."},
+	}
+	auditions, err := newTestClient(srv, "k").AuditionVoices(context.Background(), voices, &AuditionOptions{
+		Text:          "Testing, one two.",
+		UseSampleText: true,
+		Model:         ModelSSFMV30,
+	})
+	if err != nil {
+		t.Fatalf("AuditionVoices() error = %v", err)
+	}
+	if auditions[0].Text != "Testing, one two." || auditions[0].Model != ModelSSFMV21 {
+		t.Fatalf("unexpected audition a: %+v", auditions[0])
+	}
+	if auditions[1].Text != "Welcome aboard." || auditions[1].Model != ModelSSFMV30 {
+		t.Fatalf("unexpected audition b: %+v", auditions[1])
+	}
+	if _, ok := bodies["a"]["output"]; ok {
+		t.Fatalf("output must be omitted without AudioFormat: %v", bodies["a"])
+	}
+}
+
+func TestAuditionVoices_Errors(t *testing.T) {
+	srv, _ := auditionServer(t)
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+
+	var validation *ValidationError
+	if _, err := c.AuditionVoices(context.Background(), nil, nil); !errors.As(err, &validation) || validation.Field != "voices" {
+		t.Fatalf("expected voices ValidationError, got %v", err)
+	}
+	voices := []VoiceV2{
+		{VoiceID: "a", Models: []ModelInfo{{Version: ModelSSFMV30}}},
+		{VoiceID: "broken", Models: []ModelInfo{{Version: ModelSSFMV30}}},
+	}
+	if _, err := c.AuditionVoices(context.Background(), voices, nil); err == nil || !strings.Contains(err.Error(), "voice broken") {
+		t.Fatalf("expected voice error, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package typecast
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff chooses the delays between retries. Set it as
+// ClientConfig.Backoff to trade the default exponential curve for one that
+// suits the workload, such as short fixed delays for interactive calls or
+// decorrelated jitter for large batches sharing a rate limit. A Backoff is
+// shared by concurrent calls, so it must be safe for concurrent use; the
+// strategies here keep no state.
+type Backoff interface {
+	// Delay returns the delay before retry attempt (0 for the first
+	// retry), given the delay before the previous one (0 for the first).
+	Delay(attempt int, previous time.Duration) time.Duration
+}
+
+// ExponentialBackoff doubles the delay per attempt up to Max, waiting a
+// random delay between half of it and all of it. It is the default Backoff.
+type ExponentialBackoff struct {
+	// Base is the delay before the first retry (optional, defaults to
+	// 500ms)
+	Base time.Duration
+	// Max caps the delay (optional, defaults to 8s)
+	Max time.Duration
+}
+
+// Delay returns the delay before retry attempt.
+func (b ExponentialBackoff) Delay(attempt int, previous time.Duration) time.Duration {
+	shortest, longest := b.DelayRange(attempt)
+	return shortest + time.Duration(rand.Int63n(int64(longest-shortest)+1))
+}
+
+// DelayRange returns the shortest and longest delay Delay chooses before
+// retry attempt.
+func (b ExponentialBackoff) DelayRange(attempt int) (shortest, longest time.Duration) {
+	base, max := backoffBounds(b.Base, b.Max)
+	delay := base << uint(attempt)
+	if delay <= 0 || delay > max {
+		delay = max
+	}
+	return delay / 2, delay
+}
+
+// DecorrelatedJitterBackoff waits a random delay between Base and three
+// times the previous delay, up to Max. Retries of concurrent calls spread
+// out instead of arriving in waves.
+type DecorrelatedJitterBackoff struct {
+	// Base is the shortest delay (optional, defaults to 500ms)
+	Base time.Duration
+	// Max caps the delay (optional, defaults to 8s)
+	Max time.Duration
+}
+
+// Delay returns the delay before retry attempt.
+func (b DecorrelatedJitterBackoff) Delay(attempt int, previous time.Duration) time.Duration {
+	base, max := backoffBounds(b.Base, b.Max)
+	longest := 3 * previous
+	if longest > max {
+		longest = max
+	}
+	if longest < base {
+		longest = base
+	}
+	return base + time.Duration(rand.Int63n(int64(longest-base)+1))
+}
+
+// FixedBackoff waits the same delay before every retry, such as
+// FixedBackoff(200 * time.Millisecond); 0 retries at once.
+type FixedBackoff time.Duration
+
+// Delay returns the fixed delay.
+func (b FixedBackoff) Delay(attempt int, previous time.Duration) time.Duration {
+	return time.Duration(b)
+}
+
+// FibonacciBackoff grows the delay along the Fibonacci sequence (Base,
+// Base, 2×Base, 3×Base, 5×Base, ...) up to Max, more gently than doubling.
+// It has no jitter.
+type FibonacciBackoff struct {
+	// Base is the delay before the first two retries (optional, defaults
+	// to 500ms)
+	Base time.Duration
+	// Max caps the delay (optional, defaults to 8s)
+	Max time.Duration
+}
+
+// Delay returns the delay before retry attempt.
+func (b FibonacciBackoff) Delay(attempt int, previous time.Duration) time.Duration {
+	base, max := backoffBounds(b.Base, b.Max)
+	delay, next := base, base
+	for i := 0; i < attempt && delay < max; i++ {
+		delay, next = next, delay+next
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// backoffBounds returns base and max, or their defaults when not set.
+func backoffBounds(base, max time.Duration) (time.Duration, time.Duration) {
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	if max <= 0 {
+		max = defaultRetryMaxDelay
+	}
+	return base, max
+}
@@ -0,0 +1,91 @@
+package typecast
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff{Base: 100 * time.Millisecond, Max: time.Second}
+	for attempt, longest := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		if d := b.Delay(attempt, 0); d < longest/2 || d > longest {
+			t.Fatalf("attempt %d delay %v outside [%v, %v]", attempt, d, longest/2, longest)
+		}
+		if shortest, got := b.DelayRange(attempt); shortest != longest/2 || got != longest {
+			t.Fatalf("attempt %d range [%v, %v]", attempt, shortest, got)
+		}
+	}
+	if d := (ExponentialBackoff{}).Delay(0, 0); d < defaultRetryBaseDelay/2 || d > defaultRetryBaseDelay {
+		t.Fatalf("default first delay %v", d)
+	}
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	b := DecorrelatedJitterBackoff{Base: 100 * time.Millisecond, Max: time.Second}
+	var previous time.Duration
+	for attempt := 0; attempt < 20; attempt++ {
+		d := b.Delay(attempt, previous)
+		longest := 3 * previous
+		if longest < b.Base {
+			longest = b.Base
+		}
+		if longest > b.Max {
+			longest = b.Max
+		}
+		if d < b.Base || d > longest {
+			t.Fatalf("attempt %d delay %v after %v outside [%v, %v]", attempt, d, previous, b.Base, longest)
+		}
+		previous = d
+	}
+	// A Base above Max waits Base.
+	if d := (DecorrelatedJitterBackoff{Base: 2 * time.Second, Max: time.Second}).Delay(3, time.Second); d != 2*time.Second {
+		t.Fatalf("expected Base, got %v", d)
+	}
+	if d := (DecorrelatedJitterBackoff{}).Delay(0, 0); d != defaultRetryBaseDelay {
+		t.Fatalf("default first delay %v", d)
+	}
+}
+
+func TestFixedAndFibonacciBackoff(t *testing.T) {
+	if d := FixedBackoff(200*time.Millisecond).Delay(7, time.Second); d != 200*time.Millisecond {
+		t.Fatalf("fixed delay %v", d)
+	}
+	b := FibonacciBackoff{Base: 100 * time.Millisecond, Max: 700 * time.Millisecond}
+	for attempt, want := range []time.Duration{100, 100, 200, 300, 500, 700, 700} {
+		if d := b.Delay(attempt, 0); d != want*time.Millisecond {
+			t.Fatalf("attempt %d delay %v, want %v", attempt, d, want*time.Millisecond)
+		}
+	}
+	if d := (FibonacciBackoff{}).Delay(200, 0); d != defaultRetryMaxDelay {
+		t.Fatalf("expected the default cap, got %v", d)
+	}
+}
+
+type recordingBackoff struct {
+	calls [][2]time.Duration
+}
+
+func (b *recordingBackoff) Delay(attempt int, previous time.Duration) time.Duration {
+	b.calls = append(b.calls, [2]time.Duration{time.Duration(attempt), previous})
+	return time.Duration(attempt+1) * time.Millisecond
+}
+
+func TestRetry_UsesBackoff(t *testing.T) {
+	srv, calls := flakyServer(t, 3, http.StatusServiceUnavailable, nil)
+	backoff := &recordingBackoff{}
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, MaxRetries: 3, Backoff: backoff})
+	if err := ttsOnce(c, context.Background()); err != nil {
+		t.Fatalf("expected retries to succeed, got %v", err)
+	}
+	want := [][2]time.Duration{{0, 0}, {1, time.Millisecond}, {2, 2 * time.Millisecond}}
+	if *calls != 4 || len(backoff.calls) != len(want) {
+		t.Fatalf("%d attempts, backoff calls %v", *calls, backoff.calls)
+	}
+	for i := range want {
+		if backoff.calls[i] != want[i] {
+			t.Fatalf("backoff calls %v, want %v", backoff.calls, want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package typecast
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// parseBaseURL validates a base URL and normalizes it without a trailing
+// slash, so request paths can be appended to it. The URL may have a path
+// prefix, such as https://gateway.example.com/typecast, but no query or
+// fragment. An invalid URL is returned trimmed, with the error.
+func parseBaseURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw, newValidationError("base_url", fmt.Sprintf("invalid base URL %q: %v", raw, err))
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return raw, newValidationError("base_url", fmt.Sprintf("invalid base URL %q: must be an absolute http or https URL", raw))
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return raw, newValidationError("base_url", fmt.Sprintf("invalid base URL %q: must not have a query or fragment", raw))
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+	return u.String(), nil
+}
+
+// endpoint returns the URL of an API path, which may have a query, under
+// the base URL. It returns the configuration's validation error, if any.
+func (c *Client) endpoint(path string) (string, error) {
+	if c.configErr != nil {
+		return "", c.configErr
+	}
+	return c.baseURL + "/" + strings.TrimLeft(path, "/"), nil
+}
@@ -0,0 +1,81 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseBaseURL(t *testing.T) {
+	tests := []struct {
+		raw, want string
+		ok        bool
+	}{
+		{"https://api.typecast.ai", "https://api.typecast.ai", true},
+		{" https://api.typecast.ai/ ", "https://api.typecast.ai", true},
+		{"https://gateway.corp/typecast/", "https://gateway.corp/typecast", true},
+		{"http://localhost:8080//", "http://localhost:8080", true},
+		{"https://gateway.corp/a%2Fb/", "https://gateway.corp/a%2Fb", true},
+		{"api.typecast.ai", "", false},
+		{"ftp://api.typecast.ai", "", false},
+		{"https://", "", false},
+		{"https://gateway.corp/?key=1", "", false},
+		{"https://gateway.corp/#top", "", false},
+		{"http://[::1", "", false},
+	}
+	for _, tt := range tests {
+		got, err := parseBaseURL(tt.raw)
+		var validationErr *ValidationError
+		if tt.ok && (err != nil || got != tt.want) {
+			t.Errorf("parseBaseURL(%q) = %q, %v; want %q", tt.raw, got, err, tt.want)
+		}
+		if !tt.ok && (!errors.As(err, &validationErr) || validationErr.Field != "base_url") {
+			t.Errorf("parseBaseURL(%q): expected a base_url validation error, got %v", tt.raw, err)
+		}
+	}
+}
+
+func TestNew_InvalidBaseURL(t *testing.T) {
+	t.Setenv("TYPECAST_API_HOST", "")
+	if _, err := New(&ClientConfig{APIKey: "k", BaseURL: "localhost:8080"}); err == nil {
+		t.Fatal("expected an error for a base URL without a scheme")
+	}
+
+	t.Setenv("TYPECAST_API_HOST", "not a url")
+	if _, err := New(nil); err == nil {
+		t.Fatal("expected an error for an invalid TYPECAST_API_HOST")
+	}
+
+	// NewClient reports the error from each request instead.
+	c := NewClient(&ClientConfig{APIKey: "k"})
+	if _, err := c.GetVoicesV2(context.Background(), nil); err == nil || err.Error() != `invalid base URL "not a url": must be an absolute http or https URL` {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := c.CloneVoice(context.Background(), []byte("x"), "a.wav", "n", "ssfm-v30"); err == nil {
+		t.Fatal("expected a clone error")
+	}
+	if err := c.DeleteVoice(context.Background(), "v"); err == nil {
+		t.Fatal("expected a delete error")
+	}
+}
+
+func TestNew_JoinsPathPrefix(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	c, err := New(&ClientConfig{APIKey: "k", BaseURL: srv.URL + "/typecast/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = c.GetVoicesV2(context.Background(), &VoicesV2Filter{Language: "kor"})
+	_ = c.DeleteVoice(context.Background(), "v")
+	if len(paths) != 2 || paths[0] != "/typecast/v2/voices?language=kor" || paths[1] != "/typecast/v1/voices/v" {
+		t.Fatalf("unexpected paths %v", paths)
+	}
+}
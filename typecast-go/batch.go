@@ -0,0 +1,199 @@
+package typecast
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// defaultBatchWorkers is the number of items RunBatch processes at once when
+// BatchOptions.Workers is not set.
+const defaultBatchWorkers = 4
+
+// BatchItem is one synthesis job in a batch.
+type BatchItem struct {
+	// ID identifies the item in results (optional)
+	ID string
+	// Request is the synthesis request (required)
+	Request *TTSRequest
+	// Output is the path Handle writes the audio to, reported by
+	// NewBatchReport (optional)
+	Output string
+}
+
+// BatchResult is the outcome of one BatchItem.
+type BatchResult struct {
+	// Index is the item's position in the batch
+	Index int
+	// ID is the item's ID
+	ID string
+	// Response is the synthesized audio, if the item succeeded
+	Response *TTSResponse
+	// Resumed reports that Response was loaded from BatchOptions.Store
+	// instead of being synthesized; Handle is not called for it
+	Resumed bool
+	// Err is the item's failure, if any. A panic while processing the item
+	// is reported as a *PanicError.
+	Err error
+	// Elapsed is the time spent synthesizing the item and handling the
+	// response
+	Elapsed time.Duration
+	// Characters is the length of the item's text, once it was synthesized
+	Characters int
+}
+
+// BatchOptions configures RunBatch.
+type BatchOptions struct {
+	// Workers is the number of items processed at once (optional, defaults
+	// to 4). The client's MaxConcurrentRequests still applies.
+	Workers int
+	// Handle is called with each successful response, e.g. to write it to
+	// disk (optional). Its error becomes the item's Err.
+	Handle func(ctx context.Context, item BatchItem, resp *TTSResponse) error
+	// Store records items completed by RunBatch under JobID, after Handle
+	// succeeds, so a rerun of the same batch skips them (optional). Items
+	// are matched by position and request. BatchRunner ignores it.
+	Store JobStore
+	// JobID names the batch in Store (optional)
+	JobID string
+	// VerifyVoices checks every item's voice with Client.VerifyVoices before
+	// any item runs, so a mistyped voice ID fails the whole batch at once
+	// instead of item by item. BatchRunner ignores it (optional)
+	VerifyVoices bool
+}
+
+// PanicError reports a panic recovered while processing a batch item.
+type PanicError struct {
+	// Value is the value passed to panic
+	Value interface{}
+	// Stack is the goroutine's stack trace at the time of the panic
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// RunBatch synthesizes items with a pool of workers and returns one result
+// per item, in order. A failing or panicking item does not stop the others;
+// items not started before ctx is done fail with the context's error.
+func (c *Client) RunBatch(ctx context.Context, items []BatchItem, opts *BatchOptions) []BatchResult {
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return c.runBatch(ctx, items, indexes, opts)
+}
+
+// runBatch processes the items at indexes and returns one result for each,
+// in the order of indexes.
+func (c *Client) runBatch(ctx context.Context, items []BatchItem, indexes []int, opts *BatchOptions) []BatchResult {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+
+	results := make([]BatchResult, len(indexes))
+	done, err := loadJob(opts.Store, opts.JobID)
+	if err == nil && opts.VerifyVoices {
+		selected := make([]BatchItem, len(indexes))
+		for i, index := range indexes {
+			selected[i] = items[index]
+		}
+		err = c.verifyBatchVoices(ctx, selected)
+	}
+	if err != nil {
+		for i, index := range indexes {
+			results[i] = BatchResult{Index: index, ID: items[index].ID, Err: err}
+		}
+		return results
+	}
+	positions := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range positions {
+				index := indexes[i]
+				results[i] = c.runBatchItem(ctx, index, items[index], opts, done)
+			}
+		}()
+	}
+	for i, index := range indexes {
+		if ctx.Err() != nil {
+			results[i] = BatchResult{Index: index, ID: items[index].ID, Err: ctx.Err()}
+			continue
+		}
+		positions <- i
+	}
+	close(positions)
+	wg.Wait()
+	return results
+}
+
+// verifyBatchVoices verifies the voices of the items' requests.
+func (c *Client) verifyBatchVoices(ctx context.Context, items []BatchItem) error {
+	var voiceIDs []string
+	for _, item := range items {
+		if item.Request != nil {
+			voiceIDs = append(voiceIDs, item.Request.VoiceID)
+		}
+	}
+	return c.VerifyVoices(ctx, voiceIDs)
+}
+
+// runBatchItem resumes item from the job store when it was completed by an
+// earlier run, and otherwise processes it and records it in the store.
+func (c *Client) runBatchItem(ctx context.Context, index int, item BatchItem, opts *BatchOptions, done map[int]JobSegment) BatchResult {
+	if opts.Store == nil {
+		return c.processBatchItem(ctx, index, item, opts)
+	}
+	key := requestKey(item.Request)
+	if segment, ok := done[index]; ok && segment.Key == key {
+		return BatchResult{Index: index, ID: item.ID, Response: segment.response(), Resumed: true}
+	}
+	result := c.processBatchItem(ctx, index, item, opts)
+	if result.Err == nil {
+		resp := result.Response
+		segment := JobSegment{Index: index, Key: key, Duration: resp.Duration, Format: resp.Format, Audio: resp.AudioData}
+		if err := opts.Store.Save(opts.JobID, segment); err != nil {
+			result.Err = fmt.Errorf("failed to save item: %w", err)
+		}
+	}
+	return result
+}
+
+// processBatchItem synthesizes one item, converting a panic into a
+// *PanicError so a single malformed item cannot kill the batch.
+func (c *Client) processBatchItem(ctx context.Context, index int, item BatchItem, opts *BatchOptions) (result BatchResult) {
+	result = BatchResult{Index: index, ID: item.ID}
+	start := c.clock.Now()
+	defer func() {
+		result.Elapsed = c.clock.Now().Sub(start)
+		if v := recover(); v != nil {
+			result.Response = nil
+			result.Err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	resp, err := c.TextToSpeech(ctx, item.Request)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Characters = utf8.RuneCountInString(item.Request.Text)
+	if opts.Handle != nil {
+		if err := opts.Handle(ctx, item, resp); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+	result.Response = resp
+	return result
+}
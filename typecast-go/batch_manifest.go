@@ -0,0 +1,133 @@
+package typecast
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// BatchManifest turns the rows of a CSV or JSONL manifest into batch items.
+// A row either has its own text or names a template whose fields it fills,
+// so a campaign of near-identical messages is one template and a row of
+// variables per recipient:
+//
+//	id,template,name,amount,date
+//	r1,receipt,김민수,"12,000원",3월 15일
+//
+// The columns (or JSON keys) id, text, template, voice_id, and output are
+// the item's; all others are template variables.
+type BatchManifest struct {
+	// Request holds the settings of every item, which a row's text and
+	// voice_id override (required)
+	Request TTSRequest
+	// Templates maps template names to texts with fields such as "{name}",
+	// filled as by FillKoreanTemplate so Korean particles fit each value
+	// (optional)
+	Templates map[string]string
+	// Template is the name of the template of rows without text or a
+	// template of their own (optional)
+	Template string
+}
+
+// ReadCSV reads items from CSV rows under a header row naming the columns.
+func (m *BatchManifest) ReadCSV(r io.Reader) ([]BatchItem, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var items []BatchItem
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			row[strings.TrimSpace(name)] = record[i]
+		}
+		item, err := m.item(row)
+		if err != nil {
+			return nil, fmt.Errorf("manifest line %d: %w", line, err)
+		}
+		items = append(items, item)
+	}
+}
+
+// ReadJSONL reads items from JSON objects, one per line, such as
+// {"id": "r1", "template": "receipt", "name": "김민수", "amount": 12000}.
+// Numbers and booleans are used as written; blank lines are skipped.
+func (m *BatchManifest) ReadJSONL(r io.Reader) ([]BatchItem, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var items []BatchItem
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		decoder.UseNumber()
+		var fields map[string]interface{}
+		if err := decoder.Decode(&fields); err != nil {
+			return nil, fmt.Errorf("manifest line %d: %w", line, err)
+		}
+		row := make(map[string]string, len(fields))
+		for name, value := range fields {
+			switch value.(type) {
+			case string, json.Number, bool:
+				row[name] = fmt.Sprint(value)
+			case nil:
+			default:
+				return nil, fmt.Errorf("manifest line %d: field %q must be a string, number, or boolean", line, name)
+			}
+		}
+		item, err := m.item(row)
+		if err != nil {
+			return nil, fmt.Errorf("manifest line %d: %w", line, err)
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return items, nil
+}
+
+// item builds the batch item of one row.
+func (m *BatchManifest) item(row map[string]string) (BatchItem, error) {
+	request := m.Request
+	request.Text = row["text"]
+	if voiceID := row["voice_id"]; voiceID != "" {
+		request.VoiceID = voiceID
+	}
+	name := row["template"]
+	if name == "" && request.Text == "" {
+		name = m.Template
+	}
+	if name != "" {
+		template, ok := m.Templates[name]
+		if !ok {
+			return BatchItem{}, fmt.Errorf("unknown template %q", name)
+		}
+		text, err := FillKoreanTemplate(template, row)
+		if err != nil {
+			return BatchItem{}, err
+		}
+		request.Text = text
+	}
+	if strings.TrimSpace(request.Text) == "" {
+		return BatchItem{}, errors.New("row has no text or template")
+	}
+	return BatchItem{ID: row["id"], Request: &request, Output: row["output"]}, nil
+}
@@ -0,0 +1,89 @@
+package typecast
+
+import (
+	"strings"
+	"testing"
+)
+
+func testManifest() *BatchManifest {
+	return &BatchManifest{
+		Request: TTSRequest{VoiceID: "v", Model: ModelSSFMV30, Language: "kor"},
+		Templates: map[string]string{
+			"receipt":  "{name}님, {amount}이(가) {date}에 결제되었습니다.",
+			"reminder": "{name}을 잊지 마세요.",
+		},
+		Template: "receipt",
+	}
+}
+
+func TestBatchManifestReadCSV(t *testing.T) {
+	manifest := "id,template,text,voice_id,name,amount,date\n" +
+		"r1,receipt,,,김민수,\"12,000원\",3월 15일\n" +
+		"r2,,,,이영희,500원,3월 16일\n" +
+		"r3,reminder,,other,약속,,\n" +
+		"r4,,공지입니다.,,,,\n"
+	items, err := testManifest().ReadCSV(strings.NewReader(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ id, voice, text string }{
+		{"r1", "v", "김민수님, 12,000원이 3월 15일에 결제되었습니다."},
+		{"r2", "v", "이영희님, 500원이 3월 16일에 결제되었습니다."},
+		{"r3", "other", "약속을 잊지 마세요."},
+		{"r4", "v", "공지입니다."},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d items", len(items))
+	}
+	for i, w := range want {
+		req := items[i].Request
+		if items[i].ID != w.id || req.VoiceID != w.voice || req.Text != w.text || req.Model != ModelSSFMV30 || req.Language != "kor" {
+			t.Errorf("item %d = %s %+v", i, items[i].ID, req)
+		}
+	}
+
+	if items, err := testManifest().ReadCSV(strings.NewReader("")); err != nil || items != nil {
+		t.Fatalf("empty manifest: %v %v", items, err)
+	}
+}
+
+func TestBatchManifestReadJSONL(t *testing.T) {
+	manifest := `{"id": "r1", "output": "r1.wav", "name": "Anna", "amount": 12000, "date": "today", "vip": true}
+
+{"id": "r2", "template": "reminder", "name": "우산", "note": null}
+`
+	items, err := testManifest().ReadJSONL(strings.NewReader(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Output != "r1.wav" || items[0].Request.Text != "Anna님, 12000이 today에 결제되었습니다." || items[1].Request.Text != "우산을 잊지 마세요." {
+		t.Fatalf("items %+v %+v", items[0].Request, items[1].Request)
+	}
+}
+
+func TestBatchManifestErrors(t *testing.T) {
+	m := testManifest()
+	csvTests := []struct{ manifest, err string }{
+		{"id,\"name\n", "failed to read manifest"},
+		{"id,name\nr1,a,b\n", "failed to read manifest"},
+		{"id,template\nr1,nope\n", "manifest line 2: unknown template \"nope\""},
+		{"id,name\nr1,김민수\n", "manifest line 2: template field {amount} has no value"},
+	}
+	for _, tt := range csvTests {
+		if _, err := m.ReadCSV(strings.NewReader(tt.manifest)); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ReadCSV(%q) = %v, want %q", tt.manifest, err, tt.err)
+		}
+	}
+	jsonlTests := []struct{ manifest, err string }{
+		{`{"id": "r1"`, "manifest line 1"},
+		{"\n" + `{"template": "reminder", "name": ["a"]}`, `manifest line 2: field "name" must be`},
+		{`{"text": " ", "template": ""}`, "manifest line 1: row has no text or template"},
+		{`{"template": "nope"}`, `unknown template "nope"`},
+		{`{"text": "` + strings.Repeat("a", 2*1024*1024) + `"}`, "failed to read manifest"},
+	}
+	for _, tt := range jsonlTests {
+		if _, err := (&BatchManifest{Templates: m.Templates}).ReadJSONL(strings.NewReader(tt.manifest)); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ReadJSONL(%.40q) = %v, want %q", tt.manifest, err, tt.err)
+		}
+	}
+}
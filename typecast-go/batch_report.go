@@ -0,0 +1,191 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+)
+
+// ErrorClass is a coarse category of a failure, for reports and retry
+// decisions.
+type ErrorClass string
+
+const (
+	// ErrorValidation means the request was rejected as invalid, locally or
+	// by the API (400, 422).
+	ErrorValidation ErrorClass = "validation"
+	// ErrorAuth means the API key was missing or refused (401, 403).
+	ErrorAuth ErrorClass = "auth"
+	// ErrorNotFound means the voice or resource does not exist (404).
+	ErrorNotFound ErrorClass = "not_found"
+	// ErrorCredits means the account is out of credits (402).
+	ErrorCredits ErrorClass = "insufficient_credits"
+	// ErrorRateLimited means the request rate was exceeded (429).
+	ErrorRateLimited ErrorClass = "rate_limited"
+	// ErrorServer means the API failed or was overloaded (5xx).
+	ErrorServer ErrorClass = "server"
+	// ErrorAPI means the API answered with another error status.
+	ErrorAPI ErrorClass = "api"
+	// ErrorNetwork means the API could not be reached.
+	ErrorNetwork ErrorClass = "network"
+	// ErrorCanceled means the context was canceled or timed out first.
+	ErrorCanceled ErrorClass = "canceled"
+	// ErrorPanic means processing panicked; see PanicError.
+	ErrorPanic ErrorClass = "panic"
+	// ErrorOther covers the remaining failures, such as a Handle error.
+	ErrorOther ErrorClass = "other"
+)
+
+// ClassifyError returns the class of err and whether trying again later
+// may succeed: rate limits, server and network errors, and cancellations
+// are retryable. It returns "" and false for a nil err.
+func ClassifyError(err error) (ErrorClass, bool) {
+	var apiErr *APIError
+	var validationErr *ValidationError
+	var panicErr *PanicError
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return "", false
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ErrorCanceled, true
+	case errors.As(err, &validationErr):
+		return ErrorValidation, false
+	case errors.As(err, &panicErr):
+		return ErrorPanic, false
+	case errors.As(err, &apiErr):
+		switch {
+		case apiErr.IsBadRequest(), apiErr.IsValidationError():
+			return ErrorValidation, false
+		case apiErr.IsUnauthorized(), apiErr.IsForbidden():
+			return ErrorAuth, false
+		case apiErr.IsNotFound():
+			return ErrorNotFound, false
+		case apiErr.IsPaymentRequired():
+			return ErrorCredits, false
+		case apiErr.IsRateLimited():
+			return ErrorRateLimited, true
+		case apiErr.IsServerError():
+			return ErrorServer, true
+		}
+		return ErrorAPI, false
+	case errors.As(err, &netErr):
+		return ErrorNetwork, true
+	}
+	return ErrorOther, false
+}
+
+// BatchItemStatus is the outcome of a batch item in a BatchReport.
+type BatchItemStatus string
+
+const (
+	// BatchItemSucceeded means the item was synthesized and handled, or
+	// resumed from the job store.
+	BatchItemSucceeded BatchItemStatus = "succeeded"
+	// BatchItemFailed means the item failed; see its error class.
+	BatchItemFailed BatchItemStatus = "failed"
+)
+
+// BatchItemReport is the outcome of one batch item.
+type BatchItemReport struct {
+	// Index is the item's position in the batch
+	Index int `json:"index"`
+	// ID is the item's ID
+	ID string `json:"id,omitempty"`
+	// Status is the item's outcome
+	Status BatchItemStatus `json:"status"`
+	// Resumed reports that the item was loaded from the job store
+	Resumed bool `json:"resumed,omitempty"`
+	// ErrorClass is the class of the item's failure, if any
+	ErrorClass ErrorClass `json:"error_class,omitempty"`
+	// Error describes the item's failure, if any
+	Error string `json:"error,omitempty"`
+	// Retryable reports that a failed item may succeed if run again
+	Retryable bool `json:"retryable,omitempty"`
+	// Elapsed is the time spent on the item, in seconds
+	Elapsed float64 `json:"elapsed"`
+	// AudioDuration is the audio's duration in seconds, if the item
+	// succeeded
+	AudioDuration float64 `json:"audio_duration,omitempty"`
+	// Output is the item's BatchItem.Output, if it succeeded
+	Output string `json:"output,omitempty"`
+}
+
+// BatchReport is the outcome of a batch, item by item in input order, for
+// operators to review or to rerun the failed items.
+type BatchReport struct {
+	// Items holds one report per item, in input order
+	Items []BatchItemReport `json:"items"`
+	// Succeeded is the number of items that succeeded
+	Succeeded int `json:"succeeded"`
+	// Failed is the number of items that failed
+	Failed int `json:"failed"`
+}
+
+// NewBatchReport builds the report of a batch from its items and the
+// results RunBatch returned for them, or those BatchRunner reported, which
+// are sorted into input order by their Index.
+func NewBatchReport(items []BatchItem, results []BatchResult) *BatchReport {
+	report := &BatchReport{Items: make([]BatchItemReport, len(results))}
+	for i, result := range results {
+		item := BatchItemReport{
+			Index:   result.Index,
+			ID:      result.ID,
+			Status:  BatchItemSucceeded,
+			Resumed: result.Resumed,
+			Elapsed: result.Elapsed.Seconds(),
+		}
+		if result.Err != nil {
+			item.Status, item.Error = BatchItemFailed, result.Err.Error()
+			item.ErrorClass, item.Retryable = ClassifyError(result.Err)
+			report.Failed++
+		} else {
+			if result.Response != nil {
+				item.AudioDuration = result.Response.Duration
+			}
+			if result.Index >= 0 && result.Index < len(items) {
+				item.Output = items[result.Index].Output
+			}
+			report.Succeeded++
+		}
+		report.Items[i] = item
+	}
+	sort.SliceStable(report.Items, func(i, j int) bool { return report.Items[i].Index < report.Items[j].Index })
+	return report
+}
+
+// JSON returns the report as indented JSON.
+func (r *BatchReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// batchReportColumns is the header row of BatchReport.CSV.
+var batchReportColumns = []string{"index", "id", "status", "resumed", "error_class", "retryable", "elapsed", "audio_duration", "output", "error"}
+
+// CSV returns the items of the report as CSV with a header row.
+func (r *BatchReport) CSV() ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	_ = w.Write(batchReportColumns)
+	for _, item := range r.Items {
+		_ = w.Write([]string{
+			strconv.Itoa(item.Index),
+			item.ID,
+			string(item.Status),
+			strconv.FormatBool(item.Resumed),
+			string(item.ErrorClass),
+			strconv.FormatBool(item.Retryable),
+			strconv.FormatFloat(item.Elapsed, 'f', 3, 64),
+			strconv.FormatFloat(item.AudioDuration, 'f', 3, 64),
+			item.Output,
+			item.Error,
+		})
+	}
+	w.Flush()
+	return b.Bytes(), w.Error()
+}
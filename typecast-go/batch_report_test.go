@@ -0,0 +1,113 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err       error
+		class     ErrorClass
+		retryable bool
+	}{
+		{nil, "", false},
+		{context.Canceled, ErrorCanceled, true},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), ErrorCanceled, true},
+		{newValidationError("text", "text is required"), ErrorValidation, false},
+		{&PanicError{Value: "boom"}, ErrorPanic, false},
+		{NewAPIError(400, ""), ErrorValidation, false},
+		{NewAPIError(422, ""), ErrorValidation, false},
+		{NewAPIError(401, ""), ErrorAuth, false},
+		{NewAPIError(403, ""), ErrorAuth, false},
+		{NewAPIError(404, ""), ErrorNotFound, false},
+		{NewAPIError(402, ""), ErrorCredits, false},
+		{NewAPIError(429, ""), ErrorRateLimited, true},
+		{NewAPIError(503, ""), ErrorServer, true},
+		{NewAPIError(409, ""), ErrorAPI, false},
+		{&timeoutError{}, ErrorNetwork, true},
+		{errors.New("disk full"), ErrorOther, false},
+	}
+	for _, tt := range tests {
+		if class, retryable := ClassifyError(tt.err); class != tt.class || retryable != tt.retryable {
+			t.Errorf("ClassifyError(%v) = %s, %v; want %s, %v", tt.err, class, retryable, tt.class, tt.retryable)
+		}
+	}
+}
+
+// timeoutError is a net.Error.
+type timeoutError struct{}
+
+func (*timeoutError) Error() string   { return "i/o timeout" }
+func (*timeoutError) Timeout() bool   { return true }
+func (*timeoutError) Temporary() bool { return true }
+
+func TestBatchReport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Text == "bad" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		w.Header().Set("X-Audio-Duration", "0.1")
+		_, _ = w.Write(makeTestWAV(make([]byte, 1600), 8000))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	items := []BatchItem{
+		{ID: "a", Request: &TTSRequest{VoiceID: "v", Text: "Hello.", Model: ModelSSFMV30}, Output: "out/a.wav"},
+		{ID: "b", Request: &TTSRequest{VoiceID: "v", Text: "bad", Model: ModelSSFMV30}, Output: "out/b.wav"},
+		{ID: "c", Request: &TTSRequest{VoiceID: "v", Text: "Write fails.", Model: ModelSSFMV30}},
+	}
+	results := c.RunBatch(context.Background(), items, &BatchOptions{
+		Handle: func(ctx context.Context, item BatchItem, resp *TTSResponse) error {
+			if item.ID == "c" {
+				return errors.New("disk full")
+			}
+			return nil
+		},
+	})
+	// Results reported out of order, as BatchRunner does, are sorted.
+	results[0], results[2] = results[2], results[0]
+	report := NewBatchReport(items, results)
+	if report.Succeeded != 1 || report.Failed != 2 || len(report.Items) != 3 {
+		t.Fatalf("report %+v", report)
+	}
+	a, b, c2 := report.Items[0], report.Items[1], report.Items[2]
+	if a.ID != "a" || a.Status != BatchItemSucceeded || a.Output != "out/a.wav" || a.AudioDuration != 0.1 || a.Elapsed <= 0 {
+		t.Errorf("item a: %+v", a)
+	}
+	if b.Status != BatchItemFailed || b.ErrorClass != ErrorValidation || b.Retryable || b.Output != "" || b.Error == "" {
+		t.Errorf("item b: %+v", b)
+	}
+	if c2.ErrorClass != ErrorOther || c2.Error != "disk full" {
+		t.Errorf("item c: %+v", c2)
+	}
+
+	data, err := report.JSON()
+	var decoded BatchReport
+	if err != nil || json.Unmarshal(data, &decoded) != nil || decoded.Items[1].ErrorClass != ErrorValidation {
+		t.Fatalf("JSON: %s %v", data, err)
+	}
+	csvData, err := report.CSV()
+	lines := strings.Split(strings.TrimSpace(string(csvData)), "\n")
+	if err != nil || len(lines) != 4 || lines[0] != "index,id,status,resumed,error_class,retryable,elapsed,audio_duration,output,error" ||
+		!strings.HasPrefix(lines[1], "0,a,succeeded,false,,false,") || !strings.HasSuffix(lines[1], ",0.100,out/a.wav,") ||
+		!strings.HasPrefix(lines[3], "2,c,failed,false,other,false,") {
+		t.Fatalf("CSV:\n%s", csvData)
+	}
+
+	// Resumed items report no elapsed time, and results need no item.
+	resumed := NewBatchReport(nil, []BatchResult{{Index: 0, Resumed: true, Response: &TTSResponse{Duration: 2}}, {Index: 1}})
+	if item := resumed.Items[0]; !item.Resumed || item.AudioDuration != 2 || item.Output != "" || item.Elapsed != 0 {
+		t.Fatalf("resumed: %+v", item)
+	}
+}
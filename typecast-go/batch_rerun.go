@@ -0,0 +1,86 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// LoadBatchReport reads a report written by BatchReport.JSON.
+func LoadBatchReport(r io.Reader) (*BatchReport, error) {
+	var report BatchReport
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to decode batch report: %w", err)
+	}
+	return &report, nil
+}
+
+// LoadBatchReportFile reads a JSON batch report from path.
+func LoadBatchReportFile(path string) (*BatchReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open batch report: %w", err)
+	}
+	defer f.Close()
+	return LoadBatchReport(f)
+}
+
+// Pending returns the indexes of the items r does not report as succeeded:
+// those that failed, those missing from r, as after a run that was killed,
+// and those whose ID no longer matches, as after the manifest changed.
+func (r *BatchReport) Pending(items []BatchItem) []int {
+	succeeded := make(map[int]string, r.Succeeded)
+	for _, item := range r.Items {
+		if item.Status == BatchItemSucceeded {
+			succeeded[item.Index] = item.ID
+		}
+	}
+	var pending []int
+	for i, item := range items {
+		if id, ok := succeeded[i]; !ok || id != item.ID {
+			pending = append(pending, i)
+		}
+	}
+	return pending
+}
+
+// Merge returns a report of items that takes the outcomes in results, such
+// as those of RerunBatch, over those in r.
+func (r *BatchReport) Merge(items []BatchItem, results []BatchResult) *BatchReport {
+	rerun := NewBatchReport(items, results)
+	merged := make(map[int]BatchItemReport, len(r.Items)+len(rerun.Items))
+	for _, item := range r.Items {
+		merged[item.Index] = item
+	}
+	for _, item := range rerun.Items {
+		merged[item.Index] = item
+	}
+	var entries []BatchItemReport
+	for i := range items {
+		if item, ok := merged[i]; ok {
+			entries = append(entries, item)
+		}
+	}
+	report := &BatchReport{Items: entries}
+	for _, item := range entries {
+		if item.Status == BatchItemSucceeded {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+	}
+	return report
+}
+
+// RerunBatch runs the items prior does not report as succeeded, as listed
+// by Pending, so a batch that partly failed is finished without
+// synthesizing it again. It returns one result per item run, with the
+// item's Index in items; Merge them into prior for the batch's report:
+//
+//	results := client.RerunBatch(ctx, items, prior, opts)
+//	report := prior.Merge(items, results)
+func (c *Client) RerunBatch(ctx context.Context, items []BatchItem, prior *BatchReport, opts *BatchOptions) []BatchResult {
+	return c.runBatch(ctx, items, prior.Pending(items), opts)
+}
@@ -0,0 +1,112 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRerunBatch(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	failing := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		sent = append(sent, body.Text)
+		fail := failing && body.Text == "flaky"
+		mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write(makeTestWAV(make([]byte, 1600), 8000))
+	}))
+	defer srv.Close()
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, MaxRetries: -1})
+	var items []BatchItem
+	for _, text := range []string{"one", "flaky", "three", "four"} {
+		items = append(items, BatchItem{ID: text, Request: &TTSRequest{VoiceID: "v", Text: text, Model: ModelSSFMV30}})
+	}
+
+	// The first run covered the first three items before it was killed.
+	first := NewBatchReport(items, c.RunBatch(context.Background(), items[:3], nil))
+	data, _ := first.JSON()
+	path := filepath.Join(t.TempDir(), "report.json")
+	_ = os.WriteFile(path, data, 0644)
+	prior, err := LoadBatchReportFile(path)
+	if err != nil || prior.Failed != 1 {
+		t.Fatalf("LoadBatchReportFile = %+v, %v", prior, err)
+	}
+	if pending := prior.Pending(items); !reflect.DeepEqual(pending, []int{1, 3}) {
+		t.Fatalf("Pending = %v", pending)
+	}
+
+	mu.Lock()
+	sent, failing = nil, false
+	mu.Unlock()
+	var handled []string
+	results := c.RerunBatch(context.Background(), items, prior, &BatchOptions{Workers: 1, Handle: func(ctx context.Context, item BatchItem, resp *TTSResponse) error {
+		handled = append(handled, item.ID)
+		return nil
+	}})
+	if len(results) != 2 || results[0].Index != 1 || results[1].Index != 3 || results[1].ID != "four" {
+		t.Fatalf("results %+v", results)
+	}
+	if !reflect.DeepEqual(sent, []string{"flaky", "four"}) || !reflect.DeepEqual(handled, []string{"flaky", "four"}) {
+		t.Fatalf("sent %v, handled %v", sent, handled)
+	}
+	report := prior.Merge(items, results)
+	if report.Succeeded != 4 || report.Failed != 0 || len(report.Items) != 4 || report.Items[1].Status != BatchItemSucceeded || report.Items[3].ID != "four" {
+		t.Fatalf("merged %+v", report)
+	}
+	if pending := report.Pending(items); pending != nil {
+		t.Fatalf("pending after rerun: %v", pending)
+	}
+
+	// An item whose ID changed is run again.
+	renamed := append([]BatchItem(nil), items...)
+	renamed[0].ID = "uno"
+	if pending := report.Pending(renamed); !reflect.DeepEqual(pending, []int{0}) {
+		t.Fatalf("Pending after rename = %v", pending)
+	}
+	if merged := report.Merge(items, []BatchResult{{Index: 2, ID: "three", Err: context.Canceled}}); merged.Succeeded != 3 || merged.Failed != 1 || merged.Items[2].ErrorClass != ErrorCanceled {
+		t.Fatalf("merged failure %+v", merged)
+	}
+	// Entries for items no longer in the batch are dropped.
+	if merged := report.Merge(items[:2], nil); len(merged.Items) != 2 || merged.Succeeded != 2 {
+		t.Fatalf("merged %+v", merged)
+	}
+}
+
+func TestRerunBatchVerifiesPendingVoices(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	items := []BatchItem{{ID: "a", Request: &TTSRequest{VoiceID: "v1"}}, {ID: "b", Request: &TTSRequest{VoiceID: "v2"}}}
+	prior := &BatchReport{Items: []BatchItemReport{{Index: 0, ID: "a", Status: BatchItemSucceeded}}, Succeeded: 1}
+	results := c.RerunBatch(context.Background(), items, prior, &BatchOptions{VerifyVoices: true})
+	if len(results) != 1 || results[0].Index != 1 || results[0].Err == nil {
+		t.Fatalf("results %+v", results)
+	}
+}
+
+func TestLoadBatchReportErrors(t *testing.T) {
+	if _, err := LoadBatchReportFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected an open error")
+	}
+	if _, err := LoadBatchReport(strings.NewReader("{")); err == nil {
+		t.Fatal("expected a decode error")
+	}
+}
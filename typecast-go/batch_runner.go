@@ -0,0 +1,154 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrRunnerClosed is returned by BatchRunner.Submit after Shutdown.
+var ErrRunnerClosed = errors.New("typecast: batch runner is shut down")
+
+// BatchSummary counts the items a BatchRunner has processed.
+type BatchSummary struct {
+	// Processed is the number of items accepted and completed
+	Processed int
+	// Succeeded is the number of items that produced audio
+	Succeeded int
+	// Failed is the number of items that returned an error, including panics
+	Failed int
+	// Panicked is the number of failed items that panicked
+	Panicked int
+	// AudioSeconds is the total duration of the audio produced
+	AudioSeconds float64
+}
+
+// BatchRunner is a long-lived worker pool for services that synthesize a
+// continuous stream of items. Use Submit to enqueue work and Shutdown to
+// drain it, e.g. during a rolling restart.
+type BatchRunner struct {
+	client   *Client
+	opts     BatchOptions
+	onResult func(BatchResult)
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	jobs    chan BatchItem
+	closing chan struct{}
+	once    sync.Once
+	workers sync.WaitGroup
+
+	mu      sync.Mutex
+	next    int
+	summary BatchSummary
+}
+
+// NewBatchRunner starts opts.Workers workers (default 4). Items are
+// synthesized with a context derived from ctx; canceling it aborts all work.
+// onResult, if not nil, is called from a worker with each item's result.
+func (c *Client) NewBatchRunner(ctx context.Context, opts *BatchOptions, onResult func(BatchResult)) *BatchRunner {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	r := &BatchRunner{
+		client:   c,
+		opts:     *opts,
+		onResult: onResult,
+		ctx:      ctx,
+		cancel:   cancel,
+		jobs:     make(chan BatchItem),
+		closing:  make(chan struct{}),
+	}
+	r.workers.Add(workers)
+	for w := 0; w < workers; w++ {
+		go r.work()
+	}
+	return r
+}
+
+// Submit hands item to a worker, blocking while all workers are busy. It
+// returns ErrRunnerClosed once Shutdown has been called, or ctx's error if
+// ctx is done first.
+func (r *BatchRunner) Submit(ctx context.Context, item BatchItem) error {
+	select {
+	case <-r.closing:
+		return ErrRunnerClosed
+	default:
+	}
+	select {
+	case r.jobs <- item:
+		return nil
+	case <-r.closing:
+		return ErrRunnerClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown stops accepting new items and waits for in-flight items,
+// including their Handle and result callbacks, to finish. If ctx is done
+// first, in-flight requests are canceled and ctx's error is returned with
+// the summary so far.
+func (r *BatchRunner) Shutdown(ctx context.Context) (BatchSummary, error) {
+	r.once.Do(func() { close(r.closing) })
+	done := make(chan struct{})
+	go func() {
+		r.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		r.cancel()
+		return r.Summary(), nil
+	case <-ctx.Done():
+		r.cancel()
+		return r.Summary(), ctx.Err()
+	}
+}
+
+// Summary returns the counts of items completed so far.
+func (r *BatchRunner) Summary() BatchSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.summary
+}
+
+func (r *BatchRunner) work() {
+	defer r.workers.Done()
+	for {
+		select {
+		case item := <-r.jobs:
+			r.mu.Lock()
+			index := r.next
+			r.next++
+			r.mu.Unlock()
+			r.record(r.client.processBatchItem(r.ctx, index, item, &r.opts))
+		case <-r.closing:
+			return
+		}
+	}
+}
+
+func (r *BatchRunner) record(result BatchResult) {
+	r.mu.Lock()
+	r.summary.Processed++
+	if result.Err != nil {
+		r.summary.Failed++
+		var panicErr *PanicError
+		if errors.As(result.Err, &panicErr) {
+			r.summary.Panicked++
+		}
+	} else {
+		r.summary.Succeeded++
+		r.summary.AudioSeconds += result.Response.Duration
+	}
+	r.mu.Unlock()
+	if r.onResult != nil {
+		r.onResult(result)
+	}
+}
@@ -0,0 +1,116 @@
+package typecast
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatchRunner_ShutdownDrainsInFlightItems(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.Header().Set("Content-Type", "audio/wav")
+		w.Header().Set("X-Audio-Duration", "2")
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var results []BatchResult
+	runner := newTestClient(srv, "k").NewBatchRunner(context.Background(), &BatchOptions{
+		Workers: 2,
+		Handle: func(ctx context.Context, item BatchItem, resp *TTSResponse) error {
+			if item.ID == "boom" {
+				panic("bad item")
+			}
+			return nil
+		},
+	}, func(result BatchResult) {
+		mu.Lock()
+		results = append(results, result)
+		mu.Unlock()
+	})
+
+	ctx := context.Background()
+	for _, item := range batchItems("a", "boom") {
+		if err := runner.Submit(ctx, item); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+	<-started
+	<-started
+
+	// Both workers are busy: a further Submit blocks until its context ends.
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := runner.Submit(short, batchItems("c")[0]); err != context.DeadlineExceeded {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+	summary, err := runner.Shutdown(ctx)
+	if err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	want := BatchSummary{Processed: 2, Succeeded: 1, Failed: 1, Panicked: 1, AudioSeconds: 2}
+	if summary != want {
+		t.Fatalf("summary = %+v, want %+v", summary, want)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results before Shutdown returned, got %d", len(results))
+	}
+	if err := runner.Submit(ctx, batchItems("d")[0]); err != ErrRunnerClosed {
+		t.Fatalf("expected ErrRunnerClosed, got %v", err)
+	}
+}
+
+func TestBatchRunner_ShutdownDeadlineCancelsWork(t *testing.T) {
+	stop := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stop
+	}))
+	defer srv.Close()
+	defer close(stop)
+
+	errs := make(chan error, 1)
+	runner := newTestClient(srv, "k").NewBatchRunner(context.Background(), &BatchOptions{Workers: 1}, func(result BatchResult) {
+		errs <- result.Err
+	})
+	if err := runner.Submit(context.Background(), batchItems("slow")[0]); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	blocked := make(chan error, 1)
+	go func() { blocked <- runner.Submit(context.Background(), batchItems("queued")[0]) }()
+	time.Sleep(10 * time.Millisecond) // let the second Submit block on the busy worker
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := runner.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+	if err := <-blocked; err != ErrRunnerClosed {
+		t.Fatalf("blocked Submit: expected ErrRunnerClosed, got %v", err)
+	}
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatal("expected the in-flight item to fail after cancellation")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("in-flight item was not canceled")
+	}
+	if summary := runner.Summary(); summary.Failed != 1 {
+		t.Fatalf("summary = %+v", summary)
+	}
+
+	idle := newTestClient(srv, "k").NewBatchRunner(context.Background(), nil, nil)
+	if summary, err := idle.Shutdown(context.Background()); err != nil || summary != (BatchSummary{}) {
+		t.Fatalf("idle Shutdown() = %+v, %v", summary, err)
+	}
+}
@@ -0,0 +1,89 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func batchServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/wav")
+		w.Header().Set("X-Audio-Duration", "1.5")
+		_, _ = w.Write([]byte("audio"))
+	}))
+}
+
+func batchItems(texts ...string) []BatchItem {
+	items := make([]BatchItem, len(texts))
+	for i, text := range texts {
+		items[i] = BatchItem{ID: text, Request: &TTSRequest{VoiceID: "v", Text: text, Model: ModelSSFMV30}}
+	}
+	return items
+}
+
+func TestRunBatch_ResultsInOrder(t *testing.T) {
+	srv := batchServer()
+	defer srv.Close()
+
+	items := batchItems("a", "b", "c", "d", "e")
+	items = append(items, BatchItem{ID: "nil"})
+	results := newTestClient(srv, "k").RunBatch(context.Background(), items, &BatchOptions{Workers: 2})
+	for i, result := range results[:5] {
+		if result.Index != i || result.ID != items[i].ID || result.Err != nil || string(result.Response.AudioData) != "audio" {
+			t.Fatalf("unexpected result %d: %+v", i, result)
+		}
+	}
+	if last := results[5]; last.Err == nil || !strings.Contains(last.Err.Error(), "request cannot be nil") {
+		t.Fatalf("expected nil request error, got %+v", last)
+	}
+}
+
+func TestRunBatch_RecoversPanics(t *testing.T) {
+	srv := batchServer()
+	defer srv.Close()
+
+	handleErr := errors.New("disk full")
+	results := newTestClient(srv, "k").RunBatch(context.Background(), batchItems("ok", "boom", "full"), &BatchOptions{
+		Handle: func(ctx context.Context, item BatchItem, resp *TTSResponse) error {
+			switch item.ID {
+			case "boom":
+				var m map[string]int
+				m["x"]++ // panics: assignment to entry in nil map
+			case "full":
+				return handleErr
+			}
+			return nil
+		},
+	})
+
+	if results[0].Err != nil || results[0].Response == nil {
+		t.Fatalf("unexpected result 0: %+v", results[0])
+	}
+	var panicErr *PanicError
+	if !errors.As(results[1].Err, &panicErr) || results[1].Response != nil {
+		t.Fatalf("expected PanicError, got %+v", results[1])
+	}
+	if !strings.Contains(panicErr.Error(), "panic: assignment to entry in nil map") || !strings.Contains(string(panicErr.Stack), "processBatchItem") {
+		t.Fatalf("unexpected panic error %q\n%s", panicErr, panicErr.Stack)
+	}
+	if results[2].Err != handleErr || results[2].Response != nil {
+		t.Fatalf("expected handle error, got %+v", results[2])
+	}
+}
+
+func TestRunBatch_CanceledContext(t *testing.T) {
+	srv := batchServer()
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, result := range newTestClient(srv, "k").RunBatch(ctx, batchItems("a", "b"), nil) {
+		if result.Err != context.Canceled {
+			t.Fatalf("expected context.Canceled, got %+v", result)
+		}
+	}
+}
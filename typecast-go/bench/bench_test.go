@@ -0,0 +1,90 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+func benchClient(b *testing.B, config MockConfig) (*typecast.Client, func()) {
+	b.Helper()
+	srv := NewMockServer(config)
+	client := typecast.NewClient(&typecast.ClientConfig{APIKey: "bench", BaseURL: srv.URL})
+	return client, srv.Close
+}
+
+var benchRequest = typecast.TTSRequest{VoiceID: "tc_mock_0", Text: "Benchmarking the Typecast Go SDK.", Model: typecast.ModelSSFMV30}
+
+func BenchmarkTextToSpeech(b *testing.B) {
+	client, done := benchClient(b, MockConfig{AudioSize: 1 << 20})
+	defer done()
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := benchRequest
+		if _, err := client.TextToSpeech(ctx, &req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTextToSpeechBuffer(b *testing.B) {
+	client, done := benchClient(b, MockConfig{AudioSize: 1 << 20})
+	defer done()
+	ctx := context.Background()
+	var buf bytes.Buffer
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := benchRequest
+		if _, err := client.TextToSpeechBuffer(ctx, &req, &buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTextToSpeechParallel(b *testing.B) {
+	client, done := benchClient(b, MockConfig{AudioSize: 256 * 1024})
+	defer done()
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			req := benchRequest
+			if _, err := client.TextToSpeech(ctx, &req); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkGetVoicesV2(b *testing.B) {
+	client, done := benchClient(b, MockConfig{Voices: 10000})
+	defer done()
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.GetVoicesV2(ctx, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEachVoiceV2(b *testing.B) {
+	client, done := benchClient(b, MockConfig{Voices: 10000})
+	defer done()
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := client.EachVoiceV2(ctx, nil, func(typecast.VoiceV2) error { return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
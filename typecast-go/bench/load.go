@@ -0,0 +1,239 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+// Operation is one kind of request in a load mix.
+type Operation struct {
+	// Name labels the operation in reports
+	Name string
+	// Weight is the relative share of requests using this operation (defaults to 1)
+	Weight int
+	// Run performs a single request
+	Run func(ctx context.Context, client *typecast.Client) error
+}
+
+// TextToSpeechOperation returns an Operation that synthesizes request.
+func TextToSpeechOperation(request typecast.TTSRequest) Operation {
+	return Operation{
+		Name: "text_to_speech",
+		Run: func(ctx context.Context, client *typecast.Client) error {
+			req := request
+			_, err := client.TextToSpeech(ctx, &req)
+			return err
+		},
+	}
+}
+
+// StreamOperation returns an Operation that reads a full streaming synthesis.
+func StreamOperation(request typecast.TTSRequestStream) Operation {
+	return Operation{
+		Name: "text_to_speech_stream",
+		Run: func(ctx context.Context, client *typecast.Client) error {
+			stream, err := client.TextToSpeechStream(ctx, request)
+			if err != nil {
+				return err
+			}
+			defer stream.Close()
+			_, err = io.Copy(io.Discard, stream)
+			return err
+		},
+	}
+}
+
+// GetVoicesOperation returns an Operation that lists the V2 voice catalog.
+func GetVoicesOperation() Operation {
+	return Operation{
+		Name: "get_voices_v2",
+		Run: func(ctx context.Context, client *typecast.Client) error {
+			_, err := client.GetVoicesV2(ctx, nil)
+			return err
+		},
+	}
+}
+
+// LoadConfig configures a load run.
+type LoadConfig struct {
+	// Client sends the requests (required)
+	Client *typecast.Client
+	// Mix is the set of operations to run, chosen by weight (required)
+	Mix []Operation
+	// Concurrency is the number of concurrent workers (optional, defaults to 1)
+	Concurrency int
+	// Duration bounds the run by time (optional)
+	Duration time.Duration
+	// Requests bounds the run by total request count (optional)
+	Requests int
+}
+
+// OperationStats summarizes the results for one operation.
+type OperationStats struct {
+	Name     string        `json:"name"`
+	Requests int           `json:"requests"`
+	Errors   int           `json:"errors"`
+	Mean     time.Duration `json:"mean_ns"`
+	P50      time.Duration `json:"p50_ns"`
+	P90      time.Duration `json:"p90_ns"`
+	P99      time.Duration `json:"p99_ns"`
+	Max      time.Duration `json:"max_ns"`
+}
+
+// Report is the result of a load run.
+type Report struct {
+	Concurrency int              `json:"concurrency"`
+	Elapsed     time.Duration    `json:"elapsed_ns"`
+	Requests    int              `json:"requests"`
+	Errors      int              `json:"errors"`
+	Throughput  float64          `json:"throughput_rps"`
+	Operations  []OperationStats `json:"operations"`
+}
+
+// String renders the report as a human-readable table.
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "concurrency=%d elapsed=%s requests=%d errors=%d throughput=%.1f req/s\n",
+		r.Concurrency, r.Elapsed.Round(time.Millisecond), r.Requests, r.Errors, r.Throughput)
+	fmt.Fprintf(&sb, "%-24s %8s %6s %10s %10s %10s %10s %10s\n", "operation", "requests", "errors", "mean", "p50", "p90", "p99", "max")
+	for _, op := range r.Operations {
+		fmt.Fprintf(&sb, "%-24s %8d %6d %10s %10s %10s %10s %10s\n", op.Name, op.Requests, op.Errors,
+			op.Mean.Round(time.Microsecond), op.P50.Round(time.Microsecond), op.P90.Round(time.Microsecond),
+			op.P99.Round(time.Microsecond), op.Max.Round(time.Microsecond))
+	}
+	return sb.String()
+}
+
+type sample struct {
+	op      int
+	latency time.Duration
+	err     error
+}
+
+// Run executes the load described by config and returns a report. The run
+// ends when Duration elapses, Requests have been sent, or ctx is done.
+func Run(ctx context.Context, config LoadConfig) (*Report, error) {
+	if config.Client == nil {
+		return nil, fmt.Errorf("client is required")
+	}
+	if len(config.Mix) == 0 {
+		return nil, fmt.Errorf("at least one operation is required")
+	}
+	if config.Duration <= 0 && config.Requests <= 0 {
+		return nil, fmt.Errorf("duration or requests must be set")
+	}
+	concurrency := config.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	schedule := weightedSchedule(config.Mix)
+	if config.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Duration)
+		defer cancel()
+	}
+
+	var mu sync.Mutex
+	var samples []sample
+	next := 0
+	take := func() (int, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if ctx.Err() != nil || (config.Requests > 0 && next >= config.Requests) {
+			return 0, false
+		}
+		op := schedule[next%len(schedule)]
+		next++
+		return op, true
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				op, ok := take()
+				if !ok {
+					return
+				}
+				began := time.Now()
+				err := config.Mix[op].Run(ctx, config.Client)
+				s := sample{op: op, latency: time.Since(began), err: err}
+				mu.Lock()
+				samples = append(samples, s)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return buildReport(config.Mix, concurrency, time.Since(start), samples), nil
+}
+
+func weightedSchedule(mix []Operation) []int {
+	var schedule []int
+	for i, op := range mix {
+		weight := op.Weight
+		if weight < 1 {
+			weight = 1
+		}
+		for j := 0; j < weight; j++ {
+			schedule = append(schedule, i)
+		}
+	}
+	return schedule
+}
+
+func buildReport(mix []Operation, concurrency int, elapsed time.Duration, samples []sample) *Report {
+	report := &Report{Concurrency: concurrency, Elapsed: elapsed, Requests: len(samples)}
+	if elapsed > 0 {
+		report.Throughput = float64(len(samples)) / elapsed.Seconds()
+	}
+	latencies := make([][]time.Duration, len(mix))
+	errors := make([]int, len(mix))
+	for _, s := range samples {
+		latencies[s.op] = append(latencies[s.op], s.latency)
+		if s.err != nil {
+			errors[s.op]++
+			report.Errors++
+		}
+	}
+	for i, op := range mix {
+		stats := OperationStats{Name: op.Name, Requests: len(latencies[i]), Errors: errors[i]}
+		if n := len(latencies[i]); n > 0 {
+			sorted := latencies[i]
+			sort.Slice(sorted, func(a, b int) bool { return sorted[a] < sorted[b] })
+			var total time.Duration
+			for _, l := range sorted {
+				total += l
+			}
+			stats.Mean = total / time.Duration(n)
+			stats.P50 = percentile(sorted, 0.50)
+			stats.P90 = percentile(sorted, 0.90)
+			stats.P99 = percentile(sorted, 0.99)
+			stats.Max = sorted[n-1]
+		}
+		report.Operations = append(report.Operations, stats)
+	}
+	return report
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(p*float64(len(sorted)) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
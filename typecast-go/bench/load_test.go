@@ -0,0 +1,119 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+func TestRun_RequestBoundMix(t *testing.T) {
+	srv := NewMockServer(MockConfig{AudioSize: 16, Voices: 3, FailEvery: 5})
+	defer srv.Close()
+	client := typecast.NewClient(&typecast.ClientConfig{APIKey: "k", BaseURL: srv.URL})
+
+	report, err := Run(context.Background(), LoadConfig{
+		Client:      client,
+		Concurrency: 4,
+		Requests:    20,
+		Mix: []Operation{
+			func() Operation {
+				op := TextToSpeechOperation(typecast.TTSRequest{VoiceID: "v", Text: "t", Model: typecast.ModelSSFMV30})
+				op.Weight = 2
+				return op
+			}(),
+			StreamOperation(typecast.TTSRequestStream{VoiceID: "v", Text: "t", Model: typecast.ModelSSFMV30}),
+			GetVoicesOperation(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Requests != 20 || srv.Requests() != 20 {
+		t.Fatalf("expected 20 requests, report=%d server=%d", report.Requests, srv.Requests())
+	}
+	if report.Errors != 4 {
+		t.Fatalf("expected 4 injected failures, got %d", report.Errors)
+	}
+	got := map[string]int{}
+	for _, op := range report.Operations {
+		got[op.Name] = op.Requests
+		if op.P50 > op.P99 || op.P99 > op.Max || op.Mean <= 0 {
+			t.Fatalf("inconsistent latency stats: %+v", op)
+		}
+	}
+	if got["text_to_speech"] != 10 || got["text_to_speech_stream"] != 5 || got["get_voices_v2"] != 5 {
+		t.Fatalf("unexpected request mix: %v", got)
+	}
+	text := report.String()
+	if !strings.Contains(text, "requests=20") || !strings.Contains(text, "get_voices_v2") {
+		t.Fatalf("unexpected report text:\n%s", text)
+	}
+	if _, err := json.Marshal(report); err != nil {
+		t.Fatalf("report is not JSON serializable: %v", err)
+	}
+}
+
+func TestRun_DurationBound(t *testing.T) {
+	srv := NewMockServer(MockConfig{Latency: time.Millisecond})
+	defer srv.Close()
+	client := typecast.NewClient(&typecast.ClientConfig{APIKey: "k", BaseURL: srv.URL})
+	idle := Operation{Name: "idle", Run: func(ctx context.Context, c *typecast.Client) error {
+		_, err := c.GetMySubscription(ctx)
+		return err
+	}}
+	unused := Operation{Name: "unused", Weight: 0, Run: func(context.Context, *typecast.Client) error { return errors.New("never") }}
+	report, err := Run(context.Background(), LoadConfig{Client: client, Mix: []Operation{idle, unused}, Duration: 30 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Requests == 0 || report.Concurrency != 1 || report.Throughput <= 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestRun_ValidatesConfig(t *testing.T) {
+	client := typecast.NewClient(&typecast.ClientConfig{APIKey: "k"})
+	op := GetVoicesOperation()
+	cases := map[string]LoadConfig{
+		"client is required":                 {Mix: []Operation{op}, Requests: 1},
+		"at least one operation is required": {Client: client, Requests: 1},
+		"duration or requests must be set":   {Client: client, Mix: []Operation{op}},
+	}
+	for want, config := range cases {
+		if _, err := Run(context.Background(), config); err == nil || err.Error() != want {
+			t.Fatalf("expected %q, got %v", want, err)
+		}
+	}
+}
+
+func TestMockServer_UnknownPath(t *testing.T) {
+	srv := NewMockServer(MockConfig{})
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/nope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if got := percentile(sorted, 0.5); got != 5 {
+		t.Fatalf("p50 = %v", got)
+	}
+	if got := percentile(sorted, 0.99); got != 10 {
+		t.Fatalf("p99 = %v", got)
+	}
+	if got := percentile(sorted, 0); got != 1 {
+		t.Fatalf("p0 = %v", got)
+	}
+}
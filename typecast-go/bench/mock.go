@@ -0,0 +1,88 @@
+// Package bench provides a mock Typecast API server, Go benchmarks, and a
+// load generator for sizing synthesis workers before production rollouts.
+package bench
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// MockConfig configures the behavior of a MockServer.
+type MockConfig struct {
+	// Latency is added before every response (optional)
+	Latency time.Duration
+	// AudioSize is the number of audio bytes returned by synthesis endpoints (optional, defaults to 64 KiB)
+	AudioSize int
+	// Voices is the number of voices returned by /v2/voices (optional, defaults to 100)
+	Voices int
+	// FailEvery makes every Nth request fail with 503 (optional, 0 disables failures)
+	FailEvery int
+}
+
+// MockServer is an in-process stand-in for the Typecast API that serves
+// synthesis, streaming, voice catalog, and subscription endpoints.
+type MockServer struct {
+	*httptest.Server
+	config   MockConfig
+	audio    []byte
+	voices   []byte
+	requests int64
+}
+
+// NewMockServer starts a MockServer. Callers must Close it when done.
+func NewMockServer(config MockConfig) *MockServer {
+	if config.AudioSize <= 0 {
+		config.AudioSize = 64 * 1024
+	}
+	if config.Voices <= 0 {
+		config.Voices = 100
+	}
+	m := &MockServer{config: config, audio: make([]byte, config.AudioSize)}
+	voices := make([]map[string]interface{}, config.Voices)
+	for i := range voices {
+		voices[i] = map[string]interface{}{
+			"voice_id":   "tc_mock_" + strconv.Itoa(i),
+			"voice_name": "Mock " + strconv.Itoa(i),
+			"models":     []map[string]interface{}{{"version": "ssfm-v30", "emotions": []string{"normal", "happy"}}},
+		}
+	}
+	m.voices, _ = json.Marshal(voices)
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	return m
+}
+
+// Requests returns the number of requests the server has received.
+func (m *MockServer) Requests() int64 {
+	return atomic.LoadInt64(&m.requests)
+}
+
+func (m *MockServer) handle(w http.ResponseWriter, r *http.Request) {
+	n := atomic.AddInt64(&m.requests, 1)
+	if m.config.Latency > 0 {
+		time.Sleep(m.config.Latency)
+	}
+	if m.config.FailEvery > 0 && n%int64(m.config.FailEvery) == 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"detail":"mock overload"}`))
+		return
+	}
+	switch r.URL.Path {
+	case "/v1/text-to-speech", "/v1/text-to-speech/stream":
+		w.Header().Set("Content-Type", "audio/wav")
+		w.Header().Set("X-Audio-Duration", "1.0")
+		_, _ = w.Write(m.audio)
+	case "/v2/voices":
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(m.voices)
+	case "/v1/users/me/subscription":
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"plan":"plus","credits":{"plan_credits":1000000,"used_credits":0},"limits":{"concurrency_limit":100}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"detail":"not found"}`))
+	}
+}
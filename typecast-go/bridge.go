@@ -0,0 +1,179 @@
+package typecast
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+const (
+	// defaultBridgeMinChars is the shortest chunk cut at a clause boundary.
+	defaultBridgeMinChars = 40
+	// defaultBridgeMaxChars is the longest chunk before a forced cut.
+	defaultBridgeMaxChars = 250
+	// defaultBridgeLookahead is the number of chunks synthesized at once.
+	defaultBridgeLookahead = 2
+)
+
+// BridgeOptions configures StreamTextToSpeech.
+type BridgeOptions struct {
+	// MinChars is the shortest chunk that is cut at a clause boundary such
+	// as a comma (optional, defaults to 40). Sentences are always cut at
+	// their end, however short.
+	MinChars int
+	// MaxChars is the longest chunk; longer text without a boundary is cut
+	// at the last space (optional, defaults to 250)
+	MaxChars int
+	// Lookahead is the number of chunks synthesized at once while earlier
+	// ones are still being delivered (optional, defaults to 2)
+	Lookahead int
+}
+
+// BridgeChunk is one synthesized piece of a text stream.
+type BridgeChunk struct {
+	// Index is the chunk's position in the stream, starting at 0
+	Index int
+	// Text is the text that was synthesized
+	Text string
+	// Response is the chunk's audio, if synthesis succeeded
+	Response *TTSResponse
+	// Err is the chunk's failure, if any
+	Err error
+}
+
+// StreamTextToSpeech bridges an incremental text stream, such as the tokens
+// of an LLM response, to speech. Text is buffered until a natural boundary,
+// synthesized with profile while more text arrives, and delivered in order on
+// the returned channel, which is closed once text is closed and every chunk
+// is delivered, or when ctx is done. A failed chunk is delivered with its Err
+// and does not stop the stream; cancel ctx to stop early.
+func (c *Client) StreamTextToSpeech(ctx context.Context, text <-chan string, profile VoiceProfile, opts *BridgeOptions) <-chan BridgeChunk {
+	minChars, maxChars, lookahead := defaultBridgeMinChars, defaultBridgeMaxChars, defaultBridgeLookahead
+	if opts != nil {
+		if opts.MinChars > 0 {
+			minChars = opts.MinChars
+		}
+		if opts.MaxChars > 0 && opts.MaxChars <= maxTextLength {
+			maxChars = opts.MaxChars
+		}
+		if opts.Lookahead > 0 {
+			lookahead = opts.Lookahead
+		}
+	}
+
+	return c.speakInOrder(ctx, profile, lookahead, func(emit func(string) bool) error {
+		var buf string
+		for {
+			select {
+			case token, ok := <-text:
+				if !ok {
+					if rest := strings.TrimSpace(buf); rest != "" {
+						emit(rest)
+					}
+					return nil
+				}
+				buf += token
+				for {
+					chunk, rest, found := nextBridgeChunk(buf, minChars, maxChars)
+					if !found {
+						break
+					}
+					buf = rest
+					if chunk != "" && !emit(chunk) {
+						return nil
+					}
+				}
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	})
+}
+
+// speakInOrder runs produce, synthesizes each chunk it emits with profile
+// while later ones are produced, at most lookahead at a time, and delivers
+// them in order. emit reports false once ctx is done. An error returned by
+// produce is delivered as a final chunk.
+func (c *Client) speakInOrder(ctx context.Context, profile VoiceProfile, lookahead int, produce func(emit func(string) bool) error) <-chan BridgeChunk {
+	out := make(chan BridgeChunk)
+	pending := make(chan chan BridgeChunk, lookahead)
+	go func() {
+		defer close(pending)
+		index := 0
+		queue := func() (chan BridgeChunk, bool) {
+			if ctx.Err() != nil {
+				return nil, false
+			}
+			slot := make(chan BridgeChunk, 1)
+			select {
+			case pending <- slot:
+				return slot, true
+			case <-ctx.Done():
+				return nil, false
+			}
+		}
+		err := produce(func(chunk string) bool {
+			slot, ok := queue()
+			if !ok {
+				return false
+			}
+			go func(index int) {
+				resp, err := c.SpeakWith(ctx, profile, chunk)
+				slot <- BridgeChunk{Index: index, Text: chunk, Response: resp, Err: err}
+			}(index)
+			index++
+			return true
+		})
+		if err != nil {
+			if slot, ok := queue(); ok {
+				slot <- BridgeChunk{Index: index, Err: err}
+			}
+		}
+	}()
+	go func() {
+		defer close(out)
+		for slot := range pending {
+			chunk := <-slot
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// nextBridgeChunk cuts the first complete chunk from buf: text up to the
+// end of a sentence, up to a clause boundary once at least minChars long, or
+// up to the last space within maxChars. A boundary only counts once the
+// character after it has arrived.
+func nextBridgeChunk(buf string, minChars, maxChars int) (chunk, rest string, found bool) {
+	runes := []rune(buf)
+	lastSpace := -1
+	for i, r := range runes {
+		if i == maxChars {
+			cut := maxChars
+			if lastSpace > 0 {
+				cut = lastSpace
+			}
+			return strings.TrimSpace(string(runes[:cut])), string(runes[cut:]), true
+		}
+		if i > 0 && strings.ContainsRune("。！？", runes[i-1]) && !strings.ContainsRune("」』）", r) {
+			// Full-width sentences are not followed by spaces.
+			return string(runes[:i]), string(runes[i:]), true
+		}
+		if !unicode.IsSpace(r) || i == 0 {
+			continue
+		}
+		lastSpace = i
+		end := i - 1
+		for end > 0 && strings.ContainsRune("\"'”’)]」』", runes[end]) {
+			end--
+		}
+		if isSentenceTerminator(runes[end]) || (i >= minChars && strings.ContainsRune(",;:，、；：—", runes[end])) {
+			return strings.TrimSpace(string(runes[:i])), string(runes[i:]), true
+		}
+	}
+	return "", buf, false
+}
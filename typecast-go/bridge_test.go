@@ -0,0 +1,102 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNextBridgeChunk(t *testing.T) {
+	tests := []struct {
+		buf         string
+		min, max    int
+		chunk, rest string
+		found       bool
+	}{
+		{"Hello there", 20, 100, "", "Hello there", false},
+		{"Hello.", 20, 100, "", "Hello.", false},
+		{"Hello. How", 20, 100, "Hello.", " How", true},
+		{`He said "stop." Then`, 20, 100, `He said "stop."`, " Then", true},
+		{"Well, that", 20, 100, "", "Well, that", false},
+		{"Well, that is a longer clause, and", 20, 100, "Well, that is a longer clause,", " and", true},
+		{"3.14 is pi", 20, 100, "", "3.14 is pi", false},
+		{"abcdefghijkl", 20, 10, "abcdefghij", "kl", true},
+		{"abc defghijkl", 20, 10, "abc", " defghijkl", true},
+		{"안녕하세요。반갑", 20, 100, "안녕하세요。", "반갑", true},
+		{"「はい。」次", 20, 100, "", "「はい。」次", false},
+		{"            x", 20, 10, "", "   x", true},
+	}
+	for _, tt := range tests {
+		chunk, rest, found := nextBridgeChunk(tt.buf, tt.min, tt.max)
+		if chunk != tt.chunk || rest != tt.rest || found != tt.found {
+			t.Errorf("nextBridgeChunk(%q) = %q, %q, %v", tt.buf, chunk, rest, found)
+		}
+	}
+}
+
+func TestStreamTextToSpeech(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Text == "fail." {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if body.Text == "First sentence." {
+			time.Sleep(20 * time.Millisecond) // later chunks finish first
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte(body.Text))
+	}))
+	defer srv.Close()
+
+	text := make(chan string)
+	go func() {
+		for _, token := range []string{"First", " sentence", ".", " Second", " one! ", "fail.", " trailing", " words"} {
+			text <- token
+		}
+		close(text)
+	}()
+	var got []string
+	for chunk := range newTestClient(srv, "k").StreamTextToSpeech(context.Background(), text, VoiceProfile{VoiceID: "v"}, &BridgeOptions{MinChars: 10, MaxChars: 100, Lookahead: 3}) {
+		if chunk.Index != len(got) {
+			t.Fatalf("chunk %d delivered out of order", chunk.Index)
+		}
+		if chunk.Err != nil {
+			got = append(got, "error:"+chunk.Text)
+			continue
+		}
+		got = append(got, string(chunk.Response.AudioData))
+	}
+	if strings.Join(got, "|") != "First sentence.|Second one!|error:fail.|trailing words" {
+		t.Fatalf("unexpected chunks %q", got)
+	}
+}
+
+func TestStreamTextToSpeech_Cancel(t *testing.T) {
+	srv, _ := countingTTSServer("")
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+
+	// Canceled while waiting for text.
+	ctx, cancel := context.WithCancel(context.Background())
+	out := c.StreamTextToSpeech(ctx, make(chan string), VoiceProfile{VoiceID: "v"}, nil)
+	cancel()
+	if _, ok := <-out; ok {
+		t.Fatal("expected the stream to close on cancel")
+	}
+
+	// Canceled while chunks wait for a reader or for room to synthesize.
+	ctx, cancel = context.WithCancel(context.Background())
+	text := make(chan string, 1)
+	text <- "One. Two. Three. Four. "
+	out = c.StreamTextToSpeech(ctx, text, VoiceProfile{VoiceID: "v"}, &BridgeOptions{Lookahead: 1})
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	for range out {
+	}
+}
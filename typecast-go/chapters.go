@@ -0,0 +1,81 @@
+package typecast
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxChapterTitleRunes caps the titles Chapters derives from segment text.
+const maxChapterTitleRunes = 40
+
+// Chapter marks a section of an MP3 for navigation in podcast apps.
+type Chapter struct {
+	// Title is shown in the chapter list (required)
+	Title string
+	// Start is the chapter's offset in seconds
+	Start float64
+	// End is the end of the chapter in seconds; it must be after Start
+	End float64
+}
+
+// Chapters returns one chapter per segment, titled with the start of the
+// segment's text. Merge or retitle them before calling AddMP3Chapters for
+// coarser navigation.
+func (r *LongFormResult) Chapters() []Chapter {
+	chapters := make([]Chapter, 0, len(r.Segments))
+	for _, segment := range r.Segments {
+		chapters = append(chapters, Chapter{
+			Title: chapterTitle(segment.Text),
+			Start: segment.Start,
+			End:   segment.Start + segment.Duration,
+		})
+	}
+	return chapters
+}
+
+// AddMP3Chapters returns mp3 with an ID3v2.4 tag holding a table of contents
+// (CTOC) and one CHAP frame per chapter, so podcast apps can show per-section
+// navigation. An existing leading ID3v2 tag is replaced.
+func AddMP3Chapters(mp3 []byte, chapters []Chapter) ([]byte, error) {
+	if len(chapters) == 0 || len(chapters) > 255 {
+		return nil, newValidationError("chapters", "chapters must contain between 1 and 255 chapters")
+	}
+	frames := make([][]byte, 0, len(chapters)+1)
+	toc := []byte{0x03, byte(len(chapters))} // top-level, ordered
+	for i, chapter := range chapters {
+		if chapter.Title == "" {
+			return nil, newValidationError("chapters", fmt.Sprintf("chapter %d: title is required", i))
+		}
+		if chapter.Start < 0 || chapter.End <= chapter.Start {
+			return nil, newValidationError("chapters", fmt.Sprintf("chapter %d: end must be after start", i))
+		}
+		id := fmt.Sprintf("chp%d\x00", i)
+		toc = append(toc, id...)
+		times := make([]byte, 16)
+		binary.BigEndian.PutUint32(times[0:], uint32(math.Round(chapter.Start*1000)))
+		binary.BigEndian.PutUint32(times[4:], uint32(math.Round(chapter.End*1000)))
+		binary.BigEndian.PutUint32(times[8:], 0xFFFFFFFF) // byte offsets unused
+		binary.BigEndian.PutUint32(times[12:], 0xFFFFFFFF)
+		frames = append(frames, id3Frame("CHAP", []byte(id), times, id3TextFrame("TIT2", chapter.Title)))
+	}
+	frames = append([][]byte{id3Frame("CTOC", []byte("toc\x00"), toc)}, frames...)
+	tag := id3Tag(frames...)
+	return append(tag, stripID3v2(mp3)...), nil
+}
+
+// chapterTitle shortens text to a chapter title at a word boundary.
+func chapterTitle(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= maxChapterTitleRunes {
+		return text
+	}
+	runes := []rune(text)[:maxChapterTitleRunes]
+	title := string(runes)
+	if i := strings.LastIndex(title, " "); i > 0 {
+		title = title[:i]
+	}
+	return strings.TrimRight(title, ",;:") + "…"
+}
@@ -0,0 +1,65 @@
+package typecast
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestAddMP3Chapters(t *testing.T) {
+	result := &LongFormResult{Segments: []LongFormSegment{
+		{Text: "Chapter one.", Start: 0, Duration: 1.5},
+		{Text: "It was a bright cold day in April, and the clocks were striking thirteen.", Start: 1.5, Duration: 2.25},
+	}}
+	chapters := result.Chapters()
+	if chapters[0].Title != "Chapter one." || chapters[1].Title != "It was a bright cold day in April, and…" || chapters[1].End != 3.75 {
+		t.Fatalf("unexpected chapters: %+v", chapters)
+	}
+
+	audio := makeTestMP3(2)
+	tagged, err := AddMP3Chapters(append(hlsTimestampTag(0), audio...), chapters)
+	if err != nil {
+		t.Fatalf("AddMP3Chapters() error = %v", err)
+	}
+	if !bytes.Equal(stripID3v2(tagged), audio) {
+		t.Fatal("the chapter tag must replace the existing tag and keep the audio")
+	}
+	tag := tagged[:len(tagged)-len(audio)]
+	if !bytes.HasPrefix(tag, []byte("ID3\x04")) || bytes.Contains(tag, []byte("PRIV")) {
+		t.Fatalf("unexpected tag % x", tag[:10])
+	}
+	if !bytes.Contains(tag, []byte("CTOC")) || !bytes.Contains(tag, []byte("toc\x00\x03\x02chp0\x00chp1\x00")) {
+		t.Fatal("tag must hold an ordered top-level table of contents")
+	}
+	chap := bytes.Index(tag, []byte("CHAP\x00"))
+	times := tag[bytes.Index(tag[chap:], []byte("chp1\x00"))+chap+5:]
+	if binary.BigEndian.Uint32(times) != 1500 || binary.BigEndian.Uint32(times[4:]) != 3750 || binary.BigEndian.Uint32(times[8:]) != 0xFFFFFFFF {
+		t.Fatalf("unexpected chapter times % x", times[:16])
+	}
+	if !bytes.Contains(tag, []byte("TIT2\x00\x00\x00\x0d\x00\x00\x03Chapter one.")) {
+		t.Fatal("chapter must carry its title in a TIT2 frame")
+	}
+}
+
+func TestAddMP3Chapters_Validation(t *testing.T) {
+	tests := []struct {
+		chapters []Chapter
+		message  string
+	}{
+		{nil, "chapters must contain between 1 and 255 chapters"},
+		{make([]Chapter, 256), "chapters must contain between 1 and 255 chapters"},
+		{[]Chapter{{Start: 0, End: 1}}, "chapter 0: title is required"},
+		{[]Chapter{{Title: "a", Start: 0, End: 1}, {Title: "b", Start: 1, End: 1}}, "chapter 1: end must be after start"},
+		{[]Chapter{{Title: "a", Start: -1, End: 1}}, "chapter 0: end must be after start"},
+	}
+	for _, tt := range tests {
+		var validation *ValidationError
+		if _, err := AddMP3Chapters(makeTestMP3(1), tt.chapters); !errors.As(err, &validation) || validation.Message != tt.message {
+			t.Errorf("AddMP3Chapters() error = %v, want %q", err, tt.message)
+		}
+	}
+	if title := chapterTitle("Supercalifragilisticexpialidocious-even-though-the-sound-of-it"); title != "Supercalifragilisticexpialidocious-even-…" {
+		t.Errorf("chapterTitle() = %q", title)
+	}
+}
@@ -0,0 +1,143 @@
+package typecast
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// checkpointManifestName is the manifest file written to a checkpoint
+// directory.
+const checkpointManifestName = "manifest.json"
+
+// checkpointManifest lists the segment files in a checkpoint directory.
+type checkpointManifest struct {
+	Segments []checkpointEntry `json:"segments"`
+}
+
+// checkpointEntry describes one completed segment file.
+type checkpointEntry struct {
+	// Index is the segment's position in the narration
+	Index int `json:"index"`
+	// Key is the hash of the segment's request
+	Key string `json:"key"`
+	// File is the segment's file name, relative to the directory
+	File string `json:"file"`
+	// SHA256 is the hex-encoded digest of the file
+	SHA256 string `json:"sha256"`
+	// Duration is the audio duration in seconds
+	Duration float64 `json:"duration"`
+	// Format is the audio format
+	Format AudioFormat `json:"format"`
+}
+
+// checkpointStore is a JobStore that writes each segment to its own audio
+// file in dir and lists them in manifest.json. A segment is only reused when
+// its file still matches the recorded checksum. The job ID is ignored: the
+// directory is the job.
+type checkpointStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func (s *checkpointStore) Load(string) ([]JobSegment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	manifest, err := s.readManifest()
+	if err != nil {
+		return nil, err
+	}
+	var segments []JobSegment
+	for _, entry := range manifest.Segments {
+		audio, err := os.ReadFile(filepath.Join(s.dir, entry.File))
+		if err != nil || sha256Hex(audio) != entry.SHA256 {
+			continue // missing or corrupted; synthesize it again
+		}
+		segments = append(segments, JobSegment{
+			Index:    entry.Index,
+			Key:      entry.Key,
+			Duration: entry.Duration,
+			Format:   entry.Format,
+			Audio:    audio,
+		})
+	}
+	return segments, nil
+}
+
+func (s *checkpointStore) Save(_ string, segment JobSegment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	manifest, err := s.readManifest()
+	if err != nil {
+		return err
+	}
+	entry := checkpointEntry{
+		Index:    segment.Index,
+		Key:      segment.Key,
+		File:     checkpointFileName(segment.Index, segment.Format),
+		SHA256:   sha256Hex(segment.Audio),
+		Duration: segment.Duration,
+		Format:   segment.Format,
+	}
+	if err := writeFileAtomic(filepath.Join(s.dir, entry.File), segment.Audio); err != nil {
+		return err
+	}
+
+	entries := manifest.Segments[:0]
+	for _, existing := range manifest.Segments {
+		if existing.Index != entry.Index {
+			entries = append(entries, existing)
+		}
+	}
+	entries = append(entries, entry)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Index < entries[j].Index })
+	manifest.Segments = entries
+	data, _ := json.MarshalIndent(manifest, "", "  ")
+	return writeFileAtomic(filepath.Join(s.dir, checkpointManifestName), data)
+}
+
+func (s *checkpointStore) readManifest() (*checkpointManifest, error) {
+	manifest := &checkpointManifest{}
+	data, err := os.ReadFile(filepath.Join(s.dir, checkpointManifestName))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// checkpointFileName names the file of segment index in a checkpoint
+// directory.
+func checkpointFileName(index int, format AudioFormat) string {
+	if format == "" {
+		format = AudioFormatWAV
+	}
+	return fmt.Sprintf("segment-%04d.%s", index, format)
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path,
+// so readers never see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
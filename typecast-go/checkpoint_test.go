@@ -0,0 +1,122 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLongFormSynthesize_CheckpointDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "chapter-1")
+	request := LongFormRequest{
+		Profile:       VoiceProfile{VoiceID: "v"},
+		Text:          "One. Two. Three.",
+		MaxChunkChars: 6,
+		CheckpointDir: dir,
+	}
+
+	crashing, _ := countingTTSServer("Three.")
+	_, err := newTestClient(crashing, "k").LongFormSynthesize(context.Background(), request)
+	crashing.Close()
+	if err == nil {
+		t.Fatal("expected the first run to fail on segment 2")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		t.Fatalf("manifest not written: %v", err)
+	}
+	var manifest checkpointManifest
+	if err := json.Unmarshal(data, &manifest); err != nil || len(manifest.Segments) != 2 {
+		t.Fatalf("unexpected manifest %s: %v", data, err)
+	}
+	if entry := manifest.Segments[1]; entry.File != "segment-0001.wav" || entry.Format != AudioFormatWAV || entry.Duration != 0.1 || entry.Key == "" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+
+	// Corrupt segment 0: it must be synthesized again along with segment 2.
+	if err := os.WriteFile(filepath.Join(dir, "segment-0000.wav"), []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv, texts := countingTTSServer("")
+	defer srv.Close()
+	result, err := newTestClient(srv, "k").LongFormSynthesize(context.Background(), request)
+	if err != nil {
+		t.Fatalf("LongFormSynthesize() error = %v", err)
+	}
+	if strings.Join(texts(), "|") != "One.|Three." {
+		t.Fatalf("rerun synthesized %q, want the corrupted and missing segments", texts())
+	}
+	if len(result.Segments) != 3 {
+		t.Fatalf("unexpected segments: %+v", result.Segments)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err := json.Unmarshal(data, &manifest); err != nil || len(manifest.Segments) != 3 || manifest.Segments[2].Index != 2 {
+		t.Fatalf("unexpected manifest after rerun: %s", data)
+	}
+}
+
+func TestLongFormSynthesize_CheckpointDirValidation(t *testing.T) {
+	request := LongFormRequest{
+		Profile:       VoiceProfile{VoiceID: "v"},
+		Text:          "One.",
+		Store:         NewMemoryJobStore(),
+		JobID:         "job",
+		CheckpointDir: t.TempDir(),
+	}
+	var validation *ValidationError
+	if err := request.Validate(); !errors.As(err, &validation) || validation.Field != "checkpoint_dir" {
+		t.Fatalf("expected checkpoint_dir ValidationError, got %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv, _ := countingTTSServer("")
+	defer srv.Close()
+	request.Store, request.CheckpointDir = nil, dir
+	_, err := newTestClient(srv, "k").LongFormSynthesize(context.Background(), request)
+	if err == nil || !strings.Contains(err.Error(), "invalid checkpoint manifest") {
+		t.Fatalf("expected manifest error, got %v", err)
+	}
+}
+
+func TestCheckpointStore_SaveErrors(t *testing.T) {
+	root := t.TempDir()
+	mkdir := func(parts ...string) string {
+		path := filepath.Join(append([]string{root}, parts...)...)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	file := filepath.Join(root, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	segment := JobSegment{Audio: []byte("a")}
+
+	if err := (&checkpointStore{dir: file}).Save("", segment); err == nil {
+		t.Fatal("expected error when the directory is a file")
+	}
+	mkdir("manifest-dir", "manifest.json")
+	if err := (&checkpointStore{dir: filepath.Join(root, "manifest-dir")}).Save("", segment); err == nil {
+		t.Fatal("expected error when the manifest cannot be read")
+	}
+	mkdir("segment-dir", "segment-0000.wav.tmp")
+	if err := (&checkpointStore{dir: filepath.Join(root, "segment-dir")}).Save("", segment); err == nil {
+		t.Fatal("expected error when the segment cannot be written")
+	}
+	mkdir("rename-dir", "segment-0000.wav", "x")
+	if err := (&checkpointStore{dir: filepath.Join(root, "rename-dir")}).Save("", segment); err == nil {
+		t.Fatal("expected error when the segment cannot be replaced")
+	}
+	mkdir("manifest-tmp", "manifest.json.tmp")
+	if err := (&checkpointStore{dir: filepath.Join(root, "manifest-tmp")}).Save("", segment); err == nil {
+		t.Fatal("expected error when the manifest cannot be written")
+	}
+}
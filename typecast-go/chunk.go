@@ -0,0 +1,111 @@
+package typecast
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxTextLength is the largest text, in characters, accepted per request.
+const maxTextLength = 2000
+
+// splitSentences splits text after runs of sentence punctuation that are
+// followed by whitespace or the end of text. Whitespace stays attached to
+// the preceding sentence, so joining the result restores text.
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	terminated := false
+	for i, r := range text {
+		switch {
+		case isSentenceTerminator(r):
+			terminated = true
+		case unicode.IsSpace(r):
+			if terminated {
+				end := i + utf8.RuneLen(r)
+				for end < len(text) {
+					next, size := utf8.DecodeRuneInString(text[end:])
+					if !unicode.IsSpace(next) {
+						break
+					}
+					end += size
+				}
+				if end > start {
+					sentences = append(sentences, text[start:end])
+				}
+				start = end
+				terminated = false
+			}
+		case strings.ContainsRune("\"'”’)]」』", r):
+			// Closing quotes and brackets stay with the sentence they end.
+		default:
+			terminated = false
+		}
+	}
+	if start < len(text) {
+		sentences = append(sentences, text[start:])
+	}
+	return sentences
+}
+
+func isSentenceTerminator(r rune) bool {
+	return strings.ContainsRune(".!?。！？…", r)
+}
+
+// splitText packs whole sentences into chunks of at most maxChars
+// characters. Sentences longer than maxChars are split at the last space
+// that fits (after a clause, in Korean), or hard-cut when there is none.
+// Chunks are trimmed and empty chunks dropped.
+func splitText(text string, maxChars int) []string {
+	if maxChars <= 0 {
+		maxChars = maxTextLength
+	}
+	var chunks []string
+	var current strings.Builder
+	currentLen := 0
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current.Reset()
+		currentLen = 0
+	}
+	for _, sentence := range splitSentences(text) {
+		n := utf8.RuneCountInString(strings.TrimRightFunc(sentence, unicode.IsSpace))
+		if currentLen+n > maxChars {
+			flush()
+		}
+		for n > maxChars {
+			head, tail := cutAtSpace(sentence, maxChars)
+			current.WriteString(head)
+			flush()
+			sentence = strings.TrimLeftFunc(tail, unicode.IsSpace)
+			n = utf8.RuneCountInString(strings.TrimRightFunc(sentence, unicode.IsSpace))
+		}
+		current.WriteString(sentence)
+		currentLen += utf8.RuneCountInString(sentence)
+	}
+	flush()
+	return chunks
+}
+
+// cutAtSpace splits s before maxChars characters, at the last space when
+// there is one. Korean text is split after a clause when one ends in the
+// second half, so the voice pauses where a speaker would.
+func cutAtSpace(s string, maxChars int) (string, string) {
+	cut := len(s)
+	count := 0
+	for i := range s {
+		if count == maxChars {
+			cut = i
+			break
+		}
+		count++
+	}
+	if clause := koreanClauseBreak(s[:cut]); clause > 0 {
+		cut = clause
+	} else if space := strings.LastIndexFunc(s[:cut], unicode.IsSpace); space > 0 {
+		cut = space
+	}
+	return s[:cut], s[cut:]
+}
@@ -0,0 +1,59 @@
+package typecast
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitSentences(t *testing.T) {
+	text := `He said "hi." Then left!  Really?! 네。 끝`
+	got := splitSentences(text)
+	want := []string{`He said "hi." `, "Then left!  ", "Really?! ", "네。 ", "끝"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("splitSentences() = %q, want %q", got, want)
+	}
+	if strings.Join(got, "") != text {
+		t.Fatal("joined sentences must restore the text")
+	}
+	if got := splitSentences("Version 1.5 is out."); len(got) != 1 {
+		t.Fatalf("decimal point must not split: %q", got)
+	}
+}
+
+func TestSplitText_PacksSentences(t *testing.T) {
+	got := splitText("One. Two. Three. Four.", 10)
+	want := []string{"One. Two.", "Three.", "Four."}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("splitText() = %q, want %q", got, want)
+	}
+	if got := splitText("  ", 10); len(got) != 0 {
+		t.Fatalf("expected no chunks, got %q", got)
+	}
+}
+
+func TestSplitText_SplitsLongSentences(t *testing.T) {
+	got := splitText("short. alpha beta gamma delta epsilon", 12)
+	want := []string{"short.", "alpha beta", "gamma delta", "epsilon"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("splitText() = %q, want %q", got, want)
+	}
+
+	got = splitText(strings.Repeat("가", 25), 10)
+	if len(got) != 3 || utf8.RuneCountInString(got[0]) != 10 || utf8.RuneCountInString(got[2]) != 5 {
+		t.Fatalf("expected hard cuts at 10 characters, got %q", got)
+	}
+}
+
+func TestSplitText_DefaultLimit(t *testing.T) {
+	got := splitText(strings.Repeat("word ", 500), 0)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 chunks at the default limit, got %d", len(got))
+	}
+	for _, chunk := range got {
+		if n := utf8.RuneCountInString(chunk); n > maxTextLength {
+			t.Fatalf("chunk has %d characters", n)
+		}
+	}
+}
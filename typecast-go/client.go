@@ -4,17 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
-	"mime/multipart"
 	"net/http"
-	"net/textproto"
-	"net/url"
 	"os"
-	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -29,34 +27,97 @@ const (
 // Release builds may override it with -ldflags "-X github.com/neosapience/typecast-sdk/typecast-go.SDKVersion=<version>".
 var SDKVersion = "dev"
 
-// ClientConfig holds configuration options for the TypecastClient
-type ClientConfig struct {
-	// APIKey is the Typecast API key. It may be omitted when using a proxy BaseURL.
-	APIKey string
-	// BaseURL is the API base URL (optional, defaults to https://api.typecast.ai)
-	BaseURL string
-	// HTTPClient is the HTTP client to use (optional)
-	HTTPClient *http.Client
-	// Timeout is the HTTP request timeout (optional, defaults to 60s)
-	Timeout time.Duration
-}
-
-// Client is the Typecast API client
+// Client is the Typecast API client. A Client is safe for concurrent use by
+// multiple goroutines: its settings are fixed by NewClient, each call keeps
+// its own state, and what calls share (concurrency slots, the rate limiter,
+// the quota guard, the retry budget, and the observed clock skew) is
+// synchronized. Calls do not modify the requests passed to them. Share one
+// Client instead of creating one per goroutine.
 type Client struct {
-	apiKey     string
-	baseURL    string
-	httpClient *http.Client
+	apiKey       string
+	baseURL      string
+	environment  Environment
+	configErr    error
+	httpClient   *http.Client
+	voiceAliases *VoiceRegistry
+	slots        *slotScheduler
+	rateLimiter  RateLimiter
+
+	maxRetries     int
+	retryBudget    *RetryBudget
+	maxElapsedTime time.Duration
+	retryHook      func(req *http.Request, resp *http.Response, err error) bool
+	backoff        Backoff
+	retryBaseDelay time.Duration
+	clock          Clock
+
+	verifyAudioFormat bool
+	quotaGuard        *QuotaGuard
+	onRateLimit       func(RateLimitState)
+	omitTagHeaders    bool
+	validateVoiceIDs  bool
+	clockSkew         int64 // nanoseconds, accessed atomically
+
+	disableTextNormalization bool
+	pronunciations           *PronunciationLexicon
+	acronyms                 *AcronymRules
+	emojiPolicy              EmojiPolicy
+	verbalizeNumbers         bool
+	adjustKoreanParticles    bool
+	languageDetection        LanguageDetection
+	voiceLanguages           sync.Map // voice ID -> []string
+	prefetched               chan struct{}
+
+	logger                      Logger
+	suppressDeprecationWarnings bool
+	debugCurl                   bool
+	metrics                     *Metrics
+	usageSink                   UsageSink
+	auditSink                   AuditSink
+	auditTextExcerpt            int
+	logText                     bool
+	redactText                  Redactor
+	responseCache               *ResponseCache
+	revalidations               sync.WaitGroup
+	revalidationCtx             context.Context
+	traceContext                func(ctx context.Context) TraceContext
+	disableTracePropagation     bool
+	returnPendingJobs           bool
+
+	ownsTransport bool
+	lifecycle     sync.Mutex
+	closed        bool
+	closers       []func()
+	detached      sync.WaitGroup
 }
 
-// NewClient creates a new Typecast API client
+// NewClient creates a new Typecast API client. An invalid BaseURL or
+// Environment is reported by every request; use New to report it here
+// instead.
 func NewClient(config *ClientConfig) *Client {
+	c, _ := newClient(config)
+	return c
+}
+
+// New creates a new Typecast API client like NewClient, but returns a
+// *ValidationError when the configuration is invalid, such as a BaseURL (or
+// TYPECAST_API_HOST) that is not an absolute http or https URL, or an
+// unknown Environment.
+func New(config *ClientConfig) (*Client, error) {
+	c, err := newClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newClient creates a client, which is usable even when the configuration
+// is invalid, and the configuration's error.
+func newClient(config *ClientConfig) (*Client, error) {
 	// Use environment variables as defaults
 	apiKey := strings.TrimSpace(os.Getenv("TYPECAST_API_KEY"))
 	baseURL := strings.TrimSpace(os.Getenv("TYPECAST_API_HOST"))
-
-	if baseURL == "" {
-		baseURL = DefaultBaseURL
-	}
+	environment := Environment(strings.TrimSpace(os.Getenv("TYPECAST_ENV")))
 
 	timeout := DefaultTimeout
 
@@ -66,97 +127,39 @@ func NewClient(config *ClientConfig) *Client {
 			apiKey = strings.TrimSpace(config.APIKey)
 		}
 		if config.BaseURL != "" {
-			baseURL = strings.TrimRight(strings.TrimSpace(config.BaseURL), "/")
+			baseURL = config.BaseURL
+		}
+		if config.Environment != "" {
+			environment = config.Environment
 		}
 		if config.Timeout > 0 {
 			timeout = config.Timeout
 		}
 	}
 
-	httpClient := &http.Client{Timeout: timeout}
-	if config != nil && config.HTTPClient != nil {
-		httpClient = config.HTTPClient
-	}
-
-	return &Client{
-		apiKey:     apiKey,
-		baseURL:    baseURL,
-		httpClient: httpClient,
+	baseURL, configErr := resolveBaseURL(environment, baseURL)
+	c := &Client{
+		apiKey:         apiKey,
+		baseURL:        baseURL,
+		environment:    environment,
+		configErr:      configErr,
+		httpClient:     &http.Client{Timeout: timeout},
+		retryBaseDelay: defaultRetryBaseDelay,
+		clock:          SystemClock,
 	}
-}
-
-func (c *Client) setAuthHeader(headers http.Header) error {
-	apiKey := strings.TrimSpace(c.apiKey)
-	if apiKey == "" {
-		if isDefaultBaseURL(c.baseURL) {
-			return fmt.Errorf("API key is required for the default Typecast API host")
-		}
-		return nil
-	}
-	headers.Set("X-API-KEY", apiKey)
-	return nil
-}
-
-func (c *Client) setUserAgent(headers http.Header) {
-	base := "custom"
-	if isDefaultBaseURL(c.baseURL) {
-		base = "default"
-	}
-	timeout := "default"
-	if c.httpClient != nil && c.httpClient.Timeout > 0 && c.httpClient.Timeout != DefaultTimeout {
-		timeout = c.httpClient.Timeout.String()
-	}
-	headers.Set(
-		"User-Agent",
-		fmt.Sprintf(
-			"typecast-go/%s Go/%s net-http (base=%s; timeout=%s; os=%s; arch=%s; sdk_env=go; platform=server)",
-			SDKVersion,
-			strings.TrimPrefix(runtime.Version(), "go"),
-			base,
-			timeout,
-			normalizedOS(runtime.GOOS),
-			normalizedArch(runtime.GOARCH),
-		),
-	)
-}
-
-func normalizedOS(os string) string {
-	switch os {
-	case "darwin":
-		return "macos"
-	case "windows":
-		return "windows"
-	default:
-		if os == "" {
-			return "unknown"
-		}
-		return os
-	}
-}
-
-func normalizedArch(arch string) string {
-	switch arch {
-	case "amd64":
-		return "x64"
-	case "386":
-		return "x86"
-	case "arm64":
-		return "arm64"
-	default:
-		if arch == "" {
-			return "unknown"
-		}
-		return arch
+	if config != nil {
+		c.configure(config)
 	}
-}
-
-func isDefaultBaseURL(baseURL string) bool {
-	normalized := strings.TrimRight(strings.TrimSpace(baseURL), "/")
-	return strings.EqualFold(normalized, DefaultBaseURL)
+	return c, configErr
 }
 
 // doRequest performs an HTTP request with the appropriate headers
 func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	return c.doRequestWithHeader(ctx, method, path, body, nil)
+}
+
+// doRequestWithHeader is doRequest with extra request headers.
+func (c *Client) doRequestWithHeader(ctx context.Context, method, path string, body interface{}, header http.Header) (*http.Response, error) {
 	var bodyReader io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
@@ -164,22 +167,34 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
 		bodyReader = bytes.NewReader(jsonBody)
+		if c.metrics != nil {
+			ctx = withMetricsModel(ctx, jsonBody)
+		}
 	}
 
-	reqURL := c.baseURL + path
+	reqURL, err := c.endpoint(path)
+	if err != nil {
+		return nil, err
+	}
 
 	req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	for key, values := range header {
+		req.Header[key] = values
+	}
 	req.Header.Set("Content-Type", "application/json")
 	if err := c.setAuthHeader(req.Header); err != nil {
 		return nil, err
 	}
 	c.setUserAgent(req.Header)
 
-	return c.httpClient.Do(req)
+	if method == http.MethodGet && c.responseCache != nil {
+		return c.sendCached(req)
+	}
+	return c.send(req)
 }
 
 // handleErrorResponse parses an error response and returns an APIError
@@ -187,32 +202,98 @@ func (c *Client) handleErrorResponse(resp *http.Response) error {
 	var errResp ErrorResponse
 	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
 		// If we can't decode the error response, just use the status code
-		return NewAPIError(resp.StatusCode, "")
+		errResp.Detail = ""
+	}
+	apiErr := NewAPIError(resp.StatusCode, errResp.Detail)
+	apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), c.serverNow(resp.Header))
+	apiErr.ServerDate, _ = serverDate(resp.Header)
+	if resp.Request != nil {
+		apiErr.CorrelationID = resp.Request.Header.Get(CorrelationIDHeader)
+		apiErr.Tags = TagsFromContext(resp.Request.Context())
 	}
-	return NewAPIError(resp.StatusCode, errResp.Detail)
+	return apiErr
 }
 
 // TextToSpeech converts text to speech using the Typecast API
 func (c *Client) TextToSpeech(ctx context.Context, request *TTSRequest) (*TTSResponse, error) {
+	buf := getAudioBuffer()
+	defer putAudioBuffer(buf)
+	response, err := c.textToSpeech(ctx, request, buf)
+	if err != nil {
+		return nil, err
+	}
+	response.AudioData = make([]byte, buf.Len())
+	copy(response.AudioData, buf.Bytes())
+	return response, nil
+}
+
+// textToSpeech performs a TTS request and reads the audio into buf.
+// The returned response has no AudioData; callers take it from buf.
+func (c *Client) textToSpeech(ctx context.Context, request *TTSRequest, buf *bytes.Buffer) (response *TTSResponse, err error) {
 	if request == nil {
 		return nil, fmt.Errorf("request cannot be nil")
 	}
 	if err := request.Output.Validate(); err != nil {
 		return nil, err
 	}
+	voiceID, err := c.synthesisVoiceID(request.VoiceID)
+	if err != nil {
+		return nil, err
+	}
+	// Once admitted, the synthesis runs to the end even if the client is
+	// closed while it waits for a retry or a pending job.
+	if ctx, err = c.admit(ctx); err != nil {
+		return nil, err
+	}
+	text := c.normalizeText(request.Text, request.Language)
+	if language := c.requestLanguage(ctx, voiceID, request.Language, text); voiceID != request.VoiceID || text != request.Text || language != request.Language {
+		resolved := *request
+		resolved.VoiceID, resolved.Text, resolved.Language = voiceID, text, language
+		request = &resolved
+	}
+	refund, err := c.reserveQuota(ctx, request.Text)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		var pending *PendingJob
+		if err != nil && !errors.As(err, &pending) {
+			refund()
+		}
+		if err == nil {
+			c.recordUsage(ctx, "/v1/text-to-speech", request.VoiceID, request.Model, response.Duration, request.Text)
+		}
+	}()
 	resp, err := c.doRequest(ctx, http.MethodPost, "/v1/text-to-speech", request)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusAccepted {
+		job, err := c.pendingJob(resp)
+		if err != nil {
+			return nil, err
+		}
+		if c.returnPendingJobs {
+			job.settle = c.settlePendingJob(ctx, request, refund)
+			return nil, job
+		}
+		return job.await(ctx, buf)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, c.handleErrorResponse(resp)
 	}
+	return c.readTTSAudio(resp, buf)
+}
 
+// readTTSAudio reads the audio of a successful TTS response into buf.
+func (c *Client) readTTSAudio(resp *http.Response, buf *bytes.Buffer) (*TTSResponse, error) {
 	// Read audio data
-	audioData, err := io.ReadAll(resp.Body)
-	if err != nil {
+	if resp.ContentLength > 0 {
+		buf.Grow(int(resp.ContentLength))
+	}
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
 		return nil, fmt.Errorf("failed to read audio data: %w", err)
 	}
 
@@ -223,6 +304,10 @@ func (c *Client) TextToSpeech(ctx context.Context, request *TTSRequest) (*TTSRes
 		format = AudioFormatMP3
 	}
 
+	if err := c.verifyAudio(resp, buf.Bytes(), format); err != nil {
+		return nil, err
+	}
+
 	// Parse duration from header
 	var duration float64
 	if durationStr := resp.Header.Get("X-Audio-Duration"); durationStr != "" {
@@ -230,9 +315,8 @@ func (c *Client) TextToSpeech(ctx context.Context, request *TTSRequest) (*TTSRes
 	}
 
 	return &TTSResponse{
-		AudioData: audioData,
-		Duration:  duration,
-		Format:    format,
+		Duration: duration,
+		Format:   format,
 	}, nil
 }
 
@@ -254,42 +338,11 @@ func (c *Client) composeTextToSpeech(ctx context.Context, request interface{}) (
 	if strings.EqualFold(contentType, "audio/mpeg") || strings.EqualFold(contentType, "audio/mp3") {
 		format = AudioFormatMP3
 	}
-	duration, _ := strconv.ParseFloat(resp.Header.Get("X-Audio-Duration"), 64)
-	return &TTSResponse{AudioData: audioData, Duration: duration, Format: format}, nil
-}
-
-// TextToSpeechWithTimestamps synthesizes speech and returns base64 audio plus
-// alignment timestamps. The optional granularity parameter ("word", "char", or "")
-// filters the returned alignment arrays.
-func (c *Client) TextToSpeechWithTimestamps(ctx context.Context, request *TTSRequestWithTimestamps, granularity string) (*TTSWithTimestampsResponse, error) {
-	if request == nil {
-		return nil, fmt.Errorf("request cannot be nil")
-	}
-	if granularity != "" && granularity != "word" && granularity != "char" {
-		return nil, fmt.Errorf("granularity must be empty, \"word\", or \"char\"; got %q", granularity)
-	}
-	if err := request.Validate(); err != nil {
-		return nil, err
-	}
-	path := "/v1/text-to-speech/with-timestamps"
-	if granularity != "" {
-		path = path + "?granularity=" + granularity
-	}
-	resp, err := c.doRequest(ctx, http.MethodPost, path, request)
-	if err != nil {
+	if err := c.verifyAudio(resp, audioData, format); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleErrorResponse(resp)
-	}
-
-	var out TTSWithTimestampsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return nil, fmt.Errorf("failed to decode timestamps response: %w", err)
-	}
-	return &out, nil
+	duration, _ := strconv.ParseFloat(resp.Header.Get("X-Audio-Duration"), 64)
+	return &TTSResponse{AudioData: audioData, Duration: duration, Format: format}, nil
 }
 
 // TextToSpeechStream converts text to speech using the streaming endpoint.
@@ -297,120 +350,34 @@ func (c *Client) TextToSpeechWithTimestamps(ctx context.Context, request *TTSReq
 // followed by PCM data, or independently-decodable MP3 chunks). The caller
 // is responsible for closing it.
 func (c *Client) TextToSpeechStream(ctx context.Context, request TTSRequestStream) (io.ReadCloser, error) {
+	request.Text = c.normalizeText(request.Text, request.Language)
 	if err := request.Validate(); err != nil {
 		return nil, err
 	}
-	resp, err := c.doRequest(ctx, http.MethodPost, "/v1/text-to-speech/stream", request)
+	voiceID, err := c.synthesisVoiceID(request.VoiceID)
 	if err != nil {
 		return nil, err
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		defer resp.Body.Close()
-		return nil, c.handleErrorResponse(resp)
-	}
-
-	return resp.Body, nil
-}
-
-// GetVoicesV2 retrieves the list of available voices with enhanced metadata (V2 API)
-func (c *Client) GetVoicesV2(ctx context.Context, filter *VoicesV2Filter) ([]VoiceV2, error) {
-	path := "/v2/voices"
-
-	// Build query parameters
-	if filter != nil {
-		params := url.Values{}
-		if filter.Model != "" {
-			params.Set("model", string(filter.Model))
-		}
-		if filter.Gender != "" {
-			params.Set("gender", string(filter.Gender))
-		}
-		if filter.Age != "" {
-			params.Set("age", string(filter.Age))
-		}
-		if filter.UseCases != "" {
-			params.Set("use_cases", string(filter.UseCases))
-		}
-		if len(params) > 0 {
-			path = path + "?" + params.Encode()
-		}
-	}
-
-	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleErrorResponse(resp)
-	}
-
-	var voices []VoiceV2
-	if err := json.NewDecoder(resp.Body).Decode(&voices); err != nil {
-		return nil, fmt.Errorf("failed to decode voices response: %w", err)
-	}
-
-	return voices, nil
-}
-
-// GetVoiceV2 retrieves a specific voice by ID with enhanced metadata (V2 API)
-func (c *Client) GetVoiceV2(ctx context.Context, voiceID string) (*VoiceV2, error) {
-	path := fmt.Sprintf("/v2/voices/%s", voiceID)
-
-	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
+	request.VoiceID = voiceID
+	request.Language = c.requestLanguage(ctx, request.VoiceID, request.Language, request.Text)
+	refund, err := c.reserveQuota(ctx, request.Text)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleErrorResponse(resp)
-	}
-
-	var voice VoiceV2
-	if err := json.NewDecoder(resp.Body).Decode(&voice); err != nil {
-		return nil, fmt.Errorf("failed to decode voice response: %w", err)
-	}
-
-	return &voice, nil
-}
-
-// RecommendVoices recommends voices from a text description.
-//
-// Results only contain VoiceID, VoiceName, and Score. Use GetVoiceV2 or
-// GetVoicesV2 when you need detailed metadata for the returned voice IDs.
-// count must be between 1 and 10 and defaults to 5 when zero.
-func (c *Client) RecommendVoices(ctx context.Context, query string, count int) ([]RecommendedVoice, error) {
-	if count == 0 {
-		count = 5
-	}
-	if count < 1 || count > 10 {
-		return nil, fmt.Errorf("count must be between 1 and 10")
-	}
-
-	params := url.Values{}
-	params.Set("query", query)
-	params.Set("count", strconv.Itoa(count))
-	path := "/v1/voices/recommendations?" + params.Encode()
-
-	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
+	resp, err := c.doRequest(ctx, http.MethodPost, "/v1/text-to-speech/stream", request)
 	if err != nil {
+		refund()
 		return nil, err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		refund()
 		return nil, c.handleErrorResponse(resp)
 	}
 
-	var voices []RecommendedVoice
-	if err := json.NewDecoder(resp.Body).Decode(&voices); err != nil {
-		return nil, fmt.Errorf("failed to decode voice recommendations response: %w", err)
-	}
-
-	return voices, nil
+	c.recordUsage(ctx, "/v1/text-to-speech/stream", request.VoiceID, request.Model, 0, request.Text)
+	return resp.Body, nil
 }
 
 // GetMySubscription retrieves the authenticated user's subscription details
@@ -426,140 +393,9 @@ func (c *Client) GetMySubscription(ctx context.Context) (*SubscriptionResponse,
 	}
 
 	var subscription SubscriptionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&subscription); err != nil {
-		return nil, fmt.Errorf("failed to decode subscription response: %w", err)
-	}
-
-	return &subscription, nil
-}
-
-// GetVoices retrieves the list of available voices (V1 API - deprecated)
-// Deprecated: Use GetVoicesV2 for enhanced metadata and filtering options
-func (c *Client) GetVoices(ctx context.Context, model TTSModel) ([]VoiceV1, error) {
-	path := "/v1/voices"
-	if model != "" {
-		path = path + "?model=" + string(model)
-	}
-
-	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleErrorResponse(resp)
-	}
-
-	var voices []VoiceV1
-	if err := json.NewDecoder(resp.Body).Decode(&voices); err != nil {
-		return nil, fmt.Errorf("failed to decode voices response: %w", err)
-	}
-
-	return voices, nil
-}
-
-// GetVoice retrieves a specific voice by ID (V1 API - deprecated)
-// Deprecated: Use GetVoiceV2 for enhanced metadata
-func (c *Client) GetVoice(ctx context.Context, voiceID string, model TTSModel) ([]VoiceV1, error) {
-	path := fmt.Sprintf("/v1/voices/%s", voiceID)
-	if model != "" {
-		path = path + "?model=" + string(model)
-	}
-
-	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleErrorResponse(resp)
-	}
-
-	var voices []VoiceV1
-	if err := json.NewDecoder(resp.Body).Decode(&voices); err != nil {
-		return nil, fmt.Errorf("failed to decode voice response: %w", err)
-	}
-
-	return voices, nil
-}
-
-// CloneVoice creates a custom voice (created via instant cloning) from an audio sample.
-//
-// audio is the raw audio bytes. filename is the multipart filename hint
-// (e.g., "sample.wav") and is used for MIME type inference. name is 1-30
-// characters; model is "ssfm-v21" or "ssfm-v30".
-//
-// The returned CustomVoice has VoiceID with "uc_" prefix; use it directly
-// with TextToSpeech as voice_id.
-func (c *Client) CloneVoice(ctx context.Context, audio []byte, filename, name, model string) (*CustomVoice, error) {
-	if len(name) < NameMinLength || len(name) > NameMaxLength {
-		return nil, fmt.Errorf("name must be %d-%d characters; got %d", NameMinLength, NameMaxLength, len(name))
-	}
-	if int64(len(audio)) > CloningMaxFileSize {
-		return nil, fmt.Errorf("audio file exceeds 25MB limit; got %d bytes", len(audio))
-	}
-
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	_ = writer.WriteField("name", name)
-	_ = writer.WriteField("model", model)
-
-	fileHeader := make(textproto.MIMEHeader)
-	fileHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, filename))
-	fileHeader.Set("Content-Type", guessAudioMime(filename))
-	filePart, _ := writer.CreatePart(fileHeader)
-	_, _ = filePart.Write(audio)
-	_ = writer.Close()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/voices/clone", body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	if err := c.setAuthHeader(req.Header); err != nil {
-		return nil, err
-	}
-	c.setUserAgent(req.Header)
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+	if err := decodeJSON(resp, "subscription response", &subscription); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleErrorResponse(resp)
-	}
-
-	var out CustomVoice
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return nil, fmt.Errorf("failed to decode clone voice response: %w", err)
-	}
-	return &out, nil
-}
-
-// DeleteVoice soft-deletes a custom voice by ID.
-// Returns nil on a 200 OK or 204 No Content response.
-func (c *Client) DeleteVoice(ctx context.Context, voiceID string) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.baseURL+"/v1/voices/"+voiceID, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	if err := c.setAuthHeader(req.Header); err != nil {
-		return err
-	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return c.handleErrorResponse(resp)
-	}
-	return nil
+	return &subscription, nil
 }
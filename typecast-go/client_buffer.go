@@ -0,0 +1,47 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// maxPooledAudioBuffer is the largest buffer returned to the pool; bigger
+// buffers are left to the garbage collector so the pool does not pin memory.
+const maxPooledAudioBuffer = 32 * 1024 * 1024
+
+var audioBufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+func getAudioBuffer() *bytes.Buffer {
+	buf := audioBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putAudioBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledAudioBuffer {
+		return
+	}
+	audioBufferPool.Put(buf)
+}
+
+// TextToSpeechBuffer is like TextToSpeech but reads the audio into the
+// caller-supplied buf instead of allocating a new slice. buf is reset first.
+// The returned AudioData aliases buf's contents and is only valid until buf
+// is next modified, so reusing one buffer per worker avoids per-request
+// allocations in high-throughput batch synthesis.
+func (c *Client) TextToSpeechBuffer(ctx context.Context, request *TTSRequest, buf *bytes.Buffer) (*TTSResponse, error) {
+	if buf == nil {
+		return nil, fmt.Errorf("buffer cannot be nil")
+	}
+	buf.Reset()
+	response, err := c.textToSpeech(ctx, request, buf)
+	if err != nil {
+		return nil, err
+	}
+	response.AudioData = buf.Bytes()
+	return response, nil
+}
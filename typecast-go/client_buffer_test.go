@@ -0,0 +1,75 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTextToSpeechBuffer_ReusesCallerBuffer(t *testing.T) {
+	lines := []string{"first-audio", "second"}
+	call := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Header().Set("X-Audio-Duration", "0.5")
+		_, _ = w.Write([]byte(lines[call]))
+		call++
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv, "k")
+	buf := bytes.NewBufferString("stale")
+	for _, want := range lines {
+		resp, err := c.TextToSpeechBuffer(context.Background(), &TTSRequest{VoiceID: "v", Text: "t", Model: ModelSSFMV30}, buf)
+		if err != nil {
+			t.Fatalf("TextToSpeechBuffer() error = %v", err)
+		}
+		if string(resp.AudioData) != want || buf.String() != want {
+			t.Fatalf("audio = %q, buffer = %q, want %q", resp.AudioData, buf.String(), want)
+		}
+		if resp.Format != AudioFormatMP3 || resp.Duration != 0.5 {
+			t.Fatalf("unexpected metadata: %+v", resp)
+		}
+	}
+}
+
+func TestTextToSpeechBuffer_Errors(t *testing.T) {
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://x"})
+	if _, err := c.TextToSpeechBuffer(context.Background(), &TTSRequest{}, nil); err == nil || !strings.Contains(err.Error(), "buffer cannot be nil") {
+		t.Fatalf("expected nil buffer error, got %v", err)
+	}
+	if _, err := c.TextToSpeechBuffer(context.Background(), nil, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "request cannot be nil") {
+		t.Fatalf("expected nil request error, got %v", err)
+	}
+}
+
+func TestTextToSpeech_ReturnsIndependentCopyOfPooledBuffer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Query().Get("x") + "AUDIO"))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	first, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "t", Model: ModelSSFMV30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "t", Model: ModelSSFMV30}); err != nil {
+		t.Fatal(err)
+	}
+	if string(first.AudioData) != "AUDIO" {
+		t.Fatalf("first response was overwritten: %q", first.AudioData)
+	}
+}
+
+func TestAudioBufferPool_DropsOversizedBuffers(t *testing.T) {
+	big := bytes.NewBuffer(make([]byte, 0, maxPooledAudioBuffer+1))
+	putAudioBuffer(big)
+	buf := getAudioBuffer()
+	if buf.Len() != 0 {
+		t.Fatalf("expected reset buffer, got %d bytes", buf.Len())
+	}
+	putAudioBuffer(buf)
+}
@@ -0,0 +1,226 @@
+package typecast
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ClientConfig holds configuration options for the TypecastClient
+type ClientConfig struct {
+	// APIKey is the Typecast API key. It may be omitted when using a proxy BaseURL.
+	APIKey string
+	// BaseURL is the API base URL, which may include a path prefix such as
+	// https://gateway.example.com/typecast (optional, defaults to the
+	// Environment's URL)
+	BaseURL string
+	// Environment selects production, staging, or sandbox, which sets the
+	// default BaseURL and makes the API key optional outside production
+	// (optional, defaults to TYPECAST_ENV or production)
+	Environment Environment
+	// HTTPClient is the HTTP client to use (optional)
+	HTTPClient *http.Client
+	// Timeout is the HTTP request timeout (optional, defaults to 60s)
+	Timeout time.Duration
+	// VoiceAliases resolves friendly voice names used as VoiceID in requests (optional)
+	VoiceAliases *VoiceRegistry
+	// MaxConcurrentRequests caps in-flight requests for this client (optional, 0 means unlimited).
+	// Callers waiting for a slot return early when their context is done and are
+	// served in WithPriority order.
+	MaxConcurrentRequests int
+	// RateLimiter is consulted before every request (optional). Share one
+	// limiter between clients to enforce an organization-wide QPS ceiling.
+	RateLimiter RateLimiter
+	// MaxRetries is the number of times a request is retried after a transport
+	// error, 429, or 5xx response (optional, defaults to 0).
+	MaxRetries int
+	// RetryBudget caps retries to a fraction of all requests (optional).
+	// Share one budget between clients to apply the cap across them.
+	RetryBudget *RetryBudget
+	// MaxElapsedTime stops retrying once an operation has run this long,
+	// including backoff delays (optional, 0 means no limit).
+	MaxElapsedTime time.Duration
+	// Backoff chooses the delays between retries (optional, defaults to
+	// ExponentialBackoff from 500ms to 8s)
+	Backoff Backoff
+	// ShouldRetry decides whether an API request is retried, in place of
+	// DefaultShouldRetry, for example to retry a 400 known to be transient
+	// or never retry a 402 (optional). It is called with the response or
+	// the transport error of every attempt but the last, and may read the
+	// body of an error response. MaxRetries, RetryBudget, and
+	// MaxElapsedTime still apply.
+	ShouldRetry func(req *http.Request, resp *http.Response, err error) bool
+	// Clock times retry backoff, MaxElapsedTime, Retry-After, the DNS
+	// cache, the observed clock skew, batch item timings, and RunDaemon's
+	// retries, pauses, and schedules, so tests can advance a FakeClock
+	// instead of sleeping. WatchFolder, WatchFeed, and JobQueue.Enqueue
+	// keep the local time (optional, defaults to SystemClock)
+	Clock Clock
+	// VerifyAudioFormat checks that synthesized audio starts with a valid
+	// WAV or MP3 header and fails with an *IntegrityError otherwise (optional).
+	VerifyAudioFormat bool
+	// QuotaGuard enforces a local character budget on synthesis requests
+	// (optional). Share one guard between clients to budget them together.
+	QuotaGuard *QuotaGuard
+	// UnixSocket is the path of a Unix domain socket to send every request
+	// through, such as a local sidecar proxy's, instead of dialing the
+	// BaseURL host. BaseURL still forms the request URLs and Host header, so
+	// set it to the proxy's URL, typically http://localhost (optional,
+	// ignored when HTTPClient is set).
+	UnixSocket string
+	// DialContext dials every connection, like net.Dialer.DialContext, for
+	// example with a net.Dialer whose Control rejects addresses outside an
+	// allowlist (optional, ignored when HTTPClient is set)
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	// DNSCacheTTL caches the resolved addresses of the API host for this
+	// long, so high request rates do not resolve it for every connection.
+	// Addresses that all fail to connect are resolved again (optional, 0
+	// disables caching; ignored when HTTPClient is set)
+	DNSCacheTTL time.Duration
+	// OnRateLimit is called with the rate limit state of every response that
+	// reports X-RateLimit-* (or RateLimit-*) headers or is a 429, so callers
+	// can adapt their concurrency before requests fail (optional). It is
+	// called from the goroutine that sent the request and must return
+	// quickly.
+	OnRateLimit func(RateLimitState)
+	// OmitTagHeaders keeps the tags added with WithTag local, for proxies
+	// that reject unknown headers (optional)
+	OmitTagHeaders bool
+	// ValidateVoiceIDs rejects synthesis requests, including timestamped,
+	// streamed, and composed ones, whose voice ID (after alias resolution)
+	// is not formatted like one, with a *ValidationError, before they are
+	// sent (optional)
+	ValidateVoiceIDs bool
+	// DisableTextNormalization sends request text exactly as given instead
+	// of cleaning it with NormalizeText first (optional)
+	DisableTextNormalization bool
+	// Pronunciations rewrites words the voices mispronounce, such as brand
+	// names, and {word|hint} annotations into respellings before sending
+	// (optional)
+	Pronunciations *PronunciationLexicon
+	// Acronyms spells out, expands, or reads acronyms as words before
+	// sending (optional, defaults to sending them as written)
+	Acronyms *AcronymRules
+	// EmojiPolicy strips emoji from request text or spells them out
+	// (optional, defaults to passing them through)
+	EmojiPolicy EmojiPolicy
+	// AdjustKoreanParticles resolves Korean particles written in both
+	// forms, such as "은(는)", with AdjustKoreanParticles before sending
+	// (optional)
+	AdjustKoreanParticles bool
+	// VerbalizeNumbers rewrites numbers, dates, times, currency amounts,
+	// and phone numbers in English and Korean text as words with
+	// VerbalizeNumbers before sending (optional)
+	VerbalizeNumbers bool
+	// LanguageDetection detects the language of request text locally, to set
+	// an empty Language or warn about a mismatch before sending (optional,
+	// defaults to leaving it to the API)
+	LanguageDetection LanguageDetection
+	// Logger receives the client's warnings, such as the first call of each
+	// deprecated V1 method in the process, and DebugCurl's commands
+	// (optional, nothing is logged when nil)
+	Logger Logger
+	// SuppressDeprecationWarnings turns off the deprecated method warnings
+	// (optional)
+	SuppressDeprecationWarnings bool
+	// TraceContext returns the trace context to send with a request made
+	// with ctx, such as one read from an OpenTelemetry propagator
+	// (optional, defaults to TraceContextFromContext)
+	TraceContext func(ctx context.Context) TraceContext
+	// DisableTracePropagation stops sending traceparent, tracestate, and
+	// baggage headers (optional)
+	DisableTracePropagation bool
+	// PrefetchVoices fetches the voice catalog in the background when the
+	// client is created, so the first request does not wait for voice
+	// lookups, such as those of LanguageDetection, and a ResponseCache holds
+	// the catalog. A failure is logged to Logger (optional)
+	PrefetchVoices bool
+	// ResponseCache caches the responses of metadata endpoints, such as the
+	// voice catalog, for as long as their Cache-Control or Expires headers
+	// allow (optional)
+	ResponseCache *ResponseCache
+	// Metrics collects latency and payload size histograms per endpoint
+	// and model (optional). Share one between clients to aggregate them.
+	Metrics *Metrics
+	// UsageSink receives the characters and audio seconds of each
+	// successful synthesis, attributed to the tenant from WithTenant
+	// (optional)
+	UsageSink UsageSink
+	// AuditSink receives a record of every API call: the identity from
+	// WithIdentity, the endpoint, voice, and a hash of the text, and the
+	// result (optional)
+	AuditSink AuditSink
+	// AuditTextExcerpt is the number of leading characters of the text
+	// included in audit records (optional, defaults to 0, recording only
+	// its hash)
+	AuditTextExcerpt int
+	// LogText shows the text of requests verbatim in DebugCurl logs and
+	// error messages (optional, defaults to false, passing it through
+	// RedactText)
+	LogText bool
+	// RedactText renders the text of requests in DebugCurl logs and error
+	// messages (optional, defaults to RedactHash)
+	RedactText Redactor
+	// DebugCurl logs every request sent to the API, including retries, to
+	// Logger as a curl command that reproduces it, with the API key
+	// replaced by $TYPECAST_API_KEY (optional, ignored without a Logger)
+	DebugCurl bool
+	// ReturnPendingJobs makes TextToSpeech return a *PendingJob error when
+	// the API accepts a request with 202 Accepted to finish later, instead
+	// of polling for the result itself (optional)
+	ReturnPendingJobs bool
+}
+
+// configure applies the options of config, other than the connection
+// settings newClient resolves itself, to c.
+func (c *Client) configure(config *ClientConfig) {
+	if config.HTTPClient != nil {
+		c.httpClient = config.HTTPClient
+	} else {
+		c.httpClient.Transport = newTransport(config)
+		c.ownsTransport = c.httpClient.Transport != nil
+	}
+	c.voiceAliases = config.VoiceAliases
+	if config.MaxConcurrentRequests > 0 {
+		c.slots = newSlotScheduler(config.MaxConcurrentRequests)
+	}
+	c.rateLimiter = config.RateLimiter
+	c.maxRetries = config.MaxRetries
+	c.retryBudget = config.RetryBudget
+	c.maxElapsedTime = config.MaxElapsedTime
+	c.retryHook = config.ShouldRetry
+	c.backoff = config.Backoff
+	c.clock = clockOrSystem(config.Clock)
+	c.verifyAudioFormat = config.VerifyAudioFormat
+	c.quotaGuard = config.QuotaGuard
+	c.onRateLimit = config.OnRateLimit
+	c.omitTagHeaders = config.OmitTagHeaders
+	c.validateVoiceIDs = config.ValidateVoiceIDs
+	c.disableTextNormalization = config.DisableTextNormalization
+	c.pronunciations = config.Pronunciations
+	c.acronyms = config.Acronyms
+	c.emojiPolicy = config.EmojiPolicy
+	c.verbalizeNumbers = config.VerbalizeNumbers
+	c.adjustKoreanParticles = config.AdjustKoreanParticles
+	c.languageDetection = config.LanguageDetection
+	c.logger = config.Logger
+	c.suppressDeprecationWarnings = config.SuppressDeprecationWarnings
+	c.debugCurl = config.DebugCurl
+	c.metrics = config.Metrics
+	c.usageSink = config.UsageSink
+	c.auditSink = config.AuditSink
+	c.auditTextExcerpt = config.AuditTextExcerpt
+	c.logText = config.LogText
+	c.redactText = config.RedactText
+	if config.ResponseCache != nil {
+		c.responseCache = config.ResponseCache
+		c.startRevalidations()
+	}
+	c.traceContext = config.TraceContext
+	c.disableTracePropagation = config.DisableTracePropagation
+	c.returnPendingJobs = config.ReturnPendingJobs
+	if config.PrefetchVoices {
+		c.prefetchVoices()
+	}
+}
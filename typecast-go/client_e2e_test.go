@@ -76,14 +76,7 @@ func TestGetVoicesV2WithFilter(t *testing.T) {
 
 	// Verify all voices support ssfm-v30
 	for _, voice := range voices {
-		hasV30 := false
-		for _, model := range voice.Models {
-			if model.Version == ModelSSFMV30 {
-				hasV30 = true
-				break
-			}
-		}
-		if !hasV30 {
+		if !voice.SupportsModel(ModelSSFMV30) {
 			t.Errorf("Voice %s should support ssfm-v30", voice.VoiceID)
 		}
 	}
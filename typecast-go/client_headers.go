@@ -0,0 +1,78 @@
+package typecast
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+func (c *Client) setAuthHeader(headers http.Header) error {
+	apiKey := strings.TrimSpace(c.apiKey)
+	if apiKey == "" {
+		if c.environment.production() && isDefaultBaseURL(c.baseURL) {
+			return fmt.Errorf("API key is required for the default Typecast API host")
+		}
+		return nil
+	}
+	headers.Set("X-API-KEY", apiKey)
+	return nil
+}
+
+func (c *Client) setUserAgent(headers http.Header) {
+	base := "custom"
+	if isDefaultBaseURL(c.baseURL) {
+		base = "default"
+	}
+	timeout := "default"
+	if c.httpClient != nil && c.httpClient.Timeout > 0 && c.httpClient.Timeout != DefaultTimeout {
+		timeout = c.httpClient.Timeout.String()
+	}
+	headers.Set(
+		"User-Agent",
+		fmt.Sprintf(
+			"typecast-go/%s Go/%s net-http (base=%s; timeout=%s; os=%s; arch=%s; sdk_env=go; platform=server)",
+			SDKVersion,
+			strings.TrimPrefix(runtime.Version(), "go"),
+			base,
+			timeout,
+			normalizedOS(runtime.GOOS),
+			normalizedArch(runtime.GOARCH),
+		),
+	)
+}
+
+func normalizedOS(os string) string {
+	switch os {
+	case "darwin":
+		return "macos"
+	case "windows":
+		return "windows"
+	default:
+		if os == "" {
+			return "unknown"
+		}
+		return os
+	}
+}
+
+func normalizedArch(arch string) string {
+	switch arch {
+	case "amd64":
+		return "x64"
+	case "386":
+		return "x86"
+	case "arm64":
+		return "arm64"
+	default:
+		if arch == "" {
+			return "unknown"
+		}
+		return arch
+	}
+}
+
+func isDefaultBaseURL(baseURL string) bool {
+	normalized := strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	return strings.EqualFold(normalized, DefaultBaseURL)
+}
@@ -345,7 +345,7 @@ func TestTextToSpeech_NoDurationHeader(t *testing.T) {
 }
 
 func TestTextToSpeech_ErrorStatuses(t *testing.T) {
-	for _, code := range []int{400, 401, 402, 403, 404, 422, 429, 500, 418} {
+	for _, code := range []int{400, 401, 402, 403, 404, 422, 429, 500, 503, 418} {
 		code := code
 		t.Run(http.StatusText(code), func(t *testing.T) {
 			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1,6 +1,14 @@
 package typecast
 
-import "strings"
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
 
 // guessAudioMime returns a MIME type based on the audio filename extension.
 func guessAudioMime(filename string) string {
@@ -13,3 +21,63 @@ func guessAudioMime(filename string) string {
 	}
 	return "application/octet-stream"
 }
+
+// CloneVoice creates a custom voice (created via instant cloning) from an audio sample.
+//
+// audio is the raw audio bytes. filename is the multipart filename hint
+// (e.g., "sample.wav") and is used for MIME type inference. name is 1-30
+// characters; model is "ssfm-v21" or "ssfm-v30".
+//
+// The returned CustomVoice has VoiceID with "uc_" prefix; use it directly
+// with TextToSpeech as voice_id.
+func (c *Client) CloneVoice(ctx context.Context, audio []byte, filename, name, model string) (*CustomVoice, error) {
+	if len(name) < NameMinLength || len(name) > NameMaxLength {
+		return nil, fmt.Errorf("name must be %d-%d characters; got %d", NameMinLength, NameMaxLength, len(name))
+	}
+	if int64(len(audio)) > CloningMaxFileSize {
+		return nil, fmt.Errorf("audio file exceeds 25MB limit; got %d bytes", len(audio))
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	_ = writer.WriteField("name", name)
+	_ = writer.WriteField("model", model)
+
+	fileHeader := make(textproto.MIMEHeader)
+	fileHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, filename))
+	fileHeader.Set("Content-Type", guessAudioMime(filename))
+	filePart, _ := writer.CreatePart(fileHeader)
+	_, _ = filePart.Write(audio)
+	_ = writer.Close()
+
+	reqURL, err := c.endpoint("/v1/voices/clone")
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := c.setAuthHeader(req.Header); err != nil {
+		return nil, err
+	}
+	c.setUserAgent(req.Header)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var out CustomVoice
+	if err := decodeJSON(resp, "clone voice response", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,241 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// GetVoicesV2 retrieves the list of available voices with enhanced metadata (V2 API)
+func (c *Client) GetVoicesV2(ctx context.Context, filter *VoicesV2Filter) ([]VoiceV2, error) {
+	var voices []VoiceV2
+	err := c.EachVoiceV2(ctx, filter, func(voice VoiceV2) error {
+		voices = append(voices, voice)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return voices, nil
+}
+
+// EachVoiceV2 streams the V2 voice catalog, decoding one voice at a time and
+// passing it to fn, so memory stays flat regardless of catalog size.
+// Iteration stops at the first error returned by fn, which EachVoiceV2 returns.
+//
+// Filters with several values for one parameter are sent as repeated query
+// parameters. If the API rejects them, the voices are fetched with the
+// single-valued parameters only and the rest are filtered client-side.
+// Tag filters are always applied client-side.
+func (c *Client) EachVoiceV2(ctx context.Context, filter *VoicesV2Filter, fn func(VoiceV2) error) error {
+	_, err := c.eachVoiceV2(ctx, filter, nil, fn)
+	return err
+}
+
+// eachVoiceV2 is EachVoiceV2 with extra request headers. It returns the
+// response, whose body is closed, so callers can read its headers and
+// status, which is http.StatusNotModified when fn was not called because
+// of a conditional request.
+func (c *Client) eachVoiceV2(ctx context.Context, filter *VoicesV2Filter, header http.Header, fn func(VoiceV2) error) (*http.Response, error) {
+	resp, err := c.doRequestWithHeader(ctx, http.MethodGet, voicesV2Path(filter), nil, header)
+	if err != nil {
+		return nil, err
+	}
+	if filter.multiValued() && rejectsRepeatedParams(resp) {
+		// Fall back to the single-valued parameters and filter the rest here.
+		resp.Body.Close()
+		resp, err = c.doRequestWithHeader(ctx, http.MethodGet, voicesV2Path(filter.singleValued()), nil, header)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return resp, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	clientSide := filter.clientSide()
+	body := &snippetReader{r: resp.Body}
+	dec := json.NewDecoder(body)
+	decodeErr := func(start int64, err error) error {
+		return newDecodeError(resp, "voices response", []VoiceV2(nil), body, start, err)
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, decodeErr(0, err)
+	}
+	if tok == nil {
+		return resp, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, decodeErr(dec.InputOffset(), fmt.Errorf("expected a JSON array, got %v", tok))
+	}
+	for dec.More() {
+		var voice VoiceV2
+		start := body.valueStart(dec.InputOffset())
+		if err := dec.Decode(&voice); err != nil {
+			return nil, decodeErr(start, err)
+		}
+		if clientSide && !filter.Matches(voice) {
+			continue
+		}
+		if err := fn(voice); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, decodeErr(0, err)
+	}
+	return resp, nil
+}
+
+func voicesV2Path(filter *VoicesV2Filter) string {
+	path := "/v2/voices"
+
+	// Build query parameters
+	if filter != nil {
+		params := url.Values{}
+		for key, values := range filter.queryValues() {
+			for _, v := range values {
+				params.Add(key, v)
+			}
+		}
+		if len(params) > 0 {
+			path = path + "?" + params.Encode()
+		}
+	}
+	return path
+}
+
+// GetVoiceV2 retrieves a specific voice by ID with enhanced metadata (V2 API)
+func (c *Client) GetVoiceV2(ctx context.Context, voiceID string) (*VoiceV2, error) {
+	result, err := c.GetVoiceV2Conditional(ctx, voiceID, Validators{})
+	if err != nil {
+		return nil, err
+	}
+	return result.Voice, nil
+}
+
+// RecommendVoices recommends voices from a text description.
+//
+// Results only contain VoiceID, VoiceName, and Score. Use GetVoiceV2 or
+// GetVoicesV2 when you need detailed metadata for the returned voice IDs.
+// count must be between 1 and 10 and defaults to 5 when zero.
+func (c *Client) RecommendVoices(ctx context.Context, query string, count int) ([]RecommendedVoice, error) {
+	if count == 0 {
+		count = 5
+	}
+	if count < 1 || count > 10 {
+		return nil, fmt.Errorf("count must be between 1 and 10")
+	}
+
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("count", strconv.Itoa(count))
+	path := "/v1/voices/recommendations?" + params.Encode()
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var voices []RecommendedVoice
+	if err := decodeJSON(resp, "voice recommendations response", &voices); err != nil {
+		return nil, err
+	}
+
+	return voices, nil
+}
+
+// GetVoices retrieves the list of available voices (V1 API - deprecated)
+// Deprecated: Use GetVoicesV2 for enhanced metadata and filtering options
+func (c *Client) GetVoices(ctx context.Context, model TTSModel) ([]VoiceV1, error) {
+	c.warnDeprecated("GetVoices", "GetVoicesV2")
+	path := "/v1/voices"
+	if model != "" {
+		path = path + "?model=" + string(model)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var voices []VoiceV1
+	if err := decodeJSON(resp, "voices response", &voices); err != nil {
+		return nil, err
+	}
+
+	return voices, nil
+}
+
+// GetVoice retrieves a specific voice by ID (V1 API - deprecated)
+// Deprecated: Use GetVoiceV2 for enhanced metadata
+func (c *Client) GetVoice(ctx context.Context, voiceID string, model TTSModel) ([]VoiceV1, error) {
+	c.warnDeprecated("GetVoice", "GetVoiceV2")
+	path := fmt.Sprintf("/v1/voices/%s", voiceID)
+	if model != "" {
+		path = path + "?model=" + string(model)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var voices []VoiceV1
+	if err := decodeJSON(resp, "voice response", &voices); err != nil {
+		return nil, err
+	}
+
+	return voices, nil
+}
+
+// DeleteVoice soft-deletes a custom voice by ID.
+// Returns nil on a 200 OK or 204 No Content response.
+func (c *Client) DeleteVoice(ctx context.Context, voiceID string) error {
+	reqURL, err := c.endpoint("/v1/voices/" + voiceID)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := c.setAuthHeader(req.Header); err != nil {
+		return err
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return c.handleErrorResponse(resp)
+	}
+	return nil
+}
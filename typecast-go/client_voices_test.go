@@ -0,0 +1,73 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func voicesServer(t *testing.T, body string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestEachVoiceV2_StreamsVoices(t *testing.T) {
+	srv := voicesServer(t, `[{"voice_id":"a","voice_name":"A"},{"voice_id":"b","voice_name":"B"}]`)
+	var ids []string
+	err := newTestClient(srv, "k").EachVoiceV2(context.Background(), nil, func(v VoiceV2) error {
+		ids = append(ids, v.VoiceID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachVoiceV2() error = %v", err)
+	}
+	if strings.Join(ids, ",") != "a,b" {
+		t.Fatalf("unexpected voices: %v", ids)
+	}
+}
+
+func TestEachVoiceV2_CallbackErrorStopsIteration(t *testing.T) {
+	srv := voicesServer(t, `[{"voice_id":"a"},{"voice_id":"b"}]`)
+	stop := errors.New("stop")
+	calls := 0
+	err := newTestClient(srv, "k").EachVoiceV2(context.Background(), nil, func(v VoiceV2) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Fatalf("expected stop after one voice, got err=%v calls=%d", err, calls)
+	}
+}
+
+func TestEachVoiceV2_NullBodyYieldsNoVoices(t *testing.T) {
+	srv := voicesServer(t, `null`)
+	voices, err := newTestClient(srv, "k").GetVoicesV2(context.Background(), nil)
+	if err != nil || voices != nil {
+		t.Fatalf("expected no voices, got %v, %v", voices, err)
+	}
+}
+
+func TestEachVoiceV2_DecodeErrors(t *testing.T) {
+	cases := map[string]string{
+		"empty body":      ``,
+		"not an array":    `{"voice_id":"a"}`,
+		"bad element":     `[{"voice_id":1}]`,
+		"truncated array": `[{"voice_id":"a"}`,
+		"bad terminator":  `[{"voice_id":"a"}}`,
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := voicesServer(t, body)
+			err := newTestClient(srv, "k").EachVoiceV2(context.Background(), nil, func(VoiceV2) error { return nil })
+			if err == nil || !strings.Contains(err.Error(), "failed to decode voices response") {
+				t.Fatalf("expected decode error, got %v", err)
+			}
+		})
+	}
+}
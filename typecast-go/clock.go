@@ -0,0 +1,107 @@
+package typecast
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the source of time for retry backoff, rate limiting, quota
+// windows, and cache expiry. The default, SystemClock, uses the time
+// package; tests can pass a FakeClock to run those delays instantly.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer returns a timer that sends the time on its channel once d
+	// has elapsed, like time.NewTimer.
+	NewTimer(d time.Duration) Timer
+	// AfterFunc calls f in its own goroutine once d has elapsed, like
+	// time.AfterFunc.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by a Clock.
+type Timer interface {
+	// C returns the channel the time is sent on; it is nil for a timer
+	// created by AfterFunc.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing and reports whether it stopped
+	// it, like time.Timer.Stop.
+	Stop() bool
+}
+
+// SystemClock is the Clock of the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return systemTimer{time.AfterFunc(d, f)}
+}
+
+type systemTimer struct {
+	timer *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time { return t.timer.C }
+
+func (t systemTimer) Stop() bool { return t.timer.Stop() }
+
+// clockOrSystem returns clock, or SystemClock when it is nil.
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+	return clock
+}
+
+// sleepContext waits for d on clock or until ctx is done.
+func sleepContext(ctx context.Context, clock Clock, d time.Duration) error {
+	timer := clock.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ClockSkew returns how far the API's clock was ahead of the local clock
+// (negative when behind) on the last response with a Date header, or 0 if
+// none was seen yet. The Date header has a resolution of one second.
+func (c *Client) ClockSkew() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.clockSkew))
+}
+
+// observeServerDate records the clock skew from resp's Date header.
+func (c *Client) observeServerDate(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	if date, ok := serverDate(resp.Header); ok {
+		atomic.StoreInt64(&c.clockSkew, int64(date.Sub(c.clock.Now())))
+	}
+}
+
+// serverDate parses the Date header of a response.
+func serverDate(header http.Header) (time.Time, bool) {
+	date, err := http.ParseTime(header.Get("Date"))
+	return date, err == nil
+}
+
+// serverNow returns the time a response was sent by the API's clock: its
+// Date header, or the client's Clock when there is none. Absolute times in
+// the response, such as an HTTP-date Retry-After, are measured against it
+// so a drifting local clock does not distort them.
+func (c *Client) serverNow(header http.Header) time.Time {
+	if date, ok := serverDate(header); ok {
+		return date
+	}
+	return c.clock.Now()
+}
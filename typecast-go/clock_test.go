@@ -0,0 +1,71 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestClockSkew(t *testing.T) {
+	ahead := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	withDate := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if withDate {
+			w.Header().Set("Date", ahead.Format(http.TimeFormat))
+		} else {
+			w.Header()["Date"] = nil
+		}
+		if r.URL.Path == "/v1/users/me/subscription" {
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(ahead.Add(time.Minute).Unix(), 10))
+			w.Header().Set("Retry-After", ahead.Add(30*time.Second).Format(http.TimeFormat))
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	var state RateLimitState
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, OnRateLimit: func(s RateLimitState) { state = s }})
+	if c.ClockSkew() != 0 {
+		t.Fatalf("expected no skew before a response, got %v", c.ClockSkew())
+	}
+
+	_, err := c.GetMySubscription(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an APIError, got %v", err)
+	}
+	// The Retry-After date is measured against the server's clock, not
+	// the local one that is an hour behind.
+	if apiErr.RetryAfter != 30*time.Second || !apiErr.ServerDate.Equal(ahead) {
+		t.Fatalf("unexpected error %+v", apiErr)
+	}
+	if skew := c.ClockSkew(); skew < 58*time.Minute || skew > time.Hour {
+		t.Fatalf("unexpected skew %v", skew)
+	}
+	if state.RetryAfter != 30*time.Second || !state.ServerDate.Equal(ahead) {
+		t.Fatalf("unexpected state %+v", state)
+	}
+	if until := time.Until(state.Reset); until < 58*time.Second || until > 61*time.Second {
+		t.Fatalf("expected the reset a minute from now by the local clock, got %v", until)
+	}
+
+	// Responses without a Date keep the last skew.
+	withDate = false
+	if _, err := c.GetVoicesV2(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if skew := c.ClockSkew(); skew < 58*time.Minute {
+		t.Fatalf("unexpected skew %v", skew)
+	}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c = NewClient(&ClientConfig{APIKey: "k", Clock: NewFakeClock(start)})
+	if now := c.serverNow(http.Header{}); !now.Equal(start) {
+		t.Fatalf("expected the client's clock without a Date, got %v", now)
+	}
+}
@@ -0,0 +1,70 @@
+// Command typecast is a command-line client for the Typecast API.
+//
+// Usage:
+//
+//	typecast tts --voice VOICE_ID -o out.wav [text]
+//	typecast watch --voice VOICE_ID --in scripts/ --out audio/
+//	typecast serve --voice VOICE_ID --queue ./queue.db --out audio/
+//
+// Text is read from stdin when no text argument is given, so the command
+// composes with pipelines:
+//
+//	cat chapter.txt | typecast tts --voice VOICE_ID -o chapter.mp3
+//
+// watch synthesizes each .txt script or .json job file dropped into a
+// directory, writing the audio and a .status.json file per input.
+//
+// serve runs the gateway. With --queue it also processes the jobs posted to
+// /jobs, and with --schedules it enqueues the jobs of a JSON file on cron
+// schedules, such as a daily report at 06:00. Callers must send a token of
+// --token or TYPECAST_GATEWAY_TOKENS when one is set, and one must be set to
+// listen on an address other than a loopback one.
+//
+// The API key is read from TYPECAST_API_KEY and the host from
+// TYPECAST_API_HOST. --voice takes a voice ID or an alias from the JSON file
+// of --aliases, which defaults to TYPECAST_VOICE_ALIASES.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+const usage = `usage: typecast <command> [flags]
+
+commands:
+  tts    synthesize text from arguments or stdin
+  watch  synthesize scripts dropped into a directory
+  serve  run the gateway and, with --queue, a job daemon
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command in args and returns the exit status.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "tts":
+		return runTTS(ctx, args[1:], stdin, stdout, stderr)
+	case "watch":
+		return runWatch(ctx, args[1:], stdout, stderr)
+	case "serve":
+		return runServe(ctx, args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "typecast: unknown command %q\n%s", args[0], usage)
+		return 2
+	}
+}
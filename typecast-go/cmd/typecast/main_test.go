@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testWAV returns a 16-bit mono WAV of n silent samples at 8 kHz.
+func testWAV(n int) []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(36+2*n))
+	b.WriteString("WAVEfmt ")
+	_ = binary.Write(&b, binary.LittleEndian, []uint32{16})
+	_ = binary.Write(&b, binary.LittleEndian, []uint16{1, 1})
+	_ = binary.Write(&b, binary.LittleEndian, []uint32{8000, 16000})
+	_ = binary.Write(&b, binary.LittleEndian, []uint16{2, 16})
+	b.WriteString("data")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(2*n))
+	b.Write(make([]byte, 2*n))
+	return b.Bytes()
+}
+
+// ttsServer records request texts and points the client at itself.
+func ttsServer(t *testing.T) (texts func() []string) {
+	var mu sync.Mutex
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Text == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		got = append(got, body.Text)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write(testWAV(800))
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("TYPECAST_API_HOST", srv.URL)
+	t.Setenv("TYPECAST_API_KEY", "k")
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), got...)
+	}
+}
+
+func TestTTSFromStdin(t *testing.T) {
+	texts := ttsServer(t)
+	out := filepath.Join(t.TempDir(), "out.wav")
+	stdin := strings.NewReader("First sentence here. Second sentence here. Third one.\n")
+	var stderr bytes.Buffer
+	if code := run(context.Background(), []string{"tts", "--voice", "v", "--chunk", "25", "-o", out}, stdin, &bytes.Buffer{}, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if got := strings.Join(texts(), "|"); got != "First sentence here.|Second sentence here.|Third one." {
+		t.Fatalf("unexpected requests %q", got)
+	}
+	audio, err := os.ReadFile(out)
+	if err != nil || len(audio) != len(testWAV(2400)) {
+		t.Fatalf("unexpected output of %d bytes: %v", len(audio), err)
+	}
+	if !strings.Contains(stderr.String(), "3 segments") {
+		t.Fatalf("unexpected summary %q", stderr.String())
+	}
+}
+
+func TestTTSToStdout(t *testing.T) {
+	texts := ttsServer(t)
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"tts", "--voice", "v", "-o", "-", "Hello", "there."}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if got := texts(); len(got) != 1 || got[0] != "Hello there." {
+		t.Fatalf("unexpected requests %q", got)
+	}
+	if !bytes.Equal(stdout.Bytes(), testWAV(800)) || stderr.Len() != 0 {
+		t.Fatalf("unexpected output: %d bytes, stderr %q", stdout.Len(), stderr.String())
+	}
+}
+
+func TestTTSVoiceAlias(t *testing.T) {
+	voices := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			VoiceID string `json:"voice_id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		voices <- body.VoiceID
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write(testWAV(800))
+	}))
+	defer srv.Close()
+	t.Setenv("TYPECAST_API_HOST", srv.URL)
+	t.Setenv("TYPECAST_API_KEY", "k")
+	aliases := filepath.Join(t.TempDir(), "aliases.json")
+	if err := os.WriteFile(aliases, []byte(`{"narrator": "tc_narrator"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TYPECAST_VOICE_ALIASES", aliases)
+
+	var stderr bytes.Buffer
+	if code := run(context.Background(), []string{"tts", "--voice", "narrator", "-o", "-", "Hello."}, nil, &bytes.Buffer{}, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if voice := <-voices; voice != "tc_narrator" {
+		t.Fatalf("expected the alias to resolve, got %q", voice)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("boom") }
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("closed pipe") }
+
+func TestRunErrors(t *testing.T) {
+	ttsServer(t)
+	dir := t.TempDir()
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		stdout interface{ Write([]byte) (int, error) }
+		code   int
+		stderr string
+	}{
+		{"no command", nil, "", nil, 2, "usage"},
+		{"unknown command", []string{"speak"}, "", nil, 2, `unknown command "speak"`},
+		{"bad flag", []string{"tts", "--nope"}, "", nil, 2, "flag provided but not defined"},
+		{"missing voice", []string{"tts", "-o", "x.wav"}, "hi", nil, 2, "--voice and -o are required"},
+		{"empty text", []string{"tts", "--voice", "v", "-o", "x.wav"}, " \n", nil, 2, "no text"},
+		{"api error", []string{"tts", "--voice", "v", "-o", "-", "fail"}, "", nil, 1, "segment 0"},
+		{"bad format", []string{"tts", "--voice", "v", "--format", "ogg", "-o", "-", "hi"}, "", nil, 1, "audio_format"},
+		{"unwritable output", []string{"tts", "--voice", "v", "-o", dir, "hi"}, "", nil, 1, dir},
+		{"closed stdout", []string{"tts", "--voice", "v", "-o", "-", "hi"}, "", failingWriter{}, 1, "closed pipe"},
+		{"missing aliases", []string{"tts", "--voice", "narrator", "--aliases", filepath.Join(dir, "none.json"), "-o", "-", "hi"}, "", nil, 1, "none.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			stdout := tt.stdout
+			if stdout == nil {
+				stdout = &bytes.Buffer{}
+			}
+			code := run(context.Background(), tt.args, strings.NewReader(tt.stdin), stdout, &stderr)
+			if code != tt.code || !strings.Contains(stderr.String(), tt.stderr) {
+				t.Fatalf("exit %d, stderr %q", code, stderr.String())
+			}
+		})
+	}
+
+	var stderr bytes.Buffer
+	if code := run(context.Background(), []string{"tts", "--voice", "v", "-o", "-"}, failingReader{}, &bytes.Buffer{}, &stderr); code != 1 || !strings.Contains(stderr.String(), "reading stdin: boom") {
+		t.Fatalf("exit %d, stderr %q", code, stderr.String())
+	}
+	var stdout bytes.Buffer
+	if code := run(context.Background(), []string{"help"}, nil, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "tts") {
+		t.Fatalf("exit %d, stdout %q", code, stdout.String())
+	}
+}
+
+func TestTTSFormatFromExtension(t *testing.T) {
+	var format string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Output struct {
+				AudioFormat string `json:"audio_format"`
+			} `json:"output"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		format = body.Output.AudioFormat
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	t.Setenv("TYPECAST_API_HOST", srv.URL)
+
+	run(context.Background(), []string{"tts", "--voice", "v", "-o", "out.MP3", "Hi."}, nil, &bytes.Buffer{}, &bytes.Buffer{})
+	if format != "mp3" {
+		t.Fatalf("requested %q, want mp3", format)
+	}
+}
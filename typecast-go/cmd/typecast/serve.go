@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+	"github.com/neosapience/typecast-sdk/typecast-go/server"
+)
+
+// runServe runs the gateway until interrupted and, with --queue, the
+// workers that process the jobs posted to it and, with --schedules, those
+// enqueued on cron schedules.
+func runServe(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	profile, newClient := voiceFlags(flags)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	queuePath := flags.String("queue", "", "job queue file; enables POST /jobs and the job workers")
+	out := flags.String("out", ".", "directory for job audio")
+	format := flags.String("format", "", "audio format of jobs, wav or mp3 (default wav)")
+	chunk := flags.Int("chunk", 0, "maximum characters per request (defaults to 2000)")
+	workers := flags.Int("workers", 0, "jobs processed at once (defaults to 2)")
+	schedulesPath := flags.String("schedules", "", "JSON file of jobs enqueued on cron schedules; requires --queue")
+	tokens := map[string]string{}
+	flags.Func("token", "token callers must send, as NAME:TOKEN or TOKEN; repeatable (defaults to the comma-separated TYPECAST_GATEWAY_TOKENS)", func(s string) error {
+		return addToken(tokens, s)
+	})
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if len(tokens) == 0 {
+		for _, s := range strings.Split(os.Getenv("TYPECAST_GATEWAY_TOKENS"), ",") {
+			if err := addToken(tokens, s); err != nil {
+				fmt.Fprintln(stderr, "typecast serve: TYPECAST_GATEWAY_TOKENS:", err)
+				return 2
+			}
+		}
+	}
+	if len(tokens) == 0 && !isLoopback(*addr) {
+		fmt.Fprintf(stderr, "typecast serve: --token is required to listen on %s, which is not a loopback address\n", *addr)
+		flags.Usage()
+		return 2
+	}
+	if *queuePath != "" && profile.VoiceID == "" {
+		fmt.Fprintln(stderr, "typecast serve: --voice is required with --queue")
+		flags.Usage()
+		return 2
+	}
+	if *schedulesPath != "" && *queuePath == "" {
+		fmt.Fprintln(stderr, "typecast serve: --queue is required with --schedules")
+		flags.Usage()
+		return 2
+	}
+	profile.AudioFormat = typecast.AudioFormat(*format)
+	var schedules []typecast.ScheduledJob
+	if *schedulesPath != "" {
+		var err error
+		if schedules, err = typecast.LoadScheduledJobsFile(*schedulesPath); err != nil {
+			fmt.Fprintln(stderr, "typecast serve:", err)
+			return 1
+		}
+	}
+
+	client, err := newClient()
+	if err != nil {
+		fmt.Fprintln(stderr, "typecast serve:", err)
+		return 1
+	}
+	defer client.Close()
+	config := server.Config{Client: client, Tokens: tokens}
+	if *queuePath != "" {
+		queue, err := typecast.OpenJobQueue(*queuePath)
+		if err != nil {
+			fmt.Fprintln(stderr, "typecast serve:", err)
+			return 1
+		}
+		defer queue.Close()
+		config.Queue = queue
+	}
+	gateway, err := server.New(config)
+	if err != nil {
+		fmt.Fprintln(stderr, "typecast serve:", err)
+		return 1
+	}
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintln(stderr, "typecast serve:", err)
+		return 1
+	}
+	httpServer := &http.Server{Handler: gateway}
+	go func() { _ = httpServer.Serve(listener) }()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+	fmt.Fprintf(stderr, "serving on http://%s\n", listener.Addr())
+
+	if config.Queue == nil {
+		<-ctx.Done()
+		return 0
+	}
+	err = client.RunDaemon(ctx, typecast.DaemonConfig{
+		Queue:         config.Queue,
+		OutputDir:     *out,
+		Profile:       *profile,
+		Workers:       *workers,
+		MaxChunkChars: *chunk,
+		Schedules:     schedules,
+		OnJob: func(job typecast.QueuedJob) {
+			switch job.State {
+			case typecast.JobDone:
+				fmt.Fprintf(stdout, "%s: wrote %s (%.2fs)\n", job.ID, job.Audio, job.Duration)
+			case typecast.JobFailed:
+				fmt.Fprintf(stdout, "%s: failed: %s\n", job.ID, job.Error)
+			}
+		},
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintln(stderr, "typecast serve:", err)
+		return 1
+	}
+	return 0
+}
+
+// addToken adds a --token value, NAME:TOKEN or a bare TOKEN named
+// "default", to tokens. The name ends at the first colon, so tokens such as
+// base64 ones may contain "=". An empty token would match callers that
+// send none, so it is rejected.
+func addToken(tokens map[string]string, s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	name, token := "default", s
+	if i := strings.Index(s, ":"); i >= 0 {
+		name, token = s[:i], s[i+1:]
+	}
+	if token == "" {
+		return fmt.Errorf("token of %s is empty", name)
+	}
+	if name == "" {
+		return errors.New("token name is empty; use NAME:TOKEN or TOKEN")
+	}
+	tokens[token] = name
+	return nil
+}
+
+// isLoopback reports whether addr, a host and port to listen on, only
+// accepts connections from this machine.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
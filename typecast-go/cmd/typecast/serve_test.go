@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+// freeAddr returns a local address nothing listens on.
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestServeQueue(t *testing.T) {
+	texts := ttsServer(t)
+	dir := t.TempDir()
+	addr := freeAddr(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		for ctx.Err() == nil {
+			resp, err := http.Post("http://"+addr+"/jobs", "application/json", strings.NewReader(`{"id": "daily", "job": {"text": "Daily report."}}`))
+			if err == nil {
+				resp.Body.Close()
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		for ctx.Err() == nil {
+			resp, err := http.Get("http://" + addr + "/jobs/daily")
+			if err == nil {
+				var job typecast.QueuedJob
+				_ = json.NewDecoder(resp.Body).Decode(&job)
+				resp.Body.Close()
+				if job.State == typecast.JobDone {
+					cancel()
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+	var stdout, stderr bytes.Buffer
+	args := []string{"serve", "--voice", "v", "--addr", addr, "--queue", filepath.Join(dir, "queue.db"), "--out", dir}
+	if code := run(ctx, args, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "daily: wrote daily.wav") || len(texts()) != 1 {
+		t.Fatalf("unexpected output %q", stdout.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "daily.wav")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	ttsServer(t)
+	dir := t.TempDir()
+	busy, _ := net.Listen("tcp", "127.0.0.1:0")
+	defer busy.Close()
+	_ = os.WriteFile(filepath.Join(dir, "file"), nil, 0644)
+	tests := []struct {
+		args   []string
+		code   int
+		output string
+	}{
+		{[]string{"serve", "--nope"}, 2, "flag provided but not defined"},
+		{[]string{"serve", "--queue", "q.db"}, 2, "--voice is required with --queue"},
+		{[]string{"serve", "--voice", "v", "--queue", dir}, 1, "failed to read job queue"},
+		{[]string{"serve", "--schedules", "s.json"}, 2, "--queue is required with --schedules"},
+		{[]string{"serve", "--voice", "v", "--queue", "q.db", "--schedules", filepath.Join(dir, "missing.json")}, 1, "failed to open scheduled jobs"},
+		{[]string{"serve", "--addr", busy.Addr().String()}, 1, "address already in use"},
+		{[]string{"serve", "--addr", ":0"}, 2, "--token is required to listen on :0"},
+		{[]string{"serve", "--token", "alice:"}, 2, "token of alice is empty"},
+		{[]string{"serve", "--token", ":secret"}, 2, "token name is empty"},
+		{[]string{"serve", "--addr", "192.0.2.1:8080"}, 2, "not a loopback address"},
+		{[]string{"serve", "--addr", "nohost"}, 2, "not a loopback address"},
+		{[]string{"serve", "--voice", "v", "--addr", "127.0.0.1:0", "--queue", filepath.Join(dir, "q.db"), "--out", filepath.Join(dir, "file")}, 1, "not a directory"},
+	}
+	for _, tt := range tests {
+		var stderr bytes.Buffer
+		if code := run(context.Background(), tt.args, nil, &bytes.Buffer{}, &stderr); code != tt.code || !strings.Contains(stderr.String(), tt.output) {
+			t.Errorf("%v: exit %d, stderr %q", tt.args, code, stderr.String())
+		}
+	}
+
+	// Invalid schedules stop the daemon.
+	schedules := filepath.Join(dir, "schedules.json")
+	_ = os.WriteFile(schedules, []byte(`[{"name": "report", "schedule": "daily", "job": {"text": "Hi."}}]`), 0644)
+	var schedulesErr bytes.Buffer
+	args := []string{"serve", "--voice", "v", "--addr", "127.0.0.1:0", "--queue", filepath.Join(dir, "q.db"), "--schedules", schedules}
+	if code := run(context.Background(), args, nil, &bytes.Buffer{}, &schedulesErr); code != 1 || !strings.Contains(schedulesErr.String(), "invalid schedule") {
+		t.Errorf("invalid schedules: exit %d, stderr %q", code, schedulesErr.String())
+	}
+
+	// Without a queue, serve runs the gateway until interrupted.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var stderr bytes.Buffer
+	if code := run(ctx, []string{"serve", "--addr", "127.0.0.1:0"}, nil, &bytes.Buffer{}, &stderr); code != 0 || !strings.Contains(stderr.String(), "serving on http://127.0.0.1:") {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+}
+
+func TestServeTokens(t *testing.T) {
+	ttsServer(t)
+	t.Setenv("TYPECAST_GATEWAY_TOKENS", "ci:env-token, ")
+	addr := freeAddr(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	statuses := make(chan [2]int, 1)
+	go func() {
+		defer cancel()
+		status := func(token string) int {
+			req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/v2/voices", nil)
+			if token != "" {
+				req.Header.Set("X-API-KEY", token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return 0
+			}
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+		for ctx.Err() == nil {
+			if without := status(""); without != 0 {
+				statuses <- [2]int{without, status("flag-token==")}
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+	var stderr bytes.Buffer
+	// A bare base64 token keeps its padding as part of the token.
+	if code := run(ctx, []string{"serve", "--addr", addr, "--token", "flag-token=="}, nil, &bytes.Buffer{}, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if got := <-statuses; got[0] != http.StatusUnauthorized || got[1] == http.StatusUnauthorized {
+		t.Fatalf("expected only the token to be accepted, got %v", got)
+	}
+
+	// Without --token, TYPECAST_GATEWAY_TOKENS allows any address.
+	short, stop := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer stop()
+	if code := run(short, []string{"serve", "--addr", ":0"}, nil, &bytes.Buffer{}, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	t.Setenv("TYPECAST_GATEWAY_TOKENS", "ci:")
+	if code := run(short, []string{"serve", "--addr", ":0"}, nil, &bytes.Buffer{}, &stderr); code != 2 || !strings.Contains(stderr.String(), "TYPECAST_GATEWAY_TOKENS: token of ci is empty") {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+// runTTS synthesizes text from its arguments, or from stdin when there are
+// none. Text of any length is chunked at sentence boundaries and stitched.
+func runTTS(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("tts", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	profile, newClient := voiceFlags(flags)
+	output := flags.String("o", "", `output file, or "-" for stdout (required)`)
+	format := flags.String("format", "", "audio format, wav or mp3 (defaults to the output file's extension)")
+	chunk := flags.Int("chunk", 0, "maximum characters per request (defaults to 2000)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if profile.VoiceID == "" || *output == "" {
+		fmt.Fprintln(stderr, "typecast tts: --voice and -o are required")
+		flags.Usage()
+		return 2
+	}
+
+	var text string
+	if flags.NArg() > 0 {
+		text = strings.Join(flags.Args(), " ")
+	} else {
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintln(stderr, "typecast tts: reading stdin:", err)
+			return 1
+		}
+		text = string(b)
+	}
+	if strings.TrimSpace(text) == "" {
+		fmt.Fprintln(stderr, "typecast tts: no text to synthesize")
+		return 2
+	}
+
+	audioFormat := typecast.AudioFormat(*format)
+	if audioFormat == "" {
+		audioFormat = typecast.AudioFormatWAV
+		if strings.EqualFold(filepath.Ext(*output), ".mp3") {
+			audioFormat = typecast.AudioFormatMP3
+		}
+	}
+	profile.AudioFormat = audioFormat
+	client, err := newClient()
+	if err != nil {
+		fmt.Fprintln(stderr, "typecast tts:", err)
+		return 1
+	}
+	defer client.Close()
+	result, err := client.LongFormSynthesize(ctx, typecast.LongFormRequest{
+		Profile:       *profile,
+		Text:          text,
+		MaxChunkChars: *chunk,
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, "typecast tts:", err)
+		return 1
+	}
+
+	if *output == "-" {
+		_, err = stdout.Write(result.AudioData)
+	} else {
+		err = os.WriteFile(*output, result.AudioData, 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "typecast tts:", err)
+		return 1
+	}
+	if *output != "-" {
+		fmt.Fprintf(stderr, "wrote %s (%.2fs, %d segments)\n", *output, result.Duration, len(result.Segments))
+	}
+	return 0
+}
+
+// voiceFlags defines the voice flags shared by commands on flags. The
+// returned profile is filled in when flags are parsed, and newClient then
+// creates a client that resolves the aliases of --aliases.
+func voiceFlags(flags *flag.FlagSet) (profile *typecast.VoiceProfile, newClient func() (*typecast.Client, error)) {
+	profile = &typecast.VoiceProfile{Model: typecast.ModelSSFMV30}
+	flags.StringVar(&profile.VoiceID, "voice", "", "voice ID or alias (required)")
+	aliases := flags.String("aliases", os.Getenv("TYPECAST_VOICE_ALIASES"), `JSON file of voice aliases, such as {"narrator": "tc_..."}`)
+	flags.Func("model", "model (default ssfm-v30)", func(s string) error {
+		profile.Model = typecast.TTSModel(s)
+		return nil
+	})
+	flags.StringVar(&profile.Language, "language", "", "ISO 639-3 language code (auto-detected if empty)")
+	flags.Func("emotion", "emotion preset", func(s string) error {
+		profile.EmotionPreset = typecast.EmotionPreset(s)
+		return nil
+	})
+	newClient = func() (*typecast.Client, error) {
+		config := &typecast.ClientConfig{}
+		if *aliases != "" {
+			registry, err := typecast.LoadVoiceRegistryFile(*aliases)
+			if err != nil {
+				return nil, err
+			}
+			config.VoiceAliases = registry
+		}
+		return typecast.NewClient(config), nil
+	}
+	return profile, newClient
+}
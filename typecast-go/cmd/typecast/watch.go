@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+// runWatch synthesizes the scripts dropped into a directory until
+// interrupted.
+func runWatch(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	profile, newClient := voiceFlags(flags)
+	in := flags.String("in", "", "directory to watch for .txt and .json files (required)")
+	out := flags.String("out", "", "directory for audio and status files (defaults to -in)")
+	format := flags.String("format", "", "audio format, wav or mp3 (default wav)")
+	chunk := flags.Int("chunk", 0, "maximum characters per request (defaults to 2000)")
+	interval := flags.Duration("interval", 2*time.Second, "time between polls")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if profile.VoiceID == "" || *in == "" {
+		fmt.Fprintln(stderr, "typecast watch: --voice and --in are required")
+		flags.Usage()
+		return 2
+	}
+	if *out == "" {
+		*out = *in
+	}
+	profile.AudioFormat = typecast.AudioFormat(*format)
+
+	client, err := newClient()
+	if err != nil {
+		fmt.Fprintln(stderr, "typecast watch:", err)
+		return 1
+	}
+	defer client.Close()
+	fmt.Fprintf(stderr, "watching %s\n", *in)
+	err = client.WatchFolder(ctx, typecast.WatchConfig{
+		InputDir:      *in,
+		OutputDir:     *out,
+		Profile:       *profile,
+		MaxChunkChars: *chunk,
+		Interval:      *interval,
+		OnStatus: func(s typecast.WatchStatus) {
+			switch s.State {
+			case typecast.WatchDone:
+				fmt.Fprintf(stdout, "%s: wrote %s (%.2fs)\n", s.Source, s.Audio, s.Duration)
+			case typecast.WatchFailed:
+				fmt.Fprintf(stdout, "%s: failed: %s\n", s.Source, s.Error)
+			}
+		},
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintln(stderr, "typecast watch:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	texts := ttsServer(t)
+	in := t.TempDir()
+	_ = os.WriteFile(filepath.Join(in, "intro.txt"), []byte("Welcome aboard."), 0644)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		for ctx.Err() == nil {
+			if _, err := os.Stat(filepath.Join(in, "intro.status.json")); err == nil && len(texts()) > 0 {
+				time.Sleep(20 * time.Millisecond)
+				cancel()
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	var stdout, stderr bytes.Buffer
+	if code := run(ctx, []string{"watch", "--voice", "v", "--in", in, "--interval", "1ms"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "intro.txt: wrote intro.wav") {
+		t.Fatalf("unexpected output %q", stdout.String())
+	}
+	if _, err := os.Stat(filepath.Join(in, "intro.wav")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchErrors(t *testing.T) {
+	ttsServer(t)
+	in := t.TempDir()
+	_ = os.WriteFile(filepath.Join(in, "bad.json"), []byte("{"), 0644)
+	tests := []struct {
+		args   []string
+		code   int
+		output string
+	}{
+		{[]string{"watch", "--nope"}, 2, "flag provided but not defined"},
+		{[]string{"watch", "--voice", "v"}, 2, "--voice and --in are required"},
+		{[]string{"watch", "--voice", "v", "--in", filepath.Join(in, "missing"), "--out", in}, 1, "no such file"},
+	}
+	for _, tt := range tests {
+		var stderr bytes.Buffer
+		if code := run(context.Background(), tt.args, nil, &bytes.Buffer{}, &stderr); code != tt.code || !strings.Contains(stderr.String(), tt.output) {
+			t.Errorf("%v: exit %d, stderr %q", tt.args, code, stderr.String())
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var stdout bytes.Buffer
+	code := run(ctx, []string{"watch", "--voice", "v", "--model", "ssfm-v21", "--emotion", "sad", "--in", in, "--interval", "1ms"}, nil, &stdout, &bytes.Buffer{})
+	if code != 1 || !strings.Contains(stdout.String(), "bad.json: failed: invalid job file") {
+		t.Fatalf("exit %d, stdout %q", code, stdout.String())
+	}
+}
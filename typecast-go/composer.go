@@ -114,6 +114,7 @@ func (c *SpeechComposer) Generate(ctx context.Context) (*TTSResponse, error) {
 	}
 
 	segments := make([]interface{}, 0, len(plan))
+	var texts []string
 	for _, part := range plan {
 		if part.kind == SpeechPartPause {
 			if !isValidPause(part.seconds) {
@@ -122,11 +123,30 @@ func (c *SpeechComposer) Generate(ctx context.Context) (*TTSResponse, error) {
 			segments = append(segments, composePauseSegment{Type: "pause", DurationSeconds: part.seconds})
 			continue
 		}
-		segments = append(segments, composeTTSSegment{Type: "tts", TTSRequest: requestFromComposerPart(part, outputFormat)})
+		request := requestFromComposerPart(part, outputFormat)
+		voiceID, err := c.client.synthesisVoiceID(request.VoiceID)
+		if err != nil {
+			return nil, err
+		}
+		request.VoiceID = voiceID
+		request.Text = c.client.normalizeText(request.Text, request.Language)
+		request.Language = c.client.requestLanguage(ctx, request.VoiceID, request.Language, request.Text)
+		segments = append(segments, composeTTSSegment{Type: "tts", TTSRequest: request})
+		texts = append(texts, request.Text)
 	}
-	return c.client.composeTextToSpeech(ctx, struct {
+	refund, err := c.client.reserveQuota(ctx, texts...)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.client.composeTextToSpeech(ctx, struct {
 		Segments []interface{} `json:"segments"`
 	}{segments})
+	if err != nil {
+		refund()
+		return nil, err
+	}
+	c.client.recordUsage(ctx, "/v1/text-to-speech/compose", "", "", response.Duration, texts...)
+	return response, nil
 }
 
 func (c *SpeechComposer) buildPlan() ([]composerPart, error) {
@@ -247,6 +267,15 @@ func mergeComposerOutput(base, override *Output) *Output {
 		if override.AudioFormat != "" {
 			merged.AudioFormat = override.AudioFormat
 		}
+		if override.SampleRate != nil {
+			merged.SampleRate = override.SampleRate
+		}
+		if override.Channels != nil {
+			merged.Channels = override.Channels
+		}
+		if override.Bitrate != nil {
+			merged.Bitrate = override.Bitrate
+		}
 	}
 	return &merged
 }
@@ -0,0 +1,84 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// ComposedStems is a composed dialogue with one track per speaker.
+type ComposedStems struct {
+	// TTSResponse holds the mixed WAV track
+	TTSResponse
+	// Stems maps each voice ID, as given to Defaults or SayWith, to a WAV
+	// track as long as the mix that holds only that voice's lines, with
+	// silence where others talk
+	Stems map[string][]byte
+}
+
+// GenerateStems synthesizes each line separately and assembles the mixed
+// track and one stem per speaker locally, so voices can be rebalanced in
+// post. It makes one request per line instead of a single compose request,
+// and requires WAV output.
+func (c *SpeechComposer) GenerateStems(ctx context.Context) (*ComposedStems, error) {
+	plan, err := c.buildPlan()
+	if err != nil {
+		return nil, err
+	}
+	var first *wavAudio
+	stems := map[string]*wavAudio{}
+	lines := make([]*wavAudio, len(plan))
+	line := 0
+	for i, part := range plan {
+		if part.kind != SpeechPartText {
+			continue
+		}
+		if part.settings.Output != nil && part.settings.Output.AudioFormat == AudioFormatMP3 {
+			return nil, fmt.Errorf("stems require wav output")
+		}
+		resp, err := c.client.TextToSpeech(ctx, requestFromComposerPart(part, AudioFormatWAV))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		wav, err := decodeWAV(resp.AudioData)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if first == nil {
+			first = wav
+		} else if !bytes.Equal(wav.format, first.format) {
+			return nil, fmt.Errorf("line %d: wav format differs from the first line", line)
+		}
+		lines[i] = wav
+		stems[part.settings.VoiceID] = &wavAudio{format: wav.format}
+		line++
+	}
+	if first == nil {
+		return nil, fmt.Errorf("at least one speech segment is required")
+	}
+
+	mix := &wavAudio{format: first.format}
+	for i, part := range plan {
+		data := make([]byte, first.pcmLength(part.seconds))
+		if lines[i] != nil {
+			data = lines[i].data
+		}
+		mix.data = append(mix.data, data...)
+		for voice, stem := range stems {
+			if lines[i] != nil && voice == part.settings.VoiceID {
+				stem.data = append(stem.data, data...)
+			} else {
+				stem.data = append(stem.data, make([]byte, len(data))...)
+			}
+		}
+	}
+
+	result := &ComposedStems{
+		TTSResponse: TTSResponse{AudioData: mix.encode(), Duration: mix.duration(), Format: AudioFormatWAV},
+		Stems:       make(map[string][]byte, len(stems)),
+	}
+	for voice, stem := range stems {
+		result.Stems[voice] = stem.encode()
+	}
+	return result, nil
+}
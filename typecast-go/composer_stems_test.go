@@ -0,0 +1,92 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// stemsServer answers each TTS request with WAV audio that depends on the
+// voice: voice "a" gets four 0x01 bytes, any other voice eight 0x02 bytes.
+func stemsServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Output == nil || body.Output.AudioFormat != AudioFormatWAV {
+			t.Errorf("each line must be requested as wav, got %+v", body.Output)
+		}
+		switch body.VoiceID {
+		case "a":
+			_, _ = w.Write(makeTestWAV(bytes.Repeat([]byte{1}, 4), 8000))
+		case "fast":
+			_, _ = w.Write(makeTestWAV(bytes.Repeat([]byte{1}, 4), 16000))
+		case "garbage":
+			_, _ = w.Write([]byte("not a wav"))
+		case "error":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			_, _ = w.Write(makeTestWAV(bytes.Repeat([]byte{2}, 8), 8000))
+		}
+	}))
+}
+
+func TestComposeSpeech_GenerateStems(t *testing.T) {
+	srv := stemsServer(t)
+	defer srv.Close()
+
+	result, err := newTestClient(srv, "k").ComposeSpeech().
+		Defaults(ComposerSettings{VoiceID: "a", Model: ModelSSFMV30}).
+		Say("Hi<|0.001s|>").
+		SayWith("Hello", ComposerSettings{VoiceID: "b"}).
+		Say("Bye").
+		GenerateStems(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateStems() error = %v", err)
+	}
+	a, b, gap := bytes.Repeat([]byte{1}, 4), bytes.Repeat([]byte{2}, 8), make([]byte, 16)
+	join := func(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+
+	mix, _ := decodeWAV(result.AudioData)
+	if !bytes.Equal(mix.data, join(a, gap, b, a)) || result.Format != AudioFormatWAV || !approx(result.Duration, 32.0/16000) {
+		t.Fatalf("unexpected mix % x", mix.data)
+	}
+	if len(result.Stems) != 2 {
+		t.Fatalf("expected one stem per voice, got %d", len(result.Stems))
+	}
+	stemA, _ := decodeWAV(result.Stems["a"])
+	stemB, _ := decodeWAV(result.Stems["b"])
+	if !bytes.Equal(stemA.data, join(a, gap, make([]byte, 8), a)) {
+		t.Fatalf("unexpected stem a % x", stemA.data)
+	}
+	if !bytes.Equal(stemB.data, join(make([]byte, 20), b, make([]byte, 4))) {
+		t.Fatalf("unexpected stem b % x", stemB.data)
+	}
+}
+
+func TestComposeSpeech_GenerateStemsErrors(t *testing.T) {
+	srv := stemsServer(t)
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	defaults := ComposerSettings{VoiceID: "a", Model: ModelSSFMV30}
+
+	tests := []struct {
+		composer *SpeechComposer
+		want     string
+	}{
+		{c.ComposeSpeech().Say("no voice"), "voice_id is required"},
+		{c.ComposeSpeech().Pause(1), "at least one speech segment"},
+		{c.ComposeSpeech().Defaults(defaults).SayWith("x", ComposerSettings{Output: &Output{AudioFormat: AudioFormatMP3}}), "stems require wav output"},
+		{c.ComposeSpeech().Defaults(defaults).Say("x").SayWith("y", ComposerSettings{VoiceID: "error"}), "line 1:"},
+		{c.ComposeSpeech().Defaults(defaults).SayWith("y", ComposerSettings{VoiceID: "garbage"}), "line 0: invalid wav"},
+		{c.ComposeSpeech().Defaults(defaults).Say("x").SayWith("y", ComposerSettings{VoiceID: "fast"}), "line 1: wav format differs from the first line"},
+	}
+	for _, tt := range tests {
+		if _, err := tt.composer.GenerateStems(context.Background()); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("GenerateStems() error = %v, want %q", err, tt.want)
+		}
+	}
+}
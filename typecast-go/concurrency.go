@@ -0,0 +1,51 @@
+package typecast
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// sendOnce executes a single attempt of req after consulting the shared
+// RateLimiter, holding one of the client's concurrency slots until the
+// response body is closed when MaxConcurrentRequests is configured.
+func (c *Client) sendOnce(req *http.Request) (*http.Response, error) {
+	if err := c.waitForRateLimit(req.Context()); err != nil {
+		return nil, err
+	}
+	if c.slots == nil {
+		return c.do(req)
+	}
+	if err := c.slots.acquire(req.Context()); err != nil {
+		return nil, fmt.Errorf("waiting for a request slot: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		c.slots.release()
+		return nil, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: c.slots.release}
+	return resp, nil
+}
+
+// do sends req with the HTTP client and records it in Metrics.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	started := c.clock.Now()
+	resp, err := c.httpClient.Do(req)
+	c.observeRequest(req, resp, err, c.clock.Now().Sub(started))
+	return resp, err
+}
+
+// releaseOnClose releases a concurrency slot exactly once when closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
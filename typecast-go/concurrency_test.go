@@ -0,0 +1,95 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaxConcurrentRequests_CapsInFlightRequests(t *testing.T) {
+	var inFlight, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte("WAV"))
+	}))
+	defer srv.Close()
+
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, MaxConcurrentRequests: 2})
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "t", Model: ModelSSFMV30}); err != nil {
+				t.Errorf("TextToSpeech() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if peak > 2 {
+		t.Fatalf("expected at most 2 concurrent requests, saw %d", peak)
+	}
+}
+
+func TestMaxConcurrentRequests_WaitingCallerHonorsContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte("WAV"))
+	}))
+	defer srv.Close()
+
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, MaxConcurrentRequests: 1})
+	stream, err := c.TextToSpeechStream(context.Background(), TTSRequestStream{VoiceID: "v", Text: "t", Model: ModelSSFMV30})
+	if err != nil {
+		t.Fatalf("TextToSpeechStream() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = c.TextToSpeech(ctx, &TTSRequest{VoiceID: "v", Text: "t", Model: ModelSSFMV30})
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "waiting for a request slot") {
+		t.Fatalf("expected slot wait deadline error, got %v", err)
+	}
+
+	// Closing the stream twice releases the slot exactly once.
+	stream.Close()
+	stream.Close()
+	if _, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "t", Model: ModelSSFMV30}); err != nil {
+		t.Fatalf("expected slot to be released, got %v", err)
+	}
+}
+
+func TestMaxConcurrentRequests_TransportErrorReleasesSlot(t *testing.T) {
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("dial boom")
+	})
+	c := NewClient(&ClientConfig{
+		APIKey:                "k",
+		BaseURL:               "http://example.invalid",
+		HTTPClient:            &http.Client{Transport: rt},
+		MaxConcurrentRequests: 1,
+	})
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		_, err := c.GetMySubscription(ctx)
+		cancel()
+		if err == nil || !strings.Contains(err.Error(), "dial boom") {
+			t.Fatalf("attempt %d: expected transport error, got %v", i, err)
+		}
+	}
+}
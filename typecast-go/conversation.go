@@ -0,0 +1,189 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// defaultTurnGap is the silence between turns when ConversationOptions.Gap
+// is not set.
+const defaultTurnGap = 0.4
+
+// Turn is one line of a conversation.
+type Turn struct {
+	// Speaker names the speaker in ConversationOptions.Speakers (required)
+	Speaker string
+	// Text is the line (required)
+	Text string
+	// Gap overrides ConversationOptions.Gap before this turn, in seconds. A
+	// negative gap starts the turn before the previous one ends, so the two
+	// overlap (optional)
+	Gap *float64
+}
+
+// ConversationOptions configures SynthesizeConversation.
+type ConversationOptions struct {
+	// Speakers maps speaker names to voice profiles (required). Their
+	// AudioFormat is ignored: the timeline is always WAV.
+	Speakers map[string]VoiceProfile
+	// Gap is the silence between turns in seconds (optional, defaults to
+	// 0.4)
+	Gap *float64
+	// NoSmartContext disables smart context. By default, ssfm-v30 turns
+	// whose speaker has no emotion preset or intensity are sent with the
+	// same speaker's previous and next lines as context, so emotion carries
+	// across that speaker's turns.
+	NoSmartContext bool
+}
+
+// ConversationTurn places one synthesized turn on the timeline.
+type ConversationTurn struct {
+	// Index is the turn's position in the conversation
+	Index int
+	// Speaker is the turn's speaker
+	Speaker string
+	// Text is the turn's line
+	Text string
+	// Start is the turn's offset in the timeline, in seconds
+	Start float64
+	// Duration is the turn's duration in seconds
+	Duration float64
+}
+
+// ConversationResult is the mixed timeline of a conversation.
+type ConversationResult struct {
+	TTSResponse
+	// Turns lists where each turn was placed, in order
+	Turns []ConversationTurn
+}
+
+// SynthesizeConversation synthesizes turns and places them on a single WAV
+// timeline, separated by the configured gaps. Overlapping turns are mixed.
+func (c *Client) SynthesizeConversation(ctx context.Context, turns []Turn, opts ConversationOptions) (*ConversationResult, error) {
+	if err := validateConversation(turns, opts); err != nil {
+		return nil, err
+	}
+	gap := defaultTurnGap
+	if opts.Gap != nil {
+		gap = *opts.Gap
+	}
+
+	var format *wavAudio
+	result := &ConversationResult{Turns: make([]ConversationTurn, 0, len(turns))}
+	var timeline []byte
+	offset, prevStart := 0, 0
+	for i, turn := range turns {
+		resp, err := c.TextToSpeech(ctx, conversationRequest(turns, i, opts))
+		if err != nil {
+			return nil, fmt.Errorf("turn %d: %w", i, err)
+		}
+		wav, err := decodeWAV(resp.AudioData)
+		if err != nil {
+			return nil, fmt.Errorf("turn %d: %w", i, err)
+		}
+		if format == nil {
+			if !isPCM16(wav) {
+				return nil, fmt.Errorf("turn %d: conversations require 16-bit PCM audio", i)
+			}
+			format = wav
+		} else if !bytes.Equal(wav.format, format.format) {
+			return nil, fmt.Errorf("turn %d: wav format differs from the first turn", i)
+		}
+
+		start := 0
+		if i > 0 {
+			turnGap := gap
+			if turn.Gap != nil {
+				turnGap = *turn.Gap
+			}
+			start = offset + int(math.Round(turnGap*float64(format.sampleRate())))*format.blockAlign()
+			if start < prevStart {
+				start = prevStart // a turn cannot start before the one it answers
+			}
+		}
+		if end := start + len(wav.data); end > len(timeline) {
+			timeline = append(timeline, make([]byte, end-len(timeline))...)
+		}
+		mixPCM16(timeline[start:], wav.data)
+		offset, prevStart = start+len(wav.data), start
+
+		bytesPerSecond := float64(format.sampleRate() * format.blockAlign())
+		result.Turns = append(result.Turns, ConversationTurn{
+			Index:    i,
+			Speaker:  turn.Speaker,
+			Text:     turn.Text,
+			Start:    float64(start) / bytesPerSecond,
+			Duration: float64(len(wav.data)) / bytesPerSecond,
+		})
+	}
+	mix := &wavAudio{format: format.format, data: timeline}
+	result.AudioData = mix.encode()
+	result.Duration = mix.duration()
+	result.Format = AudioFormatWAV
+	return result, nil
+}
+
+func validateConversation(turns []Turn, opts ConversationOptions) error {
+	if len(turns) == 0 {
+		return newValidationError("turns", "at least one turn is required")
+	}
+	for i, turn := range turns {
+		profile, ok := opts.Speakers[turn.Speaker]
+		if !ok {
+			return newValidationError("speaker", fmt.Sprintf("turn %d: unknown speaker %q", i, turn.Speaker))
+		}
+		if err := profile.Validate(); err != nil {
+			return fmt.Errorf("speaker %s: %w", turn.Speaker, err)
+		}
+		if strings.TrimSpace(turn.Text) == "" {
+			return newValidationError("text", fmt.Sprintf("turn %d: text is required", i))
+		}
+	}
+	return nil
+}
+
+// conversationRequest builds the WAV request of turn i, with the speaker's
+// neighbouring lines as smart context when it applies.
+func conversationRequest(turns []Turn, i int, opts ConversationOptions) *TTSRequest {
+	turn := turns[i]
+	profile := opts.Speakers[turn.Speaker]
+	request := profile.Request(turn.Text)
+	request.Output = mergeComposerOutput(request.Output, &Output{AudioFormat: AudioFormatWAV})
+	if opts.NoSmartContext || request.Model != ModelSSFMV30 || request.Prompt != nil {
+		return request
+	}
+	smart := &SmartPrompt{EmotionType: "smart"}
+	for j := i - 1; j >= 0; j-- {
+		if turns[j].Speaker == turn.Speaker {
+			smart.PreviousText = turns[j].Text
+			break
+		}
+	}
+	for j := i + 1; j < len(turns); j++ {
+		if turns[j].Speaker == turn.Speaker {
+			smart.NextText = turns[j].Text
+			break
+		}
+	}
+	if smart.PreviousText != "" || smart.NextText != "" {
+		request.Prompt = smart
+	}
+	return request
+}
+
+// mixPCM16 adds the 16-bit samples of src into dst, clipping at full scale.
+func mixPCM16(dst, src []byte) {
+	for i := 0; i+1 < len(src); i += 2 {
+		sum := int32(int16(binary.LittleEndian.Uint16(dst[i:]))) + int32(int16(binary.LittleEndian.Uint16(src[i:])))
+		if sum > math.MaxInt16 {
+			sum = math.MaxInt16
+		} else if sum < math.MinInt16 {
+			sum = math.MinInt16
+		}
+		binary.LittleEndian.PutUint16(dst[i:], uint16(int16(sum)))
+	}
+}
@@ -0,0 +1,160 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// pcm16 encodes samples as 16-bit little-endian PCM.
+func pcm16(samples ...int16) []byte {
+	b := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(b[2*i:], uint16(s))
+	}
+	return b
+}
+
+// conversationServer answers with four samples whose value depends on the
+// voice, and records each request's prompt.
+func conversationServer() (*httptest.Server, func() []map[string]interface{}) {
+	var mu sync.Mutex
+	var prompts []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			VoiceID string                 `json:"voice_id"`
+			Prompt  map[string]interface{} `json:"prompt"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		prompts = append(prompts, body.Prompt)
+		mu.Unlock()
+		switch body.VoiceID {
+		case "a":
+			_, _ = w.Write(makeTestWAV(pcm16(1000, 1000, 1000, 1000), 8000))
+		case "b":
+			_, _ = w.Write(makeTestWAV(pcm16(2000, 2000, 2000, 2000), 8000))
+		case "fast":
+			_, _ = w.Write(makeTestWAV(pcm16(1), 16000))
+		case "garbage":
+			_, _ = w.Write([]byte("not a wav"))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	return srv, func() []map[string]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return prompts
+	}
+}
+
+func TestSynthesizeConversation(t *testing.T) {
+	srv, prompts := conversationServer()
+	defer srv.Close()
+	gap, overlap := 0.001, -0.00025
+	opts := ConversationOptions{
+		Speakers: map[string]VoiceProfile{
+			"agent":    {VoiceID: "a"},
+			"customer": {VoiceID: "b", EmotionPreset: EmotionAngry},
+		},
+		Gap: &gap,
+	}
+	turns := []Turn{
+		{Speaker: "agent", Text: "How can I help?"},
+		{Speaker: "customer", Text: "My order is late!"},
+		{Speaker: "agent", Text: "I'm sorry to hear that.", Gap: &overlap},
+	}
+	result, err := newTestClient(srv, "k").SynthesizeConversation(context.Background(), turns, opts)
+	if err != nil {
+		t.Fatalf("SynthesizeConversation() error = %v", err)
+	}
+
+	// a, 8 samples of silence, b, and a overlapping the last two samples of b.
+	want := pcm16(1000, 1000, 1000, 1000, 0, 0, 0, 0, 0, 0, 0, 0, 2000, 2000, 3000, 3000, 1000, 1000)
+	mix, _ := decodeWAV(result.AudioData)
+	if !bytes.Equal(mix.data, want) || result.Format != AudioFormatWAV || !approx(result.Duration, 18.0/8000) {
+		t.Fatalf("unexpected timeline % x", mix.data)
+	}
+	if turn := result.Turns[2]; turn.Index != 2 || turn.Speaker != "agent" || !approx(turn.Start, 14.0/8000) || !approx(turn.Duration, 4.0/8000) {
+		t.Fatalf("unexpected turn: %+v", turn)
+	}
+
+	got := prompts()
+	if got[0]["emotion_type"] != "smart" || got[0]["next_text"] != "I'm sorry to hear that." || got[0]["previous_text"] != nil {
+		t.Fatalf("first agent turn must see the next agent turn: %v", got[0])
+	}
+	if got[2]["previous_text"] != "How can I help?" {
+		t.Fatalf("second agent turn must see the previous agent turn: %v", got[2])
+	}
+	if got[1]["emotion_type"] != "preset" {
+		t.Fatalf("an explicit emotion must be kept: %v", got[1])
+	}
+
+	opts.NoSmartContext = true
+	far := -1.0
+	turns[2].Gap = &far
+	result, err = newTestClient(srv, "k").SynthesizeConversation(context.Background(), turns, opts)
+	if err != nil {
+		t.Fatalf("SynthesizeConversation() error = %v", err)
+	}
+	if !approx(result.Turns[2].Start, result.Turns[1].Start) || prompts()[3] != nil {
+		t.Fatalf("a turn must not start before the previous one: %+v", result.Turns)
+	}
+	opts.NoSmartContext = false
+	if _, err := newTestClient(srv, "k").SynthesizeConversation(context.Background(), turns[:1], ConversationOptions{Speakers: opts.Speakers}); err != nil || prompts()[6] != nil {
+		t.Fatalf("a lone turn has no context to send: %v", err)
+	}
+}
+
+func TestSynthesizeConversation_Errors(t *testing.T) {
+	srv, _ := conversationServer()
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	speakers := map[string]VoiceProfile{"a": {VoiceID: "a"}, "fast": {VoiceID: "fast"}, "garbage": {VoiceID: "garbage"}, "fail": {VoiceID: "fail"}, "bad": {}}
+
+	tests := []struct {
+		turns []Turn
+		want  string
+	}{
+		{nil, "at least one turn is required"},
+		{[]Turn{{Speaker: "nobody", Text: "hi"}}, `turn 0: unknown speaker "nobody"`},
+		{[]Turn{{Speaker: "bad", Text: "hi"}}, "speaker bad: "},
+		{[]Turn{{Speaker: "a", Text: " "}}, "turn 0: text is required"},
+		{[]Turn{{Speaker: "a", Text: "hi"}, {Speaker: "fail", Text: "hi"}}, "turn 1: "},
+		{[]Turn{{Speaker: "garbage", Text: "hi"}}, "turn 0: invalid wav"},
+		{[]Turn{{Speaker: "a", Text: "hi"}, {Speaker: "fast", Text: "hi"}}, "turn 1: wav format differs from the first turn"},
+	}
+	for _, tt := range tests {
+		if _, err := c.SynthesizeConversation(context.Background(), tt.turns, ConversationOptions{Speakers: speakers}); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("SynthesizeConversation() error = %v, want %q", err, tt.want)
+		}
+	}
+	var validation *ValidationError
+	if _, err := c.SynthesizeConversation(context.Background(), nil, ConversationOptions{}); !errors.As(err, &validation) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	eightBit := makeTestWAV(make([]byte, 8), 8000)
+	binary.LittleEndian.PutUint16(eightBit[34:36], 8)
+	lofi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(eightBit) }))
+	defer lofi.Close()
+	if _, err := newTestClient(lofi, "k").SynthesizeConversation(context.Background(), []Turn{{Speaker: "a", Text: "hi"}}, ConversationOptions{Speakers: speakers}); err == nil || !strings.Contains(err.Error(), "16-bit PCM") {
+		t.Fatalf("expected format error, got %v", err)
+	}
+}
+
+func TestMixPCM16_Clips(t *testing.T) {
+	dst := pcm16(30000, -30000, 5)
+	mixPCM16(dst, pcm16(10000, -10000))
+	if !bytes.Equal(dst, pcm16(32767, -32768, 5)) {
+		t.Fatalf("mixPCM16() = % x", dst)
+	}
+}
@@ -0,0 +1,31 @@
+package typecast
+
+import (
+	"context"
+	"net/http"
+)
+
+// CorrelationIDHeader is the request header carrying the ID set with
+// WithCorrelationID.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// WithCorrelationID returns a context that tags requests made with it with
+// id. The ID is sent in the X-Correlation-ID header and reported on
+// APIError.CorrelationID, so TTS calls can be joined to upstream traces.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID attached to ctx, or "".
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDContextKey).(string)
+	return id
+}
+
+// setCorrelationID copies the request context's correlation ID, if any, into
+// the request headers.
+func setCorrelationID(req *http.Request) {
+	if id := CorrelationIDFromContext(req.Context()); id != "" {
+		req.Header.Set(CorrelationIDHeader, id)
+	}
+}
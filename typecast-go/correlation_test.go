@@ -0,0 +1,64 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCorrelationID_SentAndEchoedInErrors(t *testing.T) {
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get(CorrelationIDHeader))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"detail":"overloaded"}`))
+	}))
+	defer srv.Close()
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, MaxRetries: 1})
+	c.retryBaseDelay = time.Millisecond
+
+	ctx := WithCorrelationID(context.Background(), "req-42")
+	_, err := c.TextToSpeech(ctx, &TTSRequest{VoiceID: "v", Text: "hi", Model: ModelSSFMV30})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.CorrelationID != "req-42" {
+		t.Fatalf("expected APIError with correlation ID, got %#v", err)
+	}
+	if !strings.HasSuffix(err.Error(), "overloaded (correlation id req-42)") {
+		t.Fatalf("unexpected message: %v", err)
+	}
+	if strings.Join(seen, ",") != "req-42,req-42" {
+		t.Fatalf("headers = %q, want the ID on every attempt", seen)
+	}
+
+	seen = nil
+	if _, err := c.DownloadAudio(ctx, srv.URL, &bytes.Buffer{}, &DownloadOptions{MaxAttempts: 1}); err == nil {
+		t.Fatal("expected error")
+	}
+	if seen[0] != "req-42" {
+		t.Fatalf("download header = %q", seen[0])
+	}
+}
+
+func TestCorrelationID_AbsentByDefault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Header[CorrelationIDHeader]; ok {
+			t.Errorf("unexpected %s header", CorrelationIDHeader)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	_, err := newTestClient(srv, "k").GetVoiceV2(context.Background(), "missing")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.CorrelationID != "" || strings.Contains(err.Error(), "correlation") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if id := CorrelationIDFromContext(context.Background()); id != "" {
+		t.Fatalf("CorrelationIDFromContext() = %q", id)
+	}
+}
@@ -0,0 +1,172 @@
+package typecast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleAliases are the shorthands ParseSchedule accepts for common
+// cron expressions.
+var scheduleAliases = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField describes one field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names of the values from min, such as "jan"
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// Schedule is a parsed cron expression. Times are matched in the location
+// of the time passed to Next.
+type Schedule struct {
+	expr string
+	// fields holds a bit for each value each field matches.
+	fields [5]uint64
+	// anyDay and anyWeekday record a "*" day of month or day of week.
+	anyDay, anyWeekday bool
+}
+
+// ParseSchedule parses a cron expression of five fields: minute (0-59),
+// hour (0-23), day of month (1-31), month (1-12 or jan-dec), and day of
+// week (0-7 or sun-sat, where 0 and 7 are Sunday). A field is "*", a value,
+// a range such as "1-5", or a comma-separated list of them, and "/n" steps
+// through a range or "*". As in cron, a day matches when either day field
+// does if both are restricted. The aliases @yearly, @monthly, @weekly,
+// @daily, and @hourly are accepted as well. "0 6 * * 1-5" runs at 06:00
+// on weekdays.
+func ParseSchedule(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if alias, ok := scheduleAliases[strings.ToLower(spec)]; ok {
+		spec = alias
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("invalid schedule %q: want 5 fields, got %d", expr, len(parts))
+	}
+	s := &Schedule{expr: strings.TrimSpace(expr)}
+	for i, part := range parts {
+		bits, err := cronFields[i].parse(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", expr, err)
+		}
+		s.fields[i] = bits
+	}
+	if s.fields[4]&(1<<7) != 0 {
+		s.fields[4] |= 1 // 7 is Sunday, as 0 is
+	}
+	s.anyDay, s.anyWeekday = parts[2] == "*", parts[4] == "*"
+	return s, nil
+}
+
+// String returns the expression s was parsed from.
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// parse returns the bits of the values a field matches.
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		rng, step := item, 1
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field %q", f.name, item)
+			}
+			rng, step = item[:i], n
+		}
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			var err error
+			bounds := strings.SplitN(rng, "-", 2)
+			if lo, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = f.value(bounds[1]); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				hi = f.max // "5/15" steps from 5 to the end
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("invalid range in %s field %q", f.name, item)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value parses one value of a field, as a number or a name.
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid %s %q: want %d-%d", f.name, s, f.min, f.max)
+	}
+	return n, nil
+}
+
+// Next returns the first time after after, to the minute, that s matches,
+// in after's location. It returns the zero time when s matches no time in
+// the next five years, as "0 0 30 2 *" does.
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Year() + 5
+	for t.Year() <= limit {
+		y, m, d := t.Date()
+		switch {
+		case !s.matches(3, int(m)):
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchesDay(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+		case !s.matches(1, t.Hour()):
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		case !s.matches(0, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matches(field, value int) bool {
+	return s.fields[field]&(1<<uint(value)) != 0
+}
+
+// matchesDay reports whether t's day matches: both day fields must match,
+// unless both are restricted, when either may.
+func (s *Schedule) matchesDay(t time.Time) bool {
+	day, weekday := s.matches(2, t.Day()), s.matches(4, int(t.Weekday()))
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
@@ -0,0 +1,67 @@
+package typecast
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// 2024-03-15 is a Friday.
+	after := time.Date(2024, 3, 15, 6, 30, 20, 0, time.UTC)
+	tests := []struct{ expr, want string }{
+		{"0 6 * * *", "2024-03-16 06:00"},
+		{"@daily", "2024-03-16 00:00"},
+		{"@Hourly", "2024-03-15 07:00"},
+		{"* * * * *", "2024-03-15 06:31"},
+		{"*/15 * * * *", "2024-03-15 06:45"},
+		{"5/20 6 * * *", "2024-03-15 06:45"},
+		{"0 6 * * 1-5", "2024-03-18 06:00"},
+		{"0 9 * * mon,WED", "2024-03-18 09:00"},
+		{"0 0 * * 7", "2024-03-17 00:00"},
+		{"30 8 1 * *", "2024-04-01 08:30"},
+		{"0 0 13 * 5", "2024-03-22 00:00"},
+		{"0 12 29 feb *", "2028-02-29 12:00"},
+		{"0 0 1-7/3 * *", "2024-04-01 00:00"},
+		{"@yearly", "2025-01-01 00:00"},
+		{"@weekly", "2024-03-17 00:00"},
+		{"@monthly", "2024-04-01 00:00"},
+		{"0 0 30 2 *", ""},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q): %v", tt.expr, err)
+		}
+		var got string
+		if next := s.Next(after); !next.IsZero() {
+			got = next.Format("2006-01-02 15:04")
+		}
+		if got != tt.want {
+			t.Errorf("%q.Next = %q, want %q", tt.expr, got, tt.want)
+		}
+		if s.String() != tt.expr {
+			t.Errorf("String() = %q", s.String())
+		}
+	}
+}
+
+func TestScheduleNextInLocation(t *testing.T) {
+	seoul := time.FixedZone("KST", 9*60*60)
+	s, _ := ParseSchedule("0 6 * * *")
+	next := s.Next(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC).In(seoul))
+	if want := time.Date(2024, 3, 15, 21, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Fatalf("Next = %v, want %v", next, want)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, expr := range []string{
+		"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *",
+		"* * * 13 *", "* * * * 8", "* * * foo *", "5-1 * * * *", "*/0 * * * *",
+		"*/x * * * *", "1-x * * * *", "@often",
+	} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded", expr)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package typecast
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// logCurl logs req as a curl command when DebugCurl is set.
+func (c *Client) logCurl(req *http.Request, attempt int) {
+	if !c.debugCurl || c.logger == nil {
+		return
+	}
+	c.logger.Printf("level=debug msg=%q attempt=%d\n%s", "typecast: request as curl", attempt+1, curlCommand(req, c.textRedactor()))
+}
+
+// curlCommand renders req as a curl command that sends it again. The API
+// key is replaced by a reference to $TYPECAST_API_KEY, so the command can be
+// pasted into a support ticket. Multipart files are referenced by their
+// filename instead of being inlined, and the text of a JSON body is passed
+// through redact unless it is nil.
+func curlCommand(req *http.Request, redact Redactor) string {
+	var b strings.Builder
+	b.WriteString("curl")
+	if req.Method != http.MethodGet {
+		b.WriteString(" -X " + req.Method)
+	}
+	b.WriteString(" " + shellQuote(req.URL.String()))
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	multipartForm := mediaType == "multipart/form-data"
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if multipartForm && key == "Content-Type" {
+			continue
+		}
+		for _, value := range req.Header[key] {
+			if key == "X-Api-Key" {
+				b.WriteString(` -H "X-API-KEY: $TYPECAST_API_KEY"`)
+				continue
+			}
+			b.WriteString(" -H " + shellQuote(key+": "+value))
+		}
+	}
+	if req.GetBody == nil {
+		if req.Body != nil && req.Body != http.NoBody {
+			// The body was streamed and cannot be read again.
+			b.WriteString(" --data-binary @-")
+		}
+		return b.String()
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return b.String()
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if multipartForm {
+		writeCurlForm(&b, data, params["boundary"])
+	} else if len(data) > 0 {
+		if redact != nil && mediaType == "application/json" {
+			data = redactJSONText(data, redact)
+		}
+		b.WriteString(" --data-raw " + shellQuote(string(data)))
+	}
+	return b.String()
+}
+
+// writeCurlForm renders the parts of a multipart form as curl options.
+func writeCurlForm(b *strings.Builder, data []byte, boundary string) {
+	reader := multipart.NewReader(bytes.NewReader(data), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return
+		}
+		name := part.FormName()
+		if filename := part.FileName(); filename != "" {
+			field := name + "=@" + filename
+			if contentType := part.Header.Get("Content-Type"); contentType != "" {
+				field += ";type=" + contentType
+			}
+			b.WriteString(" -F " + shellQuote(field))
+			continue
+		}
+		value, _ := io.ReadAll(part)
+		b.WriteString(" --form-string " + shellQuote(name+"="+string(value)))
+	}
+}
+
+// shellQuote quotes s as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,82 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugCurl(t *testing.T) {
+	srv, _ := flakyServer(t, 1, http.StatusServiceUnavailable, nil)
+	logger := &recordingLogger{}
+	c := newRetryTestClient(srv, ClientConfig{MaxRetries: 1, Logger: logger, DebugCurl: true, LogText: true})
+	c.apiKey = "secret-key"
+	_, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "tc_1", Text: "it's", Model: ModelSSFMV30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logger.lines) != 2 || !strings.Contains(logger.lines[1], "attempt=2\ncurl -X POST") {
+		t.Fatalf("expected one command per attempt, got %q", logger.lines)
+	}
+	line := logger.lines[0]
+	for _, want := range []string{
+		`level=debug msg="typecast: request as curl" attempt=1` + "\ncurl -X POST '" + srv.URL + "/v1/text-to-speech'",
+		` -H 'Content-Type: application/json'`,
+		` -H "X-API-KEY: $TYPECAST_API_KEY"`,
+		`"text":"it'\''s"`,
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("missing %q in %s", want, line)
+		}
+	}
+	if strings.Contains(line, "secret-key") {
+		t.Fatalf("the API key leaked: %s", line)
+	}
+}
+
+func TestDebugCurlMultipart(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"voice_id":"uc_1"}`))
+	}))
+	defer srv.Close()
+	logger := &recordingLogger{}
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Logger: logger, DebugCurl: true})
+	if _, err := c.CloneVoice(context.Background(), []byte("RIFF"), "sample.wav", "MyVoice", "ssfm-v30"); err != nil {
+		t.Fatal(err)
+	}
+	line := logger.lines[0]
+	if want := ` --form-string 'name=MyVoice' --form-string 'model=ssfm-v30' -F 'file=@sample.wav;type=audio/wav'`; !strings.Contains(line, want) {
+		t.Errorf("missing %q in %s", want, line)
+	}
+	if strings.Contains(line, "multipart/form-data") || strings.Contains(line, "RIFF") {
+		t.Fatalf("the form must be rendered as fields: %s", line)
+	}
+
+	// Without a logger, nothing is rendered.
+	c = NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, DebugCurl: true})
+	if _, err := c.CloneVoice(context.Background(), []byte("RIFF"), "sample.wav", "MyVoice", "ssfm-v30"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCurlCommandBodies(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.test/v2/voices?model=ssfm-v30", nil)
+	if got, want := curlCommand(req, nil), "curl 'https://api.example.test/v2/voices?model=ssfm-v30'"; got != want {
+		t.Fatalf("curlCommand() = %s, want %s", got, want)
+	}
+
+	req, _ = http.NewRequest(http.MethodPut, "https://api.example.test/upload", io.MultiReader(strings.NewReader("data")))
+	if got := curlCommand(req, nil); !strings.HasSuffix(got, " --data-binary @-") {
+		t.Fatalf("a streamed body must be read from stdin: %s", got)
+	}
+
+	req, _ = http.NewRequest(http.MethodPost, "https://api.example.test/v1/text-to-speech", strings.NewReader("{}"))
+	req.GetBody = func() (io.ReadCloser, error) { return nil, errors.New("gone") }
+	if got, want := curlCommand(req, nil), "curl -X POST 'https://api.example.test/v1/text-to-speech'"; got != want {
+		t.Fatalf("curlCommand() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,274 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultDaemonWorkers is the number of workers when
+	// DaemonConfig.Workers is zero.
+	defaultDaemonWorkers = 2
+	// defaultDaemonAttempts is the number of attempts when
+	// DaemonConfig.MaxAttempts is zero.
+	defaultDaemonAttempts = 3
+	// defaultDaemonRetryDelay is the base delay when
+	// DaemonConfig.RetryDelay is zero.
+	defaultDaemonRetryDelay = 30 * time.Second
+)
+
+// DaemonConfig configures Client.RunDaemon.
+type DaemonConfig struct {
+	// Queue holds the jobs to process (required)
+	Queue *JobQueue
+	// OutputDir receives the audio of each job (required)
+	OutputDir string
+	// Profile holds the voice and synthesis settings that jobs override
+	// (required)
+	Profile VoiceProfile
+	// Workers is the number of jobs processed at once (optional, defaults
+	// to 2)
+	Workers int
+	// MaxChunkChars caps the characters per request (optional, defaults to
+	// 2000)
+	MaxChunkChars int
+	// MaxAttempts is the number of times a job is tried before it fails for
+	// good. Rate-limited attempts do not count (optional, defaults to 3)
+	MaxAttempts int
+	// RetryDelay is the wait before a failed job is retried, multiplied by
+	// its attempts, and the pause after a rate limit without Retry-After
+	// (optional, defaults to 30s)
+	RetryDelay time.Duration
+	// Schedules are jobs enqueued on cron schedules (optional)
+	Schedules []ScheduledJob
+	// Location is the time zone of the schedules (optional, defaults to
+	// the local time zone)
+	Location *time.Location
+	// OnJob is called after each change of a job's state (optional)
+	OnJob func(QueuedJob)
+}
+
+// daemon holds the state shared by RunDaemon's workers.
+type daemon struct {
+	client *Client
+	cfg    DaemonConfig
+	now    func() time.Time
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+// RunDaemon processes the jobs of cfg.Queue with cfg.Workers workers until
+// ctx is done, synthesizing each with LongFormSynthesize into
+// cfg.OutputDir. Jobs that fail with a server or network error are retried
+// after RetryDelay times their attempts, up to MaxAttempts. When the API
+// rate-limits a job, it is queued again and all workers pause for the
+// Retry-After the API asked for. A job interrupted by ctx is queued again,
+// so it resumes after a restart. The jobs of cfg.Schedules are enqueued
+// as they come due, and a job with a Sink delivers its audio there instead
+// of cfg.OutputDir. A job whose audio cannot be written fails. RunDaemon
+// returns ctx's error, or the first error writing the queue or creating
+// cfg.OutputDir.
+func (c *Client) RunDaemon(ctx context.Context, cfg DaemonConfig) error {
+	if cfg.Queue == nil {
+		return newValidationError("queue", "queue is required")
+	}
+	if cfg.OutputDir == "" {
+		return newValidationError("output_dir", "output_dir is required")
+	}
+	if err := cfg.Profile.Validate(); err != nil {
+		return err
+	}
+	schedules := make([]*Schedule, len(cfg.Schedules))
+	names := map[string]bool{}
+	for i, job := range cfg.Schedules {
+		schedule, err := job.validate(c.clock.Now())
+		if err != nil {
+			return err
+		}
+		if names[job.Name] {
+			return newValidationError("name", fmt.Sprintf("schedule %s is defined twice", job.Name))
+		}
+		schedules[i], names[job.Name] = schedule, true
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.Local
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultDaemonWorkers
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultDaemonAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultDaemonRetryDelay
+	}
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		return err
+	}
+
+	d := &daemon{client: c, cfg: cfg, now: c.clock.Now}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, cfg.Workers+1)
+	var workers sync.WaitGroup
+	start := func(run func(context.Context) error) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			if err := run(ctx); err != nil && ctx.Err() == nil {
+				errs <- err
+				cancel()
+			}
+		}()
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		start(d.work)
+	}
+	if len(schedules) > 0 {
+		start(func(ctx context.Context) error { return d.runSchedules(ctx, schedules) })
+	}
+	workers.Wait()
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return ctx.Err()
+	}
+}
+
+// work claims and processes jobs until ctx is done or the queue cannot be
+// written.
+func (d *daemon) work(ctx context.Context) error {
+	queue := d.cfg.Queue
+	for ctx.Err() == nil {
+		wait := d.pause()
+		if wait <= 0 {
+			job, next, ok, err := queue.claim(d.now())
+			if err != nil {
+				return err
+			}
+			if ok {
+				queue.signal() // another job may be waiting for a worker
+				if err := d.process(ctx, job); err != nil {
+					return err
+				}
+				continue
+			}
+			wait = time.Hour
+			if !next.IsZero() {
+				wait = next.Sub(d.now())
+			}
+		}
+		timer := d.client.clock.NewTimer(wait)
+		select {
+		case <-queue.ready:
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		timer.Stop()
+	}
+	return ctx.Err()
+}
+
+// pause returns how long workers must wait after a rate limit.
+func (d *daemon) pause() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pausedUntil.Sub(d.now())
+}
+
+// process synthesizes a claimed job and records the outcome. A job whose
+// audio cannot be written fails without a retry.
+func (d *daemon) process(ctx context.Context, job QueuedJob) error {
+	d.notify(job)
+	result, err := d.client.LongFormSynthesize(ctx, LongFormRequest{
+		Profile:       job.Job.profile(d.cfg.Profile),
+		Text:          job.Job.Text,
+		MaxChunkChars: d.cfg.MaxChunkChars,
+	})
+	name := job.Output
+	if err == nil {
+		if name == "" {
+			name = job.ID + "." + string(result.Format)
+		}
+		if job.Sink != nil && job.Sink.URL != "" {
+			err = d.upload(ctx, job.Sink, name, result.AudioData)
+		} else if err := d.write(job.Sink, name, result.AudioData); err != nil {
+			job.State, job.Error = JobFailed, err.Error()
+			return d.record(job)
+		}
+	}
+	switch {
+	case err == nil:
+		job.State, job.Audio, job.Duration, job.Error = JobDone, name, result.Duration, ""
+	case ctx.Err() != nil:
+		job.State, job.Attempts = JobQueued, job.Attempts-1
+	default:
+		job.Error = err.Error()
+		d.retry(&job, err)
+	}
+	return d.record(job)
+}
+
+// write stores the audio of a finished job in its sink's directory, or in
+// the output directory.
+func (d *daemon) write(sink *OutputSink, name string, audio []byte) error {
+	dir := d.cfg.OutputDir
+	if sink != nil {
+		dir = sink.Dir
+	}
+	err := os.MkdirAll(dir, 0755)
+	if err == nil {
+		err = writeFileAtomic(filepath.Join(dir, name), audio)
+	}
+	return err
+}
+
+// retry decides whether a failed job is tried again, and when.
+func (d *daemon) retry(job *QueuedJob, err error) {
+	var apiErr *APIError
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.IsRateLimited():
+		wait := apiErr.RetryAfter
+		if wait <= 0 {
+			wait = d.cfg.RetryDelay
+		}
+		until := d.now().Add(wait)
+		d.mu.Lock()
+		if until.After(d.pausedUntil) {
+			d.pausedUntil = until
+		}
+		d.mu.Unlock()
+		job.State, job.NotBefore, job.Attempts = JobQueued, until, job.Attempts-1
+	case errors.As(err, &validationErr),
+		errors.As(err, &apiErr) && !apiErr.IsServerError(),
+		job.Attempts >= d.cfg.MaxAttempts:
+		job.State = JobFailed
+	default:
+		job.State, job.NotBefore = JobQueued, d.now().Add(time.Duration(job.Attempts)*d.cfg.RetryDelay)
+	}
+}
+
+// record stores job in the queue and reports it.
+func (d *daemon) record(job QueuedJob) error {
+	if err := d.cfg.Queue.update(job, d.now()); err != nil {
+		return err
+	}
+	d.notify(job)
+	return nil
+}
+
+func (d *daemon) notify(job QueuedJob) {
+	if d.cfg.OnJob != nil {
+		d.cfg.OnJob(job)
+	}
+}
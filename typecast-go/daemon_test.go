@@ -0,0 +1,209 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// daemonServer answers synthesis requests by their text: "bad" is
+// rejected, "flaky" fails with a 500 the first time, and "busy" is
+// rate-limited the first time.
+func daemonServer(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	seen := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		seen[body.Text]++
+		n := seen[body.Text]
+		mu.Unlock()
+		switch {
+		case body.Text == "bad":
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		case body.Text == "down" || body.Text == "flaky" && n == 1:
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		case body.Text == "busy" && n == 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		case body.Text == "slow":
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write(makeTestWAV(make([]byte, 1600), 8000))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// runDaemonUntil runs the daemon until every job of q is done or failed.
+func runDaemonUntil(t *testing.T, c *Client, cfg DaemonConfig) []QueuedJob {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var finished int64
+	total := int64(len(cfg.Queue.List()))
+	cfg.OnJob = func(job QueuedJob) {
+		if (job.State == JobDone || job.State == JobFailed) && atomic.AddInt64(&finished, 1) == total {
+			cancel()
+		}
+	}
+	if err := c.RunDaemon(ctx, cfg); !errors.Is(err, context.Canceled) {
+		t.Fatalf("RunDaemon = %v", err)
+	}
+	return cfg.Queue.List()
+}
+
+func TestRunDaemon(t *testing.T) {
+	c := newTestClient(daemonServer(t), "k")
+	dir := t.TempDir()
+	q, _ := OpenJobQueue(filepath.Join(dir, "queue.db"))
+	defer q.Close()
+	for _, job := range []QueuedJob{
+		{ID: "ok", Job: WatchJob{Text: "Hello.", VoiceID: "other"}, Output: "hello.wav"},
+		{ID: "bad", Job: WatchJob{Text: "bad"}},
+		{ID: "flaky", Job: WatchJob{Text: "flaky"}},
+		{ID: "busy", Job: WatchJob{Text: "busy"}},
+		{ID: "down", Job: WatchJob{Text: "down"}},
+	} {
+		if _, err := q.Enqueue(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jobs := runDaemonUntil(t, c, DaemonConfig{
+		Queue:       q,
+		OutputDir:   filepath.Join(dir, "audio"),
+		Profile:     VoiceProfile{VoiceID: "v"},
+		Workers:     3,
+		MaxAttempts: 2,
+		RetryDelay:  time.Millisecond,
+	})
+	want := map[string]struct {
+		state    QueuedJobState
+		attempts int
+	}{
+		"ok": {JobDone, 1}, "bad": {JobFailed, 1}, "flaky": {JobDone, 2}, "busy": {JobDone, 1}, "down": {JobFailed, 2},
+	}
+	for _, job := range jobs {
+		if w := want[job.ID]; job.State != w.state || job.Attempts != w.attempts {
+			t.Errorf("job %s: %s after %d attempts (%s), want %s after %d", job.ID, job.State, job.Attempts, job.Error, w.state, w.attempts)
+		}
+	}
+	for _, name := range []string{"hello.wav", "flaky.wav", "busy.wav"} {
+		if _, err := os.Stat(filepath.Join(dir, "audio", name)); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestRunDaemonUsesClock(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: daemonServer(t).URL, Clock: clock})
+	dir := t.TempDir()
+	q, _ := OpenJobQueue(filepath.Join(dir, "queue.db"))
+	defer q.Close()
+	_, _ = q.Enqueue(QueuedJob{ID: "flaky", Job: WatchJob{Text: "flaky"}})
+
+	// The failed attempt is retried a RetryDelay later by the client's
+	// clock, once it is advanced.
+	var retried QueuedJob
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := c.RunDaemon(ctx, DaemonConfig{Queue: q, OutputDir: dir, Profile: VoiceProfile{VoiceID: "v"}, Workers: 1, RetryDelay: time.Minute, OnJob: func(job QueuedJob) {
+		switch {
+		case job.State == JobQueued:
+			retried = job
+			go func() {
+				clock.WaitForTimers(1)
+				clock.Advance(time.Minute)
+			}()
+		case job.State == JobDone:
+			cancel()
+		}
+	}})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("RunDaemon = %v", err)
+	}
+	if !retried.NotBefore.Equal(start.Add(time.Minute)) || !retried.Updated.Equal(start) {
+		t.Fatalf("expected the retry to be timed by the clock, got %+v", retried)
+	}
+	if job, _ := q.Get("flaky"); job.State != JobDone || !job.Updated.Equal(start.Add(time.Minute)) {
+		t.Fatalf("job is %s, updated %v", job.State, job.Updated)
+	}
+}
+
+func TestRunDaemonInterrupted(t *testing.T) {
+	c := newTestClient(daemonServer(t), "k")
+	dir := t.TempDir()
+	q, _ := OpenJobQueue(filepath.Join(dir, "queue.db"))
+	defer q.Close()
+	_, _ = q.Enqueue(QueuedJob{ID: "slow", Job: WatchJob{Text: "slow"}})
+	ctx, cancel := context.WithCancel(context.Background())
+	err := c.RunDaemon(ctx, DaemonConfig{Queue: q, OutputDir: dir, Profile: VoiceProfile{VoiceID: "v"}, OnJob: func(job QueuedJob) {
+		if job.State == JobRunning {
+			time.AfterFunc(10*time.Millisecond, cancel)
+		}
+	}})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("RunDaemon = %v", err)
+	}
+	if job, _ := q.Get("slow"); job.State != JobQueued || job.Attempts != 0 {
+		t.Fatalf("interrupted job is %s after %d attempts", job.State, job.Attempts)
+	}
+}
+
+func TestRunDaemonErrors(t *testing.T) {
+	c := newTestClient(daemonServer(t), "k")
+	dir := t.TempDir()
+	q, _ := OpenJobQueue(filepath.Join(dir, "queue.db"))
+	profile := VoiceProfile{VoiceID: "v"}
+	for _, cfg := range []DaemonConfig{
+		{OutputDir: dir, Profile: profile},
+		{Queue: q, Profile: profile},
+		{Queue: q, OutputDir: dir},
+		{Queue: q, OutputDir: filepath.Join(dir, "queue.db"), Profile: profile},
+	} {
+		if err := c.RunDaemon(context.Background(), cfg); err == nil {
+			t.Errorf("RunDaemon(%+v) succeeded", cfg)
+		}
+	}
+
+	// The audio cannot be written over a directory, which fails the job
+	// without stopping the daemon.
+	_ = os.Mkdir(filepath.Join(dir, "taken.wav"), 0755)
+	_, _ = q.Enqueue(QueuedJob{ID: "taken", Job: WatchJob{Text: "Hello."}, Output: "taken.wav"})
+	_, _ = q.Enqueue(QueuedJob{ID: "next", Job: WatchJob{Text: "Hello."}})
+	jobs := runDaemonUntil(t, c, DaemonConfig{Queue: q, OutputDir: dir, Profile: profile, Workers: 1})
+	if len(jobs) != 2 || jobs[0].State != JobFailed || jobs[0].Attempts != 1 || !strings.Contains(jobs[0].Error, "taken.wav") || jobs[1].State != JobDone {
+		t.Fatalf("unexpected jobs %+v", jobs)
+	}
+
+	// A queue closed while a job runs cannot record its outcome.
+	closing, _ := OpenJobQueue(filepath.Join(dir, "closing.db"))
+	_, _ = closing.Enqueue(QueuedJob{Job: WatchJob{Text: "Hello."}})
+	err := c.RunDaemon(context.Background(), DaemonConfig{Queue: closing, OutputDir: dir, Profile: profile, OnJob: func(QueuedJob) { _ = closing.Close() }})
+	if err == nil || !strings.Contains(err.Error(), "failed to write job queue") {
+		t.Fatalf("expected a queue error, got %v", err)
+	}
+
+	// A closed queue cannot record claims.
+	_, _ = q.Enqueue(QueuedJob{Job: WatchJob{Text: "Hello."}})
+	_ = q.Close()
+	if err := c.RunDaemon(context.Background(), DaemonConfig{Queue: q, OutputDir: dir, Profile: profile, Workers: 1}); err == nil {
+		t.Fatal("expected a queue error")
+	}
+}
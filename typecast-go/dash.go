@@ -0,0 +1,201 @@
+package typecast
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DASHOptions configures a DASHWriter.
+type DASHOptions struct {
+	// SegmentDuration is the target segment length in seconds (optional,
+	// defaults to 6). Segments are cut at MP3 frame boundaries and never
+	// exceed it.
+	SegmentDuration float64
+	// ManifestName is the MPD's file name (optional, defaults to
+	// "manifest.mpd")
+	ManifestName string
+	// SegmentPrefix starts each segment's file name (optional, defaults to
+	// "segment")
+	SegmentPrefix string
+}
+
+// DASHSegment describes one media segment written by a DASHWriter.
+type DASHSegment struct {
+	// File is the segment's file name, relative to the directory
+	File string
+	// Start is the segment's offset in the narration, in seconds
+	Start float64
+	// Duration is the segment's duration in seconds
+	Duration float64
+}
+
+// DASHWriter packages MP3 audio into fragmented MP4 segments and an MPEG-DASH
+// manifest. Until Close, the manifest is dynamic and rewritten as each
+// segment is completed, so players can start before the narration is fully
+// rendered; Close turns it into a static, on-demand manifest.
+type DASHWriter struct {
+	dir  string
+	opts DASHOptions
+	now  func() time.Time
+
+	mu        sync.Mutex
+	segmenter mp3Segmenter
+	format    mp3Frame // first frame, which sets the track's format
+	started   time.Time
+	segments  []DASHSegment
+	samples   []uint64 // each segment's duration in samples
+	elapsed   uint64   // in samples
+	closed    bool
+}
+
+// NewDASHWriter creates dir if needed and returns a writer that packages
+// audio into it.
+func NewDASHWriter(dir string, opts *DASHOptions) (*DASHWriter, error) {
+	w := &DASHWriter{dir: dir, now: time.Now}
+	if opts != nil {
+		w.opts = *opts
+	}
+	if w.opts.SegmentDuration < 0 {
+		return nil, newValidationError("segment_duration", "segment_duration must not be negative")
+	}
+	if w.opts.SegmentDuration == 0 {
+		w.opts.SegmentDuration = 6
+	}
+	if w.opts.ManifestName == "" {
+		w.opts.ManifestName = "manifest.mpd"
+	}
+	if w.opts.SegmentPrefix == "" {
+		w.opts.SegmentPrefix = "segment"
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	w.segmenter = mp3Segmenter{target: w.opts.SegmentDuration, flush: w.writeSegment}
+	return w, nil
+}
+
+// Append adds MP3 audio, such as one long-form segment, and writes every
+// segment it completes. Audio that does not fill a segment is held until the
+// next Append or Close. All audio must share one sample rate.
+func (w *DASHWriter) Append(mp3 []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return fmt.Errorf("dash writer is closed")
+	}
+	frames, err := parseMP3Frames(mp3)
+	if err != nil {
+		return fmt.Errorf("dash requires mp3 audio: %w", err)
+	}
+	if w.format.sampleRate == 0 {
+		w.format = frames[0]
+	}
+	for _, frame := range frames {
+		if frame.sampleRate != w.format.sampleRate {
+			return fmt.Errorf("mp3 sample rate changed from %d to %d Hz", w.format.sampleRate, frame.sampleRate)
+		}
+	}
+	return w.segmenter.add(mp3, frames)
+}
+
+// Close writes the remaining audio as a final segment and makes the manifest
+// static.
+func (w *DASHWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	if err := w.segmenter.finish(); err != nil {
+		return err
+	}
+	w.closed = true
+	return w.writeManifest()
+}
+
+// Segments returns the media segments written so far.
+func (w *DASHWriter) Segments() []DASHSegment {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]DASHSegment(nil), w.segments...)
+}
+
+// writeSegment writes frames as the next media segment, preceded by the
+// initialization segment the first time, and updates the manifest.
+func (w *DASHWriter) writeSegment(audio []byte, frames []mp3Frame, duration float64) error {
+	if len(w.segments) == 0 {
+		if err := writeFileAtomic(filepath.Join(w.dir, w.initName()), fmp4InitSegment(w.format)); err != nil {
+			return err
+		}
+		w.started = w.now()
+	}
+	segment := DASHSegment{
+		File:     fmt.Sprintf("%s-%05d.m4s", w.opts.SegmentPrefix, len(w.segments)),
+		Start:    w.seconds(),
+		Duration: duration,
+	}
+	data := fmp4MediaSegment(len(w.segments)+1, w.elapsed, audio, frames)
+	if err := writeFileAtomic(filepath.Join(w.dir, segment.File), data); err != nil {
+		return err
+	}
+	var samples uint64
+	for _, frame := range frames {
+		samples += uint64(frame.samples)
+	}
+	w.segments = append(w.segments, segment)
+	w.samples = append(w.samples, samples)
+	w.elapsed += samples
+	return w.writeManifest()
+}
+
+// seconds returns the duration of the segments written so far.
+func (w *DASHWriter) seconds() float64 {
+	if w.elapsed == 0 {
+		return 0
+	}
+	return float64(w.elapsed) / float64(w.format.sampleRate)
+}
+
+func (w *DASHWriter) initName() string {
+	return w.opts.SegmentPrefix + "-init.mp4"
+}
+
+func (w *DASHWriter) writeManifest() error {
+	codec := "mp4a.6B"
+	if w.format.samples != 1152 {
+		codec = "mp4a.69"
+	}
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	b.WriteString(`<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-live:2011"`)
+	if w.closed {
+		fmt.Fprintf(&b, ` type="static" mediaPresentationDuration="%s"`, dashDuration(w.seconds()))
+	} else {
+		fmt.Fprintf(&b, ` type="dynamic" availabilityStartTime="%s" publishTime="%s" minimumUpdatePeriod="%s"`,
+			w.started.UTC().Format(time.RFC3339), w.now().UTC().Format(time.RFC3339), dashDuration(w.opts.SegmentDuration))
+	}
+	fmt.Fprintf(&b, " minBufferTime=\"%s\">\n", dashDuration(w.opts.SegmentDuration))
+	b.WriteString("  <Period id=\"0\" start=\"PT0S\">\n")
+	b.WriteString("    <AdaptationSet contentType=\"audio\" mimeType=\"audio/mp4\" segmentAlignment=\"true\">\n")
+	fmt.Fprintf(&b, "      <Representation id=\"audio\" codecs=\"%s\" bandwidth=\"%d\" audioSamplingRate=\"%d\">\n", codec, w.format.bitrate, w.format.sampleRate)
+	fmt.Fprintf(&b, "        <AudioChannelConfiguration schemeIdUri=\"urn:mpeg:dash:23003:3:audio_channel_configuration:2011\" value=\"%d\"/>\n", w.format.channels)
+	fmt.Fprintf(&b, "        <SegmentTemplate timescale=\"%d\" initialization=\"%s\" media=\"%s-$Number%%05d$.m4s\" startNumber=\"0\">\n",
+		w.format.sampleRate, w.initName(), w.opts.SegmentPrefix)
+	b.WriteString("          <SegmentTimeline>\n")
+	var t uint64
+	for _, d := range w.samples {
+		fmt.Fprintf(&b, "            <S t=\"%d\" d=\"%d\"/>\n", t, d)
+		t += d
+	}
+	b.WriteString("          </SegmentTimeline>\n        </SegmentTemplate>\n      </Representation>\n    </AdaptationSet>\n  </Period>\n</MPD>\n")
+	return writeFileAtomic(filepath.Join(w.dir, w.opts.ManifestName), []byte(b.String()))
+}
+
+// dashDuration formats seconds as an xs:duration.
+func dashDuration(seconds float64) string {
+	return fmt.Sprintf("PT%.3fS", seconds)
+}
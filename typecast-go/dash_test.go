@@ -0,0 +1,206 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fmp4Paths walks the boxes in b, descending into containers, and returns
+// each box's path, e.g. "moof/traf/trun". Sizes must add up exactly.
+func fmp4Paths(t *testing.T, prefix string, b []byte) []string {
+	t.Helper()
+	containers := map[string]bool{"moov": true, "trak": true, "mdia": true, "minf": true, "stbl": true, "dinf": true, "mvex": true, "moof": true, "traf": true}
+	var paths []string
+	for len(b) > 0 {
+		if len(b) < 8 {
+			t.Fatalf("truncated box header under %q", prefix)
+		}
+		size := int(binary.BigEndian.Uint32(b))
+		if size < 8 || size > len(b) {
+			t.Fatalf("bad size %d for box %q under %q", size, b[4:8], prefix)
+		}
+		path := prefix + string(b[4:8])
+		paths = append(paths, path)
+		if containers[string(b[4:8])] {
+			paths = append(paths, fmp4Paths(t, path+"/", b[8:size])...)
+		}
+		b = b[size:]
+	}
+	return paths
+}
+
+func TestDASHWriter(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dash")
+	w, err := NewDASHWriter(dir, &DASHOptions{SegmentDuration: 0.1})
+	if err != nil {
+		t.Fatalf("NewDASHWriter() error = %v", err)
+	}
+	w.now = func() time.Time { return time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC) }
+	if err := w.Append(makeTestMP3(4)); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	manifest, _ := os.ReadFile(filepath.Join(dir, "manifest.mpd"))
+	for _, want := range []string{`type="dynamic"`, `availabilityStartTime="2026-10-15T09:00:00Z"`, `<S t="0" d="3456"/>`} {
+		if !strings.Contains(string(manifest), want) {
+			t.Fatalf("dynamic manifest missing %s:\n%s", want, manifest)
+		}
+	}
+	if err := w.Append(makeTestMP3(3)); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("second Close() error = %v", err)
+	}
+
+	manifest, _ = os.ReadFile(filepath.Join(dir, "manifest.mpd"))
+	for _, want := range []string{
+		`type="static" mediaPresentationDuration="PT0.183S"`,
+		`codecs="mp4a.6B" bandwidth="128000" audioSamplingRate="44100"`,
+		`value="2"`,
+		`timescale="44100" initialization="segment-init.mp4" media="segment-$Number%05d$.m4s" startNumber="0"`,
+		`<S t="0" d="3456"/>`, `<S t="3456" d="3456"/>`, `<S t="6912" d="1152"/>`,
+	} {
+		if !strings.Contains(string(manifest), want) {
+			t.Fatalf("static manifest missing %s:\n%s", want, manifest)
+		}
+	}
+	if segments := w.Segments(); len(segments) != 3 || !approx(segments[2].Start, 6912.0/44100) {
+		t.Fatalf("unexpected segments: %+v", segments)
+	}
+
+	init, _ := os.ReadFile(filepath.Join(dir, "segment-init.mp4"))
+	paths := strings.Join(fmp4Paths(t, "", init), " ")
+	for _, want := range []string{"ftyp", "moov/mvhd", "moov/trak/mdia/minf/stbl/stsd", "moov/mvex/trex"} {
+		if !strings.Contains(paths, want) {
+			t.Fatalf("init segment missing %s: %s", want, paths)
+		}
+	}
+	if !bytes.Contains(init, []byte{0x04, 13, 0x6B, 0x15}) {
+		t.Fatal("init segment must declare MPEG-1 audio")
+	}
+
+	media, _ := os.ReadFile(filepath.Join(dir, "segment-00001.m4s"))
+	if got := strings.Join(fmp4Paths(t, "", media), " "); got != "moof moof/mfhd moof/traf moof/traf/tfhd moof/traf/tfdt moof/traf/trun mdat" {
+		t.Fatalf("unexpected media segment boxes: %s", got)
+	}
+	trun := bytes.Index(media, []byte("trun"))
+	dataOffset := binary.BigEndian.Uint32(media[trun+12:])
+	if count := binary.BigEndian.Uint32(media[trun+8:]); count != 3 || !bytes.Equal(media[dataOffset:], makeTestMP3(3)) {
+		t.Fatalf("trun must point at the frames (%d samples at %d)", count, dataOffset)
+	}
+	if tfdt := bytes.Index(media, []byte("tfdt")); binary.BigEndian.Uint64(media[tfdt+8:]) != 3456 {
+		t.Fatal("tfdt must hold the segment's decode time")
+	}
+
+	if err := w.Append(makeTestMP3(1)); err == nil {
+		t.Fatal("expected error after Close")
+	}
+}
+
+func TestDASHWriter_Formats(t *testing.T) {
+	dir := t.TempDir()
+	w, _ := NewDASHWriter(dir, nil)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if manifest, _ := os.ReadFile(filepath.Join(dir, "manifest.mpd")); !strings.Contains(string(manifest), `mediaPresentationDuration="PT0.000S"`) {
+		t.Fatalf("unexpected empty manifest:\n%s", manifest)
+	}
+
+	// MPEG-2, mono, 24 kHz
+	frame := make([]byte, 192)
+	copy(frame, []byte{0xFF, 0xF3, 0x84, 0xC4})
+	w, _ = NewDASHWriter(filepath.Join(dir, "mpeg2"), &DASHOptions{ManifestName: "audio.mpd", SegmentPrefix: "part"})
+	if err := w.Append(frame); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := w.Append(makeTestMP3(1)); err == nil || !strings.Contains(err.Error(), "sample rate changed from 24000 to 44100 Hz") {
+		t.Fatalf("expected sample rate error, got %v", err)
+	}
+	_ = w.Close()
+	manifest, _ := os.ReadFile(filepath.Join(dir, "mpeg2", "audio.mpd"))
+	if !strings.Contains(string(manifest), `codecs="mp4a.69"`) || !strings.Contains(string(manifest), `value="1"`) {
+		t.Fatalf("unexpected mpeg-2 manifest:\n%s", manifest)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "mpeg2", "part-00000.m4s")); err != nil {
+		t.Fatalf("segment not written: %v", err)
+	}
+}
+
+func TestDASHWriter_Errors(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	var validation *ValidationError
+	if _, err := NewDASHWriter(root, &DASHOptions{SegmentDuration: -1}); !errors.As(err, &validation) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if _, err := NewDASHWriter(file, nil); err == nil {
+		t.Fatal("expected error when the directory is a file")
+	}
+	w, _ := NewDASHWriter(filepath.Join(root, "wav"), nil)
+	if err := w.Append(makeTestWAV(make([]byte, 1600), 8000)); err == nil || !strings.Contains(err.Error(), "dash requires mp3 audio") {
+		t.Fatalf("expected mp3 error, got %v", err)
+	}
+
+	// Directories in place of the files make writing them fail.
+	for _, name := range []string{"segment-init.mp4.tmp", "segment-00000.m4s.tmp", "manifest.mpd.tmp"} {
+		dir := filepath.Join(root, name)
+		w, _ := NewDASHWriter(dir, nil)
+		_ = os.MkdirAll(filepath.Join(dir, name), 0755)
+		_ = w.Append(makeTestMP3(1))
+		if err := w.Close(); err == nil {
+			t.Fatalf("expected error when %s cannot be written", name)
+		}
+	}
+}
+
+func TestLongFormSynthesize_DASH(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write(makeTestMP3(2))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+
+	hls, _ := NewHLSWriter(t.TempDir(), nil)
+	dash, _ := NewDASHWriter(t.TempDir(), &DASHOptions{SegmentDuration: 0.06})
+	request := LongFormRequest{
+		Profile:       VoiceProfile{VoiceID: "v", AudioFormat: AudioFormatMP3},
+		Text:          "One. Two.",
+		MaxChunkChars: 5,
+		HLS:           hls,
+		DASH:          dash,
+	}
+	if _, err := c.LongFormSynthesize(context.Background(), request); err != nil {
+		t.Fatalf("LongFormSynthesize() error = %v", err)
+	}
+	if len(dash.Segments()) != 1 {
+		t.Fatalf("segments must be written while synthesizing, got %+v", dash.Segments())
+	}
+
+	_ = dash.Close()
+	if _, err := c.LongFormSynthesize(context.Background(), request); err == nil || !strings.Contains(err.Error(), "segment 0: dash writer is closed") {
+		t.Fatalf("expected dash error, got %v", err)
+	}
+	request.Profile.AudioFormat = AudioFormatWAV
+	request.HLS = nil
+	var validation *ValidationError
+	if _, err := c.LongFormSynthesize(context.Background(), request); !errors.As(err, &validation) || validation.Field != "dash" {
+		t.Fatalf("expected dash ValidationError, got %v", err)
+	}
+}
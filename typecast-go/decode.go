@@ -0,0 +1,144 @@
+package typecast
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// decodeSnippetContext is the number of body bytes a DecodeError shows
+	// on each side of the failure.
+	decodeSnippetContext = 40
+	// decodeWindowSize is the number of most recently read body bytes kept
+	// for the snippet.
+	decodeWindowSize = 64 * 1024
+)
+
+// DecodeError is returned when a response body cannot be decoded. Besides
+// the JSON error, it tells where the body came from, where in it decoding
+// failed, and what the body looks like there, since a bare "invalid
+// character" is of little help when a proxy answers with an HTML page.
+type DecodeError struct {
+	// What names the decoded body, such as "voices response"
+	What string
+	// Endpoint is the method and path of the request, such as
+	// GET /v2/voices
+	Endpoint string
+	// Offset is the byte offset in the body where decoding failed
+	Offset int64
+	// Snippet is the body around Offset, truncated
+	Snippet string
+	// Type is the Go type the body was decoded into
+	Type string
+	// Err is the underlying error
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	msg := "failed to decode " + e.What
+	if e.Endpoint != "" {
+		msg += " from " + e.Endpoint
+	}
+	return fmt.Sprintf("%s at byte %d into %s: %v (body: %q)", msg, e.Offset, e.Type, e.Err, e.Snippet)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// decodeJSON decodes the JSON body of resp into v, reporting a failure as
+// a *DecodeError.
+func decodeJSON(resp *http.Response, what string, v interface{}) error {
+	body := &snippetReader{r: resp.Body}
+	dec := json.NewDecoder(body)
+	if err := dec.Decode(v); err != nil {
+		return newDecodeError(resp, what, v, body, 0, err)
+	}
+	return nil
+}
+
+// newDecodeError describes err, which happened while decoding into v the
+// value of body that starts at offset start. A syntax error's offset is in
+// the body and a type error's in the value, an unexpected end is at the end
+// of what was read, and other errors happened at start.
+func newDecodeError(resp *http.Response, what string, v interface{}, body *snippetReader, start int64, err error) *DecodeError {
+	offset := start
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	} else if errors.As(err, &typeErr) {
+		offset += typeErr.Offset
+	} else if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		offset = body.read
+	}
+	// The decoder stops reading at the error, so read on for the snippet.
+	if ahead := offset + decodeSnippetContext - body.read; ahead > 0 {
+		_, _ = io.CopyN(io.Discard, body, ahead)
+	}
+	decodeErr := &DecodeError{
+		What:    what,
+		Offset:  offset,
+		Snippet: body.snippet(offset),
+		Type:    strings.TrimPrefix(fmt.Sprintf("%T", v), "*"),
+		Err:     err,
+	}
+	if resp.Request != nil {
+		decodeErr.Endpoint = resp.Request.Method + " " + resp.Request.URL.Path
+	}
+	return decodeErr
+}
+
+// snippetReader keeps the most recently read bytes of a body, so a
+// DecodeError can show the body around the failure.
+type snippetReader struct {
+	r      io.Reader
+	window []byte
+	read   int64
+}
+
+func (s *snippetReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.window = append(s.window, p[:n]...)
+	s.read += int64(n)
+	if len(s.window) > 2*decodeWindowSize {
+		s.window = append(s.window[:0], s.window[len(s.window)-decodeWindowSize:]...)
+	}
+	return n, err
+}
+
+// valueStart returns the offset of the next value of a JSON array, given
+// the decoder's offset before decoding it, which may be at the comma that
+// precedes the value.
+func (s *snippetReader) valueStart(offset int64) int64 {
+	i := offset - (s.read - int64(len(s.window)))
+	if i >= 0 && i < int64(len(s.window)) && s.window[i] == ',' {
+		return offset + 1
+	}
+	return offset
+}
+
+// snippet returns the bytes around offset that are still in the window,
+// marking cut ends with "...".
+func (s *snippetReader) snippet(offset int64) string {
+	windowStart := s.read - int64(len(s.window))
+	from, to := offset-decodeSnippetContext, offset+decodeSnippetContext
+	prefix, suffix := "...", "..."
+	if from <= windowStart {
+		from = windowStart
+		if from == 0 {
+			prefix = ""
+		}
+	}
+	if to >= s.read {
+		to, suffix = s.read, ""
+	}
+	if from >= to {
+		return ""
+	}
+	return prefix + string(s.window[from-windowStart:to-windowStart]) + suffix
+}
@@ -0,0 +1,127 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeError_SyntaxError(t *testing.T) {
+	body := `[{"voice_id":"tc_1"},` + strings.Repeat(" ", 100) + `<html>bad gateway</html>`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+
+	_, err := c.GetVoices(context.Background(), "")
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected a DecodeError, got %v", err)
+	}
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("expected the syntax error to be wrapped, got %v", decodeErr.Err)
+	}
+	if decodeErr.Endpoint != "GET /v1/voices" || decodeErr.Type != "[]typecast.VoiceV1" || decodeErr.What != "voices response" {
+		t.Fatalf("unexpected error %+v", decodeErr)
+	}
+	if want := int64(strings.Index(body, "<") + 1); decodeErr.Offset != want {
+		t.Fatalf("offset %d, want %d", decodeErr.Offset, want)
+	}
+	if !strings.HasPrefix(decodeErr.Snippet, "...") || !strings.Contains(decodeErr.Snippet, "<html>bad gateway") || strings.Contains(decodeErr.Snippet, "tc_1") {
+		t.Fatalf("unexpected snippet %q", decodeErr.Snippet)
+	}
+	if msg := err.Error(); !strings.HasPrefix(msg, "failed to decode voices response from GET /v1/voices at byte 122 into []typecast.VoiceV1: invalid character '<'") {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
+
+func TestDecodeError_TypeErrorAndEOF(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+
+	body = `{"plan":"free","credits":"many"}`
+	_, err := c.GetMySubscription(context.Background())
+	var decodeErr *DecodeError
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &decodeErr) || !errors.As(err, &typeErr) {
+		t.Fatalf("expected a type error, got %v", err)
+	}
+	if decodeErr.Offset != int64(strings.Index(body, `"many"`)+len(`"many"`)) || decodeErr.Snippet != body {
+		t.Fatalf("unexpected error %+v", decodeErr)
+	}
+
+	body = `{"voice_id":"tc_1"`
+	_, err = c.GetVoiceV2Conditional(context.Background(), "tc_1", Validators{})
+	if !errors.As(err, &decodeErr) || !errors.Is(err, io.ErrUnexpectedEOF) || decodeErr.Offset != int64(len(body)) || decodeErr.Endpoint != "GET /v2/voices/tc_1" {
+		t.Fatalf("expected an unexpected EOF at the end, got %v", err)
+	}
+}
+
+func TestDecodeError_StreamedVoices(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	eachVoice := func(b string) *DecodeError {
+		body = b
+		var decodeErr *DecodeError
+		err := c.EachVoiceV2(context.Background(), nil, func(VoiceV2) error { return nil })
+		if !errors.As(err, &decodeErr) || decodeErr.Type != "[]typecast.VoiceV2" {
+			t.Fatalf("expected a DecodeError, got %v", err)
+		}
+		return decodeErr
+	}
+
+	body = `[{"voice_id":"tc_1"},{"voice_id":7}]`
+	if e := eachVoice(body); e.Offset != int64(strings.Index(body, "7")+1) || !strings.Contains(e.Err.Error(), "VoiceV2.voice_id") {
+		t.Fatalf("unexpected error %+v", e)
+	}
+	if e := eachVoice(`{"voices":[]}`); e.Offset != 1 || !strings.Contains(e.Error(), "expected a JSON array") {
+		t.Fatalf("unexpected error %+v", e)
+	}
+	if e := eachVoice(``); !errors.Is(e, io.EOF) || e.Snippet != "" {
+		t.Fatalf("unexpected error %+v", e)
+	}
+	body = `[{"voice_id":"tc_1"},{"voice_id":"tc_2"x}]`
+	if e := eachVoice(body); e.Offset != int64(strings.Index(body, "x")+1) || !strings.HasSuffix(e.Snippet, `"tc_2"x}]`) {
+		t.Fatalf("unexpected error %+v", e)
+	}
+}
+
+func TestSnippetReader_Window(t *testing.T) {
+	data := strings.Repeat("a", 3*decodeWindowSize) + "<" + strings.Repeat("b", 100)
+	r := &snippetReader{r: strings.NewReader(data)}
+	if _, err := io.Copy(io.Discard, struct{ io.Reader }{r}); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.window) > 2*decodeWindowSize || r.read != int64(len(data)) {
+		t.Fatalf("window of %d bytes after reading %d", len(r.window), r.read)
+	}
+	offset := int64(3 * decodeWindowSize)
+	want := "..." + strings.Repeat("a", decodeSnippetContext) + "<" + strings.Repeat("b", decodeSnippetContext-1) + "..."
+	if got := r.snippet(offset); got != want {
+		t.Fatalf("snippet %q, want %q", got, want)
+	}
+	// Bytes that left the window are not shown.
+	if got := r.snippet(10); got != "" {
+		t.Fatalf("expected no snippet, got %q", got)
+	}
+	windowStart := r.read - int64(len(r.window))
+	if got := r.snippet(windowStart + 10); got != "..."+strings.Repeat("a", decodeSnippetContext+10)+"..." {
+		t.Fatalf("unexpected snippet at the window start %q", got)
+	}
+}
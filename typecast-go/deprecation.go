@@ -0,0 +1,36 @@
+package typecast
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// Logger receives the client's warnings. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// deprecationWarned records the deprecated methods already reported by this
+// process.
+var deprecationWarned sync.Map
+
+// warnDeprecated logs, once per process, that the caller of a deprecated
+// method should migrate to replacement. The warning is a logfmt line naming
+// the method, its replacement, and the calling file and line, so stragglers
+// can be found in large codebases.
+func (c *Client) warnDeprecated(method, replacement string) {
+	if c.logger == nil || c.suppressDeprecationWarnings {
+		return
+	}
+	if _, warned := deprecationWarned.LoadOrStore(method, true); warned {
+		return
+	}
+	caller := "unknown"
+	if _, file, line, ok := runtime.Caller(2); ok {
+		caller = filepath.Base(file) + ":" + strconv.Itoa(line)
+	}
+	c.logger.Printf("level=warn msg=%q method=%s replacement=%s caller=%s",
+		"typecast: deprecated V1 method called", method, replacement, caller)
+}
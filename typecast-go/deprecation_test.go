@@ -0,0 +1,52 @@
+package typecast
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type recordingLogger struct{ lines []string }
+
+func (l *recordingLogger) Printf(format string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func TestDeprecatedV1MethodsWarnOnce(t *testing.T) {
+	deprecationWarned.Delete("GetVoices")
+	deprecationWarned.Delete("GetVoice")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	logger := &recordingLogger{}
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Logger: logger})
+	for i := 0; i < 2; i++ {
+		_, _ = c.GetVoices(context.Background(), "")
+		_, _ = c.GetVoice(context.Background(), "v", "")
+	}
+	// Another client in the same process does not warn again.
+	_, _ = NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Logger: logger}).GetVoices(context.Background(), "")
+
+	if len(logger.lines) != 2 {
+		t.Fatalf("expected one warning per method, got %q", logger.lines)
+	}
+	want := `level=warn msg="typecast: deprecated V1 method called" method=GetVoices replacement=GetVoicesV2 caller=deprecation_test.go:`
+	if !strings.HasPrefix(logger.lines[0], want) || !strings.Contains(logger.lines[1], "method=GetVoice replacement=GetVoiceV2") {
+		t.Fatalf("unexpected warnings %q", logger.lines)
+	}
+}
+
+func TestDeprecationWarningsSuppressed(t *testing.T) {
+	deprecationWarned.Delete("GetVoices")
+	logger := &recordingLogger{}
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://127.0.0.1:1", Logger: logger, SuppressDeprecationWarnings: true})
+	_, _ = c.GetVoices(context.Background(), "")
+	if len(logger.lines) != 0 {
+		t.Fatalf("expected no warnings, got %q", logger.lines)
+	}
+}
@@ -0,0 +1,102 @@
+package typecast
+
+import (
+	"context"
+	"time"
+)
+
+// defaultDetachedTimeout bounds a detached synthesis when
+// DetachedOptions.Timeout is not set.
+const defaultDetachedTimeout = 5 * time.Minute
+
+// DetachedOptions configures TextToSpeechDetached.
+type DetachedOptions struct {
+	// Timeout bounds the synthesis, since the caller's deadline no longer
+	// does (optional, defaults to 5m)
+	Timeout time.Duration
+	// OnDone is called with the result once the synthesis ends, from its
+	// own goroutine (optional)
+	OnDone func(resp *TTSResponse, err error)
+}
+
+// TextToSpeechDetached synthesizes request in the background and returns at
+// once, for "generate and store" flows where an HTTP handler answers before
+// the audio is ready. The synthesis is detached from ctx's cancellation and
+// deadline, so it outlives the handler, but keeps ctx's values such as the
+// correlation ID, tags, priority, and trace context. The result goes to
+// opts.OnDone:
+//
+//	client.TextToSpeechDetached(r.Context(), request, &typecast.DetachedOptions{
+//		OnDone: func(resp *typecast.TTSResponse, err error) { store(id, resp, err) },
+//	})
+//	w.WriteHeader(http.StatusAccepted)
+//
+// request, with its Output, Seed, and Prompt, is copied before
+// TextToSpeechDetached returns. Syntheses started before Close run to the
+// end, so call WaitDetached after Close to let them finish before shutting
+// down. After Close no synthesis starts, and OnDone is called at once with
+// ErrClientClosed.
+func (c *Client) TextToSpeechDetached(ctx context.Context, request *TTSRequest, opts *DetachedOptions) {
+	if opts == nil {
+		opts = &DetachedOptions{}
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultDetachedTimeout
+	}
+	copied := cloneTTSRequest(request)
+	onDone := opts.OnDone
+	// The closed check and Add share the lifecycle lock, so no synthesis
+	// starts once WaitDetached may be waiting after Close.
+	c.lifecycle.Lock()
+	if c.closed {
+		c.lifecycle.Unlock()
+		if onDone != nil {
+			onDone(nil, ErrClientClosed)
+		}
+		return
+	}
+	c.detached.Add(1)
+	c.lifecycle.Unlock()
+	admitted := context.WithValue(ctx, admittedContextKey, true)
+	go func() {
+		defer c.detached.Done()
+		ctx, cancel := context.WithTimeout(detachedContext{admitted}, timeout)
+		defer cancel()
+		resp, err := c.TextToSpeech(ctx, copied)
+		if onDone != nil {
+			onDone(resp, err)
+		}
+	}()
+}
+
+// detachedContext keeps the values of its parent but not its cancellation or
+// deadline, like context.WithoutCancel in newer Go releases.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
+
+func (d detachedContext) Value(key interface{}) interface{} { return d.parent.Value(key) }
+
+// WaitDetached waits until the syntheses started with TextToSpeechDetached
+// have ended and their OnDone returned, or until ctx is done. Call it after
+// Close, so that no synthesis starts while it waits.
+func (c *Client) WaitDetached(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		c.detached.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
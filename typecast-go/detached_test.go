@@ -0,0 +1,139 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTextToSpeechDetached(t *testing.T) {
+	release := make(chan struct{})
+	correlationIDs := make(chan string, 2)
+	formats := make(chan AudioFormat, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		correlationIDs <- r.Header.Get(CorrelationIDHeader)
+		formats <- body.Output.AudioFormat
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte("RIFF"))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+
+	ctx, cancel := context.WithCancel(WithCorrelationID(context.Background(), "req-1"))
+	request := &TTSRequest{VoiceID: "tc_1", Text: "hello", Model: ModelSSFMV30, Output: &Output{AudioFormat: AudioFormatWAV}}
+	results := make(chan error, 1)
+	c.TextToSpeechDetached(ctx, request, &DetachedOptions{OnDone: func(resp *TTSResponse, err error) {
+		if err == nil && string(resp.AudioData) != "RIFF" {
+			err = errors.New("unexpected audio")
+		}
+		results <- err
+	}})
+	request.Text = "changed"
+	request.Output.AudioFormat = AudioFormatMP3
+	if id := <-correlationIDs; id != "req-1" {
+		t.Fatalf("context values must be kept, got correlation ID %q", id)
+	}
+	if format := <-formats; format != AudioFormatWAV {
+		t.Fatalf("the request must be copied, got format %q", format)
+	}
+
+	// The caller's cancellation does not reach the synthesis.
+	cancel()
+	waitCtx, stop := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer stop()
+	if err := c.WaitDetached(waitCtx); err != context.DeadlineExceeded {
+		t.Fatalf("WaitDetached() = %v, want the wait to time out", err)
+	}
+	close(release)
+	if err := c.WaitDetached(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-results; err != nil {
+		t.Fatalf("detached synthesis failed: %v", err)
+	}
+}
+
+func TestTextToSpeechDetachedTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+	c := newTestClient(srv, "k")
+	results := make(chan error, 1)
+	c.TextToSpeechDetached(context.Background(), &TTSRequest{VoiceID: "tc_1", Text: "hello", Model: ModelSSFMV30}, &DetachedOptions{
+		Timeout: 10 * time.Millisecond,
+		OnDone:  func(_ *TTSResponse, err error) { results <- err },
+	})
+	if err := <-results; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the timeout, got %v", err)
+	}
+
+	// Without options the result is dropped.
+	c.TextToSpeechDetached(context.Background(), nil, nil)
+	if err := c.WaitDetached(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := (detachedContext{context.Background()}).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// After Close nothing starts.
+	c.Close()
+	c.TextToSpeechDetached(context.Background(), &TTSRequest{VoiceID: "tc_1", Text: "hello", Model: ModelSSFMV30}, &DetachedOptions{
+		OnDone: func(_ *TTSResponse, err error) { results <- err },
+	})
+	if err := <-results; !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("expected ErrClientClosed, got %v", err)
+	}
+	c.TextToSpeechDetached(context.Background(), nil, nil)
+	if err := c.WaitDetached(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTextToSpeechDetachedCloseThenWait(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte("RIFF"))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	const n = 20
+	results := make(chan error, n)
+	for i := 0; i < n; i++ {
+		c.TextToSpeechDetached(context.Background(), &TTSRequest{VoiceID: "tc_1", Text: "hello", Model: ModelSSFMV30}, &DetachedOptions{
+			OnDone: func(_ *TTSResponse, err error) { results <- err },
+		})
+	}
+
+	// Syntheses started before Close finish, whether or not their request
+	// was sent yet.
+	c.Close()
+	close(release)
+	if err := c.WaitDetached(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if err := <-results; err != nil {
+			t.Fatalf("detached synthesis %d failed: %v", i, err)
+		}
+	}
+	if _, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "tc_1", Text: "hello", Model: ModelSSFMV30}); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("expected ErrClientClosed, got %v", err)
+	}
+}
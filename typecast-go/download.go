@@ -0,0 +1,130 @@
+package typecast
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultDownloadAttempts is the number of connections DownloadAudio makes
+// before giving up when DownloadOptions.MaxAttempts is not set.
+const defaultDownloadAttempts = 5
+
+// DownloadOptions configures DownloadAudio.
+type DownloadOptions struct {
+	// MaxAttempts is the number of connections to make, including resumes
+	// (optional, defaults to 5)
+	MaxAttempts int
+	// SHA256 is the expected hex-encoded SHA-256 digest of the complete
+	// payload (optional)
+	SHA256 string
+}
+
+// DownloadAudio streams the audio at url into dst and returns the number of
+// bytes written. When the connection drops midway, the download is resumed
+// with an HTTP Range request from the last byte received, so large outputs
+// survive flaky networks. Transport errors, 429 and 5xx responses are retried
+// with exponential backoff.
+//
+// The API key is not sent: url is expected to be a pre-signed download link.
+// Note that ClientConfig.Timeout bounds each connection, not the whole download.
+func (c *Client) DownloadAudio(ctx context.Context, url string, dst io.Writer, opts *DownloadOptions) (int64, error) {
+	if url == "" {
+		return 0, fmt.Errorf("url cannot be empty")
+	}
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	attempts := opts.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultDownloadAttempts
+	}
+
+	digest := sha256.New()
+	out := io.MultiWriter(dst, digest)
+	var written int64
+	var lastErr error
+	var delay time.Duration
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			delay = c.retryDelay(attempt-1, delay)
+			wait := delay
+			var apiErr *APIError
+			if errors.As(lastErr, &apiErr) && apiErr.RetryAfter > wait {
+				wait = apiErr.RetryAfter
+			}
+			if err := sleepContext(ctx, c.clock, wait); err != nil {
+				return written, err
+			}
+		}
+		n, retry, err := c.downloadFrom(ctx, url, written, out)
+		written += n
+		if err == nil {
+			return written, verifyDigest(digest, opts.SHA256)
+		}
+		if !retry || ctx.Err() != nil {
+			return written, err
+		}
+		lastErr = err
+	}
+	return written, fmt.Errorf("download failed after %d attempts: %w", attempts, lastErr)
+}
+
+// downloadFrom performs one GET starting at offset and copies the body into
+// out. It reports whether a failure may be retried.
+func (c *Client) downloadFrom(ctx context.Context, url string, offset int64, out io.Writer) (int64, bool, error) {
+	if c.refuses(ctx) {
+		return 0, false, ErrClientClosed
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setUserAgent(req.Header)
+	setCorrelationID(req)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if want := fmt.Sprintf("bytes %d-", offset); !strings.HasPrefix(resp.Header.Get("Content-Range"), want) {
+			return 0, false, fmt.Errorf("unexpected Content-Range %q for resume at byte %d", resp.Header.Get("Content-Range"), offset)
+		}
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the Range header; skip what was already written.
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			return 0, true, err
+		}
+	default:
+		apiErr := c.handleErrorResponse(resp)
+		return 0, DefaultShouldRetry(req, resp, nil), apiErr
+	}
+
+	n, err := io.Copy(out, resp.Body)
+	return n, true, err
+}
+
+func verifyDigest(digest hash.Hash, want string) error {
+	if want == "" {
+		return nil
+	}
+	got := hex.EncodeToString(digest.Sum(nil))
+	if !strings.EqualFold(got, want) {
+		return &IntegrityError{Check: "checksum", Expected: "sha256 " + want, Actual: "sha256 " + got}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newDownloadTestClient() *Client {
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://x"})
+	c.retryBaseDelay = time.Millisecond
+	return c
+}
+
+// flakyDownloadServer serves payload, dropping the connection after cut bytes
+// on the first drops requests. When honorRange is false, Range is ignored.
+func flakyDownloadServer(t *testing.T, payload []byte, cut, drops int, honorRange bool) (*httptest.Server, *[]string) {
+	var ranges []string
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-KEY") != "" {
+			t.Errorf("API key must not be sent to download URLs")
+		}
+		calls++
+		ranges = append(ranges, r.Header.Get("Range"))
+		start := 0
+		if rng := r.Header.Get("Range"); rng != "" && honorRange {
+			start, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(payload)-1, len(payload)))
+			w.Header().Set("Content-Length", strconv.Itoa(len(payload)-start))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		}
+		body := payload[start:]
+		if calls <= drops {
+			_, _ = w.Write(body[:cut])
+			w.(http.Flusher).Flush()
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	return srv, &ranges
+}
+
+func TestDownloadAudio_ResumesWithRange(t *testing.T) {
+	payload := bytes.Repeat([]byte("0123456789"), 100)
+	srv, ranges := flakyDownloadServer(t, payload, 300, 2, true)
+	defer srv.Close()
+
+	var dst bytes.Buffer
+	n, err := newDownloadTestClient().DownloadAudio(context.Background(), srv.URL, &dst, &DownloadOptions{SHA256: sha256Hex(payload)})
+	if err != nil {
+		t.Fatalf("DownloadAudio() error = %v", err)
+	}
+	if n != int64(len(payload)) || !bytes.Equal(dst.Bytes(), payload) {
+		t.Fatalf("downloaded %d bytes, payload mismatch", n)
+	}
+	want := []string{"", "bytes=300-", "bytes=600-"}
+	if strings.Join(*ranges, ",") != strings.Join(want, ",") {
+		t.Fatalf("ranges = %q, want %q", *ranges, want)
+	}
+}
+
+func TestDownloadAudio_ServerIgnoringRangeSkipsReceivedBytes(t *testing.T) {
+	payload := bytes.Repeat([]byte("abcdef"), 50)
+	srv, _ := flakyDownloadServer(t, payload, 100, 1, false)
+	defer srv.Close()
+
+	var dst bytes.Buffer
+	if _, err := newDownloadTestClient().DownloadAudio(context.Background(), srv.URL, &dst, nil); err != nil {
+		t.Fatalf("DownloadAudio() error = %v", err)
+	}
+	if !bytes.Equal(dst.Bytes(), payload) {
+		t.Fatalf("payload mismatch: %q", dst.Bytes())
+	}
+}
+
+func TestDownloadAudio_ChecksumMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+
+	_, err := newDownloadTestClient().DownloadAudio(context.Background(), srv.URL, &bytes.Buffer{}, &DownloadOptions{SHA256: sha256Hex([]byte("other"))})
+	var integrity *IntegrityError
+	if !errors.As(err, &integrity) || integrity.Check != "checksum" {
+		t.Fatalf("expected checksum IntegrityError, got %v", err)
+	}
+}
+
+func TestDownloadAudio_GivesUpAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	_, err := newDownloadTestClient().DownloadAudio(context.Background(), srv.URL, &bytes.Buffer{}, &DownloadOptions{MaxAttempts: 3})
+	if err == nil || !strings.Contains(err.Error(), "download failed after 3 attempts") {
+		t.Fatalf("expected attempts error, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDownloadAudio_NonRetryableErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// A 206 that does not start at the requested offset.
+		if r.Header.Get("Range") != "" {
+			w.Header().Set("Content-Range", "bytes 0-9/10")
+			w.WriteHeader(http.StatusPartialContent)
+			return
+		}
+		w.Header().Set("Content-Length", "10")
+		_, _ = w.Write([]byte("01234"))
+		w.(http.Flusher).Flush()
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer srv.Close()
+	c := newDownloadTestClient()
+	ctx := context.Background()
+
+	if _, err := c.DownloadAudio(ctx, srv.URL+"/missing", &bytes.Buffer{}, nil); err == nil || !strings.Contains(err.Error(), "Not Found") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if _, err := c.DownloadAudio(ctx, srv.URL, &bytes.Buffer{}, nil); err == nil || !strings.Contains(err.Error(), "unexpected Content-Range") {
+		t.Fatalf("expected Content-Range error, got %v", err)
+	}
+	if _, err := c.DownloadAudio(ctx, "", &bytes.Buffer{}, nil); err == nil || !strings.Contains(err.Error(), "url cannot be empty") {
+		t.Fatalf("expected url error, got %v", err)
+	}
+	if _, err := c.DownloadAudio(ctx, "://bad", &bytes.Buffer{}, nil); err == nil || !strings.Contains(err.Error(), "failed to create request") {
+		t.Fatalf("expected request error, got %v", err)
+	}
+}
+
+func TestDownloadAudio_ContextCanceledDuringBackoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	c := newDownloadTestClient()
+	c.retryBaseDelay = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, err := c.DownloadAudio(ctx, srv.URL, &bytes.Buffer{}, nil); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestDownloadAudio_TransportErrorAfterCancel(t *testing.T) {
+	c := newDownloadTestClient()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.DownloadAudio(ctx, "http://127.0.0.1:1", &bytes.Buffer{}, nil); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestDownloadAudio_ServerIgnoringRangeDropsAgain(t *testing.T) {
+	payload := bytes.Repeat([]byte("x"), 200)
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		n := 100
+		if calls > 1 {
+			n = 50 // drop before reaching the resume offset
+		}
+		if calls > 2 {
+			_, _ = w.Write(payload)
+			return
+		}
+		_, _ = w.Write(payload[:n])
+		w.(http.Flusher).Flush()
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer srv.Close()
+
+	var dst bytes.Buffer
+	if _, err := newDownloadTestClient().DownloadAudio(context.Background(), srv.URL, &dst, nil); err != nil {
+		t.Fatalf("DownloadAudio() error = %v", err)
+	}
+	if !bytes.Equal(dst.Bytes(), payload) {
+		t.Fatalf("payload mismatch: %d bytes", dst.Len())
+	}
+}
+
+func TestDownloadAudio_WaitsForRetryAfter(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+	clock := NewFakeClock(time.Unix(0, 0))
+	c := NewClient(&ClientConfig{APIKey: "k", Clock: clock})
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.DownloadAudio(context.Background(), srv.URL, &bytes.Buffer{}, nil)
+		done <- err
+	}()
+	clock.WaitForTimers(1)
+	clock.Advance(29 * time.Second)
+	select {
+	case err := <-done:
+		t.Fatalf("retried before Retry-After: %v", err)
+	default:
+	}
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,131 @@
+package typecast
+
+import (
+	"strings"
+	"unicode"
+)
+
+// EmojiPolicy decides what happens to emoji and pictographic symbols in
+// request text.
+type EmojiPolicy string
+
+const (
+	// EmojiPassThrough sends emoji as written, leaving them to the API. It
+	// is the default.
+	EmojiPassThrough EmojiPolicy = ""
+	// EmojiStrip removes emoji and pictographic symbols.
+	EmojiStrip EmojiPolicy = "strip"
+	// EmojiSpell replaces common emoji and symbols with their names, such as
+	// "thumbs up emoji", and removes the others.
+	EmojiSpell EmojiPolicy = "spell"
+)
+
+// ApplyEmojiPolicy rewrites the emoji in text according to policy. An emoji
+// sequence, such as a skin-toned, flag, or ZWJ-joined emoji, is handled as
+// one emoji; EmojiSpell names it after its first component it knows.
+// Keycaps keep their digit or sign. Letter-like symbols of the CJK blocks,
+// such as ㈜, are not emoji and are kept.
+func ApplyEmojiPolicy(text string, policy EmojiPolicy) string {
+	if policy == EmojiPassThrough || !hasEmoji(text) {
+		return text
+	}
+	runes := []rune(text)
+	var b strings.Builder
+	spaceBefore := false // a spelled name needs a space before the next word
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if !isEmoji(r) {
+			if isEmojiPart(r) && r != zeroWidthJoiner {
+				i++ // a stray modifier, such as a keycap's
+				continue
+			}
+			if spaceBefore && !unicode.IsSpace(r) && !unicode.IsPunct(r) {
+				b.WriteByte(' ')
+			}
+			spaceBefore = false
+			b.WriteRune(r)
+			i++
+			continue
+		}
+		name, end := emojiSequence(runes, i)
+		i = end
+		if policy != EmojiSpell || name == "" {
+			continue
+		}
+		if s := b.String(); s != "" && !unicode.IsSpace(rune(s[len(s)-1])) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(name)
+		b.WriteString(" emoji")
+		spaceBefore = true
+	}
+	return b.String()
+}
+
+// zeroWidthJoiner joins emoji into one, but also shapes letters in some
+// scripts.
+const zeroWidthJoiner = 0x200D
+
+// emojiSequence returns the name of the emoji sequence starting at
+// runes[start], after its first component with a known name, and the index
+// after the sequence.
+func emojiSequence(runes []rune, start int) (string, int) {
+	name := emojiNames[runes[start]]
+	regional := isRegionalIndicator(runes[start])
+	if name == "" && regional {
+		name = "flag"
+	}
+	i := start + 1
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case r == zeroWidthJoiner && i+1 < len(runes) && isEmoji(runes[i+1]):
+			if name == "" {
+				name = emojiNames[runes[i+1]]
+			}
+			i += 2
+		case r != zeroWidthJoiner && isEmojiPart(r):
+			i++
+		case regional && isRegionalIndicator(r):
+			regional = false // a flag is a pair of indicators
+			i++
+		default:
+			return name, i
+		}
+	}
+	return name, i
+}
+
+// isRegionalIndicator reports whether r is one of the letters that pair into
+// flag emoji.
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// hasEmoji reports whether text holds an emoji or emoji sequence part.
+func hasEmoji(text string) bool {
+	for _, r := range text {
+		if isEmoji(r) || (isEmojiPart(r) && r != zeroWidthJoiner) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmoji reports whether r is an emoji or pictographic symbol: a symbol
+// (category So) from the symbol blocks that hold emoji, or a copyright or
+// registered sign.
+func isEmoji(r rune) bool {
+	if r == 0xA9 || r == 0xAE {
+		return true
+	}
+	return ((r >= 0x2100 && r <= 0x2BFF) || (r >= 0x1F000 && r <= 0x1FAFF)) && unicode.Is(unicode.So, r)
+}
+
+// isEmojiPart reports whether r only modifies or joins emoji: a variation
+// selector, zero-width joiner, skin tone modifier, tag character, or the
+// keycap mark.
+func isEmojiPart(r rune) bool {
+	return r == 0xFE0F || r == 0xFE0E || r == zeroWidthJoiner || r == 0x20E3 ||
+		(r >= 0x1F3FB && r <= 0x1F3FF) || (r >= 0xE0020 && r <= 0xE007F)
+}
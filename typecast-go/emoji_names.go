@@ -0,0 +1,143 @@
+package typecast
+
+// emojiNames maps common emoji and symbols to their short names, following
+// the Unicode CLDR names, for EmojiSpell.
+var emojiNames = map[rune]string{
+	0x1F44D: "thumbs up",      // 👍
+	0x1F44E: "thumbs down",    // 👎
+	0x1F44F: "clapping hands", // 👏
+	0x1F64F: "folded hands",   // 🙏
+	0x1F64C: "raising hands",  // 🙌
+	0x1F44B: "waving hand",    // 👋
+	0x1F44C: "OK hand",        // 👌
+	0x270C:  "victory hand",
+	0x1F91D: "handshake",                       // 🤝
+	0x1F4AA: "flexed biceps",                   // 💪
+	0x1F440: "eyes",                            // 👀
+	0x1F602: "face with tears of joy",          // 😂
+	0x1F923: "rolling on the floor laughing",   // 🤣
+	0x1F60A: "smiling face with smiling eyes",  // 😊
+	0x1F600: "grinning face",                   // 😀
+	0x1F603: "grinning face with big eyes",     // 😃
+	0x1F604: "grinning face with smiling eyes", // 😄
+	0x1F601: "beaming face with smiling eyes",  // 😁
+	0x1F605: "grinning face with sweat",        // 😅
+	0x1F606: "grinning squinting face",         // 😆
+	0x1F609: "winking face",                    // 😉
+	0x1F60D: "smiling face with heart-eyes",    // 😍
+	0x1F618: "face blowing a kiss",             // 😘
+	0x1F970: "smiling face with hearts",        // 🥰
+	0x1F929: "star-struck",                     // 🤩
+	0x1F60E: "smiling face with sunglasses",    // 😎
+	0x1F914: "thinking face",                   // 🤔
+	0x1F644: "face with rolling eyes",          // 🙄
+	0x1F62D: "loudly crying face",              // 😭
+	0x1F622: "crying face",                     // 😢
+	0x1F621: "enraged face",                    // 😡
+	0x1F620: "angry face",                      // 😠
+	0x1F631: "face screaming in fear",          // 😱
+	0x1F633: "flushed face",                    // 😳
+	0x1F97A: "pleading face",                   // 🥺
+	0x1F634: "sleeping face",                   // 😴
+	0x1F637: "face with medical mask",          // 😷
+	0x1F642: "slightly smiling face",           // 🙂
+	0x1F643: "upside-down face",                // 🙃
+	0x1F610: "neutral face",                    // 😐
+	0x1F612: "unamused face",                   // 😒
+	0x1F614: "pensive face",                    // 😔
+	0x1F61E: "disappointed face",               // 😞
+	0x1F973: "partying face",                   // 🥳
+	0x1F92F: "exploding head",                  // 🤯
+	0x1F480: "skull",                           // 💀
+	0x1F4A9: "pile of poo",                     // 💩
+	0x1F921: "clown face",                      // 🤡
+	0x1F47B: "ghost",                           // 👻
+	0x1F916: "robot",                           // 🤖
+	0x2764:  "red heart",
+	0x1F494: "broken heart",    // 💔
+	0x1F495: "two hearts",      // 💕
+	0x1F496: "sparkling heart", // 💖
+	0x1F49C: "purple heart",    // 💜
+	0x1F499: "blue heart",      // 💙
+	0x1F49A: "green heart",     // 💚
+	0x1F49B: "yellow heart",    // 💛
+	0x1F5A4: "black heart",     // 🖤
+	0x1F90D: "white heart",     // 🤍
+	0x1F525: "fire",            // 🔥
+	0x2728:  "sparkles",
+	0x2B50:  "star",
+	0x1F31F: "glowing star", // 🌟
+	0x26A1:  "high voltage",
+	0x1F4AF: "hundred points", // 💯
+	0x1F389: "party popper",   // 🎉
+	0x1F38A: "confetti ball",  // 🎊
+	0x1F381: "wrapped gift",   // 🎁
+	0x1F382: "birthday cake",  // 🎂
+	0x1F388: "balloon",        // 🎈
+	0x1F3B5: "musical note",   // 🎵
+	0x1F3B6: "musical notes",  // 🎶
+	0x1F4E3: "megaphone",      // 📣
+	0x1F4E2: "loudspeaker",    // 📢
+	0x1F514: "bell",           // 🔔
+	0x2705:  "check mark button",
+	0x2714:  "check mark",
+	0x274C:  "cross mark",
+	0x2757:  "exclamation mark",
+	0x2753:  "question mark",
+	0x26A0:  "warning",
+	0x1F6A8: "police car light",             // 🚨
+	0x1F680: "rocket",                       // 🚀
+	0x1F4A1: "light bulb",                   // 💡
+	0x1F4B0: "money bag",                    // 💰
+	0x1F4B5: "dollar banknote",              // 💵
+	0x1F4C8: "chart increasing",             // 📈
+	0x1F4C9: "chart decreasing",             // 📉
+	0x1F4F1: "mobile phone",                 // 📱
+	0x1F4BB: "laptop",                       // 💻
+	0x1F4E7: "e-mail",                       // 📧
+	0x1F4CC: "pushpin",                      // 📌
+	0x1F4CD: "round pushpin",                // 📍
+	0x1F517: "link",                         // 🔗
+	0x1F512: "locked",                       // 🔒
+	0x1F50D: "magnifying glass tilted left", // 🔍
+	0x23F0:  "alarm clock",
+	0x231B:  "hourglass done",
+	0x1F4C5: "calendar", // 📅
+	0x2600:  "sun",
+	0x2601:  "cloud",
+	0x1F308: "rainbow", // 🌈
+	0x2744:  "snowflake",
+	0x1F30D: "globe showing Europe-Africa",  // 🌍
+	0x1F30E: "globe showing Americas",       // 🌎
+	0x1F30F: "globe showing Asia-Australia", // 🌏
+	0x1F338: "cherry blossom",               // 🌸
+	0x1F339: "rose",                         // 🌹
+	0x1F33B: "sunflower",                    // 🌻
+	0x1F340: "four leaf clover",             // 🍀
+	0x1F436: "dog face",                     // 🐶
+	0x1F431: "cat face",                     // 🐱
+	0x2615:  "hot beverage",
+	0x1F355: "pizza",           // 🍕
+	0x1F37A: "beer mug",        // 🍺
+	0x1F377: "wine glass",      // 🍷
+	0x1F3C6: "trophy",          // 🏆
+	0x1F947: "1st place medal", // 🥇
+	0x26BD:  "soccer ball",
+	0x1F3AE: "video game", // 🎮
+	0x2708:  "airplane",
+	0x1F697: "automobile", // 🚗
+	0x1F3E0: "house",      // 🏠
+	0x00A9:  "copyright",
+	0x00AE:  "registered",
+	0x2122:  "trade mark",
+	0x2605:  "star",
+	0x2606:  "star",
+	0x2665:  "heart suit",
+	0x2713:  "check mark",
+	0x2717:  "cross mark",
+	0x266A:  "eighth note",
+	0x266B:  "beamed eighth notes",
+	0x260E:  "telephone",
+	0x263A:  "smiling face",
+	0x2639:  "frowning face",
+}
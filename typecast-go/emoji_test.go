@@ -0,0 +1,58 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestApplyEmojiPolicy(t *testing.T) {
+	tests := []struct {
+		in           string
+		strip, spell string
+	}{
+		{"Great job \U0001F44D", "Great job ", "Great job thumbs up emoji"},
+		{"\U0001F44Dnice!", "nice!", "thumbs up emoji nice!"},
+		{"좋아요\U0001F44D\U0001F44D!", "좋아요!", "좋아요 thumbs up emoji thumbs up emoji!"},
+		{"Love ❤\uFE0F it", "Love  it", "Love red heart emoji it"},
+		{"Waves \U0001F44B\U0001F3FD hi", "Waves  hi", "Waves waving hand emoji hi"},
+		{"Dev \U0001F469\u200D\U0001F4BB!", "Dev !", "Dev laptop emoji!"},
+		{"Go \U0001F1F0\U0001F1F7\U0001F1EF\U0001F1F5", "Go ", "Go flag emoji flag emoji"},
+		{"Press 1\uFE0F\u20E3 now", "Press 1 now", "Press 1 now"},
+		{"Acme® © 2026", "Acme  2026", "Acme registered emoji copyright emoji 2026"},
+		{"odd \U0001F9A9 bird", "odd  bird", "odd  bird"},
+		{"㈱ 금융 → ok", "㈱ 금융 → ok", "㈱ 금융 → ok"},
+		{"क्\u200Dष", "क्\u200Dष", "क्\u200Dष"},
+	}
+	for _, tt := range tests {
+		if got := ApplyEmojiPolicy(tt.in, EmojiStrip); got != tt.strip {
+			t.Errorf("strip %q = %q, want %q", tt.in, got, tt.strip)
+		}
+		if got := ApplyEmojiPolicy(tt.in, EmojiSpell); got != tt.spell {
+			t.Errorf("spell %q = %q, want %q", tt.in, got, tt.spell)
+		}
+		if got := ApplyEmojiPolicy(tt.in, EmojiPassThrough); got != tt.in {
+			t.Errorf("pass through %q = %q", tt.in, got)
+		}
+	}
+}
+
+func TestEmojiPolicyInRequests(t *testing.T) {
+	var text string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		text = body.Text
+		_, _ = w.Write([]byte("RIFF"))
+	}))
+	defer srv.Close()
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, EmojiPolicy: EmojiSpell, DisableTextNormalization: true})
+	if _, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "Shipped \U0001F680", Model: ModelSSFMV30}); err != nil {
+		t.Fatal(err)
+	}
+	if text != "Shipped rocket emoji" {
+		t.Fatalf("sent %q", text)
+	}
+}
@@ -0,0 +1,45 @@
+package typecast
+
+import "fmt"
+
+// Environment selects the Typecast deployment a client talks to.
+type Environment string
+
+const (
+	// EnvironmentProduction is the billed production API at DefaultBaseURL.
+	EnvironmentProduction Environment = "production"
+	// EnvironmentStaging is the staging API at StagingBaseURL, for
+	// integration tests that should not bill production usage.
+	EnvironmentStaging Environment = "staging"
+	// EnvironmentSandbox is a sandbox deployment, such as a local mock or
+	// gateway. It has no default URL, so BaseURL (or TYPECAST_API_HOST) must
+	// be set; requests never fall back to production.
+	EnvironmentSandbox Environment = "sandbox"
+)
+
+// StagingBaseURL is the base URL of EnvironmentStaging.
+const StagingBaseURL = "https://api.icepeak.in"
+
+// production reports whether e is the production environment, which is the
+// default.
+func (e Environment) production() bool {
+	return e == "" || e == EnvironmentProduction
+}
+
+// resolveBaseURL returns the normalized base URL of environment, or baseURL
+// when it is set.
+func resolveBaseURL(environment Environment, baseURL string) (string, error) {
+	if baseURL != "" {
+		return parseBaseURL(baseURL)
+	}
+	switch environment {
+	case "", EnvironmentProduction:
+		return DefaultBaseURL, nil
+	case EnvironmentStaging:
+		return StagingBaseURL, nil
+	case EnvironmentSandbox:
+		return "", newValidationError("environment", "the sandbox environment requires a base URL; set BaseURL or TYPECAST_API_HOST")
+	default:
+		return "", newValidationError("environment", fmt.Sprintf("unknown environment %q: must be one of production, staging, or sandbox", environment))
+	}
+}
@@ -0,0 +1,75 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnvironmentBaseURLs(t *testing.T) {
+	t.Setenv("TYPECAST_API_HOST", "")
+	t.Setenv("TYPECAST_ENV", "")
+	tests := []struct {
+		environment Environment
+		baseURL     string
+		want        string
+	}{
+		{"", "", DefaultBaseURL},
+		{EnvironmentProduction, "", DefaultBaseURL},
+		{EnvironmentStaging, "", StagingBaseURL},
+		{EnvironmentSandbox, "http://localhost:8080/", "http://localhost:8080"},
+		{EnvironmentStaging, "https://gateway.corp/typecast", "https://gateway.corp/typecast"},
+	}
+	for _, tt := range tests {
+		c, err := New(&ClientConfig{Environment: tt.environment, BaseURL: tt.baseURL})
+		if err != nil || c.baseURL != tt.want {
+			t.Errorf("New(%q, %q) base URL = %v, %v; want %q", tt.environment, tt.baseURL, c, err, tt.want)
+		}
+	}
+
+	t.Setenv("TYPECAST_ENV", "staging")
+	if c := NewClient(nil); c.baseURL != StagingBaseURL || c.environment != EnvironmentStaging {
+		t.Fatalf("expected TYPECAST_ENV to select staging, got %q", c.baseURL)
+	}
+}
+
+func TestEnvironmentErrors(t *testing.T) {
+	t.Setenv("TYPECAST_API_HOST", "")
+	t.Setenv("TYPECAST_ENV", "")
+	var validationErr *ValidationError
+	if _, err := New(&ClientConfig{Environment: EnvironmentSandbox}); !errors.As(err, &validationErr) || validationErr.Field != "environment" {
+		t.Fatalf("expected the sandbox to require a base URL, got %v", err)
+	}
+	if _, err := New(&ClientConfig{Environment: "prod"}); !errors.As(err, &validationErr) || validationErr.Field != "environment" {
+		t.Fatalf("expected an unknown environment error, got %v", err)
+	}
+
+	// Sandbox requests never fall back to production.
+	c := NewClient(&ClientConfig{APIKey: "k", Environment: EnvironmentSandbox})
+	if _, err := c.GetVoicesV2(context.Background(), nil); !errors.As(err, &validationErr) {
+		t.Fatalf("expected the configuration error, got %v", err)
+	}
+}
+
+func TestEnvironmentAPIKeyOptionalOutsideProduction(t *testing.T) {
+	t.Setenv("TYPECAST_API_KEY", "")
+	t.Setenv("TYPECAST_ENV", "")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	// A keyless staging client may even target the production host.
+	c := NewClient(&ClientConfig{Environment: EnvironmentStaging, BaseURL: DefaultBaseURL})
+	if err := c.setAuthHeader(http.Header{}); err != nil {
+		t.Fatalf("expected no key to be required outside production, got %v", err)
+	}
+	if err := NewClient(&ClientConfig{BaseURL: DefaultBaseURL}).setAuthHeader(http.Header{}); err == nil {
+		t.Fatal("expected production to require a key")
+	}
+	if _, err := NewClient(&ClientConfig{Environment: EnvironmentSandbox, BaseURL: srv.URL}).GetVoicesV2(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+}
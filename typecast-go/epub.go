@@ -0,0 +1,279 @@
+package typecast
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidEPUB is returned when an EPUB is missing its container, package
+// document, or a file its spine lists.
+var ErrInvalidEPUB = errors.New("typecast: invalid EPUB")
+
+// bookManifestName is the manifest file NarrateBook writes next to the
+// chapter audio.
+const bookManifestName = "book.json"
+
+// Book is the metadata and chapter text of an EPUB.
+type Book struct {
+	// Title is the book's title
+	Title string `json:"title"`
+	// Author is the book's first creator
+	Author string `json:"author,omitempty"`
+	// Language is the book's language tag, such as "en"
+	Language string `json:"language,omitempty"`
+	// Chapters holds the chapters in reading order
+	Chapters []BookChapter `json:"-"`
+}
+
+// BookChapter is one document of an EPUB's reading order.
+type BookChapter struct {
+	// Title is the chapter's first heading, or its document title
+	Title string
+	// Text is the chapter's paragraphs separated by blank lines, headings
+	// included
+	Text string
+}
+
+// OpenEPUB reads the book at path.
+func OpenEPUB(path string) (*Book, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEPUB, err)
+	}
+	defer archive.Close()
+	return readBook(&archive.Reader)
+}
+
+// ReadEPUB reads an EPUB 2 or 3 book and extracts its chapters in reading
+// order. Documents without text, such as cover pages, are skipped, as are
+// footnotes, navigation, and documents the spine marks as not linear.
+func ReadEPUB(r io.ReaderAt, size int64) (*Book, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEPUB, err)
+	}
+	return readBook(archive)
+}
+
+func readBook(archive *zip.Reader) (*Book, error) {
+	var container struct {
+		Rootfiles []struct {
+			FullPath string `xml:"full-path,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := readEPUBXML(archive, "META-INF/container.xml", &container); err != nil {
+		return nil, err
+	}
+	if len(container.Rootfiles) == 0 {
+		return nil, fmt.Errorf("%w: container.xml lists no package document", ErrInvalidEPUB)
+	}
+	opfPath := container.Rootfiles[0].FullPath
+	var pkg struct {
+		Titles    []string `xml:"metadata>title"`
+		Creators  []string `xml:"metadata>creator"`
+		Languages []string `xml:"metadata>language"`
+		Items     []struct {
+			ID         string `xml:"id,attr"`
+			Href       string `xml:"href,attr"`
+			MediaType  string `xml:"media-type,attr"`
+			Properties string `xml:"properties,attr"`
+		} `xml:"manifest>item"`
+		Spine []struct {
+			IDRef  string `xml:"idref,attr"`
+			Linear string `xml:"linear,attr"`
+		} `xml:"spine>itemref"`
+	}
+	if err := readEPUBXML(archive, opfPath, &pkg); err != nil {
+		return nil, err
+	}
+	book := &Book{
+		Title:    firstNonEmpty(pkg.Titles),
+		Author:   firstNonEmpty(pkg.Creators),
+		Language: firstNonEmpty(pkg.Languages),
+	}
+	hrefs := map[string]string{}
+	for _, item := range pkg.Items {
+		if strings.Contains(item.MediaType, "html") && !strings.Contains(" "+item.Properties+" ", " nav ") {
+			hrefs[item.ID] = item.Href
+		}
+	}
+	for _, ref := range pkg.Spine {
+		href, ok := hrefs[ref.IDRef]
+		if !ok || ref.Linear == "no" {
+			continue
+		}
+		name, err := url.PathUnescape(strings.SplitN(href, "#", 2)[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEPUB, err)
+		}
+		doc, err := readEPUBFile(archive, path.Join(path.Dir(opfPath), name))
+		if err != nil {
+			return nil, err
+		}
+		if chapter, ok := bookChapter(parseHTML(string(doc)), len(book.Chapters)+1); ok {
+			book.Chapters = append(book.Chapters, chapter)
+		}
+	}
+	return book, nil
+}
+
+// bookChapter returns the chapter in a spine document, titled after its
+// first heading, its <title>, or its number. It returns false when the
+// document has no text.
+func bookChapter(root *htmlNode, number int) (BookChapter, bool) {
+	body := root.find(func(n *htmlNode) bool { return n.tag == "body" })
+	if body == nil {
+		body = root
+	}
+	collector := articleCollector{skip: isBookBoilerplate}
+	collector.walk(body, false)
+	if len(collector.blocks) == 0 {
+		return BookChapter{}, false
+	}
+	chapter := BookChapter{Text: strings.Join(collector.blocks, "\n\n")}
+	if heading := body.find(func(n *htmlNode) bool {
+		return (n.tag == "h1" || n.tag == "h2" || n.tag == "h3") && n.textContent() != ""
+	}); heading != nil {
+		chapter.Title = heading.textContent()
+	} else if title := root.find(func(n *htmlNode) bool { return n.tag == "title" }); title != nil && title.textContent() != "" {
+		chapter.Title = title.textContent()
+	} else {
+		chapter.Title = fmt.Sprintf("Chapter %d", number)
+	}
+	return chapter, true
+}
+
+// isBookBoilerplate reports whether n is navigation, a note or note
+// reference, or hidden, so its text is not read within a chapter.
+func isBookBoilerplate(n *htmlNode) bool {
+	if n.tag == "nav" || n.tag == "aside" {
+		return true
+	}
+	if _, hidden := n.attrs["hidden"]; hidden {
+		return true
+	}
+	switch n.attrs["epub:type"] {
+	case "footnote", "endnote", "rearnote", "note", "noteref":
+		return true
+	}
+	return false
+}
+
+func readEPUBFile(archive *zip.Reader, name string) ([]byte, error) {
+	f, err := archive.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEPUB, err)
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func readEPUBXML(archive *zip.Reader, name string, v interface{}) error {
+	data, err := readEPUBFile(archive, name)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidEPUB, name, err)
+	}
+	return nil
+}
+
+func firstNonEmpty(values []string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// BookOptions configures NarrateBook.
+type BookOptions struct {
+	// Profile holds the voice and synthesis settings (required)
+	Profile VoiceProfile
+	// OutputDir receives one audio file per chapter and book.json (required)
+	OutputDir string
+	// MaxChunkChars caps the characters per request (optional, defaults to 2000)
+	MaxChunkChars int
+	// Seed is sent with every chunk for reproducible output (optional)
+	Seed *int
+}
+
+// BookChapterAudio describes the audio file of one narrated chapter.
+type BookChapterAudio struct {
+	// Track is the chapter's 1-based position in the book
+	Track int `json:"track"`
+	// Title is the chapter's title
+	Title string `json:"title"`
+	// File is the audio file's name, relative to OutputDir
+	File string `json:"file"`
+	// Duration is the chapter's length in seconds
+	Duration float64 `json:"duration"`
+}
+
+// NarrateBook narrates each chapter of book with LongFormSynthesize and
+// writes it to OutputDir as chapter-001.wav, chapter-002.wav, and so on,
+// then lists the book's metadata and chapter files in book.json. MP3
+// chapters are tagged with the chapter and book titles, author, and track
+// number for audiobook players.
+func (c *Client) NarrateBook(ctx context.Context, book *Book, opts BookOptions) ([]BookChapterAudio, error) {
+	if len(book.Chapters) == 0 {
+		return nil, newValidationError("chapters", "book has no chapters")
+	}
+	if opts.OutputDir == "" {
+		return nil, newValidationError("output_dir", "output_dir is required")
+	}
+	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+		return nil, err
+	}
+	chapters := make([]BookChapterAudio, 0, len(book.Chapters))
+	for i, chapter := range book.Chapters {
+		result, err := c.LongFormSynthesize(ctx, LongFormRequest{
+			Profile:       opts.Profile,
+			Text:          chapter.Text,
+			MaxChunkChars: opts.MaxChunkChars,
+			Seed:          opts.Seed,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("chapter %d: %w", i+1, err)
+		}
+		audio := result.AudioData
+		if result.Format == AudioFormatMP3 {
+			audio = append(id3Tag(
+				id3TextFrame("TIT2", chapter.Title),
+				id3TextFrame("TALB", book.Title),
+				id3TextFrame("TPE1", book.Author),
+				id3TextFrame("TRCK", fmt.Sprintf("%d/%d", i+1, len(book.Chapters))),
+			), stripID3v2(audio)...)
+		}
+		file := fmt.Sprintf("chapter-%03d.%s", i+1, result.Format)
+		if err := writeFileAtomic(filepath.Join(opts.OutputDir, file), audio); err != nil {
+			return nil, err
+		}
+		chapters = append(chapters, BookChapterAudio{Track: i + 1, Title: chapter.Title, File: file, Duration: result.Duration})
+	}
+	manifest, _ := json.MarshalIndent(struct {
+		*Book
+		Chapters []BookChapterAudio `json:"chapters"`
+	}{book, chapters}, "", "  ")
+	if err := writeFileAtomic(filepath.Join(opts.OutputDir, bookManifestName), manifest); err != nil {
+		return nil, err
+	}
+	return chapters, nil
+}
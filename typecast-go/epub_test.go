@@ -0,0 +1,265 @@
+package typecast
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testEPUBPackage = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title> </dc:title><dc:title>The Long Rain</dc:title>
+    <dc:creator>Jo Writer</dc:creator>
+    <dc:language>en</dc:language>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="cover" href="text/cover.xhtml" media-type="application/xhtml+xml"/>
+    <item id="c1" href="text/chapter%201.xhtml" media-type="application/xhtml+xml"/>
+    <item id="c2" href="text/c2.xhtml#start" media-type="application/xhtml+xml"/>
+    <item id="c3" href="text/c3.xhtml" media-type="application/xhtml+xml"/>
+    <item id="notes" href="text/notes.xhtml" media-type="application/xhtml+xml"/>
+    <item id="img" href="images/cover.jpg" media-type="image/jpeg"/>
+  </manifest>
+  <spine>
+    <itemref idref="nav"/><itemref idref="cover"/><itemref idref="img"/>
+    <itemref idref="c1"/><itemref idref="c2"/><itemref idref="c3"/><itemref idref="notes" linear="no"/>
+  </spine>
+</package>`
+
+// testEPUBFiles returns the files of a small EPUB 3 book.
+func testEPUBFiles() map[string]string {
+	return map[string]string{
+		"mimetype":               "application/epub+zip",
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="OEBPS/content.opf"/></rootfiles></container>`,
+		"OEBPS/content.opf":      testEPUBPackage,
+		"OEBPS/nav.xhtml":        `<html><body><nav><ol><li><a href="text/c2.xhtml">One</a></li></ol></nav></body></html>`,
+		"OEBPS/text/cover.xhtml": `<html><body><img src="../images/cover.jpg"/></body></html>`,
+		"OEBPS/text/chapter 1.xhtml": `<html><head><title>Ignored</title></head><body>` +
+			`<section><header><h1>The Beginning</h1></header><p>It rained for a year.<a epub:type="noteref" href="notes.xhtml#n1">1</a></p>` +
+			`<aside epub:type="footnote"><p>A note.</p></aside><p>Nobody minded.</p></section></body></html>`,
+		"OEBPS/text/c2.xhtml":    `<html><head><title>Interlude</title></head><body><p>Then it stopped.</p></body></html>`,
+		"OEBPS/text/c3.xhtml":    `<p>It started again.</p><p epub:type="endnote">Later.</p><p hidden="">Draft.</p>`,
+		"OEBPS/text/notes.xhtml": `<html><body><p>Notes</p></body></html>`,
+	}
+}
+
+func makeTestEPUB(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range names {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.Write([]byte(files[name]))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadEPUB(t *testing.T) {
+	data := makeTestEPUB(t, testEPUBFiles())
+	book, err := ReadEPUB(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("ReadEPUB() error = %v", err)
+	}
+	want := &Book{
+		Title:    "The Long Rain",
+		Author:   "Jo Writer",
+		Language: "en",
+		Chapters: []BookChapter{
+			{Title: "The Beginning", Text: "The Beginning.\n\nIt rained for a year.\n\nNobody minded."},
+			{Title: "Interlude", Text: "Then it stopped."},
+			{Title: "Chapter 3", Text: "It started again."},
+		},
+	}
+	if !reflect.DeepEqual(book, want) {
+		t.Fatalf("ReadEPUB() = %#v, want %#v", book, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "book.epub")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	opened, err := OpenEPUB(path)
+	if err != nil || !reflect.DeepEqual(opened, want) {
+		t.Fatalf("OpenEPUB() = %#v, %v", opened, err)
+	}
+	if got := firstNonEmpty([]string{" ", ""}); got != "" {
+		t.Errorf("firstNonEmpty() = %q, want empty", got)
+	}
+}
+
+func TestReadEPUB_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := OpenEPUB(filepath.Join(dir, "missing.epub")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("OpenEPUB(missing) error = %v, want not exist", err)
+	}
+	notZip := filepath.Join(dir, "book.epub")
+	if err := os.WriteFile(notZip, []byte("not a zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenEPUB(notZip); !errors.Is(err, ErrInvalidEPUB) {
+		t.Errorf("OpenEPUB(not zip) error = %v, want ErrInvalidEPUB", err)
+	}
+	if _, err := ReadEPUB(strings.NewReader("not a zip"), 9); !errors.Is(err, ErrInvalidEPUB) {
+		t.Errorf("ReadEPUB(not zip) error = %v, want ErrInvalidEPUB", err)
+	}
+
+	tests := map[string]func(files map[string]string){
+		"no container":  func(files map[string]string) { delete(files, "META-INF/container.xml") },
+		"bad container": func(files map[string]string) { files["META-INF/container.xml"] = "<container>" },
+		"no rootfile":   func(files map[string]string) { files["META-INF/container.xml"] = "<container/>" },
+		"no package":    func(files map[string]string) { delete(files, "OEBPS/content.opf") },
+		"missing chapter": func(files map[string]string) {
+			delete(files, "OEBPS/text/c2.xhtml")
+		},
+		"bad href": func(files map[string]string) {
+			files["OEBPS/content.opf"] = strings.Replace(testEPUBPackage, "chapter%201", "chapter%zz", 1)
+		},
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			files := testEPUBFiles()
+			mutate(files)
+			data := makeTestEPUB(t, files)
+			if _, err := ReadEPUB(bytes.NewReader(data), int64(len(data))); !errors.Is(err, ErrInvalidEPUB) {
+				t.Errorf("ReadEPUB() error = %v, want ErrInvalidEPUB", err)
+			}
+		})
+	}
+
+	t.Run("corrupt chapter", func(t *testing.T) {
+		data := makeTestEPUB(t, testEPUBFiles())
+		data = bytes.Replace(data, []byte("Then it stopped."), []byte("Then it started."), 1)
+		if _, err := ReadEPUB(bytes.NewReader(data), int64(len(data))); !errors.Is(err, zip.ErrChecksum) {
+			t.Errorf("ReadEPUB() error = %v, want zip.ErrChecksum", err)
+		}
+	})
+}
+
+func TestNarrateBook(t *testing.T) {
+	var texts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		texts = append(texts, body.Text)
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Header().Set("X-Audio-Duration", "2")
+		_, _ = w.Write(makeTestMP3(3))
+	}))
+	defer srv.Close()
+	client := newTestClient(srv, "k")
+	book := &Book{Title: "The Long Rain", Author: "Jo Writer", Chapters: []BookChapter{
+		{Title: "One", Text: "It rained."},
+		{Title: "Two", Text: "It stopped."},
+	}}
+	dir := filepath.Join(t.TempDir(), "out")
+
+	chapters, err := client.NarrateBook(context.Background(), book, BookOptions{
+		Profile:   VoiceProfile{VoiceID: "tc_narrator", AudioFormat: AudioFormatMP3},
+		OutputDir: dir,
+	})
+	if err != nil {
+		t.Fatalf("NarrateBook() error = %v", err)
+	}
+	if strings.Join(texts, "|") != "It rained.|It stopped." {
+		t.Fatalf("unexpected texts: %q", texts)
+	}
+	if len(chapters) != 2 || chapters[1].Track != 2 || chapters[1].Title != "Two" || chapters[1].File != "chapter-002.mp3" {
+		t.Fatalf("unexpected chapters: %+v", chapters)
+	}
+	audio, err := os.ReadFile(filepath.Join(dir, "chapter-002.mp3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, frame := range []string{"TIT2", "TALB", "TPE1", "TRCK"} {
+		if !bytes.Contains(audio[:bytes.Index(audio, makeTestMP3(1)[:4])], []byte(frame)) {
+			t.Errorf("chapter audio has no %s frame", frame)
+		}
+	}
+	if !bytes.Contains(audio, []byte("2/2")) || !bytes.HasSuffix(audio, makeTestMP3(3)) {
+		t.Error("chapter audio is not the tagged MP3")
+	}
+	var manifest struct {
+		Title    string             `json:"title"`
+		Author   string             `json:"author"`
+		Chapters []BookChapterAudio `json:"chapters"`
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "book.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil || manifest.Title != book.Title || manifest.Author != book.Author ||
+		!reflect.DeepEqual(manifest.Chapters, chapters) {
+		t.Fatalf("unexpected manifest: %s", data)
+	}
+}
+
+func TestNarrateBook_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write(makeTestWAV(make([]byte, 1600), 8000))
+	}))
+	defer srv.Close()
+	client := newTestClient(srv, "k")
+	book := &Book{Chapters: []BookChapter{{Title: "One", Text: "It rained."}}}
+	profile := VoiceProfile{VoiceID: "tc_narrator"}
+	ctx := context.Background()
+
+	var validationErr *ValidationError
+	if _, err := client.NarrateBook(ctx, &Book{}, BookOptions{Profile: profile, OutputDir: t.TempDir()}); !errors.As(err, &validationErr) {
+		t.Errorf("empty book error = %v, want *ValidationError", err)
+	}
+	if _, err := client.NarrateBook(ctx, book, BookOptions{Profile: profile}); !errors.As(err, &validationErr) {
+		t.Errorf("no output dir error = %v, want *ValidationError", err)
+	}
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NarrateBook(ctx, book, BookOptions{Profile: profile, OutputDir: filepath.Join(file, "out")}); err == nil {
+		t.Error("expected an error creating the output directory")
+	}
+	if _, err := client.NarrateBook(ctx, book, BookOptions{OutputDir: t.TempDir()}); err == nil || !strings.HasPrefix(err.Error(), "chapter 1: ") {
+		t.Errorf("missing voice error = %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "chapter-001.wav.tmp"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NarrateBook(ctx, book, BookOptions{Profile: profile, OutputDir: dir}); err == nil {
+		t.Error("expected an error writing the chapter")
+	}
+	dir = t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "book.json.tmp"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NarrateBook(ctx, book, BookOptions{Profile: profile, OutputDir: dir}); err == nil {
+		t.Error("expected an error writing the manifest")
+	}
+}
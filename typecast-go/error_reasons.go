@@ -0,0 +1,83 @@
+package typecast
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrorReason is a machine-readable category for quota and capacity
+// failures, so schedulers can choose between pausing, switching keys, or
+// degrading gracefully.
+type ErrorReason string
+
+const (
+	// ReasonInsufficientCredits means the account is out of credits (402).
+	// Switch keys or stop; retrying will not help.
+	ReasonInsufficientCredits ErrorReason = "insufficient_credits"
+	// ReasonRateLimited means the key exceeded its request rate (429).
+	// Pause for RetryAfter and try again.
+	ReasonRateLimited ErrorReason = "rate_limited"
+	// ReasonOverloaded means the model is at capacity (503). Retry later or
+	// fall back to another model.
+	ReasonOverloaded ErrorReason = "overloaded"
+)
+
+// Sentinel errors matched by an *APIError of the corresponding reason, e.g.
+// errors.Is(err, typecast.ErrRateLimited).
+var (
+	ErrInsufficientCredits = errors.New("typecast: insufficient credits")
+	ErrRateLimited         = errors.New("typecast: rate limited")
+	ErrOverloaded          = errors.New("typecast: model overloaded")
+)
+
+// Reason returns the quota or capacity category of the error, or "" for
+// other status codes.
+func (e *APIError) Reason() ErrorReason {
+	switch e.StatusCode {
+	case http.StatusPaymentRequired:
+		return ReasonInsufficientCredits
+	case http.StatusTooManyRequests:
+		return ReasonRateLimited
+	case http.StatusServiceUnavailable:
+		return ReasonOverloaded
+	}
+	return ""
+}
+
+// Is reports whether target is the sentinel error for e's Reason.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrInsufficientCredits:
+		return e.Reason() == ReasonInsufficientCredits
+	case ErrRateLimited:
+		return e.Reason() == ReasonRateLimited
+	case ErrOverloaded:
+		return e.Reason() == ReasonOverloaded
+	}
+	return false
+}
+
+// IsOverloaded returns true if the error is a 503 Service Unavailable
+func (e *APIError) IsOverloaded() bool {
+	return e.StatusCode == http.StatusServiceUnavailable
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date. It returns 0 when the header is absent or invalid.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
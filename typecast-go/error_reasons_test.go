@@ -0,0 +1,74 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAPIError_ReasonsAndSentinels(t *testing.T) {
+	tests := []struct {
+		code     int
+		reason   ErrorReason
+		sentinel error
+	}{
+		{http.StatusPaymentRequired, ReasonInsufficientCredits, ErrInsufficientCredits},
+		{http.StatusTooManyRequests, ReasonRateLimited, ErrRateLimited},
+		{http.StatusServiceUnavailable, ReasonOverloaded, ErrOverloaded},
+		{http.StatusBadRequest, "", nil},
+	}
+	sentinels := []error{ErrInsufficientCredits, ErrRateLimited, ErrOverloaded}
+	for _, tt := range tests {
+		err := fmt.Errorf("wrapped: %w", NewAPIError(tt.code, ""))
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Reason() != tt.reason {
+			t.Fatalf("status %d: Reason() = %q, want %q", tt.code, apiErr.Reason(), tt.reason)
+		}
+		for _, sentinel := range sentinels {
+			if got := errors.Is(err, sentinel); got != (sentinel == tt.sentinel) {
+				t.Fatalf("status %d: errors.Is(%v) = %v", tt.code, sentinel, got)
+			}
+		}
+		if apiErr.IsOverloaded() != (tt.code == http.StatusServiceUnavailable) {
+			t.Fatalf("status %d: unexpected IsOverloaded()", tt.code)
+		}
+	}
+	if errors.Is(NewAPIError(http.StatusTooManyRequests, ""), errors.New("other")) {
+		t.Fatal("APIError must not match unrelated errors")
+	}
+}
+
+func TestAPIError_RetryAfterFromResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	_, err := newTestClient(srv, "k").TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "t", Model: ModelSSFMV30})
+	var apiErr *APIError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &apiErr) || apiErr.RetryAfter != 7*time.Second {
+		t.Fatalf("expected rate limited error with RetryAfter, got %#v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"":                              0,
+		"120":                           2 * time.Minute,
+		"-1":                            0,
+		"soon":                          0,
+		"Fri, 02 Jan 2026 03:04:35 GMT": 30 * time.Second,
+		"Fri, 02 Jan 2026 03:00:00 GMT": 0,
+	}
+	for header, want := range tests {
+		if got := parseRetryAfter(header, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
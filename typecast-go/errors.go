@@ -2,6 +2,7 @@ package typecast
 
 import (
 	"fmt"
+	"time"
 )
 
 // APIError represents an error returned by the Typecast API
@@ -9,13 +10,28 @@ type APIError struct {
 	StatusCode int
 	Message    string
 	Detail     string
+	// CorrelationID is the ID set with WithCorrelationID on the failed
+	// request, if any
+	CorrelationID string
+	// Tags are the tags added with WithTag to the failed request, if any
+	Tags []string
+	// RetryAfter is the wait requested by the server's Retry-After header,
+	// if any (typically on 429 and 503 responses). An HTTP-date is measured
+	// against ServerDate, so it is correct even if the local clock drifts.
+	RetryAfter time.Duration
+	// ServerDate is the response's Date header, if any
+	ServerDate time.Time
 }
 
 func (e *APIError) Error() string {
+	message := e.Message
 	if e.Detail != "" {
-		return fmt.Sprintf("%s - %s", e.Message, e.Detail)
+		message = fmt.Sprintf("%s - %s", e.Message, e.Detail)
 	}
-	return e.Message
+	if e.CorrelationID != "" {
+		message = fmt.Sprintf("%s (correlation id %s)", message, e.CorrelationID)
+	}
+	return message
 }
 
 // NewAPIError creates a new APIError from an HTTP response
@@ -39,6 +55,8 @@ func NewAPIError(statusCode int, detail string) *APIError {
 		message = "Too Many Requests - Rate limit exceeded"
 	case 500:
 		message = "Internal Server Error - Something went wrong on the server"
+	case 503:
+		message = "Service Unavailable - The model is at capacity, try again later"
 	default:
 		message = fmt.Sprintf("API request failed with status %d", statusCode)
 	}
@@ -89,3 +107,20 @@ func (e *APIError) IsServerError() bool {
 func (e *APIError) IsForbidden() bool {
 	return e.StatusCode == 403
 }
+
+// ValidationError reports a request field rejected by client-side validation
+// before any request was sent.
+type ValidationError struct {
+	// Field is the JSON name of the invalid field (e.g., "audio_tempo")
+	Field string
+	// Message describes the violated constraint
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func newValidationError(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
@@ -0,0 +1,138 @@
+package typecast
+
+import (
+	"strings"
+	"unicode"
+)
+
+// defaultCharsPerSecond holds typical speaking rates at tempo 1.0, in
+// non-whitespace characters per second, keyed by ISO 639-3 language code.
+var defaultCharsPerSecond = map[string]float64{
+	"eng": 13,
+	"kor": 7,
+	"jpn": 8,
+	"zho": 5,
+	"yue": 5,
+	"tha": 11,
+	"vie": 10,
+	"ara": 11,
+	"hin": 11,
+	"ben": 11,
+}
+
+// DurationEstimator predicts speech duration from text before synthesis,
+// using per-language characters-per-second heuristics. Tune the rates per
+// model by keeping one estimator per model.
+type DurationEstimator struct {
+	// CharsPerSecond maps ISO 639-3 language codes to speaking rates in
+	// non-whitespace characters per second at tempo 1.0
+	CharsPerSecond map[string]float64
+	// DefaultCharsPerSecond is used for languages missing from CharsPerSecond
+	DefaultCharsPerSecond float64
+	// SentencePause is the pause added per sentence, in seconds
+	SentencePause float64
+	// Tolerance is the ratio beyond which Anomalous reports a result
+	// (e.g. 2 flags results under half or over twice the estimate)
+	Tolerance float64
+}
+
+// NewDurationEstimator returns an estimator populated with the default rates.
+func NewDurationEstimator() *DurationEstimator {
+	rates := make(map[string]float64, len(defaultCharsPerSecond))
+	for language, rate := range defaultCharsPerSecond {
+		rates[language] = rate
+	}
+	return &DurationEstimator{
+		CharsPerSecond:        rates,
+		DefaultCharsPerSecond: 13,
+		SentencePause:         0.3,
+		Tolerance:             2,
+	}
+}
+
+var defaultDurationEstimator = NewDurationEstimator()
+
+// EstimateDuration returns the expected speech duration of text in seconds
+// using the default DurationEstimator. tempo is the Output.AudioTempo value
+// (values <= 0 mean 1.0); an empty language is guessed from the script.
+func EstimateDuration(text string, tempo float64, language string) float64 {
+	return defaultDurationEstimator.Estimate(text, tempo, language)
+}
+
+// Estimate returns the expected speech duration of text in seconds.
+func (e *DurationEstimator) Estimate(text string, tempo float64, language string) float64 {
+	if tempo <= 0 {
+		tempo = 1
+	}
+	if language == "" {
+		language = guessLanguage(text)
+	}
+	rate, ok := e.CharsPerSecond[strings.ToLower(language)]
+	if !ok || rate <= 0 {
+		rate = e.DefaultCharsPerSecond
+	}
+	chars := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			chars++
+		}
+	}
+	if chars == 0 || rate <= 0 {
+		return 0
+	}
+	seconds := float64(chars)/rate + float64(countSentences(text))*e.SentencePause
+	return seconds / tempo
+}
+
+// Anomalous reports whether an actual duration in seconds falls outside
+// Tolerance of the estimate for text, which usually means truncated or
+// runaway audio.
+func (e *DurationEstimator) Anomalous(text string, tempo float64, language string, actual float64) bool {
+	expected := e.Estimate(text, tempo, language)
+	if expected == 0 || e.Tolerance <= 1 {
+		return false
+	}
+	return actual < expected/e.Tolerance || actual > expected*e.Tolerance
+}
+
+// guessLanguage picks a language from the script of text, returning "" for
+// scripts whose rate is close to the default.
+func guessLanguage(text string) string {
+	var han bool
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Hangul, r):
+			return "kor"
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			return "jpn"
+		case unicode.Is(unicode.Han, r):
+			han = true
+		}
+	}
+	if han {
+		return "zho"
+	}
+	return ""
+}
+
+// countSentences counts runs of text ended by sentence punctuation, plus a
+// trailing unterminated run.
+func countSentences(text string) int {
+	count := 0
+	pending := false
+	for _, r := range text {
+		switch {
+		case isSentenceTerminator(r):
+			if pending {
+				count++
+				pending = false
+			}
+		case !unicode.IsSpace(r):
+			pending = true
+		}
+	}
+	if pending {
+		count++
+	}
+	return count
+}
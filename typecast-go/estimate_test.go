@@ -0,0 +1,81 @@
+package typecast
+
+import (
+	"math"
+	"testing"
+)
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestEstimateDuration(t *testing.T) {
+	// 26 characters at 13 chars/s = 2s, plus one 0.3s sentence pause.
+	if got := EstimateDuration("abcdefghijkl nopqrstuvwxyz.", 0, "eng"); !approx(got, 2.3) {
+		t.Fatalf("EstimateDuration(eng) = %v", got)
+	}
+	if got := EstimateDuration("abcdefghijkl nopqrstuvwxyz.", 2, "ENG"); !approx(got, 1.15) {
+		t.Fatalf("EstimateDuration(tempo 2) = %v", got)
+	}
+	if got := EstimateDuration("   ", 1, "eng"); got != 0 {
+		t.Fatalf("EstimateDuration(blank) = %v", got)
+	}
+}
+
+func TestEstimateDuration_GuessesLanguageFromScript(t *testing.T) {
+	cases := []struct {
+		text string
+		want float64
+	}{
+		{"안녕하세요", 5.0/7 + 0.3},
+		{"こんにちは", 5.0/8 + 0.3},
+		{"日本語です", 5.0/8 + 0.3},
+		{"你好世界", 4.0/5 + 0.3},
+		{"hello", 5.0/13 + 0.3},
+	}
+	for _, tc := range cases {
+		if got := EstimateDuration(tc.text, 1, ""); !approx(got, tc.want) {
+			t.Errorf("EstimateDuration(%q) = %v, want %v", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestDurationEstimator_CustomRatesAndAnomalies(t *testing.T) {
+	e := NewDurationEstimator()
+	e.CharsPerSecond["eng"] = 10
+	e.SentencePause = 0
+	if EstimateDuration("abcdefghij", 1, "eng") == e.Estimate("abcdefghij", 1, "eng") {
+		t.Fatal("custom estimator must not change the default rates")
+	}
+	if got := e.Estimate("abcdefghij", 1, "eng"); got != 1 {
+		t.Fatalf("Estimate() = %v", got)
+	}
+	if e.Anomalous("abcdefghij", 1, "eng", 1.5) {
+		t.Fatal("1.5s should be within tolerance of 1s")
+	}
+	if !e.Anomalous("abcdefghij", 1, "eng", 0.2) || !e.Anomalous("abcdefghij", 1, "eng", 3) {
+		t.Fatal("expected short and long results to be anomalous")
+	}
+	if e.Anomalous("", 1, "eng", 10) {
+		t.Fatal("empty text has no estimate to compare against")
+	}
+
+	e.DefaultCharsPerSecond = 0
+	if got := e.Estimate("abc", 1, "xyz"); got != 0 {
+		t.Fatalf("Estimate() without a usable rate = %v", got)
+	}
+}
+
+func TestCountSentences(t *testing.T) {
+	cases := map[string]int{
+		"":               0,
+		"Hello":          1,
+		"Hello. World!":  2,
+		"Wait... what?!": 2,
+		"안녕하세요。반갑습니다！ 네": 3,
+		"  ... ": 0,
+	}
+	for text, want := range cases {
+		if got := countSentences(text); got != want {
+			t.Errorf("countSentences(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package typecast
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a Clock whose time only moves when Advance is called, so
+// tests of retry backoff, rate limiting, quotas, and cache expiry run
+// instantly and deterministically:
+//
+//	clock := typecast.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+//	client := typecast.NewClient(&typecast.ClientConfig{MaxRetries: 3, Clock: clock})
+//	go func() { done <- client.TextToSpeech(ctx, request) }()
+//	clock.WaitForTimers(1) // the first backoff has started
+//	clock.Advance(time.Minute)
+//
+// It is safe for concurrent use.
+type FakeClock struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+// NewFakeClock returns a fake clock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.changed = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the fake time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer returns a timer that fires once the fake time has advanced by d.
+// A timer of d <= 0 fires at once.
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1)}
+	c.add(t, d)
+	return t
+}
+
+// AfterFunc returns a timer that calls f once the fake time has advanced by
+// d. Advance calls f before it returns; f of a timer of d <= 0 is called in
+// its own goroutine at once.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	t := &fakeTimer{clock: c, f: f}
+	c.add(t, d)
+	return t
+}
+
+// Advance moves the fake time forward by d, firing the timers that come due
+// in order of their deadlines, with Now set to each one's deadline as it
+// fires. Timers created by the functions they call fire too if they come due
+// within d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	for {
+		next := -1
+		for i, t := range c.timers {
+			if !t.when.After(end) && (next < 0 || t.when.Before(c.timers[next].when)) {
+				next = i
+			}
+		}
+		if next < 0 {
+			break
+		}
+		t := c.timers[next]
+		c.timers = append(c.timers[:next], c.timers[next+1:]...)
+		c.now = t.when
+		c.mu.Unlock()
+		t.fire(t.when)
+		c.mu.Lock()
+	}
+	c.now = end
+	c.changed.Broadcast()
+	c.mu.Unlock()
+}
+
+// Timers returns the number of timers waiting to fire.
+func (c *FakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// WaitForTimers blocks until at least n timers are waiting to fire, such as
+// when the code under test has started n backoff delays, so the test can
+// Advance past them without racing it.
+func (c *FakeClock) WaitForTimers(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.changed.Wait()
+	}
+}
+
+// add schedules t to fire d after the fake time.
+func (c *FakeClock) add(t *fakeTimer, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t.when = c.now.Add(d)
+	if d <= 0 {
+		if t.f != nil {
+			go t.f()
+		} else {
+			t.c <- t.when
+		}
+		return
+	}
+	c.timers = append(c.timers, t)
+	c.changed.Broadcast()
+}
+
+// stop removes t from the waiting timers.
+func (c *FakeClock) stop(t *fakeTimer) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, waiting := range c.timers {
+		if waiting == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.changed.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// fakeTimer is a timer of a FakeClock.
+type fakeTimer struct {
+	clock *FakeClock
+	when  time.Time
+	c     chan time.Time
+	f     func()
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool { return t.clock.stop(t) }
+
+// fire sends now on t's channel or calls its function.
+func (t *fakeTimer) fire(now time.Time) {
+	if t.f != nil {
+		t.f()
+		return
+	}
+	t.c <- now
+}
@@ -0,0 +1,84 @@
+package typecast
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	late := clock.NewTimer(2 * time.Second)
+	early := clock.NewTimer(time.Second)
+	var fired []time.Time
+	var chained Timer
+	clock.AfterFunc(1500*time.Millisecond, func() {
+		fired = append(fired, clock.Now())
+		chained = clock.AfterFunc(time.Second, func() { fired = append(fired, clock.Now()) })
+	})
+	stopped := clock.NewTimer(time.Second)
+	if !stopped.Stop() || stopped.Stop() {
+		t.Fatal("Stop must report whether it stopped a waiting timer")
+	}
+	if n := clock.Timers(); n != 3 {
+		t.Fatalf("Timers() = %d, want 3", n)
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case now := <-early.C():
+		if !now.Equal(start.Add(time.Second)) {
+			t.Fatalf("early fired at %v", now)
+		}
+	default:
+		t.Fatal("early timer did not fire")
+	}
+	select {
+	case <-late.C():
+		t.Fatal("late timer fired early")
+	default:
+	}
+
+	// Timers fire in deadline order, including one created while advancing.
+	clock.Advance(2 * time.Second)
+	if len(fired) != 2 || !fired[0].Equal(start.Add(1500*time.Millisecond)) || !fired[1].Equal(start.Add(2500*time.Millisecond)) {
+		t.Fatalf("fired = %v", fired)
+	}
+	if now := <-late.C(); !now.Equal(start.Add(2 * time.Second)) {
+		t.Fatalf("late fired at %v", now)
+	}
+	if chained.C() != nil || chained.Stop() {
+		t.Fatal("a fired AfterFunc timer has no channel and cannot be stopped")
+	}
+	if !clock.Now().Equal(start.Add(3 * time.Second)) {
+		t.Fatalf("Now() = %v", clock.Now())
+	}
+
+	// Timers that are already due fire at once.
+	<-clock.NewTimer(0).C()
+	done := make(chan struct{})
+	clock.AfterFunc(-time.Second, func() { close(done) })
+	<-done
+
+	go func() {
+		clock.NewTimer(time.Minute)
+		clock.NewTimer(time.Minute)
+	}()
+	clock.WaitForTimers(2)
+}
+
+func TestSystemClock(t *testing.T) {
+	if d := time.Since(SystemClock.Now()); d < 0 || d > time.Minute {
+		t.Fatalf("SystemClock.Now() is %v off", d)
+	}
+	<-SystemClock.NewTimer(time.Millisecond).C()
+	done := make(chan struct{})
+	timer := SystemClock.AfterFunc(time.Millisecond, func() { close(done) })
+	<-done
+	if timer.Stop() {
+		t.Fatal("Stop() = true for a fired timer")
+	}
+	if clockOrSystem(nil) != SystemClock {
+		t.Fatal("a nil clock must be the system clock")
+	}
+}
@@ -0,0 +1,351 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultFeedInterval is the time between polls of FeedConfig.FeedURL.
+const defaultFeedInterval = 15 * time.Minute
+
+// maxFeedBytes caps the size of a fetched feed or article page.
+const maxFeedBytes = 10 << 20
+
+const (
+	// feedStateName is the file in FeedConfig.OutputDir recording narrated
+	// and skipped items.
+	feedStateName = "episodes.json"
+	// feedName is the podcast feed written to FeedConfig.OutputDir.
+	feedName = "feed.xml"
+)
+
+// FeedConfig configures WatchFeed and PollFeed.
+type FeedConfig struct {
+	// FeedURL is the RSS or Atom feed to narrate (required)
+	FeedURL string
+	// OutputDir receives each episode's audio, feed.xml, and episodes.json,
+	// which records the items already handled (required)
+	OutputDir string
+	// AudioBaseURL is where OutputDir is published; episode enclosures link
+	// to AudioBaseURL + "/" + file (required)
+	AudioBaseURL string
+	// Podcast describes the generated podcast. Title, Link, and Description
+	// are required; Episodes is ignored.
+	Podcast PodcastFeed
+	// Profile holds the voice and synthesis settings (required). Podcast
+	// apps expect AudioFormatMP3.
+	Profile VoiceProfile
+	// MaxChunkChars caps the characters per request (optional, defaults to
+	// 2000)
+	MaxChunkChars int
+	// FetchArticles narrates the article each item links to, extracted with
+	// ExtractArticle, instead of the item's own content (optional)
+	FetchArticles bool
+	// Backfill is how many of the newest items are narrated on the first
+	// poll; older items are skipped (optional, defaults to 0, so only items
+	// published later are narrated)
+	Backfill int
+	// Interval is the time between polls of WatchFeed (optional, defaults
+	// to 15m)
+	Interval time.Duration
+	// OnEpisode is called after each episode is published (optional)
+	OnEpisode func(PodcastEpisode)
+	// OnError is called when the feed cannot be fetched or an item cannot
+	// be narrated; the item is retried on the next poll (optional)
+	OnError func(error)
+}
+
+// feedItem is an item of an RSS feed or an entry of an Atom feed.
+type feedItem struct {
+	guid      string
+	title     string
+	link      string
+	content   string // HTML or plain text
+	published time.Time
+}
+
+// feedState is the content of episodes.json.
+type feedState struct {
+	// Seen lists the GUIDs of current feed items that need no narration
+	Seen     []string      `json:"seen"`
+	Episodes []feedEpisode `json:"episodes"`
+}
+
+// feedEpisode records a narrated item.
+type feedEpisode struct {
+	Source      string    `json:"source"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	File        string    `json:"file"`
+	Size        int       `json:"size"`
+	Type        string    `json:"type"`
+	Duration    float64   `json:"duration"`
+	Published   time.Time `json:"published"`
+}
+
+// WatchFeed polls cfg.FeedURL with PollFeed until ctx is done or OutputDir
+// cannot be read or written, turning the feed into a narrated podcast.
+func (c *Client) WatchFeed(ctx context.Context, cfg FeedConfig) error {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultFeedInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.PollFeed(ctx, cfg); err != nil {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// PollFeed fetches cfg.FeedURL once and narrates its new items, oldest
+// first, with LongFormSynthesize. Each episode is written to OutputDir and
+// published in feed.xml as soon as it is done, so a crash loses at most the
+// episode in progress. Fetch and narration failures are reported to OnError
+// and retried on the next poll; PollFeed returns an error only for an
+// invalid configuration, a canceled ctx, or when OutputDir cannot be read or
+// written.
+func (c *Client) PollFeed(ctx context.Context, cfg FeedConfig) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		return err
+	}
+	state, first, err := loadFeedState(cfg.OutputDir)
+	if err != nil {
+		return err
+	}
+	data, err := c.fetchURL(ctx, cfg.FeedURL)
+	var items []feedItem
+	if err == nil {
+		items, err = parseFeed(data)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		cfg.report(fmt.Errorf("feed %s: %w", cfg.FeedURL, err))
+		return nil
+	}
+	if err := c.narrateFeedItems(ctx, cfg, state, items, first); err != nil {
+		return err
+	}
+	return saveFeed(cfg, state)
+}
+
+func (cfg *FeedConfig) validate() error {
+	if cfg.FeedURL == "" {
+		return newValidationError("feed_url", "feed_url is required")
+	}
+	if cfg.OutputDir == "" {
+		return newValidationError("output_dir", "output_dir is required")
+	}
+	if cfg.AudioBaseURL == "" {
+		return newValidationError("audio_base_url", "audio_base_url is required")
+	}
+	podcast := cfg.Podcast
+	podcast.Episodes = nil
+	if err := podcast.Validate(); err != nil {
+		return err
+	}
+	return cfg.Profile.Validate()
+}
+
+func (cfg *FeedConfig) report(err error) {
+	if cfg.OnError != nil {
+		cfg.OnError(err)
+	}
+}
+
+// narrateFeedItems narrates the items not yet seen. On the first poll,
+// all but the newest cfg.Backfill items are marked seen instead.
+func (c *Client) narrateFeedItems(ctx context.Context, cfg FeedConfig, state *feedState, items []feedItem, first bool) error {
+	sort.SliceStable(items, func(i, j int) bool { return items[i].published.After(items[j].published) })
+	seen := map[string]bool{}
+	for _, guid := range state.Seen {
+		seen[guid] = true
+	}
+	for _, episode := range state.Episodes {
+		seen[episode.Source] = true
+	}
+	current := make([]string, 0, len(items))
+	for i, item := range items {
+		current = append(current, item.guid)
+		if first && i >= cfg.Backfill {
+			seen[item.guid] = true
+		}
+	}
+	state.Seen = state.Seen[:0]
+	for _, guid := range current {
+		if seen[guid] {
+			state.Seen = append(state.Seen, guid)
+		}
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		if seen[item.guid] {
+			continue
+		}
+		seen[item.guid] = true
+		episode, audio, err := c.narrateFeedItem(ctx, cfg, item)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			cfg.report(fmt.Errorf("item %q: %w", item.title, err))
+			continue
+		}
+		if err := writeFileAtomic(filepath.Join(cfg.OutputDir, episode.File), audio); err != nil {
+			return err
+		}
+		state.Seen = append(state.Seen, item.guid)
+		state.Episodes = append(state.Episodes, episode)
+		if err := saveFeed(cfg, state); err != nil {
+			return err
+		}
+		if cfg.OnEpisode != nil {
+			cfg.OnEpisode(cfg.podcastEpisode(episode))
+		}
+	}
+	return nil
+}
+
+// narrateFeedItem synthesizes an item's title and text.
+func (c *Client) narrateFeedItem(ctx context.Context, cfg FeedConfig, item feedItem) (feedEpisode, []byte, error) {
+	var paragraphs []string
+	if cfg.FetchArticles && item.link != "" {
+		page, err := c.fetchURL(ctx, item.link)
+		if err != nil {
+			return feedEpisode{}, nil, err
+		}
+		article, err := ExtractArticle(bytes.NewReader(page))
+		if err != nil {
+			return feedEpisode{}, nil, err
+		}
+		paragraphs = article.Paragraphs
+	} else {
+		collector := articleCollector{title: item.title, skip: isBoilerplate}
+		collector.walk(parseHTML(item.content), false)
+		paragraphs = collector.blocks
+	}
+	title := item.title
+	if title == "" && len(paragraphs) > 0 {
+		title = chapterTitle(paragraphs[0])
+	}
+	if title == "" {
+		return feedEpisode{}, nil, newValidationError("text", "item has no text")
+	}
+	parts := paragraphs
+	if item.title != "" {
+		parts = append([]string{asSentence(item.title)}, parts...)
+	}
+	text := strings.Join(parts, "\n\n")
+	result, err := c.LongFormSynthesize(ctx, LongFormRequest{Profile: cfg.Profile, Text: text, MaxChunkChars: cfg.MaxChunkChars})
+	if err != nil {
+		return feedEpisode{}, nil, err
+	}
+	published := item.published
+	if published.IsZero() {
+		published = time.Now().UTC()
+	}
+	audio := NewPodcastEpisode(title, "", &result.TTSResponse)
+	episode := feedEpisode{
+		Source:    item.guid,
+		Title:     title,
+		File:      "episode-" + sha256Hex([]byte(item.guid))[:16] + "." + string(result.Format),
+		Size:      audio.AudioSize,
+		Type:      audio.AudioType,
+		Duration:  audio.Duration,
+		Published: published,
+	}
+	if len(paragraphs) > 0 {
+		episode.Description = paragraphs[0]
+	}
+	return episode, result.AudioData, nil
+}
+
+func (cfg *FeedConfig) podcastEpisode(e feedEpisode) PodcastEpisode {
+	return PodcastEpisode{
+		GUID:        e.Source,
+		Title:       e.Title,
+		Description: e.Description,
+		AudioURL:    strings.TrimRight(cfg.AudioBaseURL, "/") + "/" + e.File,
+		AudioSize:   e.Size,
+		AudioType:   e.Type,
+		Duration:    e.Duration,
+		Published:   e.Published,
+	}
+}
+
+// loadFeedState reads episodes.json. It reports whether the file did not
+// exist yet, i.e. this is the first poll.
+func loadFeedState(dir string) (*feedState, bool, error) {
+	b, err := os.ReadFile(filepath.Join(dir, feedStateName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &feedState{}, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var state feedState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, false, fmt.Errorf("invalid %s: %w", feedStateName, err)
+	}
+	return &state, false, nil
+}
+
+// saveFeed writes episodes.json and feed.xml, newest episode first.
+func saveFeed(cfg FeedConfig, state *feedState) error {
+	b, _ := json.MarshalIndent(state, "", "  ")
+	if err := writeFileAtomic(filepath.Join(cfg.OutputDir, feedStateName), b); err != nil {
+		return err
+	}
+	podcast := cfg.Podcast
+	podcast.Episodes = make([]PodcastEpisode, 0, len(state.Episodes))
+	for i := len(state.Episodes) - 1; i >= 0; i-- {
+		podcast.Episodes = append(podcast.Episodes, cfg.podcastEpisode(state.Episodes[i]))
+	}
+	sort.SliceStable(podcast.Episodes, func(i, j int) bool {
+		return podcast.Episodes[i].Published.After(podcast.Episodes[j].Published)
+	})
+	rss, _ := podcast.RSS() // validated by PollFeed, and every episode has a title and URL
+	return writeFileAtomic(filepath.Join(cfg.OutputDir, feedName), rss)
+}
+
+// fetchURL returns the body of a GET request to a URL outside the API.
+func (c *Client) fetchURL(ctx context.Context, url string) ([]byte, error) {
+	if c.refuses(ctx) {
+		return nil, ErrClientClosed
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setUserAgent(req.Header)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes))
+}
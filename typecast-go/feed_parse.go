@@ -0,0 +1,120 @@
+package typecast
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// feedDocument holds the items of an RSS 2.0, RSS 1.0, or Atom feed.
+type feedDocument struct {
+	XMLName  xml.Name
+	Items    []sourceRSSItem  `xml:"channel>item"`
+	RDFItems []sourceRSSItem  `xml:"item"`
+	Entries  []sourceAtomItem `xml:"entry"`
+}
+
+type sourceRSSItem struct {
+	GUID        string `xml:"guid"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Description string `xml:"description"`
+	Encoded     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+}
+
+type sourceAtomItem struct {
+	ID        string `xml:"id"`
+	Title     string `xml:"title"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Links     []struct {
+		Rel  string `xml:"rel,attr"`
+		Href string `xml:"href,attr"`
+	} `xml:"link"`
+}
+
+// parseFeed returns the items of an RSS or Atom feed. Items without a GUID
+// are identified by their link, or else their title, and dropped without
+// either.
+func parseFeed(data []byte) ([]feedItem, error) {
+	var doc feedDocument
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid feed: %w", err)
+	}
+	var items []feedItem
+	switch doc.XMLName.Local {
+	case "rss", "RDF":
+		for _, source := range append(doc.Items, doc.RDFItems...) {
+			content := source.Encoded
+			if strings.TrimSpace(content) == "" {
+				content = source.Description
+			}
+			date := source.PubDate
+			if date == "" {
+				date = source.Date
+			}
+			items = append(items, feedItem{
+				guid:      firstNonEmpty([]string{source.GUID, source.Link, source.Title}),
+				title:     strings.TrimSpace(source.Title),
+				link:      strings.TrimSpace(source.Link),
+				content:   content,
+				published: parseFeedTime(date),
+			})
+		}
+	case "feed":
+		for _, source := range doc.Entries {
+			item := feedItem{
+				title:     strings.TrimSpace(source.Title),
+				content:   source.Content,
+				published: parseFeedTime(firstNonEmpty([]string{source.Published, source.Updated})),
+			}
+			if strings.TrimSpace(item.content) == "" {
+				item.content = source.Summary
+			}
+			for _, link := range source.Links {
+				if link.Rel == "" || link.Rel == "alternate" {
+					item.link = strings.TrimSpace(link.Href)
+					break
+				}
+			}
+			item.guid = firstNonEmpty([]string{source.ID, item.link, item.title})
+			items = append(items, item)
+		}
+	default:
+		return nil, fmt.Errorf("invalid feed: unexpected root element <%s>", doc.XMLName.Local)
+	}
+	identified := items[:0]
+	for _, item := range items {
+		if item.guid != "" {
+			identified = append(identified, item)
+		}
+	}
+	return identified, nil
+}
+
+// feedTimeLayouts are the date formats found in RSS and Atom feeds.
+var feedTimeLayouts = []string{
+	time.RFC1123Z, time.RFC1123, time.RFC3339, time.RFC822Z, time.RFC822,
+	"Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", "2 Jan 2006 15:04:05 -0700",
+}
+
+// parseFeedTime parses a feed date, returning the zero time when it is
+// missing or in an unknown format.
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
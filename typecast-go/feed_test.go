@@ -0,0 +1,392 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testRSSFeed(items ...string) string {
+	return `<?xml version="1.0"?><rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/"><channel>` +
+		strings.Join(items, "") + `</channel></rss>`
+}
+
+func feedTestItem(guid, title, pubDate string) string {
+	return `<item><guid>` + guid + `</guid><title>` + title + `</title><link>https://example.com/` + guid + `</link>` +
+		`<pubDate>` + pubDate + `</pubDate><description>&lt;p&gt;Summary of ` + title + `.&lt;/p&gt;</description></item>`
+}
+
+// feedServer serves a mutable feed at /feed.xml, an article at /article,
+// and MP3 synthesis, recording the synthesized texts.
+type feedServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	feed   string
+	texts  []string
+	fail   string             // synthesis of texts containing it fails
+	cancel context.CancelFunc // called before a synthesis fails
+}
+
+func newFeedServer(t *testing.T, feed string) *feedServer {
+	s := &feedServer{feed: feed}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch r.URL.Path {
+		case "/feed.xml":
+			_, _ = w.Write([]byte(s.feed))
+		case "/blank":
+			_, _ = w.Write([]byte(`<nav><a href="/">Home</a></nav>`))
+		case "/article":
+			_, _ = w.Write([]byte(`<h1>Full story</h1><p>The full story, with every detail, as published.</p>`))
+		case "/v1/text-to-speech":
+			var body struct {
+				Text string `json:"text"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if s.fail != "" && strings.Contains(body.Text, s.fail) {
+				if s.cancel != nil {
+					s.cancel()
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			s.texts = append(s.texts, body.Text)
+			w.Header().Set("Content-Type", "audio/mpeg")
+			w.Header().Set("X-Audio-Duration", "1")
+			_, _ = w.Write(makeTestMP3(2))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *feedServer) set(feed, fail string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.feed, s.fail, s.texts = feed, fail, nil
+}
+
+func (s *feedServer) synthesized() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.texts...)
+}
+
+func testFeedConfig(srv *feedServer, dir string) FeedConfig {
+	return FeedConfig{
+		FeedURL:      srv.URL + "/feed.xml",
+		OutputDir:    dir,
+		AudioBaseURL: "https://cdn.example.com/news/",
+		Podcast:      PodcastFeed{Title: "News, Read Aloud", Link: "https://example.com", Description: "Daily news"},
+		Profile:      VoiceProfile{VoiceID: "tc_narrator", AudioFormat: AudioFormatMP3},
+	}
+}
+
+func readFeedState(t *testing.T, dir string) feedState {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(dir, feedStateName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state feedState
+	if err := json.Unmarshal(b, &state); err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+func TestPollFeed(t *testing.T) {
+	first := feedTestItem("a", "First", "Mon, 02 Jan 2006 15:04:05 +0000")
+	second := feedTestItem("b", "Second", "Tue, 03 Jan 2006 15:04:05 +0000")
+	third := feedTestItem("c", "Third", "Wed, 04 Jan 2006 15:04:05 +0000")
+	srv := newFeedServer(t, testRSSFeed(second, first))
+	client := newTestClient(srv.Server, "k")
+	dir := t.TempDir()
+	cfg := testFeedConfig(srv, dir)
+	cfg.Backfill = 1
+	var published []PodcastEpisode
+	cfg.OnEpisode = func(e PodcastEpisode) { published = append(published, e) }
+
+	if err := client.PollFeed(context.Background(), cfg); err != nil {
+		t.Fatalf("PollFeed() error = %v", err)
+	}
+	if got := srv.synthesized(); strings.Join(got, "|") != "Second.\n\nSummary of Second." {
+		t.Fatalf("first poll synthesized %q", got)
+	}
+	state := readFeedState(t, dir)
+	if strings.Join(state.Seen, ",") != "a,b" || len(state.Episodes) != 1 {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+
+	srv.set(testRSSFeed(third, second, first), "")
+	if err := client.PollFeed(context.Background(), cfg); err != nil {
+		t.Fatalf("PollFeed() error = %v", err)
+	}
+	if got := srv.synthesized(); strings.Join(got, "|") != "Third.\n\nSummary of Third." {
+		t.Fatalf("second poll synthesized %q", got)
+	}
+	if len(published) != 2 || published[1].Title != "Third" || published[1].GUID != "c" ||
+		published[1].AudioURL != "https://cdn.example.com/news/"+readFeedState(t, dir).Episodes[1].File ||
+		published[1].AudioType != "audio/mpeg" || published[1].Description != "Summary of Third." {
+		t.Fatalf("unexpected episodes: %+v", published)
+	}
+	if _, err := os.Stat(filepath.Join(dir, readFeedState(t, dir).Episodes[1].File)); err != nil {
+		t.Fatalf("episode audio not written: %v", err)
+	}
+	rss, err := os.ReadFile(filepath.Join(dir, feedName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i, j := strings.Index(string(rss), "<title>Third</title>"), strings.Index(string(rss), "<title>Second</title>"); i < 0 || j < i {
+		t.Fatalf("feed.xml does not list the newest episode first:\n%s", rss)
+	}
+
+	srv.set(testRSSFeed(third), "")
+	if err := client.PollFeed(context.Background(), cfg); err != nil {
+		t.Fatalf("PollFeed() error = %v", err)
+	}
+	if got := srv.synthesized(); len(got) != 0 {
+		t.Fatalf("third poll synthesized %q", got)
+	}
+	if state := readFeedState(t, dir); strings.Join(state.Seen, ",") != "c" || len(state.Episodes) != 2 {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+}
+
+func TestPollFeed_AtomAndArticles(t *testing.T) {
+	atom := `<feed xmlns="http://www.w3.org/2005/Atom">
+<entry><id>urn:1</id><title>Full</title><link rel="self" href="/self"/><link href="%s/article"/>
+<updated>2024-05-01T10:00:00Z</updated><summary>Short.</summary></entry>
+<entry><title>Untitled link</title><link rel="alternate" href="%s/missing"/></entry>
+<entry><title>Blank</title><link href="%s/blank"/></entry>
+</feed>`
+	srv := newFeedServer(t, "")
+	srv.set(strings.ReplaceAll(atom, "%s", srv.URL), "")
+	client := newTestClient(srv.Server, "k")
+	cfg := testFeedConfig(srv, t.TempDir())
+	cfg.Backfill = 3
+	cfg.FetchArticles = true
+	var errs []string
+	cfg.OnError = func(err error) { errs = append(errs, err.Error()) }
+
+	if err := client.PollFeed(context.Background(), cfg); err != nil {
+		t.Fatalf("PollFeed() error = %v", err)
+	}
+	if got := srv.synthesized(); strings.Join(got, "|") != "Full.\n\nThe full story, with every detail, as published." {
+		t.Fatalf("synthesized %q", got)
+	}
+	if len(errs) != 2 || !strings.Contains(errs[0], `item "Blank": `+ErrNoArticle.Error()) ||
+		!strings.Contains(errs[1], `item "Untitled link": GET `) {
+		t.Fatalf("errors = %q", errs)
+	}
+}
+
+func TestPollFeed_ItemFallbacks(t *testing.T) {
+	rdf := `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/"
+xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<item><link>https://example.com/x</link><dc:date>2024-05-01T10:00:00Z</dc:date>
+<content:encoded><![CDATA[<p>A story without a title, told in full.</p>]]></content:encoded></item>
+<item><guid>empty</guid><description>  </description></item>
+<item></item>
+</rdf:RDF>`
+	srv := newFeedServer(t, rdf)
+	client := newTestClient(srv.Server, "k")
+	dir := t.TempDir()
+	cfg := testFeedConfig(srv, dir)
+	cfg.Backfill = 5
+	var errs []error
+	cfg.OnError = func(err error) { errs = append(errs, err) }
+
+	if err := client.PollFeed(context.Background(), cfg); err != nil {
+		t.Fatalf("PollFeed() error = %v", err)
+	}
+	if got := srv.synthesized(); strings.Join(got, "|") != "A story without a title, told in full." {
+		t.Fatalf("synthesized %q", got)
+	}
+	var validationErr *ValidationError
+	if len(errs) != 1 || !errors.As(errs[0], &validationErr) {
+		t.Fatalf("errors = %v", errs)
+	}
+	state := readFeedState(t, dir)
+	if len(state.Episodes) != 1 || state.Episodes[0].Title != "A story without a title, told in full." ||
+		state.Episodes[0].Source != "https://example.com/x" || state.Episodes[0].Published.Year() != 2024 {
+		t.Fatalf("unexpected episodes: %+v", state.Episodes)
+	}
+}
+
+func TestPollFeed_Errors(t *testing.T) {
+	srv := newFeedServer(t, testRSSFeed(feedTestItem("a", "First", "")))
+	client := newTestClient(srv.Server, "k")
+	ctx := context.Background()
+
+	var validationErr *ValidationError
+	for _, mutate := range []func(*FeedConfig){
+		func(c *FeedConfig) { c.FeedURL = "" },
+		func(c *FeedConfig) { c.OutputDir = "" },
+		func(c *FeedConfig) { c.AudioBaseURL = "" },
+		func(c *FeedConfig) { c.Podcast.Title = "" },
+		func(c *FeedConfig) { c.Profile.VoiceID = "" },
+	} {
+		cfg := testFeedConfig(srv, t.TempDir())
+		mutate(&cfg)
+		if err := client.PollFeed(ctx, cfg); !errors.As(err, &validationErr) {
+			t.Errorf("PollFeed() error = %v, want *ValidationError", err)
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.PollFeed(ctx, testFeedConfig(srv, filepath.Join(file, "out"))); err == nil {
+		t.Error("expected an error creating the output directory")
+	}
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, feedStateName), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.PollFeed(ctx, testFeedConfig(srv, dir)); err == nil {
+		t.Error("expected an error reading episodes.json")
+	}
+	dir = t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, feedStateName), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.PollFeed(ctx, testFeedConfig(srv, dir)); err == nil || !strings.Contains(err.Error(), "invalid episodes.json") {
+		t.Errorf("PollFeed() error = %v, want invalid episodes.json", err)
+	}
+
+	for feed, want := range map[string]string{
+		"<rss><channel>":             "invalid feed",
+		"<html><body></body></html>": "unexpected root element <html>",
+	} {
+		srv.set(feed, "")
+		dir := t.TempDir()
+		cfg := testFeedConfig(srv, dir)
+		var errs []error
+		cfg.OnError = func(err error) { errs = append(errs, err) }
+		if err := client.PollFeed(ctx, cfg); err != nil {
+			t.Fatalf("PollFeed() error = %v", err)
+		}
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), want) {
+			t.Errorf("errors = %v, want %q", errs, want)
+		}
+		if _, err := os.Stat(filepath.Join(dir, feedStateName)); !errors.Is(err, os.ErrNotExist) {
+			t.Error("a failed first poll must not record the feed as seen")
+		}
+	}
+
+	// A failed item is retried on the next poll.
+	srv.set(testRSSFeed(feedTestItem("a", "First", "")), "First")
+	dir = t.TempDir()
+	cfg := testFeedConfig(srv, dir)
+	cfg.Backfill = 1
+	if err := client.PollFeed(ctx, cfg); err != nil {
+		t.Fatalf("PollFeed() error = %v", err)
+	}
+	srv.set(testRSSFeed(feedTestItem("a", "First", "")), "")
+	if err := client.PollFeed(ctx, cfg); err != nil || len(srv.synthesized()) != 1 {
+		t.Fatalf("retry: error = %v, synthesized %q", err, srv.synthesized())
+	}
+
+	for _, name := range []string{"episode-" + sha256Hex([]byte("b"))[:16] + ".mp3.tmp", feedStateName + ".tmp"} {
+		srv.set(testRSSFeed(feedTestItem("b", "Second", "")), "")
+		dir := t.TempDir()
+		cfg := testFeedConfig(srv, dir)
+		cfg.Backfill = 1
+		if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := client.PollFeed(ctx, cfg); err == nil {
+			t.Errorf("expected an error writing %s", name)
+		}
+	}
+	dir = t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, feedName+".tmp"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.PollFeed(ctx, testFeedConfig(srv, dir)); err == nil {
+		t.Error("expected an error writing feed.xml")
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := client.PollFeed(canceled, testFeedConfig(srv, t.TempDir())); !errors.Is(err, context.Canceled) {
+		t.Errorf("PollFeed() error = %v, want context.Canceled", err)
+	}
+	cancelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	srv.set(testRSSFeed(feedTestItem("b", "Second", "")), "Second")
+	srv.mu.Lock()
+	srv.cancel = cancel
+	srv.mu.Unlock()
+	cfg = testFeedConfig(srv, t.TempDir())
+	cfg.Backfill = 1
+	cfg.OnError = func(err error) { t.Errorf("unexpected report: %v", err) }
+	if err := client.PollFeed(cancelCtx, cfg); !errors.Is(err, context.Canceled) {
+		t.Errorf("PollFeed() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestWatchFeed(t *testing.T) {
+	srv := newFeedServer(t, testRSSFeed(feedTestItem("a", "First", "")))
+	client := newTestClient(srv.Server, "k")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := testFeedConfig(srv, t.TempDir())
+	cfg.Interval = time.Millisecond
+	polls := 0
+	cfg.OnError = func(error) {
+		if polls++; polls == 2 {
+			cancel()
+		}
+	}
+	srv.set("not a feed", "")
+	if err := client.WatchFeed(ctx, cfg); !errors.Is(err, context.Canceled) {
+		t.Errorf("WatchFeed() error = %v, want context.Canceled", err)
+	}
+	cfg.FeedURL, cfg.Interval = "", 0
+	var validationErr *ValidationError
+	if err := client.WatchFeed(context.Background(), cfg); !errors.As(err, &validationErr) {
+		t.Errorf("WatchFeed() error = %v, want *ValidationError", err)
+	}
+}
+
+func TestFetchURL(t *testing.T) {
+	srv := newFeedServer(t, "")
+	client := newTestClient(srv.Server, "k")
+	if _, err := client.fetchURL(context.Background(), "http://\x7f"); err == nil {
+		t.Error("expected an error for an invalid URL")
+	}
+	if _, err := client.fetchURL(context.Background(), "http://127.0.0.1:1/feed"); err == nil {
+		t.Error("expected a transport error")
+	}
+	client.Close()
+	if _, err := client.fetchURL(context.Background(), srv.URL+"/feed.xml"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("fetchURL() error = %v, want ErrClientClosed", err)
+	}
+}
+
+func TestParseFeedTime(t *testing.T) {
+	for value, want := range map[string]string{
+		"Mon, 02 Jan 2006 15:04:05 -0700": "2006-01-02T22:04:05Z",
+		"Mon, 2 Jan 2006 15:04:05 GMT":    "2006-01-02T15:04:05Z",
+		" 2024-05-01T10:00:00+09:00 ":     "2024-05-01T01:00:00Z",
+		"yesterday":                       "0001-01-01T00:00:00Z",
+	} {
+		if got := parseFeedTime(value).UTC().Format(time.RFC3339); got != want {
+			t.Errorf("parseFeedTime(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package typecast
+
+import "encoding/binary"
+
+// fmp4Box returns an ISO BMFF box of type typ whose payload is the
+// concatenation of parts.
+func fmp4Box(typ string, parts ...[]byte) []byte {
+	size := 8
+	for _, part := range parts {
+		size += len(part)
+	}
+	box := make([]byte, 8, size)
+	binary.BigEndian.PutUint32(box, uint32(size))
+	copy(box[4:], typ)
+	for _, part := range parts {
+		box = append(box, part...)
+	}
+	return box
+}
+
+// fmp4FullBox returns a box with a version and flags header.
+func fmp4FullBox(typ string, version byte, flags uint32, parts ...[]byte) []byte {
+	header := []byte{version, byte(flags >> 16), byte(flags >> 8), byte(flags)}
+	return fmp4Box(typ, append([][]byte{header}, parts...)...)
+}
+
+// be returns the big-endian encoding of each value, using as many bytes as
+// its type: uint16, uint32, or uint64.
+func be(values ...interface{}) []byte {
+	var b []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case uint16:
+			b = append(b, byte(v>>8), byte(v))
+		case uint32:
+			b = append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+		case uint64:
+			b = append(b, be(uint32(v>>32), uint32(v))...)
+		}
+	}
+	return b
+}
+
+// fmp4Matrix is the identity transformation matrix of mvhd and tkhd.
+var fmp4Matrix = be(uint32(0x00010000), uint32(0), uint32(0), uint32(0), uint32(0x00010000), uint32(0), uint32(0), uint32(0), uint32(0x40000000))
+
+// fmp4InitSegment returns the initialization segment of a fragmented MP4
+// with a single MP3 audio track described by frame.
+func fmp4InitSegment(frame mp3Frame) []byte {
+	objectType := uint8(0x6B) // MPEG-1 audio
+	if frame.samples != 1152 {
+		objectType = 0x69 // MPEG-2 audio, including 2.5
+	}
+	decoderConfig := append([]byte{0x04, 13, objectType, 0x15, 0, 0, 0}, be(uint32(frame.bitrate), uint32(frame.bitrate))...)
+	esDescriptor := append(append([]byte{0x03, byte(3 + len(decoderConfig) + 3), 0, 1, 0}, decoderConfig...), 0x06, 1, 0x02)
+	sampleEntry := fmp4Box("mp4a",
+		make([]byte, 6), be(uint16(1)), make([]byte, 8),
+		be(uint16(frame.channels), uint16(16), uint16(0), uint16(0), uint32(frame.sampleRate)<<16),
+		fmp4FullBox("esds", 0, 0, esDescriptor),
+	)
+	stbl := fmp4Box("stbl",
+		fmp4FullBox("stsd", 0, 0, be(uint32(1)), sampleEntry),
+		fmp4FullBox("stts", 0, 0, be(uint32(0))),
+		fmp4FullBox("stsc", 0, 0, be(uint32(0))),
+		fmp4FullBox("stsz", 0, 0, be(uint32(0), uint32(0))),
+		fmp4FullBox("stco", 0, 0, be(uint32(0))),
+	)
+	minf := fmp4Box("minf",
+		fmp4FullBox("smhd", 0, 0, be(uint16(0), uint16(0))),
+		fmp4Box("dinf", fmp4FullBox("dref", 0, 0, be(uint32(1)), fmp4FullBox("url ", 0, 1))),
+		stbl,
+	)
+	mdia := fmp4Box("mdia",
+		fmp4FullBox("mdhd", 0, 0, be(uint32(0), uint32(0), uint32(frame.sampleRate), uint32(0), uint16(0x55C4), uint16(0))),
+		fmp4FullBox("hdlr", 0, 0, be(uint32(0)), []byte("soun"), make([]byte, 12), []byte("SoundHandler\x00")),
+		minf,
+	)
+	trak := fmp4Box("trak",
+		fmp4FullBox("tkhd", 0, 3, be(uint32(0), uint32(0), uint32(1), uint32(0), uint32(0)), make([]byte, 8),
+			be(uint16(0), uint16(0), uint16(0x0100), uint16(0)), fmp4Matrix, be(uint32(0), uint32(0))),
+		mdia,
+	)
+	moov := fmp4Box("moov",
+		fmp4FullBox("mvhd", 0, 0, be(uint32(0), uint32(0), uint32(1000), uint32(0), uint32(0x00010000), uint16(0x0100)),
+			make([]byte, 10), fmp4Matrix, make([]byte, 24), be(uint32(2))),
+		trak,
+		fmp4Box("mvex", fmp4FullBox("trex", 0, 0, be(uint32(1), uint32(1), uint32(0), uint32(0), uint32(0)))),
+	)
+	ftyp := fmp4Box("ftyp", []byte("iso6"), be(uint32(0)), []byte("iso6dashmp41"))
+	return append(ftyp, moov...)
+}
+
+// fmp4MediaSegment returns media segment number sequence (starting at 1)
+// holding frames, whose offsets are relative to audio, starting at
+// decodeTime in sample-rate units.
+func fmp4MediaSegment(sequence int, decodeTime uint64, audio []byte, frames []mp3Frame) []byte {
+	const trunFlags = 0x000001 | 0x000100 | 0x000200 // data offset, sample durations and sizes
+	samples := make([]byte, 0, 8*len(frames))
+	for _, frame := range frames {
+		samples = append(samples, be(uint32(frame.samples), uint32(frame.length))...)
+	}
+	moof := func(dataOffset uint32) []byte {
+		return fmp4Box("moof",
+			fmp4FullBox("mfhd", 0, 0, be(uint32(sequence))),
+			fmp4Box("traf",
+				fmp4FullBox("tfhd", 0, 0x020000, be(uint32(1))), // default-base-is-moof
+				fmp4FullBox("tfdt", 1, 0, be(decodeTime)),
+				fmp4FullBox("trun", 0, trunFlags, be(uint32(len(frames)), dataOffset), samples),
+			),
+		)
+	}
+	// The data offset points past the moof and the mdat header.
+	segment := moof(uint32(len(moof(0)) + 8))
+	return append(segment, fmp4Box("mdat", audio)...)
+}
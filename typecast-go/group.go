@@ -0,0 +1,121 @@
+package typecast
+
+import (
+	"context"
+	"fmt"
+)
+
+// TextToSpeechFunc returns a function that synthesizes request with ctx and
+// stores the response in *out, for errgroup.Group.Go or any goroutine that
+// runs a func() error:
+//
+//	g, ctx := errgroup.WithContext(ctx)
+//	responses := make([]*typecast.TTSResponse, len(requests))
+//	for i := range requests {
+//		g.Go(client.TextToSpeechFunc(ctx, requests[i], &responses[i]))
+//	}
+//	err := g.Wait()
+//
+// request, with its Output, Seed, and Prompt, is copied when
+// TextToSpeechFunc is called, so the caller may reuse it for the next
+// call. Each function writes only to its own out.
+func (c *Client) TextToSpeechFunc(ctx context.Context, request *TTSRequest, out **TTSResponse) func() error {
+	copied := cloneTTSRequest(request)
+	return func() error {
+		resp, err := c.TextToSpeech(ctx, copied)
+		if err != nil {
+			return err
+		}
+		*out = resp
+		return nil
+	}
+}
+
+// cloneTTSRequest returns a deep copy of request, or nil, so a synthesis
+// that runs later is not changed by the caller reusing request, its Output,
+// its Seed, or a *Prompt, *PresetPrompt, or *SmartPrompt in its Prompt.
+func cloneTTSRequest(request *TTSRequest) *TTSRequest {
+	if request == nil {
+		return nil
+	}
+	r := *request
+	switch prompt := r.Prompt.(type) {
+	case *Prompt:
+		if prompt != nil {
+			p := *prompt
+			p.EmotionIntensity = cloneFloat(p.EmotionIntensity)
+			r.Prompt = &p
+		}
+	case *PresetPrompt:
+		if prompt != nil {
+			p := *prompt
+			p.EmotionIntensity = cloneFloat(p.EmotionIntensity)
+			r.Prompt = &p
+		}
+	case *SmartPrompt:
+		if prompt != nil {
+			p := *prompt
+			r.Prompt = &p
+		}
+	}
+	if r.Output != nil {
+		o := *r.Output
+		o.Volume = cloneInt(o.Volume)
+		o.TargetLUFS = cloneFloat(o.TargetLUFS)
+		o.AudioPitch = cloneInt(o.AudioPitch)
+		o.AudioTempo = cloneFloat(o.AudioTempo)
+		o.SampleRate = cloneInt(o.SampleRate)
+		o.Channels = cloneInt(o.Channels)
+		o.Bitrate = cloneInt(o.Bitrate)
+		r.Output = &o
+	}
+	r.Seed = cloneInt(r.Seed)
+	return &r
+}
+
+func cloneInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+func cloneFloat(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+// Semaphore bounds how many functions run at once. Share one among groups,
+// goroutines, or clients that draw from the same budget; waiters are served
+// by WithPriority order. It is safe for concurrent use.
+type Semaphore struct {
+	slots *slotScheduler
+}
+
+// NewSemaphore returns a semaphore allowing n functions at once. n below 1
+// is treated as 1.
+func NewSemaphore(n int) *Semaphore {
+	if n < 1 {
+		n = 1
+	}
+	return &Semaphore{slots: newSlotScheduler(n)}
+}
+
+// Limit returns a function that runs fn while holding one of s's slots.
+// It waits for a slot until ctx is done, and then returns ctx's error
+// without running fn:
+//
+//	g.Go(sem.Limit(ctx, client.TextToSpeechFunc(ctx, request, &responses[i])))
+func (s *Semaphore) Limit(ctx context.Context, fn func() error) func() error {
+	return func() error {
+		if err := s.slots.acquire(ctx); err != nil {
+			return fmt.Errorf("waiting for a semaphore slot: %w", err)
+		}
+		defer s.slots.release()
+		return fn()
+	}
+}
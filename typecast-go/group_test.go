@@ -0,0 +1,203 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTextToSpeechFunc(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Text == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte("RIFF" + body.Text))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+
+	// The request is copied, so reusing it does not change earlier calls.
+	request := &TTSRequest{VoiceID: "v", Model: ModelSSFMV30}
+	responses := make([]*TTSResponse, 3)
+	var fns []func() error
+	for i, text := range []string{"one", "two", "three"} {
+		request.Text = text
+		fns = append(fns, c.TextToSpeechFunc(context.Background(), request, &responses[i]))
+	}
+	var wg sync.WaitGroup
+	for _, fn := range fns {
+		wg.Add(1)
+		go func(fn func() error) {
+			defer wg.Done()
+			if err := fn(); err != nil {
+				t.Error(err)
+			}
+		}(fn)
+	}
+	wg.Wait()
+	for i, want := range []string{"RIFFone", "RIFFtwo", "RIFFthree"} {
+		if responses[i] == nil || string(responses[i].AudioData) != want {
+			t.Fatalf("response %d = %+v", i, responses[i])
+		}
+	}
+
+	var out *TTSResponse
+	if err := c.TextToSpeechFunc(context.Background(), &TTSRequest{VoiceID: "v", Text: "bad", Model: ModelSSFMV30}, &out)(); err == nil || out != nil {
+		t.Fatalf("bad request: %v, %+v", err, out)
+	}
+	if err := c.TextToSpeechFunc(context.Background(), nil, &out)(); err == nil {
+		t.Fatal("expected an error for a nil request")
+	}
+}
+
+func TestTextToSpeechFunc_CopiesNestedFields(t *testing.T) {
+	bodies := make(chan TTSRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies <- body
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte("RIFF"))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+
+	tempo, seed := 1.5, 7
+	request := &TTSRequest{VoiceID: "v", Text: "hi", Model: ModelSSFMV30, Output: &Output{AudioTempo: &tempo}, Seed: &seed}
+	var out *TTSResponse
+	fn := c.TextToSpeechFunc(context.Background(), request, &out)
+	request.Output.AudioTempo = nil
+	request.Output.AudioFormat = AudioFormatMP3
+	tempo, seed = 2, 8
+	if err := fn(); err != nil {
+		t.Fatal(err)
+	}
+	body := <-bodies
+	if body.Output == nil || body.Output.AudioTempo == nil || *body.Output.AudioTempo != 1.5 || body.Output.AudioFormat != "" || *body.Seed != 7 {
+		t.Fatalf("expected the request as it was, got %+v", body)
+	}
+}
+
+func TestCloneTTSRequest(t *testing.T) {
+	intensity := 1.5
+	for _, prompt := range []interface{}{
+		&Prompt{EmotionPreset: EmotionHappy, EmotionIntensity: &intensity},
+		&PresetPrompt{EmotionType: "preset", EmotionIntensity: &intensity},
+		&SmartPrompt{EmotionType: "smart", PreviousText: "a"},
+		(*Prompt)(nil),
+		Prompt{EmotionPreset: EmotionSad},
+	} {
+		request := &TTSRequest{Prompt: prompt}
+		copied := cloneTTSRequest(request)
+		data, _ := json.Marshal(prompt)
+		got, _ := json.Marshal(copied.Prompt)
+		if string(got) != string(data) {
+			t.Fatalf("clone of %T = %s, want %s", prompt, got, data)
+		}
+	}
+	request := &TTSRequest{Prompt: &Prompt{EmotionIntensity: &intensity}}
+	copied := cloneTTSRequest(request)
+	intensity = 2
+	if *copied.Prompt.(*Prompt).EmotionIntensity != 1.5 || copied.Seed != nil || copied.Output != nil {
+		t.Fatalf("expected a deep copy, got %+v", copied)
+	}
+	if cloneTTSRequest(nil) != nil {
+		t.Fatal("expected nil")
+	}
+}
+
+func TestSemaphore(t *testing.T) {
+	sem := NewSemaphore(2)
+	var running, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = sem.Limit(context.Background(), func() error {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})()
+		}()
+	}
+	wg.Wait()
+	if peak != 2 {
+		t.Fatalf("peak concurrency %d, want 2", peak)
+	}
+
+	// A function waiting for a slot gives up with its context.
+	one := NewSemaphore(0)
+	holding, release := make(chan struct{}), make(chan struct{})
+	go func() {
+		_ = one.Limit(context.Background(), func() error { close(holding); <-release; return nil })()
+	}()
+	<-holding
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	ran := false
+	err := one.Limit(ctx, func() error { ran = true; return nil })()
+	close(release)
+	if !errors.Is(err, context.DeadlineExceeded) || ran || !strings.Contains(err.Error(), "semaphore slot") {
+		t.Fatalf("Limit = %v, ran %v", err, ran)
+	}
+}
+
+// TestClientConcurrentUse exercises a Client's shared state from many
+// goroutines; run it with -race.
+func TestClientConcurrentUse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte("RIFF"))
+	}))
+	defer srv.Close()
+	lexicon, _ := NewPronunciationLexicon(map[string]string{"SQL": "sequel"})
+	c := NewClient(&ClientConfig{
+		APIKey:                "k",
+		BaseURL:               srv.URL,
+		MaxConcurrentRequests: 3,
+		RateLimiter:           NewTokenBucketLimiter(1000, 10),
+		QuotaGuard:            NewQuotaGuard(QuotaConfig{HardLimit: 1 << 20}),
+		Pronunciations:        lexicon,
+		Acronyms:              &AcronymRules{Default: AcronymSpell},
+	})
+	defer c.Close()
+	request := &TTSRequest{VoiceID: "v", Text: "SQL over HTTP", Model: ModelSSFMV30}
+	sem := NewSemaphore(4)
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var resp *TTSResponse
+			ctx := WithPriority(context.Background(), Priority(i%3))
+			if err := sem.Limit(ctx, c.TextToSpeechFunc(ctx, request, &resp))(); err != nil {
+				t.Error(err)
+			}
+			_ = c.ClockSkew()
+		}(i)
+	}
+	wg.Wait()
+	if request.Text != "SQL over HTTP" {
+		t.Fatalf("request was modified: %q", request.Text)
+	}
+}
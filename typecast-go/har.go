@@ -0,0 +1,378 @@
+package typecast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultHARBodySize is the number of bytes of each body a HARRecorder
+// keeps when MaxBodySize is not set.
+const defaultHARBodySize = 64 * 1024
+
+// HARRecorder is an http.RoundTripper that records the requests it sends
+// and their responses as an HTTP Archive (HAR 1.2), so a failing run can be
+// attached to a bug report and its requests replayed with any HAR tool. Use
+// it as the Transport of ClientConfig.HTTPClient:
+//
+//	recorder := &typecast.HARRecorder{}
+//	client := typecast.NewClient(&typecast.ClientConfig{
+//		HTTPClient: &http.Client{Transport: recorder, Timeout: typecast.DefaultTimeout},
+//	})
+//	defer recorder.WriteFile("typecast.har")
+//
+// The API key header is redacted, and so is the text of JSON bodies unless
+// IncludeText is set. Audio bodies, such as synthesized speech and cloning
+// samples, are summarized by their size, and other bodies are cut at
+// MaxBodySize. The zero value is ready to use and safe for
+// concurrent use.
+type HARRecorder struct {
+	// Next sends the requests (optional, defaults to
+	// http.DefaultTransport)
+	Next http.RoundTripper
+	// MaxBodySize is the number of bytes of each body to keep (optional,
+	// defaults to 64 KiB)
+	MaxBodySize int
+	// Clock times the entries (optional, defaults to SystemClock)
+	Clock Clock
+	// IncludeText records the text of JSON bodies, such as the input of a
+	// synthesis, verbatim (optional, defaults to false, passing it through
+	// RedactText)
+	IncludeText bool
+	// RedactText renders the text of JSON bodies (optional, defaults to
+	// RedactHash)
+	RedactText Redactor
+
+	mu      sync.Mutex
+	entries []*harEntry
+}
+
+// RoundTrip sends req with Next and records it.
+func (r *HARRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	clock := clockOrSystem(r.Clock)
+	entry := &harEntry{
+		Request: r.harRequest(req),
+		Cache:   struct{}{},
+	}
+	started := clock.Now()
+	entry.StartedDateTime = started.Format(time.RFC3339Nano)
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	headers := clock.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry.Timings.Wait = milliseconds(headers.Sub(started))
+	entry.Time = entry.Timings.Wait
+	if err != nil {
+		entry.Response = harResponse{Headers: []harNameValue{}, Cookies: []harNameValue{}, Content: harContent{MimeType: "x-unknown"}, HeadersSize: -1, BodySize: -1, Comment: err.Error()}
+		return nil, err
+	}
+	mimeType := resp.Header.Get("Content-Type")
+	entry.Response = harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  strings.TrimSpace(strings.TrimPrefix(resp.Status, fmt.Sprint(resp.StatusCode))),
+		HTTPVersion: resp.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(resp.Header),
+		Content:     harContent{MimeType: mimeType, Comment: "body not read"},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	resp.Body = &harBody{ReadCloser: resp.Body, recorder: r, entry: entry, clock: clock, started: headers, mimeType: mimeType}
+	return resp, nil
+}
+
+// JSON returns the recorded entries as an indented HAR log.
+func (r *HARRecorder) JSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := r.entries
+	if entries == nil {
+		entries = []*harEntry{}
+	}
+	var log harLog
+	log.Log.Version = "1.2"
+	log.Log.Creator = harNameVersion{Name: "typecast-go", Version: SDKVersion}
+	log.Log.Entries = entries
+	return json.MarshalIndent(log, "", "  ")
+}
+
+// WriteFile writes the HAR log to path.
+func (r *HARRecorder) WriteFile(path string) error {
+	data, err := r.JSON()
+	if err == nil {
+		err = writeFileAtomic(path, data)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write HAR file: %w", err)
+	}
+	return nil
+}
+
+// maxBodySize returns the number of bytes of each body to keep.
+func (r *HARRecorder) maxBodySize() int {
+	if r.MaxBodySize > 0 {
+		return r.MaxBodySize
+	}
+	return defaultHARBodySize
+}
+
+// harRequest records req, reading its body again through GetBody.
+func (r *HARRecorder) harRequest(req *http.Request) harRequest {
+	out := harRequest{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: req.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(req.Header),
+		QueryString: []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    req.ContentLength,
+	}
+	query := req.URL.Query()
+	for _, name := range sortedKeys(query) {
+		for _, value := range query[name] {
+			out.QueryString = append(out.QueryString, harNameValue{Name: name, Value: value})
+		}
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return out
+	}
+	mimeType := req.Header.Get("Content-Type")
+	out.PostData = &harPostData{MimeType: mimeType}
+	if req.GetBody == nil {
+		out.PostData.Comment = "streamed body not recorded"
+		return out
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		out.PostData.Comment = "body not recorded: " + err.Error()
+		return out
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if mediaType, params, _ := mime.ParseMediaType(mimeType); mediaType == "multipart/form-data" {
+		out.PostData.Params = harFormParams(data, params["boundary"], r.maxBodySize())
+		return out
+	}
+	data = r.redact(mimeType, data)
+	out.PostData.Text, out.PostData.Comment = harText(mimeType, data, int64(len(data)), r.maxBodySize())
+	return out
+}
+
+// redact passes the text of a JSON body through RedactText, unless
+// IncludeText is set.
+func (r *HARRecorder) redact(mimeType string, data []byte) []byte {
+	if mediaType, _, _ := mime.ParseMediaType(mimeType); r.IncludeText || mediaType != "application/json" {
+		return data
+	}
+	return redactJSONText(data, redactorOrDefault(r.RedactText))
+}
+
+// harBody records a response body as it is read.
+type harBody struct {
+	io.ReadCloser
+	recorder *HARRecorder
+	entry    *harEntry
+	clock    Clock
+	started  time.Time
+	mimeType string
+	data     []byte
+	size     int64
+	done     bool
+}
+
+func (b *harBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if keep := b.recorder.maxBodySize() - len(b.data); keep > 0 {
+		if keep > n {
+			keep = n
+		}
+		b.data = append(b.data, p[:keep]...)
+	}
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *harBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish()
+	return err
+}
+
+// finish records the body read so far in the entry, once.
+func (b *harBody) finish() {
+	if b.done {
+		return
+	}
+	b.done = true
+	receive := milliseconds(b.clock.Now().Sub(b.started))
+	b.recorder.mu.Lock()
+	defer b.recorder.mu.Unlock()
+	content := &b.entry.Response.Content
+	content.Size = b.size
+	content.Text, content.Comment = harText(b.mimeType, b.recorder.redact(b.mimeType, b.data), b.size, b.recorder.maxBodySize())
+	b.entry.Response.BodySize = b.size
+	b.entry.Timings.Receive = receive
+	b.entry.Time += receive
+}
+
+// harText returns the text to record of a body of size bytes starting with
+// data, and a comment on what was left out.
+func harText(mimeType string, data []byte, size int64, limit int) (string, string) {
+	if strings.HasPrefix(mimeType, "audio/") || mimeType == "application/octet-stream" {
+		return "", fmt.Sprintf("%d bytes of audio not recorded", size)
+	}
+	if len(data) > limit {
+		data = data[:limit]
+	}
+	if size > int64(len(data)) {
+		return string(data), fmt.Sprintf("truncated to %d of %d bytes", len(data), size)
+	}
+	return string(data), ""
+}
+
+// harFormParams records the fields of a multipart form, summarizing files.
+func harFormParams(data []byte, boundary string, limit int) []harParam {
+	params := []harParam{}
+	reader := multipart.NewReader(bytes.NewReader(data), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return params
+		}
+		value, _ := io.ReadAll(part)
+		param := harParam{Name: part.FormName(), FileName: part.FileName(), ContentType: part.Header.Get("Content-Type")}
+		if param.FileName != "" {
+			param.Comment = fmt.Sprintf("%d bytes not recorded", len(value))
+		} else {
+			param.Value, param.Comment = harText(param.ContentType, value, int64(len(value)), limit)
+		}
+		params = append(params, param)
+	}
+}
+
+// harHeaders records header, redacting the API key.
+func harHeaders(header http.Header) []harNameValue {
+	headers := []harNameValue{}
+	for _, name := range sortedKeys(header) {
+		for _, value := range header[name] {
+			if name == "X-Api-Key" {
+				value = "REDACTED"
+			}
+			headers = append(headers, harNameValue{Name: name, Value: value})
+		}
+	}
+	return headers
+}
+
+func sortedKeys(values map[string][]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+type harLog struct {
+	Log struct {
+		Version string         `json:"version"`
+		Creator harNameVersion `json:"creator"`
+		Entries []*harEntry    `json:"entries"`
+	} `json:"log"`
+}
+
+type harNameVersion struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+	Comment     string         `json:"comment,omitempty"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harPostData struct {
+	MimeType string     `json:"mimeType"`
+	Text     string     `json:"text"`
+	Params   []harParam `json:"params,omitempty"`
+	Comment  string     `json:"comment,omitempty"`
+}
+
+type harParam struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
@@ -0,0 +1,175 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// harFile is the part of a HAR log the tests check.
+type harFile struct {
+	Log struct {
+		Creator harNameVersion `json:"creator"`
+		Entries []harEntry     `json:"entries"`
+	} `json:"log"`
+}
+
+func readHAR(t *testing.T, recorder *HARRecorder) harFile {
+	t.Helper()
+	data, err := recorder.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		t.Fatal(err)
+	}
+	return har
+}
+
+func TestHARRecorder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/text-to-speech":
+			w.Header().Set("Content-Type", "audio/wav")
+			_, _ = w.Write(makeTestWAV(make([]byte, 1600), 16000))
+		case "/v2/voices":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"voice_id":"tc_1","voice_name":"Olivia","models":[]}]`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"voice_id":"uc_1"}`))
+		}
+	}))
+	defer srv.Close()
+	clock := NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	recorder := &HARRecorder{
+		Next: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			clock.Advance(40 * time.Millisecond)
+			return http.DefaultTransport.RoundTrip(r)
+		}),
+		MaxBodySize: 32,
+		Clock:       clock,
+		IncludeText: true,
+	}
+	c := NewClient(&ClientConfig{APIKey: "secret-key", BaseURL: srv.URL, HTTPClient: &http.Client{Transport: recorder}})
+	ctx := context.Background()
+	if _, err := c.TextToSpeech(ctx, &TTSRequest{VoiceID: "tc_1", Text: "hi", Model: ModelSSFMV30}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetVoicesV2(ctx, &VoicesV2Filter{Model: ModelSSFMV30}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CloneVoice(ctx, []byte("RIFF...."), "sample.wav", "MyVoice", "ssfm-v30"); err != nil {
+		t.Fatal(err)
+	}
+
+	har := readHAR(t, recorder)
+	if har.Log.Creator.Name != "typecast-go" || len(har.Log.Entries) != 3 {
+		t.Fatalf("unexpected log %+v", har.Log)
+	}
+	tts := har.Log.Entries[0]
+	if tts.StartedDateTime != "2026-01-01T00:00:00Z" || tts.Timings.Wait != 40 || tts.Time != 40 {
+		t.Fatalf("unexpected timing %s %+v", tts.StartedDateTime, tts.Timings)
+	}
+	if tts.Request.Method != "POST" || tts.Request.PostData == nil || tts.Request.PostData.Text != `{"voice_id":"tc_1","text":"hi","` ||
+		tts.Request.PostData.Comment != "truncated to 32 of 50 bytes" {
+		t.Fatalf("unexpected request %+v", tts.Request.PostData)
+	}
+	for _, header := range tts.Request.Headers {
+		if header.Name == "X-Api-Key" && header.Value != "REDACTED" {
+			t.Fatalf("the API key leaked: %+v", header)
+		}
+	}
+	if content := tts.Response.Content; tts.Response.Status != 200 || tts.Response.StatusText != "OK" || content.Text != "" ||
+		content.Size != 1644 || content.Comment != "1644 bytes of audio not recorded" {
+		t.Fatalf("unexpected response %+v", tts.Response)
+	}
+
+	voices := har.Log.Entries[1]
+	if len(voices.Request.QueryString) != 1 || voices.Request.QueryString[0] != (harNameValue{Name: "model", Value: "ssfm-v30"}) {
+		t.Fatalf("unexpected query %+v", voices.Request.QueryString)
+	}
+	if voices.Request.PostData != nil || voices.Response.Content.Comment != "truncated to 32 of 55 bytes" {
+		t.Fatalf("unexpected entry %+v", voices)
+	}
+
+	clone := har.Log.Entries[2]
+	params := clone.Request.PostData.Params
+	if len(params) != 3 || params[0] != (harParam{Name: "name", Value: "MyVoice"}) ||
+		params[2] != (harParam{Name: "file", FileName: "sample.wav", ContentType: "audio/wav", Comment: "8 bytes not recorded"}) {
+		t.Fatalf("unexpected form %+v", params)
+	}
+	if clone.Response.Content.Text != `{"voice_id":"uc_1"}` || clone.Response.Content.Comment != "" {
+		t.Fatalf("unexpected response %+v", clone.Response.Content)
+	}
+
+	path := filepath.Join(t.TempDir(), "run.har")
+	if err := recorder.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || !strings.Contains(string(data), `"version": "1.2"`) {
+		t.Fatalf("unexpected file %s: %v", data, err)
+	}
+	if err := recorder.WriteFile(filepath.Join(path, "run.har")); err == nil || !strings.Contains(err.Error(), "failed to write HAR file") {
+		t.Fatalf("expected a write error, got %v", err)
+	}
+}
+
+func TestHARRecorderEdgeCases(t *testing.T) {
+	recorder := &HARRecorder{}
+	if har := readHAR(t, recorder); har.Log.Entries == nil || len(har.Log.Entries) != 0 {
+		t.Fatalf("an empty log must have no entries, got %+v", har.Log)
+	}
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, HTTPClient: &http.Client{Transport: recorder}})
+	if _, err := c.GetVoicesV2(context.Background(), nil); err == nil {
+		t.Fatal("expected a 404")
+	}
+	if har := readHAR(t, recorder); har.Log.Entries[0].Response.Status != http.StatusNotFound {
+		t.Fatalf("unexpected entry %+v", har.Log.Entries[0])
+	}
+	recorder = &HARRecorder{}
+
+	failing := errors.New("connection refused")
+	recorder.Next = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Method == http.MethodPut {
+			return nil, failing
+		}
+		return &http.Response{StatusCode: http.StatusNoContent, Header: http.Header{}, Body: http.NoBody}, nil
+	})
+	req, _ := http.NewRequest(http.MethodPut, "https://api.example.test/upload", io.MultiReader(strings.NewReader("data")))
+	if _, err := recorder.RoundTrip(req); err != failing {
+		t.Fatalf("expected the transport error, got %v", err)
+	}
+	req, _ = http.NewRequest(http.MethodPost, "https://api.example.test/v1/text-to-speech", strings.NewReader("{}"))
+	req.GetBody = func() (io.ReadCloser, error) { return nil, errors.New("gone") }
+	resp, err := recorder.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	har := readHAR(t, recorder)
+	streamed, unread := har.Log.Entries[0], har.Log.Entries[1]
+	if streamed.Request.PostData.Comment != "streamed body not recorded" || streamed.Response.Comment != "connection refused" {
+		t.Fatalf("unexpected entry %+v", streamed)
+	}
+	if unread.Request.PostData.Comment != "body not recorded: gone" || unread.Response.Content.Comment != "body not read" {
+		t.Fatalf("unexpected entry %+v", unread)
+	}
+
+	_ = resp.Body.Close()
+	_ = resp.Body.Close()
+	if har := readHAR(t, recorder); har.Log.Entries[1].Response.Content.Comment != "" || har.Log.Entries[1].Response.BodySize != 0 {
+		t.Fatalf("a closed body must be recorded, got %+v", har.Log.Entries[1].Response)
+	}
+}
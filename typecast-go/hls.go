@@ -0,0 +1,154 @@
+package typecast
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// hlsTimestampOwner is the PRIV frame owner that carries the presentation
+// timestamp of an HLS packed audio segment.
+const hlsTimestampOwner = "com.apple.streaming.transportStreamTimestamp"
+
+// HLSOptions configures an HLSWriter.
+type HLSOptions struct {
+	// SegmentDuration is the target segment length in seconds (optional,
+	// defaults to 6). Segments are cut at MP3 frame boundaries and never
+	// exceed it.
+	SegmentDuration float64
+	// PlaylistName is the playlist's file name (optional, defaults to
+	// "playlist.m3u8")
+	PlaylistName string
+	// SegmentPrefix starts each segment's file name (optional, defaults to
+	// "segment")
+	SegmentPrefix string
+}
+
+// HLSSegment describes one segment file written by an HLSWriter.
+type HLSSegment struct {
+	// File is the segment's file name, relative to the directory
+	File string
+	// Start is the segment's offset in the narration, in seconds
+	Start float64
+	// Duration is the segment's duration in seconds
+	Duration float64
+}
+
+// HLSWriter packages MP3 audio into HLS packed audio segments and an m3u8
+// playlist. The playlist is rewritten as each segment is completed, so
+// players can start before the narration is fully rendered.
+type HLSWriter struct {
+	dir       string
+	opts      HLSOptions
+	mu        sync.Mutex
+	segmenter mp3Segmenter
+	segments  []HLSSegment
+	elapsed   float64
+	closed    bool
+}
+
+// NewHLSWriter creates dir if needed and returns a writer that packages
+// audio into it.
+func NewHLSWriter(dir string, opts *HLSOptions) (*HLSWriter, error) {
+	w := &HLSWriter{dir: dir}
+	if opts != nil {
+		w.opts = *opts
+	}
+	if w.opts.SegmentDuration < 0 {
+		return nil, newValidationError("segment_duration", "segment_duration must not be negative")
+	}
+	if w.opts.SegmentDuration == 0 {
+		w.opts.SegmentDuration = 6
+	}
+	if w.opts.PlaylistName == "" {
+		w.opts.PlaylistName = "playlist.m3u8"
+	}
+	if w.opts.SegmentPrefix == "" {
+		w.opts.SegmentPrefix = "segment"
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	w.segmenter = mp3Segmenter{target: w.opts.SegmentDuration, flush: w.writeSegment}
+	return w, nil
+}
+
+// Append adds MP3 audio, such as one long-form segment, and writes every
+// segment it completes. Audio that does not fill a segment is held until the
+// next Append or Close.
+func (w *HLSWriter) Append(mp3 []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return fmt.Errorf("hls writer is closed")
+	}
+	frames, err := parseMP3Frames(mp3)
+	if err != nil {
+		return fmt.Errorf("hls requires mp3 audio: %w", err)
+	}
+	return w.segmenter.add(mp3, frames)
+}
+
+// Close writes the remaining audio as a final segment and ends the playlist.
+func (w *HLSWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	if err := w.segmenter.finish(); err != nil {
+		return err
+	}
+	w.closed = true
+	return w.writePlaylist()
+}
+
+// Segments returns the segments written so far.
+func (w *HLSWriter) Segments() []HLSSegment {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]HLSSegment(nil), w.segments...)
+}
+
+// writeSegment writes audio as the next segment and updates the playlist.
+func (w *HLSWriter) writeSegment(audio []byte, _ []mp3Frame, duration float64) error {
+	segment := HLSSegment{
+		File:     fmt.Sprintf("%s-%05d.mp3", w.opts.SegmentPrefix, len(w.segments)),
+		Start:    w.elapsed,
+		Duration: duration,
+	}
+	data := append(hlsTimestampTag(w.elapsed), audio...)
+	if err := writeFileAtomic(filepath.Join(w.dir, segment.File), data); err != nil {
+		return err
+	}
+	w.segments = append(w.segments, segment)
+	w.elapsed += duration
+	return w.writePlaylist()
+}
+
+func (w *HLSWriter) writePlaylist() error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", int(math.Ceil(w.opts.SegmentDuration)))
+	b.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-PLAYLIST-TYPE:EVENT\n")
+	for _, segment := range w.segments {
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n%s\n", segment.Duration, segment.File)
+	}
+	if w.closed {
+		b.WriteString("#EXT-X-ENDLIST\n")
+	}
+	return writeFileAtomic(filepath.Join(w.dir, w.opts.PlaylistName), []byte(b.String()))
+}
+
+// hlsTimestampTag returns the ID3v2.4 tag that starts every packed audio
+// segment: a PRIV frame holding the segment's start as a 33-bit, 90 kHz
+// MPEG-2 timestamp.
+func hlsTimestampTag(start float64) []byte {
+	timestamp := make([]byte, 8)
+	binary.BigEndian.PutUint64(timestamp, uint64(math.Round(start*90000))&(1<<33-1))
+	return id3Tag(id3Frame("PRIV", []byte(hlsTimestampOwner+"\x00"), timestamp))
+}
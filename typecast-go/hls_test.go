@@ -0,0 +1,138 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHLSWriter(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "hls")
+	w, err := NewHLSWriter(dir, &HLSOptions{SegmentDuration: 0.1})
+	if err != nil {
+		t.Fatalf("NewHLSWriter() error = %v", err)
+	}
+	// Three 26ms frames fit in a 100ms segment; a fourth would not.
+	if err := w.Append(makeTestMP3(4)); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := w.Append(makeTestMP3(6)); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	playlist, _ := os.ReadFile(filepath.Join(dir, "playlist.m3u8"))
+	if strings.Count(string(playlist), "#EXTINF") != 3 || strings.Contains(string(playlist), "#EXT-X-ENDLIST") {
+		t.Fatalf("playlist before Close:\n%s", playlist)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("second Close() error = %v", err)
+	}
+
+	want := "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:1\n#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-PLAYLIST-TYPE:EVENT\n" +
+		"#EXTINF:0.078,\nsegment-00000.mp3\n#EXTINF:0.078,\nsegment-00001.mp3\n" +
+		"#EXTINF:0.078,\nsegment-00002.mp3\n#EXTINF:0.026,\nsegment-00003.mp3\n#EXT-X-ENDLIST\n"
+	playlist, _ = os.ReadFile(filepath.Join(dir, "playlist.m3u8"))
+	if string(playlist) != want {
+		t.Fatalf("playlist =\n%s\nwant\n%s", playlist, want)
+	}
+	segments := w.Segments()
+	if len(segments) != 4 || !approx(segments[1].Start, 3*1152.0/44100) {
+		t.Fatalf("unexpected segments: %+v", segments)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "segment-00001.mp3"))
+	tag := hlsTimestampTag(segments[1].Start)
+	if !bytes.HasPrefix(data, tag) || !bytes.Equal(data[len(tag):], makeTestMP3(3)) {
+		t.Fatal("segment must be the timestamp tag followed by its frames")
+	}
+	if !bytes.Contains(tag, []byte(hlsTimestampOwner+"\x00")) || binary.BigEndian.Uint64(tag[len(tag)-8:]) != 7053 {
+		t.Fatalf("unexpected timestamp tag % x", tag)
+	}
+	if len(stripID3v2(data)) != 3*417 {
+		t.Fatal("timestamp tag must be a well-formed ID3v2 tag")
+	}
+	if err := w.Append(makeTestMP3(1)); err == nil {
+		t.Fatal("expected error after Close")
+	}
+}
+
+func TestHLSWriter_Errors(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	var validation *ValidationError
+	if _, err := NewHLSWriter(root, &HLSOptions{SegmentDuration: -1}); !errors.As(err, &validation) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if _, err := NewHLSWriter(file, nil); err == nil {
+		t.Fatal("expected error when the directory is a file")
+	}
+
+	w, _ := NewHLSWriter(filepath.Join(root, "wav"), nil)
+	if err := w.Append(makeTestWAV(make([]byte, 1600), 8000)); err == nil || !strings.Contains(err.Error(), "hls requires mp3 audio") {
+		t.Fatalf("expected mp3 error, got %v", err)
+	}
+
+	// Directories in place of the files make writing them fail.
+	segmentDir := filepath.Join(root, "segment")
+	w, _ = NewHLSWriter(segmentDir, &HLSOptions{SegmentDuration: 0.03, SegmentPrefix: "part"})
+	_ = os.MkdirAll(filepath.Join(segmentDir, "part-00000.mp3.tmp"), 0755)
+	if err := w.Append(makeTestMP3(2)); err == nil {
+		t.Fatal("expected error when the segment cannot be written")
+	}
+	playlistDir := filepath.Join(root, "playlist")
+	w, _ = NewHLSWriter(playlistDir, &HLSOptions{PlaylistName: "index.m3u8"})
+	_ = os.MkdirAll(filepath.Join(playlistDir, "index.m3u8.tmp"), 0755)
+	_ = w.Append(makeTestMP3(1))
+	if err := w.Close(); err == nil {
+		t.Fatal("expected error when the playlist cannot be written")
+	}
+}
+
+func TestLongFormSynthesize_HLS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write(makeTestMP3(2))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+
+	dir := t.TempDir()
+	hls, _ := NewHLSWriter(dir, &HLSOptions{SegmentDuration: 0.06})
+	request := LongFormRequest{
+		Profile:       VoiceProfile{VoiceID: "v", AudioFormat: AudioFormatMP3},
+		Text:          "One. Two.",
+		MaxChunkChars: 5,
+		HLS:           hls,
+	}
+	if _, err := c.LongFormSynthesize(context.Background(), request); err != nil {
+		t.Fatalf("LongFormSynthesize() error = %v", err)
+	}
+	if segments := hls.Segments(); len(segments) != 1 {
+		t.Fatalf("segments must be written while synthesizing, got %+v", segments)
+	}
+	_ = hls.Close()
+	if len(hls.Segments()) != 2 {
+		t.Fatalf("unexpected segments after Close: %+v", hls.Segments())
+	}
+
+	if _, err := c.LongFormSynthesize(context.Background(), request); err == nil || !strings.Contains(err.Error(), "segment 0: hls writer is closed") {
+		t.Fatalf("expected hls error, got %v", err)
+	}
+	request.Profile.AudioFormat = AudioFormatWAV
+	var validation *ValidationError
+	if _, err := c.LongFormSynthesize(context.Background(), request); !errors.As(err, &validation) || validation.Field != "hls" {
+		t.Fatalf("expected hls ValidationError, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package typecast
+
+import (
+	"context"
+	"time"
+)
+
+// defaultHookRetryDelay is the first delay before retrying a failed hook.
+const defaultHookRetryDelay = 500 * time.Millisecond
+
+// DeliveryHooks push long-form outputs to a CDN, a queue, or any other
+// destination as soon as they are ready, so delivery does not have to poll
+// the filesystem. A failing hook is retried with exponential backoff; once
+// its retries are exhausted, LongFormSynthesize fails with its error.
+//
+// A rerun resumed from a Store or CheckpointDir calls OnSegmentDone again for
+// the stored segments, so hooks should be idempotent.
+type DeliveryHooks struct {
+	// OnSegmentDone is called with each segment and its audio once it is
+	// synthesized, in order (optional)
+	OnSegmentDone func(ctx context.Context, segment LongFormSegment, audio []byte) error
+	// OnJobDone is called with the stitched result (optional)
+	OnJobDone func(ctx context.Context, result *LongFormResult) error
+	// MaxRetries is the number of times a failing hook is retried
+	// (optional, defaults to 0)
+	MaxRetries int
+	// RetryDelay is the delay before the first retry; it doubles after each
+	// attempt (optional, defaults to 500ms)
+	RetryDelay time.Duration
+	// Clock times the retry delays (optional, defaults to SystemClock)
+	Clock Clock
+}
+
+func (h *DeliveryHooks) segmentDone(ctx context.Context, segment LongFormSegment, audio []byte) error {
+	if h == nil || h.OnSegmentDone == nil {
+		return nil
+	}
+	return h.retry(ctx, func() error { return h.OnSegmentDone(ctx, segment, audio) })
+}
+
+func (h *DeliveryHooks) jobDone(ctx context.Context, result *LongFormResult) error {
+	if h == nil || h.OnJobDone == nil {
+		return nil
+	}
+	return h.retry(ctx, func() error { return h.OnJobDone(ctx, result) })
+}
+
+// retry calls fn until it succeeds, MaxRetries is exhausted, or ctx is done.
+func (h *DeliveryHooks) retry(ctx context.Context, fn func() error) error {
+	delay := h.RetryDelay
+	if delay <= 0 {
+		delay = defaultHookRetryDelay
+	}
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= h.MaxRetries {
+			return err
+		}
+		if err := sleepContext(ctx, clockOrSystem(h.Clock), delay<<uint(attempt)); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLongFormSynthesize_DeliveryHooks(t *testing.T) {
+	srv, _ := countingTTSServer("")
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+
+	var delivered []string
+	attempts := 0
+	hooks := &DeliveryHooks{
+		OnSegmentDone: func(ctx context.Context, segment LongFormSegment, audio []byte) error {
+			attempts++
+			if attempts == 1 {
+				return errors.New("cdn unavailable")
+			}
+			if len(audio) == 0 {
+				t.Error("segment hook must receive the audio")
+			}
+			delivered = append(delivered, segment.Text)
+			return nil
+		},
+		OnJobDone: func(ctx context.Context, result *LongFormResult) error {
+			delivered = append(delivered, "job")
+			return nil
+		},
+		MaxRetries: 1,
+		RetryDelay: time.Millisecond,
+	}
+	request := LongFormRequest{Profile: VoiceProfile{VoiceID: "v"}, Text: "One. Two.", MaxChunkChars: 5, Hooks: hooks}
+	if _, err := c.LongFormSynthesize(context.Background(), request); err != nil {
+		t.Fatalf("LongFormSynthesize() error = %v", err)
+	}
+	if strings.Join(delivered, "|") != "One.|Two.|job" || attempts != 3 {
+		t.Fatalf("delivered %q in %d attempts", delivered, attempts)
+	}
+
+	hooks.MaxRetries = 0
+	hooks.OnJobDone = func(ctx context.Context, result *LongFormResult) error { return errors.New("queue full") }
+	if _, err := c.LongFormSynthesize(context.Background(), request); err == nil || err.Error() != "failed to deliver: queue full" {
+		t.Fatalf("expected job hook error, got %v", err)
+	}
+	hooks.OnSegmentDone = func(ctx context.Context, segment LongFormSegment, audio []byte) error { return errors.New("cdn down") }
+	if _, err := c.LongFormSynthesize(context.Background(), request); err == nil || err.Error() != "segment 0: failed to deliver: cdn down" {
+		t.Fatalf("expected segment hook error, got %v", err)
+	}
+}
+
+func TestDeliveryHooks_RetryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	hooks := &DeliveryHooks{
+		OnJobDone: func(context.Context, *LongFormResult) error {
+			cancel()
+			return errors.New("fail")
+		},
+		MaxRetries: 5,
+	}
+	start := time.Now()
+	if err := hooks.jobDone(ctx, &LongFormResult{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > defaultHookRetryDelay {
+		t.Fatal("retry must not wait once the context is done")
+	}
+	if err := (*DeliveryHooks)(nil).segmentDone(ctx, LongFormSegment{}, nil); err != nil {
+		t.Fatalf("nil hooks must be a no-op, got %v", err)
+	}
+}
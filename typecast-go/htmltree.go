@@ -0,0 +1,223 @@
+package typecast
+
+import (
+	"html"
+	"strings"
+)
+
+// htmlNode is an element or text node of a leniently parsed HTML document.
+type htmlNode struct {
+	tag      string // lowercase element name; empty for text
+	text     string // unescaped text of a text node
+	attrs    map[string]string
+	parent   *htmlNode
+	children []*htmlNode
+}
+
+// htmlVoidElements never have content or an end tag.
+var htmlVoidElements = tagSet("area base br col embed hr img input link meta param source track wbr")
+
+// htmlRawTextElements hold text up to their end tag, without markup.
+var htmlRawTextElements = tagSet("script style noscript textarea title xmp template")
+
+// htmlClosesParagraph holds the elements whose start tag ends an open <p>.
+var htmlClosesParagraph = tagSet("address article aside blockquote div dl fieldset figure footer form " +
+	"h1 h2 h3 h4 h5 h6 header hr main nav ol p pre section table ul")
+
+func tagSet(names string) map[string]bool {
+	set := map[string]bool{}
+	for _, name := range strings.Fields(names) {
+		set[name] = true
+	}
+	return set
+}
+
+// parseHTML builds a tree from an HTML document, recovering from malformed
+// markup the way browsers commonly do: unclosed paragraphs and list items
+// are closed by the next block, and stray end tags are ignored. Comments,
+// doctypes, and processing instructions are dropped.
+func parseHTML(doc string) *htmlNode {
+	root := &htmlNode{tag: "#document"}
+	current := root
+	for i := 0; i < len(doc); {
+		lt := strings.IndexByte(doc[i:], '<')
+		if lt < 0 {
+			current.appendText(doc[i:])
+			break
+		}
+		current.appendText(doc[i : i+lt])
+		i += lt
+		switch {
+		case strings.HasPrefix(doc[i:], "<!--"):
+			i = skipPast(doc, i+4, "-->")
+		case strings.HasPrefix(doc[i:], "<!") || strings.HasPrefix(doc[i:], "<?"):
+			i = skipPast(doc, i+2, ">")
+		case strings.HasPrefix(doc[i:], "</") && i+2 < len(doc) && isASCIILetter(doc[i+2]):
+			name, _, end := parseTag(doc, i+2)
+			i = end
+			for n := current; n != root; n = n.parent {
+				if n.tag == name {
+					current = n.parent
+					break
+				}
+			}
+		case i+1 < len(doc) && isASCIILetter(doc[i+1]):
+			name, attrs, end := parseTag(doc, i+1)
+			i = end
+			current = current.implicitlyClose(name, root)
+			node := &htmlNode{tag: name, attrs: attrs, parent: current}
+			current.children = append(current.children, node)
+			switch {
+			case htmlVoidElements[name] || strings.HasSuffix(doc[:end], "/>"):
+			case htmlRawTextElements[name]:
+				closing := strings.Index(strings.ToLower(doc[i:]), "</"+name)
+				if closing < 0 {
+					closing = len(doc) - i
+				}
+				node.appendText(doc[i : i+closing])
+				i = skipPast(doc, i+closing, ">")
+			default:
+				current = node
+			}
+		default:
+			current.appendText("<")
+			i++
+		}
+	}
+	return root
+}
+
+// implicitlyClose returns the element a new name element is added to,
+// closing an open paragraph or list item it cannot be nested in.
+func (n *htmlNode) implicitlyClose(name string, root *htmlNode) *htmlNode {
+	if htmlClosesParagraph[name] && n.tag == "p" {
+		return n.parent
+	}
+	if name == "li" || name == "dt" || name == "dd" {
+		for open := n; open != root && open.tag != "ul" && open.tag != "ol" && open.tag != "dl"; open = open.parent {
+			if open.tag == "li" || open.tag == "dt" || open.tag == "dd" {
+				return open.parent
+			}
+		}
+	}
+	return n
+}
+
+func (n *htmlNode) appendText(raw string) {
+	if raw != "" {
+		n.children = append(n.children, &htmlNode{text: html.UnescapeString(raw), parent: n})
+	}
+}
+
+// parseTag parses the name and attributes of the tag whose name starts at
+// doc[start], returning the index after its closing '>'.
+func parseTag(doc string, start int) (name string, attrs map[string]string, end int) {
+	i := start
+	for i < len(doc) && !isTagSpace(doc[i]) && doc[i] != '>' && doc[i] != '/' {
+		i++
+	}
+	name = strings.ToLower(doc[start:i])
+	for i < len(doc) {
+		for i < len(doc) && (isTagSpace(doc[i]) || doc[i] == '/') {
+			i++
+		}
+		if i >= len(doc) || doc[i] == '>' {
+			break
+		}
+		keyStart := i
+		for i < len(doc) && !isTagSpace(doc[i]) && doc[i] != '>' && doc[i] != '=' && doc[i] != '/' {
+			i++
+		}
+		key := strings.ToLower(doc[keyStart:i])
+		value := ""
+		if i < len(doc) && doc[i] == '=' {
+			i++
+			if i < len(doc) && (doc[i] == '"' || doc[i] == '\'') {
+				quote := doc[i]
+				closing := strings.IndexByte(doc[i+1:], quote)
+				if closing < 0 {
+					value, i = doc[i+1:], len(doc)
+				} else {
+					value = doc[i+1 : i+1+closing]
+					i += closing + 2
+				}
+			} else {
+				valueStart := i
+				for i < len(doc) && !isTagSpace(doc[i]) && doc[i] != '>' {
+					i++
+				}
+				value = doc[valueStart:i]
+			}
+		}
+		if attrs == nil {
+			attrs = map[string]string{}
+		}
+		if _, ok := attrs[key]; !ok {
+			attrs[key] = html.UnescapeString(value)
+		}
+	}
+	if i < len(doc) {
+		i++
+	}
+	return name, attrs, i
+}
+
+// skipPast returns the index after the first marker at or after start, or
+// the end of doc.
+func skipPast(doc string, start int, marker string) int {
+	if start > len(doc) {
+		return len(doc)
+	}
+	if end := strings.Index(doc[start:], marker); end >= 0 {
+		return start + end + len(marker)
+	}
+	return len(doc)
+}
+
+func isTagSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// find returns the first element in n's subtree, in document order, for
+// which match returns true.
+func (n *htmlNode) find(match func(*htmlNode) bool) *htmlNode {
+	for _, child := range n.children {
+		if child.tag == "" {
+			continue
+		}
+		if match(child) {
+			return child
+		}
+		if found := child.find(match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// textContent returns the text of n's subtree with whitespace collapsed.
+func (n *htmlNode) textContent() string {
+	var b strings.Builder
+	n.writeText(&b)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func (n *htmlNode) writeText(b *strings.Builder) {
+	if n.tag == "" {
+		b.WriteString(n.text)
+		return
+	}
+	if htmlRawTextElements[n.tag] && n.tag != "title" {
+		return
+	}
+	for _, child := range n.children {
+		child.writeText(b)
+	}
+	if n.tag == "br" || !htmlInlineElements[n.tag] {
+		b.WriteByte(' ')
+	}
+}
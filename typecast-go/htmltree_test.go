@@ -0,0 +1,69 @@
+package typecast
+
+import (
+	"strings"
+	"testing"
+)
+
+// dumpHTML renders a tree as nested element names and quoted text.
+func dumpHTML(n *htmlNode) string {
+	if n.tag == "" {
+		return "'" + n.text + "'"
+	}
+	var parts []string
+	for _, child := range n.children {
+		parts = append(parts, dumpHTML(child))
+	}
+	return n.tag + "(" + strings.Join(parts, " ") + ")"
+}
+
+func TestParseHTML(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want string
+	}{
+		{`<?xml version="1.0"?><!DOCTYPE html><!-- note --><P>a &lt; b</p>`, `#document(p('a < b'))`},
+		{`<p>one<p>two<div>three</div>`, `#document(p('one') p('two') div('three'))`},
+		{`<ul><li>a<li>b<ul><li>c</ul><li>d</ul>`, `#document(ul(li('a') li('b' ul(li('c'))) li('d')))`},
+		{`<dl><dt>term<dd>meaning</dl>`, `#document(dl(dt('term') dd('meaning')))`},
+		{`a<br/>b<img src=x>c<span/>d`, `#document('a' br() 'b' img() 'c' span() 'd')`},
+		{`<b>bold</i> text</b> after`, `#document(b('bold' ' text') ' after')`},
+		{`1 < 2 </3 <`, `#document('1 ' '<' ' 2 ' '<' '/3 ' '<')`},
+		{`<script>x = "</p>"</SCRIPT>y`, `#document(script('x = "</p>"') 'y')`},
+		{`<style>p {}`, `#document(style('p {}'))`},
+		{`<p>unclosed <!-- comment`, `#document(p('unclosed '))`},
+		{`<p`, `#document(p())`},
+	}
+	for _, tt := range tests {
+		if got := dumpHTML(parseHTML(tt.doc)); got != tt.want {
+			t.Errorf("parseHTML(%q) = %s, want %s", tt.doc, got, tt.want)
+		}
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	doc := `<A HREF="/x?a=1&amp;b=2" data-x='y' checked id=main href=/dup title="open>`
+	name, attrs, end := parseTag(doc, 1)
+	if name != "a" || end != len(doc) {
+		t.Fatalf("parseTag() = %q, %d", name, end)
+	}
+	want := map[string]string{"href": "/x?a=1&b=2", "data-x": "y", "checked": "", "id": "main", "title": "open>"}
+	for key, value := range want {
+		if attrs[key] != value {
+			t.Errorf("attrs[%q] = %q, want %q", key, attrs[key], value)
+		}
+	}
+	if len(attrs) != len(want) {
+		t.Errorf("attrs = %v", attrs)
+	}
+}
+
+func TestHTMLNodeTextContent(t *testing.T) {
+	root := parseHTML(`<div><title>T</title><p>One<br>two <em>three</em></p><p>four</p><style>x</style></div>`)
+	if got := root.textContent(); got != "T One two three four" {
+		t.Errorf("textContent() = %q", got)
+	}
+	if skipPast("abc", 5, ">") != 3 {
+		t.Error("skipPast() past the end should return the document length")
+	}
+}
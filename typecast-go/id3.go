@@ -0,0 +1,38 @@
+package typecast
+
+// id3Tag returns an ID3v2.4 tag holding frames.
+func id3Tag(frames ...[]byte) []byte {
+	size := 0
+	for _, frame := range frames {
+		size += len(frame)
+	}
+	tag := append([]byte("ID3\x04\x00\x00"), syncsafe(size)...)
+	for _, frame := range frames {
+		tag = append(tag, frame...)
+	}
+	return tag
+}
+
+// id3Frame returns an ID3v2.4 frame with no flags.
+func id3Frame(id string, payload ...[]byte) []byte {
+	size := 0
+	for _, part := range payload {
+		size += len(part)
+	}
+	frame := append([]byte(id), syncsafe(size)...)
+	frame = append(frame, 0, 0)
+	for _, part := range payload {
+		frame = append(frame, part...)
+	}
+	return frame
+}
+
+// id3TextFrame returns a UTF-8 text frame such as TIT2.
+func id3TextFrame(id, text string) []byte {
+	return id3Frame(id, []byte{3}, []byte(text))
+}
+
+// syncsafe encodes n as a 4-byte ID3v2 synchsafe integer.
+func syncsafe(n int) []byte {
+	return []byte{byte(n>>21) & 0x7f, byte(n>>14) & 0x7f, byte(n>>7) & 0x7f, byte(n) & 0x7f}
+}
@@ -0,0 +1,107 @@
+package typecast
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// IntegrityError reports audio that failed an integrity check: a length or
+// checksum that does not match the response headers, or a payload that does
+// not look like the expected container format.
+type IntegrityError struct {
+	// Check is the failed check: "length", "checksum" or "format"
+	Check string
+	// Expected describes the expected value
+	Expected string
+	// Actual describes the value found
+	Actual string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("audio integrity check failed: %s mismatch: expected %s, got %s", e.Check, e.Expected, e.Actual)
+}
+
+// verifyAudio checks data against the length and checksum headers of resp
+// and, when VerifyAudioFormat is enabled, against the container format.
+func (c *Client) verifyAudio(resp *http.Response, data []byte, format AudioFormat) error {
+	if err := verifyAudioHeaders(resp, data); err != nil {
+		return err
+	}
+	if c.verifyAudioFormat {
+		return verifyAudioFormat(format, data)
+	}
+	return nil
+}
+
+// verifyAudioHeaders compares data with Content-Length, Content-MD5 and the
+// sha-256/md5 entries of a Digest header, whichever the server provided.
+func verifyAudioHeaders(resp *http.Response, data []byte) error {
+	if resp.ContentLength > 0 && int64(len(data)) != resp.ContentLength {
+		return &IntegrityError{Check: "length", Expected: strconv.FormatInt(resp.ContentLength, 10) + " bytes", Actual: strconv.Itoa(len(data)) + " bytes"}
+	}
+	if want := resp.Header.Get("Content-MD5"); want != "" {
+		if err := verifyChecksum("md5", want, data); err != nil {
+			return err
+		}
+	}
+	for _, entry := range strings.Split(resp.Header.Get("Digest"), ",") {
+		i := strings.Index(entry, "=")
+		if i < 0 {
+			continue
+		}
+		algorithm := strings.ToLower(strings.TrimSpace(entry[:i]))
+		if algorithm != "sha-256" && algorithm != "md5" {
+			continue
+		}
+		if err := verifyChecksum(algorithm, strings.TrimSpace(entry[i+1:]), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func verifyChecksum(algorithm, want string, data []byte) error {
+	var sum []byte
+	if algorithm == "md5" {
+		digest := md5.Sum(data)
+		sum = digest[:]
+	} else {
+		digest := sha256.Sum256(data)
+		sum = digest[:]
+	}
+	if got := base64.StdEncoding.EncodeToString(sum); got != want {
+		return &IntegrityError{Check: "checksum", Expected: algorithm + " " + want, Actual: algorithm + " " + got}
+	}
+	return nil
+}
+
+// verifyAudioFormat sanity-checks the container header: a RIFF/WAVE header
+// for WAV, and an ID3 tag or MPEG frame sync for MP3.
+func verifyAudioFormat(format AudioFormat, data []byte) error {
+	switch format {
+	case AudioFormatWAV:
+		if len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && string(data[8:12]) == "WAVE" {
+			return nil
+		}
+		return &IntegrityError{Check: "format", Expected: "RIFF/WAVE header", Actual: describeAudioHeader(data)}
+	case AudioFormatMP3:
+		if bytes.HasPrefix(data, []byte("ID3")) || (len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0) {
+			return nil
+		}
+		return &IntegrityError{Check: "format", Expected: "ID3 tag or MPEG frame sync", Actual: describeAudioHeader(data)}
+	}
+	return nil
+}
+
+func describeAudioHeader(data []byte) string {
+	if len(data) > 12 {
+		data = data[:12]
+	}
+	return fmt.Sprintf("% x", data)
+}
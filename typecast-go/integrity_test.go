@@ -0,0 +1,127 @@
+package typecast
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testWAV = []byte("RIFF\x24\x00\x00\x00WAVEfmt ")
+
+func base64Sum(b []byte, algorithm string) string {
+	if algorithm == "md5" {
+		sum := md5.Sum(b)
+		return base64.StdEncoding.EncodeToString(sum[:])
+	}
+	sum := sha256.Sum256(b)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func integrityServer(contentType string, body []byte, headers map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range headers {
+			w.Header().Set(k, v)
+		}
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(body)
+	}))
+}
+
+func TestTextToSpeech_VerifiesChecksumHeaders(t *testing.T) {
+	req := &TTSRequest{VoiceID: "v", Text: "t", Model: ModelSSFMV30}
+	good := map[string]string{
+		"Content-MD5": base64Sum(testWAV, "md5"),
+		"Digest":      "unknown=abc, bogus, SHA-256=" + base64Sum(testWAV, "sha-256"),
+	}
+	srv := integrityServer("audio/wav", testWAV, good)
+	defer srv.Close()
+	if _, err := newTestClient(srv, "k").TextToSpeech(context.Background(), req); err != nil {
+		t.Fatalf("TextToSpeech() error = %v", err)
+	}
+
+	for name, headers := range map[string]map[string]string{
+		"content-md5": {"Content-MD5": base64Sum([]byte("x"), "md5")},
+		"digest":      {"Digest": "sha-256=" + base64Sum([]byte("x"), "sha-256")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := integrityServer("audio/wav", testWAV, headers)
+			defer srv.Close()
+			_, err := newTestClient(srv, "k").TextToSpeech(context.Background(), req)
+			var integrity *IntegrityError
+			if !errors.As(err, &integrity) || integrity.Check != "checksum" {
+				t.Fatalf("expected checksum IntegrityError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "checksum mismatch") {
+				t.Fatalf("unexpected message: %v", err)
+			}
+		})
+	}
+}
+
+func TestTextToSpeech_LengthMismatch(t *testing.T) {
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://x"})
+	c.httpClient = &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{"Content-Type": []string{"audio/wav"}},
+			ContentLength: 100,
+			Body:          io.NopCloser(strings.NewReader("short")),
+		}, nil
+	})}
+	_, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "t", Model: ModelSSFMV30})
+	var integrity *IntegrityError
+	if !errors.As(err, &integrity) || integrity.Check != "length" || integrity.Actual != "5 bytes" {
+		t.Fatalf("expected length IntegrityError, got %v", err)
+	}
+}
+
+func TestTextToSpeech_VerifyAudioFormat(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		body        []byte
+		wantErr     bool
+	}{
+		{"valid wav", "audio/wav", testWAV, false},
+		{"invalid wav", "audio/wav", []byte("<html>oops</html>"), true},
+		{"id3 mp3", "audio/mpeg", []byte("ID3\x04\x00"), false},
+		{"frame sync mp3", "audio/mpeg", []byte{0xFF, 0xFB, 0x90, 0x64}, false},
+		{"invalid mp3", "audio/mpeg", []byte("{}"), true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := integrityServer(tc.contentType, tc.body, nil)
+			defer srv.Close()
+			c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, VerifyAudioFormat: true})
+			_, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "t", Model: ModelSSFMV30})
+			var integrity *IntegrityError
+			if tc.wantErr != errors.As(err, &integrity) {
+				t.Fatalf("wantErr=%v, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr && integrity.Check != "format" {
+				t.Fatalf("unexpected check: %+v", integrity)
+			}
+		})
+	}
+	if err := verifyAudioFormat(AudioFormat("ogg"), nil); err != nil {
+		t.Fatalf("unknown formats should not be checked, got %v", err)
+	}
+}
+
+func TestComposeSpeech_VerifiesAudio(t *testing.T) {
+	srv := integrityServer("audio/wav", []byte("not audio"), nil)
+	defer srv.Close()
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, VerifyAudioFormat: true})
+	_, err := c.ComposeSpeech().SayWith("hi", ComposerSettings{VoiceID: "v", Model: ModelSSFMV30}).Generate(context.Background())
+	var integrity *IntegrityError
+	if !errors.As(err, &integrity) {
+		t.Fatalf("expected IntegrityError, got %v", err)
+	}
+}
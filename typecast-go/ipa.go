@@ -0,0 +1,148 @@
+package typecast
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ipaPhone is an IPA sound and its English respelling.
+type ipaPhone struct {
+	spelling string
+	vowel    bool
+}
+
+// ipaPhones respells IPA symbols and digraphs after the respelling keys of
+// English dictionaries. Length marks are removed before lookup, so "iː"
+// and "i" are both "ee".
+var ipaPhones = map[string]ipaPhone{
+	// Diphthongs and r-colored vowels
+	"aɪ": {"eye", true}, "aʊ": {"ow", true}, "ɔɪ": {"oy", true}, "eɪ": {"ay", true},
+	"oʊ": {"oh", true}, "əʊ": {"oh", true}, "ɪə": {"eer", true}, "eə": {"air", true},
+	"ʊə": {"oor", true}, "ɑr": {"ar", true}, "ɔr": {"or", true}, "ɪr": {"eer", true},
+	"ɛr": {"air", true}, "ʊr": {"oor", true}, "ɜr": {"ur", true}, "ər": {"er", true},
+	// Vowels
+	"æ": {"a", true}, "ɑ": {"ah", true}, "ɒ": {"o", true}, "ɔ": {"aw", true},
+	"ə": {"uh", true}, "ɚ": {"er", true}, "ɜ": {"ur", true}, "ɝ": {"ur", true},
+	"ɛ": {"eh", true}, "e": {"eh", true}, "ɪ": {"ih", true}, "i": {"ee", true},
+	"o": {"oh", true}, "ʊ": {"uu", true}, "u": {"oo", true}, "ʌ": {"uh", true},
+	"a": {"ah", true}, "ɐ": {"uh", true}, "ɨ": {"ih", true}, "y": {"ue", true},
+	"ø": {"er", true}, "œ": {"er", true},
+	// Consonants
+	"tʃ": {"ch", false}, "dʒ": {"j", false}, "ʧ": {"ch", false}, "ʤ": {"j", false},
+	"p": {"p", false}, "b": {"b", false}, "t": {"t", false}, "d": {"d", false},
+	"k": {"k", false}, "g": {"g", false}, "ɡ": {"g", false}, "f": {"f", false},
+	"v": {"v", false}, "θ": {"th", false}, "ð": {"dh", false}, "s": {"s", false},
+	"z": {"z", false}, "ʃ": {"sh", false}, "ʒ": {"zh", false}, "h": {"h", false},
+	"m": {"m", false}, "n": {"n", false}, "ŋ": {"ng", false}, "l": {"l", false},
+	"ɫ": {"l", false}, "r": {"r", false}, "ɹ": {"r", false}, "ɾ": {"d", false},
+	"j": {"y", false}, "w": {"w", false}, "ʍ": {"wh", false}, "x": {"kh", false},
+	"ʔ": {"", false},
+}
+
+// RespellIPA converts an IPA transcription, such as "ˈnaɪki", to the
+// English respelling a voice reads the same way, such as "ny-kee", with a
+// hyphen between syllables. Stress, length, and diacritic marks only
+// separate syllables. It fails on symbols it does not know, with an error
+// that shows them through RedactHash.
+func RespellIPA(ipa string) (string, error) {
+	var words []string
+	for _, word := range strings.Fields(strings.Trim(ipa, "/[]")) {
+		var syllables []string
+		for _, group := range strings.FieldsFunc(word, func(r rune) bool {
+			return r == 'ˈ' || r == 'ˌ' || r == '.' || r == '‿'
+		}) {
+			phones, err := ipaSplit(group)
+			if err != nil {
+				return "", err
+			}
+			syllables = append(syllables, ipaSyllables(phones)...)
+		}
+		if len(syllables) > 0 {
+			words = append(words, strings.Join(syllables, "-"))
+		}
+	}
+	if len(words) == 0 {
+		return "", fmt.Errorf("IPA %s has no sounds", RedactHash(ipa))
+	}
+	return strings.Join(words, " "), nil
+}
+
+// ipaSplit reads the phones of an IPA group, matching digraphs first.
+func ipaSplit(group string) ([]ipaPhone, error) {
+	group = strings.Map(func(r rune) rune {
+		if r == 'ː' || r == 'ˑ' || unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, group)
+	var phones []ipaPhone
+	for group != "" {
+		first, size := utf8.DecodeRuneInString(group)
+		if second, next := utf8.DecodeRuneInString(group[size:]); next > 0 {
+			phone, ok := ipaPhones[group[:size+next]]
+			// An r before a vowel starts the next syllable instead of
+			// coloring the vowel before it, as in "ˈvɛri".
+			if following, _ := utf8.DecodeRuneInString(group[size+next:]); second == 'r' && ipaPhones[string(following)].vowel {
+				ok = false
+			}
+			if ok {
+				phones = append(phones, phone)
+				group = group[size+next:]
+				continue
+			}
+		}
+		phone, ok := ipaPhones[group[:size]]
+		if !ok {
+			return nil, fmt.Errorf("unsupported IPA symbol %s", RedactHash(string(first)))
+		}
+		phones = append(phones, phone)
+		group = group[size:]
+	}
+	return phones, nil
+}
+
+// ipaSyllables respells phones as syllables, one per vowel, giving the
+// last consonant between two vowels to the second syllable.
+func ipaSyllables(phones []ipaPhone) []string {
+	var bounds []int
+	lastVowel := -1
+	for i, phone := range phones {
+		if !phone.vowel {
+			continue
+		}
+		if lastVowel >= 0 {
+			if i-lastVowel > 1 {
+				bounds = append(bounds, i-1)
+			} else {
+				bounds = append(bounds, i)
+			}
+		}
+		lastVowel = i
+	}
+	bounds = append(bounds, len(phones))
+	var syllables []string
+	start := 0
+	for _, end := range bounds {
+		var b strings.Builder
+		for i := start; i < end; i++ {
+			spelling := phones[i].spelling
+			// "eye" reads as a syllable of its own; after a consonant it is
+			// "y" at the end of the syllable and "igh" before another one.
+			if spelling == "eye" && i > start {
+				if i == end-1 {
+					spelling = "y"
+				} else {
+					spelling = "igh"
+				}
+			}
+			b.WriteString(spelling)
+		}
+		if b.Len() > 0 {
+			syllables = append(syllables, b.String())
+		}
+		start = end
+	}
+	return syllables
+}
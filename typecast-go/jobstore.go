@@ -0,0 +1,192 @@
+package typecast
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// JobStore persists the completed segments of long-running jobs so that a
+// crashed process can resume instead of starting over. Segments absent from
+// the store are pending. Implementations must be safe for concurrent use.
+type JobStore interface {
+	// Load returns the completed segments recorded for jobID.
+	Load(jobID string) ([]JobSegment, error)
+	// Save records segment as completed for jobID, replacing any earlier
+	// record with the same Index.
+	Save(jobID string, segment JobSegment) error
+}
+
+// JobSegment is one completed unit of work in a JobStore.
+type JobSegment struct {
+	// Index is the segment's position in the job
+	Index int `json:"index"`
+	// Key is a hash of the request that produced the segment; a stored
+	// segment is only reused when the request is unchanged
+	Key string `json:"key"`
+	// Duration is the audio duration in seconds
+	Duration float64 `json:"duration"`
+	// Format is the audio format
+	Format AudioFormat `json:"format"`
+	// Audio is the segment's audio
+	Audio []byte `json:"-"`
+}
+
+// response rebuilds the TTSResponse the segment was saved from.
+func (s JobSegment) response() *TTSResponse {
+	return &TTSResponse{AudioData: s.Audio, Duration: s.Duration, Format: s.Format}
+}
+
+// requestKey hashes a request so stored segments can be matched to it.
+func requestKey(request *TTSRequest) string {
+	b, _ := json.Marshal(request)
+	return sha256Hex(b)
+}
+
+// loadJob indexes the completed segments of jobID, or returns nil without a
+// store.
+func loadJob(store JobStore, jobID string) (map[int]JobSegment, error) {
+	if store == nil {
+		return nil, nil
+	}
+	segments, err := store.Load(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job %s: %w", jobID, err)
+	}
+	done := make(map[int]JobSegment, len(segments))
+	for _, segment := range segments {
+		done[segment.Index] = segment
+	}
+	return done, nil
+}
+
+// MemoryJobStore is a JobStore held in memory, for tests and for resuming
+// within a single process.
+type MemoryJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]map[int]JobSegment
+}
+
+// NewMemoryJobStore creates an empty MemoryJobStore.
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{jobs: map[string]map[int]JobSegment{}}
+}
+
+// Load returns the completed segments of jobID.
+func (s *MemoryJobStore) Load(jobID string) ([]JobSegment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	segments := make([]JobSegment, 0, len(s.jobs[jobID]))
+	for _, segment := range s.jobs[jobID] {
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// Save records a completed segment of jobID.
+func (s *MemoryJobStore) Save(jobID string, segment JobSegment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.jobs[jobID] == nil {
+		s.jobs[jobID] = map[int]JobSegment{}
+	}
+	s.jobs[jobID][segment.Index] = segment
+	return nil
+}
+
+// DirJobStore is a JobStore that keeps each job in a directory: one audio
+// file per segment and a segments.jsonl log appended after the audio is
+// safely on disk, so a crash never records a segment without its audio.
+type DirJobStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewDirJobStore creates a DirJobStore rooted at dir. Directories are
+// created on first Save.
+func NewDirJobStore(dir string) *DirJobStore {
+	return &DirJobStore{dir: dir}
+}
+
+// Load returns the completed segments of jobID. Log entries whose audio file
+// is missing are ignored, and later entries win over earlier ones.
+func (s *DirJobStore) Load(jobID string) ([]JobSegment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.Open(filepath.Join(s.jobDir(jobID), "segments.jsonl"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	latest := map[int]JobSegment{}
+	var order []int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var segment JobSegment
+		if err := json.Unmarshal(scanner.Bytes(), &segment); err != nil {
+			continue // a line torn by a crash
+		}
+		if _, seen := latest[segment.Index]; !seen {
+			order = append(order, segment.Index)
+		}
+		latest[segment.Index] = segment
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	segments := make([]JobSegment, 0, len(order))
+	for _, index := range order {
+		segment := latest[index]
+		audio, err := os.ReadFile(s.audioPath(jobID, index))
+		if err != nil {
+			continue
+		}
+		segment.Audio = audio
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// Save writes the segment's audio and then records it in the job's log.
+func (s *DirJobStore) Save(jobID string, segment JobSegment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dir := s.jobDir(jobID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.audioPath(jobID, segment.Index), segment.Audio); err != nil {
+		return err
+	}
+	line, _ := json.Marshal(segment)
+	log, err := os.OpenFile(filepath.Join(dir, "segments.jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = log.Write(append(line, '\n'))
+	if err == nil {
+		err = log.Sync()
+	}
+	if closeErr := log.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (s *DirJobStore) jobDir(jobID string) string {
+	return filepath.Join(s.dir, url.PathEscape(jobID))
+}
+
+func (s *DirJobStore) audioPath(jobID string, index int) string {
+	return filepath.Join(s.jobDir(jobID), "segment-"+strconv.Itoa(index)+".audio")
+}
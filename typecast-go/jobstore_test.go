@@ -0,0 +1,256 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// failingJobStore fails every Load or Save.
+type failingJobStore struct{ loadErr, saveErr error }
+
+func (s failingJobStore) Load(string) ([]JobSegment, error) { return nil, s.loadErr }
+func (s failingJobStore) Save(string, JobSegment) error     { return s.saveErr }
+
+func TestDirJobStore_RoundTrip(t *testing.T) {
+	store := NewDirJobStore(t.TempDir())
+	if segments, err := store.Load("book/1"); err != nil || len(segments) != 0 {
+		t.Fatalf("Load() on empty store = %v, %v", segments, err)
+	}
+	for _, segment := range []JobSegment{
+		{Index: 0, Key: "k0", Duration: 1, Format: AudioFormatWAV, Audio: []byte("zero")},
+		{Index: 1, Key: "old", Audio: []byte("stale")},
+		{Index: 1, Key: "k1", Duration: 2, Format: AudioFormatMP3, Audio: []byte("one")},
+	} {
+		if err := store.Save("book/1", segment); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	segments, err := store.Load("book/1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(segments) != 2 || segments[0].Key != "k0" || string(segments[0].Audio) != "zero" {
+		t.Fatalf("unexpected segments: %+v", segments)
+	}
+	if segments[1].Key != "k1" || string(segments[1].Audio) != "one" || segments[1].Format != AudioFormatMP3 || segments[1].Duration != 2 {
+		t.Fatalf("later records must win: %+v", segments[1])
+	}
+	if other, _ := store.Load("book/2"); len(other) != 0 {
+		t.Fatalf("jobs must be isolated: %+v", other)
+	}
+}
+
+func TestDirJobStore_SurvivesTornLogAndMissingAudio(t *testing.T) {
+	dir := t.TempDir()
+	store := NewDirJobStore(dir)
+	_ = store.Save("job", JobSegment{Index: 0, Key: "k0", Audio: []byte("a")})
+	_ = store.Save("job", JobSegment{Index: 1, Key: "k1", Audio: []byte("b")})
+	jobDir := filepath.Join(dir, "job")
+	if err := os.Remove(filepath.Join(jobDir, "segment-1.audio")); err != nil {
+		t.Fatal(err)
+	}
+	log, _ := os.OpenFile(filepath.Join(jobDir, "segments.jsonl"), os.O_APPEND|os.O_WRONLY, 0644)
+	_, _ = log.WriteString(`{"index":2,"ke`)
+	log.Close()
+
+	segments, err := store.Load("job")
+	if err != nil || len(segments) != 1 || segments[0].Index != 0 {
+		t.Fatalf("Load() = %+v, %v", segments, err)
+	}
+}
+
+func TestDirJobStore_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := NewDirJobStore(dir)
+	if _, err := store.Load("file"); err == nil {
+		t.Fatal("expected Load error when the job path is a file")
+	}
+	if err := store.Save("file", JobSegment{}); err == nil {
+		t.Fatal("expected Save error when the job path is a file")
+	}
+
+	// A directory in place of the log makes reading it fail.
+	if err := os.MkdirAll(filepath.Join(dir, "logdir", "segments.jsonl"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load("logdir"); err == nil {
+		t.Fatal("expected Load error when the log is a directory")
+	}
+	if err := store.Save("logdir", JobSegment{Audio: []byte("a")}); err == nil {
+		t.Fatal("expected Save error when the log is a directory")
+	}
+
+	// Directories in place of the audio files make writing them fail.
+	if err := os.MkdirAll(filepath.Join(dir, "tmp", "segment-0.audio.tmp"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save("tmp", JobSegment{}); err == nil {
+		t.Fatal("expected Save error when the temporary file cannot be written")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "rename", "segment-0.audio", "x"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save("rename", JobSegment{}); err == nil {
+		t.Fatal("expected Save error when the audio file cannot be replaced")
+	}
+}
+
+func TestMemoryJobStore(t *testing.T) {
+	store := NewMemoryJobStore()
+	_ = store.Save("job", JobSegment{Index: 1, Key: "b"})
+	_ = store.Save("job", JobSegment{Index: 0, Key: "a"})
+	segments, _ := store.Load("job")
+	sort.Slice(segments, func(i, j int) bool { return segments[i].Index < segments[j].Index })
+	if len(segments) != 2 || segments[0].Key != "a" || segments[1].Key != "b" {
+		t.Fatalf("unexpected segments: %+v", segments)
+	}
+}
+
+// countingTTSServer returns a WAV for every request and counts the texts.
+func countingTTSServer(fail string) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var texts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		texts = append(texts, body.Text)
+		mu.Unlock()
+		if body.Text == fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		w.Header().Set("X-Audio-Duration", "0.1")
+		_, _ = w.Write(makeTestWAV(make([]byte, 1600), 8000))
+	}))
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		sorted := append([]string(nil), texts...)
+		sort.Strings(sorted)
+		return sorted
+	}
+}
+
+func TestLongFormSynthesize_ResumesFromStore(t *testing.T) {
+	store := NewDirJobStore(t.TempDir())
+	request := LongFormRequest{
+		Profile:       VoiceProfile{VoiceID: "v"},
+		Text:          "One. Two. Three.",
+		MaxChunkChars: 6,
+		Store:         store,
+		JobID:         "book",
+	}
+
+	crashing, texts := countingTTSServer("Three.")
+	_, err := newTestClient(crashing, "k").LongFormSynthesize(context.Background(), request)
+	crashing.Close()
+	if err == nil || !strings.Contains(err.Error(), "segment 2") {
+		t.Fatalf("expected segment 2 failure, got %v", err)
+	}
+	if strings.Join(texts(), "|") != "One.|Three.|Two." {
+		t.Fatalf("first run texts = %q", texts())
+	}
+
+	srv, texts := countingTTSServer("")
+	defer srv.Close()
+	result, err := newTestClient(srv, "k").LongFormSynthesize(context.Background(), request)
+	if err != nil {
+		t.Fatalf("LongFormSynthesize() error = %v", err)
+	}
+	if strings.Join(texts(), "|") != "Three." {
+		t.Fatalf("rerun must only synthesize the missing segment, got %q", texts())
+	}
+	if len(result.Segments) != 3 || !approx(result.Duration, 0.3) {
+		t.Fatalf("unexpected result: %+v", result.Segments)
+	}
+
+	// A changed profile invalidates the stored segments.
+	request.Profile.VoiceID = "other"
+	if _, err := newTestClient(srv, "k").LongFormSynthesize(context.Background(), request); err != nil {
+		t.Fatalf("LongFormSynthesize() error = %v", err)
+	}
+	if len(texts()) != 4 {
+		t.Fatalf("expected every segment to be resynthesized, got %q", texts())
+	}
+}
+
+func TestLongFormSynthesize_StoreErrors(t *testing.T) {
+	srv, _ := countingTTSServer("")
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	request := LongFormRequest{Profile: VoiceProfile{VoiceID: "v"}, Text: "One.", Store: NewMemoryJobStore()}
+
+	var validation *ValidationError
+	if _, err := c.LongFormSynthesize(context.Background(), request); !errors.As(err, &validation) || validation.Field != "job_id" {
+		t.Fatalf("expected job_id ValidationError, got %v", err)
+	}
+	request.JobID = "job"
+	request.Store = failingJobStore{loadErr: errors.New("disk gone")}
+	if _, err := c.LongFormSynthesize(context.Background(), request); err == nil || !strings.Contains(err.Error(), "failed to load job job: disk gone") {
+		t.Fatalf("expected load error, got %v", err)
+	}
+	request.Store = failingJobStore{saveErr: errors.New("disk full")}
+	if _, err := c.LongFormSynthesize(context.Background(), request); err == nil || !strings.Contains(err.Error(), "failed to save segment: disk full") {
+		t.Fatalf("expected save error, got %v", err)
+	}
+}
+
+func TestRunBatch_ResumesFromStore(t *testing.T) {
+	store := NewMemoryJobStore()
+	opts := &BatchOptions{Store: store, JobID: "batch"}
+	items := batchItems("a", "fail", "c")
+
+	first, _ := countingTTSServer("fail")
+	results := newTestClient(first, "k").RunBatch(context.Background(), items, opts)
+	first.Close()
+	if results[1].Err == nil || results[0].Err != nil || results[0].Resumed {
+		t.Fatalf("unexpected first run: %+v", results)
+	}
+
+	srv, texts := countingTTSServer("")
+	defer srv.Close()
+	handled := 0
+	opts.Handle = func(ctx context.Context, item BatchItem, resp *TTSResponse) error {
+		handled++
+		return nil
+	}
+	opts.Workers = 1
+	results = newTestClient(srv, "k").RunBatch(context.Background(), items, opts)
+	if strings.Join(texts(), "|") != "fail" || handled != 1 {
+		t.Fatalf("rerun must only process the failed item, got %q (handled %d)", texts(), handled)
+	}
+	if !results[0].Resumed || !results[2].Resumed || results[1].Resumed || results[0].Response.Duration != 0.1 {
+		t.Fatalf("unexpected rerun results: %+v", results)
+	}
+}
+
+func TestRunBatch_StoreErrors(t *testing.T) {
+	srv, _ := countingTTSServer("")
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+
+	results := c.RunBatch(context.Background(), batchItems("a"), &BatchOptions{Store: failingJobStore{loadErr: errors.New("gone")}})
+	if results[0].Err == nil || !strings.Contains(results[0].Err.Error(), "failed to load job") {
+		t.Fatalf("expected load error, got %+v", results[0])
+	}
+	results = c.RunBatch(context.Background(), batchItems("a"), &BatchOptions{Store: failingJobStore{saveErr: errors.New("full")}})
+	if results[0].Err == nil || !strings.Contains(results[0].Err.Error(), "failed to save item: full") {
+		t.Fatalf("expected save error, got %+v", results[0])
+	}
+}
@@ -0,0 +1,291 @@
+package typecast
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// koreanParticles pairs the forms of each Korean particle (조사) that
+// depends on the word before it: the form after a final consonant
+// (받침), then the form after a vowel. 으로 and 로 also differ after ㄹ,
+// which takes 로.
+var koreanParticles = [][2]string{
+	{"은", "는"}, {"이", "가"}, {"을", "를"}, {"과", "와"}, {"으로", "로"},
+	{"이나", "나"}, {"이랑", "랑"}, {"아", "야"},
+}
+
+var (
+	// dualParticle matches the parenthesized form of a particle written
+	// with both forms, as in "은(는)" or "(이)라고"; see dualForms.
+	dualParticle = regexp.MustCompile(`\((으로|이나|이랑|[은는이가을를과와로아야나랑으])\)`)
+	// templateField matches a template field such as "{name}".
+	templateField = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	// leadingParticle matches the particle, written in either form, at the
+	// start of the text after a template field.
+	leadingParticle = regexp.MustCompile(`^(?:으로|이나|이랑|[은는이가을를과와로아야]|나|랑)`)
+)
+
+// AdjustKoreanParticles resolves the Korean particles written with both
+// forms, as templated text does ("{name}은(는)", "{item}을(를)",
+// "(이)라고"), to the form that fits the word before them: "철수는",
+// "책을", "서울로". Numbers and Latin words are judged by how they are
+// read, so "3은(는)" becomes "3은" (삼은) and "API을(를)" becomes
+// "API를". A particle after anything else keeps its first form.
+func AdjustKoreanParticles(text string) string {
+	if !strings.Contains(text, "(") {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range dualParticle.FindAllStringSubmatchIndex(text, -1) {
+		second := text[m[2]:m[3]]
+		first, optional, ok := dualForms(text, m)
+		if !ok || m[0]-len(first) < last {
+			continue
+		}
+		start := m[0] - len(first)
+		final, known := finalSound(text[:start])
+		var particle string
+		switch {
+		case optional:
+			// (이) and (으) are kept after a final consonant, except (으)
+			// after ㄹ, as in "서울로".
+			if !known || final != 0 && !(final == 'ㄹ' && second == "으") {
+				particle = second
+			}
+		case known:
+			pair, _ := particlePair(first, second)
+			particle = pickParticle(pair, final)
+		default:
+			particle = first
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(particle)
+		last = m[1]
+	}
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// particleForms lists the forms a particle written with both forms can
+// start with, longest first.
+var particleForms = []string{"으로", "이나", "이랑", "은", "는", "이", "가", "을", "를", "과", "와", "로", "아", "야", "나", "랑"}
+
+// dualForms reads the particle whose parenthesized form dualParticle
+// matched at loc in text. Either it is an optional (이) or (으) that
+// starts the particle after it, as in "사과(이)라고" or "집(으)로", or the
+// form before the parenthesis is the particle's other form, as in
+// "은(는)", which it returns. It reports false for anything else, such as
+// "이(는)".
+func dualForms(text string, loc []int) (first string, optional bool, ok bool) {
+	second := text[loc[2]:loc[3]]
+	// An optional (이) is followed by the rest of its particle, while a
+	// particle written with both forms, such as "가(이)", ends there. A
+	// noun's last syllable, as in "사과(이)다", is not a particle form.
+	next, _ := utf8.DecodeRuneInString(text[loc[1]:])
+	isOptional := second == "이" || second == "으"
+	if isOptional && isHangulSyllable(next) {
+		return "", true, true
+	}
+	for _, form := range particleForms {
+		if strings.HasSuffix(text[:loc[0]], form) {
+			if _, ok := particlePair(form, second); ok {
+				return form, false, true
+			}
+		}
+	}
+	return "", isOptional, isOptional
+}
+
+// FillKoreanTemplate replaces the fields of template, such as "{name}",
+// with their values and makes the particle after each field fit its
+// value, whether it is written in one form or both: with "철수" as name,
+// "{name}이 {item}을(를) 샀다" becomes "철수가 ...". The particles
+// elsewhere are resolved as by AdjustKoreanParticles. It returns an error
+// naming a field that has no value.
+func FillKoreanTemplate(template string, values map[string]string) (string, error) {
+	var b strings.Builder
+	last := 0
+	for _, loc := range templateField.FindAllStringSubmatchIndex(template, -1) {
+		name := template[loc[2]:loc[3]]
+		value, ok := values[name]
+		if !ok {
+			return "", fmt.Errorf("template field {%s} has no value", name)
+		}
+		b.WriteString(template[last:loc[0]])
+		b.WriteString(value)
+		last = loc[1]
+		rest := template[last:]
+		if loc := dualParticle.FindStringSubmatchIndex(rest); loc != nil {
+			if first, _, ok := dualForms(rest, loc); ok && loc[0] == len(first) {
+				// Both forms are resolved with the rest of the text.
+				continue
+			}
+		}
+		particle := leadingParticle.FindString(rest)
+		final, known := finalSound(value)
+		if particle == "" || !known || !endsParticle(rest[len(particle):]) {
+			continue
+		}
+		for _, pair := range koreanParticles {
+			if particle == pair[0] || particle == pair[1] {
+				b.WriteString(pickParticle(pair, final))
+				last += len(particle)
+				break
+			}
+		}
+	}
+	b.WriteString(template[last:])
+	return AdjustKoreanParticles(b.String()), nil
+}
+
+// endsParticle reports whether rest, the text after a particle, ends it:
+// anything but Hangul, or a particle that can follow, as in "과의" or
+// "으로는", so "{name}가족" is left alone.
+func endsParticle(rest string) bool {
+	r, _ := utf8.DecodeRuneInString(rest)
+	return !isHangulSyllable(r) || strings.ContainsRune("는도의만요", r)
+}
+
+func isHangulSyllable(r rune) bool {
+	return r >= hangulSBase && r < hangulSBase+hangulSCount
+}
+
+// particlePair returns the pair whose two forms are a and b.
+func particlePair(a, b string) ([2]string, bool) {
+	for _, pair := range koreanParticles {
+		if a == pair[0] && b == pair[1] || a == pair[1] && b == pair[0] {
+			return pair, true
+		}
+	}
+	return [2]string{}, false
+}
+
+// pickParticle returns the form of pair that follows a word whose final
+// consonant is final, or 0 after a vowel.
+func pickParticle(pair [2]string, final rune) string {
+	if final == 0 || final == 'ㄹ' && pair[0] == "으로" {
+		return pair[1]
+	}
+	return pair[0]
+}
+
+// latinLetterFinals holds the final consonant of the Korean names of the
+// Latin letters that end in one, such as 엘 for L.
+var latinLetterFinals = map[byte]rune{'L': 'ㄹ', 'M': 'ㅁ', 'N': 'ㄴ', 'R': 'ㄹ'}
+
+// finalSound returns the final consonant of the word at the end of text,
+// as a compatibility jamo such as 'ㄴ', or 0 when it ends in a vowel. It
+// reports false when text does not end in a word it can judge. Closing
+// quotes and brackets are skipped.
+func finalSound(text string) (rune, bool) {
+	text = strings.TrimRight(text, "\"'”’)]」』")
+	r, _ := utf8.DecodeLastRuneInString(text)
+	switch {
+	case isHangulSyllable(r):
+		t := (r - hangulSBase) % hangulTCount
+		if t == 0 {
+			return 0, true
+		}
+		return hangulFinals[t-1], true
+	case r >= '0' && r <= '9':
+		number := text[len(strings.TrimRightFunc(text, func(r rune) bool { return r >= '0' && r <= '9' || r == ',' })):]
+		return numberFinal(strings.ReplaceAll(number, ",", "")), true
+	case r < utf8.RuneSelf && unicode.IsLetter(r):
+		return latinFinal(text), true
+	}
+	return 0, false
+}
+
+// hangulFinals lists the compatibility jamo of the final consonants, in
+// the order of the Unicode composition, as the sound each is read with.
+var hangulFinals = []rune("ㄱㄱㄱㄴㄴㄴㄷㄹㄹㄹㄹㄹㄹㄹㄹㅁㅂㅂㅅㅅㅇㅈㅊㅋㅌㅍㅎ")
+
+// numberFinal returns the final sound of digits read in Sino-Korean: 일
+// and 팔 end in ㄹ, 이 and 사 in a vowel, and 십, 백, 천, 만, and 억 in
+// a consonant.
+func numberFinal(digits string) rune {
+	trimmed := strings.TrimLeft(strings.TrimRight(digits, "0"), "0")
+	zeros := len(digits) - len(strings.TrimRight(digits, "0"))
+	switch {
+	case trimmed == "":
+		return 'ㅇ' // 영
+	case zeros == 0:
+		return []rune{0, 'ㄹ', 0, 'ㅁ', 0, 0, 'ㄱ', 'ㄹ', 'ㄹ', 0}[trimmed[len(trimmed)-1]-'0']
+	case zeros == 12:
+		return 0 // 조
+	}
+	return 'ㄴ'
+}
+
+// latinFinal returns the final sound of the Latin word ending text as it
+// is read in Korean: acronyms by the name of their last letter, such as
+// 엠 for "CRM", and other words by their spelling, such as 구글 for
+// "Google" and 애플 for "Apple".
+func latinFinal(text string) rune {
+	start := len(text)
+	for start > 0 && text[start-1] < utf8.RuneSelf && unicode.IsLetter(rune(text[start-1])) {
+		start--
+	}
+	word := text[start:]
+	if strings.ToUpper(word) == word {
+		return latinLetterFinals[word[len(word)-1]]
+	}
+	word = strings.ToLower(word)
+	switch {
+	case strings.HasSuffix(word, "ng"):
+		return 'ㅇ'
+	case strings.HasSuffix(word, "m"):
+		return 'ㅁ'
+	case strings.HasSuffix(word, "n"):
+		return 'ㄴ'
+	case strings.HasSuffix(word, "l"), strings.HasSuffix(word, "le") && len(word) > 2 && !strings.ContainsRune("aeiou", rune(word[len(word)-3])):
+		return 'ㄹ'
+	case strings.HasSuffix(word, "k"):
+		return 'ㄱ'
+	}
+	return 0
+}
+
+// koreanClauseEndings are the connective endings (연결 어미) a long
+// Korean sentence is best split after, as a speaker pauses there.
+var koreanClauseEndings = []string{
+	"면서", "지만", "는데", "은데", "인데", "니까", "으니", "어서", "아서", "해서",
+	"므로", "거나", "도록", "려고", "고", "며",
+}
+
+// koreanClauseBreak returns the index in s of the last space after a
+// Korean clause, a Hangul word followed by a comma or ending in a
+// connective ending such as "-지만" (the conjunction 그리고 comes
+// before a clause instead), in the second half of s, or -1 when
+// there is none.
+func koreanClauseBreak(s string) int {
+	for i := len(s) - 1; i > len(s)/2; i-- {
+		if s[i] != ' ' {
+			continue
+		}
+		word := strings.TrimRight(s[:i], " ")
+		comma := strings.HasSuffix(word, ",")
+		word = strings.TrimSuffix(word, ",")
+		clause := hasAnySuffix(word, koreanClauseEndings) && !strings.HasSuffix(word, "그리고")
+		if r, _ := utf8.DecodeLastRuneInString(word); isHangulSyllable(r) && (comma || clause) {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasAnySuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAdjustKoreanParticles(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"철수은(는) 책을(를) 샀다", "철수는 책을 샀다"},
+		{"영희이(가) 사과를(을) 먹고 학교와(과) 집으로(로)", "영희가 사과를 먹고 학교와 집으로"},
+		{"서울으로(로) 가요, 부산(으)로 가요, 집(으)로 가요", "서울로 가요, 부산으로 가요, 집으로 가요"},
+		{"민수(이)라고 하고 지민(이)라고 해요", "민수라고 하고 지민이라고 해요"},
+		{"'철수'은(는)", "'철수'는"},
+		{"3은(는) 2은(는) 10은(는) 1,000이(가) 0을(를) 7로(으로) 6으로(로)", "3은 2는 10은 1,000이 0을 7로 6으로"},
+		{"API을(를) CRM은(는) URL으로(로)", "API를 CRM은 URL로"},
+		{"Google을(를) Nike을(를) Facebook이(가) Apple과(와) Ring은(는) Team이(가) Don이(가)", "Google을 Nike를 Facebook이 Apple과 Ring은 Team이 Don이"},
+		{"Node은(는) 1000000000000이(가)", "Node는 1000000000000가"},
+		{"(이)라고 - 은(는)", "이라고 - 은"},
+		{"이(는) 괄호 (없음)", "이(는) 괄호 (없음)"},
+		// A noun's last syllable is not taken for the first form.
+		{"사과(이)다, 요가(이)다, 코로나(이)라고, 나(이)라고", "사과다, 요가다, 코로나라고, 나라고"},
+		{"아이(이)라고, 고양이(이)다, 책(이)다", "아이라고, 고양이다, 책이다"},
+		{"철수가(이) 왔다, 사과와(과) 배", "철수가 왔다, 사과와 배"},
+		{"no particles", "no particles"},
+	}
+	for _, tt := range tests {
+		if got := AdjustKoreanParticles(tt.in); got != tt.want {
+			t.Errorf("AdjustKoreanParticles(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFillKoreanTemplate(t *testing.T) {
+	template := "{name}이 {item}을(를) 샀고 {place}으로 갔어요. {name}과의 약속, {brand}가족"
+	got, err := FillKoreanTemplate(template, map[string]string{"name": "철수", "item": "책", "place": "서울", "brand": "Nike"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "철수가 책을 샀고 서울로 갔어요. 철수와의 약속, Nike가족"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	got, _ = FillKoreanTemplate("{name}는 {x}은", map[string]string{"name": "지민", "x": "..."})
+	if got != "지민은 ...은" {
+		t.Fatalf("got %q", got)
+	}
+	got, _ = FillKoreanTemplate("{name}(이)라고 불러", map[string]string{"name": "사과"})
+	if got != "사과라고 불러" {
+		t.Fatalf("got %q", got)
+	}
+	if _, err := FillKoreanTemplate("{name}은", nil); err == nil || !strings.Contains(err.Error(), "{name}") {
+		t.Fatalf("expected a missing field error, got %v", err)
+	}
+}
+
+func TestSplitText_KoreanClauses(t *testing.T) {
+	text := "오늘은 날씨가 좋아서 공원에 갔지만 사람이 너무 많아서 금방 돌아왔습니다"
+	got := splitText(text, 30)
+	want := []string{"오늘은 날씨가 좋아서 공원에 갔지만", "사람이 너무 많아서 금방 돌아왔습니다"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("splitText() = %q, want %q", got, want)
+	}
+	got = splitText("시장에는 사과와 배 그리고 포도, 귤과 감이 있습니다", 22)
+	want = []string{"시장에는 사과와 배 그리고 포도,", "귤과 감이 있습니다"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("splitText() = %q, want %q", got, want)
+	}
+	got = splitText("사과와 배와 감 그리고 포도가 있습니다", 16)
+	want = []string{"사과와 배와 감 그리고", "포도가 있습니다"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("splitText() = %q, want %q", got, want)
+	}
+}
+
+func TestKoreanParticlesInRequests(t *testing.T) {
+	var text string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		text = body.Text
+		_, _ = w.Write([]byte("RIFF"))
+	}))
+	defer srv.Close()
+	// Particles are only resolved when the client asks for it.
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL})
+	if _, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "철수은(는) 왔어요", Model: ModelSSFMV30}); err != nil {
+		t.Fatal(err)
+	}
+	if text != "철수은(는) 왔어요" {
+		t.Fatalf("sent %q", text)
+	}
+	c = NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, AdjustKoreanParticles: true})
+	if _, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "철수은(는) 왔어요", Model: ModelSSFMV30}); err != nil {
+		t.Fatal(err)
+	}
+	if text != "철수는 왔어요" {
+		t.Fatalf("sent %q", text)
+	}
+}
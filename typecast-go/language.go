@@ -0,0 +1,239 @@
+package typecast
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// LanguageDetection decides whether the client detects the language of
+// request text itself before sending it. The API detects an omitted
+// Language too, but can pick the wrong accent for short or ambiguous text.
+type LanguageDetection string
+
+const (
+	// LanguageDetectionOff leaves the language to the request and the API.
+	// It is the default.
+	LanguageDetectionOff LanguageDetection = ""
+	// LanguageDetectionSet sets an empty Language to the language
+	// DetectLanguage finds, if any. A Language set by the caller is kept.
+	LanguageDetectionSet LanguageDetection = "set"
+	// LanguageDetectionWarn sends requests unchanged, but logs a warning to
+	// the client's Logger when DetectLanguage finds a language other than
+	// the request's Language, or one the voice does not list. The voice's
+	// languages are looked up with GetVoiceV2 once per voice.
+	LanguageDetectionWarn LanguageDetection = "warn"
+)
+
+// languageScripts maps writing systems to the language DetectLanguage
+// reports for them. Latin and Cyrillic text is told apart further by
+// latinLanguage and cyrillicLanguage.
+var languageScripts = []struct {
+	script   *unicode.RangeTable
+	language string
+}{
+	{unicode.Hangul, "kor"},
+	{unicode.Hiragana, "jpn"},
+	{unicode.Katakana, "jpn"},
+	{unicode.Han, "zho"},
+	{unicode.Thai, "tha"},
+	{unicode.Devanagari, "hin"},
+	{unicode.Bengali, "ben"},
+	{unicode.Gurmukhi, "pan"},
+	{unicode.Tamil, "tam"},
+	{unicode.Greek, "ell"},
+	{unicode.Arabic, "ara"},
+	{unicode.Cyrillic, "cyrillic"},
+	{unicode.Latin, "latin"},
+}
+
+// latinLanguages holds, for each language written in the Latin script, the
+// letters and frequent words that suggest it. Letters and words may be
+// shared; the language with the most matches wins.
+var latinLanguages = []struct {
+	language string
+	letters  string
+	words    string
+}{
+	{"eng", "", "the and is are was were of to in that it you this with for not have be on at what my your we they he she"},
+	{"spa", "ñ¿¡áíóú", "el la los las de que y en es un una por con para no se del al lo como pero más está son muy"},
+	{"fra", "œùûëïçèéêâ", "le la les de des et est un une du que qui en pas pour dans ce il elle je vous nous avec sur au ne c'est"},
+	{"deu", "äöüß", "der die das und ist nicht ich du sie ein eine zu mit den dem von es auf für auch wir"},
+	{"ita", "ìòèà", "il lo la gli le di che e è un una per non sono con del della questo ma anche mi ti"},
+	{"por", "ãõçáéêó", "o a os as de que e é um uma não para com do da em no na você eu ele mas são"},
+	{"nld", "", "de het een en van is niet dat ik je we zijn met voor op te dit maar ook"},
+	{"pol", "ąęłńśźżó", "i w na nie się to jest że z do jak co tak ale od"},
+	{"ces", "řůěčšžý", "a je se na to že v nebo jsem jak ale co do není"},
+	{"slk", "ľĺŕôäčšž", "a je sa na to že v alebo som ako ale čo do nie"},
+	{"hrv", "đćčšž", "i je u na da se to su ne za od što kao ali"},
+	{"ron", "ășțâî", "și de la în cu pe nu este un o că sunt mai din"},
+	{"hun", "őűáéö", "a az és egy hogy nem is van meg de ez"},
+	{"fin", "äö", "ja on ei se että hän mutta kun ovat minä sinä tämä"},
+	{"swe", "åäö", "och är att det en som på inte jag med för av till har"},
+	{"dan", "æøå", "og er at det en som på ikke jeg med for af til har"},
+	{"nor", "æøå", "og er at det en som på ikke jeg med for av til har"},
+	{"tur", "ğışçöü", "ve bir bu da de için ile ne çok değil ben sen var"},
+	{"vie", "ăđơưạảấầẩẫậắằẳẵặẹẻẽếềểễệỉịọỏốồổỗộớờởỡợụủứừửữựỳỵỷỹ", "và là của có không một những người tôi được cho này"},
+	{"ind", "", "dan yang di ini itu tidak dengan untuk saya ada dari ke akan bisa karena sudah"},
+	{"msa", "", "dan yang di ini itu tidak dengan untuk saya ada dari ke akan boleh kerana sudah"},
+	{"tgl", "", "ang ng mga sa na at ay ako ka siya hindi ito"},
+}
+
+// DetectLanguage returns the ISO 639-3 code of the language text is most
+// likely written in, or "" when it cannot tell, as for text without
+// letters or a short Latin phrase without telling words. The script
+// decides most languages; Japanese is told from Chinese by its kana, and
+// Latin and Cyrillic text by telling letters and frequent words.
+func DetectLanguage(text string) string {
+	switch script := dominantScript(text); script {
+	case "latin":
+		return latinLanguage(strings.ToLower(text))
+	case "cyrillic":
+		return cyrillicLanguage(strings.ToLower(text))
+	default:
+		return script
+	}
+}
+
+// dominantScript returns the language of the script most of text is
+// written in, as listed in languageScripts, or "" for text without letters.
+func dominantScript(text string) string {
+	counts := map[string]int{}
+	for _, r := range text {
+		for _, s := range languageScripts {
+			if unicode.Is(s.script, r) {
+				// A Hangul, kana, or Han character carries about a
+				// syllable, so it outweighs a single letter of mixed text.
+				switch s.language {
+				case "kor", "jpn", "zho":
+					counts[s.language] += 3
+				default:
+					counts[s.language]++
+				}
+				break
+			}
+		}
+	}
+	if counts["jpn"] > 0 {
+		counts["jpn"] += counts["zho"]
+		counts["zho"] = 0
+	}
+	best := ""
+	for _, s := range languageScripts {
+		if counts[s.language] > counts[best] {
+			best = s.language
+		}
+	}
+	return best
+}
+
+// latinLanguage scores lowercase Latin text against latinLanguages,
+// returning "" when no language scores or two tie.
+func latinLanguage(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	best, bestScore, tied := "", 0, false
+	for _, l := range latinLanguages {
+		score := 0
+		for _, r := range text {
+			if strings.ContainsRune(l.letters, r) {
+				score++
+			}
+		}
+		for _, word := range words {
+			if strings.Contains(" "+l.words+" ", " "+word+" ") {
+				score++
+			}
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = l.language, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if tied || bestScore == 0 {
+		return ""
+	}
+	return best
+}
+
+// cyrillicLanguage tells Ukrainian and Bulgarian from Russian by the
+// letters each uses alone.
+func cyrillicLanguage(text string) string {
+	switch {
+	case strings.ContainsAny(text, "іїєґ"):
+		return "ukr"
+	case strings.ContainsAny(text, "ыэё"):
+		return "rus"
+	case strings.ContainsRune(text, 'ъ'):
+		return "bul"
+	}
+	return "rus"
+}
+
+// requestLanguage applies the client's LanguageDetection to a request for
+// text spoken by voiceID, returning the Language to send.
+func (c *Client) requestLanguage(ctx context.Context, voiceID, language, text string) string {
+	switch c.languageDetection {
+	case LanguageDetectionSet:
+		if language == "" {
+			return DetectLanguage(text)
+		}
+	case LanguageDetectionWarn:
+		c.warnLanguageMismatch(ctx, voiceID, language, text)
+	}
+	return language
+}
+
+// voiceLookupRetry is how long a voice whose languages could not be looked
+// up goes unchecked before the lookup is tried again.
+const voiceLookupRetry = time.Minute
+
+// failedVoiceLookup is cached in place of a voice's languages when the
+// lookup failed, until the time it holds.
+type failedVoiceLookup time.Time
+
+// warnLanguageMismatch logs when the detected language of text differs
+// from language, or is not one of the voice's languages. Voices whose
+// languages cannot be looked up are not checked, and the lookup is only
+// tried again after voiceLookupRetry, so an outage of the voices endpoint
+// does not add a request to every synthesis.
+func (c *Client) warnLanguageMismatch(ctx context.Context, voiceID, language, text string) {
+	if c.logger == nil {
+		return
+	}
+	detected := DetectLanguage(text)
+	if detected == "" {
+		return
+	}
+	if language != "" {
+		if !strings.EqualFold(language, detected) {
+			c.logger.Printf("level=warn msg=%q language=%s detected=%s",
+				"typecast: text does not match the request language", language, detected)
+		}
+		return
+	}
+	languages, ok := c.voiceLanguages.Load(voiceID)
+	if failed, isFailure := languages.(failedVoiceLookup); isFailure {
+		if c.clock.Now().Before(time.Time(failed)) {
+			return
+		}
+		ok = false
+	}
+	if !ok {
+		voice, err := c.GetVoiceV2(ctx, voiceID)
+		if err != nil {
+			c.voiceLanguages.Store(voiceID, failedVoiceLookup(c.clock.Now().Add(voiceLookupRetry)))
+			return
+		}
+		c.voiceLanguages.Store(voiceID, voice.Languages)
+		languages = voice.Languages
+	}
+	if voice := (VoiceV2{Languages: languages.([]string)}); len(voice.Languages) > 0 && !voice.SupportsLanguage(detected) {
+		c.logger.Printf("level=warn msg=%q voice_id=%s languages=%s detected=%s",
+			"typecast: text does not match the voice's languages", voiceID, strings.Join(voice.Languages, ","), detected)
+	}
+}
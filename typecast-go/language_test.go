@@ -0,0 +1,167 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"안녕하세요, 반갑습니다.", "kor"},
+		{"今日は良い天気ですね。", "jpn"},
+		{"我们明天见。", "zho"},
+		{"สวัสดีครับ", "tha"},
+		{"नमस्ते दुनिया", "hin"},
+		{"Καλημέρα", "ell"},
+		{"مرحبا بالعالم", "ara"},
+		{"Привет, как дела? Это мы.", "rus"},
+		{"Привіт, як справи?", "ukr"},
+		{"Здравей, къде си?", "bul"},
+		{"Как дела", "rus"},
+		{"The weather is nice today.", "eng"},
+		{"¿Dónde está la estación?", "spa"},
+		{"Je ne sais pas où est la gare.", "fra"},
+		{"Ich weiß nicht, wo der Bahnhof ist.", "deu"},
+		{"Nie wiem, gdzie jest dworzec.", "pol"},
+		{"Tôi không biết nhà ga ở đâu.", "vie"},
+		{"Hello", ""},
+		{"OK", ""},
+		{"12345 !?", ""},
+		{"", ""},
+		{"Google 검색 결과", "kor"},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.in); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLanguageDetectionSet(t *testing.T) {
+	var languages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Language string `json:"language"`
+			Segments []struct {
+				Language string `json:"language"`
+			} `json:"segments"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		languages = append(languages, body.Language)
+		for _, segment := range body.Segments {
+			languages = append(languages, segment.Language)
+		}
+		if strings.HasSuffix(r.URL.Path, "/with-timestamps") {
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		_, _ = w.Write([]byte("RIFF"))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, LanguageDetection: LanguageDetectionSet})
+
+	if _, err := c.TextToSpeech(ctx, &TTSRequest{VoiceID: "v", Text: "안녕하세요", Model: ModelSSFMV30}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.TextToSpeech(ctx, &TTSRequest{VoiceID: "v", Text: "안녕하세요", Model: ModelSSFMV30, Language: "eng"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.TextToSpeechWithTimestamps(ctx, &TTSRequestWithTimestamps{VoiceID: "v", Text: "こんにちは", Model: ModelSSFMV30}, ""); err != nil {
+		t.Fatal(err)
+	}
+	stream, err := c.TextToSpeechStream(ctx, TTSRequestStream{VoiceID: "v", Text: "Hello", Model: ModelSSFMV30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Close()
+	if _, err := c.ComposeSpeech().Defaults(ComposerSettings{VoiceID: "v", Model: ModelSSFMV30}).Say("我们明天见。").Generate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"kor", "eng", "jpn", "", "", "zho"}
+	if strings.Join(languages, ",") != strings.Join(want, ",") {
+		t.Fatalf("sent languages %q, want %q", languages, want)
+	}
+}
+
+func TestLanguageDetectionWarn(t *testing.T) {
+	var sent []string
+	voiceLookups, missingLookups := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/voices/eng-voice":
+			voiceLookups++
+			_, _ = w.Write([]byte(`{"voice_id":"eng-voice","voice_name":"A","languages":["eng"]}`))
+		case "/v2/voices/any-voice":
+			voiceLookups++
+			_, _ = w.Write([]byte(`{"voice_id":"any-voice","voice_name":"B"}`))
+		case "/v2/voices/missing":
+			missingLookups++
+			http.Error(w, `{"detail":"not found"}`, http.StatusNotFound)
+		default:
+			var body TTSRequest
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			sent = append(sent, body.Language)
+			_, _ = w.Write([]byte("RIFF"))
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	logger := &recordingLogger{}
+	clock := NewFakeClock(time.Unix(0, 0))
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, LanguageDetection: LanguageDetectionWarn, Logger: logger, Clock: clock})
+
+	requests := []TTSRequest{
+		{VoiceID: "eng-voice", Text: "안녕하세요"},
+		{VoiceID: "eng-voice", Text: "반갑습니다"},
+		{VoiceID: "eng-voice", Text: "The weather is nice today."},
+		{VoiceID: "any-voice", Text: "안녕하세요"},
+		{VoiceID: "missing", Text: "안녕하세요"},
+		{VoiceID: "missing", Text: "반갑습니다"},
+		{VoiceID: "eng-voice", Text: "안녕하세요", Language: "eng"},
+		{VoiceID: "eng-voice", Text: "안녕하세요", Language: "KOR"},
+		{VoiceID: "eng-voice", Text: "OK"},
+	}
+	for i := range requests {
+		requests[i].Model = ModelSSFMV30
+		if _, err := c.TextToSpeech(ctx, &requests[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{
+		`level=warn msg="typecast: text does not match the voice's languages" voice_id=eng-voice languages=eng detected=kor`,
+		`level=warn msg="typecast: text does not match the voice's languages" voice_id=eng-voice languages=eng detected=kor`,
+		`level=warn msg="typecast: text does not match the request language" language=eng detected=kor`,
+	}
+	if strings.Join(logger.lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("logged:\n%s", strings.Join(logger.lines, "\n"))
+	}
+	if voiceLookups != 2 {
+		t.Fatalf("looked up voices %d times, want once per voice", voiceLookups)
+	}
+	// A failed lookup is cached for a minute, then tried again.
+	if missingLookups != 1 {
+		t.Fatalf("looked up the missing voice %d times, want once", missingLookups)
+	}
+	clock.Advance(time.Minute)
+	if _, err := c.TextToSpeech(ctx, &TTSRequest{VoiceID: "missing", Text: "안녕하세요", Model: ModelSSFMV30}); err != nil || missingLookups != 2 {
+		t.Fatalf("expected a second lookup after a minute, got %d, %v", missingLookups, err)
+	}
+	if strings.Join(sent, ",") != ",,,,,,eng,KOR,," {
+		t.Fatalf("warn mode changed languages: %q", sent)
+	}
+
+	// Without a logger, nothing is detected or looked up.
+	quiet := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, LanguageDetection: LanguageDetectionWarn})
+	if _, err := quiet.TextToSpeech(ctx, &TTSRequest{VoiceID: "any-voice", Text: "안녕하세요", Model: ModelSSFMV30}); err != nil {
+		t.Fatal(err)
+	}
+	if voiceLookups != 2 {
+		t.Fatalf("looked up a voice without a logger")
+	}
+}
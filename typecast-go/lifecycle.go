@@ -0,0 +1,82 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrClientClosed is returned by requests sent after Client.Close.
+var ErrClientClosed = errors.New("typecast: client is closed")
+
+// Close releases the client's resources: it stops the background work of
+// the client's features and closes the idle connections of the transport
+// the client built for UnixSocket, DialContext, or DNSCacheTTL. Requests in
+// flight are not interrupted, and neither are syntheses started before
+// Close, such as detached ones: their retries and pending-job polls still
+// go out. Other requests sent after Close fail with ErrClientClosed. An
+// HTTPClient, RateLimiter, RetryBudget, or QuotaGuard passed in
+// ClientConfig may be shared and is left as is. Close is safe to call more
+// than once and always returns nil.
+func (c *Client) Close() error {
+	c.lifecycle.Lock()
+	if c.closed {
+		c.lifecycle.Unlock()
+		return nil
+	}
+	c.closed = true
+	closers := c.closers
+	c.closers = nil
+	c.lifecycle.Unlock()
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		closers[i]()
+	}
+	if c.ownsTransport {
+		if t, ok := c.httpClient.Transport.(*http.Transport); ok {
+			t.CloseIdleConnections()
+		}
+	}
+	return nil
+}
+
+// onClose registers fn to stop background work when the client is closed,
+// in reverse order of registration. fn runs at once if the client is
+// already closed.
+func (c *Client) onClose(fn func()) {
+	c.lifecycle.Lock()
+	if !c.closed {
+		c.closers = append(c.closers, fn)
+		fn = nil
+	}
+	c.lifecycle.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+// admit marks ctx as the context of a synthesis started before Close, so
+// its requests still go out once the client is closed. It fails after
+// Close unless ctx was admitted already.
+func (c *Client) admit(ctx context.Context) (context.Context, error) {
+	if ctx.Value(admittedContextKey) != nil {
+		return ctx, nil
+	}
+	if c.isClosed() {
+		return nil, ErrClientClosed
+	}
+	return context.WithValue(ctx, admittedContextKey, true), nil
+}
+
+// refuses reports whether a request made with ctx must fail with
+// ErrClientClosed: the client is closed and ctx was not admitted.
+func (c *Client) refuses(ctx context.Context) bool {
+	return ctx.Value(admittedContextKey) == nil && c.isClosed()
+}
+
+// isClosed reports whether Close was called.
+func (c *Client) isClosed() bool {
+	c.lifecycle.Lock()
+	defer c.lifecycle.Unlock()
+	return c.closed
+}
@@ -0,0 +1,56 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, DNSCacheTTL: 1})
+	if !c.ownsTransport {
+		t.Fatal("expected the client to own its transport")
+	}
+	if _, err := c.GetVoicesV2(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var stopped []string
+	c.onClose(func() { stopped = append(stopped, "first") })
+	c.onClose(func() { stopped = append(stopped, "second") })
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(stopped, ",") != "second,first" {
+		t.Fatalf("unexpected closers %v", stopped)
+	}
+	if err := c.Close(); err != nil || len(stopped) != 2 {
+		t.Fatalf("expected Close to be idempotent, got %v %v", err, stopped)
+	}
+	c.onClose(func() { stopped = append(stopped, "late") })
+	if len(stopped) != 3 {
+		t.Fatal("expected a closer registered after Close to run at once")
+	}
+
+	if _, err := c.GetVoicesV2(context.Background(), nil); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("expected ErrClientClosed, got %v", err)
+	}
+	if _, err := c.DownloadAudio(context.Background(), srv.URL, &bytes.Buffer{}, nil); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("expected ErrClientClosed, got %v", err)
+	}
+
+	// Clients without their own transport close too.
+	shared := newTestClient(srv, "k")
+	if shared.ownsTransport || shared.Close() != nil {
+		t.Fatal("expected a shared transport to be left open")
+	}
+}
@@ -0,0 +1,293 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// LongFormRequest describes a narration longer than a single request allows.
+type LongFormRequest struct {
+	// Profile holds the voice and default synthesis settings (required)
+	Profile VoiceProfile
+	// Text is the full narration; it is split at sentence boundaries (required)
+	Text string
+	// MaxChunkChars caps the characters per request (optional, defaults to 2000)
+	MaxChunkChars int
+	// IntensityRamp interpolates the emotion intensity across segments instead
+	// of using Profile.EmotionIntensity for all of them (optional)
+	IntensityRamp *IntensityRamp
+	// Store records completed segments under JobID so a rerun after a crash
+	// only synthesizes the missing ones (optional)
+	Store JobStore
+	// JobID names the job in Store (required with Store)
+	JobID string
+	// Seed is sent with every chunk for reproducible output (optional)
+	Seed *int
+	// CheckpointDir is a directory where each completed segment is written
+	// as its own file and listed in manifest.json. A rerun with the same
+	// directory reuses segments whose request is unchanged and whose file
+	// still matches its checksum (optional, exclusive with Store)
+	CheckpointDir string
+	// HLS receives each segment's audio as soon as it is synthesized, so
+	// playback can start before the narration is complete. It requires
+	// Profile.AudioFormat mp3; the caller closes it (optional)
+	HLS *HLSWriter
+	// DASH is like HLS for MPEG-DASH delivery (optional)
+	DASH *DASHWriter
+	// Hooks deliver each segment and the finished narration (optional)
+	Hooks *DeliveryHooks
+	// SoundDir is the directory that {sfx:file} markers in Text are read
+	// from; without it, markers are read as text. A marker must name a file
+	// directly inside SoundDir. Effects are inserted between the
+	// surrounding speech: WAV effects are converted to the speech's sample
+	// rate and channels, and MP3 output requires MP3 effects (optional)
+	SoundDir string
+}
+
+// IntensityRamp moves emotion intensity linearly from From on the first
+// segment to To on the last, e.g. building from 0.8 to 1.6 over a climax.
+type IntensityRamp struct {
+	// From is the intensity of the first segment (0.0 to 2.0)
+	From float64
+	// To is the intensity of the last segment (0.0 to 2.0)
+	To float64
+}
+
+// At returns the intensity of segment i out of n.
+func (r IntensityRamp) At(i, n int) float64 {
+	if n <= 1 {
+		return r.From
+	}
+	return r.From + (r.To-r.From)*float64(i)/float64(n-1)
+}
+
+// LongFormSegment describes one synthesized chunk of a long-form narration.
+type LongFormSegment struct {
+	// Index is the chunk's position, starting at 0
+	Index int `json:"index"`
+	// Text is the chunk that was synthesized
+	Text string `json:"text"`
+	// VoiceID is the voice used for the chunk
+	VoiceID string `json:"voice_id"`
+	// Model is the model used for the chunk
+	Model TTSModel `json:"model"`
+	// Language is the language code sent with the chunk, if any
+	Language string `json:"language,omitempty"`
+	// EmotionPreset is the emotion used for the chunk, if any
+	EmotionPreset EmotionPreset `json:"emotion_preset,omitempty"`
+	// EmotionIntensity is the intensity used for the chunk, if any
+	EmotionIntensity *float64 `json:"emotion_intensity,omitempty"`
+	// AudioTempo is the speech speed used for the chunk, if any
+	AudioTempo *float64 `json:"audio_tempo,omitempty"`
+	// AudioPitch is the pitch used for the chunk, if any
+	AudioPitch *int `json:"audio_pitch,omitempty"`
+	// Seed is the seed used for the chunk, if any
+	Seed *int `json:"seed,omitempty"`
+	// Start is the chunk's offset in the stitched audio, in seconds
+	Start float64 `json:"start"`
+	// Duration is the chunk's audio duration in seconds
+	Duration float64 `json:"duration"`
+	// File is the chunk's audio file in CheckpointDir, if one was set
+	File string `json:"file,omitempty"`
+}
+
+// LongFormResult is the stitched audio of a long-form narration.
+type LongFormResult struct {
+	TTSResponse
+	// Segments lists the synthesized chunks in order
+	Segments []LongFormSegment
+	// SoundEffects lists the inserted sound effects in order
+	SoundEffects []SoundEffect
+}
+
+// Manifest returns the segments as indented JSON, without audio, for audit,
+// captioning, retakes, and editing tools.
+func (r *LongFormResult) Manifest() ([]byte, error) {
+	return json.MarshalIndent(struct {
+		Format       AudioFormat       `json:"format"`
+		Duration     float64           `json:"duration"`
+		Segments     []LongFormSegment `json:"segments"`
+		SoundEffects []SoundEffect     `json:"sound_effects,omitempty"`
+	}{r.Format, r.Duration, r.Segments, r.SoundEffects}, "", "  ")
+}
+
+// Validate checks the LongFormRequest fields for invalid values.
+func (r *LongFormRequest) Validate() error {
+	if err := r.Profile.Validate(); err != nil {
+		return err
+	}
+	if strings.TrimSpace(r.Text) == "" {
+		return newValidationError("text", "text is required")
+	}
+	if r.MaxChunkChars < 0 || r.MaxChunkChars > maxTextLength {
+		return newValidationError("max_chunk_chars", fmt.Sprintf("max_chunk_chars must be between 0 and %d", maxTextLength))
+	}
+	if ramp := r.IntensityRamp; ramp != nil && (ramp.From < 0 || ramp.From > 2.0 || ramp.To < 0 || ramp.To > 2.0) {
+		return newValidationError("emotion_intensity", "emotion_intensity ramp must stay between 0.0 and 2.0")
+	}
+	if r.Store != nil && r.CheckpointDir != "" {
+		return newValidationError("checkpoint_dir", "checkpoint_dir cannot be combined with a store")
+	}
+	if r.HLS != nil && r.Profile.AudioFormat != AudioFormatMP3 {
+		return newValidationError("hls", "hls packaging requires mp3 audio_format")
+	}
+	if r.DASH != nil && r.Profile.AudioFormat != AudioFormatMP3 {
+		return newValidationError("dash", "dash packaging requires mp3 audio_format")
+	}
+	if r.Store != nil && r.JobID == "" {
+		return newValidationError("job_id", "job_id is required when a store is set")
+	}
+	if r.SoundDir != "" {
+		for _, m := range sfxMarkup.FindAllStringSubmatch(r.Text, -1) {
+			if !isSoundEffectName(m[1]) {
+				return newValidationError("sound_dir", fmt.Sprintf("sound effect %q must be a file name inside sound_dir", m[1]))
+			}
+		}
+	}
+	return nil
+}
+
+// LongFormSynthesize splits Text into chunks at sentence boundaries,
+// synthesizes them in order with the request's voice profile, and stitches
+// the results into a single WAV or MP3 file.
+func (c *Client) LongFormSynthesize(ctx context.Context, request LongFormRequest) (*LongFormResult, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	store := request.Store
+	if request.CheckpointDir != "" {
+		store = &checkpointStore{dir: request.CheckpointDir}
+	}
+	done, err := loadJob(store, request.JobID)
+	if err != nil {
+		return nil, err
+	}
+	parts := splitScript(request.Text, request.MaxChunkChars, request.SoundDir != "")
+	chunks := 0
+	for _, part := range parts {
+		if part.sfx == "" {
+			chunks++
+		}
+	}
+	format := request.Profile.AudioFormat
+	if format == "" {
+		format = AudioFormatWAV
+	}
+	result := &LongFormResult{TTSResponse: TTSResponse{Format: format}, Segments: make([]LongFormSegment, 0, chunks)}
+	audio := make([][]byte, 0, len(parts))
+	var effects []int // indexes of the sound effects in audio
+	firstSpeech := -1
+	for _, part := range parts {
+		if part.sfx != "" {
+			data, duration, err := loadSoundEffect(request.SoundDir, part.sfx, format)
+			if err != nil {
+				return nil, err
+			}
+			if err := request.packageSegment(data); err != nil {
+				return nil, fmt.Errorf("sfx %s: %w", part.sfx, err)
+			}
+			effects = append(effects, len(audio))
+			audio = append(audio, data)
+			result.SoundEffects = append(result.SoundEffects, SoundEffect{File: part.sfx, Start: result.Duration, Duration: duration})
+			result.Duration += duration
+			continue
+		}
+		i, chunk := len(result.Segments), part.text
+		profile := request.Profile
+		if request.IntensityRamp != nil {
+			intensity := request.IntensityRamp.At(i, chunks)
+			profile.EmotionIntensity = &intensity
+		}
+		tts := profile.Request(chunk)
+		tts.Seed = request.Seed
+		resp, err := c.synthesizeSegment(ctx, store, request.JobID, i, tts, done)
+		if err != nil {
+			return nil, fmt.Errorf("segment %d: %w", i, err)
+		}
+		if err := request.packageSegment(resp.AudioData); err != nil {
+			return nil, fmt.Errorf("segment %d: %w", i, err)
+		}
+		if firstSpeech < 0 {
+			firstSpeech = len(audio)
+		}
+		audio = append(audio, resp.AudioData)
+		result.Format = resp.Format
+		segment := LongFormSegment{
+			Index:            i,
+			Text:             chunk,
+			VoiceID:          tts.VoiceID,
+			Model:            tts.Model,
+			Language:         tts.Language,
+			EmotionPreset:    profile.EmotionPreset,
+			EmotionIntensity: profile.EmotionIntensity,
+			AudioTempo:       profile.AudioTempo,
+			AudioPitch:       profile.AudioPitch,
+			Seed:             tts.Seed,
+			Start:            result.Duration,
+			Duration:         resp.Duration,
+		}
+		if request.CheckpointDir != "" {
+			segment.File = filepath.Join(request.CheckpointDir, checkpointFileName(i, resp.Format))
+		}
+		if err := request.Hooks.segmentDone(ctx, segment, resp.AudioData); err != nil {
+			return nil, fmt.Errorf("segment %d: failed to deliver: %w", i, err)
+		}
+		result.Segments = append(result.Segments, segment)
+		result.Duration += resp.Duration
+	}
+	if len(effects) > 0 && result.Format == AudioFormatWAV {
+		reference := audio[0]
+		if firstSpeech >= 0 {
+			reference = audio[firstSpeech]
+		}
+		if err := matchWAVFormat(audio, effects, reference); err != nil {
+			return nil, fmt.Errorf("failed to stitch audio: %w", err)
+		}
+	}
+	stitched, err := concatAudio(result.Format, audio)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stitch audio: %w", err)
+	}
+	result.AudioData = stitched
+	if wav, err := decodeWAV(stitched); err == nil {
+		result.Duration = wav.duration()
+	}
+	if err := request.Hooks.jobDone(ctx, result); err != nil {
+		return nil, fmt.Errorf("failed to deliver: %w", err)
+	}
+	return result, nil
+}
+
+// packageSegment appends a segment's audio to the HLS and DASH writers.
+func (r *LongFormRequest) packageSegment(audio []byte) error {
+	if r.HLS != nil {
+		if err := r.HLS.Append(audio); err != nil {
+			return err
+		}
+	}
+	if r.DASH != nil {
+		return r.DASH.Append(audio)
+	}
+	return nil
+}
+
+// synthesizeSegment returns the stored audio of segment index when its
+// request is unchanged, and otherwise synthesizes and stores it.
+func (c *Client) synthesizeSegment(ctx context.Context, store JobStore, jobID string, index int, request *TTSRequest, done map[int]JobSegment) (*TTSResponse, error) {
+	key := requestKey(request)
+	if segment, ok := done[index]; ok && segment.Key == key {
+		return segment.response(), nil
+	}
+	resp, err := c.TextToSpeech(ctx, request)
+	if err != nil || store == nil {
+		return resp, err
+	}
+	segment := JobSegment{Index: index, Key: key, Duration: resp.Duration, Format: resp.Format, Audio: resp.AudioData}
+	if err := store.Save(jobID, segment); err != nil {
+		return nil, fmt.Errorf("failed to save segment: %w", err)
+	}
+	return resp, nil
+}
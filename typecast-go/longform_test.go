@@ -0,0 +1,201 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIntensityRamp_At(t *testing.T) {
+	ramp := IntensityRamp{From: 0.8, To: 1.6}
+	if ramp.At(0, 1) != 0.8 || ramp.At(0, 5) != 0.8 || !approx(ramp.At(4, 5), 1.6) || !approx(ramp.At(2, 5), 1.2) {
+		t.Fatalf("unexpected ramp values: %v %v %v", ramp.At(0, 5), ramp.At(2, 5), ramp.At(4, 5))
+	}
+}
+
+func TestLongFormSynthesize_RampsIntensityAndStitchesWAV(t *testing.T) {
+	var texts []string
+	var intensities []float64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text   string `json:"text"`
+			Prompt struct {
+				EmotionPreset    string  `json:"emotion_preset"`
+				EmotionIntensity float64 `json:"emotion_intensity"`
+			} `json:"prompt"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		texts = append(texts, body.Text)
+		intensities = append(intensities, body.Prompt.EmotionIntensity)
+		w.Header().Set("Content-Type", "audio/wav")
+		w.Header().Set("X-Audio-Duration", "9")
+		_, _ = w.Write(makeTestWAV(make([]byte, 1600), 8000))
+	}))
+	defer srv.Close()
+
+	result, err := newTestClient(srv, "k").LongFormSynthesize(context.Background(), LongFormRequest{
+		Profile:       VoiceProfile{VoiceID: "tc_narrator", EmotionPreset: EmotionAngry},
+		Text:          "It was quiet. Then a knock. Then another! The door burst open.",
+		MaxChunkChars: 20,
+		IntensityRamp: &IntensityRamp{From: 0.8, To: 1.4},
+	})
+	if err != nil {
+		t.Fatalf("LongFormSynthesize() error = %v", err)
+	}
+	if strings.Join(texts, "|") != "It was quiet.|Then a knock.|Then another!|The door burst open." {
+		t.Fatalf("unexpected chunks: %q", texts)
+	}
+	want := []float64{0.8, 1.0, 1.2, 1.4}
+	for i := range want {
+		if diff := intensities[i] - want[i]; diff > 1e-9 || diff < -1e-9 {
+			t.Fatalf("intensities = %v, want %v", intensities, want)
+		}
+		if *result.Segments[i].EmotionIntensity != intensities[i] || result.Segments[i].Duration != 9 {
+			t.Fatalf("unexpected segment %d: %+v", i, result.Segments[i])
+		}
+	}
+	if !bytes.Equal(result.AudioData, makeTestWAV(make([]byte, 6400), 8000)) || result.Format != AudioFormatWAV {
+		t.Fatal("unexpected stitched audio")
+	}
+	if result.Duration != 0.4 {
+		t.Fatalf("Duration = %v, want duration measured from the stitched wav", result.Duration)
+	}
+}
+
+func TestLongFormSynthesize_MP3WithoutRamp(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Header().Set("X-Audio-Duration", "1.5")
+		_, _ = w.Write([]byte{0xFF, 0xFB})
+	}))
+	defer srv.Close()
+
+	result, err := newTestClient(srv, "k").LongFormSynthesize(context.Background(), LongFormRequest{
+		Profile:       VoiceProfile{VoiceID: "v", AudioFormat: AudioFormatMP3},
+		Text:          "One. Two.",
+		MaxChunkChars: 5,
+	})
+	if err != nil {
+		t.Fatalf("LongFormSynthesize() error = %v", err)
+	}
+	if len(result.Segments) != 2 || result.Segments[0].EmotionIntensity != nil || result.Duration != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestLongFormResult_Manifest(t *testing.T) {
+	var seeds []interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		seeds = append(seeds, body["seed"])
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Header().Set("X-Audio-Duration", "1.5")
+		_, _ = w.Write([]byte{0xFF, 0xFB})
+	}))
+	defer srv.Close()
+
+	tempo, pitch, seed := 1.2, -2, 7
+	dir := t.TempDir()
+	result, err := newTestClient(srv, "k").LongFormSynthesize(context.Background(), LongFormRequest{
+		Profile: VoiceProfile{
+			VoiceID:       "v",
+			Language:      "eng",
+			EmotionPreset: EmotionSad,
+			AudioTempo:    &tempo,
+			AudioPitch:    &pitch,
+			AudioFormat:   AudioFormatMP3,
+		},
+		Text:          "One. Two.",
+		MaxChunkChars: 5,
+		Seed:          &seed,
+		CheckpointDir: dir,
+	})
+	if err != nil {
+		t.Fatalf("LongFormSynthesize() error = %v", err)
+	}
+	if len(seeds) != 2 || seeds[0] != float64(7) || seeds[1] != float64(7) {
+		t.Fatalf("seed must be sent with every chunk: %v", seeds)
+	}
+	second := result.Segments[1]
+	if second.Index != 1 || second.Start != 1.5 || second.File != filepath.Join(dir, "segment-0001.mp3") || *second.Seed != 7 {
+		t.Fatalf("unexpected segment: %+v", second)
+	}
+
+	data, err := result.Manifest()
+	if err != nil {
+		t.Fatalf("Manifest() error = %v", err)
+	}
+	var manifest struct {
+		Format   AudioFormat `json:"format"`
+		Duration float64     `json:"duration"`
+		Segments []map[string]interface{}
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("invalid manifest: %v", err)
+	}
+	if manifest.Format != AudioFormatMP3 || manifest.Duration != 3 || len(manifest.Segments) != 2 {
+		t.Fatalf("unexpected manifest: %s", data)
+	}
+	want := map[string]interface{}{
+		"index": float64(1), "text": "Two.", "voice_id": "v", "model": "ssfm-v30", "language": "eng",
+		"emotion_preset": "sad", "audio_tempo": 1.2, "audio_pitch": float64(-2), "seed": float64(7),
+		"start": 1.5, "duration": 1.5, "file": filepath.Join(dir, "segment-0001.mp3"),
+	}
+	for key, value := range want {
+		if manifest.Segments[1][key] != value {
+			t.Fatalf("manifest %s = %v, want %v\n%s", key, manifest.Segments[1][key], value, data)
+		}
+	}
+	if strings.Contains(string(data), "AudioData") {
+		t.Fatalf("manifest must not include audio: %s", data)
+	}
+}
+
+func TestLongFormSynthesize_Errors(t *testing.T) {
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://x"})
+	ctx := context.Background()
+	cases := []struct {
+		request LongFormRequest
+		field   string
+	}{
+		{LongFormRequest{Text: "hi"}, "voice_id"},
+		{LongFormRequest{Profile: VoiceProfile{VoiceID: "v"}}, "text"},
+		{LongFormRequest{Profile: VoiceProfile{VoiceID: "v"}, Text: "hi", MaxChunkChars: 2001}, "max_chunk_chars"},
+		{LongFormRequest{Profile: VoiceProfile{VoiceID: "v"}, Text: "hi", IntensityRamp: &IntensityRamp{From: 1, To: 3}}, "emotion_intensity"},
+	}
+	for _, tc := range cases {
+		var validation *ValidationError
+		if _, err := c.LongFormSynthesize(ctx, tc.request); !errors.As(err, &validation) || validation.Field != tc.field {
+			t.Errorf("expected %s ValidationError, got %v", tc.field, err)
+		}
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Fail") != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte("not a wav"))
+	}))
+	defer srv.Close()
+	c = newTestClient(srv, "k")
+	if _, err := c.LongFormSynthesize(ctx, LongFormRequest{Profile: VoiceProfile{VoiceID: "v"}, Text: "hi"}); err == nil || !strings.Contains(err.Error(), "failed to stitch audio") {
+		t.Fatalf("expected stitch error, got %v", err)
+	}
+
+	failing := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, HTTPClient: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		r.Header.Set("X-Fail", "1")
+		return http.DefaultTransport.RoundTrip(r)
+	})}})
+	if _, err := failing.LongFormSynthesize(ctx, LongFormRequest{Profile: VoiceProfile{VoiceID: "v"}, Text: "hi"}); err == nil || !strings.Contains(err.Error(), "segment 0") {
+		t.Fatalf("expected segment error, got %v", err)
+	}
+}
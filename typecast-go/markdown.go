@@ -0,0 +1,418 @@
+package typecast
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultHeadingPause is the silence, in seconds, around headings.
+const defaultHeadingPause = 0.6
+
+var (
+	atxHeading     = regexp.MustCompile(`^(#{1,6})(?:[ \t]+|$)`)
+	atxClosing     = regexp.MustCompile(`(?:^|[ \t]+)#+$`)
+	listMarker     = regexp.MustCompile(`^(?:[-*+]|\d{1,9}[.)])(?:[ \t]+|$)`)
+	taskBox        = regexp.MustCompile(`^\[[ xX]\][ \t]+`)
+	thematicBreak  = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	linkDefinition = regexp.MustCompile(`^\[([^\]]+)\]:[ \t]*(.*)$`)
+	tableDelimiter = regexp.MustCompile(`^\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?$`)
+	htmlComment    = regexp.MustCompile(`(?s)<!--.*?(?:-->|$)`)
+	htmlTag        = regexp.MustCompile(`^</?[A-Za-z][A-Za-z0-9-]*(?:\s[^<>]*)?/?>`)
+	autolink       = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*|[^\s<>@]+@[^\s<>@]+)>`)
+)
+
+// MarkdownOptions configures MarkdownToSpeech.
+type MarkdownOptions struct {
+	// HeadingPause is the silence around headings, in seconds (optional,
+	// defaults to 0.6). It is written as pause markup, such as <|0.6s|>,
+	// which SpeechComposer.Say turns into pauses. A negative value writes
+	// no markup, for text sent with TextToSpeech or LongFormSynthesize.
+	HeadingPause float64
+	// CodeBlockPlaceholder is read in place of each code block (optional,
+	// e.g. "Code sample omitted."). Code blocks are skipped when empty.
+	CodeBlockPlaceholder string
+}
+
+// markdownBlock is a paragraph, heading, list item, or table row of
+// speakable text.
+type markdownBlock struct {
+	text    string
+	heading bool
+}
+
+// MarkdownToSpeech converts Markdown, such as documentation or a blog post,
+// to text for narration. Formatting, images, HTML tags, link targets, and
+// YAML front matter are dropped; links and images are read as their text.
+// Headings, list items, and table rows are read as sentences, and headings
+// are surrounded by pauses. Inline code is read as written, while code
+// blocks are skipped or read as CodeBlockPlaceholder. Blocks are separated
+// by blank lines.
+func MarkdownToSpeech(markdown string, opts *MarkdownOptions) string {
+	var o MarkdownOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.HeadingPause == 0 {
+		o.HeadingPause = defaultHeadingPause
+	}
+	p := markdownParser{opts: o}
+	markdown = strings.ReplaceAll(markdown, "\r\n", "\n")
+	markdown = htmlComment.ReplaceAllString(markdown, "")
+	p.parse(skipFrontMatter(strings.Split(markdown, "\n")))
+	return p.render()
+}
+
+// skipFrontMatter drops a leading YAML front matter block.
+func skipFrontMatter(lines []string) []string {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return lines
+	}
+	for i := 1; i < len(lines); i++ {
+		if line := strings.TrimSpace(lines[i]); line == "---" || line == "..." {
+			return lines[i+1:]
+		}
+	}
+	return lines
+}
+
+type markdownParser struct {
+	opts      MarkdownOptions
+	blocks    []markdownBlock
+	paragraph []string
+	inItem    bool // the paragraph is a list item
+	afterItem bool // the last block was a list item, so indented lines continue it
+}
+
+func (p *markdownParser) parse(lines []string) {
+	for i := 0; i < len(lines); i++ {
+		line := stripBlockQuote(strings.TrimRight(lines[i], " \t"))
+		trimmed := strings.TrimLeft(line, " \t")
+		indented := markdownIndent(line) >= 4
+		switch {
+		case trimmed == "":
+			if p.inItem {
+				p.afterItem = true
+			}
+			p.flush()
+		case !indented && isCodeFence(trimmed):
+			p.flush()
+			i = skipFencedCode(lines, i)
+			p.addCodeBlock()
+		case indented && len(p.paragraph) == 0 && !p.afterItem:
+			for i+1 < len(lines) && (strings.TrimSpace(lines[i+1]) == "" || markdownIndent(lines[i+1]) >= 4) {
+				i++
+			}
+			p.addCodeBlock()
+		case !indented && atxHeading.MatchString(trimmed):
+			p.flush()
+			text := atxHeading.ReplaceAllString(trimmed, "")
+			p.add(atxClosing.ReplaceAllString(text, ""), true)
+		case !indented && len(p.paragraph) > 0 && !p.inItem && isSetextUnderline(trimmed):
+			text := strings.Join(p.paragraph, " ")
+			p.paragraph = nil
+			p.add(text, true)
+		case !indented && thematicBreak.MatchString(trimmed):
+			p.flush()
+		case !indented && listMarker.MatchString(trimmed):
+			p.flush()
+			item := listMarker.ReplaceAllString(trimmed, "")
+			p.paragraph = append(p.paragraph, taskBox.ReplaceAllString(item, ""))
+			p.inItem = true
+		case !indented && len(p.paragraph) == 0 && linkDefinition.MatchString(trimmed):
+			// Footnotes are read; link reference definitions are not.
+			if m := linkDefinition.FindStringSubmatch(trimmed); strings.HasPrefix(m[1], "^") {
+				p.paragraph = append(p.paragraph, m[2])
+			}
+		case len(p.paragraph) == 0 && strings.Contains(trimmed, "|") && i+1 < len(lines) &&
+			tableDelimiter.MatchString(strings.TrimSpace(stripBlockQuote(lines[i+1]))):
+			p.addTableRow(trimmed)
+			for i += 2; i < len(lines); i++ {
+				row := strings.TrimSpace(stripBlockQuote(lines[i]))
+				if row == "" || !strings.Contains(row, "|") {
+					i--
+					break
+				}
+				p.addTableRow(row)
+			}
+		default:
+			if !p.inItem && !indented {
+				p.afterItem = false
+			}
+			p.paragraph = append(p.paragraph, trimmed)
+		}
+	}
+	p.flush()
+}
+
+// flush ends the current paragraph or list item.
+func (p *markdownParser) flush() {
+	if len(p.paragraph) > 0 {
+		text := strings.Join(p.paragraph, " ")
+		if p.inItem {
+			text = asSentence(markdownInline(text))
+			if text != "" {
+				p.blocks = append(p.blocks, markdownBlock{text: text})
+			}
+		} else {
+			p.add(text, false)
+		}
+	}
+	p.paragraph = nil
+	p.inItem = false
+}
+
+// add appends a paragraph or heading, read as a sentence, from its Markdown
+// source.
+func (p *markdownParser) add(source string, heading bool) {
+	text := markdownInline(source)
+	if text == "" {
+		return
+	}
+	if heading {
+		text = asSentence(text)
+	}
+	p.blocks = append(p.blocks, markdownBlock{text: text, heading: heading})
+	p.afterItem = false
+}
+
+func (p *markdownParser) addCodeBlock() {
+	if p.opts.CodeBlockPlaceholder != "" {
+		p.blocks = append(p.blocks, markdownBlock{text: p.opts.CodeBlockPlaceholder})
+	}
+	p.afterItem = false
+}
+
+// addTableRow reads a table row as its cells separated by commas.
+func (p *markdownParser) addTableRow(row string) {
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	var cells []string
+	for _, cell := range strings.Split(row, "|") {
+		if cell = markdownInline(cell); cell != "" {
+			cells = append(cells, cell)
+		}
+	}
+	if len(cells) > 0 {
+		p.blocks = append(p.blocks, markdownBlock{text: asSentence(strings.Join(cells, ", "))})
+	}
+	p.afterItem = false
+}
+
+// render joins the blocks with blank lines and pauses around headings.
+func (p *markdownParser) render() string {
+	pause := ""
+	if p.opts.HeadingPause > 0 {
+		pause = "<|" + strconv.FormatFloat(p.opts.HeadingPause, 'f', -1, 64) + "s|>"
+	}
+	var b strings.Builder
+	for i, block := range p.blocks {
+		if i > 0 {
+			b.WriteString("\n\n")
+			if block.heading && !p.blocks[i-1].heading {
+				b.WriteString(pause)
+			}
+		}
+		b.WriteString(block.text)
+		if block.heading && i < len(p.blocks)-1 {
+			b.WriteString(pause)
+		}
+	}
+	return b.String()
+}
+
+// markdownIndent returns the width of line's leading whitespace, counting a
+// tab as four columns.
+func markdownIndent(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+func stripBlockQuote(line string) string {
+	for {
+		trimmed := strings.TrimLeft(line, " ")
+		if !strings.HasPrefix(trimmed, ">") || markdownIndent(line) >= 4 {
+			return line
+		}
+		line = strings.TrimPrefix(trimmed[1:], " ")
+	}
+}
+
+func isCodeFence(line string) bool {
+	return strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~")
+}
+
+// skipFencedCode returns the index of the line closing the fence opened on
+// line start, or the last line when the fence is never closed.
+func skipFencedCode(lines []string, start int) int {
+	open := strings.TrimLeft(stripBlockQuote(lines[start]), " \t")
+	fence := open[:len(open)-len(strings.TrimLeft(open, open[:1]))]
+	for i := start + 1; i < len(lines); i++ {
+		line := strings.TrimSpace(stripBlockQuote(lines[i]))
+		if strings.HasPrefix(line, fence) && strings.Trim(line, fence[:1]) == "" {
+			return i
+		}
+	}
+	return len(lines) - 1
+}
+
+func isSetextUnderline(line string) bool {
+	return strings.Trim(line, "=") == "" || strings.Trim(line, "-") == ""
+}
+
+// asSentence ends text with a period unless it already ends with
+// punctuation, so headings and list items are not run together.
+func asSentence(text string) string {
+	last, _ := utf8.DecodeLastRuneInString(strings.TrimRight(text, "\"'”’)]」』"))
+	if text == "" || isSentenceTerminator(last) || strings.ContainsRune(",:;", last) {
+		return text
+	}
+	return text + "."
+}
+
+// markdownInline returns the speakable text of inline Markdown, with
+// emphasis, link targets, and HTML tags removed and whitespace collapsed.
+func markdownInline(source string) string {
+	var b strings.Builder
+	writeInline(&b, source)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func writeInline(b *strings.Builder, s string) {
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", s[i+1]) >= 0:
+			b.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '`':
+			run := delimiterRun(s, i)
+			if end := strings.Index(s[i+run:], s[i:i+run]); end >= 0 {
+				b.WriteString(s[i+run : i+run+end])
+				i += run + end + run
+				continue
+			}
+			b.WriteString(s[i : i+run])
+			i += run
+			continue
+		case c == '[' || (c == '!' && strings.HasPrefix(s[i+1:], "[")):
+			if text, n, ok := parseLink(s[i:]); ok {
+				writeInline(b, text)
+				i += n
+				continue
+			}
+		case c == '<':
+			if m := autolink.FindStringSubmatch(s[i:]); m != nil {
+				b.WriteString(m[1])
+				i += len(m[0])
+				continue
+			}
+			if m := htmlTag.FindString(s[i:]); m != "" {
+				b.WriteByte(' ')
+				i += len(m)
+				continue
+			}
+		case c == '*' || c == '_' || c == '~':
+			run := delimiterRun(s, i)
+			if isEmphasis(s, i, run) {
+				i += run
+				continue
+			}
+			b.WriteString(s[i : i+run])
+			i += run
+			continue
+		}
+		b.WriteByte(c)
+		i++
+	}
+}
+
+// delimiterRun returns the length of the run of s[i] starting at i.
+func delimiterRun(s string, i int) int {
+	n := 1
+	for i+n < len(s) && s[i+n] == s[i] {
+		n++
+	}
+	return n
+}
+
+// isEmphasis reports whether the run of n delimiters at s[i] opens or
+// closes emphasis or strikethrough. A run between spaces, a single tilde,
+// and underscores inside a word, as in snake_case, are literal.
+func isEmphasis(s string, i, n int) bool {
+	before, _ := utf8.DecodeLastRuneInString(s[:i])
+	after, _ := utf8.DecodeRuneInString(s[i+n:])
+	spaceBefore := i == 0 || unicode.IsSpace(before)
+	spaceAfter := i+n == len(s) || unicode.IsSpace(after)
+	switch {
+	case spaceBefore && spaceAfter:
+		return false
+	case s[i] == '~':
+		return n == 2
+	case s[i] == '_':
+		return spaceBefore || spaceAfter || !isWordRune(before) || !isWordRune(after)
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// parseLink parses an inline, reference, or footnote link, or an image, at
+// the start of s. It returns the text to read, which is empty for a
+// footnote reference, and the length of the link.
+func parseLink(s string) (text string, n int, ok bool) {
+	open := strings.IndexByte(s, '[')
+	end := matchingBracket(s, open, '[', ']')
+	if end < 0 {
+		return "", 0, false
+	}
+	text = s[open+1 : end]
+	rest := s[end+1:]
+	switch {
+	case open == 0 && strings.HasPrefix(text, "^"):
+		return "", end + 1, true
+	case strings.HasPrefix(rest, "("):
+		if close := matchingBracket(rest, 0, '(', ')'); close >= 0 {
+			return text, end + 1 + close + 1, true
+		}
+	case strings.HasPrefix(rest, "["):
+		if close := strings.IndexByte(rest, ']'); close >= 0 {
+			return text, end + 1 + close + 1, true
+		}
+	}
+	return "", 0, false
+}
+
+// matchingBracket returns the index of the bracket closing the one at
+// s[open], or -1. Backslash-escaped brackets are skipped.
+func matchingBracket(s string, open int, opening, closing byte) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case opening:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
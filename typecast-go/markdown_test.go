@@ -0,0 +1,105 @@
+package typecast
+
+import "testing"
+
+func TestMarkdownToSpeech(t *testing.T) {
+	markdown := "---\ntitle: Release notes\n---\n" +
+		"# Getting *Started* #\n\n" +
+		"Install the [SDK](https://example.com/sdk_(go)) with `go get`\nand read the **docs**.\n\n" +
+		"```go\nfmt.Println(\"hi\")\n```\n\n" +
+		"Setup\n-----\n\n" +
+		"- [x] Create a key\n- Set it\n  in your shell\n\n" +
+		"## Limits\n\n" +
+		"| Plan | Characters |\n|---|:-:|\n| Free | 10,000 |\n"
+	want := "Getting Started.<|0.6s|>\n\n" +
+		"Install the SDK with go get and read the docs.\n\n" +
+		"<|0.6s|>Setup.<|0.6s|>\n\n" +
+		"Create a key.\n\n" +
+		"Set it in your shell.\n\n" +
+		"<|0.6s|>Limits.<|0.6s|>\n\n" +
+		"Plan, Characters.\n\n" +
+		"Free, 10,000."
+	if got := MarkdownToSpeech(markdown, nil); got != want {
+		t.Errorf("MarkdownToSpeech() = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownToSpeechOptions(t *testing.T) {
+	markdown := "# One\n## Two\n\nBody\n\n```\ncode\n```\n\n\tindented code\n\n### Three"
+	tests := []struct {
+		name string
+		opts *MarkdownOptions
+		want string
+	}{
+		{"default", &MarkdownOptions{}, "One.<|0.6s|>\n\nTwo.<|0.6s|>\n\nBody\n\n<|0.6s|>Three."},
+		{"pause", &MarkdownOptions{HeadingPause: 1.5}, "One.<|1.5s|>\n\nTwo.<|1.5s|>\n\nBody\n\n<|1.5s|>Three."},
+		{"no pause", &MarkdownOptions{HeadingPause: -1}, "One.\n\nTwo.\n\nBody\n\nThree."},
+		{"placeholder", &MarkdownOptions{HeadingPause: -1, CodeBlockPlaceholder: "Code sample omitted."},
+			"One.\n\nTwo.\n\nBody\n\nCode sample omitted.\n\nCode sample omitted.\n\nThree."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MarkdownToSpeech(markdown, tt.opts); got != tt.want {
+				t.Errorf("MarkdownToSpeech() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownToSpeechBlocks(t *testing.T) {
+	plain := &MarkdownOptions{HeadingPause: -1}
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"crlf", "Title\r\n===\r\nText", "Title.\n\nText"},
+		{"unclosed front matter", "---\ntitle: x", "title: x"},
+		{"thematic break", "One\n\n* * *\nTwo", "One\n\nTwo"},
+		{"empty heading", "#\n\nText", "Text"},
+		{"closing hashes", "## Why C# ##", "Why C#."},
+		{"heading punctuation", "# What is it?\n# \"Quoted.\"", "What is it?\n\n\"Quoted.\""},
+		{"comment", "Before <!-- hidden\nnote --> after <!-- open", "Before after"},
+		{"block quote", "> Quoted\n> > nested\n>\n> ```\n> code\n> ```", "Quoted nested"},
+		{"unclosed fence", "Text\n~~~~\ncode\n~~~\nmore", "Text"},
+		{"longer closing fence", "```\ncode\n`````\nText", "Text"},
+		{"list item continues", "1. First\n2) Second!\n\n   more\n\n-\n\nText", "First.\n\nSecond!\n\nmore\n\nText"},
+		{"code after list", "- Item\n\nText\n\n    code", "Item.\n\nText"},
+		{"references", "See [the docs][docs] and [this][].\n\n[docs]: https://example.com\n", "See the docs and this."},
+		{"footnote", "Fact[^1].\n\n[^1]: Source note", "Fact.\n\nSource note"},
+		{"table ends", "a | b\n--|--\n||\nc | d\nText", "a, b.\n\nc, d.\n\nText"},
+		{"pipe in paragraph", "Use a | b\nhere", "Use a | b here"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MarkdownToSpeech(tt.markdown, plain); got != tt.want {
+				t.Errorf("MarkdownToSpeech(%q) = %q, want %q", tt.markdown, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownInline(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"*a* **b** ***c*** _d_ __e__ ~~f~~", "a b c d e f"},
+		{"snake_case_name and 2 * 3 and ~5 min", "snake_case_name and 2 * 3 and ~5 min"},
+		{"(_a_) *b*", "(a) b"},
+		{"\\*literal\\* \\a", "*literal* \\a"},
+		{"``code with ` tick`` and `open", "code with ` tick and `open"},
+		{"![alt *text*](img.png \"title\") [link](a(b)c)", "alt text link"},
+		{"[broken](open and [open", "[broken](open and [open"},
+		{"[a\\]b](x) [nested [x]](y)", "a]b nested [x]"},
+		{"[plain] text", "[plain] text"},
+		{"line<br/>break <span class=\"x\">tag</span>", "line break tag"},
+		{"<https://example.com> <hi@example.com> a < b", "https://example.com hi@example.com a < b"},
+		{"Note[^2] here", "Note here"},
+	}
+	for _, tt := range tests {
+		if got := markdownInline(tt.source); got != tt.want {
+			t.Errorf("markdownInline(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLatencyBuckets are the upper bounds of the latency histograms when
+// MetricsConfig.LatencyBuckets is not set.
+var defaultLatencyBuckets = []time.Duration{
+	50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2500 * time.Millisecond, 5 * time.Second, 10 * time.Second, 30 * time.Second, time.Minute,
+}
+
+// defaultSizeBuckets are the upper bounds of the payload size histograms,
+// in bytes, when MetricsConfig.SizeBuckets is not set.
+var defaultSizeBuckets = []int64{256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20}
+
+// MetricsConfig configures Metrics.
+type MetricsConfig struct {
+	// LatencyBuckets are the upper bounds of the latency histograms, in
+	// increasing order (optional, defaults to 50ms through 1m)
+	LatencyBuckets []time.Duration
+	// SizeBuckets are the upper bounds of the payload size histograms, in
+	// bytes and increasing order (optional, defaults to 256B through 16MiB)
+	SizeBuckets []int64
+}
+
+// Metrics collects histograms of the latency and payload sizes of API
+// requests per endpoint and model, since models have very different latency
+// profiles and need separate SLOs. Set it as ClientConfig.Metrics; share one
+// between clients to aggregate them. It is safe for concurrent use.
+type Metrics struct {
+	latencyBounds []float64
+	sizeBounds    []float64
+
+	mu     sync.Mutex
+	series map[metricsKey]*EndpointMetrics
+}
+
+type metricsKey struct {
+	endpoint string
+	model    TTSModel
+}
+
+// EndpointMetrics are the metrics of one endpoint and model.
+type EndpointMetrics struct {
+	// Endpoint is the request path, such as /v1/text-to-speech, with voice
+	// IDs replaced by {voice_id}
+	Endpoint string `json:"endpoint"`
+	// Model is the model of synthesis requests, if any
+	Model TTSModel `json:"model,omitempty"`
+	// Requests is the number of requests sent, counting each retry
+	Requests int `json:"requests"`
+	// Errors is the number of requests that failed to send or got an error
+	// status
+	Errors int `json:"errors"`
+	// Latency is the time until the response headers arrived, in seconds
+	Latency Histogram `json:"latency"`
+	// RequestSize is the size of the request bodies, in bytes
+	RequestSize Histogram `json:"request_size"`
+	// ResponseSize is the size of the response bodies read, in bytes
+	ResponseSize Histogram `json:"response_size"`
+}
+
+// Histogram counts observations in buckets.
+type Histogram struct {
+	// Bounds are the inclusive upper bounds of the buckets
+	Bounds []float64 `json:"bounds"`
+	// Counts holds the number of observations per bucket, and those above
+	// the last bound in a final bucket
+	Counts []int `json:"counts"`
+	// Count is the number of observations
+	Count int `json:"count"`
+	// Sum is the sum of the observations
+	Sum float64 `json:"sum"`
+}
+
+// Quantile estimates the q-quantile (0 to 1) of the observations as the
+// upper bound of the bucket it falls in, or +Inf if that is the final
+// bucket. It returns 0 without observations.
+func (h Histogram) Quantile(q float64) float64 {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(q * float64(h.Count)))
+	seen := 0
+	for i, bound := range h.Bounds {
+		seen += h.Counts[i]
+		if seen >= rank {
+			return bound
+		}
+	}
+	return math.Inf(1)
+}
+
+// Mean returns the mean of the observations, or 0 without any.
+func (h Histogram) Mean() float64 {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / float64(h.Count)
+}
+
+func (h *Histogram) observe(value float64) {
+	i := sort.SearchFloat64s(h.Bounds, value)
+	h.Counts[i]++
+	h.Count++
+	h.Sum += value
+}
+
+func (h Histogram) clone() Histogram {
+	h.Counts = append([]int(nil), h.Counts...)
+	return h
+}
+
+// NewMetrics returns empty metrics with the given buckets.
+func NewMetrics(config MetricsConfig) *Metrics {
+	latency := config.LatencyBuckets
+	if len(latency) == 0 {
+		latency = defaultLatencyBuckets
+	}
+	sizes := config.SizeBuckets
+	if len(sizes) == 0 {
+		sizes = defaultSizeBuckets
+	}
+	m := &Metrics{series: map[metricsKey]*EndpointMetrics{}}
+	for _, bound := range latency {
+		m.latencyBounds = append(m.latencyBounds, bound.Seconds())
+	}
+	for _, bound := range sizes {
+		m.sizeBounds = append(m.sizeBounds, float64(bound))
+	}
+	return m
+}
+
+// Endpoints returns the metrics of each endpoint and model seen, sorted by
+// endpoint and then model.
+func (m *Metrics) Endpoints() []EndpointMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	endpoints := make([]EndpointMetrics, 0, len(m.series))
+	for _, series := range m.series {
+		snapshot := *series
+		snapshot.Latency = series.Latency.clone()
+		snapshot.RequestSize = series.RequestSize.clone()
+		snapshot.ResponseSize = series.ResponseSize.clone()
+		endpoints = append(endpoints, snapshot)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Endpoint != endpoints[j].Endpoint {
+			return endpoints[i].Endpoint < endpoints[j].Endpoint
+		}
+		return endpoints[i].Model < endpoints[j].Model
+	})
+	return endpoints
+}
+
+// JSON returns Endpoints as indented JSON.
+func (m *Metrics) JSON() ([]byte, error) {
+	return json.MarshalIndent(m.Endpoints(), "", "  ")
+}
+
+// record calls fn with the series of key under the lock, creating it first.
+func (m *Metrics) record(key metricsKey, fn func(*EndpointMetrics)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	series, ok := m.series[key]
+	if !ok {
+		series = &EndpointMetrics{
+			Endpoint:     key.endpoint,
+			Model:        key.model,
+			Latency:      Histogram{Bounds: m.latencyBounds, Counts: make([]int, len(m.latencyBounds)+1)},
+			RequestSize:  Histogram{Bounds: m.sizeBounds, Counts: make([]int, len(m.sizeBounds)+1)},
+			ResponseSize: Histogram{Bounds: m.sizeBounds, Counts: make([]int, len(m.sizeBounds)+1)},
+		}
+		m.series[key] = series
+	}
+	fn(series)
+}
+
+type metricsModelKey struct{}
+
+// withMetricsModel returns ctx carrying the model of the JSON request body,
+// if it has one, for Metrics.
+func withMetricsModel(ctx context.Context, body []byte) context.Context {
+	var request struct {
+		Model TTSModel `json:"model"`
+	}
+	if json.Unmarshal(body, &request) != nil || request.Model == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, metricsModelKey{}, request.Model)
+}
+
+// observeRequest records one attempt of req that took latency, and wraps
+// the response body to record its size once it is read.
+func (c *Client) observeRequest(req *http.Request, resp *http.Response, err error, latency time.Duration) {
+	if c.metrics == nil {
+		return
+	}
+	model, _ := req.Context().Value(metricsModelKey{}).(TTSModel)
+	key := metricsKey{endpoint: c.metricsEndpoint(req), model: model}
+	c.metrics.record(key, func(series *EndpointMetrics) {
+		series.Requests++
+		if err != nil || resp.StatusCode >= 400 {
+			series.Errors++
+		}
+		series.Latency.observe(latency.Seconds())
+		if req.ContentLength > 0 {
+			series.RequestSize.observe(float64(req.ContentLength))
+		}
+	})
+	if resp != nil {
+		resp.Body = &metricsBody{ReadCloser: resp.Body, metrics: c.metrics, key: key}
+	}
+}
+
+// metricsEndpoint returns the path of req relative to the base URL, with
+// voice IDs replaced so each endpoint is one series.
+func (c *Client) metricsEndpoint(req *http.Request) string {
+	base, _ := url.Parse(c.baseURL)
+	path := strings.TrimPrefix(req.URL.Path, strings.TrimRight(base.Path, "/"))
+	segments := strings.Split(path, "/")
+	for i := 1; i < len(segments); i++ {
+		if segments[i-1] == "voices" && segments[i] != "clone" && segments[i] != "recommendations" {
+			segments[i] = "{voice_id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// metricsBody records the size of a response body once it is read to the
+// end or closed.
+type metricsBody struct {
+	io.ReadCloser
+	metrics *Metrics
+	key     metricsKey
+	size    int64
+	done    bool
+}
+
+func (b *metricsBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *metricsBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish()
+	return err
+}
+
+func (b *metricsBody) finish() {
+	if b.done {
+		return
+	}
+	b.done = true
+	b.metrics.record(b.key, func(series *EndpointMetrics) {
+		series.ResponseSize.observe(float64(b.size))
+	})
+}
@@ -0,0 +1,116 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsPerEndpointAndModel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/typecast/v2/voices/") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"detail":"no such voice"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write(make([]byte, 2000))
+	}))
+	defer srv.Close()
+	clock := NewFakeClock(time.Unix(0, 0))
+	latency := map[TTSModel]time.Duration{ModelSSFMV30: 2 * time.Second, ModelSSFMV21: 300 * time.Millisecond}
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		var request TTSRequest
+		if r.GetBody != nil {
+			body, _ := r.GetBody()
+			_ = json.NewDecoder(body).Decode(&request)
+		}
+		clock.Advance(latency[request.Model])
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	metrics := NewMetrics(MetricsConfig{})
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL + "/typecast", HTTPClient: &http.Client{Transport: transport}, Clock: clock, Metrics: metrics})
+	ctx := context.Background()
+	for _, model := range []TTSModel{ModelSSFMV30, ModelSSFMV30, ModelSSFMV21} {
+		if _, err := c.TextToSpeech(ctx, &TTSRequest{VoiceID: "tc_1", Text: "hello", Model: model}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.GetVoiceV2(ctx, "tc_1"); err == nil {
+		t.Fatal("expected a 404")
+	}
+
+	endpoints := metrics.Endpoints()
+	if len(endpoints) != 3 {
+		t.Fatalf("unexpected series %+v", endpoints)
+	}
+	v21, v30, voice := endpoints[0], endpoints[1], endpoints[2]
+	if v21.Endpoint != "/v1/text-to-speech" || v21.Model != ModelSSFMV21 || v30.Model != ModelSSFMV30 || v30.Requests != 2 {
+		t.Fatalf("unexpected series %+v %+v", v21, v30)
+	}
+	if p50 := v30.Latency.Quantile(0.5); p50 != 2.5 {
+		t.Fatalf("v30 p50 = %v, want 2.5", p50)
+	}
+	if p50 := v21.Latency.Quantile(0.5); p50 != 0.5 {
+		t.Fatalf("v21 p50 = %v, want 0.5", p50)
+	}
+	if v30.Latency.Mean() != 2 || v30.ResponseSize.Count != 2 || v30.ResponseSize.Quantile(1) != 4096 || v30.RequestSize.Count != 2 {
+		t.Fatalf("unexpected histograms %+v", v30)
+	}
+	if voice.Endpoint != "/v2/voices/{voice_id}" || voice.Model != "" || voice.Errors != 1 || voice.RequestSize.Count != 0 {
+		t.Fatalf("unexpected series %+v", voice)
+	}
+
+	data, err := metrics.JSON()
+	if err != nil || !strings.Contains(string(data), `"endpoint": "/v1/text-to-speech"`) {
+		t.Fatalf("unexpected JSON %s: %v", data, err)
+	}
+}
+
+func TestMetricsTransportErrorsAndBuckets(t *testing.T) {
+	metrics := NewMetrics(MetricsConfig{LatencyBuckets: []time.Duration{time.Second}, SizeBuckets: []int64{10}})
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://api.test", Metrics: metrics, HTTPClient: &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if r.URL.Path == "/v1/voices/clone" {
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"voice_id":"uc_1"}`)), Header: http.Header{}}, nil
+			}
+			return nil, io.ErrUnexpectedEOF
+		}),
+	}})
+	_, _ = c.GetMySubscription(context.Background())
+	if _, err := c.CloneVoice(context.Background(), []byte("RIFF"), "sample.wav", "MyVoice", "ssfm-v30"); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = c.composeTextToSpeech(context.Background(), []string{"not an object"})
+
+	endpoints := metrics.Endpoints()
+	if len(endpoints) != 3 {
+		t.Fatalf("unexpected series %+v", endpoints)
+	}
+	compose, subscription, clone := endpoints[0], endpoints[1], endpoints[2]
+	if compose.Endpoint != "/v1/text-to-speech/compose" || compose.Model != "" {
+		t.Fatalf("unexpected series %+v", compose)
+	}
+	if subscription.Endpoint != "/v1/users/me/subscription" || subscription.Errors != 1 || subscription.ResponseSize.Count != 0 {
+		t.Fatalf("unexpected series %+v", subscription)
+	}
+	if clone.Endpoint != "/v1/voices/clone" || clone.ResponseSize.Counts[1] != 1 || clone.RequestSize.Quantile(0.5) != math.Inf(1) {
+		t.Fatalf("unexpected series %+v", clone)
+	}
+
+	// Snapshots do not change with later requests.
+	_, _ = c.GetMySubscription(context.Background())
+	if subscription.Latency.Count != 1 || subscription.Latency.Counts[0] != 1 {
+		t.Fatalf("the snapshot changed: %+v", subscription.Latency)
+	}
+	var empty Histogram
+	if empty.Quantile(0.5) != 0 || empty.Mean() != 0 {
+		t.Fatal("an empty histogram must report 0")
+	}
+}
@@ -0,0 +1,110 @@
+package typecast
+
+import "fmt"
+
+// modelEmotions lists the emotion presets each known model supports, for
+// requests migrated without a V2 voice to check against.
+var modelEmotions = map[TTSModel][]EmotionPreset{
+	ModelSSFMV21: {EmotionNormal, EmotionSad, EmotionHappy, EmotionAngry},
+	ModelSSFMV30: {EmotionNormal, EmotionSad, EmotionHappy, EmotionAngry, EmotionWhisper, EmotionToneUp, EmotionToneDown},
+}
+
+// VoicesV1ToV2 converts V1 voices, which list one entry per voice and model,
+// into V2 voices with one entry per voice listing each model and its
+// emotions. Voices keep the order of their first entry. V1 carries no
+// gender, age, or use cases, so those are left empty.
+func VoicesV1ToV2(voices []VoiceV1) []VoiceV2 {
+	var out []VoiceV2
+	index := map[string]int{}
+	for _, v := range voices {
+		i, ok := index[v.VoiceID]
+		if !ok {
+			i = len(out)
+			index[v.VoiceID] = i
+			out = append(out, VoiceV2{VoiceID: v.VoiceID, VoiceName: v.VoiceName})
+		}
+		out[i].Models = append(out[i].Models, ModelInfo{Version: v.Model, Emotions: v.Emotions})
+	}
+	return out
+}
+
+// V2Migration configures MigrateTTSRequest.
+type V2Migration struct {
+	// VoiceIDs maps old voice IDs to their V2 replacements (optional,
+	// unmapped IDs are kept)
+	VoiceIDs map[string]string
+	// Model is the model to migrate to (optional, defaults to ssfm-v30)
+	Model TTSModel
+	// Voice is the V2 voice the request will use, to check its models and
+	// emotions against (optional, known models' presets are used otherwise)
+	Voice *VoiceV2
+}
+
+// MigrateTTSRequest returns a copy of request rewritten for V2: its voice ID
+// mapped, its model set to m.Model (or the voice's best model when the voice
+// lacks it), and its emotion prompt converted to the model's prompt type.
+// An emotion the model or voice does not support falls back to normal, and
+// a smart prompt, which ssfm-v21 lacks, is dropped. Each such adjustment is
+// described in the returned notes, so migrations can be reviewed.
+func MigrateTTSRequest(request *TTSRequest, m V2Migration) (*TTSRequest, []string) {
+	out := *request
+	var notes []string
+	if id, ok := m.VoiceIDs[out.VoiceID]; ok {
+		out.VoiceID = id
+	}
+	out.Model = m.Model
+	if out.Model == "" {
+		out.Model = ModelSSFMV30
+	}
+	if m.Voice != nil && len(m.Voice.Models) > 0 && !m.Voice.SupportsModel(out.Model) {
+		best := m.Voice.BestModel()
+		notes = append(notes, fmt.Sprintf("voice %s does not support %s; using %s", out.VoiceID, out.Model, best))
+		out.Model = best
+	}
+
+	var preset EmotionPreset
+	var intensity *float64
+	switch p := out.Prompt.(type) {
+	case *Prompt:
+		preset, intensity = p.EmotionPreset, p.EmotionIntensity
+	case Prompt:
+		preset, intensity = p.EmotionPreset, p.EmotionIntensity
+	case *PresetPrompt:
+		preset, intensity = p.EmotionPreset, p.EmotionIntensity
+	case PresetPrompt:
+		preset, intensity = p.EmotionPreset, p.EmotionIntensity
+	case *SmartPrompt, SmartPrompt:
+		if out.Model == ModelSSFMV21 {
+			notes = append(notes, "ssfm-v21 does not support smart prompts; dropping the prompt")
+			out.Prompt = nil
+		}
+		return &out, notes
+	default:
+		return &out, notes // no prompt, or one this helper does not know
+	}
+
+	if preset != "" && !supportsEmotion(m.Voice, out.Model, preset) {
+		notes = append(notes, fmt.Sprintf("%s does not support emotion %s; using normal", out.Model, preset))
+		preset = EmotionNormal
+	}
+	out.Prompt = VoiceProfile{EmotionPreset: preset, EmotionIntensity: intensity}.prompt(out.Model)
+	return &out, notes
+}
+
+// supportsEmotion reports whether emotion e is supported with model, by the
+// voice when it lists the model, or by the model otherwise.
+func supportsEmotion(voice *VoiceV2, model TTSModel, e EmotionPreset) bool {
+	if voice != nil && voice.SupportsModel(model) {
+		return voice.SupportsEmotion(model, e)
+	}
+	emotions, known := modelEmotions[model]
+	if !known {
+		return true
+	}
+	for _, emotion := range emotions {
+		if emotion == e {
+			return true
+		}
+	}
+	return false
+}
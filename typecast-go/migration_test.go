@@ -0,0 +1,110 @@
+package typecast
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVoicesV1ToV2(t *testing.T) {
+	got := VoicesV1ToV2([]VoiceV1{
+		{VoiceID: "tc_a", VoiceName: "A", Model: ModelSSFMV21, Emotions: []string{"normal"}},
+		{VoiceID: "tc_b", VoiceName: "B", Model: ModelSSFMV30, Emotions: []string{"normal", "whisper"}},
+		{VoiceID: "tc_a", VoiceName: "A", Model: ModelSSFMV30, Emotions: []string{"normal", "sad"}},
+	})
+	want := []VoiceV2{
+		{VoiceID: "tc_a", VoiceName: "A", Models: []ModelInfo{
+			{Version: ModelSSFMV21, Emotions: []string{"normal"}},
+			{Version: ModelSSFMV30, Emotions: []string{"normal", "sad"}},
+		}},
+		{VoiceID: "tc_b", VoiceName: "B", Models: []ModelInfo{{Version: ModelSSFMV30, Emotions: []string{"normal", "whisper"}}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("VoicesV1ToV2() = %+v", got)
+	}
+	if VoicesV1ToV2(nil) != nil {
+		t.Fatal("expected no voices")
+	}
+}
+
+func TestMigrateTTSRequest(t *testing.T) {
+	intensity := 1.5
+	v30Only := &VoiceV2{VoiceID: "tc_new", Models: []ModelInfo{{Version: ModelSSFMV30, Emotions: []string{"normal", "happy"}}}}
+	tests := []struct {
+		name      string
+		request   TTSRequest
+		migration V2Migration
+		model     TTSModel
+		prompt    interface{}
+		notes     string
+	}{
+		{
+			name:      "v21 prompt to v30 preset",
+			request:   TTSRequest{VoiceID: "old", Model: ModelSSFMV21, Prompt: &Prompt{EmotionPreset: EmotionSad, EmotionIntensity: &intensity}},
+			migration: V2Migration{VoiceIDs: map[string]string{"old": "tc_new"}},
+			model:     ModelSSFMV30,
+			prompt:    &PresetPrompt{EmotionType: "preset", EmotionPreset: EmotionSad, EmotionIntensity: &intensity},
+		},
+		{
+			name:      "v30 preset to v21 falls back to normal",
+			request:   TTSRequest{VoiceID: "tc_new", Model: ModelSSFMV30, Prompt: PresetPrompt{EmotionType: "preset", EmotionPreset: EmotionWhisper}},
+			migration: V2Migration{Model: ModelSSFMV21},
+			model:     ModelSSFMV21,
+			prompt:    &Prompt{EmotionPreset: EmotionNormal},
+			notes:     "ssfm-v21 does not support emotion whisper; using normal",
+		},
+		{
+			name:      "value prompt checked against the voice",
+			request:   TTSRequest{VoiceID: "tc_new", Model: ModelSSFMV21, Prompt: Prompt{EmotionPreset: EmotionAngry}},
+			migration: V2Migration{Model: ModelSSFMV21, Voice: v30Only},
+			model:     ModelSSFMV30,
+			prompt:    &PresetPrompt{EmotionType: "preset", EmotionPreset: EmotionNormal},
+			notes:     "voice tc_new does not support ssfm-v21; using ssfm-v30|ssfm-v30 does not support emotion angry; using normal",
+		},
+		{
+			name:      "intensity only",
+			request:   TTSRequest{VoiceID: "tc_new", Prompt: &PresetPrompt{EmotionType: "preset", EmotionIntensity: &intensity}},
+			migration: V2Migration{Model: "ssfm-v99"},
+			model:     "ssfm-v99",
+			prompt:    &PresetPrompt{EmotionType: "preset", EmotionIntensity: &intensity},
+		},
+		{
+			name:      "unknown model allows any emotion",
+			request:   TTSRequest{VoiceID: "tc_new", Prompt: &Prompt{EmotionPreset: EmotionWhisper}},
+			migration: V2Migration{Model: "ssfm-v99"},
+			model:     "ssfm-v99",
+			prompt:    &PresetPrompt{EmotionType: "preset", EmotionPreset: EmotionWhisper},
+		},
+		{
+			name:      "smart prompt dropped for v21",
+			request:   TTSRequest{VoiceID: "tc_new", Prompt: &SmartPrompt{EmotionType: "smart"}},
+			migration: V2Migration{Model: ModelSSFMV21},
+			model:     ModelSSFMV21,
+			notes:     "ssfm-v21 does not support smart prompts; dropping the prompt",
+		},
+		{
+			name:    "smart prompt kept for v30",
+			request: TTSRequest{VoiceID: "tc_new", Prompt: SmartPrompt{EmotionType: "smart"}},
+			model:   ModelSSFMV30,
+			prompt:  SmartPrompt{EmotionType: "smart"},
+		},
+		{
+			name:    "no prompt",
+			request: TTSRequest{VoiceID: "tc_new"},
+			model:   ModelSSFMV30,
+		},
+	}
+	for _, tt := range tests {
+		original := tt.request
+		got, notes := MigrateTTSRequest(&tt.request, tt.migration)
+		if got.Model != tt.model || !reflect.DeepEqual(got.Prompt, tt.prompt) || strings.Join(notes, "|") != tt.notes {
+			t.Errorf("%s: got model %s, prompt %#v, notes %q", tt.name, got.Model, got.Prompt, notes)
+		}
+		if tt.migration.VoiceIDs != nil && got.VoiceID != "tc_new" {
+			t.Errorf("%s: expected the voice ID to be mapped, got %s", tt.name, got.VoiceID)
+		}
+		if !reflect.DeepEqual(tt.request, original) {
+			t.Errorf("%s: the original request was modified", tt.name)
+		}
+	}
+}
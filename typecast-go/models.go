@@ -90,6 +90,12 @@ type Output struct {
 	AudioTempo *float64 `json:"audio_tempo,omitempty"`
 	// AudioFormat is the output format (wav or mp3, default: wav)
 	AudioFormat AudioFormat `json:"audio_format,omitempty"`
+	// SampleRate is the output sample rate in Hz (see SupportedSampleRates, optional)
+	SampleRate *int `json:"sample_rate,omitempty"`
+	// Channels is the number of audio channels (1 or 2, optional)
+	Channels *int `json:"channels,omitempty"`
+	// Bitrate is the MP3 bitrate in kbps (see SupportedMP3Bitrates, optional, mp3 only)
+	Bitrate *int `json:"bitrate,omitempty"`
 }
 
 // Validate checks the Output fields for invalid values.
@@ -98,24 +104,24 @@ func (o *Output) Validate() error {
 		return nil
 	}
 	if o.Volume != nil && o.TargetLUFS != nil {
-		return fmt.Errorf("volume and target_lufs are mutually exclusive")
+		return newValidationError("volume", "volume and target_lufs are mutually exclusive")
 	}
 	if o.Volume != nil && (*o.Volume < 0 || *o.Volume > 200) {
-		return fmt.Errorf("volume must be between 0 and 200")
+		return newValidationError("volume", "volume must be between 0 and 200")
 	}
 	if o.TargetLUFS != nil && (math.IsNaN(*o.TargetLUFS) || math.IsInf(*o.TargetLUFS, 0) || *o.TargetLUFS < -70 || *o.TargetLUFS > 0) {
-		return fmt.Errorf("target_lufs must be between -70 and 0")
+		return newValidationError("target_lufs", "target_lufs must be between -70 and 0")
 	}
 	if o.AudioPitch != nil && (*o.AudioPitch < -12 || *o.AudioPitch > 12) {
-		return fmt.Errorf("audio_pitch must be between -12 and 12")
+		return newValidationError("audio_pitch", "audio_pitch must be between -12 and 12")
 	}
 	if o.AudioTempo != nil && (*o.AudioTempo < 0.5 || *o.AudioTempo > 2.0) {
-		return fmt.Errorf("audio_tempo must be between 0.5 and 2.0")
+		return newValidationError("audio_tempo", "audio_tempo must be between 0.5 and 2.0")
 	}
 	if o.AudioFormat != "" && o.AudioFormat != AudioFormatWAV && o.AudioFormat != AudioFormatMP3 {
-		return fmt.Errorf("audio_format must be one of wav or mp3")
+		return newValidationError("audio_format", "audio_format must be one of wav or mp3")
 	}
-	return nil
+	return o.validateEncoding()
 }
 
 // Prompt represents emotion settings for ssfm-v21 model
@@ -191,13 +197,13 @@ func (r *GenerateToFileRequest) Validate() error {
 		return fmt.Errorf("request cannot be nil")
 	}
 	if strings.TrimSpace(r.VoiceID) == "" {
-		return fmt.Errorf("voice_id is required")
+		return newValidationError("voice_id", "voice_id is required")
 	}
 	if strings.TrimSpace(r.Text) == "" {
-		return fmt.Errorf("text is required")
+		return newValidationError("text", "text is required")
 	}
 	if utf8.RuneCountInString(r.Text) > 2000 {
-		return fmt.Errorf("text must not exceed 2000 characters")
+		return newValidationError("text", "text must not exceed 2000 characters")
 	}
 	return r.Output.Validate()
 }
@@ -237,16 +243,16 @@ func (o *OutputStream) Validate() error {
 		return nil
 	}
 	if o.AudioPitch != nil && (*o.AudioPitch < -12 || *o.AudioPitch > 12) {
-		return fmt.Errorf("audio_pitch must be between -12 and 12")
+		return newValidationError("audio_pitch", "audio_pitch must be between -12 and 12")
 	}
 	if o.AudioTempo != nil && (*o.AudioTempo < 0.5 || *o.AudioTempo > 2.0) {
-		return fmt.Errorf("audio_tempo must be between 0.5 and 2.0")
+		return newValidationError("audio_tempo", "audio_tempo must be between 0.5 and 2.0")
 	}
 	if o.AudioFormat != "" && o.AudioFormat != AudioFormatWAV && o.AudioFormat != AudioFormatMP3 {
-		return fmt.Errorf("audio_format must be one of wav or mp3")
+		return newValidationError("audio_format", "audio_format must be one of wav or mp3")
 	}
 	if o.TargetLUFS != nil && (*o.TargetLUFS < -70 || *o.TargetLUFS > 0) {
-		return fmt.Errorf("target_lufs must be between -70 and 0")
+		return newValidationError("target_lufs", "target_lufs must be between -70 and 0")
 	}
 	return nil
 }
@@ -273,16 +279,16 @@ type TTSRequestStream struct {
 // Validate checks the TTSRequestStream fields for invalid values.
 func (r *TTSRequestStream) Validate() error {
 	if r.VoiceID == "" {
-		return fmt.Errorf("voice_id is required")
+		return newValidationError("voice_id", "voice_id is required")
 	}
 	if r.Text == "" {
-		return fmt.Errorf("text is required")
+		return newValidationError("text", "text is required")
 	}
 	if utf8.RuneCountInString(r.Text) > 2000 {
-		return fmt.Errorf("text must not exceed 2000 characters")
+		return newValidationError("text", "text must not exceed 2000 characters")
 	}
 	if r.Model == "" {
-		return fmt.Errorf("model is required")
+		return newValidationError("model", "model is required")
 	}
 	return r.Output.Validate()
 }
@@ -331,6 +337,14 @@ type VoiceV2 struct {
 	Age *AgeEnum `json:"age,omitempty"`
 	// UseCases is the list of use case categories
 	UseCases []string `json:"use_cases,omitempty"`
+	// Languages lists the ISO 639-3 codes the voice is suited for, when provided
+	Languages []string `json:"languages,omitempty"`
+	// Tags lists style descriptors such as "warm" or "authoritative", when provided
+	Tags []string `json:"tags,omitempty"`
+	// Description is the voice's catalog description, when provided
+	Description string `json:"description,omitempty"`
+	// SampleText is the voice's default preview line, when provided
+	SampleText string `json:"sample_text,omitempty"`
 }
 
 // RecommendedVoice is a single voice recommendation result.
@@ -357,6 +371,18 @@ type VoicesV2Filter struct {
 	Age AgeEnum `url:"age,omitempty"`
 	// UseCases filters by use case
 	UseCases UseCaseEnum `url:"use_cases,omitempty"`
+	// Models matches voices supporting any of the listed models (combined with Model)
+	Models []TTSModel `url:"model,omitempty"`
+	// Genders matches voices of any of the listed genders (combined with Gender)
+	Genders []GenderEnum `url:"gender,omitempty"`
+	// Ages matches voices in any of the listed age groups (combined with Age)
+	Ages []AgeEnum `url:"age,omitempty"`
+	// UseCasesAnyOf matches voices with any of the listed use cases (combined with UseCases)
+	UseCasesAnyOf []UseCaseEnum `url:"use_cases,omitempty"`
+	// Language filters by ISO 639-3 language code (e.g., "kor", "spa")
+	Language string `url:"language,omitempty"`
+	// Tags matches voices carrying all of the listed tags (filtered client-side)
+	Tags []string `url:"-"`
 }
 
 // ErrorResponse represents an API error response
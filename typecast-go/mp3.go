@@ -0,0 +1,130 @@
+package typecast
+
+import "fmt"
+
+// mp3Bitrates lists Layer III bitrates in kbps by bitrate index, for MPEG-1
+// and for MPEG-2/2.5.
+var mp3Bitrates = [2][15]int{
+	{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+}
+
+// mp3SampleRates lists MPEG-1 sample rates by sample rate index; MPEG-2
+// halves them and MPEG-2.5 quarters them.
+var mp3SampleRates = [3]int{44100, 48000, 32000}
+
+// mp3Frame locates one MPEG audio Layer III frame.
+type mp3Frame struct {
+	offset     int
+	length     int
+	samples    int
+	sampleRate int
+	bitrate    int
+	channels   int
+}
+
+func (f mp3Frame) duration() float64 {
+	return float64(f.samples) / float64(f.sampleRate)
+}
+
+// parseMP3Header decodes the 4-byte frame header at b[0:4].
+func parseMP3Header(b []byte) (mp3Frame, bool) {
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
+	}
+	version := (b[1] >> 3) & 3 // 0: MPEG-2.5, 2: MPEG-2, 3: MPEG-1
+	layer := (b[1] >> 1) & 3   // 1: Layer III
+	bitrateIndex := int(b[2] >> 4)
+	rateIndex := int(b[2]>>2) & 3
+	if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return mp3Frame{}, false
+	}
+	padding := int(b[2]>>1) & 1
+
+	frame := mp3Frame{
+		sampleRate: mp3SampleRates[rateIndex],
+		samples:    1152,
+		bitrate:    mp3Bitrates[0][bitrateIndex] * 1000,
+		channels:   2,
+	}
+	if version != 3 {
+		frame.sampleRate /= 2
+		if version == 0 {
+			frame.sampleRate /= 2
+		}
+		frame.samples = 576
+		frame.bitrate = mp3Bitrates[1][bitrateIndex] * 1000
+	}
+	if b[3]>>6 == 3 {
+		frame.channels = 1
+	}
+	frame.length = frame.samples/8*frame.bitrate/frame.sampleRate + padding
+	return frame, true
+}
+
+// parseMP3Frames returns the Layer III frames in b, skipping a leading ID3v2
+// tag and any bytes between frames that do not start a valid frame. A final
+// frame truncated by the end of b is dropped.
+func parseMP3Frames(b []byte) ([]mp3Frame, error) {
+	start := len(b) - len(stripID3v2(b))
+	var frames []mp3Frame
+	for pos := start; pos+4 <= len(b); {
+		frame, ok := parseMP3Header(b[pos:])
+		if !ok {
+			pos++
+			continue
+		}
+		if pos+frame.length > len(b) {
+			break
+		}
+		frame.offset = pos
+		frames = append(frames, frame)
+		pos += frame.length
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no MP3 frames found")
+	}
+	return frames, nil
+}
+
+// mp3Segmenter groups MP3 frames into segments of at most target seconds,
+// cut at frame boundaries, and hands each completed segment to flush.
+type mp3Segmenter struct {
+	target float64
+	flush  func(audio []byte, frames []mp3Frame, duration float64) error
+
+	audio    []byte
+	frames   []mp3Frame
+	duration float64
+}
+
+// add appends frames, parsed from mp3, to the pending segment, flushing it
+// whenever the next frame would push it past the target. The offsets of the
+// frames passed to flush are relative to its audio.
+func (s *mp3Segmenter) add(mp3 []byte, frames []mp3Frame) error {
+	for _, frame := range frames {
+		if len(s.frames) > 0 && s.duration+frame.duration() > s.target {
+			if err := s.finish(); err != nil {
+				return err
+			}
+		}
+		data := mp3[frame.offset : frame.offset+frame.length]
+		frame.offset = len(s.audio)
+		s.audio = append(s.audio, data...)
+		s.frames = append(s.frames, frame)
+		s.duration += frame.duration()
+	}
+	return nil
+}
+
+// finish flushes the pending segment, if any.
+func (s *mp3Segmenter) finish() error {
+	if len(s.frames) == 0 {
+		return nil
+	}
+	if err := s.flush(s.audio, s.frames, s.duration); err != nil {
+		return err
+	}
+	s.audio, s.frames, s.duration = nil, nil, 0
+	return nil
+}
@@ -0,0 +1,62 @@
+package typecast
+
+import (
+	"bytes"
+	"testing"
+)
+
+// makeTestMP3 returns n silent MPEG-1 Layer III frames at 128 kbps and
+// 44.1 kHz, 417 bytes and 1152 samples each.
+func makeTestMP3(n int) []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x64})
+	return bytes.Repeat(frame, n)
+}
+
+func TestParseMP3Header(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     []byte
+		ok         bool
+		length     int
+		samples    int
+		sampleRate int
+	}{
+		{"mpeg1", []byte{0xFF, 0xFB, 0x90, 0x64}, true, 417, 1152, 44100},
+		{"mpeg1 padded", []byte{0xFF, 0xFB, 0x92, 0x64}, true, 418, 1152, 44100},
+		{"mpeg2", []byte{0xFF, 0xF3, 0x84, 0x64}, true, 192, 576, 24000},
+		{"mpeg2.5", []byte{0xFF, 0xE3, 0x84, 0x64}, true, 384, 576, 12000},
+		{"short", []byte{0xFF, 0xFB}, false, 0, 0, 0},
+		{"no sync", []byte{0x49, 0x44, 0x33, 0x04}, false, 0, 0, 0},
+		{"reserved version", []byte{0xFF, 0xEB, 0x90, 0x64}, false, 0, 0, 0},
+		{"layer ii", []byte{0xFF, 0xFD, 0x90, 0x64}, false, 0, 0, 0},
+		{"free bitrate", []byte{0xFF, 0xFB, 0x00, 0x64}, false, 0, 0, 0},
+		{"reserved rate", []byte{0xFF, 0xFB, 0x9C, 0x64}, false, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, ok := parseMP3Header(tt.header)
+			if ok != tt.ok || frame.length != tt.length || frame.samples != tt.samples || frame.sampleRate != tt.sampleRate {
+				t.Fatalf("parseMP3Header() = %+v, %v", frame, ok)
+			}
+		})
+	}
+}
+
+func TestParseMP3Frames(t *testing.T) {
+	tag := hlsTimestampTag(0)
+	data := append(append(append(tag, 0x00, 0xFF), makeTestMP3(2)...), makeTestMP3(1)[:100]...)
+	frames, err := parseMP3Frames(data)
+	if err != nil {
+		t.Fatalf("parseMP3Frames() error = %v", err)
+	}
+	if len(frames) != 2 || frames[0].offset != len(tag)+2 || frames[1].offset != len(tag)+2+417 {
+		t.Fatalf("unexpected frames: %+v", frames)
+	}
+	if !approx(frames[0].duration(), 1152.0/44100) {
+		t.Fatalf("duration() = %v", frames[0].duration())
+	}
+	if _, err := parseMP3Frames(makeTestWAV(make([]byte, 1600), 8000)); err == nil {
+		t.Fatal("expected error for non-mp3 audio")
+	}
+}
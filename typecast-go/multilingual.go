@@ -0,0 +1,198 @@
+package typecast
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultMinForeignWords is the fewest words a run in another script needs
+// to become its own segment when LanguageSplitOptions.MinWords is not set.
+const defaultMinForeignWords = 3
+
+// LanguageSegment is a run of text in one language.
+type LanguageSegment struct {
+	// Language is the segment's ISO 639-3 code, or "" when DetectLanguage
+	// cannot tell
+	Language string
+	// Text is the segment's text, including the whitespace that follows it
+	Text string
+}
+
+// LanguageSplitOptions configures SplitByLanguage.
+type LanguageSplitOptions struct {
+	// MinWords is the fewest words a run in another script needs to become
+	// its own segment. Shorter runs, such as an English product name in a
+	// Korean sentence, stay in the surrounding segment (optional, defaults
+	// to 3)
+	MinWords int
+}
+
+// languageRun is a run of words in one script while SplitByLanguage
+// segments text.
+type languageRun struct {
+	script string
+	words  int
+	text   string
+}
+
+// SplitByLanguage segments text that alternates languages, such as Korean
+// narration with full English sentences, into runs of one script each,
+// labeled with their DetectLanguage language. A run shorter than MinWords
+// stays in the segment around it unless it is a whole sentence. Numbers and
+// punctuation stay with the words around them. Joining the segments' Text
+// gives back text.
+func SplitByLanguage(text string, opts *LanguageSplitOptions) []LanguageSegment {
+	minWords := defaultMinForeignWords
+	if opts != nil && opts.MinWords > 0 {
+		minWords = opts.MinWords
+	}
+	var runs []languageRun
+	for _, word := range splitWords(text) {
+		script := scriptGroup(dominantScript(word))
+		last := len(runs) - 1
+		switch {
+		case last >= 0 && (script == "" || script == runs[last].script):
+			runs[last].text += word
+			if script != "" {
+				runs[last].words++
+			}
+		case last >= 0 && runs[last].script == "":
+			runs[last] = languageRun{script: script, words: 1, text: runs[last].text + word}
+		default:
+			words := 1
+			if script == "" {
+				words = 0
+			}
+			runs = append(runs, languageRun{script: script, words: words, text: word})
+		}
+	}
+
+	// Fold runs that cannot stand alone into the run before them, and a
+	// first run that cannot into the one after it.
+	var merged []languageRun
+	for i, run := range runs {
+		last := len(merged) - 1
+		switch {
+		case last >= 0 && run.script == merged[last].script:
+			merged[last].text += run.text
+			merged[last].words += run.words
+		case last >= 0 && !run.standsAlone(runs[i-1].text, i == len(runs)-1, minWords):
+			merged[last].text += run.text
+		default:
+			merged = append(merged, run)
+		}
+	}
+	if len(merged) > 1 && !merged[0].standsAlone("", false, minWords) {
+		merged[1].text = merged[0].text + merged[1].text
+		merged = merged[1:]
+	}
+
+	segments := make([]LanguageSegment, len(merged))
+	for i, run := range merged {
+		segments[i] = LanguageSegment{Language: DetectLanguage(run.text), Text: run.text}
+	}
+	return segments
+}
+
+// standsAlone reports whether the run has minWords words, or is a whole
+// sentence: it follows the text before it, which ends a sentence, and ends
+// one itself or the text when final.
+func (r languageRun) standsAlone(before string, final bool, minWords int) bool {
+	if r.words >= minWords {
+		return true
+	}
+	return (before == "" || endsSentence(before)) && (final || endsSentence(r.text))
+}
+
+// endsSentence reports whether text ends with sentence punctuation.
+func endsSentence(text string) bool {
+	text = strings.TrimRightFunc(text, unicode.IsSpace)
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return isSentenceTerminator(r)
+}
+
+// splitWords splits text into words, each with the whitespace that follows
+// it. Leading whitespace stays with the first word.
+func splitWords(text string) []string {
+	var words []string
+	start, inSpace := 0, true
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if !space && inSpace && i > 0 && strings.TrimSpace(text[start:i]) != "" {
+			words = append(words, text[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	if start < len(text) {
+		words = append(words, text[start:])
+	}
+	return words
+}
+
+// scriptGroup returns the script a language from dominantScript is written
+// in, treating Japanese and Chinese as one so Han words do not split a
+// Japanese sentence.
+func scriptGroup(script string) string {
+	if script == "jpn" || script == "zho" {
+		return "cjk"
+	}
+	return script
+}
+
+// MixedLanguageOptions configures SynthesizeMixedLanguage.
+type MixedLanguageOptions struct {
+	// Default speaks segments in languages missing from Voices (required)
+	Default VoiceProfile
+	// Voices maps ISO 639-3 codes to the profiles that speak segments in
+	// that language (optional). Profiles without a Language are sent the
+	// segment's language. All profiles must use one AudioFormat.
+	Voices map[string]VoiceProfile
+	// MinWords is passed to SplitByLanguage (optional, defaults to 3)
+	MinWords int
+}
+
+// MixedLanguageResult is the stitched audio of a mixed-language text.
+type MixedLanguageResult struct {
+	TTSResponse
+	// Segments lists the segments the text was split into, in order
+	Segments []LanguageSegment
+}
+
+// SynthesizeMixedLanguage splits text with SplitByLanguage, speaks each
+// segment with the profile of its language, and stitches the segments into
+// one audio file with ComposeSpeech.
+func (c *Client) SynthesizeMixedLanguage(ctx context.Context, text string, opts MixedLanguageOptions) (*MixedLanguageResult, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, newValidationError("text", "text is required")
+	}
+	if err := opts.Default.Validate(); err != nil {
+		return nil, fmt.Errorf("default voice: %w", err)
+	}
+	for language, profile := range opts.Voices {
+		if err := profile.Validate(); err != nil {
+			return nil, fmt.Errorf("voice for %s: %w", language, err)
+		}
+	}
+	segments := SplitByLanguage(text, &LanguageSplitOptions{MinWords: opts.MinWords})
+	composer := c.ComposeSpeech()
+	for _, segment := range segments {
+		profile, ok := opts.Voices[segment.Language]
+		if !ok {
+			profile = opts.Default
+		}
+		settings := profile.ComposerSettings()
+		if settings.Language == "" {
+			settings.Language = segment.Language
+		}
+		composer.SayWith(segment.Text, settings)
+	}
+	response, err := composer.Generate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &MixedLanguageResult{TTSResponse: *response, Segments: segments}, nil
+}
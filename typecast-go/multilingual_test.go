@@ -0,0 +1,150 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSplitByLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		opts *LanguageSplitOptions
+		want []LanguageSegment
+	}{
+		{"one language", "오늘은 날씨가 좋네요.", nil, []LanguageSegment{{"kor", "오늘은 날씨가 좋네요."}}},
+		{
+			"product name stays", "새로운 iPhone 15 Pro를 소개합니다.", nil,
+			[]LanguageSegment{{"kor", "새로운 iPhone 15 Pro를 소개합니다."}},
+		},
+		{
+			"full sentences split", "오늘 소개할 제품입니다. This is the best phone we have ever made. 지금 만나보세요!", nil,
+			[]LanguageSegment{
+				{"kor", "오늘 소개할 제품입니다. "},
+				{"eng", "This is the best phone we have ever made. "},
+				{"kor", "지금 만나보세요!"},
+			},
+		},
+		{
+			"short first run joins the next", "  Galaxy Book, 정말 가볍고 빠른 노트북입니다.", nil,
+			[]LanguageSegment{{"kor", "  Galaxy Book, 정말 가볍고 빠른 노트북입니다."}},
+		},
+		{
+			"min words", "안녕하세요 여러분 Welcome to the show", &LanguageSplitOptions{MinWords: 2},
+			[]LanguageSegment{{"kor", "안녕하세요 여러분 "}, {"eng", "Welcome to the show"}},
+		},
+		{
+			"japanese with kanji words", "東京 に 行きます。 Привет, как дела у тебя?", nil,
+			[]LanguageSegment{{"jpn", "東京 に 行きます。 "}, {"rus", "Привет, как дела у тебя?"}},
+		},
+		{
+			"short sentences split", "Hello. 반갑습니다. Nice meeting you",
+			nil,
+			[]LanguageSegment{{"", "Hello. "}, {"kor", "반갑습니다. "}, {"eng", "Nice meeting you"}},
+		},
+		{
+			"short runs mid-sentence stay", "안녕하세요. iPhone을 보세요",
+			nil,
+			[]LanguageSegment{{"kor", "안녕하세요. iPhone을 보세요"}},
+		},
+		{"no letters", "123 456", nil, []LanguageSegment{{"", "123 456"}}},
+		{"numbers lead", "1. 첫 번째 항목", nil, []LanguageSegment{{"kor", "1. 첫 번째 항목"}}},
+		{"empty", "", nil, []LanguageSegment{}},
+	}
+	for _, tt := range tests {
+		got := SplitByLanguage(tt.in, tt.opts)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+			continue
+		}
+		var joined strings.Builder
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: segment %d = %q, want %q", tt.name, i, got[i], tt.want[i])
+			}
+			joined.WriteString(got[i].Text)
+		}
+		if joined.String() != tt.in {
+			t.Errorf("%s: segments join to %q", tt.name, joined.String())
+		}
+	}
+}
+
+func TestSynthesizeMixedLanguage(t *testing.T) {
+	type segment struct {
+		VoiceID  string `json:"voice_id"`
+		Text     string `json:"text"`
+		Language string `json:"language"`
+	}
+	var sent []segment
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/text-to-speech/compose" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var body struct{ Segments []segment }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		sent = body.Segments
+		w.Header().Set("X-Audio-Duration", "4.5")
+		_, _ = w.Write(makeTestWAV(make([]byte, 8), 16000))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+
+	result, err := c.SynthesizeMixedLanguage(context.Background(),
+		"오늘 소개할 제품입니다. This is the best phone we have ever made. Привет, как дела у тебя?",
+		MixedLanguageOptions{
+			Default: VoiceProfile{VoiceID: "tc_ko"},
+			Voices: map[string]VoiceProfile{
+				"eng": {VoiceID: "tc_en", Language: "eng"},
+			},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []segment{
+		{"tc_ko", "오늘 소개할 제품입니다. ", "kor"},
+		{"tc_en", "This is the best phone we have ever made. ", "eng"},
+		{"tc_ko", "Привет, как дела у тебя?", "rus"},
+	}
+	if len(sent) != len(want) {
+		t.Fatalf("sent %+v", sent)
+	}
+	for i := range want {
+		if sent[i] != want[i] {
+			t.Errorf("segment %d = %+v, want %+v", i, sent[i], want[i])
+		}
+	}
+	if len(result.Segments) != 3 || result.Duration != 4.5 || result.Format != AudioFormatWAV {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestSynthesizeMixedLanguageErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"detail":"boom"}`, http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	ctx := context.Background()
+	voice := VoiceProfile{VoiceID: "tc_ko"}
+
+	var validation *ValidationError
+	if _, err := c.SynthesizeMixedLanguage(ctx, " ", MixedLanguageOptions{Default: voice}); !errors.As(err, &validation) {
+		t.Fatalf("expected a validation error for empty text, got %v", err)
+	}
+	if _, err := c.SynthesizeMixedLanguage(ctx, "안녕", MixedLanguageOptions{}); !errors.As(err, &validation) || !strings.HasPrefix(err.Error(), "default voice: ") {
+		t.Fatalf("expected a default voice error, got %v", err)
+	}
+	bad := map[string]VoiceProfile{"eng": {}}
+	if _, err := c.SynthesizeMixedLanguage(ctx, "안녕", MixedLanguageOptions{Default: voice, Voices: bad}); err == nil || !strings.HasPrefix(err.Error(), "voice for eng: ") {
+		t.Fatalf("expected a voice error, got %v", err)
+	}
+	if _, err := c.SynthesizeMixedLanguage(ctx, "안녕", MixedLanguageOptions{Default: voice}); err == nil {
+		t.Fatal("expected the compose error")
+	}
+}
@@ -0,0 +1,192 @@
+package typecast
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Hangul syllable composition constants, from the Unicode standard.
+const (
+	hangulSBase  = 0xAC00
+	hangulLBase  = 0x1100
+	hangulVBase  = 0x1161
+	hangulTBase  = 0x11A7
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulSCount = hangulLCount * hangulNCount
+)
+
+// compositionTable lists, for each combining mark, pairs of a base letter
+// and the letter it composes into, for the Latin letters of Latin-1,
+// Latin Extended-A and B, and Latin Extended Additional (which covers
+// Vietnamese).
+var compositionTable = []struct {
+	mark  rune
+	pairs string
+}{
+	// combining grave accent
+	{0x0300, "AÀEÈIÌOÒUÙaàeèiìoòuùÜǛüǜNǸnǹĒḔēḕŌṐōṑWẀwẁÂẦâầĂẰăằÊỀêềÔỒôồƠỜơờ" +
+		"ƯỪưừYỲyỳ"},
+	// combining acute accent
+	{0x0301, "AÁEÉIÍOÓUÚYÝaáeéiíoóuúyýCĆcćLĹlĺNŃnńRŔrŕSŚsśZŹzźÜǗüǘGǴgǵÅǺåǻ" +
+		"ÆǼæǽØǾøǿÇḈçḉĒḖēḗÏḮïḯKḰkḱMḾmḿÕṌõṍŌṒōṓPṔpṕŨṸũṹWẂwẃÂẤâấĂẮăắÊẾêế" +
+		"ÔỐôốƠỚơớƯỨưứ"},
+	// combining circumflex accent
+	{0x0302, "AÂEÊIÎOÔUÛaâeêiîoôuûCĈcĉGĜgĝHĤhĥJĴjĵSŜsŝWŴwŵYŶyŷZẐzẑẠẬạậẸỆẹệ" +
+		"ỌỘọộ"},
+	// combining tilde
+	{0x0303, "AÃNÑOÕaãnñoõIĨiĩUŨuũVṼvṽÂẪâẫĂẴăẵEẼeẽÊỄêễÔỖôỗƠỠơỡƯỮưữYỸyỹ"},
+	// combining macron
+	{0x0304, "AĀaāEĒeēIĪiīOŌoōUŪuūÜǕüǖÄǞäǟȦǠȧǡÆǢæǣǪǬǫǭÖȪöȫÕȬõȭȮȰȯȱYȲyȳGḠgḡ" +
+		"ḶḸḷḹṚṜṛṝ"},
+	// combining breve
+	{0x0306, "AĂaăEĔeĕGĞgğIĬiĭOŎoŏUŬuŭȨḜȩḝẠẶạặ"},
+	// combining dot above
+	{0x0307, "CĊcċEĖeėGĠgġIİZŻzżAȦaȧOȮoȯBḂbḃDḊdḋFḞfḟHḢhḣMṀmṁNṄnṅPṖpṗRṘrṙSṠ" +
+		"sṡŚṤśṥŠṦšṧṢṨṣṩTṪtṫWẆwẇXẊxẋYẎyẏſẛ"},
+	// combining diaeresis
+	{0x0308, "AÄEËIÏOÖUÜaäeëiïoöuüyÿYŸHḦhḧÕṎõṏŪṺūṻWẄwẅXẌxẍtẗ"},
+	// combining hook above
+	{0x0309, "AẢaảÂẨâẩĂẲăẳEẺeẻÊỂêểIỈiỉOỎoỏÔỔôổƠỞơởUỦuủƯỬưửYỶyỷ"},
+	// combining ring above
+	{0x030A, "AÅaåUŮuůwẘyẙ"},
+	// combining double acute accent
+	{0x030B, "OŐoőUŰuű"},
+	// combining caron
+	{0x030C, "CČcčDĎdďEĚeěLĽlľNŇnňRŘrřSŠsšTŤtťZŽzžAǍaǎIǏiǐOǑoǒUǓuǔÜǙüǚGǦgǧ" +
+		"KǨkǩƷǮʒǯjǰHȞhȟ"},
+	// combining double grave accent
+	{0x030F, "AȀaȁEȄeȅIȈiȉOȌoȍRȐrȑUȔuȕ"},
+	// combining inverted breve
+	{0x0311, "AȂaȃEȆeȇIȊiȋOȎoȏRȒrȓUȖuȗ"},
+	// combining horn
+	{0x031B, "OƠoơUƯuư"},
+	// combining dot below
+	{0x0323, "BḄbḅDḌdḍHḤhḥKḲkḳLḶlḷMṂmṃNṆnṇRṚrṛSṢsṣTṬtṭVṾvṿWẈwẉZẒzẓAẠaạEẸeẹ" +
+		"IỊiịOỌoọƠỢơợUỤuụƯỰưựYỴyỵ"},
+	// combining diaeresis below
+	{0x0324, "UṲuṳ"},
+	// combining ring below
+	{0x0325, "AḀaḁ"},
+	// combining comma below
+	{0x0326, "SȘsșTȚtț"},
+	// combining cedilla
+	{0x0327, "CÇcçGĢgģKĶkķLĻlļNŅnņRŖrŗSŞsşTŢtţEȨeȩDḐdḑHḨhḩ"},
+	// combining ogonek
+	{0x0328, "AĄaąEĘeęIĮiįUŲuųOǪoǫ"},
+	// combining circumflex accent below
+	{0x032D, "DḒdḓEḘeḙLḼlḽNṊnṋTṰtṱUṶuṷ"},
+	// combining breve below
+	{0x032E, "HḪhḫ"},
+	// combining tilde below
+	{0x0330, "EḚeḛIḬiḭUṴuṵ"},
+	// combining macron below
+	{0x0331, "BḆbḇDḎdḏKḴkḵLḺlḻNṈnṉRṞrṟTṮtṯZẔzẕhẖ"},
+}
+
+// compositions maps a base letter and a combining mark to the precomposed
+// letter.
+var compositions = func() map[[2]rune]rune {
+	m := map[[2]rune]rune{}
+	for _, entry := range compositionTable {
+		pairs := []rune(entry.pairs)
+		for i := 0; i+1 < len(pairs); i += 2 {
+			m[[2]rune{pairs[i], entry.mark}] = pairs[i+1]
+		}
+	}
+	return m
+}()
+
+// NormalizeText cleans up text copied from a CMS or word processor before
+// it is synthesized. It composes decomposed letters, the NFC cases that
+// occur in practice: Hangul jamo into syllables and accented Latin letters
+// (full NFC needs golang.org/x/text). It removes byte order marks, zero-width
+// spaces, word joiners, and soft hyphens, which are invisible but billed,
+// and replaces each run of spaces and tabs that holds a non-ASCII space,
+// such as a no-break space, with one space. Line breaks, and the zero-width
+// joiners that emoji and some scripts need, are kept.
+func NormalizeText(text string) string {
+	ascii := true
+	for i := 0; i < len(text) && ascii; i++ {
+		ascii = text[i] < utf8.RuneSelf
+	}
+	if ascii {
+		return text
+	}
+	runes := []rune(text)
+	out := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\uFEFF' || r == '\u200B' || r == '\u2060' || r == '\u00AD':
+			continue
+		case isHorizontalSpace(r):
+			j, exotic := i, false
+			for ; j < len(runes) && isHorizontalSpace(runes[j]); j++ {
+				exotic = exotic || runes[j] >= utf8.RuneSelf
+			}
+			if exotic {
+				out = append(out, ' ')
+			} else {
+				out = append(out, runes[i:j]...)
+			}
+			i = j - 1
+			continue
+		}
+		if n := len(out); n > 0 {
+			if composed, ok := compose(out[n-1], r); ok {
+				out[n-1] = composed
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+// isHorizontalSpace reports whether r is whitespace other than a line break.
+func isHorizontalSpace(r rune) bool {
+	switch r {
+	case '\n', '\r', '\v', '\f', '\u0085', '\u2028', '\u2029':
+		return false
+	}
+	return unicode.IsSpace(r)
+}
+
+// compose returns the precomposed form of base followed by r, if any.
+func compose(base, r rune) (rune, bool) {
+	if base >= hangulLBase && base < hangulLBase+hangulLCount && r >= hangulVBase && r < hangulVBase+hangulVCount {
+		return hangulSBase + ((base-hangulLBase)*hangulVCount+(r-hangulVBase))*hangulTCount, true
+	}
+	if base >= hangulSBase && base < hangulSBase+hangulSCount && (base-hangulSBase)%hangulTCount == 0 &&
+		r > hangulTBase && r < hangulTBase+hangulTCount {
+		return base + (r - hangulTBase), true
+	}
+	composed, ok := compositions[[2]rune{base, r}]
+	return composed, ok
+}
+
+// normalizeText prepares request text: it applies NormalizeText unless
+// the client disables it, then the client's AdjustKoreanParticles,
+// Pronunciations, Acronyms, VerbalizeNumbers in the request language, and
+// EmojiPolicy.
+func (c *Client) normalizeText(text, language string) string {
+	if !c.disableTextNormalization {
+		text = NormalizeText(text)
+	}
+	if c.adjustKoreanParticles {
+		text = AdjustKoreanParticles(text)
+	}
+	if c.pronunciations != nil {
+		text = c.pronunciations.Apply(text)
+	}
+	if c.acronyms != nil {
+		text = c.acronyms.Apply(text)
+	}
+	if c.verbalizeNumbers {
+		text = VerbalizeNumbers(text, language)
+	}
+	return ApplyEmojiPolicy(text, c.emojiPolicy)
+}
@@ -0,0 +1,79 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct{ name, in, want string }{
+		{"ascii untouched", "Hello,  world.\n\tNext", "Hello,  world.\n\tNext"},
+		{"bom and zero-width", "\uFEFFHel\u200Blo\u2060 wo\u00ADrld", "Hello world"},
+		{"exotic whitespace", "a\u00A0b \u3000 c\u2009\u2009d", "a b c d"},
+		{"line breaks kept", "a\u00A0\nb\u2028c", "a \nb\u2028c"},
+		{"latin", "Cafe\u0301 nai\u0308ve n\u0303 A\u030A", "Caf\u00E9 na\u00EFve \u00F1 \u00C5"},
+		{"stacked vietnamese", "Vie\u0323\u0302t", "Vi\u1EC7t"},
+		{"hangul jamo", "\u1112\u1161\u11AB\u1100\u1173\u11AF", "\uD55C\uAE00"},
+		{"hangul syllable and final", "\uAC00\u11A8", "\uAC01"},
+		{"precomposed untouched", "\uD55C\uAE00 Vi\u1EC7t", "\uD55C\uAE00 Vi\u1EC7t"},
+		{"emoji joiners kept", "\U0001F469\u200D\U0001F4BB", "\U0001F469\u200D\U0001F4BB"},
+		{"lone marks kept", "\u0301a\u1161", "\u0301a\u1161"},
+		{"syllable with final not recomposed", "\uAC01\u11A8", "\uAC01\u11A8"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeText(tt.in); got != tt.want {
+			t.Errorf("%s: NormalizeText(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTextNormalizationInRequests(t *testing.T) {
+	var texts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text     string `json:"text"`
+			Segments []struct {
+				Text string `json:"text"`
+			} `json:"segments"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		texts = append(texts, body.Text)
+		for _, s := range body.Segments {
+			texts = append(texts, s.Text)
+		}
+		if r.URL.Path == "/v1/text-to-speech/with-timestamps" {
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		_, _ = w.Write([]byte("RIFF"))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	const raw, clean = "\uFEFFHello\u00A0world", "Hello world"
+
+	c := newTestClient(srv, "k")
+	request := &TTSRequest{VoiceID: "v", Text: raw, Model: ModelSSFMV30}
+	_, _ = c.TextToSpeech(ctx, request)
+	_, _ = c.TextToSpeechWithTimestamps(ctx, &TTSRequestWithTimestamps{VoiceID: "v", Text: raw, Model: ModelSSFMV30}, "")
+	stream, _ := c.TextToSpeechStream(ctx, TTSRequestStream{VoiceID: "v", Text: raw, Model: ModelSSFMV30})
+	stream.Close()
+	_, _ = c.ComposeSpeech().Defaults(ComposerSettings{VoiceID: "v", Model: ModelSSFMV30}).Say(raw).Generate(ctx)
+	for i, text := range texts {
+		if text != clean && text != "" {
+			t.Errorf("request %d sent %q", i, text)
+		}
+	}
+	if len(texts) != 5 || request.Text != raw {
+		t.Fatalf("expected 5 texts without modifying the caller's request, got %q", texts)
+	}
+
+	texts = nil
+	c = NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, DisableTextNormalization: true})
+	_, _ = c.TextToSpeech(ctx, request)
+	if len(texts) != 1 || texts[0] != raw {
+		t.Fatalf("expected the raw text, got %q", texts)
+	}
+}
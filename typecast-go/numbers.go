@@ -0,0 +1,426 @@
+package typecast
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxVerbalizedDigits is the longest integer read as a number; longer
+// ones, like account numbers, are read digit by digit.
+const maxVerbalizedDigits = 15
+
+var (
+	isoDate     = regexp.MustCompile(`(\d{4})[-./](\d{1,2})[-./](\d{1,2})`)
+	phoneNumber = regexp.MustCompile(`\((\d{2,4})\) ?(\d{3,4})-(\d{4})|(\d{2,4})-(\d{3,4})-(\d{4})`)
+	clockTime   = regexp.MustCompile(`(\d{1,2}):(\d{2})(?::(\d{2}))?(?: ?([AaPp])\.?[Mm]\b)?`)
+	currency    = regexp.MustCompile(`([$€£¥₩]) ?(\d{1,3}(?:,\d{3})+|\d+)(\.\d+)?(?: (thousand|million|billion|trillion))?`)
+	numberToken = regexp.MustCompile(`([-−])?(\d{1,3}(?:,\d{3})+|\d+)(\.\d+)?(st|nd|rd|th|%)?`)
+	yearContext = regexp.MustCompile(`(?i)\b(?:in|since|until|by|from|of|year)\s+$`)
+	// International phone numbers are left as written, since their
+	// grouping varies by country.
+	internationalNumber = regexp.MustCompile(`\+\d{1,3}[- ]\d[\d -]*\d|\+\d{7,15}`)
+	// Dates and times the earlier passes declined, such as "2024-13-45",
+	// are left as written too instead of being read as separate numbers.
+	writtenNumber = regexp.MustCompile(internationalNumber.String() + `|\d{4}[-./]\d{1,2}[-./]\d{1,2}|\d{1,2}(?::\d{2})+`)
+)
+
+// currencyNames holds the English singular, plural, and cent names and the
+// Korean name of each currency symbol.
+var currencyNames = map[string][4]string{
+	"$": {"dollar", "dollars", "cent", "달러"},
+	"€": {"euro", "euros", "cent", "유로"},
+	"£": {"pound", "pounds", "penny", "파운드"},
+	"¥": {"yen", "yen", "", "엔"},
+	"₩": {"won", "won", "", "원"},
+}
+
+// VerbalizeNumbers rewrites the numbers in text as the words a voice
+// should read in language, "eng" or "kor" (an empty language is detected
+// from text, and English when Latin text ties): dates, clock times with
+// seconds and AM or PM, phone numbers read digit by digit, currency
+// amounts, percentages, decimals, and English ordinals such as "21st".
+// Invalid dates and times, such as "2024-13-45", and international phone
+// numbers, such as "+82-2-123-4567", are left as written. Korean numbers
+// are read with native numerals before counters that take them, as in
+// "세 개" and "두 시", and with Sino-Korean numerals otherwise, as in
+// "삼 층". Numbers in tokens such as "v2", "1e5", and "1/2" are left as
+// written, and so is text in other languages.
+func VerbalizeNumbers(text, language string) string {
+	if language == "" {
+		// Latin text whose language ties, such as "I have 1999 apples"
+		// between English, Polish, and Croatian, is read as English.
+		if language = DetectLanguage(text); language == "" && dominantScript(text) == "latin" {
+			language = "eng"
+		}
+	}
+	language = strings.ToLower(language)
+	if language != "eng" && language != "kor" || !strings.ContainsAny(text, "0123456789") {
+		return text
+	}
+	korean := language == "kor"
+	text = replaceNumbers(text, isoDate, internationalNumber, func(m []string, _, _ string) (string, bool) {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		if month < 1 || month > 12 || day < 1 || day > 31 {
+			return "", false
+		}
+		if korean {
+			return koreanDate(year, month, day), true
+		}
+		return englishMonths[month-1] + " " + englishOrdinal(day) + ", " + englishYear(year), true
+	})
+	text = replaceNumbers(text, phoneNumber, internationalNumber, func(m []string, _, _ string) (string, bool) {
+		var groups []string
+		for _, group := range m[1:] {
+			if group != "" {
+				groups = append(groups, readDigits(group, korean))
+			}
+		}
+		if korean {
+			return strings.Join(groups, " "), true
+		}
+		return strings.Join(groups, ", "), true
+	})
+	text = replaceNumbers(text, clockTime, internationalNumber, func(m []string, before, after string) (string, bool) {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		second, _ := strconv.Atoi(m[3])
+		meridiem := ""
+		if m[4] != "" {
+			meridiem = strings.ToUpper(m[4]) + "M"
+		}
+		switch {
+		case hour > 24 || minute > 59 || second > 59,
+			meridiem != "" && (hour < 1 || hour > 12),
+			inToken(before, after),
+			strings.HasPrefix(after, ":") && continuesNumber(after[1:]):
+			return "", false
+		case korean:
+			return koreanTime(hour, minute, second, meridiem), true
+		}
+		return englishTime(hour, minute, second, meridiem), true
+	})
+	text = replaceNumbers(text, currency, internationalNumber, func(m []string, _, _ string) (string, bool) {
+		names := currencyNames[m[1]]
+		integer, fraction, scale := strings.ReplaceAll(m[2], ",", ""), m[3], m[4]
+		if korean {
+			amount := sinoKoreanNumber(integer, fraction)
+			if scale != "" {
+				amount += " " + scale
+			}
+			return amount + " " + names[3], true
+		}
+		if scale != "" || len(fraction) > 3 || fraction != "" && names[2] == "" {
+			amount := englishNumber(integer, fraction)
+			if scale != "" {
+				amount += " " + scale
+			}
+			return amount + " " + names[1], true
+		}
+		return englishAmount(integer, strings.TrimPrefix(fraction, "."), names), true
+	})
+	return replaceNumbers(text, numberToken, writtenNumber, func(m []string, before, after string) (string, bool) {
+		integer, fraction, suffix := strings.ReplaceAll(m[2], ",", ""), m[3], m[4]
+		if inToken(before, after) {
+			return "", false
+		}
+		var words string
+		switch {
+		case korean && suffix != "" && suffix != "%":
+			return "", false
+		case korean:
+			words = koreanNumber(integer, fraction, after)
+		case suffix != "" && suffix != "%":
+			if fraction != "" || len(integer) > 9 || !hasOrdinalSuffix(integer, suffix) {
+				return "", false
+			}
+			n, _ := strconv.Atoi(integer)
+			words = englishOrdinal(n)
+		case m[1] == "" && fraction == "" && suffix == "" && len(m[2]) == 4 && m[2] >= "1100" && m[2] < "2100" && yearContext.MatchString(before):
+			year, _ := strconv.Atoi(integer)
+			words = englishYear(year)
+		default:
+			words = englishNumber(integer, fraction)
+		}
+		minus, percent := "minus ", " percent"
+		if korean {
+			minus, percent = "마이너스 ", " 퍼센트"
+		}
+		switch {
+		case m[1] != "" && before != "" && isASCIIWordByte(before[len(before)-1]):
+			// A range or a code, as in "10-20": keep the hyphen.
+			words = m[1] + words
+		case m[1] != "":
+			words = minus + words
+		}
+		if suffix == "%" {
+			words += percent
+		}
+		return words, true
+	})
+}
+
+// replaceNumbers replaces the matches of re in text that do not continue a
+// word or number with what read returns for the match's submatches and
+// the text before and after it. Matches read declines, and those inside a
+// match of keep, are left as written. A match starting with a minus sign
+// may follow a word, as in "10-20", for read to take the sign as a hyphen.
+func replaceNumbers(text string, re, keep *regexp.Regexp, read func(m []string, before, after string) (string, bool)) string {
+	var b strings.Builder
+	last := 0
+	kept := keep.FindAllStringIndex(text, -1)
+	for _, loc := range re.FindAllStringSubmatchIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		signed := text[start] == '-' || strings.HasPrefix(text[start:], "−")
+		if start > 0 && isASCIIWordByte(text[start-1]) && !signed || continuesNumber(text[end:]) || overlaps(kept, start, end) {
+			continue
+		}
+		m := make([]string, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		words, ok := read(m, text[:start], text[end:])
+		if !ok {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(words)
+		last = end
+	}
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// overlaps reports whether [start, end) overlaps one of spans.
+func overlaps(spans [][]int, start, end int) bool {
+	for _, span := range spans {
+		if start < span[1] && span[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+// continuesNumber reports whether rest, the text after a match, continues
+// the number, as "5" and ".5" do.
+func continuesNumber(rest string) bool {
+	if rest != "" && (rest[0] == '.' || rest[0] == ',') {
+		rest = rest[1:]
+	}
+	return rest != "" && rest[0] >= '0' && rest[0] <= '9'
+}
+
+// inToken reports whether a number, with the text before and after it, is
+// part of a token it cannot be read out of: followed by a letter, as in
+// "1e5" or "3x", or joined to another number by a slash, as in "1/2" or
+// "24/7".
+func inToken(before, after string) bool {
+	if after != "" && (after[0] >= 'a' && after[0] <= 'z' || after[0] >= 'A' && after[0] <= 'Z') {
+		return true
+	}
+	if strings.HasPrefix(after, "/") && len(after) > 1 && after[1] >= '0' && after[1] <= '9' {
+		return true
+	}
+	n := len(before)
+	return n > 1 && before[n-1] == '/' && before[n-2] >= '0' && before[n-2] <= '9'
+}
+
+func isASCIIWordByte(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '.' || c == ','
+}
+
+// readDigits reads digits one by one, with "공" or "zero" for 0.
+func readDigits(digits string, korean bool) string {
+	words := make([]string, 0, len(digits))
+	for _, d := range digits {
+		if korean {
+			words = append(words, koreanDigits[d-'0'])
+		} else {
+			words = append(words, englishOnes[d-'0'])
+		}
+	}
+	if korean {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words, " ")
+}
+
+var (
+	englishOnes = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+	englishTens   = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	englishScales = []string{"", " thousand", " million", " billion", " trillion"}
+	englishMonths = []string{"January", "February", "March", "April", "May", "June", "July",
+		"August", "September", "October", "November", "December"}
+)
+
+// englishNumber reads an integer and an optional fraction such as ".25".
+// Integers with leading zeros or too many digits are read digit by digit.
+func englishNumber(integer, fraction string) string {
+	var words string
+	if len(integer) > maxVerbalizedDigits || len(integer) > 1 && integer[0] == '0' {
+		words = readDigits(integer, false)
+	} else {
+		n, _ := strconv.ParseInt(integer, 10, 64)
+		words = englishCardinal(n)
+	}
+	if fraction != "" {
+		words += " point " + readDigits(fraction[1:], false)
+	}
+	return words
+}
+
+// englishCardinal reads n, such as "one hundred twenty-three".
+func englishCardinal(n int64) string {
+	if n == 0 {
+		return "zero"
+	}
+	var groups []string
+	for scale := 0; n > 0; scale++ {
+		if group := n % 1000; group > 0 {
+			groups = append([]string{englishHundreds(int(group)) + englishScales[scale]}, groups...)
+		}
+		n /= 1000
+	}
+	return strings.Join(groups, " ")
+}
+
+// englishHundreds reads 1 to 999.
+func englishHundreds(n int) string {
+	var words []string
+	if n >= 100 {
+		words = append(words, englishOnes[n/100]+" hundred")
+		n %= 100
+	}
+	switch {
+	case n >= 20 && n%10 != 0:
+		words = append(words, englishTens[n/10]+"-"+englishOnes[n%10])
+	case n >= 20:
+		words = append(words, englishTens[n/10])
+	case n > 0:
+		words = append(words, englishOnes[n])
+	}
+	return strings.Join(words, " ")
+}
+
+// englishOrdinal reads n as an ordinal, such as "twenty-first".
+func englishOrdinal(n int) string {
+	words := englishCardinal(int64(n))
+	cut := strings.LastIndexAny(words, " -") + 1
+	last := words[cut:]
+	switch {
+	case last == "one":
+		last = "first"
+	case last == "two":
+		last = "second"
+	case last == "three":
+		last = "third"
+	case last == "five":
+		last = "fifth"
+	case last == "eight":
+		last = "eighth"
+	case last == "nine":
+		last = "ninth"
+	case last == "twelve":
+		last = "twelfth"
+	case strings.HasSuffix(last, "y"):
+		last = strings.TrimSuffix(last, "y") + "ieth"
+	default:
+		last += "th"
+	}
+	return words[:cut] + last
+}
+
+// hasOrdinalSuffix reports whether suffix is the English ordinal suffix of
+// integer, as "st" is of 21 but not of 11.
+func hasOrdinalSuffix(integer, suffix string) bool {
+	if len(integer) > 2 {
+		integer = integer[len(integer)-2:]
+	}
+	n, _ := strconv.Atoi(integer)
+	want := "th"
+	if n/10 != 1 {
+		want = [10]string{"th", "st", "nd", "rd", "th", "th", "th", "th", "th", "th"}[n%10]
+	}
+	return suffix == want
+}
+
+// englishYear reads a year the way it is said, such as "nineteen
+// ninety-nine" or "two thousand five".
+func englishYear(year int) string {
+	hi, lo := year/100, year%100
+	switch {
+	case year < 1100 || year%1000 < 10:
+		return englishCardinal(int64(year))
+	case lo == 0:
+		return englishHundreds(hi) + " hundred"
+	case lo < 10:
+		return englishHundreds(hi) + " oh " + englishOnes[lo]
+	}
+	return englishHundreds(hi) + " " + englishHundreds(lo)
+}
+
+// englishTime reads a clock time, such as "nine thirty", "ten o'clock",
+// or "three fifteen PM", with seconds, such as "and five seconds", when
+// second is not 0. meridiem is "AM", "PM", or "".
+func englishTime(hour, minute, second int, meridiem string) string {
+	var words string
+	switch {
+	case minute == 0 && meridiem != "":
+		words = englishCardinal(int64(hour))
+	case minute == 0:
+		words = englishCardinal(int64(hour)) + " o'clock"
+	case minute < 10:
+		words = englishCardinal(int64(hour)) + " oh " + englishOnes[minute]
+	default:
+		words = englishCardinal(int64(hour)) + " " + englishHundreds(minute)
+	}
+	switch {
+	case second == 1:
+		words += " and one second"
+	case second > 0:
+		words += " and " + englishHundreds(second) + " seconds"
+	}
+	if meridiem != "" {
+		words += " " + meridiem
+	}
+	return words
+}
+
+// englishAmount reads a currency amount with up to two decimal places,
+// such as "five dollars and ninety-nine cents".
+func englishAmount(integer, cents string, names [4]string) string {
+	n, _ := strconv.ParseInt(integer, 10, 64)
+	words := englishCardinal(n) + " " + names[1]
+	if n == 1 {
+		words = "one " + names[0]
+	}
+	if cents == "" {
+		return words
+	}
+	if len(cents) == 1 {
+		cents += "0"
+	}
+	c, _ := strconv.Atoi(cents)
+	centWords := englishCardinal(int64(c)) + " " + names[2] + "s"
+	if names[2] == "penny" {
+		centWords = englishCardinal(int64(c)) + " pence"
+	}
+	if c == 1 {
+		centWords = "one " + names[2]
+	}
+	switch {
+	case c == 0:
+		return words
+	case n == 0:
+		return centWords
+	}
+	return words + " and " + centWords
+}
@@ -0,0 +1,167 @@
+package typecast
+
+import (
+	"strconv"
+	"strings"
+)
+
+var (
+	koreanDigits      = []string{"공", "일", "이", "삼", "사", "오", "육", "칠", "팔", "구"}
+	koreanUnits       = []string{"", "십", "백", "천"}
+	koreanGroups      = []string{"", "만", "억", "조"}
+	nativeKoreanOnes  = []string{"", "하나", "둘", "셋", "넷", "다섯", "여섯", "일곱", "여덟", "아홉"}
+	nativeKoreanTens  = []string{"", "열", "스물", "서른", "마흔", "쉰", "예순", "일흔", "여든", "아흔"}
+	koreanAttributive = map[string]string{"하나": "한", "둘": "두", "셋": "세", "넷": "네", "스물": "스무"}
+)
+
+var (
+	// nativeKoreanCounters are the counters read with native Korean
+	// numerals, as in "세 개" and "두 시간". Counters such as 분, 층, and 원
+	// take Sino-Korean numerals, the default.
+	nativeKoreanCounters = []string{
+		"군데", "그루", "마리", "사람", "송이", "시간", "켤레", "가지",
+		"개", "곳", "달", "명", "벌", "병", "살", "시", "잔", "채", "척",
+	}
+	// sinoKoreanCounters start like a native counter but take Sino-Korean
+	// numerals, as in "삼 개월".
+	sinoKoreanCounters = []string{"개월", "개국", "달러"}
+)
+
+// koreanNumber reads a number in Korean text. Integers from 1 to 99 before
+// a native counter are read with native numerals, in the attributive form
+// counters take ("한 개", "스무 살"), as is the number of "N번째" ("두
+// 번째"), whose 1 is "첫". Other numbers are Sino-Korean.
+func koreanNumber(integer, fraction, after string) string {
+	n, err := strconv.Atoi(integer)
+	native := err == nil && fraction == "" && n >= 1 && n <= 99 && len(integer) == len(strconv.Itoa(n))
+	if native {
+		counter := strings.TrimLeft(after, " ")
+		switch {
+		case strings.HasPrefix(counter, "번째") && n == 1:
+			return "첫"
+		case strings.HasPrefix(counter, "번째"):
+			return nativeKorean(n)
+		case hasAnyPrefix(counter, sinoKoreanCounters):
+		case strings.HasPrefix(counter, "시") && !strings.HasPrefix(counter, "시간") && n > 12:
+			// 24-hour times are read in Sino-Korean, as in "십삼 시".
+		case hasAnyPrefix(counter, nativeKoreanCounters):
+			return nativeKorean(n)
+		}
+	}
+	if native && strings.HasPrefix(after, "월") {
+		// Months drop a sound: 6월 and 10월 are 유월 and 시월.
+		switch n {
+		case 6:
+			return "유"
+		case 10:
+			return "시"
+		}
+	}
+	return sinoKoreanNumber(integer, fraction)
+}
+
+// nativeKorean reads 1 to 99 with native Korean numerals in the
+// attributive form, such as "스물한".
+func nativeKorean(n int) string {
+	tens, ones := nativeKoreanTens[n/10], nativeKoreanOnes[n%10]
+	if ones == "" {
+		if attributive, ok := koreanAttributive[tens]; ok {
+			return attributive
+		}
+		return tens
+	}
+	if attributive, ok := koreanAttributive[ones]; ok {
+		ones = attributive
+	}
+	return tens + ones
+}
+
+// sinoKoreanNumber reads an integer and an optional fraction such as ".25"
+// with Sino-Korean numerals, such as "삼백이십 점 오". Integers with
+// leading zeros or too many digits are read digit by digit.
+func sinoKoreanNumber(integer, fraction string) string {
+	var words string
+	if len(integer) > maxVerbalizedDigits || len(integer) > 1 && integer[0] == '0' {
+		words = readDigits(integer, true)
+	} else {
+		n, _ := strconv.ParseInt(integer, 10, 64)
+		words = sinoKorean(n)
+	}
+	if fraction != "" {
+		digits := readDigits(fraction[1:], true)
+		words += " 점 " + strings.ReplaceAll(digits, "공", "영")
+	}
+	return words
+}
+
+// sinoKorean reads n with Sino-Korean numerals in groups of four digits,
+// such as "만 이천삼백사십오" for 12345. A leading 1 is dropped before 십,
+// 백, 천, and 만, as it is said.
+func sinoKorean(n int64) string {
+	if n == 0 {
+		return "영"
+	}
+	var groups []string
+	for group := 0; n > 0; group++ {
+		value := int(n % 10000)
+		n /= 10000
+		if value == 0 {
+			continue
+		}
+		var b strings.Builder
+		for unit := 3; unit >= 0; unit-- {
+			digit := value / pow10(unit) % 10
+			if digit == 0 {
+				continue
+			}
+			if digit > 1 || unit == 0 && !(value == 1 && group == 1) {
+				b.WriteString(koreanDigits[digit])
+			}
+			b.WriteString(koreanUnits[unit])
+		}
+		groups = append([]string{b.String() + koreanGroups[group]}, groups...)
+	}
+	return strings.Join(groups, " ")
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func pow10(n int) int {
+	p := 1
+	for ; n > 0; n-- {
+		p *= 10
+	}
+	return p
+}
+
+// koreanDate reads a date, such as "이천이십사 년 삼월 십오 일".
+func koreanDate(year, month, day int) string {
+	return sinoKorean(int64(year)) + " 년 " + koreanNumber(strconv.Itoa(month), "", "월") + "월 " + sinoKorean(int64(day)) + " 일"
+}
+
+// koreanTime reads a clock time with a native hour and Sino-Korean
+// minutes and seconds, such as "두 시 삼십 분", after "오전" or "오후" for
+// an AM or PM meridiem.
+func koreanTime(hour, minute, second int, meridiem string) string {
+	words := koreanNumber(strconv.Itoa(hour), "", "시") + " 시"
+	if minute > 0 {
+		words += " " + sinoKorean(int64(minute)) + " 분"
+	}
+	if second > 0 {
+		words += " " + sinoKorean(int64(second)) + " 초"
+	}
+	switch meridiem {
+	case "AM":
+		words = "오전 " + words
+	case "PM":
+		words = "오후 " + words
+	}
+	return words
+}
@@ -0,0 +1,115 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVerbalizeNumbersEnglish(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"I have 3 cats and 1,024 fish.", "I have three cats and one thousand twenty-four fish."},
+		{"The 21st and 11th floors, not the 21th.", "The twenty-first and eleventh floors, not the 21th."},
+		{"Born in 1999, moved in 2005, room 1999.", "Born in nineteen ninety-nine, moved in two thousand five, room one thousand nine hundred ninety-nine."},
+		{"Since 1900 and by 2010 and of 1805", "Since nineteen hundred and by twenty ten and of eighteen oh five"},
+		{"Due 2024-03-15.", "Due March fifteenth, twenty twenty-four."},
+		{"Not a date: 2024-13-40", "Not a date: 2024-13-40"},
+		{"Not a date: 2024-13-45.", "Not a date: 2024-13-45."},
+		{"Meet at 9:30 or 10:00 or 7:05.", "Meet at nine thirty or ten o'clock or seven oh five."},
+		{"Score 99:99", "Score 99:99"},
+		{"At 3:30pm, 3:30 PM, 11:05 a.m. and 12:00am.", "At three thirty PM, three thirty PM, eleven oh five AM. and twelve AM."},
+		{"Lap 10:45:30 and 0:00:01, not 13:30pm or 3:30pmx", "Lap ten forty-five and thirty seconds and zero o'clock and one second, not 13:30pm or 3:30pmx"},
+		{"Not 10:45:30:10 or 10:45:61", "Not 10:45:30:10 or 10:45:61"},
+		{"Call +82-2-123-4567, +1 555 123 4567, or +821012345678.", "Call +82-2-123-4567, +1 555 123 4567, or +821012345678."},
+		{"Up +5.5% and +82-10-1234-5678", "Up +five point five percent and +82-10-1234-5678"},
+		{"Call 555-123-4567 or (02) 1234-5678.", "Call five five five, one two three, four five six seven or zero two, one two three four, five six seven eight."},
+		{"It costs $5.99, $1, $0.99, $0.01, or $3.00.", "It costs five dollars and ninety-nine cents, one dollar, ninety-nine cents, one cent, or three dollars."},
+		{"Raised $1.5 million and £2.50 and €1.5", "Raised one point five million dollars and two pounds and fifty pence and one euro and fifty cents"},
+		{"¥500 or ¥1.5 or $2.125", "five hundred yen or one point five yen or two point one two five dollars"},
+		{"Up 15% to -3.25 and −2", "Up fifteen percent to minus three point two five and minus two"},
+		{"Code 007 and 12345678901234567", "Code zero zero seven and one two three four five six seven eight nine zero one two three four five six seven"},
+		{"v2 and 3.4.5 and x10", "v2 and 3.4.5 and x10"},
+		{"1000000 and 0", "one million and zero"},
+		{"2nd 3rd 5th 8th 9th 12th 20th 101st 1000th", "second third fifth eighth ninth twelfth twentieth one hundred first one thousandth"},
+		{"Pages 10-20, or 1.5.", "Pages ten-twenty, or one point five."},
+		{"1e5 and 3x, not 3 x", "1e5 and 3x, not three x"},
+		{"1/2 cup, open 24/7, or 2 / 3", "1/2 cup, open 24/7, or two / three"},
+	}
+	for _, tt := range tests {
+		if got := VerbalizeNumbers(tt.in, "eng"); got != tt.want {
+			t.Errorf("VerbalizeNumbers(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+		}
+	}
+	// A tie between Latin languages is read as English.
+	if got := VerbalizeNumbers("I have 1999 apples", ""); got != "I have one thousand nine hundred ninety-nine apples" {
+		t.Errorf("tied detection: got %q", got)
+	}
+	if got := VerbalizeNumbers("We have 2 dogs and they are with the cat", ""); got != "We have two dogs and they are with the cat" {
+		t.Fatalf("detected language: %q", got)
+	}
+}
+
+func TestVerbalizeNumbersKorean(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"사과 3개와 20살", "사과 세개와 스무살"},
+		{"2시 30분, 13시, 3시간", "두시 삼십분, 십삼시, 세시간"},
+		{"1번째와 2번째", "첫번째와 두번째"},
+		{"3개월 동안 5개국에서 10달러", "삼개월 동안 오개국에서 십달러"},
+		{"6월과 10월과 3월", "유월과 시월과 삼월"},
+		{"12345명이 아니라 12345원", "만 이천삼백사십오명이 아니라 만 이천삼백사십오원"},
+		{"10000원과 100000000원", "만원과 일억원"},
+		{"전화 010-1234-5678", "전화 공일공 일이삼사 오육칠팔"},
+		{"3:30pm과 10:45:30과 9:00 AM", "오후 세 시 삼십 분과 열 시 사십오 분 삼십 초과 오전 아홉 시"},
+		{"가격은 ₩5,000입니다", "가격은 오천 원입니다"},
+		{"$1.5 million이에요", "일 점 오 million 달러이에요"},
+		{"3.05퍼센트와 15%와 -3도", "삼 점 영오퍼센트와 십오 퍼센트와 마이너스 삼도"},
+		{"2024-03-15에 만나요", "이천이십사 년 삼월 십오 일에 만나요"},
+		{"오후 9:05 또는 14:00에", "오후 아홉 시 오 분 또는 십사 시에"},
+		{"30살과 007번", "서른살과 공공칠번"},
+		{"0시와 21번째 1st", "영시와 스물한번째 1st"},
+	}
+	for _, tt := range tests {
+		if got := VerbalizeNumbers(tt.in, "kor"); got != tt.want {
+			t.Errorf("VerbalizeNumbers(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVerbalizeNumbersUnsupported(t *testing.T) {
+	for _, tt := range []struct{ text, language string }{
+		{"J'ai 3 chats", "fra"},
+		{"no digits here", "eng"},
+		{"42", ""},
+		{"Il a 3 chats et le chien", ""},
+	} {
+		if got := VerbalizeNumbers(tt.text, tt.language); got != tt.text {
+			t.Errorf("VerbalizeNumbers(%q, %q) = %q", tt.text, tt.language, got)
+		}
+	}
+}
+
+func TestVerbalizeNumbersInRequests(t *testing.T) {
+	var text string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		text = body.Text
+		_, _ = w.Write([]byte("RIFF"))
+	}))
+	defer srv.Close()
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, VerbalizeNumbers: true})
+	if _, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "Buy 3 of them at the store", Model: ModelSSFMV30}); err != nil {
+		t.Fatal(err)
+	}
+	if text != "Buy three of them at the store" {
+		t.Fatalf("sent %q", text)
+	}
+	if _, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "OK 3개", Model: ModelSSFMV30, Language: "kor"}); err != nil {
+		t.Fatal(err)
+	}
+	if text != "OK 세개" {
+		t.Fatalf("sent %q", text)
+	}
+}
@@ -0,0 +1,49 @@
+package typecast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SupportedSampleRates lists the Output.SampleRate values accepted by the API.
+var SupportedSampleRates = []int{8000, 16000, 22050, 24000, 44100, 48000}
+
+// SupportedMP3Bitrates lists the Output.Bitrate values (kbps) accepted by the API.
+var SupportedMP3Bitrates = []int{32, 64, 96, 128, 192, 256, 320}
+
+// validateEncoding checks SampleRate, Channels and Bitrate.
+func (o *Output) validateEncoding() error {
+	if o.SampleRate != nil && !containsInt(SupportedSampleRates, *o.SampleRate) {
+		return newValidationError("sample_rate", fmt.Sprintf("sample_rate must be one of %s Hz", joinInts(SupportedSampleRates)))
+	}
+	if o.Channels != nil && *o.Channels != 1 && *o.Channels != 2 {
+		return newValidationError("channels", "channels must be 1 or 2")
+	}
+	if o.Bitrate != nil {
+		if o.AudioFormat != AudioFormatMP3 {
+			return newValidationError("bitrate", "bitrate requires audio_format mp3")
+		}
+		if !containsInt(SupportedMP3Bitrates, *o.Bitrate) {
+			return newValidationError("bitrate", fmt.Sprintf("bitrate must be one of %s kbps", joinInts(SupportedMP3Bitrates)))
+		}
+	}
+	return nil
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ", ")
+}
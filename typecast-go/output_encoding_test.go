@@ -0,0 +1,68 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOutput_EncodingFieldsSerialize(t *testing.T) {
+	rate, channels, bitrate := 24000, 1, 128
+	out := &Output{AudioFormat: AudioFormatMP3, SampleRate: &rate, Channels: &channels, Bitrate: &bitrate}
+	if err := out.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	b, _ := json.Marshal(out)
+	if string(b) != `{"audio_format":"mp3","sample_rate":24000,"channels":1,"bitrate":128}` {
+		t.Fatalf("unexpected JSON: %s", b)
+	}
+}
+
+func TestOutput_ValidationErrorsAreTyped(t *testing.T) {
+	badRate, badChannels, bitrate, badBitrate := 11025, 3, 128, 100
+	tempo := 5.0
+	cases := []struct {
+		name   string
+		output *Output
+		field  string
+		want   string
+	}{
+		{"sample rate", &Output{SampleRate: &badRate}, "sample_rate", "sample_rate must be one of 8000, 16000, 22050, 24000, 44100, 48000 Hz"},
+		{"channels", &Output{Channels: &badChannels}, "channels", "channels must be 1 or 2"},
+		{"bitrate without mp3", &Output{Bitrate: &bitrate}, "bitrate", "bitrate requires audio_format mp3"},
+		{"bitrate value", &Output{AudioFormat: AudioFormatMP3, Bitrate: &badBitrate}, "bitrate", "bitrate must be one of"},
+		{"tempo", &Output{AudioTempo: &tempo}, "audio_tempo", "audio_tempo must be between 0.5 and 2.0"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.output.Validate()
+			var validation *ValidationError
+			if !errors.As(err, &validation) {
+				t.Fatalf("expected *ValidationError, got %T %v", err, err)
+			}
+			if validation.Field != tc.field || !strings.Contains(validation.Error(), tc.want) {
+				t.Fatalf("unexpected error: %+v", validation)
+			}
+		})
+	}
+}
+
+func TestTextToSpeech_RejectsInvalidOutputBeforeSending(t *testing.T) {
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://127.0.0.1:1"})
+	channels := 0
+	_, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "t", Model: ModelSSFMV30, Output: &Output{Channels: &channels}})
+	var validation *ValidationError
+	if !errors.As(err, &validation) || validation.Field != "channels" {
+		t.Fatalf("expected channels ValidationError, got %v", err)
+	}
+}
+
+func TestMergeComposerOutput_KeepsEncodingFields(t *testing.T) {
+	rate, channels, bitrate := 44100, 2, 192
+	merged := mergeComposerOutput(&Output{AudioFormat: AudioFormatMP3}, &Output{SampleRate: &rate, Channels: &channels, Bitrate: &bitrate})
+	if *merged.SampleRate != 44100 || *merged.Channels != 2 || *merged.Bitrate != 192 || merged.AudioFormat != AudioFormatMP3 {
+		t.Fatalf("unexpected merged output: %+v", merged)
+	}
+}
@@ -0,0 +1,195 @@
+package typecast
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// PodcastFeed describes a podcast and its episodes.
+type PodcastFeed struct {
+	// Title is the show's title (required)
+	Title string
+	// Link is the show's website (required)
+	Link string
+	// Description summarizes the show (required)
+	Description string
+	// Language is the show's language code, e.g. "en-us" (optional)
+	Language string
+	// Author is the show's author (optional)
+	Author string
+	// ImageURL is the show's artwork, at least 1400x1400 pixels (optional)
+	ImageURL string
+	// Category is an Apple Podcasts category, e.g. "News" (optional)
+	Category string
+	// Explicit marks the show as containing explicit content
+	Explicit bool
+	// Episodes lists the episodes, conventionally newest first
+	Episodes []PodcastEpisode
+}
+
+// PodcastEpisode describes one episode of a PodcastFeed.
+type PodcastEpisode struct {
+	// GUID uniquely and permanently identifies the episode (optional,
+	// defaults to AudioURL)
+	GUID string
+	// Title is the episode's title (required)
+	Title string
+	// Description summarizes the episode (optional)
+	Description string
+	// AudioURL is where the episode's audio file is published (required)
+	AudioURL string
+	// AudioSize is the audio file's size in bytes
+	AudioSize int
+	// AudioType is the audio file's MIME type (optional, defaults to
+	// "audio/mpeg")
+	AudioType string
+	// Duration is the audio duration in seconds
+	Duration float64
+	// Published is the episode's release time (optional)
+	Published time.Time
+}
+
+// NewPodcastEpisode returns an episode for synthesized audio, such as a
+// LongFormResult's TTSResponse, published at audioURL.
+func NewPodcastEpisode(title, audioURL string, audio *TTSResponse) PodcastEpisode {
+	episode := PodcastEpisode{
+		Title:     title,
+		AudioURL:  audioURL,
+		AudioSize: len(audio.AudioData),
+		AudioType: "audio/mpeg",
+		Duration:  audio.Duration,
+		Published: time.Now(),
+	}
+	if audio.Format == AudioFormatWAV {
+		episode.AudioType = "audio/wav"
+	}
+	return episode
+}
+
+// Validate checks that the feed and its episodes have their required fields.
+func (f *PodcastFeed) Validate() error {
+	if f.Title == "" {
+		return newValidationError("title", "title is required")
+	}
+	if f.Link == "" {
+		return newValidationError("link", "link is required")
+	}
+	if f.Description == "" {
+		return newValidationError("description", "description is required")
+	}
+	for i, episode := range f.Episodes {
+		if episode.Title == "" {
+			return newValidationError("episodes", fmt.Sprintf("episode %d: title is required", i))
+		}
+		if episode.AudioURL == "" {
+			return newValidationError("episodes", fmt.Sprintf("episode %d: audio_url is required", i))
+		}
+	}
+	return nil
+}
+
+// RSS renders the feed as an RSS 2.0 document with the iTunes podcast
+// extensions that podcast directories expect.
+func (f *PodcastFeed) RSS() ([]byte, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	explicit := "false"
+	if f.Explicit {
+		explicit = "true"
+	}
+	channel := rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: f.Description,
+		Language:    f.Language,
+		Author:      f.Author,
+		Explicit:    explicit,
+		Items:       make([]rssItem, 0, len(f.Episodes)),
+	}
+	if f.ImageURL != "" {
+		channel.Image = &rssHref{Href: f.ImageURL}
+	}
+	if f.Category != "" {
+		channel.Category = &rssCategory{Text: f.Category}
+	}
+	for _, episode := range f.Episodes {
+		item := rssItem{
+			Title:       episode.Title,
+			Description: episode.Description,
+			Enclosure:   rssEnclosure{URL: episode.AudioURL, Length: episode.AudioSize, Type: episode.AudioType},
+			GUID:        rssGUID{IsPermaLink: "false", Value: episode.GUID},
+			Duration:    formatPodcastDuration(episode.Duration),
+		}
+		if item.Enclosure.Type == "" {
+			item.Enclosure.Type = "audio/mpeg"
+		}
+		if item.GUID.Value == "" {
+			item.GUID.Value = episode.AudioURL
+		}
+		if !episode.Published.IsZero() {
+			item.PubDate = episode.Published.Format(time.RFC1123Z)
+		}
+		channel.Items = append(channel.Items, item)
+	}
+	out, _ := xml.MarshalIndent(rssDocument{
+		Version: "2.0",
+		ITunes:  "http://www.itunes.com/dtds/podcast-1.0.dtd",
+		Channel: channel,
+	}, "", "  ")
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// formatPodcastDuration formats seconds as HH:MM:SS for itunes:duration.
+func formatPodcastDuration(seconds float64) string {
+	total := int(seconds + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total/60%60, total%60)
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	ITunes  string     `xml:"xmlns:itunes,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string       `xml:"title"`
+	Link        string       `xml:"link"`
+	Description string       `xml:"description"`
+	Language    string       `xml:"language,omitempty"`
+	Author      string       `xml:"itunes:author,omitempty"`
+	Image       *rssHref     `xml:"itunes:image"`
+	Category    *rssCategory `xml:"itunes:category"`
+	Explicit    string       `xml:"itunes:explicit"`
+	Items       []rssItem    `xml:"item"`
+}
+
+type rssHref struct {
+	Href string `xml:"href,attr"`
+}
+
+type rssCategory struct {
+	Text string `xml:"text,attr"`
+}
+
+type rssItem struct {
+	Title       string       `xml:"title"`
+	Description string       `xml:"description,omitempty"`
+	Enclosure   rssEnclosure `xml:"enclosure"`
+	GUID        rssGUID      `xml:"guid"`
+	PubDate     string       `xml:"pubDate,omitempty"`
+	Duration    string       `xml:"itunes:duration"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
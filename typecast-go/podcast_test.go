@@ -0,0 +1,96 @@
+package typecast
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPodcastFeed_RSS(t *testing.T) {
+	published := time.Date(2026, 10, 15, 6, 0, 0, 0, time.UTC)
+	episode := NewPodcastEpisode("Morning News", "https://cdn.example.com/2026-10-15.mp3",
+		&TTSResponse{AudioData: make([]byte, 2048), Duration: 3725.4, Format: AudioFormatMP3})
+	episode.Description = "Headlines & weather"
+	episode.Published = published
+	feed := &PodcastFeed{
+		Title:       "Daily Brief",
+		Link:        "https://example.com",
+		Description: "AI-narrated news",
+		Language:    "en-us",
+		Author:      "Example News",
+		ImageURL:    "https://example.com/art.png",
+		Category:    "News",
+		Explicit:    true,
+		Episodes: []PodcastEpisode{
+			episode,
+			{GUID: "ep-1", Title: "Pilot", AudioURL: "https://cdn.example.com/pilot.wav", AudioType: "audio/wav"},
+		},
+	}
+	data, err := feed.RSS()
+	if err != nil {
+		t.Fatalf("RSS() error = %v", err)
+	}
+	rss := string(data)
+	for _, want := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">`,
+		`<itunes:author>Example News</itunes:author>`,
+		`<itunes:image href="https://example.com/art.png"></itunes:image>`,
+		`<itunes:category text="News"></itunes:category>`,
+		`<itunes:explicit>true</itunes:explicit>`,
+		`<description>Headlines &amp; weather</description>`,
+		`<enclosure url="https://cdn.example.com/2026-10-15.mp3" length="2048" type="audio/mpeg"></enclosure>`,
+		`<guid isPermaLink="false">https://cdn.example.com/2026-10-15.mp3</guid>`,
+		`<pubDate>Thu, 15 Oct 2026 06:00:00 +0000</pubDate>`,
+		`<itunes:duration>01:02:05</itunes:duration>`,
+		`<guid isPermaLink="false">ep-1</guid>`,
+		`type="audio/wav"`,
+	} {
+		if !strings.Contains(rss, want) {
+			t.Fatalf("feed missing %s:\n%s", want, rss)
+		}
+	}
+	if strings.Count(rss, "<pubDate>") != 1 {
+		t.Fatal("episodes without a release time must omit pubDate")
+	}
+	var parsed struct {
+		Items []struct {
+			Title string `xml:"title"`
+		} `xml:"channel>item"`
+	}
+	if err := xml.Unmarshal(data, &parsed); err != nil || len(parsed.Items) != 2 {
+		t.Fatalf("feed must be well-formed: %v", err)
+	}
+
+	minimal := &PodcastFeed{Title: "t", Link: "l", Description: "d",
+		Episodes: []PodcastEpisode{NewPodcastEpisode("wav", "u", &TTSResponse{Format: AudioFormatWAV})}}
+	data, _ = minimal.RSS()
+	if strings.Contains(string(data), "itunes:image") || !strings.Contains(string(data), `type="audio/wav"`) || !strings.Contains(string(data), "<itunes:explicit>false") {
+		t.Fatalf("unexpected minimal feed:\n%s", data)
+	}
+	minimal.Episodes[0].AudioType = ""
+	if data, _ = minimal.RSS(); !strings.Contains(string(data), `type="audio/mpeg"`) {
+		t.Fatal("AudioType must default to audio/mpeg")
+	}
+}
+
+func TestPodcastFeed_Validate(t *testing.T) {
+	tests := []struct {
+		feed    PodcastFeed
+		message string
+	}{
+		{PodcastFeed{}, "title is required"},
+		{PodcastFeed{Title: "t"}, "link is required"},
+		{PodcastFeed{Title: "t", Link: "l"}, "description is required"},
+		{PodcastFeed{Title: "t", Link: "l", Description: "d", Episodes: []PodcastEpisode{{AudioURL: "u"}}}, "episode 0: title is required"},
+		{PodcastFeed{Title: "t", Link: "l", Description: "d", Episodes: []PodcastEpisode{{Title: "e"}}}, "episode 0: audio_url is required"},
+	}
+	for _, tt := range tests {
+		var validation *ValidationError
+		if _, err := tt.feed.RSS(); !errors.As(err, &validation) || validation.Message != tt.message {
+			t.Errorf("RSS() error = %v, want %q", err, tt.message)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package typecast
+
+import "context"
+
+// prefetchVoices fetches the voice catalog in the background for
+// PrefetchVoices, remembering each voice's languages for LanguageDetection
+// and, with a ResponseCache, caching the catalog response. Close stops it.
+// A failure is logged, and the first request fetches what it needs itself.
+func (c *Client) prefetchVoices() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.onClose(cancel)
+	c.prefetched = make(chan struct{})
+	go func() {
+		defer close(c.prefetched)
+		defer cancel()
+		err := c.EachVoiceV2(ctx, nil, func(voice VoiceV2) error {
+			c.voiceLanguages.Store(voice.VoiceID, voice.Languages)
+			return nil
+		})
+		if err != nil && c.logger != nil {
+			c.logger.Printf("level=warn msg=%q error=%q", "typecast: voice prefetch failed", err.Error())
+		}
+	}()
+}
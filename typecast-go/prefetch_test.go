@@ -0,0 +1,73 @@
+package typecast
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPrefetchVoices(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprint(w, `[{"voice_id":"tc_1","languages":["eng"]},{"voice_id":"tc_2","languages":["kor","eng"]}]`)
+	}))
+	defer srv.Close()
+	c := NewClient(&ClientConfig{
+		APIKey:         "k",
+		BaseURL:        srv.URL,
+		PrefetchVoices: true,
+		ResponseCache:  NewResponseCache(ResponseCacheConfig{TTL: time.Hour}),
+	})
+	defer c.Close()
+	<-c.prefetched
+
+	languages, ok := c.voiceLanguages.Load("tc_2")
+	if !ok || strings.Join(languages.([]string), ",") != "kor,eng" {
+		t.Fatalf("expected the prefetched languages, got %v", languages)
+	}
+	voices, err := c.GetVoicesV2(context.Background(), nil)
+	if err != nil || len(voices) != 2 || atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected the cached catalog, got %d voices after %d calls, %v", len(voices), calls, err)
+	}
+}
+
+func TestPrefetchVoices_LogsFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	logger := &recordingLogger{}
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, PrefetchVoices: true, Logger: logger})
+	<-c.prefetched
+	if len(logger.lines) != 1 || !strings.Contains(logger.lines[0], `msg="typecast: voice prefetch failed" error="`) {
+		t.Fatalf("expected the failure to be logged, got %q", logger.lines)
+	}
+
+	// Without a Logger the failure is dropped.
+	c = NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, PrefetchVoices: true})
+	<-c.prefetched
+}
+
+func TestPrefetchVoices_StopsOnClose(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, PrefetchVoices: true})
+	<-started
+	c.Close()
+	select {
+	case <-c.prefetched:
+	case <-time.After(5 * time.Second):
+		t.Fatal("prefetch did not stop on Close")
+	}
+}
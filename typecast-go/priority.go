@@ -0,0 +1,139 @@
+package typecast
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// Priority orders requests competing for a client's concurrency slots.
+// Interactive traffic should use PriorityHigh and background batches
+// PriorityLow so that real-time callers are not starved.
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+type contextKey int
+
+const (
+	priorityContextKey contextKey = iota
+	correlationIDContextKey
+	tagsContextKey
+	traceContextKey
+	tenantContextKey
+	identityContextKey
+	admittedContextKey
+)
+
+// WithPriority returns a context that tags requests made with it with p.
+// Priorities only take effect when MaxConcurrentRequests is configured.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityContextKey, p)
+}
+
+// PriorityFromContext returns the priority attached to ctx, or PriorityNormal.
+func PriorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityContextKey).(Priority); ok {
+		return p
+	}
+	return PriorityNormal
+}
+
+// slotScheduler hands out a fixed number of slots. When all slots are busy,
+// waiters are served by priority and then in arrival order.
+type slotScheduler struct {
+	mu       sync.Mutex
+	capacity int
+	inUse    int
+	seq      uint64
+	waiters  slotWaiters
+}
+
+type slotWaiter struct {
+	priority Priority
+	seq      uint64
+	index    int
+	ready    chan struct{}
+}
+
+func newSlotScheduler(capacity int) *slotScheduler {
+	return &slotScheduler{capacity: capacity}
+}
+
+func (s *slotScheduler) acquire(ctx context.Context) error {
+	s.mu.Lock()
+	if s.inUse < s.capacity && len(s.waiters) == 0 {
+		s.inUse++
+		s.mu.Unlock()
+		return nil
+	}
+	s.seq++
+	w := &slotWaiter{priority: PriorityFromContext(ctx), seq: s.seq, ready: make(chan struct{})}
+	heap.Push(&s.waiters, w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+	s.mu.Lock()
+	if w.index >= 0 {
+		heap.Remove(&s.waiters, w.index)
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+	s.mu.Unlock()
+	// The slot was granted while the context ended; hand it to the next waiter.
+	s.release()
+	return ctx.Err()
+}
+
+func (s *slotScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.waiters) > 0 {
+		w := heap.Pop(&s.waiters).(*slotWaiter)
+		close(w.ready)
+		return
+	}
+	s.inUse--
+}
+
+// slotWaiters implements heap.Interface ordered by priority, then arrival.
+type slotWaiters []*slotWaiter
+
+func (q slotWaiters) Len() int { return len(q) }
+
+func (q slotWaiters) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q slotWaiters) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *slotWaiters) Push(x interface{}) {
+	w := x.(*slotWaiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *slotWaiters) Pop() interface{} {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*q = old[:n-1]
+	return w
+}
@@ -0,0 +1,123 @@
+package typecast
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPriorityFromContext(t *testing.T) {
+	if got := PriorityFromContext(context.Background()); got != PriorityNormal {
+		t.Fatalf("expected default normal priority, got %v", got)
+	}
+	if got := PriorityFromContext(WithPriority(context.Background(), PriorityHigh)); got != PriorityHigh {
+		t.Fatalf("expected high priority, got %v", got)
+	}
+}
+
+// waitForWaiters blocks until the scheduler has n queued waiters.
+func waitForWaiters(t *testing.T, s *slotScheduler, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		got := len(s.waiters)
+		s.mu.Unlock()
+		if got == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d waiters", n)
+}
+
+func TestSlotScheduler_ServesByPriorityThenArrival(t *testing.T) {
+	s := newSlotScheduler(1)
+	if err := s.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	enqueue := func(name string, p Priority, queued int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.acquire(WithPriority(context.Background(), p)); err != nil {
+				t.Errorf("acquire(%s) error = %v", name, err)
+				return
+			}
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			s.release()
+		}()
+		waitForWaiters(t, s, queued)
+	}
+	enqueue("low", PriorityLow, 1)
+	enqueue("normal", PriorityNormal, 2)
+	enqueue("high-1", PriorityHigh, 3)
+	enqueue("high-2", PriorityHigh, 4)
+
+	s.release()
+	wg.Wait()
+	want := []string{"high-1", "high-2", "normal", "low"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("dispatch order = %v, want %v", order, want)
+	}
+	if s.inUse != 0 {
+		t.Fatalf("expected all slots released, inUse = %d", s.inUse)
+	}
+}
+
+func TestSlotScheduler_CanceledWaiterLeavesQueue(t *testing.T) {
+	s := newSlotScheduler(1)
+	_ = s.acquire(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.acquire(ctx) }()
+	waitForWaiters(t, s, 1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(s.waiters) != 0 {
+		t.Fatalf("expected empty queue, got %d waiters", len(s.waiters))
+	}
+	s.release()
+	if s.inUse != 0 {
+		t.Fatalf("expected slot to be free, inUse = %d", s.inUse)
+	}
+}
+
+func TestSlotScheduler_SlotGrantedAfterCancelIsPassedOn(t *testing.T) {
+	s := newSlotScheduler(1)
+	_ = s.acquire(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.acquire(ctx) }()
+	waitForWaiters(t, s, 1)
+
+	// Cancel while holding the lock so the waiter observes ctx.Done first,
+	// then grant it the slot before it can remove itself from the queue.
+	s.mu.Lock()
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	w := heap.Pop(&s.waiters).(*slotWaiter)
+	close(w.ready)
+	s.mu.Unlock()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if s.inUse != 0 {
+		t.Fatalf("expected granted slot to be returned, inUse = %d", s.inUse)
+	}
+}
@@ -0,0 +1,101 @@
+package typecast
+
+import (
+	"context"
+	"strings"
+)
+
+// VoiceProfile binds a voice ID to default synthesis settings so that named
+// characters sound the same on every request.
+type VoiceProfile struct {
+	// Name is an optional human-readable label (e.g., "narrator")
+	Name string
+	// VoiceID is the voice identifier (required)
+	VoiceID string
+	// Model is the TTS model to use (optional, defaults to ssfm-v30)
+	Model TTSModel
+	// Language is the ISO 639-3 language code (optional, auto-detected if not provided)
+	Language string
+	// EmotionPreset is the default emotion preset (optional)
+	EmotionPreset EmotionPreset
+	// EmotionIntensity is the default emotion strength (0.0 to 2.0, optional)
+	EmotionIntensity *float64
+	// AudioTempo is the default speech speed (0.5 to 2.0, optional)
+	AudioTempo *float64
+	// AudioPitch is the default pitch in semitones (-12 to +12, optional)
+	AudioPitch *int
+	// AudioFormat is the default output format (wav or mp3, optional)
+	AudioFormat AudioFormat
+}
+
+// Validate checks the VoiceProfile fields for invalid values.
+func (p VoiceProfile) Validate() error {
+	if strings.TrimSpace(p.VoiceID) == "" {
+		return newValidationError("voice_id", "voice_id is required")
+	}
+	if p.EmotionIntensity != nil && (*p.EmotionIntensity < 0 || *p.EmotionIntensity > 2.0) {
+		return newValidationError("emotion_intensity", "emotion_intensity must be between 0.0 and 2.0")
+	}
+	return p.output().Validate()
+}
+
+// Request builds a TTSRequest for text using the profile defaults.
+func (p VoiceProfile) Request(text string) *TTSRequest {
+	settings := p.ComposerSettings()
+	return &TTSRequest{
+		VoiceID:  settings.VoiceID,
+		Text:     text,
+		Model:    settings.Model,
+		Language: settings.Language,
+		Prompt:   settings.Prompt,
+		Output:   settings.Output,
+	}
+}
+
+// ComposerSettings converts the profile into settings usable with
+// SpeechComposer.Defaults or SpeechComposer.SayWith.
+func (p VoiceProfile) ComposerSettings() ComposerSettings {
+	model := p.Model
+	if model == "" {
+		model = ModelSSFMV30
+	}
+	return ComposerSettings{
+		VoiceID:  p.VoiceID,
+		Model:    model,
+		Language: p.Language,
+		Prompt:   p.prompt(model),
+		Output:   p.output(),
+	}
+}
+
+func (p VoiceProfile) prompt(model TTSModel) interface{} {
+	if p.EmotionPreset == "" && p.EmotionIntensity == nil {
+		return nil
+	}
+	if model == ModelSSFMV21 {
+		return &Prompt{EmotionPreset: p.EmotionPreset, EmotionIntensity: p.EmotionIntensity}
+	}
+	return &PresetPrompt{
+		EmotionType:      "preset",
+		EmotionPreset:    p.EmotionPreset,
+		EmotionIntensity: p.EmotionIntensity,
+	}
+}
+
+func (p VoiceProfile) output() *Output {
+	if p.AudioTempo == nil && p.AudioPitch == nil && p.AudioFormat == "" {
+		return nil
+	}
+	return &Output{AudioTempo: p.AudioTempo, AudioPitch: p.AudioPitch, AudioFormat: p.AudioFormat}
+}
+
+// SpeakWith synthesizes text using the settings stored in profile.
+func (c *Client) SpeakWith(ctx context.Context, profile VoiceProfile, text string) (*TTSResponse, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, newValidationError("text", "text is required")
+	}
+	return c.TextToSpeech(ctx, profile.Request(text))
+}
@@ -0,0 +1,133 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVoiceProfile_RequestV30UsesPresetPrompt(t *testing.T) {
+	intensity := 1.4
+	tempo := 1.1
+	pitch := -2
+	profile := VoiceProfile{
+		Name:             "narrator",
+		VoiceID:          "tc_narrator",
+		Language:         "eng",
+		EmotionPreset:    EmotionHappy,
+		EmotionIntensity: &intensity,
+		AudioTempo:       &tempo,
+		AudioPitch:       &pitch,
+		AudioFormat:      AudioFormatMP3,
+	}
+	req := profile.Request("Once upon a time")
+	if req.VoiceID != "tc_narrator" || req.Text != "Once upon a time" || req.Language != "eng" {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	if req.Model != ModelSSFMV30 {
+		t.Fatalf("expected default model ssfm-v30, got %s", req.Model)
+	}
+	prompt, ok := req.Prompt.(*PresetPrompt)
+	if !ok {
+		t.Fatalf("expected *PresetPrompt, got %T", req.Prompt)
+	}
+	if prompt.EmotionType != "preset" || prompt.EmotionPreset != EmotionHappy || *prompt.EmotionIntensity != 1.4 {
+		t.Fatalf("unexpected prompt: %+v", prompt)
+	}
+	if req.Output == nil || *req.Output.AudioTempo != 1.1 || *req.Output.AudioPitch != -2 || req.Output.AudioFormat != AudioFormatMP3 {
+		t.Fatalf("unexpected output: %+v", req.Output)
+	}
+}
+
+func TestVoiceProfile_RequestV21UsesBasicPrompt(t *testing.T) {
+	profile := VoiceProfile{VoiceID: "tc_a", Model: ModelSSFMV21, EmotionPreset: EmotionSad}
+	req := profile.Request("hi")
+	prompt, ok := req.Prompt.(*Prompt)
+	if !ok {
+		t.Fatalf("expected *Prompt, got %T", req.Prompt)
+	}
+	if prompt.EmotionPreset != EmotionSad {
+		t.Fatalf("unexpected prompt: %+v", prompt)
+	}
+	if req.Output != nil {
+		t.Fatalf("expected nil output, got %+v", req.Output)
+	}
+}
+
+func TestVoiceProfile_RequestWithoutEmotionOmitsPrompt(t *testing.T) {
+	req := VoiceProfile{VoiceID: "tc_a"}.Request("hi")
+	if req.Prompt != nil {
+		t.Fatalf("expected nil prompt, got %#v", req.Prompt)
+	}
+}
+
+func TestVoiceProfile_Validate(t *testing.T) {
+	badIntensity := 2.5
+	badTempo := 3.0
+	cases := []struct {
+		name    string
+		profile VoiceProfile
+		want    string
+	}{
+		{"missing voice", VoiceProfile{VoiceID: " "}, "voice_id is required"},
+		{"bad intensity", VoiceProfile{VoiceID: "v", EmotionIntensity: &badIntensity}, "emotion_intensity"},
+		{"bad tempo", VoiceProfile{VoiceID: "v", AudioTempo: &badTempo}, "audio_tempo"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.profile.Validate()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected %q error, got %v", tc.want, err)
+			}
+		})
+	}
+	if err := (VoiceProfile{VoiceID: "v"}).Validate(); err != nil {
+		t.Fatalf("expected valid profile, got %v", err)
+	}
+}
+
+func TestSpeakWith_SendsProfileSettings(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte("WAV"))
+	}))
+	defer srv.Close()
+
+	tempo := 0.9
+	profile := VoiceProfile{VoiceID: "tc_agent", EmotionPreset: EmotionToneUp, AudioTempo: &tempo}
+	resp, err := newTestClient(srv, "k").SpeakWith(context.Background(), profile, "How can I help?")
+	if err != nil {
+		t.Fatalf("SpeakWith() error = %v", err)
+	}
+	if string(resp.AudioData) != "WAV" {
+		t.Fatalf("unexpected audio: %q", resp.AudioData)
+	}
+	if body["voice_id"] != "tc_agent" || body["model"] != "ssfm-v30" || body["text"] != "How can I help?" {
+		t.Fatalf("unexpected body: %v", body)
+	}
+	prompt := body["prompt"].(map[string]interface{})
+	if prompt["emotion_type"] != "preset" || prompt["emotion_preset"] != "toneup" {
+		t.Fatalf("unexpected prompt: %v", prompt)
+	}
+	output := body["output"].(map[string]interface{})
+	if output["audio_tempo"] != 0.9 {
+		t.Fatalf("unexpected output: %v", output)
+	}
+}
+
+func TestSpeakWith_ValidationErrors(t *testing.T) {
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://x"})
+	if _, err := c.SpeakWith(context.Background(), VoiceProfile{}, "hi"); err == nil || !strings.Contains(err.Error(), "voice_id is required") {
+		t.Fatalf("expected voice_id error, got %v", err)
+	}
+	if _, err := c.SpeakWith(context.Background(), VoiceProfile{VoiceID: "v"}, "  "); err == nil || !strings.Contains(err.Error(), "text is required") {
+		t.Fatalf("expected text error, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package typecast
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PronunciationLexicon maps words the voices mispronounce, such as brand
+// names, to how they are said. The API takes no phonetic input, so hints
+// are respellings sent in place of the word, such as "ny-kee" for "Nike",
+// or IPA between slashes, such as "/ˈnaɪki/", which is converted to an
+// English respelling. Words and phrases are matched case-insensitively. A
+// PronunciationLexicon is safe for concurrent use.
+type PronunciationLexicon struct {
+	mu      sync.RWMutex
+	entries map[string]string
+	words   []string // entry keys, longest first
+}
+
+// NewPronunciationLexicon creates a lexicon from a word-to-hint map.
+func NewPronunciationLexicon(hints map[string]string) (*PronunciationLexicon, error) {
+	l := &PronunciationLexicon{entries: map[string]string{}}
+	for word, hint := range hints {
+		if err := l.Add(word, hint); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// LoadPronunciationLexicon reads a JSON object of word-to-hint pairs, e.g.
+// {"Nike": "/ˈnaɪki/", "Hyundai": "hun-day"}.
+func LoadPronunciationLexicon(r io.Reader) (*PronunciationLexicon, error) {
+	var hints map[string]string
+	if err := json.NewDecoder(r).Decode(&hints); err != nil {
+		return nil, fmt.Errorf("failed to decode pronunciations: %w", err)
+	}
+	return NewPronunciationLexicon(hints)
+}
+
+// LoadPronunciationLexiconFile reads a JSON pronunciation file from path.
+func LoadPronunciationLexiconFile(path string) (*PronunciationLexicon, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pronunciations: %w", err)
+	}
+	defer f.Close()
+	return LoadPronunciationLexicon(f)
+}
+
+// Add adds or replaces the hint for word, which may be a phrase. It fails
+// when the hint is empty or is IPA with a symbol it cannot respell; the
+// error shows word through RedactHash.
+func (l *PronunciationLexicon) Add(word, hint string) error {
+	key := strings.ToLower(strings.TrimSpace(word))
+	if key == "" {
+		return fmt.Errorf("pronunciation word cannot be empty")
+	}
+	spoken, err := pronunciationHint(hint)
+	if err != nil {
+		return fmt.Errorf("pronunciation for %s: %w", RedactHash(word), err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.entries[key]; !ok {
+		l.words = append(l.words, key)
+		sort.SliceStable(l.words, func(i, j int) bool {
+			return utf8.RuneCountInString(l.words[i]) > utf8.RuneCountInString(l.words[j])
+		})
+	}
+	l.entries[key] = spoken
+	return nil
+}
+
+// Lookup returns the respelling sent for word.
+func (l *PronunciationLexicon) Lookup(word string) (string, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	spoken, ok := l.entries[strings.ToLower(strings.TrimSpace(word))]
+	return spoken, ok
+}
+
+// Apply replaces the lexicon's words in text with their respellings, then
+// any inline annotations, with ApplyPronunciationHints. Longer entries win
+// over the words they contain. A word must stand alone, except that a word
+// ending in Hangul, kana, or Han may be followed by a particle, as in
+// "나이키는".
+func (l *PronunciationLexicon) Apply(text string) string {
+	text = ApplyPronunciationHints(text)
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if len(l.words) == 0 {
+		return text
+	}
+	var b strings.Builder
+	prev := rune(-1)
+	for i := 0; i < len(text); {
+		if !isWordRune(prev) {
+			if key, n := l.match(text[i:]); n > 0 {
+				b.WriteString(l.entries[key])
+				r, _ := utf8.DecodeLastRuneInString(text[i : i+n])
+				prev = r
+				i += n
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		b.WriteString(text[i : i+size])
+		prev = r
+		i += size
+	}
+	return b.String()
+}
+
+// match returns the longest entry text starts with, and its length in
+// text. l.mu must be held.
+func (l *PronunciationLexicon) match(text string) (string, int) {
+	for _, key := range l.words {
+		n, runes := 0, utf8.RuneCountInString(key)
+		for ; runes > 0 && n < len(text); runes-- {
+			_, size := utf8.DecodeRuneInString(text[n:])
+			n += size
+		}
+		if runes > 0 || !strings.EqualFold(text[:n], key) {
+			continue
+		}
+		last, _ := utf8.DecodeLastRuneInString(key)
+		next, _ := utf8.DecodeRuneInString(text[n:])
+		if n == len(text) || !isWordRune(next) || unicode.In(last, unicode.Hangul, unicode.Hiragana, unicode.Katakana, unicode.Han) {
+			return key, n
+		}
+	}
+	return "", 0
+}
+
+// ApplyPronunciationHints replaces inline pronunciation annotations in
+// text, written {word|hint}, with the hint's respelling, as in
+// "{Nike|/ˈnaɪki/} shoes" or "{Nike|ny-kee} shoes". An annotation whose
+// hint cannot be respelled is replaced by its word.
+func ApplyPronunciationHints(text string) string {
+	if !strings.Contains(text, "|") {
+		return text
+	}
+	var b strings.Builder
+	for {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexAny(text[open+1:], "{}\n")
+		if end < 0 || text[open+1+end] != '}' {
+			b.WriteString(text[:open+1])
+			text = text[open+1:]
+			continue
+		}
+		annotation := text[open+1 : open+1+end]
+		bar := strings.IndexByte(annotation, '|')
+		if bar < 0 {
+			b.WriteString(text[:open+1])
+			text = text[open+1:]
+			continue
+		}
+		b.WriteString(text[:open])
+		word := annotation[:bar]
+		if spoken, err := pronunciationHint(annotation[bar+1:]); err == nil {
+			b.WriteString(spoken)
+		} else {
+			b.WriteString(word)
+		}
+		text = text[open+1+end+1:]
+	}
+	b.WriteString(text)
+	return b.String()
+}
+
+// pronunciationHint returns the respelling to send for hint: the hint
+// itself, or the respelling of IPA written between slashes or brackets.
+func pronunciationHint(hint string) (string, error) {
+	hint = strings.TrimSpace(hint)
+	if hint == "" {
+		return "", fmt.Errorf("pronunciation hint cannot be empty")
+	}
+	if len(hint) > 2 && (hint[0] == '/' && hint[len(hint)-1] == '/' || hint[0] == '[' && hint[len(hint)-1] == ']') {
+		return RespellIPA(hint[1 : len(hint)-1])
+	}
+	return hint, nil
+}
@@ -0,0 +1,145 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRespellIPA(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"ˈnaɪki", "ny-kee"},
+		{"/aɪˈkiːə/", "eye-kee-uh"},
+		{"ˈvɛri", "veh-ree"},
+		{"ˈhʌn.deɪ", "huhn-day"},
+		{"ˈpɔrʃə", "por-shuh"},
+		{"ˈbaɪt", "bight"},
+		{"dʒɛt ˈblu", "jeht bloo"},
+		{"ˈθɪŋk", "thihngk"},
+		{"ˈskɹ̩", "skr"},
+	}
+	for _, tt := range tests {
+		got, err := RespellIPA(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("RespellIPA(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"ˈnaɪkʀ", "ˈ ."} {
+		// The error redacts the transcription, which may spell out a name.
+		if _, err := RespellIPA(bad); err == nil || strings.Contains(err.Error(), strings.TrimLeft(bad, "ˈ")) || !strings.Contains(err.Error(), "[redacted ") {
+			t.Errorf("RespellIPA(%q) = %v, want a redacted error", bad, err)
+		}
+	}
+}
+
+func TestApplyPronunciationHints(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"New {Nike|/ˈnaɪki/} shoes", "New ny-kee shoes"},
+		{"{Hyundai|hun-day} and {Kia| kee-uh }", "hun-day and kee-uh"},
+		{"{Nike|/ˈnaɪkʀ/} shoes", "Nike shoes"},
+		{"{Nike|} shoes", "Nike shoes"},
+		{"a|b {no bar} {open {Nike|ny-kee}", "a|b {no bar} {open ny-kee"},
+		{"{unterminated|x", "{unterminated|x"},
+		{"{line|\nbreak}", "{line|\nbreak}"},
+		{"plain text", "plain text"},
+	}
+	for _, tt := range tests {
+		if got := ApplyPronunciationHints(tt.in); got != tt.want {
+			t.Errorf("ApplyPronunciationHints(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPronunciationLexicon(t *testing.T) {
+	lexicon, err := NewPronunciationLexicon(map[string]string{
+		"Nike":         "/ˈnaɪki/",
+		"Nike Air":     "ny-kee air",
+		"나이키":          "나이끼",
+		"Porsche":      "por-shuh",
+		"Porsche 911":  "por-shuh nine eleven",
+		"Huawei Mate ": "wah-way mate",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct{ in, want string }{
+		{"NIKE, Nike Air, and nike.", "ny-kee, ny-kee air, and ny-kee."},
+		{"Nikes and SNIKE stay", "Nikes and SNIKE stay"},
+		{"나이키는 좋아요", "나이끼는 좋아요"},
+		{"My Porsche 911 and Porsche 9110", "My por-shuh nine eleven and por-shuh 9110"},
+		{"huawei mate", "wah-way mate"},
+		{"{Kia|kee-uh} Nike", "kee-uh ny-kee"},
+		{"Nik", "Nik"},
+	}
+	for _, tt := range tests {
+		if got := lexicon.Apply(tt.in); got != tt.want {
+			t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if spoken, ok := lexicon.Lookup(" nike "); !ok || spoken != "ny-kee" {
+		t.Fatalf("Lookup = %q, %v", spoken, ok)
+	}
+	if err := lexicon.Add("Nike", "nigh-key"); err != nil {
+		t.Fatal(err)
+	}
+	if got := lexicon.Apply("Nike"); got != "nigh-key" {
+		t.Fatalf("replaced hint not applied: %q", got)
+	}
+
+	empty, _ := NewPronunciationLexicon(nil)
+	if got := empty.Apply("{Nike|ny-kee} Nike"); got != "ny-kee Nike" {
+		t.Fatalf("empty lexicon: %q", got)
+	}
+	for _, hints := range []map[string]string{{" ": "x"}, {"Nike": " "}, {"Nike": "/ʀ/"}} {
+		if _, err := NewPronunciationLexicon(hints); err == nil {
+			t.Errorf("NewPronunciationLexicon(%q) succeeded", hints)
+		}
+	}
+	if _, err := NewPronunciationLexicon(map[string]string{"Jane": "/ʀ/"}); err == nil || strings.Contains(err.Error(), "Jane") {
+		t.Errorf("expected a redacted error, got %v", err)
+	}
+}
+
+func TestLoadPronunciationLexiconFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pronunciations.json")
+	if err := os.WriteFile(path, []byte(`{"Nike": "/ˈnaɪki/"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lexicon, err := LoadPronunciationLexiconFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spoken, _ := lexicon.Lookup("Nike"); spoken != "ny-kee" {
+		t.Fatalf("Lookup = %q", spoken)
+	}
+	if _, err := LoadPronunciationLexiconFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected an open error")
+	}
+	if _, err := LoadPronunciationLexicon(strings.NewReader(`[`)); err == nil {
+		t.Fatal("expected a decode error")
+	}
+}
+
+func TestPronunciationsInRequests(t *testing.T) {
+	var text string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		text = body.Text
+		_, _ = w.Write([]byte("RIFF"))
+	}))
+	defer srv.Close()
+	lexicon, _ := NewPronunciationLexicon(map[string]string{"Nike": "ny-kee"})
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Pronunciations: lexicon})
+	if _, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "v", Text: "Nike and {Kia|/ˈkiə/}", Model: ModelSSFMV30}); err != nil {
+		t.Fatal(err)
+	}
+	if text != "ny-kee and kee-uh" {
+		t.Fatalf("sent %q", text)
+	}
+}
@@ -0,0 +1,279 @@
+package typecast
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrJobNotFound is returned by JobQueue.Get for an unknown job ID.
+	ErrJobNotFound = errors.New("typecast: job not found")
+	// ErrJobActive is returned by JobQueue.Enqueue for the ID of a job
+	// that is queued or running.
+	ErrJobActive = errors.New("typecast: job is queued or running")
+)
+
+// QueuedJobState is the state of a job in a JobQueue.
+type QueuedJobState string
+
+const (
+	// JobQueued means the job waits for a worker.
+	JobQueued QueuedJobState = "queued"
+	// JobRunning means a worker is synthesizing the job. Jobs left running
+	// by a crash are queued again when the queue is reopened.
+	JobRunning QueuedJobState = "running"
+	// JobDone means the audio was written.
+	JobDone QueuedJobState = "done"
+	// JobFailed means the job failed for good; see QueuedJob.Error.
+	JobFailed QueuedJobState = "failed"
+)
+
+// QueuedJob is a narration job in a JobQueue.
+type QueuedJob struct {
+	// ID identifies the job and must be a plain file name, as it names the
+	// audio without Output; Enqueue assigns one when it is empty
+	ID string `json:"id"`
+	// Job holds the text and the voice settings that override the
+	// daemon's profile
+	Job WatchJob `json:"job"`
+	// Output is the name of the audio file written to the daemon's output
+	// directory (optional, defaults to the ID and the format's extension)
+	Output string `json:"output,omitempty"`
+	// Sink delivers the audio instead of the daemon's output directory
+	// (optional)
+	Sink *OutputSink `json:"sink,omitempty"`
+	// State is the job's state
+	State QueuedJobState `json:"state"`
+	// Attempts is the number of times a worker started the job
+	Attempts int `json:"attempts,omitempty"`
+	// Error describes the last failure, if any
+	Error string `json:"error,omitempty"`
+	// Audio is the name of the audio file written, once done
+	Audio string `json:"audio,omitempty"`
+	// Duration is the audio's duration in seconds, once done
+	Duration float64 `json:"duration,omitempty"`
+	// NotBefore delays the job's next attempt after a rate limit or a
+	// retryable failure
+	NotBefore time.Time `json:"not_before"`
+	// Created is when the job was enqueued
+	Created time.Time `json:"created"`
+	// Updated is when the job last changed
+	Updated time.Time `json:"updated"`
+}
+
+// JobQueue is a persistent queue of narration jobs, processed by
+// Client.RunDaemon. Every change is appended to a log file and synced
+// before it is acknowledged, so enqueued jobs survive a crash or restart;
+// opening the queue replays and compacts the log. A queue file must be
+// opened by one process at a time. JobQueue is safe for concurrent use.
+type JobQueue struct {
+	mu    sync.Mutex
+	log   *os.File
+	jobs  map[string]*QueuedJob
+	order []string
+	ready chan struct{}
+}
+
+// OpenJobQueue opens the queue stored at path, creating it when missing.
+// Jobs that were running when the queue was last closed are queued again.
+func OpenJobQueue(path string) (*JobQueue, error) {
+	q := &JobQueue{jobs: map[string]*QueuedJob{}, ready: make(chan struct{}, 1)}
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read job queue: %w", err)
+	}
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		var job QueuedJob
+		if err := json.Unmarshal(line, &job); err != nil || job.ID == "" {
+			continue // a blank line, or one torn by a crash
+		}
+		if _, seen := q.jobs[job.ID]; !seen {
+			q.order = append(q.order, job.ID)
+		}
+		if job.State == JobRunning {
+			job.State = JobQueued
+		}
+		q.jobs[job.ID] = &job
+	}
+
+	// The log is compacted to one line per job in a new file that replaces
+	// it, and that file is kept open for appending.
+	var compacted bytes.Buffer
+	for _, id := range q.order {
+		line, _ := json.Marshal(q.jobs[id])
+		compacted.Write(append(line, '\n'))
+	}
+	q.log, err = os.OpenFile(path+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0644)
+	if err == nil {
+		_, err = q.log.Write(compacted.Bytes())
+		if err == nil {
+			err = q.log.Sync()
+		}
+		if err == nil {
+			err = os.Rename(path+".tmp", path)
+		}
+	}
+	if err != nil {
+		_ = q.log.Close()
+		return nil, fmt.Errorf("failed to open job queue: %w", err)
+	}
+	q.signal()
+	return q, nil
+}
+
+// Close closes the queue's log file.
+func (q *JobQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.log.Close()
+}
+
+// Enqueue validates job and adds it to the queue. It returns the job as
+// stored, with its ID and state set. Enqueueing an ID that is already
+// queued or running fails; a finished job is replaced. The ID and Output
+// must be plain file names, as they name the audio written into the
+// daemon's output directory.
+// The job's Created time is the local time, since a queue is not tied to a
+// client's Clock.
+func (q *JobQueue) Enqueue(job QueuedJob) (QueuedJob, error) {
+	return q.enqueue(job, time.Now())
+}
+
+// enqueue adds job to the queue at now.
+func (q *JobQueue) enqueue(job QueuedJob, now time.Time) (QueuedJob, error) {
+	if strings.TrimSpace(job.Job.Text) == "" {
+		return QueuedJob{}, newValidationError("text", "text is required")
+	}
+	if job.Output != "" && !isFileName(job.Output) {
+		return QueuedJob{}, newValidationError("output", "output must be a file name without directories")
+	}
+	if !job.Sink.valid() {
+		return QueuedJob{}, newValidationError("sink", "sink needs one of dir or url")
+	}
+	if job.ID != "" && !isFileName(job.ID) {
+		return QueuedJob{}, newValidationError("id", "id must be a file name without directories")
+	}
+	if job.ID == "" {
+		id := make([]byte, 8)
+		_, _ = rand.Read(id)
+		job.ID = hex.EncodeToString(id)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if prev, ok := q.jobs[job.ID]; ok && (prev.State == JobQueued || prev.State == JobRunning) {
+		return QueuedJob{}, fmt.Errorf("job %s: %w", job.ID, ErrJobActive)
+	}
+	now = now.UTC()
+	job.State, job.Attempts, job.Error, job.Audio, job.Duration = JobQueued, 0, "", "", 0
+	job.NotBefore, job.Created, job.Updated = time.Time{}, now, now
+	if err := q.save(&job); err != nil {
+		return QueuedJob{}, err
+	}
+	q.signal()
+	return job, nil
+}
+
+// isFileName reports whether name is a file name without directories.
+func isFileName(name string) bool {
+	return filepath.Base(name) == name && name != "." && name != ".."
+}
+
+// Get returns the job with id, or ErrJobNotFound.
+func (q *JobQueue) Get(id string) (QueuedJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return QueuedJob{}, ErrJobNotFound
+	}
+	return *job, nil
+}
+
+// List returns the jobs in the order they were first enqueued.
+func (q *JobQueue) List() []QueuedJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]QueuedJob, 0, len(q.order))
+	for _, id := range q.order {
+		jobs = append(jobs, *q.jobs[id])
+	}
+	return jobs
+}
+
+// claim marks the oldest queued job that is due at now as running and
+// returns it. When none is due, it returns false and the time the next
+// delayed job is due, or the zero time.
+func (q *JobQueue) claim(now time.Time) (QueuedJob, time.Time, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var next time.Time
+	for _, id := range q.order {
+		job := q.jobs[id]
+		if job.State != JobQueued {
+			continue
+		}
+		if job.NotBefore.After(now) {
+			if next.IsZero() || job.NotBefore.Before(next) {
+				next = job.NotBefore
+			}
+			continue
+		}
+		claimed := *job
+		claimed.State, claimed.Attempts, claimed.Updated = JobRunning, job.Attempts+1, now.UTC()
+		if err := q.save(&claimed); err != nil {
+			return QueuedJob{}, time.Time{}, false, err
+		}
+		return claimed, time.Time{}, true, nil
+	}
+	return QueuedJob{}, next, false, nil
+}
+
+// update records a change to a claimed job at now.
+func (q *JobQueue) update(job QueuedJob, now time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job.Updated = now.UTC()
+	if err := q.save(&job); err != nil {
+		return err
+	}
+	if job.State == JobQueued {
+		q.signal()
+	}
+	return nil
+}
+
+// save appends job to the log and syncs it before updating the in-memory
+// state. q.mu must be held.
+func (q *JobQueue) save(job *QueuedJob) error {
+	line, _ := json.Marshal(job)
+	_, err := q.log.Write(append(line, '\n'))
+	if err == nil {
+		err = q.log.Sync()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write job queue: %w", err)
+	}
+	if _, ok := q.jobs[job.ID]; !ok {
+		q.order = append(q.order, job.ID)
+	}
+	stored := *job
+	q.jobs[job.ID] = &stored
+	return nil
+}
+
+// signal wakes one idle worker.
+func (q *JobQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
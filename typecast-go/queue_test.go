@@ -0,0 +1,106 @@
+package typecast
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.db")
+	q, err := OpenJobQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := q.Enqueue(QueuedJob{Job: WatchJob{Text: "First."}})
+	if err != nil || first.ID == "" || first.State != JobQueued || first.Created.IsZero() {
+		t.Fatalf("Enqueue = %+v, %v", first, err)
+	}
+	if _, err := q.Enqueue(QueuedJob{ID: "daily", Job: WatchJob{Text: "Second."}, Output: "daily.mp3"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Enqueue(QueuedJob{ID: "daily", Job: WatchJob{Text: "Again."}}); !errors.Is(err, ErrJobActive) {
+		t.Fatalf("expected ErrJobActive, got %v", err)
+	}
+	for _, bad := range []QueuedJob{
+		{Job: WatchJob{Text: " "}},
+		{Job: WatchJob{Text: "x"}, Output: "../escape.wav"},
+		{Job: WatchJob{Text: "x"}, Output: ".."},
+		{ID: "../../tmp/escaped", Job: WatchJob{Text: "x"}},
+		{ID: "..", Job: WatchJob{Text: "x"}},
+	} {
+		var validationErr *ValidationError
+		if _, err := q.Enqueue(bad); !errors.As(err, &validationErr) {
+			t.Errorf("Enqueue(%+v) = %v, want a validation error", bad, err)
+		}
+	}
+
+	claimed, _, ok, err := q.claim(time.Now())
+	if !ok || err != nil || claimed.ID != first.ID || claimed.State != JobRunning || claimed.Attempts != 1 {
+		t.Fatalf("claim = %+v, %v, %v", claimed, ok, err)
+	}
+	if job, _ := q.Get(first.ID); job.State != JobRunning {
+		t.Fatalf("claimed job is %s", job.State)
+	}
+	if _, err := q.Get("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("expected ErrJobNotFound, got %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash leaves a torn line; the running job is queued again.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	_, _ = f.WriteString(`{"id": "torn", "job": {"te`)
+	_ = f.Close()
+	q, err = OpenJobQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	jobs := q.List()
+	if len(jobs) != 2 || jobs[0].ID != first.ID || jobs[0].State != JobQueued || jobs[1].Output != "daily.mp3" {
+		t.Fatalf("reopened queue holds %+v", jobs)
+	}
+	if b, _ := os.ReadFile(path); strings.Count(string(b), "\n") != 2 {
+		t.Fatalf("queue was not compacted:\n%s", b)
+	}
+
+	// A delayed job reports when it is due.
+	jobs[0].NotBefore = time.Now().Add(time.Hour)
+	_ = q.update(jobs[0], time.Now())
+	claimed, _, _, _ = q.claim(time.Now())
+	claimed.State = JobDone
+	_ = q.update(claimed, time.Now())
+	if _, next, ok, _ := q.claim(time.Now()); ok || !next.Equal(jobs[0].NotBefore) {
+		t.Fatalf("claim = %v, next %v", ok, next)
+	}
+	if again, err := q.Enqueue(QueuedJob{ID: "daily", Job: WatchJob{Text: "Tomorrow."}}); err != nil || again.Attempts != 0 {
+		t.Fatalf("re-enqueue of a finished job = %+v, %v", again, err)
+	}
+}
+
+func TestJobQueueErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := OpenJobQueue(dir); err == nil || !strings.Contains(err.Error(), "failed to read job queue") {
+		t.Fatalf("expected a read error, got %v", err)
+	}
+	if _, err := OpenJobQueue(filepath.Join(dir, "missing", "queue.db")); err == nil || !strings.Contains(err.Error(), "failed to open job queue") {
+		t.Fatalf("expected an open error, got %v", err)
+	}
+	q, err := OpenJobQueue(filepath.Join(dir, "queue.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = q.Enqueue(QueuedJob{Job: WatchJob{Text: "x"}})
+	_ = q.Close()
+	if _, err := q.Enqueue(QueuedJob{Job: WatchJob{Text: "x"}}); err == nil {
+		t.Fatal("expected a write error")
+	}
+	if _, _, _, err := q.claim(time.Now()); err == nil {
+		t.Fatal("expected a write error")
+	}
+}
@@ -198,6 +198,7 @@ func TestCloneVoicePropagatesDoError(t *testing.T) {
 	c := &Client{
 		apiKey:  "k",
 		baseURL: "https://api.example.test",
+		clock:   SystemClock,
 		httpClient: &http.Client{
 			Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
 				return nil, io.ErrUnexpectedEOF
@@ -300,6 +301,7 @@ func TestDeleteVoicePropagatesDoError(t *testing.T) {
 	c := &Client{
 		apiKey:  "k",
 		baseURL: "https://api.example.test",
+		clock:   SystemClock,
 		httpClient: &http.Client{
 			Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
 				return nil, io.ErrUnexpectedEOF
@@ -0,0 +1,174 @@
+package typecast
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// QuotaConfig configures a QuotaGuard.
+type QuotaConfig struct {
+	// Window is the length of each budget period, e.g. 24h or 30 days
+	// (optional, 0 means the budget never resets)
+	Window time.Duration
+	// SoftLimit is the character count that triggers OnSoftLimit once per
+	// window (optional, 0 disables it)
+	SoftLimit int
+	// HardLimit is the character count no window may exceed (optional, 0
+	// disables it)
+	HardLimit int
+	// BlockOnHardLimit makes requests over the hard limit wait for the next
+	// window instead of failing with a *QuotaExceededError (optional)
+	BlockOnHardLimit bool
+	// OnSoftLimit is called when usage in a window first reaches SoftLimit
+	// (optional)
+	OnSoftLimit func(QuotaUsage)
+	// OnHardLimit is called each time a request would exceed HardLimit
+	// (optional)
+	OnHardLimit func(QuotaUsage)
+	// Clock measures the windows and times BlockOnHardLimit waits
+	// (optional, defaults to SystemClock)
+	Clock Clock
+}
+
+// QuotaUsage is a snapshot of a QuotaGuard's current window.
+type QuotaUsage struct {
+	// Used is the number of characters charged in the window
+	Used int
+	// Requested is the size of the request that triggered a callback, if any
+	Requested int
+	// SoftLimit and HardLimit are the configured budgets
+	SoftLimit int
+	HardLimit int
+	// ResetsAt is when the window ends (zero if it never resets)
+	ResetsAt time.Time
+}
+
+// QuotaExceededError is returned when a request would push usage past the
+// hard limit of a QuotaGuard. No request is sent to the API.
+type QuotaExceededError struct {
+	QuotaUsage
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("character quota exceeded: %d used + %d requested > %d", e.Used, e.Requested, e.HardLimit)
+}
+
+// QuotaGuard counts characters synthesized locally and enforces soft and
+// hard budgets per window, independent of server-side credit enforcement.
+// Characters are charged before a request is sent and refunded if it fails.
+// A QuotaGuard is safe for concurrent use; share one between clients to
+// budget them together.
+type QuotaGuard struct {
+	config QuotaConfig
+	clock  Clock
+
+	mu          sync.Mutex
+	used        int
+	windowStart time.Time
+	softFired   bool
+}
+
+// NewQuotaGuard creates a guard with the given budgets.
+func NewQuotaGuard(config QuotaConfig) *QuotaGuard {
+	return &QuotaGuard{config: config, clock: clockOrSystem(config.Clock)}
+}
+
+// Usage returns the characters charged in the current window.
+func (g *QuotaGuard) Usage() QuotaUsage {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.roll()
+	return g.usage(0)
+}
+
+// reserve charges chars against the budget, blocking or failing when the
+// hard limit would be exceeded.
+func (g *QuotaGuard) reserve(ctx context.Context, chars int) error {
+	for {
+		g.mu.Lock()
+		g.roll()
+		if g.config.HardLimit > 0 && g.used+chars > g.config.HardLimit {
+			usage := g.usage(chars)
+			g.mu.Unlock()
+			if g.config.OnHardLimit != nil {
+				g.config.OnHardLimit(usage)
+			}
+			if !g.config.BlockOnHardLimit || g.config.Window <= 0 || chars > g.config.HardLimit {
+				return &QuotaExceededError{QuotaUsage: usage}
+			}
+			if err := sleepContext(ctx, g.clock, usage.ResetsAt.Sub(g.clock.Now())); err != nil {
+				return err
+			}
+			continue
+		}
+		g.used += chars
+		var soft *QuotaUsage
+		if g.config.SoftLimit > 0 && !g.softFired && g.used >= g.config.SoftLimit {
+			g.softFired = true
+			usage := g.usage(chars)
+			soft = &usage
+		}
+		g.mu.Unlock()
+		if soft != nil && g.config.OnSoftLimit != nil {
+			g.config.OnSoftLimit(*soft)
+		}
+		return nil
+	}
+}
+
+// refund returns chars charged for a request that did not succeed.
+func (g *QuotaGuard) refund(chars int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.used -= chars
+	if g.used < 0 {
+		g.used = 0
+	}
+}
+
+// roll starts a new window once the current one has ended.
+func (g *QuotaGuard) roll() {
+	now := g.clock.Now()
+	if g.windowStart.IsZero() {
+		g.windowStart = now
+	}
+	if g.config.Window > 0 && !now.Before(g.windowStart.Add(g.config.Window)) {
+		elapsed := now.Sub(g.windowStart) / g.config.Window
+		g.windowStart = g.windowStart.Add(elapsed * g.config.Window)
+		g.used = 0
+		g.softFired = false
+	}
+}
+
+func (g *QuotaGuard) usage(requested int) QuotaUsage {
+	usage := QuotaUsage{
+		Used:      g.used,
+		Requested: requested,
+		SoftLimit: g.config.SoftLimit,
+		HardLimit: g.config.HardLimit,
+	}
+	if g.config.Window > 0 {
+		usage.ResetsAt = g.windowStart.Add(g.config.Window)
+	}
+	return usage
+}
+
+// reserveQuota charges the characters of texts against the configured
+// QuotaGuard. The returned function refunds the charge and must be called
+// when the request fails.
+func (c *Client) reserveQuota(ctx context.Context, texts ...string) (func(), error) {
+	if c.quotaGuard == nil {
+		return func() {}, nil
+	}
+	chars := 0
+	for _, text := range texts {
+		chars += utf8.RuneCountInString(text)
+	}
+	if err := c.quotaGuard.reserve(ctx, chars); err != nil {
+		return nil, err
+	}
+	return func() { c.quotaGuard.refund(chars) }, nil
+}
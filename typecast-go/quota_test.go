@@ -0,0 +1,207 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func quotaServer(calls *int) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		*calls++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/v1/text-to-speech/with-timestamps":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"audio_base64":"","duration":1}`))
+		case "/v1/text-to-speech/stream", "/v1/text-to-speech/compose", "/v1/text-to-speech":
+			if r.Header.Get("X-Fail") != "" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "audio/wav")
+			_, _ = w.Write([]byte("audio"))
+		}
+	}))
+}
+
+func newQuotaTestClient(srv *httptest.Server, guard *QuotaGuard) *Client {
+	return NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, QuotaGuard: guard})
+}
+
+func TestQuotaGuard_HardLimitFailsWithoutSending(t *testing.T) {
+	calls := 0
+	srv := quotaServer(&calls)
+	defer srv.Close()
+
+	var hardUsage QuotaUsage
+	guard := NewQuotaGuard(QuotaConfig{HardLimit: 10, OnHardLimit: func(u QuotaUsage) { hardUsage = u }})
+	c := newQuotaTestClient(srv, guard)
+	ctx := context.Background()
+
+	if _, err := c.TextToSpeech(ctx, &TTSRequest{VoiceID: "v", Text: "안녕하세요 반가", Model: ModelSSFMV30}); err != nil {
+		t.Fatalf("TextToSpeech() error = %v", err)
+	}
+	_, err := c.TextToSpeech(ctx, &TTSRequest{VoiceID: "v", Text: "over", Model: ModelSSFMV30})
+	var quotaErr *QuotaExceededError
+	if !errors.As(err, &quotaErr) || quotaErr.Used != 8 || quotaErr.Requested != 4 || quotaErr.HardLimit != 10 {
+		t.Fatalf("expected QuotaExceededError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "8 used + 4 requested > 10") {
+		t.Fatalf("unexpected message: %v", err)
+	}
+	if calls != 1 || hardUsage.Requested != 4 {
+		t.Fatalf("calls = %d, hard usage = %+v", calls, hardUsage)
+	}
+	if !guard.Usage().ResetsAt.IsZero() {
+		t.Fatal("a guard without a window must never reset")
+	}
+}
+
+func TestQuotaGuard_SoftLimitFiresOncePerWindow(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+	guard := NewQuotaGuard(QuotaConfig{Window: time.Hour, SoftLimit: 5, Clock: clock})
+	fired := 0
+	guard.config.OnSoftLimit = func(u QuotaUsage) {
+		fired++
+		if u.Used != 6 || !u.ResetsAt.Equal(time.Unix(1000, 0).Add(time.Hour)) {
+			t.Errorf("unexpected usage %+v", u)
+		}
+	}
+	ctx := context.Background()
+	for _, chars := range []int{3, 3, 3} {
+		if err := guard.reserve(ctx, chars); err != nil {
+			t.Fatalf("reserve() error = %v", err)
+		}
+	}
+	if fired != 1 {
+		t.Fatalf("soft limit fired %d times, want 1", fired)
+	}
+
+	clock.Advance(2*time.Hour + time.Minute)
+	if usage := guard.Usage(); usage.Used != 0 || !usage.ResetsAt.Equal(time.Unix(1000, 0).Add(3*time.Hour)) {
+		t.Fatalf("window did not roll: %+v", usage)
+	}
+	guard.config.OnSoftLimit = func(QuotaUsage) { fired++ }
+	_ = guard.reserve(ctx, 5)
+	if fired != 2 {
+		t.Fatalf("soft limit must fire again in a new window, fired %d", fired)
+	}
+}
+
+func TestQuotaGuard_BlocksUntilNextWindow(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := NewFakeClock(start)
+	guard := NewQuotaGuard(QuotaConfig{Window: time.Hour, HardLimit: 4, BlockOnHardLimit: true, Clock: clock})
+	ctx := context.Background()
+	if err := guard.reserve(ctx, 4); err != nil {
+		t.Fatalf("reserve() error = %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- guard.reserve(ctx, 2) }()
+	clock.WaitForTimers(1)
+	clock.Advance(time.Hour)
+	if err := <-done; err != nil {
+		t.Fatalf("reserve() error = %v", err)
+	}
+	if !clock.Now().Equal(start.Add(time.Hour)) {
+		t.Fatal("expected reserve to block until the next window")
+	}
+	if used := guard.Usage().Used; used != 2 {
+		t.Fatalf("Used = %d, want 2", used)
+	}
+
+	// A request larger than the whole budget can never fit.
+	var quotaErr *QuotaExceededError
+	if err := guard.reserve(ctx, 5); !errors.As(err, &quotaErr) {
+		t.Fatalf("expected QuotaExceededError, got %v", err)
+	}
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_ = guard.reserve(ctx, 2)
+	if err := guard.reserve(cancelCtx, 4); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestQuotaGuard_RefundsFailedRequests(t *testing.T) {
+	calls := 0
+	srv := quotaServer(&calls)
+	defer srv.Close()
+	guard := NewQuotaGuard(QuotaConfig{HardLimit: 100})
+	failing := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, QuotaGuard: guard, HTTPClient: &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			r.Header.Set("X-Fail", "1")
+			return http.DefaultTransport.RoundTrip(r)
+		}),
+	}})
+	ctx := context.Background()
+
+	if _, err := failing.TextToSpeech(ctx, &TTSRequest{VoiceID: "v", Text: "hello", Model: ModelSSFMV30}); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := failing.TextToSpeechStream(ctx, TTSRequestStream{VoiceID: "v", Text: "hello", Model: ModelSSFMV30}); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := failing.ComposeSpeech().Defaults(ComposerSettings{VoiceID: "v", Model: ModelSSFMV30}).Say("hello").Generate(ctx); err == nil {
+		t.Fatal("expected error")
+	}
+	if used := guard.Usage().Used; used != 0 {
+		t.Fatalf("failed requests must be refunded, Used = %d", used)
+	}
+
+	unreachable := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://127.0.0.1:1", QuotaGuard: guard})
+	if _, err := unreachable.TextToSpeechStream(ctx, TTSRequestStream{VoiceID: "v", Text: "hello", Model: ModelSSFMV30}); err == nil {
+		t.Fatal("expected error")
+	}
+	guard.refund(1)
+	if used := guard.Usage().Used; used != 0 {
+		t.Fatalf("Used = %d, want 0", used)
+	}
+}
+
+func TestQuotaGuard_ChargesEveryEndpoint(t *testing.T) {
+	calls := 0
+	srv := quotaServer(&calls)
+	defer srv.Close()
+	guard := NewQuotaGuard(QuotaConfig{HardLimit: 12})
+	c := newQuotaTestClient(srv, guard)
+	ctx := context.Background()
+
+	if _, err := c.TextToSpeechWithTimestamps(ctx, &TTSRequestWithTimestamps{VoiceID: "v", Text: "abc", Model: ModelSSFMV30}, ""); err != nil {
+		t.Fatalf("TextToSpeechWithTimestamps() error = %v", err)
+	}
+	stream, err := c.TextToSpeechStream(ctx, TTSRequestStream{VoiceID: "v", Text: "abc", Model: ModelSSFMV30})
+	if err != nil {
+		t.Fatalf("TextToSpeechStream() error = %v", err)
+	}
+	stream.Close()
+	if _, err := c.ComposeSpeech().Defaults(ComposerSettings{VoiceID: "v", Model: ModelSSFMV30}).Say("ab").Pause(0.5).Say("c").Generate(ctx); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if used := guard.Usage().Used; used != 9 {
+		t.Fatalf("Used = %d, want 9", used)
+	}
+
+	var quotaErr *QuotaExceededError
+	if _, err := c.TextToSpeechWithTimestamps(ctx, &TTSRequestWithTimestamps{VoiceID: "v", Text: "abcd", Model: ModelSSFMV30}, ""); !errors.As(err, &quotaErr) {
+		t.Fatalf("expected QuotaExceededError, got %v", err)
+	}
+	if _, err := c.TextToSpeechStream(ctx, TTSRequestStream{VoiceID: "v", Text: "abcd", Model: ModelSSFMV30}); !errors.As(err, &quotaErr) {
+		t.Fatalf("expected QuotaExceededError, got %v", err)
+	}
+	if _, err := c.ComposeSpeech().Defaults(ComposerSettings{VoiceID: "v", Model: ModelSSFMV30}).Say("abcd").Generate(ctx); !errors.As(err, &quotaErr) {
+		t.Fatalf("expected QuotaExceededError, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("calls = %d, want 3", calls)
+	}
+}
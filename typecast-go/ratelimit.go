@@ -0,0 +1,132 @@
+package typecast
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter decides when a request may be sent. Implementations must be
+// safe for concurrent use: a single limiter is meant to be shared by every
+// Client (or service) that draws from the same organization-wide budget.
+// Wait blocks until the caller may proceed or ctx is done.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// TokenBucketLimiter is a RateLimiter that allows PerSecond requests per
+// second on average with bursts of up to Burst requests. When requests are
+// queued, they are released in WithPriority order.
+type TokenBucketLimiter struct {
+	mu      sync.Mutex
+	clock   Clock
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+	seq     uint64
+	waiters slotWaiters
+	timer   Timer
+}
+
+// NewTokenBucketLimiter creates a limiter allowing perSecond requests per
+// second with the given burst size. A burst below 1 is treated as 1, and a
+// rate of zero or less disables limiting.
+func NewTokenBucketLimiter(perSecond float64, burst int) *TokenBucketLimiter {
+	return NewTokenBucketLimiterWithClock(perSecond, burst, SystemClock)
+}
+
+// NewTokenBucketLimiterWithClock creates a limiter like
+// NewTokenBucketLimiter that refills its tokens by clock's time, such as a
+// FakeClock's in tests. A nil clock is SystemClock.
+func NewTokenBucketLimiterWithClock(perSecond float64, burst int, clock Clock) *TokenBucketLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	clock = clockOrSystem(clock)
+	return &TokenBucketLimiter{
+		clock:  clock,
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   clock.Now(),
+	}
+}
+
+// Wait blocks until a request may be sent.
+func (l *TokenBucketLimiter) Wait(ctx context.Context) error {
+	if l.rate <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	l.refill()
+	if len(l.waiters) == 0 && l.tokens >= 1 {
+		l.tokens--
+		l.mu.Unlock()
+		return nil
+	}
+	l.seq++
+	w := &slotWaiter{priority: PriorityFromContext(ctx), seq: l.seq, ready: make(chan struct{})}
+	heap.Push(&l.waiters, w)
+	l.schedule()
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w.index >= 0 {
+		heap.Remove(&l.waiters, w.index)
+	} else {
+		// The token was granted while the context ended; give it back.
+		l.tokens = math.Min(l.burst, l.tokens+1)
+	}
+	return ctx.Err()
+}
+
+func (l *TokenBucketLimiter) refill() {
+	now := l.clock.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+}
+
+// schedule arms a timer for when the next token becomes available.
+func (l *TokenBucketLimiter) schedule() {
+	if l.timer != nil {
+		return
+	}
+	wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	l.timer = l.clock.AfterFunc(wait, l.dispatch)
+}
+
+func (l *TokenBucketLimiter) dispatch() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.timer = nil
+	l.refill()
+	for len(l.waiters) > 0 && l.tokens >= 1 {
+		w := heap.Pop(&l.waiters).(*slotWaiter)
+		l.tokens--
+		close(w.ready)
+	}
+	if len(l.waiters) > 0 {
+		l.schedule()
+	}
+}
+
+// waitForRateLimit consults the configured RateLimiter, if any.
+func (c *Client) waitForRateLimit(ctx context.Context) error {
+	if c.rateLimiter == nil {
+		return nil
+	}
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("waiting for rate limiter: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package typecast
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitState is the rate limit the API reported on a response.
+type RateLimitState struct {
+	// Method and Path identify the request that was answered
+	Method string
+	Path   string
+	// Limit is the number of requests allowed per window, or -1 if the
+	// response did not report it
+	Limit int
+	// Remaining is the number of requests left in the window, or -1 if the
+	// response did not report it
+	Remaining int
+	// Reset is when the window resets by the local clock; it is zero if the
+	// response did not report it. A reset given as a timestamp is corrected
+	// for ClockSkew.
+	Reset time.Time
+	// Limited reports a 429 Too Many Requests response
+	Limited bool
+	// RetryAfter is the wait requested by a 429 response's Retry-After
+	// header, if any
+	RetryAfter time.Duration
+	// ServerDate is the response's Date header, if any
+	ServerDate time.Time
+	// Tags are the tags added with WithTag to the request, if any
+	Tags []string
+}
+
+// observeRateLimit reports the rate limit state of resp to the OnRateLimit
+// callback when resp carries rate limit headers or is a 429.
+func (c *Client) observeRateLimit(req *http.Request, resp *http.Response) {
+	if c.onRateLimit == nil || resp == nil {
+		return
+	}
+	now := c.clock.Now()
+	date, hasDate := serverDate(resp.Header)
+	state := RateLimitState{
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		Limit:      rateLimitHeader(resp.Header, "Limit"),
+		Remaining:  rateLimitHeader(resp.Header, "Remaining"),
+		Limited:    resp.StatusCode == http.StatusTooManyRequests,
+		ServerDate: date,
+		Tags:       TagsFromContext(req.Context()),
+	}
+	if reset := rateLimitHeaderValue(resp.Header, "Reset"); reset != "" {
+		if seconds, err := strconv.ParseFloat(reset, 64); err == nil && seconds >= 0 {
+			if seconds > 1e9 {
+				// An epoch timestamp rather than seconds from now, by the
+				// server's clock.
+				state.Reset = time.Unix(0, int64(seconds*float64(time.Second)))
+				if hasDate {
+					state.Reset = state.Reset.Add(now.Sub(date))
+				}
+			} else {
+				state.Reset = now.Add(time.Duration(math.Round(seconds * float64(time.Second))))
+			}
+		}
+	}
+	if state.Limited {
+		state.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), c.serverNow(resp.Header))
+	}
+	if state.Limit < 0 && state.Remaining < 0 && state.Reset.IsZero() && !state.Limited {
+		return
+	}
+	c.onRateLimit(state)
+}
+
+// rateLimitHeader returns the integer value of the X-RateLimit-<name> or
+// RateLimit-<name> header, or -1.
+func rateLimitHeader(header http.Header, name string) int {
+	n, err := strconv.Atoi(rateLimitHeaderValue(header, name))
+	if err != nil || n < 0 {
+		return -1
+	}
+	return n
+}
+
+func rateLimitHeaderValue(header http.Header, name string) string {
+	if v := header.Get("X-RateLimit-" + name); v != "" {
+		return v
+	}
+	return header.Get("RateLimit-" + name)
+}
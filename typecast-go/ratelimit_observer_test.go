@@ -0,0 +1,79 @@
+package typecast
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOnRateLimit(t *testing.T) {
+	responses := []func(w http.ResponseWriter){
+		func(w http.ResponseWriter) {
+			w.Header().Set("X-RateLimit-Limit", "100")
+			w.Header().Set("X-RateLimit-Remaining", "42")
+			w.Header().Set("X-RateLimit-Reset", "30")
+		},
+		func(w http.ResponseWriter) {
+			w.Header().Set("RateLimit-Remaining", "0")
+			w.Header().Set("RateLimit-Reset", "2000000000")
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		},
+		func(w http.ResponseWriter) {
+			w.Header().Set("X-RateLimit-Limit", "many")
+			w.Header().Set("X-RateLimit-Reset", "soon")
+		},
+		func(w http.ResponseWriter) {},
+	}
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		responses[calls](w)
+		calls++
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	var states []RateLimitState
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, OnRateLimit: func(s RateLimitState) { states = append(states, s) }})
+	for range responses {
+		_, _ = c.GetMySubscription(context.Background())
+	}
+	if len(states) != 2 {
+		t.Fatalf("expected 2 observations, got %+v", states)
+	}
+
+	first := states[0]
+	if first.Method != http.MethodGet || first.Path != "/v1/users/me/subscription" || first.Limit != 100 || first.Remaining != 42 || first.Limited {
+		t.Fatalf("unexpected state %+v", first)
+	}
+	if until := time.Until(first.Reset); until < 29*time.Second || until > 30*time.Second {
+		t.Fatalf("unexpected reset in %v", until)
+	}
+	second := states[1]
+	skew := second.Reset.Sub(time.Unix(2000000000, 0))
+	if second.Limit != -1 || second.Remaining != 0 || !second.Limited || second.RetryAfter != 7*time.Second || skew < -2*time.Second || skew > 2*time.Second {
+		t.Fatalf("unexpected state %+v", second)
+	}
+}
+
+func TestOnRateLimit_EachAttempt(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+	limited := 0
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, MaxRetries: 2, OnRateLimit: func(s RateLimitState) {
+		if s.Limited && s.RetryAfter == 0 && s.Remaining == -1 {
+			limited++
+		}
+	}})
+	c.retryBaseDelay = time.Millisecond
+	if _, err := c.GetMySubscription(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if limited != 3 {
+		t.Fatalf("expected 3 observed 429s, got %d", limited)
+	}
+}
@@ -0,0 +1,169 @@
+package typecast
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func waitForLimiterWaiters(t *testing.T, l *TokenBucketLimiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		l.mu.Lock()
+		got := len(l.waiters)
+		l.mu.Unlock()
+		if got == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d limiter waiters", n)
+}
+
+func TestTokenBucketLimiter_PacesRequests(t *testing.T) {
+	l := NewTokenBucketLimiter(100, 0)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	// One burst token, then four more at 10ms intervals.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Fatalf("expected requests to be paced, took %v", elapsed)
+	}
+}
+
+func TestTokenBucketLimiter_DisabledForNonPositiveRate(t *testing.T) {
+	l := NewTokenBucketLimiter(0, 1)
+	for i := 0; i < 100; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+}
+
+func TestTokenBucketLimiter_ReleasesByPriority(t *testing.T) {
+	l := NewTokenBucketLimiter(20, 1)
+	_ = l.Wait(context.Background())
+
+	var mu sync.Mutex
+	var order []Priority
+	var wg sync.WaitGroup
+	for i, p := range []Priority{PriorityLow, PriorityNormal, PriorityHigh} {
+		p := p
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.Wait(WithPriority(context.Background(), p)); err != nil {
+				t.Errorf("Wait() error = %v", err)
+				return
+			}
+			mu.Lock()
+			order = append(order, p)
+			mu.Unlock()
+		}()
+		waitForLimiterWaiters(t, l, i+1)
+	}
+	wg.Wait()
+	want := []Priority{PriorityHigh, PriorityNormal, PriorityLow}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("release order = %v, want %v", order, want)
+	}
+}
+
+func TestTokenBucketLimiter_CanceledWaiterLeavesQueue(t *testing.T) {
+	l := NewTokenBucketLimiter(0.001, 1)
+	_ = l.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+	if len(l.waiters) != 0 {
+		t.Fatalf("expected empty queue, got %d", len(l.waiters))
+	}
+}
+
+func TestTokenBucketLimiter_TokenGrantedAfterCancelIsReturned(t *testing.T) {
+	l := NewTokenBucketLimiter(0.001, 1)
+	_ = l.Wait(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- l.Wait(ctx) }()
+	waitForLimiterWaiters(t, l, 1)
+
+	l.mu.Lock()
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	w := heap.Pop(&l.waiters).(*slotWaiter)
+	close(w.ready)
+	l.tokens = 0
+	l.mu.Unlock()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if l.tokens != 1 {
+		t.Fatalf("expected token to be returned, tokens = %v", l.tokens)
+	}
+}
+
+func TestClient_SharedRateLimiter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"plan":"free"}`))
+	}))
+	defer srv.Close()
+
+	limiter := NewTokenBucketLimiter(0.001, 1)
+	a := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, RateLimiter: limiter})
+	b := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, RateLimiter: limiter})
+	if _, err := a.GetMySubscription(context.Background()); err != nil {
+		t.Fatalf("first request error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := b.GetMySubscription(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "waiting for rate limiter") {
+		t.Fatalf("expected second client to share the budget, got %v", err)
+	}
+}
+
+func TestTokenBucketLimiter_UsesClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	l := NewTokenBucketLimiterWithClock(1, 2, clock)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { done <- l.Wait(ctx) }()
+	}
+	waitForLimiterWaiters(t, l, 2)
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	select {
+	case <-done:
+		t.Fatal("the second waiter must wait another second")
+	default:
+	}
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+}
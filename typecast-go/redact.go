@@ -0,0 +1,107 @@
+package typecast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Redactor renders the text of a request, such as the input of a
+// synthesis, where it must not appear verbatim: DebugCurl logs, HAR dumps,
+// and error messages. Input text often holds customer names and other
+// personal data that should not reach observability systems.
+type Redactor func(text string) string
+
+// RedactHash replaces text with its length and a short SHA-256, so logs
+// can still tell whether two requests had the same text. It is the default
+// Redactor.
+func RedactHash(text string) string {
+	return fmt.Sprintf("[redacted %d chars sha256:%s]", utf8.RuneCountInString(text), sha256Hex([]byte(text))[:12])
+}
+
+// RedactTruncate returns a Redactor that keeps the first n characters of
+// text, marking a cut with an ellipsis.
+func RedactTruncate(n int) Redactor {
+	return func(text string) string { return excerpt(text, n) }
+}
+
+// textRedactor returns the Redactor for text in logs and errors, or nil
+// when LogText shows it verbatim.
+func (c *Client) textRedactor() Redactor {
+	if c.logText {
+		return nil
+	}
+	return redactorOrDefault(c.redactText)
+}
+
+func redactorOrDefault(r Redactor) Redactor {
+	if r == nil {
+		return RedactHash
+	}
+	return r
+}
+
+// redactJSONText rewrites the JSON in data with redact applied to the
+// string values of every "text" member, at any depth, including the
+// strings in an array of texts, keeping the order of the members. Data
+// that is cut short, or that is not JSON, is rewritten up to where it
+// stops being valid, so no partial text survives.
+func redactJSONText(data []byte, redact Redactor) []byte {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out bytes.Buffer
+	// Each open container is an object ('{') or array ('['), with the
+	// number of values written and, for objects, the last key. An array
+	// under a "text" member holds texts.
+	type container struct {
+		object bool
+		count  int
+		key    string
+		text   bool
+	}
+	var stack []*container
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return out.Bytes()
+		}
+		var top *container
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			out.WriteByte(byte(delim))
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		// A key is written with its colon; the value after it needs no
+		// comma.
+		isKey := top != nil && top.object && top.count%2 == 0
+		isText := top != nil && !isKey && (top.object && top.key == "text" || !top.object && top.text)
+		if top != nil {
+			if top.count > 0 && (!top.object || isKey) {
+				out.WriteByte(',')
+			}
+			top.count++
+		}
+		switch v := token.(type) {
+		case json.Delim:
+			out.WriteByte(byte(v))
+			stack = append(stack, &container{object: v == '{', text: v == '[' && isText})
+		case string:
+			if isText {
+				v = redact(v)
+			}
+			encoded, _ := json.Marshal(v)
+			out.Write(encoded)
+			if isKey {
+				top.key = v
+				out.WriteByte(':')
+			}
+		default:
+			encoded, _ := json.Marshal(v)
+			out.Write(encoded)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedactJSONText(t *testing.T) {
+	upper := Redactor(strings.ToUpper)
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{`{"voice_id":"tc_1","text":"Jane Doe","model":"ssfm-v30","seed":7}`, `{"voice_id":"tc_1","text":"JANE DOE","model":"ssfm-v30","seed":7}`},
+		{`{"segments":[{"type":"tts","text":"a"},{"type":"pause","duration_seconds":1.5}],"text":null}`, `{"segments":[{"type":"tts","text":"A"},{"type":"pause","duration_seconds":1.5}],"text":null}`},
+		{`{"words":[{"text":"hi","start":0}],"characters":[],"ok":true}`, `{"words":[{"text":"HI","start":0}],"characters":[],"ok":true}`},
+		{`{"text":["a",["b"],{"text":"c","id":"d"},1],"id":"e"}`, `{"text":["A",["B"],{"text":"C","id":"d"},1],"id":"e"}`},
+		{`{"context":"text","note":{"text":"<b>"}}`, `{"context":"text","note":{"text":"\u003cB\u003e"}}`},
+		// A body cut short drops the partial text.
+		{`{"voice_id":"tc_1","text":"Jane Do`, `{"voice_id":"tc_1","text":`},
+		{`not json`, ``},
+	} {
+		if got := string(redactJSONText([]byte(tc.in), upper)); got != tc.want {
+			t.Errorf("redactJSONText(%s) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+	if got := RedactHash("Jane"); got != "[redacted 4 chars sha256:"+sha256Hex([]byte("Jane"))[:12]+"]" {
+		t.Fatalf("RedactHash = %s", got)
+	}
+	if got := RedactTruncate(2)("Jane"); got != "Ja…" {
+		t.Fatalf("RedactTruncate = %s", got)
+	}
+}
+
+func TestRedaction_CurlAndErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"audio":"","words":[{"text":"Jane","start":"0"}]}`)
+	}))
+	defer srv.Close()
+	logger := &recordingLogger{}
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Logger: logger, DebugCurl: true})
+	request := &TTSRequestWithTimestamps{VoiceID: "tc_1", Text: "Hello, Jane", Model: ModelSSFMV30}
+
+	_, err := c.TextToSpeechWithTimestamps(context.Background(), request, "")
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || strings.Contains(err.Error(), "Jane") || !strings.Contains(decodeErr.Snippet, "[redacted ") {
+		t.Fatalf("expected a redacted decode error, got %v", err)
+	}
+	if len(logger.lines) != 1 || strings.Contains(logger.lines[0], "Jane") || !strings.Contains(logger.lines[0], `"text":"[redacted 11 chars sha256:`) {
+		t.Fatalf("expected a redacted curl command, got %q", logger.lines)
+	}
+
+	// A custom Redactor replaces the default, and LogText disables it.
+	logger.lines = nil
+	c = NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Logger: logger, DebugCurl: true, RedactText: RedactTruncate(3)})
+	_, _ = c.TextToSpeechWithTimestamps(context.Background(), request, "")
+	if len(logger.lines) != 1 || !strings.Contains(logger.lines[0], `"text":"Hel…"`) {
+		t.Fatalf("expected a truncated text, got %q", logger.lines)
+	}
+	c = NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, LogText: true})
+	if _, err := c.TextToSpeechWithTimestamps(context.Background(), request, ""); err == nil || !strings.Contains(err.Error(), "Jane") {
+		t.Fatalf("expected the verbatim snippet, got %v", err)
+	}
+}
+
+func TestRedaction_HAR(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"audio":"","audio_format":"wav","audio_duration":1,"words":[{"text":"Jane","start":0,"end":1}],"characters":[]}`)
+	}))
+	defer srv.Close()
+	recorder := &HARRecorder{}
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, HTTPClient: &http.Client{Transport: recorder}})
+	if _, err := c.TextToSpeechWithTimestamps(context.Background(), &TTSRequestWithTimestamps{VoiceID: "tc_1", Text: "Hello, Jane", Model: ModelSSFMV30}, ""); err != nil {
+		t.Fatal(err)
+	}
+	data, err := recorder.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "Jane") || strings.Count(string(data), "[redacted ") != 2 {
+		t.Fatalf("expected the text to be redacted, got %s", data)
+	}
+}
@@ -0,0 +1,436 @@
+package typecast
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultResponseCacheEntries is the number of responses a ResponseCache
+// keeps when MaxEntries is not set.
+const defaultResponseCacheEntries = 256
+
+// revalidationTimeout bounds a background refresh for
+// StaleWhileRevalidate, since the request that started it does not.
+const revalidationTimeout = 30 * time.Second
+
+// stalenessBuckets are the upper bounds, in seconds, of the histogram of how
+// stale the responses served were.
+var stalenessBuckets = []float64{1, 5, 15, 30, 60, 300, 900, 3600, 6 * 3600, 24 * 3600}
+
+// ResponseCacheConfig configures a ResponseCache.
+type ResponseCacheConfig struct {
+	// TTL is how long a response without Cache-Control max-age or Expires
+	// is served (optional, 0 only caches responses the API marks fresh)
+	TTL time.Duration
+	// MaxEntries is the number of responses kept, evicting the least
+	// recently used (optional, defaults to 256)
+	MaxEntries int
+	// StaleIfError is how long past its freshness a response is still
+	// served, marked stale, when the API cannot be reached or answers with
+	// a 5xx, unless the response sets Cache-Control stale-if-error
+	// (optional, 0 fails instead)
+	StaleIfError time.Duration
+	// StaleWhileRevalidate is how long past its freshness a response is
+	// still served at once, marked stale, while it is refreshed in the
+	// background, unless the response sets Cache-Control
+	// stale-while-revalidate (optional, 0 refreshes before answering)
+	StaleWhileRevalidate time.Duration
+}
+
+// ResponseCache caches the responses of the API's metadata endpoints, such
+// as the voice catalog and the subscription, so repeated lookups do not
+// cost a request. A response is served for as long as the API allows with
+// Cache-Control max-age or Expires, and for TTL only when it sends neither,
+// so the cache follows the server once it signals freshness. Responses
+// marked no-store or no-cache are not cached, and conditional requests
+// bypass the cache. With StaleIfError, the last good response keeps being
+// served during an outage, so voice pickers keep working; it carries a
+// Warning: 110 header, and the results of the conditional voice methods
+// are marked Stale. With StaleWhileRevalidate, an expired response is served
+// at once while a single background request per response refreshes it, so
+// metadata lookups never wait on the API under load. Stats reports the
+// hits, misses, and how stale the responses served were. Set it as
+// ClientConfig.ResponseCache; clients with different API keys can share
+// one, as each key has its own entries. It is safe for concurrent use.
+type ResponseCache struct {
+	ttl                  time.Duration
+	maxEntries           int
+	staleIfError         time.Duration
+	staleWhileRevalidate time.Duration
+
+	mu           sync.Mutex
+	order        *list.List
+	entries      map[string]*list.Element
+	revalidating map[string]bool
+	stats        ResponseCacheStats
+}
+
+// ResponseCacheStats are the counters of a ResponseCache.
+type ResponseCacheStats struct {
+	// Hits is the number of requests answered with a fresh response
+	Hits int `json:"hits"`
+	// StaleHits is the number of requests answered with a stale response,
+	// for StaleWhileRevalidate or StaleIfError
+	StaleHits int `json:"stale_hits"`
+	// Misses is the number of requests sent to the API
+	Misses int `json:"misses"`
+	// Revalidations is the number of background refreshes started
+	Revalidations int `json:"revalidations"`
+	// RevalidationErrors is the number of background refreshes that failed
+	RevalidationErrors int `json:"revalidation_errors"`
+	// Staleness is how long past their freshness the stale responses
+	// served were, in seconds
+	Staleness Histogram `json:"staleness"`
+}
+
+type responseCacheEntry struct {
+	key             string
+	status          int
+	header          http.Header
+	body            []byte
+	expires         time.Time
+	staleUntil      time.Time
+	revalidateUntil time.Time
+}
+
+// NewResponseCache returns an empty cache.
+func NewResponseCache(config ResponseCacheConfig) *ResponseCache {
+	maxEntries := config.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultResponseCacheEntries
+	}
+	return &ResponseCache{
+		ttl:                  config.TTL,
+		maxEntries:           maxEntries,
+		staleIfError:         config.StaleIfError,
+		staleWhileRevalidate: config.StaleWhileRevalidate,
+		order:                list.New(),
+		entries:              map[string]*list.Element{},
+		revalidating:         map[string]bool{},
+		stats:                ResponseCacheStats{Staleness: Histogram{Bounds: stalenessBuckets, Counts: make([]int, len(stalenessBuckets)+1)}},
+	}
+}
+
+// Stats returns the cache's counters.
+func (rc *ResponseCache) Stats() ResponseCacheStats {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	stats := rc.stats
+	stats.Staleness = stats.Staleness.clone()
+	return stats
+}
+
+// record calls fn with the counters under the lock.
+func (rc *ResponseCache) record(fn func(*ResponseCacheStats)) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	fn(&rc.stats)
+}
+
+// Len returns the number of responses cached, including expired ones not
+// yet evicted.
+func (rc *ResponseCache) Len() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.order.Len()
+}
+
+// Clear removes every response.
+func (rc *ResponseCache) Clear() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.order.Init()
+	rc.entries = map[string]*list.Element{}
+}
+
+// get returns the response for key at now, if it may still be served
+// fresh or stale, and whether it is fresh.
+func (rc *ResponseCache) get(key string, now time.Time) (*responseCacheEntry, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	e, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*responseCacheEntry)
+	if !now.Before(entry.expires) && !now.Before(entry.staleUntil) && !now.Before(entry.revalidateUntil) {
+		rc.order.Remove(e)
+		delete(rc.entries, key)
+		return nil, false
+	}
+	rc.order.MoveToFront(e)
+	return entry, now.Before(entry.expires)
+}
+
+func (rc *ResponseCache) put(entry *responseCacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if e, ok := rc.entries[entry.key]; ok {
+		e.Value = entry
+		rc.order.MoveToFront(e)
+		return
+	}
+	rc.entries[entry.key] = rc.order.PushFront(entry)
+	if rc.order.Len() > rc.maxEntries {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.entries, oldest.Value.(*responseCacheEntry).key)
+	}
+}
+
+// freshFor returns how long a response with header, received at now, may
+// be served: its Cache-Control max-age or Expires, less its Age, or the TTL
+// without either. It returns 0 when the response may not be cached.
+func (rc *ResponseCache) freshFor(header http.Header, now time.Time) time.Duration {
+	directives := cacheControl(header)
+	if _, ok := directives["no-store"]; ok {
+		return 0
+	}
+	if _, ok := directives["no-cache"]; ok {
+		return 0
+	}
+	lifetime := rc.ttl
+	maxAge, explicit := directives["max-age"]
+	if explicit {
+		seconds, err := strconv.ParseInt(maxAge, 10, 64)
+		if err != nil {
+			return 0
+		}
+		lifetime = time.Duration(seconds) * time.Second
+	}
+	if expires := header.Get("Expires"); expires != "" && !explicit {
+		// An invalid Expires, such as 0, means already expired.
+		at, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+		date, ok := serverDate(header)
+		if !ok {
+			date = now
+		}
+		lifetime = at.Sub(date)
+	}
+	if age, err := strconv.ParseInt(header.Get("Age"), 10, 64); err == nil {
+		lifetime -= time.Duration(age) * time.Second
+	}
+	if lifetime < 0 {
+		return 0
+	}
+	return lifetime
+}
+
+// staleFor returns how long past its freshness a response with header may
+// be served when the API fails or while it is refreshed: its Cache-Control
+// directive, or else fallback.
+func staleFor(header http.Header, directive string, fallback time.Duration) time.Duration {
+	if value, ok := cacheControl(header)[directive]; ok {
+		seconds, _ := strconv.ParseInt(value, 10, 64)
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
+
+// beginRevalidation reports whether a background refresh of key may start,
+// as none is running.
+func (rc *ResponseCache) beginRevalidation(key string) bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.revalidating[key] {
+		return false
+	}
+	rc.revalidating[key] = true
+	rc.stats.Revalidations++
+	return true
+}
+
+// endRevalidation records the end of the background refresh of key.
+func (rc *ResponseCache) endRevalidation(key string, failed bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	delete(rc.revalidating, key)
+	if failed {
+		rc.stats.RevalidationErrors++
+	}
+}
+
+// cacheControl returns the directives of the Cache-Control headers of
+// header by lowercase name, with their unquoted values.
+func cacheControl(header http.Header) map[string]string {
+	directives := map[string]string{}
+	for _, directive := range strings.Split(strings.Join(header.Values("Cache-Control"), ","), ",") {
+		name, value := strings.TrimSpace(directive), ""
+		if i := strings.IndexByte(name, '='); i >= 0 {
+			name, value = name[:i], strings.Trim(name[i+1:], `"`)
+		}
+		directives[strings.ToLower(name)] = value
+	}
+	return directives
+}
+
+// response returns the cached response as a response to req.
+func (e *responseCacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.status, http.StatusText(e.status)),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// sendCached sends the metadata request req through the ResponseCache.
+// Conditional requests are always sent, but fall back to a stale response
+// within its stale-if-error window too.
+func (c *Client) sendCached(req *http.Request) (*http.Response, error) {
+	conditional := req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+	key := responseCacheKey(req)
+	now := c.clock.Now()
+	entry, fresh := c.responseCache.get(key, now)
+	if fresh && !conditional {
+		c.responseCache.record(func(stats *ResponseCacheStats) { stats.Hits++ })
+		return entry.response(req), nil
+	}
+	if entry != nil && !conditional && now.Before(entry.revalidateUntil) {
+		c.revalidate(req, key)
+		return c.staleResponse(req, entry, now), nil
+	}
+	c.responseCache.record(func(stats *ResponseCacheStats) { stats.Misses++ })
+	resp, err := c.send(req)
+	if entry != nil && now.Before(entry.staleUntil) && req.Context().Err() == nil && (err != nil || resp.StatusCode >= 500) {
+		return c.serveStale(req, entry, resp, err), nil
+	}
+	if conditional {
+		return resp, err
+	}
+	return c.store(key, resp, err)
+}
+
+// store caches resp, the response to a request for key, if it is a
+// cacheable response.
+func (c *Client) store(key string, resp *http.Response, err error) (*http.Response, error) {
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	now := c.clock.Now()
+	lifetime := c.responseCache.freshFor(resp.Header, now)
+	if lifetime <= 0 {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	expires := now.Add(lifetime)
+	c.responseCache.put(&responseCacheEntry{
+		key:             key,
+		status:          resp.StatusCode,
+		header:          resp.Header.Clone(),
+		body:            body,
+		expires:         expires,
+		staleUntil:      expires.Add(staleFor(resp.Header, "stale-if-error", c.responseCache.staleIfError)),
+		revalidateUntil: expires.Add(staleFor(resp.Header, "stale-while-revalidate", c.responseCache.staleWhileRevalidate)),
+	})
+	return resp, nil
+}
+
+// startRevalidations lets the client refresh stale responses in the
+// background until Close, which cancels the refreshes and waits for them.
+func (c *Client) startRevalidations() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.revalidationCtx = ctx
+	c.onClose(func() {
+		cancel()
+		c.revalidations.Wait()
+	})
+}
+
+// revalidate refreshes the response to req in the background, unless a
+// refresh is running already. The refresh keeps req's context values but
+// not its cancellation, as req is answered before it ends; Close cancels
+// it instead.
+func (c *Client) revalidate(req *http.Request, key string) {
+	// The closed check and Add share the lifecycle lock, so Close waits
+	// for every refresh it lets start.
+	c.lifecycle.Lock()
+	defer c.lifecycle.Unlock()
+	if c.closed || !c.responseCache.beginRevalidation(key) {
+		return
+	}
+	c.revalidations.Add(1)
+	go func() {
+		defer c.revalidations.Done()
+		ctx, cancel := context.WithTimeout(detachedContext{req.Context()}, revalidationTimeout)
+		defer cancel()
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-c.revalidationCtx.Done():
+				cancel()
+			case <-done:
+			}
+		}()
+		resp, err := c.send(req.Clone(ctx))
+		resp, err = c.store(key, resp, err)
+		if err == nil {
+			if resp.StatusCode != http.StatusOK {
+				err = fmt.Errorf("status %d", resp.StatusCode)
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		c.responseCache.endRevalidation(key, err != nil)
+		if err != nil && c.logger != nil {
+			c.logger.Printf("level=warn msg=%q path=%s error=%q", "typecast: response revalidation failed", req.URL.Path, err.Error())
+		}
+	}()
+}
+
+// serveStale answers req with the stale entry after the API failed with
+// resp or err, logging the failure.
+func (c *Client) serveStale(req *http.Request, entry *responseCacheEntry, resp *http.Response, err error) *http.Response {
+	if resp != nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		err = fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if c.logger != nil {
+		c.logger.Printf("level=warn msg=%q path=%s error=%q", "typecast: serving stale response", req.URL.Path, err.Error())
+	}
+	return c.staleResponse(req, entry, c.clock.Now())
+}
+
+// staleResponse returns the stale entry as a response to req at now,
+// recording how stale it is.
+func (c *Client) staleResponse(req *http.Request, entry *responseCacheEntry, now time.Time) *http.Response {
+	c.responseCache.record(func(stats *ResponseCacheStats) {
+		stats.StaleHits++
+		stats.Staleness.observe(now.Sub(entry.expires).Seconds())
+	})
+	stale := entry.response(req)
+	stale.Header.Set("Warning", `110 - "Response is Stale"`)
+	return stale
+}
+
+// responseCacheKey identifies the response to req: its URL and a hash of
+// its API key, so clients sharing a cache do not see each other's
+// responses.
+func responseCacheKey(req *http.Request) string {
+	apiKey := sha256.Sum256([]byte(req.Header.Get("X-Api-Key")))
+	return fmt.Sprintf("%x %s", apiKey[:8], req.URL.String())
+}
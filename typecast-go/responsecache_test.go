@@ -0,0 +1,345 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResponseCache_FreshFor(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	date := now.Add(-time.Hour).Format(http.TimeFormat)
+	rc := NewResponseCache(ResponseCacheConfig{TTL: time.Minute})
+	for _, tc := range []struct {
+		header http.Header
+		want   time.Duration
+	}{
+		{http.Header{}, time.Minute},
+		{http.Header{"Cache-Control": {"public, max-age=300"}}, 5 * time.Minute},
+		{http.Header{"Cache-Control": {`max-age="300"`}, "Age": {"100"}}, 200 * time.Second},
+		{http.Header{"Cache-Control": {"max-age=0"}}, 0},
+		{http.Header{"Cache-Control": {"max-age=soon"}}, 0},
+		{http.Header{"Cache-Control": {"private", "No-Store"}}, 0},
+		{http.Header{"Cache-Control": {"no-cache"}}, 0},
+		{http.Header{"Cache-Control": {"max-age=10"}, "Expires": {now.Add(time.Hour).Format(http.TimeFormat)}}, 10 * time.Second},
+		{http.Header{"Expires": {now.Add(30 * time.Second).Format(http.TimeFormat)}}, 30 * time.Second},
+		// Expires is measured against the response's Date.
+		{http.Header{"Date": {date}, "Expires": {now.Add(-30 * time.Minute).Format(http.TimeFormat)}}, 30 * time.Minute},
+		{http.Header{"Expires": {"0"}}, 0},
+		{http.Header{"Age": {"120"}}, 0},
+	} {
+		if got := rc.freshFor(tc.header, now); got != tc.want {
+			t.Errorf("freshFor(%v) = %v, want %v", tc.header, got, tc.want)
+		}
+	}
+	if got := NewResponseCache(ResponseCacheConfig{}).freshFor(http.Header{}, now); got != 0 {
+		t.Errorf("expected no caching without a TTL, got %v", got)
+	}
+}
+
+func TestResponseCache_ServesFreshResponses(t *testing.T) {
+	var calls int32
+	var cacheControl string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		if strings.HasPrefix(r.URL.Path, "/v2/voices/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"detail":"not found"}`)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/v2/voices/") {
+			fmt.Fprintf(w, `{"voice_id":%q,"voice_name":"Olivia"}`, strings.TrimPrefix(r.URL.Path, "/v2/voices/"))
+			return
+		}
+		fmt.Fprintf(w, `{"plan":"free","credits":{"plan_credits":%d}}`, atomic.LoadInt32(&calls))
+	}))
+	defer srv.Close()
+	clock := NewFakeClock(time.Unix(0, 0))
+	cache := NewResponseCache(ResponseCacheConfig{TTL: time.Minute, MaxEntries: 2})
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Clock: clock, ResponseCache: cache})
+	ctx := context.Background()
+
+	first, err := c.GetMySubscription(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.GetMySubscription(ctx)
+	if err != nil || second.Credits != first.Credits || calls != 1 {
+		t.Fatalf("expected a cached response, got %+v, %v after %d calls", second, err, calls)
+	}
+	clock.Advance(time.Minute)
+	if _, err := c.GetMySubscription(ctx); err != nil || calls != 2 {
+		t.Fatalf("expected the TTL to expire, %d calls, %v", calls, err)
+	}
+
+	// The API's max-age overrides the TTL.
+	cacheControl = "max-age=3600"
+	if _, err := c.GetVoiceV2(ctx, "tc_1"); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(30 * time.Minute)
+	if voice, err := c.GetVoiceV2(ctx, "tc_1"); err != nil || voice.VoiceName != "Olivia" || calls != 3 {
+		t.Fatalf("expected max-age to keep the voice, %d calls, %v", calls, err)
+	}
+
+	// Conditional requests, uncacheable responses, and errors bypass it.
+	if _, err := c.GetVoiceV2Conditional(ctx, "tc_1", Validators{ETag: `"v1"`}); err != nil || calls != 4 {
+		t.Fatalf("expected a conditional request to be sent, %d calls, %v", calls, err)
+	}
+	cacheControl = "no-store"
+	_, _ = c.GetVoiceV2(ctx, "tc_2")
+	_, _ = c.GetVoiceV2(ctx, "tc_2")
+	if calls != 6 {
+		t.Fatalf("expected no-store responses to be sent again, %d calls", calls)
+	}
+	cacheControl = ""
+	for i := 0; i < 2; i++ {
+		var apiErr *APIError
+		if _, err := c.GetVoiceV2(ctx, "missing"); !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+			t.Fatalf("expected a 404, got %v", err)
+		}
+	}
+	if calls != 8 {
+		t.Fatalf("expected errors not to be cached, %d calls", calls)
+	}
+
+	// Another API key has its own entries, and the oldest entry is evicted.
+	other := NewClient(&ClientConfig{APIKey: "other", BaseURL: srv.URL, Clock: clock, ResponseCache: cache})
+	if _, err := other.GetVoiceV2(ctx, "tc_1"); err != nil || calls != 9 || cache.Len() != 2 {
+		t.Fatalf("expected another key to miss, %d calls, %d entries, %v", calls, cache.Len(), err)
+	}
+	cache.Clear()
+	if cache.Len() != 0 {
+		t.Fatalf("expected an empty cache, got %d entries", cache.Len())
+	}
+}
+
+func TestResponseCache_ReplacesAndReadErrors(t *testing.T) {
+	var body io.Reader
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Cache-Control": {"max-age=60"}}, Body: io.NopCloser(body), Request: r}, nil
+	})
+	cache := NewResponseCache(ResponseCacheConfig{})
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://example.invalid", HTTPClient: &http.Client{Transport: rt}, ResponseCache: cache})
+
+	body = io.MultiReader(strings.NewReader(`{"plan":`), errReader{})
+	if _, err := c.GetMySubscription(context.Background()); err == nil || !strings.Contains(err.Error(), "failed to read response: read boom") {
+		t.Fatalf("expected the read error, got %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Fatalf("expected nothing cached, got %d entries", cache.Len())
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.invalid/v1/users/me/subscription", nil)
+	req.Header.Set("X-API-KEY", "k")
+	key := responseCacheKey(req)
+	cache.put(&responseCacheEntry{key: key, status: http.StatusOK, header: http.Header{}, body: []byte(`{"plan":"stale"}`)})
+	cache.put(&responseCacheEntry{key: key, status: http.StatusOK, header: http.Header{}, body: []byte(`{"plan":"pro"}`), expires: time.Now().Add(time.Hour)})
+	if sub, err := c.GetMySubscription(context.Background()); err != nil || sub.Plan != "pro" || cache.Len() != 1 {
+		t.Fatalf("expected the replaced entry, got %+v, %v", sub, err)
+	}
+}
+
+func TestResponseCache_StaleIfError(t *testing.T) {
+	var status int32 = http.StatusOK
+	cacheControl := "max-age=60"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s := int(atomic.LoadInt32(&status)); s != http.StatusOK {
+			w.WriteHeader(s)
+			return
+		}
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `[{"voice_id":"tc_1","voice_name":"Olivia"}]`)
+	}))
+	defer srv.Close()
+	clock := NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	logger := &recordingLogger{}
+	cache := NewResponseCache(ResponseCacheConfig{StaleIfError: time.Hour})
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Clock: clock, Logger: logger, ResponseCache: cache})
+	ctx := context.Background()
+
+	if result, err := c.GetVoicesV2Conditional(ctx, nil, Validators{}); err != nil || result.Stale {
+		t.Fatalf("expected a fresh catalog, got %+v, %v", result, err)
+	}
+	atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+	clock.Advance(30 * time.Minute)
+	result, err := c.GetVoicesV2Conditional(ctx, nil, Validators{ETag: `"v1"`})
+	if err != nil || !result.Stale || len(result.Voices) != 1 || result.Validators.ETag != `"v1"` {
+		t.Fatalf("expected the stale catalog, got %+v, %v", result, err)
+	}
+	if len(logger.lines) != 1 || !strings.Contains(logger.lines[0], `msg="typecast: serving stale response" path=/v2/voices error="status 503"`) {
+		t.Fatalf("expected the stale response to be logged, got %q", logger.lines)
+	}
+	if voice, err := c.GetVoiceV2Conditional(ctx, "tc_1", Validators{}); err == nil {
+		t.Fatalf("expected an uncached voice to fail, got %+v", voice)
+	}
+
+	// A client error is not an outage, and a canceled request fails.
+	atomic.StoreInt32(&status, http.StatusNotFound)
+	if _, err := c.GetVoicesV2(ctx, nil); err == nil {
+		t.Fatal("expected a 404")
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.GetVoicesV2(canceled, nil); err == nil {
+		t.Fatal("expected a canceled request to fail")
+	}
+
+	// The API's stale-if-error overrides StaleIfError, and after it the
+	// request fails.
+	atomic.StoreInt32(&status, http.StatusOK)
+	cacheControl = "max-age=60, stale-if-error=120"
+	clock.Advance(time.Hour)
+	if _, err := c.GetVoicesV2(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+	clock.Advance(2 * time.Minute)
+	if voices, err := c.GetVoicesV2(ctx, nil); err != nil || len(voices) != 1 {
+		t.Fatalf("expected the stale catalog after a transport error, got %v, %v", voices, err)
+	}
+	clock.Advance(time.Minute)
+	if _, err := c.GetVoicesV2(ctx, nil); err == nil || cache.Len() != 0 {
+		t.Fatalf("expected the stale catalog to expire, %d entries, %v", cache.Len(), err)
+	}
+
+	// An entry kept only to revalidate is not served on an error.
+	srv = httptest.NewServer(srv.Config.Handler)
+	defer srv.Close()
+	atomic.StoreInt32(&status, http.StatusOK)
+	cacheControl = "max-age=60"
+	cache = NewResponseCache(ResponseCacheConfig{StaleWhileRevalidate: time.Hour})
+	c = NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Clock: clock, ResponseCache: cache})
+	if _, err := c.GetVoicesV2Conditional(ctx, nil, Validators{}); err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+	clock.Advance(2 * time.Minute)
+	if result, err := c.GetVoicesV2Conditional(ctx, nil, Validators{ETag: `"v1"`}); err == nil {
+		t.Fatalf("expected the outage to fail without StaleIfError, got %+v", result)
+	}
+
+	// Without a Logger the failure is dropped.
+	c = NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Clock: clock, ResponseCache: cache})
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if resp := c.serveStale(req, &responseCacheEntry{header: http.Header{}}, nil, errors.New("boom")); !isStale(resp) {
+		t.Fatal("expected a stale response")
+	}
+}
+
+func TestResponseCache_StaleWhileRevalidate(t *testing.T) {
+	var calls int32
+	var status int32 = http.StatusOK
+	release := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if n > 1 {
+			<-release
+		}
+		if s := int(atomic.LoadInt32(&status)); s != http.StatusOK {
+			w.WriteHeader(s)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprintf(w, `{"plan":"plan-%d"}`, n)
+	}))
+	defer srv.Close()
+	clock := NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	logger := &recordingLogger{}
+	cache := NewResponseCache(ResponseCacheConfig{StaleWhileRevalidate: time.Hour})
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Clock: clock, Logger: logger, ResponseCache: cache})
+	ctx := context.Background()
+
+	if sub, err := c.GetMySubscription(ctx); err != nil || sub.Plan != "plan-1" {
+		t.Fatalf("expected the first response, got %+v, %v", sub, err)
+	}
+	if _, err := c.GetMySubscription(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Expired responses are served at once while one refresh runs.
+	clock.Advance(90 * time.Second)
+	for i := 0; i < 3; i++ {
+		if sub, err := c.GetMySubscription(ctx); err != nil || sub.Plan != "plan-1" {
+			t.Fatalf("expected the stale response, got %+v, %v", sub, err)
+		}
+	}
+	release <- struct{}{}
+	c.revalidations.Wait()
+	if sub, err := c.GetMySubscription(ctx); err != nil || sub.Plan != "plan-2" || atomic.LoadInt32(&calls) != 2 {
+		t.Fatalf("expected the refreshed response after %d calls, got %+v, %v", calls, sub, err)
+	}
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.StaleHits != 3 || stats.Misses != 1 || stats.Revalidations != 1 || stats.RevalidationErrors != 0 ||
+		stats.Staleness.Count != 3 || stats.Staleness.Quantile(1) != 30 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	// A failed refresh keeps the stale response and is logged.
+	atomic.StoreInt32(&status, http.StatusInternalServerError)
+	clock.Advance(2 * time.Minute)
+	if sub, err := c.GetMySubscription(ctx); err != nil || sub.Plan != "plan-2" {
+		t.Fatalf("expected the stale response, got %+v, %v", sub, err)
+	}
+	release <- struct{}{}
+	c.revalidations.Wait()
+	if stats := cache.Stats(); stats.RevalidationErrors != 1 || cache.Len() != 1 {
+		t.Fatalf("expected a failed refresh, got %+v", stats)
+	}
+	if len(logger.lines) != 1 || !strings.Contains(logger.lines[0], `msg="typecast: response revalidation failed" path=/v1/users/me/subscription error="status 500"`) {
+		t.Fatalf("expected the failure to be logged, got %q", logger.lines)
+	}
+
+	// A closed client does not refresh, and a transport error is dropped
+	// without a Logger.
+	c.Close()
+	if _, err := c.GetMySubscription(ctx); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+	c = NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Clock: clock, ResponseCache: cache})
+	if _, err := c.GetMySubscription(ctx); err != nil {
+		t.Fatal(err)
+	}
+	c.revalidations.Wait()
+	if stats := cache.Stats(); stats.Revalidations != 3 || stats.RevalidationErrors != 2 {
+		t.Fatalf("expected a failed refresh, got %+v", stats)
+	}
+
+	// Close cancels a refresh in flight and waits for it.
+	var hang int32
+	hanging := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hang, 1) > 1 {
+			close(hanging)
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprint(w, `{"plan":"plan-1"}`)
+	}))
+	defer hung.Close()
+	c = NewClient(&ClientConfig{APIKey: "k", BaseURL: hung.URL, Clock: clock, ResponseCache: cache})
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetMySubscription(ctx); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(2 * time.Minute)
+	}
+	<-hanging
+	c.Close()
+	if stats := cache.Stats(); stats.Revalidations != 4 || stats.RevalidationErrors != 3 {
+		t.Fatalf("expected Close to end the refresh, got %+v", stats)
+	}
+}
@@ -0,0 +1,193 @@
+package typecast
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRetryBaseDelay is the first retry delay before exponential growth.
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	// defaultRetryMaxDelay caps the delay between two attempts.
+	defaultRetryMaxDelay = 8 * time.Second
+	// maxShouldRetryBody is the number of bytes of an error response a
+	// ShouldRetry hook can read.
+	maxShouldRetryBody = 64 * 1024
+)
+
+// RetryBudget caps retries to a fraction of all requests so that retry storms
+// cannot amplify an upstream outage. Every request deposits Ratio into the
+// budget and every retry withdraws one unit. Share one budget between clients
+// to enforce the ratio across them. A RetryBudget is safe for concurrent use.
+type RetryBudget struct {
+	mu      sync.Mutex
+	ratio   float64
+	balance float64
+	max     float64
+}
+
+// NewRetryBudget creates a budget that allows retries for ratio of requests
+// (e.g. 0.1 for at most 10%). reserve is the number of retries available
+// before any request has been made, and also the most the budget can save up.
+func NewRetryBudget(ratio float64, reserve int) *RetryBudget {
+	if reserve < 1 {
+		reserve = 1
+	}
+	return &RetryBudget{ratio: ratio, balance: float64(reserve), max: float64(reserve)}
+}
+
+func (b *RetryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.balance += b.ratio
+	if b.balance > b.max {
+		b.balance = b.max
+	}
+}
+
+func (b *RetryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.balance < 1 {
+		return false
+	}
+	b.balance--
+	return true
+}
+
+// send executes req, retrying transient failures (transport errors, 429 and
+// 5xx responses, or what ShouldRetry chooses) up to MaxRetries times while
+// the RetryBudget and MaxElapsedTime allow. A Retry-After longer than the
+// backoff delay is waited out instead. The call is recorded for the
+// AuditSink once it ends.
+func (c *Client) send(req *http.Request) (resp *http.Response, err error) {
+	if c.auditSink != nil {
+		started := c.clock.Now()
+		defer func() { c.audit(req, started, resp, err) }()
+	}
+	if c.refuses(req.Context()) {
+		return nil, ErrClientClosed
+	}
+	// Like the API key, the correlation ID, trace context, and tags only go
+	// to the API's own host, not to a pending job's Location elsewhere.
+	if c.sameOrigin(req.URL) {
+		setCorrelationID(req)
+		c.setTraceHeaders(req)
+		c.setTags(req)
+	}
+	if c.retryBudget != nil {
+		c.retryBudget.deposit()
+	}
+	start := c.clock.Now()
+	var delay time.Duration
+	for attempt := 0; ; attempt++ {
+		c.logCurl(req, attempt)
+		resp, err := c.sendOnce(req)
+		c.observeServerDate(resp)
+		c.observeRateLimit(req, resp)
+		if attempt >= c.maxRetries || !c.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+		delay = c.retryDelay(attempt, delay)
+		wait := c.retryWait(delay, resp)
+		if c.maxElapsedTime > 0 && c.clock.Now().Sub(start)+wait > c.maxElapsedTime {
+			return resp, err
+		}
+		if c.retryBudget != nil && !c.retryBudget.withdraw() {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		timer := c.clock.NewTimer(wait)
+		select {
+		case <-timer.C():
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+		req = cloneRequestForRetry(req)
+	}
+}
+
+// retryWait returns how long to wait before retrying after resp: the
+// backoff delay, or the response's Retry-After when it asks for longer. An
+// HTTP-date Retry-After is measured against the response's Date, so a
+// skewed local clock does not distort it.
+func (c *Client) retryWait(delay time.Duration, resp *http.Response) time.Duration {
+	if resp == nil {
+		return delay
+	}
+	if after := parseRetryAfter(resp.Header.Get("Retry-After"), c.serverNow(resp.Header)); after > delay {
+		return after
+	}
+	return delay
+}
+
+// shouldRetry classifies an attempt with the ShouldRetry hook, or
+// DefaultShouldRetry without one. The hook may read the body of an error
+// response: it is buffered and restored afterwards, so the error still
+// carries the API's message.
+func (c *Client) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if c.retryHook == nil {
+		return DefaultShouldRetry(req, resp, err)
+	}
+	if err != nil || resp.StatusCode < 400 {
+		return c.retryHook(req, resp, err)
+	}
+	body := resp.Body
+	data, _ := io.ReadAll(io.LimitReader(body, maxShouldRetryBody))
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	retry := c.retryHook(req, resp, err)
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), body), body}
+	return retry
+}
+
+// DefaultShouldRetry is the retry classification of a Client without
+// ClientConfig.ShouldRetry: transport errors, unless the request's context
+// is done, and 429, 500, 502, 503, and 504 responses are retried. A
+// ShouldRetry hook can call it for the cases it does not decide itself.
+func DefaultShouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryDelay returns the delay before retry attempt with the Backoff, or
+// ExponentialBackoff without one.
+func (c *Client) retryDelay(attempt int, previous time.Duration) time.Duration {
+	if c.backoff != nil {
+		return c.backoff.Delay(attempt, previous)
+	}
+	return ExponentialBackoff{Base: c.retryBaseDelay}.Delay(attempt, previous)
+}
+
+// RetryDelayRange returns the shortest and longest delay a Client with the
+// default Backoff waits before retry attempt (0 for the first retry). The
+// delay starts at 500ms and doubles per attempt up to 8s; jitter picks one
+// between half of it and all of it. Tests can check recorded backoffs
+// against it.
+func RetryDelayRange(attempt int) (shortest, longest time.Duration) {
+	return ExponentialBackoff{}.DelayRange(attempt)
+}
+
+func cloneRequestForRetry(req *http.Request) *http.Request {
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		retry.Body, _ = req.GetBody()
+	}
+	return retry
+}
@@ -0,0 +1,282 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newRetryTestClient returns a client with retries enabled and millisecond backoff.
+func newRetryTestClient(server *httptest.Server, config ClientConfig) *Client {
+	config.APIKey = "k"
+	config.BaseURL = server.URL
+	c := NewClient(&config)
+	c.retryBaseDelay = time.Millisecond
+	return c
+}
+
+// flakyServer fails the first failures requests with status and then succeeds.
+func flakyServer(t *testing.T, failures int32, status int, bodies *[]string) (*httptest.Server, *int32) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if bodies != nil {
+			b, _ := io.ReadAll(r.Body)
+			*bodies = append(*bodies, string(b))
+		}
+		if n <= failures {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"detail":"try again"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte("WAV"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func ttsOnce(c *Client, ctx context.Context) error {
+	_, err := c.TextToSpeech(ctx, &TTSRequest{VoiceID: "v", Text: "hello", Model: ModelSSFMV30})
+	return err
+}
+
+func TestRetry_RecoversFromTransientErrorsAndReplaysBody(t *testing.T) {
+	var bodies []string
+	srv, calls := flakyServer(t, 2, http.StatusServiceUnavailable, &bodies)
+	c := newRetryTestClient(srv, ClientConfig{MaxRetries: 2})
+	if err := ttsOnce(c, context.Background()); err != nil {
+		t.Fatalf("expected retries to succeed, got %v", err)
+	}
+	if *calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *calls)
+	}
+	for _, body := range bodies {
+		if body != bodies[0] || !strings.Contains(body, `"text":"hello"`) {
+			t.Fatalf("request body not replayed: %q", bodies)
+		}
+	}
+}
+
+func TestRetry_GivesUpAfterMaxRetries(t *testing.T) {
+	srv, calls := flakyServer(t, 5, http.StatusTooManyRequests, nil)
+	c := newRetryTestClient(srv, ClientConfig{MaxRetries: 1})
+	var apiErr *APIError
+	if err := ttsOnce(c, context.Background()); !errors.As(err, &apiErr) || apiErr.StatusCode != 429 {
+		t.Fatalf("expected final 429 APIError, got %v", err)
+	}
+	if *calls != 2 {
+		t.Fatalf("expected 2 attempts, got %d", *calls)
+	}
+}
+
+func TestRetry_DoesNotRetryClientErrors(t *testing.T) {
+	srv, calls := flakyServer(t, 1, http.StatusBadRequest, nil)
+	c := newRetryTestClient(srv, ClientConfig{MaxRetries: 3})
+	if err := ttsOnce(c, context.Background()); err == nil {
+		t.Fatal("expected 400 error")
+	}
+	if *calls != 1 {
+		t.Fatalf("expected 1 attempt, got %d", *calls)
+	}
+}
+
+func TestRetry_RetriesTransportErrors(t *testing.T) {
+	var calls int32
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return nil, errors.New("connection reset")
+		}
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"plan":"free"}`))}, nil
+	})
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://example.invalid", HTTPClient: &http.Client{Transport: rt}, MaxRetries: 1})
+	c.retryBaseDelay = time.Millisecond
+	if _, err := c.GetMySubscription(context.Background()); err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestRetry_BudgetLimitsRetries(t *testing.T) {
+	srv, calls := flakyServer(t, 100, http.StatusInternalServerError, nil)
+	budget := NewRetryBudget(0, 1)
+	c := newRetryTestClient(srv, ClientConfig{MaxRetries: 3, RetryBudget: budget})
+	_ = ttsOnce(c, context.Background())
+	_ = ttsOnce(c, context.Background())
+	// One reserved retry for the first request, none left for the second.
+	if *calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *calls)
+	}
+}
+
+func TestRetryBudget_DepositsAreCapped(t *testing.T) {
+	b := NewRetryBudget(0.5, 0)
+	for i := 0; i < 10; i++ {
+		b.deposit()
+	}
+	if !b.withdraw() || b.withdraw() {
+		t.Fatalf("expected exactly one retry available, balance = %v", b.balance)
+	}
+}
+
+func TestRetry_MaxElapsedTimeStopsRetrying(t *testing.T) {
+	srv, calls := flakyServer(t, 100, http.StatusBadGateway, nil)
+	c := newRetryTestClient(srv, ClientConfig{MaxRetries: 5, MaxElapsedTime: time.Nanosecond})
+	_ = ttsOnce(c, context.Background())
+	if *calls != 1 {
+		t.Fatalf("expected no retries past MaxElapsedTime, got %d attempts", *calls)
+	}
+}
+
+func TestRetry_ContextCanceledDuringBackoff(t *testing.T) {
+	srv, _ := flakyServer(t, 100, http.StatusGatewayTimeout, nil)
+	c := newRetryTestClient(srv, ClientConfig{MaxRetries: 5})
+	c.retryBaseDelay = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := ttsOnce(c, ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+}
+
+func TestRetry_DelayGrowsAndIsCapped(t *testing.T) {
+	c := NewClient(&ClientConfig{APIKey: "k"})
+	for attempt, max := range []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second} {
+		d := c.retryDelay(attempt, 0)
+		if d < max/2 || d > max {
+			t.Fatalf("attempt %d delay %v outside [%v, %v]", attempt, d, max/2, max)
+		}
+	}
+	for _, attempt := range []int{10, 80} {
+		if d := c.retryDelay(attempt, 0); d > defaultRetryMaxDelay || d < defaultRetryMaxDelay/2 {
+			t.Fatalf("attempt %d delay %v not capped", attempt, d)
+		}
+	}
+	if shortest, longest := RetryDelayRange(1); shortest != 500*time.Millisecond || longest != time.Second {
+		t.Fatalf("RetryDelayRange(1) = %v, %v", shortest, longest)
+	}
+}
+
+func TestRetry_BackoffUsesClock(t *testing.T) {
+	srv, calls := flakyServer(t, 3, http.StatusServiceUnavailable, nil)
+	clock := NewFakeClock(time.Unix(0, 0))
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, MaxRetries: 3, MaxElapsedTime: 30 * time.Second, Clock: clock})
+	done := make(chan error, 1)
+	go func() { done <- ttsOnce(c, context.Background()) }()
+
+	// The first backoff waits on the clock until it is advanced.
+	clock.WaitForTimers(1)
+	if n := atomic.LoadInt32(calls); n != 1 {
+		t.Fatalf("expected 1 attempt before advancing, got %d", n)
+	}
+	clock.Advance(time.Minute)
+
+	// A minute has passed by the clock, so MaxElapsedTime stops retrying.
+	var apiErr *APIError
+	if err := <-done; !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected the 503 error, got %v", err)
+	}
+	if n := atomic.LoadInt32(calls); n != 2 {
+		t.Fatalf("expected 2 attempts, got %d", n)
+	}
+}
+
+func TestRetry_WaitsForRetryAfterByServerClock(t *testing.T) {
+	local := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	// The server's clock runs an hour behind the local one.
+	server := local.Add(-time.Hour)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Date", server.Format(http.TimeFormat))
+			w.Header().Set("Retry-After", server.Add(5*time.Second).Format(http.TimeFormat))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+	clock := NewFakeClock(local)
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, MaxRetries: 1, Clock: clock, Backoff: FixedBackoff(time.Second)})
+	done := make(chan error, 1)
+	go func() { done <- ttsOnce(c, context.Background()) }()
+
+	// Measured against the server's Date, Retry-After asks for 5s, longer
+	// than the backoff.
+	clock.WaitForTimers(1)
+	clock.Advance(4 * time.Second)
+	select {
+	case err := <-done:
+		t.Fatalf("retried before Retry-After: %v", err)
+	default:
+	}
+	clock.Advance(time.Second)
+	if err := <-done; err != nil || atomic.LoadInt32(&calls) != 2 {
+		t.Fatalf("expected the retry to succeed after %d calls, got %v", calls, err)
+	}
+
+	// A Retry-After past MaxElapsedTime stops retrying.
+	atomic.StoreInt32(&calls, 0)
+	c = NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, MaxRetries: 1, Clock: clock, MaxElapsedTime: 3 * time.Second})
+	var apiErr *APIError
+	if err := ttsOnce(c, context.Background()); !errors.As(err, &apiErr) || apiErr.RetryAfter != 5*time.Second || atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected the 503 after %d calls, got %v", calls, err)
+	}
+}
+
+func TestRetry_ShouldRetryReadsErrorBody(t *testing.T) {
+	srv, calls := flakyServer(t, 2, http.StatusBadRequest, nil)
+	var seen []int
+	c := newRetryTestClient(srv, ClientConfig{MaxRetries: 3, ShouldRetry: func(req *http.Request, resp *http.Response, err error) bool {
+		seen = append(seen, resp.StatusCode)
+		if resp.StatusCode == http.StatusBadRequest {
+			body, _ := io.ReadAll(resp.Body)
+			return strings.Contains(string(body), "try again")
+		}
+		return DefaultShouldRetry(req, resp, err)
+	}})
+	if err := ttsOnce(c, context.Background()); err != nil {
+		t.Fatalf("expected the 400s to be retried, got %v", err)
+	}
+	if *calls != 3 || len(seen) != 3 || seen[2] != http.StatusOK {
+		t.Fatalf("%d attempts, hook saw %v", *calls, seen)
+	}
+}
+
+func TestRetry_ShouldRetryRestoresBody(t *testing.T) {
+	srv, calls := flakyServer(t, 5, http.StatusPaymentRequired, nil)
+	c := newRetryTestClient(srv, ClientConfig{MaxRetries: 3, ShouldRetry: func(req *http.Request, resp *http.Response, err error) bool {
+		_, _ = io.ReadAll(resp.Body)
+		return false
+	}})
+	var apiErr *APIError
+	if err := ttsOnce(c, context.Background()); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusPaymentRequired || apiErr.Detail != "try again" {
+		t.Fatalf("expected the 402 with its message, got %v", err)
+	}
+	if *calls != 1 {
+		t.Fatalf("expected 1 attempt, got %d", *calls)
+	}
+}
+
+func TestRetry_ShouldRetryTransportErrors(t *testing.T) {
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection reset")
+	})
+	var errs []error
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://example.invalid", HTTPClient: &http.Client{Transport: rt}, MaxRetries: 2,
+		ShouldRetry: func(req *http.Request, resp *http.Response, err error) bool {
+			errs = append(errs, err)
+			return false
+		}})
+	if _, err := c.GetMySubscription(context.Background()); err == nil || len(errs) != 1 || !strings.Contains(errs[0].Error(), "connection reset") {
+		t.Fatalf("expected one hook call with the transport error, got %v and %v", err, errs)
+	}
+}
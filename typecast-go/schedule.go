@@ -0,0 +1,232 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ScheduledJob is a job RunDaemon enqueues on a cron schedule, such as the
+// narration of a daily report at 06:00.
+type ScheduledJob struct {
+	// Name identifies the schedule and prefixes the IDs of its jobs, which
+	// end with the time they were due, as in "report-20240315-0600"
+	// (required)
+	Name string `json:"name"`
+	// Schedule is a cron expression as accepted by ParseSchedule, such as
+	// "0 6 * * *" (required)
+	Schedule string `json:"schedule"`
+	// Job holds the text and the voice settings that override the daemon's
+	// profile. Its text may be empty when TextFile or TextURL is set
+	Job WatchJob `json:"job"`
+	// TextFile is read for the text each time the job is due (optional)
+	TextFile string `json:"text_file,omitempty"`
+	// TextURL is fetched for the text each time the job is due (optional)
+	TextURL string `json:"text_url,omitempty"`
+	// Output is the name of the audio file, where "{date}" and "{time}" are
+	// replaced with when the job was due, as in "report-{date}.mp3"
+	// (optional, defaults to the job ID and the format's extension)
+	Output string `json:"output,omitempty"`
+	// Sink delivers the audio instead of the daemon's output directory
+	// (optional)
+	Sink *OutputSink `json:"sink,omitempty"`
+}
+
+// OutputSink is where the daemon delivers a job's audio instead of its
+// output directory. Set Dir or URL.
+type OutputSink struct {
+	// Dir is a directory the audio file is written to (optional)
+	Dir string `json:"dir,omitempty"`
+	// URL receives the audio in the body of a request, such as a
+	// presigned upload URL (optional)
+	URL string `json:"url,omitempty"`
+	// Method is the method of the request to URL (optional, defaults to
+	// PUT)
+	Method string `json:"method,omitempty"`
+}
+
+// LoadScheduledJobs reads a JSON array of scheduled jobs, e.g.
+// [{"name": "report", "schedule": "0 6 * * *", "text_file": "report.txt"}].
+// They are validated by RunDaemon.
+func LoadScheduledJobs(r io.Reader) ([]ScheduledJob, error) {
+	var jobs []ScheduledJob
+	if err := json.NewDecoder(r).Decode(&jobs); err != nil {
+		return nil, fmt.Errorf("failed to decode scheduled jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// LoadScheduledJobsFile reads a JSON file of scheduled jobs from path.
+func LoadScheduledJobsFile(path string) ([]ScheduledJob, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scheduled jobs: %w", err)
+	}
+	defer f.Close()
+	return LoadScheduledJobs(f)
+}
+
+// validate checks a scheduled job at now and parses its schedule.
+func (s ScheduledJob) validate(now time.Time) (*Schedule, error) {
+	if s.Name == "" {
+		return nil, newValidationError("name", "schedule name is required")
+	}
+	schedule, err := ParseSchedule(s.Schedule)
+	if err != nil {
+		return nil, newValidationError("schedule", fmt.Sprintf("schedule %s: %v", s.Name, err))
+	}
+	if schedule.Next(now).IsZero() {
+		return nil, newValidationError("schedule", fmt.Sprintf("schedule %s never runs", s.Name))
+	}
+	if strings.TrimSpace(s.Job.Text) == "" && s.TextFile == "" && s.TextURL == "" {
+		return nil, newValidationError("text", fmt.Sprintf("schedule %s: text, text_file, or text_url is required", s.Name))
+	}
+	if output := s.scheduledJob(now).Output; output != "" && !isFileName(output) {
+		return nil, newValidationError("output", fmt.Sprintf("schedule %s: output must be a file name without directories", s.Name))
+	}
+	if !s.Sink.valid() {
+		return nil, newValidationError("sink", fmt.Sprintf("schedule %s: sink needs one of dir or url", s.Name))
+	}
+	return schedule, nil
+}
+
+// valid reports whether a sink, if any, has one of Dir or URL.
+func (s *OutputSink) valid() bool {
+	return s == nil || (s.Dir == "") != (s.URL == "")
+}
+
+// scheduledJob returns the job s enqueues when it is due at.
+func (s ScheduledJob) scheduledJob(at time.Time) QueuedJob {
+	stamp := strings.NewReplacer("{date}", at.Format("2006-01-02"), "{time}", at.Format("1504"))
+	return QueuedJob{
+		ID:     s.Name + "-" + at.Format("20060102-1504"),
+		Job:    s.Job,
+		Output: stamp.Replace(s.Output),
+		Sink:   s.Sink,
+	}
+}
+
+// runSchedules enqueues the jobs of cfg.Schedules as they come due, until
+// ctx is done. Runs missed while the daemon was stopped are skipped.
+func (d *daemon) runSchedules(ctx context.Context, schedules []*Schedule) error {
+	next := make([]time.Time, len(schedules))
+	now := d.now().In(d.cfg.Location)
+	for i, schedule := range schedules {
+		next[i] = schedule.Next(now)
+	}
+	for {
+		first := 0
+		for i := range next {
+			if next[i].Before(next[first]) {
+				first = i
+			}
+		}
+		timer := d.client.clock.NewTimer(next[first].Sub(d.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+		now := d.now()
+		for i := range next {
+			if next[i].After(now) {
+				continue
+			}
+			if err := d.enqueueScheduled(ctx, d.cfg.Schedules[i], next[i]); err != nil {
+				return err
+			}
+			next[i] = schedules[i].Next(next[i])
+		}
+	}
+}
+
+// enqueueScheduled reads the text of a scheduled job due at and enqueues
+// it. A job whose text cannot be read is recorded as failed.
+func (d *daemon) enqueueScheduled(ctx context.Context, s ScheduledJob, at time.Time) error {
+	job := s.scheduledJob(at)
+	text, err := d.scheduledText(ctx, s)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err == nil {
+		job.Job.Text = text
+		var enqueued QueuedJob
+		enqueued, err = d.cfg.Queue.enqueue(job, d.now())
+		var validationErr *ValidationError
+		switch {
+		case err == nil:
+			d.notify(enqueued)
+			return nil
+		case errors.Is(err, ErrJobActive):
+			return nil // the run is already queued
+		case !errors.As(err, &validationErr):
+			return err
+		}
+	}
+	job.State, job.Error, job.Created = JobFailed, err.Error(), d.now().UTC()
+	return d.record(job)
+}
+
+// scheduledText returns the text of a scheduled job: its file's or URL's
+// content, or the job's own text.
+func (d *daemon) scheduledText(ctx context.Context, s ScheduledJob) (string, error) {
+	switch {
+	case s.TextFile != "":
+		b, err := os.ReadFile(s.TextFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read text: %w", err)
+		}
+		return string(b), nil
+	case s.TextURL != "":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.TextURL, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch text: %w", err)
+		}
+		d.client.setUserAgent(req.Header)
+		resp, err := d.client.httpClient.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch text: %w", err)
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		if err == nil && resp.StatusCode/100 != 2 {
+			err = fmt.Errorf("status %d", resp.StatusCode)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch text: %w", err)
+		}
+		return string(b), nil
+	}
+	return s.Job.Text, nil
+}
+
+// upload sends the audio of a finished job to its sink's URL.
+func (d *daemon) upload(ctx context.Context, sink *OutputSink, name string, audio []byte) error {
+	method := sink.Method
+	if method == "" {
+		method = http.MethodPut
+	}
+	req, err := http.NewRequestWithContext(ctx, method, sink.URL, bytes.NewReader(audio))
+	if err != nil {
+		return fmt.Errorf("failed to upload audio: %w", err)
+	}
+	req.Header.Set("Content-Type", guessAudioMime(name))
+	d.client.setUserAgent(req.Header)
+	resp, err := d.client.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload audio: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to upload audio: status %d", resp.StatusCode)
+	}
+	return nil
+}
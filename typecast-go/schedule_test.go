@@ -0,0 +1,243 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunSchedules(t *testing.T) {
+	c := newTestClient(daemonServer(t), "k")
+	dir := t.TempDir()
+	q, _ := OpenJobQueue(filepath.Join(dir, "queue.db"))
+	defer q.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The clock is moved to just before the next minute.
+	real := time.Now()
+	offset := real.Truncate(time.Minute).Add(time.Minute - 20*time.Millisecond).Sub(real)
+	var mu sync.Mutex
+	var enqueued []QueuedJob
+	d := &daemon{client: c, now: func() time.Time { return time.Now().Add(offset) }, cfg: DaemonConfig{
+		Queue:    q,
+		Location: time.UTC,
+		Schedules: []ScheduledJob{
+			{Name: "yearly", Schedule: "@yearly", Job: WatchJob{Text: "Happy new year."}},
+			{Name: "tick", Schedule: "* * * * *", Job: WatchJob{Text: "Tick."}, Output: "tick-{date}-{time}.wav"},
+		},
+		OnJob: func(job QueuedJob) {
+			mu.Lock()
+			enqueued = append(enqueued, job)
+			mu.Unlock()
+			cancel()
+		},
+	}}
+	tick, _ := ParseSchedule("* * * * *")
+	yearly, _ := ParseSchedule("@yearly")
+	if err := d.runSchedules(ctx, []*Schedule{yearly, tick}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("runSchedules = %v", err)
+	}
+	due := real.Add(offset).Add(time.Minute).Truncate(time.Minute).UTC()
+	if len(enqueued) != 1 || enqueued[0].ID != "tick-"+due.Format("20060102-1504") || enqueued[0].Output != "tick-"+due.Format("2006-01-02-1504")+".wav" {
+		t.Fatalf("enqueued %+v", enqueued)
+	}
+	if job, _ := q.Get(enqueued[0].ID); job.State != JobQueued || job.Job.Text != "Tick." {
+		t.Fatalf("queued %+v", job)
+	}
+
+	// A due job that cannot be enqueued stops the schedules. The clock
+	// moves to just before the minute after the first run's.
+	_ = q.Close()
+	real = time.Now()
+	offset = real.Truncate(time.Minute).Add(2*time.Minute - 20*time.Millisecond).Sub(real)
+	if err := d.runSchedules(context.Background(), []*Schedule{yearly, tick}); err == nil || errors.Is(err, context.Canceled) {
+		t.Fatalf("runSchedules on a closed queue = %v", err)
+	}
+}
+
+func TestEnqueueScheduled(t *testing.T) {
+	texts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, "Sales rose 3%.")
+	}))
+	defer texts.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "report.txt"), []byte("Daily report."), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "empty.txt"), nil, 0644)
+	q, _ := OpenJobQueue(filepath.Join(dir, "queue.db"))
+	defer q.Close()
+	d := &daemon{client: NewClient(&ClientConfig{APIKey: "k"}), cfg: DaemonConfig{Queue: q}, now: time.Now}
+	at := time.Date(2024, 3, 15, 6, 0, 0, 0, time.UTC)
+	tests := []struct {
+		job   ScheduledJob
+		state QueuedJobState
+		text  string
+		err   string
+	}{
+		{ScheduledJob{Name: "static", Job: WatchJob{Text: "Hello."}}, JobQueued, "Hello.", ""},
+		{ScheduledJob{Name: "file", TextFile: filepath.Join(dir, "report.txt")}, JobQueued, "Daily report.", ""},
+		{ScheduledJob{Name: "url", TextURL: texts.URL + "/sales"}, JobQueued, "Sales rose 3%.", ""},
+		{ScheduledJob{Name: "nofile", TextFile: filepath.Join(dir, "missing.txt")}, JobFailed, "", "failed to read text"},
+		{ScheduledJob{Name: "empty", TextFile: filepath.Join(dir, "empty.txt")}, JobFailed, "", "text is required"},
+		{ScheduledJob{Name: "notfound", TextURL: texts.URL + "/missing"}, JobFailed, "", "status 404"},
+		{ScheduledJob{Name: "badurl", TextURL: "://"}, JobFailed, "", "failed to fetch text"},
+		{ScheduledJob{Name: "down", TextURL: closed.URL}, JobFailed, "", "failed to fetch text"},
+	}
+	for _, tt := range tests {
+		if err := d.enqueueScheduled(context.Background(), tt.job, at); err != nil {
+			t.Fatalf("%s: %v", tt.job.Name, err)
+		}
+		job, err := q.Get(tt.job.Name + "-20240315-0600")
+		if err != nil || job.State != tt.state || job.Job.Text != tt.text || !strings.Contains(job.Error, tt.err) {
+			t.Errorf("%s: %+v (%v)", tt.job.Name, job, err)
+		}
+	}
+
+	// A run that is still queued is not enqueued again.
+	if err := d.enqueueScheduled(context.Background(), tests[0].job, at); err != nil {
+		t.Fatalf("enqueue twice: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := d.enqueueScheduled(ctx, tests[2].job, at.Add(time.Hour)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled: %v", err)
+	}
+	_ = q.Close()
+	if err := d.enqueueScheduled(context.Background(), tests[0].job, at.Add(time.Hour)); err == nil {
+		t.Fatal("expected a queue error")
+	}
+}
+
+func TestRunDaemonSinks(t *testing.T) {
+	var mu sync.Mutex
+	uploads := map[string]string{}
+	failed := false
+	store := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/flaky" && !failed {
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		uploads[r.URL.Path] = r.Method + " " + r.Header.Get("Content-Type") + " " + string(body[:4])
+	}))
+	defer store.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	c := newTestClient(daemonServer(t), "k")
+	dir := t.TempDir()
+	q, _ := OpenJobQueue(filepath.Join(dir, "queue.db"))
+	defer q.Close()
+	for _, job := range []QueuedJob{
+		{ID: "dir", Job: WatchJob{Text: "Hello."}, Sink: &OutputSink{Dir: filepath.Join(dir, "published")}},
+		{ID: "put", Job: WatchJob{Text: "Hello."}, Output: "put.wav", Sink: &OutputSink{URL: store.URL + "/put"}},
+		{ID: "post", Job: WatchJob{Text: "Hello."}, Sink: &OutputSink{URL: store.URL + "/flaky", Method: http.MethodPost}},
+		{ID: "badurl", Job: WatchJob{Text: "Hello."}, Sink: &OutputSink{URL: "://"}},
+		{ID: "down", Job: WatchJob{Text: "Hello."}, Sink: &OutputSink{URL: closed.URL}},
+	} {
+		if _, err := q.Enqueue(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jobs := runDaemonUntil(t, c, DaemonConfig{
+		Queue:       q,
+		OutputDir:   filepath.Join(dir, "audio"),
+		Profile:     VoiceProfile{VoiceID: "v"},
+		Workers:     1,
+		MaxAttempts: 2,
+		RetryDelay:  time.Millisecond,
+	})
+	want := map[string]QueuedJobState{"dir": JobDone, "put": JobDone, "post": JobDone, "badurl": JobFailed, "down": JobFailed}
+	for _, job := range jobs {
+		if job.State != want[job.ID] {
+			t.Errorf("job %s: %s (%s)", job.ID, job.State, job.Error)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "published", "dir.wav")); err != nil {
+		t.Error(err)
+	}
+	if uploads["/put"] != "PUT audio/wav RIFF" || uploads["/flaky"] != "POST audio/wav RIFF" {
+		t.Errorf("uploads: %v", uploads)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "audio", "put.wav")); !os.IsNotExist(err) {
+		t.Errorf("uploaded audio was also written: %v", err)
+	}
+}
+
+func TestRunDaemonSchedulesConfig(t *testing.T) {
+	c := newTestClient(daemonServer(t), "k")
+	dir := t.TempDir()
+	q, _ := OpenJobQueue(filepath.Join(dir, "queue.db"))
+	defer q.Close()
+	profile := VoiceProfile{VoiceID: "v"}
+	text := WatchJob{Text: "Hello."}
+	for _, schedules := range [][]ScheduledJob{
+		{{Schedule: "@daily", Job: text}},
+		{{Name: "a", Schedule: "daily", Job: text}},
+		{{Name: "a", Schedule: "0 0 31 2 *", Job: text}},
+		{{Name: "a", Schedule: "@daily"}},
+		{{Name: "a", Schedule: "@daily", Job: text, Output: "{date}/a.wav"}},
+		{{Name: "a", Schedule: "@daily", Job: text, Sink: &OutputSink{}}},
+		{{Name: "a", Schedule: "@daily", Job: text}, {Name: "a", Schedule: "@hourly", Job: text}},
+	} {
+		err := c.RunDaemon(context.Background(), DaemonConfig{Queue: q, OutputDir: dir, Profile: profile, Schedules: schedules})
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("RunDaemon(%+v) = %v", schedules, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := c.RunDaemon(ctx, DaemonConfig{Queue: q, OutputDir: dir, Profile: profile, Schedules: []ScheduledJob{
+		{Name: "report", Schedule: "0 6 * * *", TextFile: "report.txt", Sink: &OutputSink{Dir: dir}},
+	}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RunDaemon = %v", err)
+	}
+}
+
+func TestEnqueueSinkValidation(t *testing.T) {
+	q, _ := OpenJobQueue(filepath.Join(t.TempDir(), "queue.db"))
+	defer q.Close()
+	for _, sink := range []*OutputSink{{}, {Dir: "out", URL: "http://example.com"}} {
+		if _, err := q.Enqueue(QueuedJob{Job: WatchJob{Text: "Hi."}, Sink: sink}); err == nil {
+			t.Errorf("Enqueue with sink %+v succeeded", sink)
+		}
+	}
+}
+
+func TestLoadScheduledJobsFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "schedules.json")
+	_ = os.WriteFile(path, []byte(`[{"name": "report", "schedule": "0 6 * * 1-5", "text_file": "report.txt",
+		"job": {"voice_id": "anchor"}, "output": "report-{date}.mp3", "sink": {"url": "https://example.com/upload"}}]`), 0644)
+	jobs, err := LoadScheduledJobsFile(path)
+	if err != nil || len(jobs) != 1 || jobs[0].Job.VoiceID != "anchor" || jobs[0].Sink.URL != "https://example.com/upload" {
+		t.Fatalf("LoadScheduledJobsFile = %+v, %v", jobs, err)
+	}
+	if _, err := LoadScheduledJobsFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Fatal("expected an open error")
+	}
+	if _, err := LoadScheduledJobs(strings.NewReader("{")); err == nil {
+		t.Fatal("expected a decode error")
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+// audioCache is a least-recently-used cache of synthesized responses keyed
+// by the hash of their request.
+type audioCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List
+	entries map[[32]byte]*list.Element
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	key     [32]byte
+	resp    *typecast.TTSResponse
+	expires time.Time
+}
+
+// newAudioCache creates a cache of size entries. A size below 1 disables
+// caching, and a ttl of zero keeps entries until they are evicted.
+func newAudioCache(size int, ttl time.Duration) *audioCache {
+	return &audioCache{size: size, ttl: ttl, order: list.New(), entries: map[[32]byte]*list.Element{}, now: time.Now}
+}
+
+func (c *audioCache) get(key [32]byte) (*typecast.TTSResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*cacheEntry)
+	if c.ttl > 0 && c.now().After(entry.expires) {
+		c.order.Remove(e)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return entry.resp, true
+}
+
+func (c *audioCache) put(key [32]byte, resp *typecast.TTSResponse) {
+	if c.size < 1 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &cacheEntry{key: key, resp: resp, expires: c.now().Add(c.ttl)}
+	if e, ok := c.entries[key]; ok {
+		e.Value = entry
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
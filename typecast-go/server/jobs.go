@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+// handleJobs enqueues a job (POST) or lists the queue (GET).
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.config.Queue.List())
+	case http.MethodPost:
+		var job typecast.QueuedJob
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody)).Decode(&job); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+		if job.Sink != nil {
+			// A sink names a directory or URL on the daemon's side, which
+			// only its operator may choose.
+			writeError(w, http.StatusBadRequest, "sink cannot be set by a request")
+			return
+		}
+		job, err := s.config.Queue.Enqueue(job)
+		var validationErr *typecast.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			writeError(w, http.StatusBadRequest, validationErr.Message)
+		case errors.Is(err, typecast.ErrJobActive):
+			writeError(w, http.StatusConflict, err.Error())
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		default:
+			writeJSON(w, http.StatusAccepted, job)
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleJob reports the state of one job.
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	job, err := s.config.Queue.Get(strings.TrimPrefix(r.URL.Path, "/jobs/"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
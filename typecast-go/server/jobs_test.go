@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+func TestJobs(t *testing.T) {
+	queue, err := typecast.OpenJobQueue(filepath.Join(t.TempDir(), "queue.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer queue.Close()
+	gateway, _ := newTestServer(t, Config{Queue: queue})
+
+	resp, body := do(t, http.MethodPost, gateway.URL+"/jobs", "", `{"id": "daily", "job": {"text": "Good morning."}, "output": "daily.wav"}`)
+	var job typecast.QueuedJob
+	_ = json.Unmarshal([]byte(body), &job)
+	if resp.StatusCode != http.StatusAccepted || job.ID != "daily" || job.State != typecast.JobQueued {
+		t.Fatalf("POST /jobs: %d %s", resp.StatusCode, body)
+	}
+	if resp, body := do(t, http.MethodGet, gateway.URL+"/jobs/daily", "", ""); resp.StatusCode != http.StatusOK || !strings.Contains(body, `"output":"daily.wav"`) {
+		t.Fatalf("GET /jobs/daily: %d %s", resp.StatusCode, body)
+	}
+	var jobs []typecast.QueuedJob
+	_, body = do(t, http.MethodGet, gateway.URL+"/jobs", "", "")
+	if err := json.Unmarshal([]byte(body), &jobs); err != nil || len(jobs) != 1 {
+		t.Fatalf("GET /jobs: %s", body)
+	}
+
+	tests := []struct {
+		method, path, body string
+		status             int
+		detail             string
+	}{
+		{http.MethodPost, "/jobs", `{"id": "daily", "job": {"text": "Again."}}`, http.StatusConflict, "queued or running"},
+		{http.MethodPost, "/jobs", `{"job": {"text": ""}}`, http.StatusBadRequest, "text is required"},
+		{http.MethodPost, "/jobs", `{"job": {"text": "Hi."}, "sink": {"dir": "/tmp"}}`, http.StatusBadRequest, "sink cannot be set"},
+		{http.MethodPost, "/jobs", `{`, http.StatusBadRequest, "invalid request body"},
+		{http.MethodDelete, "/jobs", "", http.StatusMethodNotAllowed, "method not allowed"},
+		{http.MethodPost, "/jobs/daily", "", http.StatusMethodNotAllowed, "method not allowed"},
+		{http.MethodGet, "/jobs/missing", "", http.StatusNotFound, "job not found"},
+	}
+	for _, tt := range tests {
+		resp, body := do(t, tt.method, gateway.URL+tt.path, "", tt.body)
+		var errResp typecast.ErrorResponse
+		_ = json.Unmarshal([]byte(body), &errResp)
+		if resp.StatusCode != tt.status || !strings.Contains(errResp.Detail, tt.detail) {
+			t.Errorf("%s %s: %d %q", tt.method, tt.path, resp.StatusCode, body)
+		}
+	}
+
+	_ = queue.Close()
+	if resp, _ := do(t, http.MethodPost, gateway.URL+"/jobs", "", `{"job": {"text": "Late."}}`); resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("POST /jobs to a closed queue: %d", resp.StatusCode)
+	}
+}
+
+func TestJobsNotServedWithoutQueue(t *testing.T) {
+	gateway, _ := newTestServer(t, Config{})
+	if resp, _ := do(t, http.MethodGet, gateway.URL+"/jobs", "", ""); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("GET /jobs without a queue: %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+// openAISpeechPath is the path of OpenAI's text-to-speech endpoint.
+const openAISpeechPath = "/v1/audio/speech"
+
+// openAISpeechRequest is the body of an OpenAI speech request.
+type openAISpeechRequest struct {
+	Model          string   `json:"model"`
+	Input          string   `json:"input"`
+	Voice          string   `json:"voice"`
+	ResponseFormat string   `json:"response_format"`
+	Speed          *float64 `json:"speed"`
+}
+
+// handleOpenAISpeech serves OpenAI speech requests with Typecast, so tools
+// written against OpenAI's speech API can switch backends with a URL change.
+func (s *Server) handleOpenAISpeech(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeOpenAIError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var body openAISpeechRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody)).Decode(&body); err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	request, err := s.openAIRequest(body)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.synthesize(w, r, request, writeOpenAIError)
+}
+
+// openAIRequest maps an OpenAI speech request onto a Typecast request.
+func (s *Server) openAIRequest(body openAISpeechRequest) (*typecast.TTSRequest, error) {
+	if body.Input == "" {
+		return nil, fmt.Errorf("input is required")
+	}
+	if body.Voice == "" {
+		return nil, fmt.Errorf("voice is required")
+	}
+	voice := body.Voice
+	if id, ok := s.config.OpenAIVoices[voice]; ok {
+		voice = id
+	}
+	model := typecast.ModelSSFMV30
+	if m, ok := s.config.OpenAIModels[body.Model]; ok {
+		model = m
+	}
+	output := &typecast.Output{AudioTempo: body.Speed}
+	switch body.ResponseFormat {
+	case "", "mp3":
+		output.AudioFormat = typecast.AudioFormatMP3
+	case "wav":
+		output.AudioFormat = typecast.AudioFormatWAV
+	default:
+		return nil, fmt.Errorf("unsupported response_format %q: use mp3 or wav", body.ResponseFormat)
+	}
+	return &typecast.TTSRequest{VoiceID: voice, Text: body.Input, Model: model, Output: output}, nil
+}
+
+// writeOpenAIError writes an error in OpenAI's format.
+func writeOpenAIError(w http.ResponseWriter, status int, message string) {
+	kind := "invalid_request_error"
+	switch {
+	case status == http.StatusUnauthorized:
+		kind = "authentication_error"
+	case status == http.StatusTooManyRequests:
+		kind = "rate_limit_error"
+	case status >= http.StatusInternalServerError:
+		kind = "server_error"
+	}
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{"message": message, "type": kind, "param": nil, "code": nil},
+	})
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+func openAISpeech(t *testing.T, url, token, body string) (*http.Response, string) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, url+"/v1/audio/speech", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp, string(b)
+}
+
+func TestOpenAISpeech(t *testing.T) {
+	gateway, _ := newTestServer(t, Config{
+		Tokens:       map[string]string{"sk-gateway": "tools"},
+		OpenAIVoices: map[string]string{"alloy": "tc_1"},
+		OpenAIModels: map[string]typecast.TTSModel{"tts-1": typecast.ModelSSFMV21},
+	})
+
+	resp, body := openAISpeech(t, gateway.URL, "sk-gateway", `{"model": "tts-1", "input": "mp3", "voice": "alloy", "speed": 1.25}`)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "audio/mpeg" || body != "audio:mp3" {
+		t.Fatalf("unexpected response %d %v %q", resp.StatusCode, resp.Header, body)
+	}
+	got := lastRequest.Load().(typecast.TTSRequest)
+	if got.VoiceID != "tc_1" || got.Model != typecast.ModelSSFMV21 || got.Output.AudioFormat != typecast.AudioFormatMP3 || *got.Output.AudioTempo != 1.25 {
+		t.Fatalf("unexpected upstream request %+v", got)
+	}
+
+	_, body = openAISpeech(t, gateway.URL, "sk-gateway", `{"model": "gpt-4o-mini-tts", "input": "Hi", "voice": "tc_2", "response_format": "wav"}`)
+	got = lastRequest.Load().(typecast.TTSRequest)
+	if body != "audio:Hi" || got.VoiceID != "tc_2" || got.Model != typecast.ModelSSFMV30 || got.Output.AudioFormat != typecast.AudioFormatWAV {
+		t.Fatalf("unexpected upstream request %+v", got)
+	}
+}
+
+func TestOpenAISpeechErrors(t *testing.T) {
+	gateway, _ := newTestServer(t, Config{Tokens: map[string]string{"sk-gateway": "tools"}})
+	tests := []struct {
+		token, body string
+		status      int
+		kind        string
+		message     string
+	}{
+		{"wrong", `{}`, http.StatusUnauthorized, "authentication_error", "gateway token"},
+		{"sk-gateway", `{`, http.StatusBadRequest, "invalid_request_error", "invalid request body"},
+		{"sk-gateway", `{"voice": "alloy"}`, http.StatusBadRequest, "invalid_request_error", "input is required"},
+		{"sk-gateway", `{"input": "Hi"}`, http.StatusBadRequest, "invalid_request_error", "voice is required"},
+		{"sk-gateway", `{"input": "Hi", "voice": "tc_1", "response_format": "opus"}`, http.StatusBadRequest, "invalid_request_error", "unsupported response_format"},
+		{"sk-gateway", `{"input": "busy", "voice": "tc_1"}`, http.StatusTooManyRequests, "rate_limit_error", "slow down"},
+	}
+	for _, tt := range tests {
+		resp, body := openAISpeech(t, gateway.URL, tt.token, tt.body)
+		var errResp struct {
+			Error struct {
+				Message string `json:"message"`
+				Type    string `json:"type"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal([]byte(body), &errResp)
+		if resp.StatusCode != tt.status || errResp.Error.Type != tt.kind || !strings.Contains(errResp.Error.Message, tt.message) {
+			t.Errorf("%s: %d %q", tt.body, resp.StatusCode, body)
+		}
+	}
+
+	s, _ := New(Config{Client: typecast.NewClient(&typecast.ClientConfig{APIKey: "secret", BaseURL: "http://127.0.0.1:1"})})
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/audio/speech", strings.NewReader(`{"input": "Hi", "voice": "tc_1"}`)))
+	if w.Code != http.StatusBadGateway || !strings.Contains(w.Body.String(), "server_error") {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/audio/speech", nil))
+	if w.Code != http.StatusMethodNotAllowed || !strings.Contains(w.Body.String(), "invalid_request_error") {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+}
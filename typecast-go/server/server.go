@@ -0,0 +1,301 @@
+// Package server exposes a typecast.Client as a local HTTP service, so
+// services written in other languages can use Typecast through one gateway
+// that holds the API key, caches synthesized audio, and rate-limits each
+// caller.
+//
+// The gateway serves the Typecast API paths it proxies, so any Typecast SDK
+// can use it by pointing its base URL at the gateway and sending a gateway
+// token instead of the API key:
+//
+//	POST /v1/text-to-speech
+//	GET  /v2/voices
+//	GET  /v2/voices/{voice_id}
+//	POST /v1/audio/speech (OpenAI-compatible, see Config.OpenAIVoices)
+//	GET  /healthz
+//
+// With Config.Queue set, it also accepts narration jobs for a daemon
+// running typecast.Client.RunDaemon on the same queue:
+//
+//	POST /jobs        (a typecast.QueuedJob, answered 202 with its ID)
+//	GET  /jobs
+//	GET  /jobs/{id}
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+const (
+	// defaultCacheSize is the number of responses cached when
+	// Config.CacheSize is zero.
+	defaultCacheSize = 256
+	// maxRequestBody caps the size of request bodies.
+	maxRequestBody = 1 << 20
+)
+
+// Config configures a Server.
+type Config struct {
+	// Client sends the requests to Typecast; its API key never leaves the
+	// gateway (required)
+	Client *typecast.Client
+	// Tokens maps the tokens callers send, as X-API-KEY or
+	// "Authorization: Bearer <token>", to caller names. When set, requests
+	// without a known token are rejected with 401; a token cannot be empty
+	// (optional, when empty the gateway is open to anyone who can reach it)
+	Tokens map[string]string
+	// RateLimit is the requests per second allowed to each caller
+	// (optional, 0 disables limiting)
+	RateLimit float64
+	// Burst is the number of requests a caller may make at once (optional,
+	// defaults to 1)
+	Burst int
+	// MaxQueueWait is how long a rate-limited request waits for its turn
+	// before it is rejected with 429 (optional, defaults to rejecting at
+	// once)
+	MaxQueueWait time.Duration
+	// CacheSize is the number of synthesized responses kept in memory and
+	// served again for identical requests (optional, defaults to 256; a
+	// negative size disables caching)
+	CacheSize int
+	// CacheTTL is how long a cached response is served (optional, 0 keeps
+	// responses until they are evicted)
+	CacheTTL time.Duration
+	// OpenAIVoices maps the voice names of OpenAI speech requests, such as
+	// "alloy", to Typecast voice IDs. Names that are not mapped are used as
+	// voice IDs (optional)
+	OpenAIVoices map[string]string
+	// OpenAIModels maps the model names of OpenAI speech requests, such as
+	// "tts-1", to Typecast models (optional, unmapped names use ssfm-v30)
+	OpenAIModels map[string]typecast.TTSModel
+	// Queue receives the jobs posted to /jobs (optional, the job endpoints
+	// are only served when set)
+	Queue *typecast.JobQueue
+}
+
+// Server is an http.Handler that proxies Typecast requests.
+type Server struct {
+	config Config
+	cache  *audioCache
+	mux    *http.ServeMux
+
+	mu       sync.Mutex
+	limiters map[string]*typecast.TokenBucketLimiter
+}
+
+// New creates a Server.
+func New(config Config) (*Server, error) {
+	if config.Client == nil {
+		return nil, errors.New("server: client is required")
+	}
+	// An empty token would authenticate callers that send none.
+	if _, ok := config.Tokens[""]; ok {
+		return nil, errors.New("server: tokens cannot be empty")
+	}
+	size := config.CacheSize
+	if size == 0 {
+		size = defaultCacheSize
+	}
+	s := &Server{
+		config:   config,
+		cache:    newAudioCache(size, config.CacheTTL),
+		mux:      http.NewServeMux(),
+		limiters: map[string]*typecast.TokenBucketLimiter{},
+	}
+	s.mux.HandleFunc("/v1/text-to-speech", s.handleTextToSpeech)
+	s.mux.HandleFunc("/v2/voices", s.handleVoices)
+	s.mux.HandleFunc("/v2/voices/", s.handleVoice)
+	s.mux.HandleFunc(openAISpeechPath, s.handleOpenAISpeech)
+	if config.Queue != nil {
+		s.mux.HandleFunc("/jobs", s.handleJobs)
+		s.mux.HandleFunc("/jobs/", s.handleJob)
+	}
+	s.mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return s, nil
+}
+
+// ServeHTTP authenticates and rate-limits the caller, then serves r.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/healthz" {
+		s.mux.ServeHTTP(w, r)
+		return
+	}
+	fail := writeError
+	if r.URL.Path == openAISpeechPath {
+		fail = writeOpenAIError
+	}
+	caller, ok := s.authenticate(r)
+	if !ok {
+		fail(w, http.StatusUnauthorized, "invalid or missing gateway token")
+		return
+	}
+	if err := s.wait(r.Context(), caller); err != nil {
+		w.Header().Set("Retry-After", "1")
+		fail(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// authenticate returns the name of the caller that sent r.
+func (s *Server) authenticate(r *http.Request) (string, bool) {
+	if len(s.config.Tokens) == 0 {
+		return "", true
+	}
+	token := r.Header.Get("X-API-KEY")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+	found, caller := false, ""
+	for known, name := range s.config.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(known)) == 1 {
+			found, caller = true, name
+		}
+	}
+	return caller, found
+}
+
+// wait waits for the caller's rate limit, for at most MaxQueueWait.
+func (s *Server) wait(ctx context.Context, caller string) error {
+	if s.config.RateLimit <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	limiter, ok := s.limiters[caller]
+	if !ok {
+		limiter = typecast.NewTokenBucketLimiter(s.config.RateLimit, s.config.Burst)
+		s.limiters[caller] = limiter
+	}
+	s.mu.Unlock()
+	ctx, cancel := context.WithTimeout(ctx, s.config.MaxQueueWait)
+	defer cancel()
+	return limiter.Wait(ctx)
+}
+
+func (s *Server) handleTextToSpeech(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var request typecast.TTSRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	s.synthesize(w, r, &request, writeError)
+}
+
+// synthesize writes the audio of request, from the cache when possible.
+// Errors are written with fail.
+func (s *Server) synthesize(w http.ResponseWriter, r *http.Request, request *typecast.TTSRequest, fail errorWriter) {
+	body, _ := json.Marshal(request)
+	key := sha256.Sum256(body)
+	resp, hit := s.cache.get(key)
+	if !hit {
+		var err error
+		resp, err = s.config.Client.TextToSpeech(r.Context(), request)
+		if err != nil {
+			writeUpstreamError(w, err, fail)
+			return
+		}
+		s.cache.put(key, resp)
+	}
+	cache := "MISS"
+	if hit {
+		cache = "HIT"
+	}
+	contentType := "audio/wav"
+	if resp.Format == typecast.AudioFormatMP3 {
+		contentType = "audio/mpeg"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(resp.AudioData)))
+	w.Header().Set("X-Audio-Duration", strconv.FormatFloat(resp.Duration, 'f', -1, 64))
+	w.Header().Set("X-Cache", cache)
+	_, _ = w.Write(resp.AudioData)
+}
+
+func (s *Server) handleVoices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	query := r.URL.Query()
+	filter := &typecast.VoicesV2Filter{Language: query.Get("language")}
+	for _, m := range query["model"] {
+		filter.Models = append(filter.Models, typecast.TTSModel(m))
+	}
+	for _, g := range query["gender"] {
+		filter.Genders = append(filter.Genders, typecast.GenderEnum(g))
+	}
+	for _, a := range query["age"] {
+		filter.Ages = append(filter.Ages, typecast.AgeEnum(a))
+	}
+	for _, u := range query["use_cases"] {
+		filter.UseCasesAnyOf = append(filter.UseCasesAnyOf, typecast.UseCaseEnum(u))
+	}
+	voices, err := s.config.Client.GetVoicesV2(r.Context(), filter)
+	if err != nil {
+		writeUpstreamError(w, err, writeError)
+		return
+	}
+	writeJSON(w, http.StatusOK, voices)
+}
+
+func (s *Server) handleVoice(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	voice, err := s.config.Client.GetVoiceV2(r.Context(), strings.TrimPrefix(r.URL.Path, "/v2/voices/"))
+	if err != nil {
+		writeUpstreamError(w, err, writeError)
+		return
+	}
+	writeJSON(w, http.StatusOK, voice)
+}
+
+// errorWriter writes an error response in an API's format.
+type errorWriter func(w http.ResponseWriter, status int, message string)
+
+// writeUpstreamError reports a failed Typecast call with fail, using the
+// status of the API error, 400 for a request rejected locally, or 502
+// otherwise.
+func writeUpstreamError(w http.ResponseWriter, err error, fail errorWriter) {
+	var apiErr *typecast.APIError
+	var validationErr *typecast.ValidationError
+	switch {
+	case errors.As(err, &apiErr):
+		if apiErr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int((apiErr.RetryAfter+time.Second-1)/time.Second)))
+		}
+		fail(w, apiErr.StatusCode, apiErr.Detail)
+	case errors.As(err, &validationErr):
+		fail(w, http.StatusBadRequest, validationErr.Message)
+	default:
+		fail(w, http.StatusBadGateway, err.Error())
+	}
+}
+
+// writeError writes an error in the Typecast API's format.
+func writeError(w http.ResponseWriter, status int, detail string) {
+	writeJSON(w, status, typecast.ErrorResponse{Detail: detail})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
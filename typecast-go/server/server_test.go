@@ -0,0 +1,243 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+// lastRequest holds the last synthesis request upstream received.
+var lastRequest atomic.Value
+
+// upstream fakes the Typecast API and counts synthesis requests.
+func upstream(t *testing.T) (*httptest.Server, *int64) {
+	var requests int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-KEY") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/v1/text-to-speech":
+			atomic.AddInt64(&requests, 1)
+			var body typecast.TTSRequest
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			lastRequest.Store(body)
+			switch body.Text {
+			case "busy":
+				w.Header().Set("Retry-After", "3")
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(`{"detail": "slow down"}`))
+				return
+			case "mp3":
+				w.Header().Set("Content-Type", "audio/mpeg")
+			default:
+				w.Header().Set("Content-Type", "audio/wav")
+			}
+			w.Header().Set("X-Audio-Duration", "1.5")
+			_, _ = w.Write([]byte("audio:" + body.Text))
+		case r.URL.Path == "/v2/voices":
+			_, _ = w.Write([]byte(`[{"voice_id": "tc_1", "voice_name": "One", "models": [], "echo": "` + r.URL.RawQuery + `"}]`))
+		case r.URL.Path == "/v2/voices/tc_1":
+			_, _ = w.Write([]byte(`{"voice_id": "tc_1", "voice_name": "One", "models": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"detail": "voice not found"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func newTestServer(t *testing.T, config Config) (*httptest.Server, *int64) {
+	up, requests := upstream(t)
+	config.Client = typecast.NewClient(&typecast.ClientConfig{APIKey: "secret", BaseURL: up.URL})
+	s, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gateway := httptest.NewServer(s)
+	t.Cleanup(gateway.Close)
+	return gateway, requests
+}
+
+func do(t *testing.T, method, url, token, body string) (*http.Response, string) {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("X-API-KEY", token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp, string(b)
+}
+
+func TestTextToSpeechThroughGateway(t *testing.T) {
+	gateway, requests := newTestServer(t, Config{Tokens: map[string]string{"svc-token": "billing"}})
+
+	// Any Typecast SDK works against the gateway with a gateway token.
+	client := typecast.NewClient(&typecast.ClientConfig{APIKey: "svc-token", BaseURL: gateway.URL})
+	request := &typecast.TTSRequest{VoiceID: "tc_1", Text: "Hello", Model: typecast.ModelSSFMV30}
+	for i := 0; i < 2; i++ {
+		resp, err := client.TextToSpeech(context.Background(), request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(resp.AudioData) != "audio:Hello" || resp.Duration != 1.5 || resp.Format != typecast.AudioFormatWAV {
+			t.Fatalf("unexpected response %+v", resp)
+		}
+	}
+	if *requests != 1 {
+		t.Fatalf("expected the second request to be cached, got %d upstream requests", *requests)
+	}
+
+	resp, body := do(t, http.MethodPost, gateway.URL+"/v1/text-to-speech", "svc-token", `{"voice_id": "tc_1", "text": "mp3", "model": "ssfm-v30"}`)
+	if resp.Header.Get("Content-Type") != "audio/mpeg" || resp.Header.Get("X-Cache") != "MISS" || body != "audio:mp3" {
+		t.Fatalf("unexpected response %v %q", resp.Header, body)
+	}
+	resp, _ = do(t, http.MethodPost, gateway.URL+"/v1/text-to-speech", "svc-token", `{"voice_id": "tc_1", "text": "mp3", "model": "ssfm-v30"}`)
+	if resp.Header.Get("X-Cache") != "HIT" {
+		t.Fatalf("expected a cache hit, got %v", resp.Header)
+	}
+}
+
+func TestGatewayErrors(t *testing.T) {
+	gateway, _ := newTestServer(t, Config{CacheSize: -1})
+	tests := []struct {
+		method, path, body string
+		status             int
+		detail             string
+	}{
+		{http.MethodGet, "/v1/text-to-speech", "", http.StatusMethodNotAllowed, "method not allowed"},
+		{http.MethodPost, "/v2/voices", "", http.StatusMethodNotAllowed, "method not allowed"},
+		{http.MethodPost, "/v2/voices/tc_1", "", http.StatusMethodNotAllowed, "method not allowed"},
+		{http.MethodPost, "/v1/text-to-speech", "{", http.StatusBadRequest, "invalid request body"},
+		{http.MethodPost, "/v1/text-to-speech", `{"text": "t", "output": {"audio_format": "ogg"}}`, http.StatusBadRequest, "audio_format"},
+		{http.MethodPost, "/v1/text-to-speech", `{"text": "busy"}`, http.StatusTooManyRequests, "slow down"},
+		{http.MethodGet, "/v2/voices/missing", "", http.StatusNotFound, "voice not found"},
+	}
+	for _, tt := range tests {
+		resp, body := do(t, tt.method, gateway.URL+tt.path, "", tt.body)
+		var errResp typecast.ErrorResponse
+		_ = json.Unmarshal([]byte(body), &errResp)
+		if resp.StatusCode != tt.status || !strings.Contains(errResp.Detail, tt.detail) {
+			t.Errorf("%s %s: %d %q", tt.method, tt.path, resp.StatusCode, body)
+		}
+		if tt.status == http.StatusTooManyRequests && resp.Header.Get("Retry-After") != "3" {
+			t.Errorf("expected Retry-After to be forwarded, got %v", resp.Header)
+		}
+	}
+
+	// An unreachable upstream is a bad gateway.
+	s, _ := New(Config{Client: typecast.NewClient(&typecast.ClientConfig{APIKey: "secret", BaseURL: "http://127.0.0.1:1"})})
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v2/voices", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v2/voices/tc_1", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502, got %d", w.Code)
+	}
+
+	if _, err := New(Config{}); err == nil {
+		t.Fatal("expected an error without a client")
+	}
+	client := typecast.NewClient(&typecast.ClientConfig{APIKey: "k"})
+	if _, err := New(Config{Client: client, Tokens: map[string]string{"": "alice"}}); err == nil || !strings.Contains(err.Error(), "tokens cannot be empty") {
+		t.Fatalf("expected an empty token error, got %v", err)
+	}
+}
+
+func TestGatewayVoices(t *testing.T) {
+	gateway, _ := newTestServer(t, Config{})
+	_, body := do(t, http.MethodGet, gateway.URL+"/v2/voices?model=ssfm-v30&gender=female&age=young_adult&use_cases=Audiobook&language=kor", "", "")
+	if !strings.Contains(body, `"voice_id":"tc_1"`) {
+		t.Fatalf("unexpected voices %q", body)
+	}
+	_, body = do(t, http.MethodGet, gateway.URL+"/v2/voices/tc_1", "", "")
+	if !strings.Contains(body, `"voice_name":"One"`) {
+		t.Fatalf("unexpected voice %q", body)
+	}
+	resp, body := do(t, http.MethodGet, gateway.URL+"/healthz", "", "")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "ok") {
+		t.Fatalf("unexpected health %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestGatewayAuthAndRateLimit(t *testing.T) {
+	gateway, _ := newTestServer(t, Config{
+		Tokens:    map[string]string{"a-token": "a", "b-token": "b"},
+		RateLimit: 0.001,
+		Burst:     1,
+	})
+	if resp, _ := do(t, http.MethodGet, gateway.URL+"/v2/voices/tc_1", "", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d", resp.StatusCode)
+	}
+	if resp, _ := do(t, http.MethodGet, gateway.URL+"/v2/voices/tc_1", "secret", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the upstream key to be rejected, got %d", resp.StatusCode)
+	}
+	if resp, _ := do(t, http.MethodGet, gateway.URL+"/healthz", "", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected health checks without a token, got %d", resp.StatusCode)
+	}
+
+	if resp, _ := do(t, http.MethodGet, gateway.URL+"/v2/voices/tc_1", "a-token", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	resp, _ := do(t, http.MethodGet, gateway.URL+"/v2/voices/tc_1", "a-token", "")
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		t.Fatalf("expected 429, got %d", resp.StatusCode)
+	}
+
+	// Each caller has its own budget.
+	req, _ := http.NewRequest(http.MethodGet, gateway.URL+"/v2/voices/tc_1", nil)
+	req.Header.Set("Authorization", "Bearer b-token")
+	bResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bResp.Body.Close()
+	if bResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for another caller, got %d", bResp.StatusCode)
+	}
+}
+
+func TestAudioCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newAudioCache(2, time.Minute)
+	c.now = func() time.Time { return now }
+	a, b, d := [32]byte{1}, [32]byte{2}, [32]byte{3}
+	c.put(a, &typecast.TTSResponse{Duration: 1})
+	c.put(b, &typecast.TTSResponse{Duration: 2})
+	c.put(a, &typecast.TTSResponse{Duration: 3}) // refreshes a
+	c.put(d, &typecast.TTSResponse{Duration: 4}) // evicts b
+	if _, ok := c.get(b); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if resp, ok := c.get(a); !ok || resp.Duration != 3 {
+		t.Fatalf("unexpected entry %+v", resp)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.get(a); ok {
+		t.Fatal("expected a to expire")
+	}
+
+	disabled := newAudioCache(0, 0)
+	disabled.put(a, &typecast.TTSResponse{})
+	if _, ok := disabled.get(a); ok {
+		t.Fatal("expected caching to be disabled")
+	}
+}
@@ -0,0 +1,151 @@
+package typecast
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// sfxMarkup matches sound effect markers such as {sfx:door_knock.wav}.
+var sfxMarkup = regexp.MustCompile(`\{sfx:\s*([^{}]+?)\s*\}`)
+
+// SoundEffect is a sound effect placed in a long-form narration by a
+// {sfx:file} marker.
+type SoundEffect struct {
+	// File is the effect's file name as written in the marker
+	File string `json:"file"`
+	// Start is the effect's offset in the stitched audio, in seconds
+	Start float64 `json:"start"`
+	// Duration is the effect's duration in seconds
+	Duration float64 `json:"duration"`
+}
+
+// scriptPart is either a chunk of text to synthesize or a sound effect.
+type scriptPart struct {
+	text string
+	sfx  string
+}
+
+// splitScript splits text at {sfx:file} markers, when effects is set, and
+// packs the text between them into chunks of at most maxChars characters.
+func splitScript(text string, maxChars int, effects bool) []scriptPart {
+	var parts []scriptPart
+	addText := func(text string) {
+		for _, chunk := range splitText(text, maxChars) {
+			parts = append(parts, scriptPart{text: chunk})
+		}
+	}
+	if !effects {
+		addText(text)
+		return parts
+	}
+	last := 0
+	for _, m := range sfxMarkup.FindAllStringSubmatchIndex(text, -1) {
+		addText(text[last:m[0]])
+		parts = append(parts, scriptPart{sfx: text[m[2]:m[3]]})
+		last = m[1]
+	}
+	addText(text[last:])
+	return parts
+}
+
+// isSoundEffectName reports whether name is a file directly inside the
+// sound directory, since markers may come from untrusted text.
+func isSoundEffectName(name string) bool {
+	return !filepath.IsAbs(name) && !strings.ContainsAny(name, `/\`) && !strings.Contains(name, "..")
+}
+
+// loadSoundEffect reads the sound effect file name from dir and checks that
+// it can be stitched into audio of format. It returns the audio and its
+// duration in seconds.
+func loadSoundEffect(dir, name string, format AudioFormat) ([]byte, float64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil, 0, fmt.Errorf("sfx %s: %w", name, err)
+	}
+	if format == AudioFormatMP3 {
+		frames, err := parseMP3Frames(data)
+		if err != nil {
+			return nil, 0, fmt.Errorf("sfx %s: mp3 output requires mp3 sound effects: %w", name, err)
+		}
+		var duration float64
+		for _, frame := range frames {
+			duration += frame.duration()
+		}
+		return data, duration, nil
+	}
+	wav, err := decodeWAV(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("sfx %s: %w", name, err)
+	}
+	if !isPCM16(wav) {
+		return nil, 0, fmt.Errorf("sfx %s: only 16-bit mono or stereo PCM wav is supported", name)
+	}
+	return data, wav.duration(), nil
+}
+
+// matchWAVFormat converts each 16-bit PCM WAV in parts at the indexes in
+// convert to the channel count and sample rate of reference.
+func matchWAVFormat(parts [][]byte, convert []int, reference []byte) error {
+	ref, err := decodeWAV(reference)
+	if err != nil {
+		return err
+	}
+	if !isPCM16(ref) {
+		return fmt.Errorf("sound effects require 16-bit mono or stereo PCM speech")
+	}
+	for _, i := range convert {
+		wav, _ := decodeWAV(parts[i]) // validated by loadSoundEffect
+		if bytes.Equal(wav.format, ref.format) {
+			continue
+		}
+		parts[i] = (&wavAudio{format: ref.format, data: convertPCM16(wav, ref.channels(), ref.sampleRate())}).encode()
+	}
+	return nil
+}
+
+// isPCM16 reports whether wav holds 16-bit mono or stereo PCM.
+func isPCM16(wav *wavAudio) bool {
+	format := binary.LittleEndian.Uint16(wav.format[0:2])
+	channels := wav.channels()
+	return format == 1 && wav.bitsPerSample() == 16 && (channels == 1 || channels == 2) && wav.sampleRate() > 0
+}
+
+// convertPCM16 remixes 16-bit PCM to channels and linearly resamples it to
+// sampleRate.
+func convertPCM16(wav *wavAudio, channels, sampleRate int) []byte {
+	inChannels := wav.channels()
+	frames := len(wav.data) / (2 * inChannels)
+	sample := func(frame, channel int) float64 {
+		if channel >= inChannels {
+			channel = inChannels - 1
+		}
+		if inChannels == 2 && channels == 1 {
+			left := int16(binary.LittleEndian.Uint16(wav.data[frame*4:]))
+			right := int16(binary.LittleEndian.Uint16(wav.data[frame*4+2:]))
+			return (float64(left) + float64(right)) / 2
+		}
+		return float64(int16(binary.LittleEndian.Uint16(wav.data[(frame*inChannels+channel)*2:])))
+	}
+	outFrames := int(int64(frames) * int64(sampleRate) / int64(wav.sampleRate()))
+	out := make([]byte, outFrames*channels*2)
+	step := float64(wav.sampleRate()) / float64(sampleRate)
+	for i := 0; i < outFrames; i++ {
+		pos := float64(i) * step
+		frame := int(pos)
+		frac := pos - float64(frame)
+		next := frame + 1
+		if next >= frames {
+			next = frames - 1
+		}
+		for c := 0; c < channels; c++ {
+			v := sample(frame, c)*(1-frac) + sample(next, c)*frac
+			binary.LittleEndian.PutUint16(out[(i*channels+c)*2:], uint16(int16(v)))
+		}
+	}
+	return out
+}
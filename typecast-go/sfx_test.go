@@ -0,0 +1,214 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// makeTestStereoWAV builds a 16-bit stereo PCM WAV file from samples
+// interleaved as left, right.
+func makeTestStereoWAV(sampleRate int, samples ...int16) []byte {
+	format := make([]byte, 16)
+	binary.LittleEndian.PutUint16(format[0:2], 1)
+	binary.LittleEndian.PutUint16(format[2:4], 2)
+	binary.LittleEndian.PutUint32(format[4:8], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(format[8:12], uint32(sampleRate*4))
+	binary.LittleEndian.PutUint16(format[12:14], 4)
+	binary.LittleEndian.PutUint16(format[14:16], 16)
+	pcm := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(s))
+	}
+	return (&wavAudio{format: format, data: pcm}).encode()
+}
+
+func TestSplitScript(t *testing.T) {
+	parts := splitScript("Knock knock. {sfx: door_knock.wav } Who's there?{sfx:creak.wav}", 0, true)
+	want := []scriptPart{{text: "Knock knock."}, {sfx: "door_knock.wav"}, {text: "Who's there?"}, {sfx: "creak.wav"}}
+	if len(parts) != len(want) {
+		t.Fatalf("splitScript() = %+v", parts)
+	}
+	for i := range want {
+		if parts[i] != want[i] {
+			t.Fatalf("part %d = %+v, want %+v", i, parts[i], want[i])
+		}
+	}
+	// Without a sound directory, markers are text.
+	if parts := splitScript("Knock. {sfx:/etc/hostname}", 0, false); len(parts) != 1 || parts[0].text != "Knock. {sfx:/etc/hostname}" {
+		t.Fatalf("splitScript() = %+v", parts)
+	}
+}
+
+func TestLongFormSynthesize_SoundEffectNames(t *testing.T) {
+	srv, _ := countingTTSServer("")
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	for _, name := range []string{"/etc/hostname", "../secret.wav", "sub/knock.wav", `sub\knock.wav`, "..wav"} {
+		var validationErr *ValidationError
+		_, err := c.LongFormSynthesize(context.Background(), LongFormRequest{
+			Profile:  VoiceProfile{VoiceID: "v"},
+			Text:     "One. {sfx:" + name + "} Two.",
+			SoundDir: t.TempDir(),
+		})
+		if !errors.As(err, &validationErr) || validationErr.Field != "sound_dir" {
+			t.Errorf("%s: expected a validation error, got %v", name, err)
+		}
+	}
+}
+
+func TestLongFormSynthesize_SoundEffectsWAV(t *testing.T) {
+	dir := t.TempDir()
+	// 0.05s of stereo audio at 16 kHz, converted to 8 kHz mono on stitching.
+	samples := make([]int16, 1600)
+	for i := range samples {
+		samples[i] = 1000
+	}
+	if err := os.WriteFile(filepath.Join(dir, "knock.wav"), makeTestStereoWAV(16000, samples...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv, _ := countingTTSServer("")
+	defer srv.Close()
+
+	result, err := newTestClient(srv, "k").LongFormSynthesize(context.Background(), LongFormRequest{
+		Profile:  VoiceProfile{VoiceID: "v"},
+		Text:     "One. {sfx:knock.wav} Two.",
+		SoundDir: dir,
+	})
+	if err != nil {
+		t.Fatalf("LongFormSynthesize() error = %v", err)
+	}
+	if len(result.Segments) != 2 || result.Segments[1].Index != 1 || !approx(result.Segments[1].Start, 0.15) {
+		t.Fatalf("unexpected segments: %+v", result.Segments)
+	}
+	if len(result.SoundEffects) != 1 || result.SoundEffects[0] != (SoundEffect{File: "knock.wav", Start: 0.1, Duration: 0.05}) {
+		t.Fatalf("unexpected sound effects: %+v", result.SoundEffects)
+	}
+	wav, err := decodeWAV(result.AudioData)
+	if err != nil || wav.sampleRate() != 8000 || wav.channels() != 1 || !approx(result.Duration, 0.25) {
+		t.Fatalf("unexpected stitched audio: %v, duration %v", err, result.Duration)
+	}
+	if effect := wav.data[1600:2400]; !bytes.Equal(effect, bytes.Repeat([]byte{0xE8, 0x03}, 400)) {
+		t.Fatal("the effect must be mixed in between the two segments")
+	}
+	if manifest, _ := result.Manifest(); !strings.Contains(string(manifest), `"sound_effects"`) {
+		t.Fatalf("manifest must list the sound effects:\n%s", manifest)
+	}
+
+	// An effect matching the speech format is inserted as is, even alone.
+	if err := os.WriteFile(filepath.Join(dir, "same.wav"), makeTestWAV(make([]byte, 800), 8000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = newTestClient(srv, "k").LongFormSynthesize(context.Background(), LongFormRequest{
+		Profile:  VoiceProfile{VoiceID: "v"},
+		Text:     "{sfx:same.wav} {sfx:knock.wav}",
+		SoundDir: dir,
+	})
+	if err != nil || len(result.Segments) != 0 || !approx(result.Duration, 0.1) {
+		t.Fatalf("unexpected effects-only result: %+v, %v", result, err)
+	}
+
+	eightBit := makeTestWAV(make([]byte, 800), 8000)
+	binary.LittleEndian.PutUint16(eightBit[34:36], 8)
+	speech := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write(eightBit)
+	}))
+	defer speech.Close()
+	_, err = newTestClient(speech, "k").LongFormSynthesize(context.Background(), LongFormRequest{
+		Profile:  VoiceProfile{VoiceID: "v"},
+		Text:     "One. {sfx:same.wav}",
+		SoundDir: dir,
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to stitch audio: sound effects require 16-bit") {
+		t.Fatalf("expected stitch error, got %v", err)
+	}
+}
+
+func TestLongFormSynthesize_SoundEffectsMP3(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "sting.mp3"), makeTestMP3(1), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "sting.wav"), makeTestWAV(make([]byte, 800), 8000), 0644)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write(makeTestMP3(2))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+
+	hls, _ := NewHLSWriter(t.TempDir(), nil)
+	request := LongFormRequest{
+		Profile:  VoiceProfile{VoiceID: "v", AudioFormat: AudioFormatMP3},
+		Text:     "One. {sfx:sting.mp3}",
+		SoundDir: dir,
+		HLS:      hls,
+	}
+	result, err := c.LongFormSynthesize(context.Background(), request)
+	if err != nil {
+		t.Fatalf("LongFormSynthesize() error = %v", err)
+	}
+	if !bytes.Equal(result.AudioData, makeTestMP3(3)) || !approx(result.SoundEffects[0].Duration, 1152.0/44100) {
+		t.Fatalf("unexpected result: %+v", result.SoundEffects)
+	}
+	_ = hls.Close()
+	if segments := hls.Segments(); len(segments) != 1 || !approx(segments[0].Duration, 3*1152.0/44100) {
+		t.Fatalf("effects must be packaged with the speech: %+v", segments)
+	}
+
+	for text, want := range map[string]string{
+		"{sfx:sting.mp3}": "sfx sting.mp3: hls writer is closed",
+		"{sfx:sting.wav}": "sfx sting.wav: mp3 output requires mp3 sound effects",
+		"{sfx:none.mp3}":  "sfx none.mp3:",
+	} {
+		request.Text = text
+		if _, err := c.LongFormSynthesize(context.Background(), request); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected %q, got %v", text, want, err)
+		}
+	}
+}
+
+func TestLoadSoundEffect_Errors(t *testing.T) {
+	dir := t.TempDir()
+	eightBit := makeTestWAV(make([]byte, 8), 8000)
+	binary.LittleEndian.PutUint16(eightBit[34:36], 8)
+	_ = os.WriteFile(filepath.Join(dir, "8bit.wav"), eightBit, 0644)
+	_ = os.WriteFile(filepath.Join(dir, "text.wav"), []byte("not audio"), 0644)
+	for name, want := range map[string]string{
+		"8bit.wav": "only 16-bit mono or stereo PCM wav is supported",
+		"text.wav": "invalid wav",
+	} {
+		if _, _, err := loadSoundEffect(dir, name, AudioFormatWAV); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected %q, got %v", name, want, err)
+		}
+	}
+
+	parts := [][]byte{makeTestWAV(nil, 8000)}
+	if err := matchWAVFormat(parts, []int{0}, []byte("garbage")); err == nil {
+		t.Fatal("expected error for undecodable speech")
+	}
+	if err := matchWAVFormat(parts, []int{0}, eightBit); err == nil || !strings.Contains(err.Error(), "16-bit") {
+		t.Fatalf("expected format error, got %v", err)
+	}
+}
+
+func TestConvertPCM16(t *testing.T) {
+	mono, _ := decodeWAV(makeTestWAV([]byte{0x10, 0x00, 0x30, 0x00}, 8000))
+	// Mono to stereo at twice the rate duplicates channels and interpolates.
+	got := convertPCM16(mono, 2, 16000)
+	want := []byte{0x10, 0, 0x10, 0, 0x20, 0, 0x20, 0, 0x30, 0, 0x30, 0, 0x30, 0, 0x30, 0}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("convertPCM16() = % x, want % x", got, want)
+	}
+	stereo, _ := decodeWAV(makeTestStereoWAV(8000, 100, 300, -100, -300))
+	got = convertPCM16(stereo, 1, 8000)
+	if int16(binary.LittleEndian.Uint16(got)) != 200 || int16(binary.LittleEndian.Uint16(got[2:])) != -200 {
+		t.Fatalf("stereo must be averaged to mono, got % x", got)
+	}
+}
@@ -0,0 +1,50 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// PartialResultError is returned when a streaming synthesis is interrupted,
+// for example by context cancellation, after audio has started to arrive.
+// Audio holds the bytes received before the interruption so callers can
+// decide whether to keep or discard them.
+type PartialResultError struct {
+	// Audio is the audio received before the stream ended
+	Audio []byte
+	// Format is the requested audio format of Audio
+	Format AudioFormat
+	// Err is the error that ended the stream
+	Err error
+}
+
+func (e *PartialResultError) Error() string {
+	return fmt.Sprintf("stream interrupted after %d bytes: %v", len(e.Audio), e.Err)
+}
+
+// Unwrap returns the error that ended the stream.
+func (e *PartialResultError) Unwrap() error {
+	return e.Err
+}
+
+// TextToSpeechStreamCollect runs a streaming synthesis and collects the whole
+// stream into a TTSResponse. If the stream fails midway, the audio received
+// so far is returned inside a *PartialResultError.
+func (c *Client) TextToSpeechStreamCollect(ctx context.Context, request TTSRequestStream) (*TTSResponse, error) {
+	stream, err := c.TextToSpeechStream(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	format := AudioFormatWAV
+	if request.Output != nil && request.Output.AudioFormat != "" {
+		format = request.Output.AudioFormat
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(stream); err != nil {
+		return nil, &PartialResultError{Audio: buf.Bytes(), Format: format, Err: err}
+	}
+	return &TTSResponse{AudioData: buf.Bytes(), Format: format}, nil
+}
@@ -0,0 +1,69 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTextToSpeechStreamCollect_FullStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("chunk-1"))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte("chunk-2"))
+	}))
+	defer srv.Close()
+
+	resp, err := newTestClient(srv, "k").TextToSpeechStreamCollect(context.Background(), TTSRequestStream{
+		VoiceID: "v", Text: "t", Model: ModelSSFMV30, Output: &OutputStream{AudioFormat: AudioFormatMP3},
+	})
+	if err != nil {
+		t.Fatalf("TextToSpeechStreamCollect() error = %v", err)
+	}
+	if string(resp.AudioData) != "chunk-1chunk-2" || resp.Format != AudioFormatMP3 {
+		t.Fatalf("unexpected response: %q %s", resp.AudioData, resp.Format)
+	}
+}
+
+func TestTextToSpeechStreamCollect_CanceledMidStreamReturnsPartialAudio(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("first-half"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := newTestClient(srv, "k").TextToSpeechStreamCollect(ctx, TTSRequestStream{VoiceID: "v", Text: "t", Model: ModelSSFMV30})
+
+	var partial *PartialResultError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected *PartialResultError, got %T %v", err, err)
+	}
+	if string(partial.Audio) != "first-half" || partial.Format != AudioFormatWAV {
+		t.Fatalf("unexpected partial result: %q %s", partial.Audio, partial.Format)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected wrapped context.Canceled, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "stream interrupted after 10 bytes") {
+		t.Fatalf("unexpected message: %v", err)
+	}
+}
+
+func TestTextToSpeechStreamCollect_RequestErrors(t *testing.T) {
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://x"})
+	if _, err := c.TextToSpeechStreamCollect(context.Background(), TTSRequestStream{}); err == nil || !strings.Contains(err.Error(), "voice_id is required") {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package typecast
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// JobSummary is the final tally of a batch or pipeline run, for
+// observability and cost tracking.
+type JobSummary struct {
+	// Items is the number of items recorded
+	Items int `json:"items"`
+	// Succeeded is the number of items that succeeded, including resumed
+	// ones
+	Succeeded int `json:"succeeded"`
+	// Failed is the number of items that failed
+	Failed int `json:"failed"`
+	// Resumed is the number of items loaded from a job store instead of
+	// being synthesized
+	Resumed int `json:"resumed,omitempty"`
+	// FailuresByClass counts the failures by ClassifyError's class
+	FailuresByClass map[ErrorClass]int `json:"failures_by_class,omitempty"`
+	// Characters is the number of characters synthesized, including those
+	// of items whose Handle failed afterwards
+	Characters int `json:"characters"`
+	// AudioSeconds is the total duration of the audio of the items that
+	// succeeded
+	AudioSeconds float64 `json:"audio_seconds"`
+	// EstimatedCost is Characters times the summarizer's cost per
+	// character
+	EstimatedCost float64 `json:"estimated_cost"`
+	// WallTime is the time since the summarizer was created, in seconds
+	WallTime float64 `json:"wall_time"`
+	// AverageLatency is the mean time spent per synthesized item, in
+	// seconds
+	AverageLatency float64 `json:"average_latency"`
+}
+
+// JSON returns the summary as indented JSON.
+func (s JobSummary) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// JobSummarizer tallies the results of a run into a JobSummary. It is safe
+// for concurrent use, so it can be fed from BatchRunner's onResult.
+type JobSummarizer struct {
+	costPerCharacter float64
+	start            time.Time
+	now              func() time.Time
+
+	mu       sync.Mutex
+	summary  JobSummary
+	latency  time.Duration
+	measured int
+}
+
+// NewJobSummarizer starts a summary whose wall time runs from now.
+// costPerCharacter is the price of one character in the caller's currency,
+// used for EstimatedCost; pass 0 to leave it out.
+func NewJobSummarizer(costPerCharacter float64) *JobSummarizer {
+	return &JobSummarizer{costPerCharacter: costPerCharacter, start: time.Now(), now: time.Now}
+}
+
+// Add records the result of an item.
+func (s *JobSummarizer) Add(result BatchResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summary := &s.summary
+	summary.Items++
+	switch {
+	case result.Err != nil:
+		class, _ := ClassifyError(result.Err)
+		if summary.FailuresByClass == nil {
+			summary.FailuresByClass = map[ErrorClass]int{}
+		}
+		summary.Failed++
+		summary.FailuresByClass[class]++
+	case result.Resumed:
+		summary.Succeeded++
+		summary.Resumed++
+	default:
+		summary.Succeeded++
+	}
+	summary.Characters += result.Characters
+	if result.Err == nil && result.Response != nil {
+		summary.AudioSeconds += result.Response.Duration
+	}
+	if result.Elapsed > 0 {
+		s.latency += result.Elapsed
+		s.measured++
+	}
+}
+
+// AddBatch records the results of RunBatch or RerunBatch.
+func (s *JobSummarizer) AddBatch(results []BatchResult) {
+	for _, result := range results {
+		s.Add(result)
+	}
+}
+
+// Summary returns the summary of the results recorded so far.
+func (s *JobSummarizer) Summary() JobSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summary := s.summary
+	if summary.FailuresByClass != nil {
+		summary.FailuresByClass = make(map[ErrorClass]int, len(s.summary.FailuresByClass))
+		for class, n := range s.summary.FailuresByClass {
+			summary.FailuresByClass[class] = n
+		}
+	}
+	summary.EstimatedCost = float64(summary.Characters) * s.costPerCharacter
+	summary.WallTime = s.now().Sub(s.start).Seconds()
+	if s.measured > 0 {
+		summary.AverageLatency = (s.latency / time.Duration(s.measured)).Seconds()
+	}
+	return summary
+}
@@ -0,0 +1,78 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestJobSummarizer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch body.Text {
+		case "bad":
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		case "busy":
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		w.Header().Set("X-Audio-Duration", "1.5")
+		_, _ = w.Write(makeTestWAV(make([]byte, 1600), 8000))
+	}))
+	defer srv.Close()
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, MaxRetries: -1})
+	var items []BatchItem
+	for _, text := range []string{"Hello.", "안녕하세요.", "bad", "busy", "Handle fails."} {
+		items = append(items, BatchItem{ID: text, Request: &TTSRequest{VoiceID: "v", Text: text, Model: ModelSSFMV30}})
+	}
+	summarizer := NewJobSummarizer(0.001)
+	start := summarizer.start
+	summarizer.now = func() time.Time { return start.Add(90 * time.Second) }
+	summarizer.AddBatch(c.RunBatch(context.Background(), items, &BatchOptions{
+		Handle: func(ctx context.Context, item BatchItem, resp *TTSResponse) error {
+			if item.ID == "Handle fails." {
+				return errors.New("disk full")
+			}
+			return nil
+		},
+	}))
+	summarizer.Add(BatchResult{Resumed: true, Response: &TTSResponse{Duration: 2}})
+
+	summary := summarizer.Summary()
+	want := map[ErrorClass]int{ErrorValidation: 1, ErrorRateLimited: 1, ErrorOther: 1}
+	if summary.Items != 6 || summary.Succeeded != 3 || summary.Failed != 3 || summary.Resumed != 1 || len(summary.FailuresByClass) != 3 {
+		t.Fatalf("summary %+v", summary)
+	}
+	for class, n := range want {
+		if summary.FailuresByClass[class] != n {
+			t.Errorf("failures by class %v", summary.FailuresByClass)
+		}
+	}
+	// Hello. (6) + 안녕하세요. (6) + Handle fails. (13), billed though Handle failed.
+	if summary.Characters != 25 || summary.EstimatedCost != 0.025 || summary.AudioSeconds != 5 || summary.WallTime != 90 || summary.AverageLatency <= 0 {
+		t.Fatalf("summary %+v", summary)
+	}
+
+	// The summary is a copy.
+	summary.FailuresByClass[ErrorServer] = 1
+	if _, ok := summarizer.Summary().FailuresByClass[ErrorServer]; ok {
+		t.Fatal("Summary shares its map")
+	}
+	data, err := summary.JSON()
+	var decoded map[string]interface{}
+	if err != nil || json.Unmarshal(data, &decoded) != nil || decoded["characters"] != 25.0 || decoded["failures_by_class"].(map[string]interface{})["validation"] != 1.0 {
+		t.Fatalf("JSON: %s %v", data, err)
+	}
+
+	empty := NewJobSummarizer(0).Summary()
+	if empty.Items != 0 || empty.FailuresByClass != nil || empty.AverageLatency != 0 {
+		t.Fatalf("empty summary %+v", empty)
+	}
+}
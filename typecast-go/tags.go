@@ -0,0 +1,40 @@
+package typecast
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// TagHeader is the request header carrying each tag added with WithTag.
+const TagHeader = "X-Typecast-Tag"
+
+// WithTag returns a context that adds tag, such as "campaign=spring", to the
+// requests made with it, so usage can be attributed to product features.
+// Tags accumulate: each call adds one to those already on ctx. They are sent
+// as X-Typecast-Tag headers, unless ClientConfig.OmitTagHeaders is set, and
+// reported on APIError.Tags and RateLimitState.Tags. Tags that are empty or
+// hold control characters are not sent, but are still reported locally.
+func WithTag(ctx context.Context, tag string) context.Context {
+	tags := TagsFromContext(ctx)
+	return context.WithValue(ctx, tagsContextKey, append(tags[:len(tags):len(tags)], tag))
+}
+
+// TagsFromContext returns the tags added to ctx with WithTag, in order.
+func TagsFromContext(ctx context.Context) []string {
+	tags, _ := ctx.Value(tagsContextKey).([]string)
+	return tags
+}
+
+// setTags copies the request context's tags, if any, into the request
+// headers.
+func (c *Client) setTags(req *http.Request) {
+	if c.omitTagHeaders {
+		return
+	}
+	for _, tag := range TagsFromContext(req.Context()) {
+		if tag != "" && !strings.ContainsAny(tag, "\x00\r\n\x7f") {
+			req.Header.Add(TagHeader, tag)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithTag_SentAndReported(t *testing.T) {
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, strings.Join(r.Header.Values(TagHeader), "|"))
+		w.Header().Set("X-RateLimit-Remaining", "3")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"detail":"overloaded"}`))
+	}))
+	defer srv.Close()
+	var states []RateLimitState
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, MaxRetries: 1, OnRateLimit: func(s RateLimitState) {
+		states = append(states, s)
+	}})
+	c.retryBaseDelay = time.Millisecond
+
+	base := WithTag(context.Background(), "campaign=spring")
+	ctx := WithTag(base, "feature=onboarding")
+	other := WithTag(base, "feature=\r\nX-Injected: 1")
+	if got := TagsFromContext(base); strings.Join(got, ",") != "campaign=spring" {
+		t.Fatalf("expected the parent context to be unchanged, got %v", got)
+	}
+
+	_, err := c.TextToSpeech(ctx, &TTSRequest{VoiceID: "v", Text: "hi", Model: ModelSSFMV30})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || strings.Join(apiErr.Tags, ",") != "campaign=spring,feature=onboarding" {
+		t.Fatalf("expected APIError with tags, got %#v", err)
+	}
+	if strings.Join(seen, ",") != "campaign=spring|feature=onboarding,campaign=spring|feature=onboarding" {
+		t.Fatalf("headers = %q, want the tags on every attempt", seen)
+	}
+	if len(states) != 2 || len(states[0].Tags) != 2 {
+		t.Fatalf("expected tags on rate limit states, got %+v", states)
+	}
+
+	// Tags that cannot be sent as headers are only reported locally.
+	seen = nil
+	_, err = c.TextToSpeech(other, &TTSRequest{VoiceID: "v", Text: "hi", Model: ModelSSFMV30})
+	if !errors.As(err, &apiErr) || len(apiErr.Tags) != 2 || seen[0] != "campaign=spring" {
+		t.Fatalf("unexpected tags %v, headers %q", apiErr.Tags, seen)
+	}
+}
+
+func TestWithTag_OmitTagHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Header[TagHeader]; ok {
+			t.Errorf("unexpected %s header", TagHeader)
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, OmitTagHeaders: true})
+	if _, err := c.GetVoicesV2(WithTag(context.Background(), "campaign=spring"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if tags := TagsFromContext(context.Background()); tags != nil {
+		t.Fatalf("expected no tags, got %v", tags)
+	}
+}
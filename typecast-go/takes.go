@@ -0,0 +1,155 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// takeIntensitySpread is how far GenerateTakes moves the emotion intensity
+// above and below the request's intensity when varying takes by intensity.
+const takeIntensitySpread = 0.4
+
+// Take is one variant of a line produced by GenerateTakes.
+type Take struct {
+	// Index is the take's position, starting at 0
+	Index int `json:"index"`
+	// Seed is the seed used for the take, if any
+	Seed *int `json:"seed,omitempty"`
+	// EmotionIntensity is the intensity used for the take, if varied
+	EmotionIntensity *float64 `json:"emotion_intensity,omitempty"`
+	// Duration is the audio duration in seconds
+	Duration float64 `json:"duration"`
+	// Format is the audio format
+	Format AudioFormat `json:"format"`
+	// AudioData is the take's audio
+	AudioData []byte `json:"-"`
+}
+
+// TakeSet holds the takes generated for one line.
+type TakeSet struct {
+	// Text is the line that was synthesized
+	Text string `json:"text"`
+	// VoiceID is the voice used for every take
+	VoiceID string `json:"voice_id"`
+	// Model is the model used for every take
+	Model TTSModel `json:"model"`
+	// Takes lists the variants in order
+	Takes []Take `json:"takes"`
+}
+
+// Manifest returns the take set as indented JSON, without audio, so a
+// director can review the variants and record the chosen take.
+func (s *TakeSet) Manifest() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// GenerateTakes synthesizes n variants of the same line. With varySeed, take
+// i uses seed Seed+i (Seed defaults to 0); otherwise the emotion intensity is
+// spread evenly within ±0.4 of the request's intensity (default 1.0). Takes
+// are requested concurrently, within the client's concurrency limit.
+func (c *Client) GenerateTakes(ctx context.Context, request *TTSRequest, n int, varySeed bool) (*TakeSet, error) {
+	if request == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	if n < 1 {
+		return nil, newValidationError("n", "n must be at least 1")
+	}
+	requests := make([]*TTSRequest, n)
+	for i := range requests {
+		take := *request
+		if varySeed {
+			seed := i
+			if request.Seed != nil {
+				seed += *request.Seed
+			}
+			take.Seed = &seed
+		} else {
+			prompt, err := promptWithIntensity(request.Model, request.Prompt, takeIntensity(request.Prompt, i, n))
+			if err != nil {
+				return nil, err
+			}
+			take.Prompt = prompt
+		}
+		requests[i] = &take
+	}
+
+	set := &TakeSet{Text: request.Text, VoiceID: request.VoiceID, Model: request.Model, Takes: make([]Take, n)}
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i, take := range requests {
+		wg.Add(1)
+		go func(i int, take *TTSRequest) {
+			defer wg.Done()
+			resp, err := c.TextToSpeech(ctx, take)
+			if err != nil {
+				errs[i] = fmt.Errorf("take %d: %w", i, err)
+				return
+			}
+			var intensity *float64
+			if !varySeed {
+				intensity = promptIntensity(take.Prompt)
+			}
+			set.Takes[i] = Take{
+				Index:            i,
+				Seed:             take.Seed,
+				EmotionIntensity: intensity,
+				Duration:         resp.Duration,
+				Format:           resp.Format,
+				AudioData:        resp.AudioData,
+			}
+		}(i, take)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// takeIntensity spreads take i of n around the prompt's base intensity.
+func takeIntensity(prompt interface{}, i, n int) float64 {
+	base := 1.0
+	if intensity := promptIntensity(prompt); intensity != nil {
+		base = *intensity
+	}
+	low := math.Max(0, base-takeIntensitySpread)
+	high := math.Min(2, base+takeIntensitySpread)
+	return IntensityRamp{From: low, To: high}.At(i, n)
+}
+
+// promptIntensity returns the emotion intensity of a Prompt or PresetPrompt.
+func promptIntensity(prompt interface{}) *float64 {
+	switch p := prompt.(type) {
+	case *Prompt:
+		return p.EmotionIntensity
+	case *PresetPrompt:
+		return p.EmotionIntensity
+	}
+	return nil
+}
+
+// promptWithIntensity copies prompt with its intensity replaced. A nil
+// prompt becomes the model's basic emotion prompt.
+func promptWithIntensity(model TTSModel, prompt interface{}, intensity float64) (interface{}, error) {
+	switch p := prompt.(type) {
+	case nil:
+		if model == ModelSSFMV21 {
+			return &Prompt{EmotionIntensity: &intensity}, nil
+		}
+		return &PresetPrompt{EmotionType: "preset", EmotionIntensity: &intensity}, nil
+	case *Prompt:
+		copied := *p
+		copied.EmotionIntensity = &intensity
+		return &copied, nil
+	case *PresetPrompt:
+		copied := *p
+		copied.EmotionIntensity = &intensity
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("varying takes by intensity requires a *Prompt or *PresetPrompt, got %T", prompt)
+}
@@ -0,0 +1,126 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// takesServer echoes the seed and intensity of each request in the
+// X-Audio-Duration header so tests can match responses to requests.
+func takesServer(t *testing.T) (*httptest.Server, *[]map[string]interface{}) {
+	var mu sync.Mutex
+	var bodies []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+		if body["text"] == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		seed, _ := body["seed"].(float64)
+		w.Header().Set("Content-Type", "audio/wav")
+		w.Header().Set("X-Audio-Duration", strconv.FormatFloat(seed, 'f', -1, 64))
+		_, _ = w.Write([]byte("take"))
+	}))
+	return srv, &bodies
+}
+
+func TestGenerateTakes_VarySeed(t *testing.T) {
+	srv, _ := takesServer(t)
+	defer srv.Close()
+
+	seed := 40
+	set, err := newTestClient(srv, "k").GenerateTakes(context.Background(), &TTSRequest{VoiceID: "v", Text: "Action!", Model: ModelSSFMV30, Seed: &seed}, 3, true)
+	if err != nil {
+		t.Fatalf("GenerateTakes() error = %v", err)
+	}
+	for i, take := range set.Takes {
+		if take.Index != i || *take.Seed != 40+i || take.Duration != float64(40+i) || string(take.AudioData) != "take" {
+			t.Fatalf("unexpected take %d: %+v", i, take)
+		}
+		if take.EmotionIntensity != nil {
+			t.Fatalf("seed takes must not report an intensity: %+v", take)
+		}
+	}
+	manifest, err := set.Manifest()
+	if err != nil {
+		t.Fatalf("Manifest() error = %v", err)
+	}
+	if !strings.Contains(string(manifest), `"seed": 42`) || strings.Contains(string(manifest), "AudioData") {
+		t.Fatalf("unexpected manifest: %s", manifest)
+	}
+
+	set, err = newTestClient(srv, "k").GenerateTakes(context.Background(), &TTSRequest{VoiceID: "v", Text: "Action!", Model: ModelSSFMV30}, 2, true)
+	if err != nil || *set.Takes[0].Seed != 0 || *set.Takes[1].Seed != 1 {
+		t.Fatalf("expected seeds 0 and 1, got %v", err)
+	}
+}
+
+func TestGenerateTakes_VaryIntensity(t *testing.T) {
+	srv, bodies := takesServer(t)
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	ctx := context.Background()
+
+	intensity := 1.8
+	cases := []struct {
+		name   string
+		model  TTSModel
+		prompt interface{}
+		want   []float64
+	}{
+		{"default v30", ModelSSFMV30, nil, []float64{0.6, 1.0, 1.4}},
+		{"default v21", ModelSSFMV21, nil, []float64{0.6, 1.0, 1.4}},
+		{"preset clamped", ModelSSFMV30, &PresetPrompt{EmotionType: "preset", EmotionPreset: EmotionHappy, EmotionIntensity: &intensity}, []float64{1.4, 1.7, 2.0}},
+		{"basic prompt", ModelSSFMV21, &Prompt{EmotionPreset: EmotionSad}, []float64{0.6, 1.0, 1.4}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			set, err := c.GenerateTakes(ctx, &TTSRequest{VoiceID: "v", Text: "Line", Model: tc.model, Prompt: tc.prompt}, 3, false)
+			if err != nil {
+				t.Fatalf("GenerateTakes() error = %v", err)
+			}
+			for i, take := range set.Takes {
+				if !approx(*take.EmotionIntensity, tc.want[i]) {
+					t.Fatalf("take %d intensity = %v, want %v", i, *take.EmotionIntensity, tc.want[i])
+				}
+			}
+		})
+	}
+	if intensity != 1.8 {
+		t.Fatal("caller prompt was mutated")
+	}
+	last := (*bodies)[len(*bodies)-1]["prompt"].(map[string]interface{})
+	if last["emotion_preset"] != "sad" {
+		t.Fatalf("expected preset to be kept, got %v", last)
+	}
+}
+
+func TestGenerateTakes_Errors(t *testing.T) {
+	srv, _ := takesServer(t)
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	ctx := context.Background()
+
+	if _, err := c.GenerateTakes(ctx, nil, 2, true); err == nil || !strings.Contains(err.Error(), "request cannot be nil") {
+		t.Fatalf("expected nil request error, got %v", err)
+	}
+	if _, err := c.GenerateTakes(ctx, &TTSRequest{}, 0, true); err == nil || !strings.Contains(err.Error(), "n must be at least 1") {
+		t.Fatalf("expected n error, got %v", err)
+	}
+	if _, err := c.GenerateTakes(ctx, &TTSRequest{VoiceID: "v", Text: "t", Model: ModelSSFMV30, Prompt: &SmartPrompt{EmotionType: "smart"}}, 2, false); err == nil || !strings.Contains(err.Error(), "*typecast.SmartPrompt") {
+		t.Fatalf("expected prompt type error, got %v", err)
+	}
+	if _, err := c.GenerateTakes(ctx, &TTSRequest{VoiceID: "v", Text: "fail", Model: ModelSSFMV30}, 2, true); err == nil || !strings.Contains(err.Error(), "take 0") {
+		t.Fatalf("expected take error, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// readerBlockSize is how much text SynthesizeFromReader reads at a time.
+const readerBlockSize = 32 * 1024
+
+// ReaderOptions configures SynthesizeFromReader.
+type ReaderOptions struct {
+	// Profile holds the voice and synthesis settings (required)
+	Profile VoiceProfile
+	// MaxChunkChars caps the characters per request (optional, defaults to
+	// 2000)
+	MaxChunkChars int
+	// Lookahead is the number of chunks synthesized at once while earlier
+	// ones are still being delivered (optional, defaults to 2)
+	Lookahead int
+}
+
+// SynthesizeFromReader reads text from r as it is needed, packs whole
+// sentences into chunks of at most MaxChunkChars characters like
+// LongFormSynthesize, and delivers each chunk's audio in order on the
+// returned channel. Only a few chunks of text are held in memory, so
+// manuscripts of any size can be spoken without loading them into a string.
+// A failed chunk is delivered with its Err and does not stop the stream; a
+// read error is delivered as a final chunk with no Text. The channel is
+// closed at the end of r or when ctx is done.
+func (c *Client) SynthesizeFromReader(ctx context.Context, r io.Reader, opts ReaderOptions) <-chan BridgeChunk {
+	maxChars := maxTextLength
+	if opts.MaxChunkChars > 0 && opts.MaxChunkChars < maxTextLength {
+		maxChars = opts.MaxChunkChars
+	}
+	lookahead := defaultBridgeLookahead
+	if opts.Lookahead > 0 {
+		lookahead = opts.Lookahead
+	}
+
+	return c.speakInOrder(ctx, opts.Profile, lookahead, func(emit func(string) bool) error {
+		block := make([]byte, readerBlockSize)
+		var partial []byte // the start of a rune split across reads
+		var text string
+		for {
+			n, err := r.Read(block)
+			data := append(partial, block[:n]...)
+			cut := completeRunes(data)
+			if errors.Is(err, io.EOF) {
+				cut = len(data)
+			}
+			text += string(data[:cut])
+			partial = append([]byte(nil), data[cut:]...)
+
+			if err != nil || utf8.RuneCountInString(text) > 2*maxChars {
+				trailingSpace := strings.TrimRightFunc(text, unicode.IsSpace) != text
+				chunks := splitText(text, maxChars)
+				if err == nil {
+					// The last chunk may end mid-sentence; pack it with
+					// the text that follows.
+					text = ""
+					if len(chunks) > 0 {
+						text = chunks[len(chunks)-1]
+						chunks = chunks[:len(chunks)-1]
+					}
+					if trailingSpace && text != "" {
+						text += " "
+					}
+				}
+				for _, chunk := range chunks {
+					if !emit(chunk) {
+						return nil
+					}
+				}
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// completeRunes returns the length of the longest prefix of b that does not
+// end in the middle of a UTF-8 sequence.
+func completeRunes(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if utf8.FullRune(b[i:]) {
+				return len(b)
+			}
+			return i
+		}
+	}
+	return len(b)
+}
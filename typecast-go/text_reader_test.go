@@ -0,0 +1,134 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf8"
+)
+
+// echoTTSServer returns each request's text as its audio.
+func echoTTSServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte(body.Text))
+	}))
+}
+
+func TestSynthesizeFromReader(t *testing.T) {
+	srv := echoTTSServer()
+	defer srv.Close()
+
+	text := strings.Repeat("첫 문장입니다. Second sentence here! ", 20) + "No terminator at the end"
+	r := iotest.OneByteReader(strings.NewReader(text))
+	var got []string
+	for chunk := range newTestClient(srv, "k").SynthesizeFromReader(context.Background(), r, ReaderOptions{
+		Profile:       VoiceProfile{VoiceID: "v"},
+		MaxChunkChars: 60,
+		Lookahead:     3,
+	}) {
+		if chunk.Err != nil {
+			t.Fatalf("chunk %d: %v", chunk.Index, chunk.Err)
+		}
+		if chunk.Index != len(got) {
+			t.Fatalf("chunk %d delivered out of order", chunk.Index)
+		}
+		if n := utf8.RuneCountInString(chunk.Text); n > 60 {
+			t.Fatalf("chunk %d has %d characters", chunk.Index, n)
+		}
+		if string(chunk.Response.AudioData) != chunk.Text {
+			t.Fatalf("chunk %d audio does not match its text", chunk.Index)
+		}
+		got = append(got, chunk.Text)
+	}
+	if strings.Join(got, " ") != strings.TrimSpace(text) {
+		t.Fatalf("chunks do not restore the text: %q", got)
+	}
+	if len(got) < 10 || !strings.HasSuffix(got[len(got)-1], "No terminator at the end") {
+		t.Fatalf("unexpected chunks %q", got)
+	}
+}
+
+func TestSynthesizeFromReader_Whitespace(t *testing.T) {
+	srv := echoTTSServer()
+	defer srv.Close()
+
+	r := io.MultiReader(strings.NewReader(strings.Repeat(" \n", 100)), strings.NewReader("Hi."))
+	var got []string
+	for chunk := range newTestClient(srv, "k").SynthesizeFromReader(context.Background(), r, ReaderOptions{
+		Profile:       VoiceProfile{VoiceID: "v"},
+		MaxChunkChars: 10,
+	}) {
+		got = append(got, chunk.Text)
+	}
+	if len(got) != 1 || got[0] != "Hi." {
+		t.Fatalf("unexpected chunks %q", got)
+	}
+
+	// A read that ends between words keeps them apart.
+	r = io.MultiReader(strings.NewReader("ab cd ef gh  "), strings.NewReader("ij."))
+	got = nil
+	for chunk := range newTestClient(srv, "k").SynthesizeFromReader(context.Background(), r, ReaderOptions{
+		Profile:       VoiceProfile{VoiceID: "v"},
+		MaxChunkChars: 6,
+	}) {
+		got = append(got, chunk.Text)
+	}
+	if strings.Join(got, "|") != "ab cd|ef gh|ij." {
+		t.Fatalf("unexpected chunks %q", got)
+	}
+}
+
+func TestSynthesizeFromReader_ReadError(t *testing.T) {
+	srv := echoTTSServer()
+	defer srv.Close()
+
+	r := io.MultiReader(strings.NewReader("Before the failure."), errReader{})
+	var got []BridgeChunk
+	for chunk := range newTestClient(srv, "k").SynthesizeFromReader(context.Background(), r, ReaderOptions{Profile: VoiceProfile{VoiceID: "v"}}) {
+		got = append(got, chunk)
+	}
+	if len(got) != 2 || got[0].Text != "Before the failure." || got[1].Index != 1 || got[1].Err == nil || got[1].Err.Error() != "read boom" {
+		t.Fatalf("unexpected chunks %+v", got)
+	}
+}
+
+func TestSynthesizeFromReader_Cancel(t *testing.T) {
+	srv := echoTTSServer()
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	out := newTestClient(srv, "k").SynthesizeFromReader(ctx, io.MultiReader(strings.NewReader("A. B."), errReader{}), ReaderOptions{
+		Profile:       VoiceProfile{VoiceID: "v"},
+		MaxChunkChars: 2,
+	})
+	for range out {
+	}
+}
+
+func TestCompleteRunes(t *testing.T) {
+	word := []byte("한")
+	tests := []struct {
+		b    []byte
+		want int
+	}{
+		{nil, 0},
+		{[]byte("ab"), 2},
+		{append([]byte("a"), word...), 4},
+		{append([]byte("a"), word[:2]...), 1},
+		{[]byte{0x80, 0x80, 0x80, 0x80, 0x80}, 5},
+	}
+	for _, tt := range tests {
+		if got := completeRunes(tt.b); got != tt.want {
+			t.Errorf("completeRunes(%x) = %d, want %d", tt.b, got, tt.want)
+		}
+	}
+}
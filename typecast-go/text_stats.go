@@ -0,0 +1,97 @@
+package typecast
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextStatistics summarizes an input text for cost previews and batch
+// planning.
+type TextStatistics struct {
+	// Characters is the number of Unicode characters, as counted for the
+	// per-request limit and billing
+	Characters int
+	// Graphemes approximates user-perceived characters: combining marks,
+	// emoji modifiers and joined sequences count once
+	Graphemes int
+	// Sentences is the number of sentences
+	Sentences int
+	// Chunks is the number of requests needed when the text is split at
+	// sentence boundaries into pieces within the 2000-character limit
+	Chunks int
+	// Scripts counts letters per writing system (e.g. "Latin", "Hangul",
+	// "Han"), a breakdown of the language mix
+	Scripts map[string]int
+}
+
+// statsScripts are the writing systems reported in TextStatistics.Scripts;
+// letters in other scripts are counted as "Other".
+var statsScripts = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{"Latin", unicode.Latin},
+	{"Hangul", unicode.Hangul},
+	{"Han", unicode.Han},
+	{"Hiragana", unicode.Hiragana},
+	{"Katakana", unicode.Katakana},
+	{"Cyrillic", unicode.Cyrillic},
+	{"Greek", unicode.Greek},
+	{"Arabic", unicode.Arabic},
+	{"Thai", unicode.Thai},
+	{"Devanagari", unicode.Devanagari},
+	{"Bengali", unicode.Bengali},
+	{"Tamil", unicode.Tamil},
+	{"Gurmukhi", unicode.Gurmukhi},
+}
+
+// TextStats computes character, grapheme, sentence and chunk counts and a
+// per-script letter breakdown for text.
+func TextStats(text string) TextStatistics {
+	stats := TextStatistics{
+		Characters: utf8.RuneCountInString(text),
+		Graphemes:  countGraphemes(text),
+		Sentences:  countSentences(text),
+		Chunks:     len(splitText(text, maxTextLength)),
+		Scripts:    map[string]int{},
+	}
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		script := "Other"
+		for _, s := range statsScripts {
+			if unicode.Is(s.table, r) {
+				script = s.name
+				break
+			}
+		}
+		stats.Scripts[script]++
+	}
+	return stats
+}
+
+// countGraphemes approximates extended grapheme clusters without the full
+// Unicode segmentation tables: marks, variation selectors, emoji modifiers,
+// zero-width-joined runes, the second regional indicator of a flag, and
+// Hangul medial/final jamo extend the preceding cluster.
+func countGraphemes(text string) int {
+	count := 0
+	joined := false
+	regional := false
+	for _, r := range text {
+		extends := joined ||
+			unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+			unicode.Is(unicode.Variation_Selector, r) ||
+			(r >= 0x1F3FB && r <= 0x1F3FF) ||
+			(r >= 0x1160 && r <= 0x11FF) ||
+			(regional && r >= 0x1F1E6 && r <= 0x1F1FF)
+		isRegional := r >= 0x1F1E6 && r <= 0x1F1FF
+		regional = isRegional && !(extends && regional)
+		joined = r == 0x200D
+		if !extends && !joined {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,46 @@
+package typecast
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTextStats(t *testing.T) {
+	stats := TextStats("Hello, world! 안녕하세요. Привет мир ۱ ኢ")
+	if stats.Characters != 35 {
+		t.Errorf("Characters = %d", stats.Characters)
+	}
+	if stats.Sentences != 3 {
+		t.Errorf("Sentences = %d", stats.Sentences)
+	}
+	if stats.Chunks != 1 {
+		t.Errorf("Chunks = %d", stats.Chunks)
+	}
+	want := map[string]int{"Latin": 10, "Hangul": 5, "Cyrillic": 9, "Other": 1}
+	if !reflect.DeepEqual(stats.Scripts, want) {
+		t.Errorf("Scripts = %v, want %v", stats.Scripts, want)
+	}
+
+	if got := TextStats(strings.Repeat("This is a sentence. ", 150)).Chunks; got != 2 {
+		t.Errorf("Chunks for 3000 characters = %d", got)
+	}
+}
+
+func TestCountGraphemes(t *testing.T) {
+	cases := map[string]int{
+		"abc":   3,
+		"é":    1, // e + combining acute
+		"👍🏽":    1, // emoji + skin tone modifier
+		"👨‍👩‍👧": 1, // family ZWJ sequence
+		"🇰🇷🇯🇵":  2, // two flags
+		"각":   1, // conjoining Hangul jamo
+		"❤️":    1, // variation selector
+		"한국어":   3,
+	}
+	for text, want := range cases {
+		if got := countGraphemes(text); got != want {
+			t.Errorf("countGraphemes(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package typecast
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"unicode/utf8"
@@ -257,3 +259,62 @@ func formatSRTTime(seconds float64) string {
 func formatVTTTime(seconds float64) string {
 	return strings.Replace(formatSRTTime(seconds), ",", ".", 1)
 }
+
+// TextToSpeechWithTimestamps synthesizes speech and returns base64 audio plus
+// alignment timestamps. The optional granularity parameter ("word", "char", or "")
+// filters the returned alignment arrays.
+func (c *Client) TextToSpeechWithTimestamps(ctx context.Context, request *TTSRequestWithTimestamps, granularity string) (_ *TTSWithTimestampsResponse, err error) {
+	if request == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	if granularity != "" && granularity != "word" && granularity != "char" {
+		return nil, fmt.Errorf("granularity must be empty, \"word\", or \"char\"; got %q", granularity)
+	}
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	voiceID, err := c.synthesisVoiceID(request.VoiceID)
+	if err != nil {
+		return nil, err
+	}
+	text := c.normalizeText(request.Text, request.Language)
+	if language := c.requestLanguage(ctx, voiceID, request.Language, text); voiceID != request.VoiceID || text != request.Text || language != request.Language {
+		resolved := *request
+		resolved.VoiceID, resolved.Text, resolved.Language = voiceID, text, language
+		request = &resolved
+	}
+	path := "/v1/text-to-speech/with-timestamps"
+	if granularity != "" {
+		path = path + "?granularity=" + granularity
+	}
+	refund, err := c.reserveQuota(ctx, request.Text)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			refund()
+		}
+	}()
+	resp, err := c.doRequest(ctx, http.MethodPost, path, request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var out TTSWithTimestampsResponse
+	if err := decodeJSON(resp, "timestamps response", &out); err != nil {
+		// The alignment repeats the text.
+		var decodeErr *DecodeError
+		if redact := c.textRedactor(); redact != nil && errors.As(err, &decodeErr) {
+			decodeErr.Snippet = redact(decodeErr.Snippet)
+		}
+		return nil, err
+	}
+	c.recordUsage(ctx, "/v1/text-to-speech/with-timestamps", request.VoiceID, request.Model, out.AudioDuration, request.Text)
+	return &out, nil
+}
@@ -0,0 +1,89 @@
+package typecast
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// TraceContext holds the W3C Trace Context and Baggage headers of a trace,
+// so Typecast calls appear under the caller's span in distributed traces
+// without full OpenTelemetry instrumentation.
+type TraceContext struct {
+	// TraceParent is the traceparent header, such as
+	// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	TraceParent string
+	// TraceState is the tracestate header (optional)
+	TraceState string
+	// Baggage is the baggage header (optional)
+	Baggage string
+}
+
+// TraceContextFromHeader returns the trace context of an incoming request's
+// headers.
+func TraceContextFromHeader(header http.Header) TraceContext {
+	return TraceContext{
+		TraceParent: header.Get("traceparent"),
+		TraceState:  header.Get("tracestate"),
+		Baggage:     header.Get("baggage"),
+	}
+}
+
+// WithTraceContext returns a context whose requests carry tc's headers:
+//
+//	ctx := typecast.WithTraceContext(r.Context(), typecast.TraceContextFromHeader(r.Header))
+//	audio, err := client.TextToSpeech(ctx, request)
+func WithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey, tc)
+}
+
+// TraceContextFromContext returns the trace context attached to ctx with
+// WithTraceContext, or the zero TraceContext.
+func TraceContextFromContext(ctx context.Context) TraceContext {
+	tc, _ := ctx.Value(traceContextKey).(TraceContext)
+	return tc
+}
+
+// setTraceHeaders copies the trace context of the request context into the
+// request headers. A malformed traceparent is not sent, and neither are
+// tracestate and baggage without it.
+func (c *Client) setTraceHeaders(req *http.Request) {
+	if c.disableTracePropagation {
+		return
+	}
+	tc := TraceContextFromContext(req.Context())
+	if c.traceContext != nil {
+		tc = c.traceContext(req.Context())
+	}
+	if !validTraceParent(tc.TraceParent) {
+		return
+	}
+	req.Header.Set("traceparent", tc.TraceParent)
+	if tc.TraceState != "" && validHeaderValue(tc.TraceState) {
+		req.Header.Set("tracestate", tc.TraceState)
+	}
+	if tc.Baggage != "" && validHeaderValue(tc.Baggage) {
+		req.Header.Set("baggage", tc.Baggage)
+	}
+}
+
+// validTraceParent reports whether s is a version 00 traceparent with
+// nonzero trace and parent IDs.
+func validTraceParent(s string) bool {
+	fields := strings.Split(s, "-")
+	if len(fields) != 4 || fields[0] != "00" {
+		return false
+	}
+	for i, n := range []int{32, 16, 2} {
+		field := fields[i+1]
+		if len(field) != n || strings.Trim(field, "0123456789abcdef") != "" {
+			return false
+		}
+	}
+	return strings.Trim(fields[1], "0") != "" && strings.Trim(fields[2], "0") != ""
+}
+
+// validHeaderValue reports whether s can be sent as a header value.
+func validHeaderValue(s string) bool {
+	return !strings.ContainsAny(s, "\x00\r\n\x7f")
+}
@@ -0,0 +1,70 @@
+package typecast
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestTracePropagation(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	incoming := http.Header{}
+	incoming.Set("traceparent", testTraceParent)
+	incoming.Set("tracestate", "vendor=abc")
+	incoming.Set("baggage", "tenant=acme")
+	ctx := WithTraceContext(context.Background(), TraceContextFromHeader(incoming))
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL})
+	if _, err := c.GetVoicesV2(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("traceparent") != testTraceParent || got.Get("tracestate") != "vendor=abc" || got.Get("baggage") != "tenant=acme" {
+		t.Fatalf("trace headers not propagated: %v", got)
+	}
+
+	// Without a valid traceparent, nothing is sent.
+	for _, tc := range []TraceContext{
+		{},
+		{TraceParent: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", Baggage: "a=b"},
+		{TraceParent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+		{TraceParent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"},
+	} {
+		if _, err := c.GetVoicesV2(WithTraceContext(context.Background(), tc), nil); err != nil {
+			t.Fatal(err)
+		}
+		if got.Get("traceparent") != "" || got.Get("baggage") != "" {
+			t.Fatalf("%+v must not be sent: %v", tc, got)
+		}
+	}
+
+	// Unsafe tracestate and baggage values are dropped.
+	unsafe := TraceContext{TraceParent: testTraceParent, TraceState: "a=b\r\nX-Evil: 1", Baggage: "c=d\n"}
+	if _, err := c.GetVoicesV2(WithTraceContext(context.Background(), unsafe), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("traceparent") != testTraceParent || got.Get("tracestate") != "" || got.Get("baggage") != "" {
+		t.Fatalf("unexpected headers %v", got)
+	}
+
+	// A custom source replaces the context's, and propagation can be disabled.
+	custom := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, TraceContext: func(context.Context) TraceContext {
+		return TraceContext{TraceParent: testTraceParent}
+	}})
+	if _, err := custom.GetVoicesV2(context.Background(), nil); err != nil || got.Get("traceparent") != testTraceParent {
+		t.Fatalf("custom trace context not sent: %v %v", err, got)
+	}
+	disabled := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, DisableTracePropagation: true})
+	if _, err := disabled.GetVoicesV2(ctx, nil); err != nil || got.Get("traceparent") != "" {
+		t.Fatalf("trace headers sent while disabled: %v %v", err, got)
+	}
+}
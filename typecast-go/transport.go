@@ -0,0 +1,109 @@
+package typecast
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// dialFunc dials a network address, like net.Dialer.DialContext.
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// newTransport returns the transport of a client built from config, or nil
+// when the default transport serves it.
+func newTransport(config *ClientConfig) http.RoundTripper {
+	if config.UnixSocket == "" && config.DialContext == nil && config.DNSCacheTTL <= 0 {
+		return nil
+	}
+	dial := dialFunc(config.DialContext)
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	if config.DNSCacheTTL > 0 {
+		dial = newDNSCache(config.DNSCacheTTL, clockOrSystem(config.Clock), net.DefaultResolver.LookupHost).dial(dial)
+	}
+	if socket := config.UnixSocket; socket != "" {
+		next := dial
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return next(ctx, "unix", socket)
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dial
+	return transport
+}
+
+// dnsCache remembers the addresses of hosts for a TTL.
+type dnsCache struct {
+	ttl    time.Duration
+	lookup func(ctx context.Context, host string) ([]string, error)
+	clock  Clock
+
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+}
+
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+func newDNSCache(ttl time.Duration, clock Clock, lookup func(ctx context.Context, host string) ([]string, error)) *dnsCache {
+	return &dnsCache{ttl: ttl, lookup: lookup, clock: clock, entries: map[string]dnsEntry{}}
+}
+
+// resolve returns the cached addresses of host, looking them up when they
+// are missing or expired.
+func (d *dnsCache) resolve(ctx context.Context, host string) ([]string, error) {
+	d.mu.Lock()
+	entry, ok := d.entries[host]
+	d.mu.Unlock()
+	if ok && d.clock.Now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+	addrs, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	d.mu.Lock()
+	d.entries[host] = dnsEntry{addrs: addrs, expires: d.clock.Now().Add(d.ttl)}
+	d.mu.Unlock()
+	return addrs, nil
+}
+
+// forget drops the cached addresses of host, so the next dial resolves it
+// again.
+func (d *dnsCache) forget(host string) {
+	d.mu.Lock()
+	delete(d.entries, host)
+	d.mu.Unlock()
+}
+
+// dial returns a dialFunc that resolves host names through the cache and
+// dials their addresses in order with next until one connects. When none
+// does, the host is forgotten, as its addresses may have changed.
+func (d *dnsCache) dial(next dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return next(ctx, network, addr)
+		}
+		addrs, err := d.resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range addrs {
+			var conn net.Conn
+			if conn, err = next(ctx, network, net.JoinHostPort(ip, port)); err == nil {
+				return conn, nil
+			}
+		}
+		d.forget(host)
+		return nil, err
+	}
+}
@@ -0,0 +1,163 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUnixSocketTransport(t *testing.T) {
+	dir, err := os.MkdirTemp("", "tc") // short, as socket paths are limited
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "gw.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"voice_id":"` + r.Host + `"}]`))
+	}))
+	srv.Listener = listener
+	srv.Start()
+	defer srv.Close()
+
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://typecast.sidecar", UnixSocket: socket})
+	voices, err := c.GetVoicesV2(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(voices) != 1 || voices[0].VoiceID != "typecast.sidecar" {
+		t.Fatalf("unexpected voices %+v", voices)
+	}
+
+	// An explicit HTTPClient wins.
+	custom := &http.Client{}
+	if c := NewClient(&ClientConfig{UnixSocket: socket, HTTPClient: custom}); c.httpClient != custom {
+		t.Fatal("expected the custom HTTP client")
+	}
+}
+
+func TestDialContextAndDNSCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close") // dial for every request
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	var mu sync.Mutex
+	var dialed []string
+	dialer := &net.Dialer{}
+	c := NewClient(&ClientConfig{
+		APIKey:  "k",
+		BaseURL: "http://localhost:" + port,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			mu.Lock()
+			dialed = append(dialed, addr)
+			mu.Unlock()
+			return dialer.DialContext(ctx, network, addr)
+		},
+		DNSCacheTTL: time.Minute,
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetVoicesV2(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dialed) < 2 {
+		t.Fatalf("expected the dial hook to be used, got %v", dialed)
+	}
+	for _, addr := range dialed {
+		if host, _, _ := net.SplitHostPort(addr); net.ParseIP(host) == nil {
+			t.Fatalf("expected resolved addresses to be dialed, got %v", dialed)
+		}
+	}
+}
+
+func TestDNSCache(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	lookups := 0
+	answers := map[string][]string{"api.test": {"10.0.0.1", "10.0.0.2"}, "empty.test": nil}
+	cache := newDNSCache(time.Minute, clock, func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		addrs, ok := answers[host]
+		if !ok {
+			return nil, errors.New("lookup failed")
+		}
+		return addrs, nil
+	})
+
+	var dialed []string
+	down := map[string]bool{"10.0.0.1:443": true}
+	dial := cache.dial(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		if down[addr] || addr == "bad-addr" {
+			return nil, errors.New("connection refused")
+		}
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	})
+	ctx := context.Background()
+
+	// The first address that connects is used, and the lookup is cached.
+	for i := 0; i < 2; i++ {
+		conn, err := dial(ctx, "tcp", "api.test:443")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}
+	if lookups != 1 || strings.Join(dialed, ",") != "10.0.0.1:443,10.0.0.2:443,10.0.0.1:443,10.0.0.2:443" {
+		t.Fatalf("unexpected dials %v after %d lookups", dialed, lookups)
+	}
+
+	// Entries expire.
+	clock.Advance(2 * time.Minute)
+	if conn, err := dial(ctx, "tcp", "api.test:443"); err != nil || lookups != 2 {
+		t.Fatalf("expected a new lookup, got %v after %d lookups", err, lookups)
+	} else {
+		conn.Close()
+	}
+
+	// A host whose addresses all fail is resolved again.
+	down["10.0.0.2:443"] = true
+	if _, err := dial(ctx, "tcp", "api.test:443"); err == nil {
+		t.Fatal("expected a dial error")
+	}
+	if _, err := dial(ctx, "tcp", "api.test:443"); err == nil || lookups != 3 {
+		t.Fatalf("expected the failed host to be resolved again, got %d lookups", lookups)
+	}
+
+	// IP addresses and unparsable addresses are dialed as is.
+	dialed = nil
+	if conn, err := dial(ctx, "tcp", "127.0.0.1:443"); err != nil {
+		t.Fatal(err)
+	} else {
+		conn.Close()
+	}
+	if _, err := dial(ctx, "tcp", "bad-addr"); err == nil || strings.Join(dialed, ",") != "127.0.0.1:443,bad-addr" {
+		t.Fatalf("unexpected dials %v", dialed)
+	}
+
+	var dnsErr *net.DNSError
+	if _, err := dial(ctx, "tcp", "empty.test:443"); !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	if _, err := dial(ctx, "tcp", "missing.test:443"); err == nil {
+		t.Fatal("expected a lookup error")
+	}
+}
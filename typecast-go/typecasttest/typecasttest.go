@@ -0,0 +1,193 @@
+// Package typecasttest provides fakes for testing retry and rate limiting
+// configurations without the real API or real sleeps: a Storm transport
+// that answers with a burst of 429 Too Many Requests, and a Clock that
+// skips the backoff delays the client waits for and records them, so the
+// schedule can be checked with CheckBackoff.
+//
+//	clock := typecasttest.NewClock(time.Now())
+//	storm := &typecasttest.Storm{Limited: 5}
+//	client := typecast.NewClient(&typecast.ClientConfig{
+//		APIKey:     "test",
+//		HTTPClient: &http.Client{Transport: storm},
+//		MaxRetries: 3,
+//		Clock:      clock,
+//	})
+//	_, err := client.TextToSpeech(ctx, request) // fails with the fourth 429
+//	if err := typecasttest.CheckBackoff(clock.Delays(), nil, 0); err != nil {
+//		t.Fatal(err)
+//	}
+package typecasttest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+// Storm is an http.RoundTripper that answers like an API under a rate limit
+// storm: the first Limited requests get Status, and the storm then passes.
+// Use it as the Transport of ClientConfig.HTTPClient. It is safe for
+// concurrent use.
+type Storm struct {
+	// Limited is the number of requests refused before the storm passes
+	// (optional, 0 refuses none)
+	Limited int
+	// Status is the status of the refused requests (optional, defaults to
+	// 429)
+	Status int
+	// RetryAfter is sent as the Retry-After header of the refused
+	// requests, in whole seconds; the client waits for it when it is
+	// longer than the backoff (optional)
+	RetryAfter time.Duration
+	// Next answers the requests after the storm (optional, defaults to a
+	// silent WAV for every request)
+	Next http.RoundTripper
+
+	mu       sync.Mutex
+	requests int
+}
+
+// RoundTrip answers req.
+func (s *Storm) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+	s.mu.Lock()
+	s.requests++
+	refused := s.requests <= s.Limited
+	s.mu.Unlock()
+	if !refused {
+		if s.Next != nil {
+			return s.Next.RoundTrip(req)
+		}
+		resp := response(req, http.StatusOK, "audio/wav", silentWAV)
+		resp.Header.Set("X-Audio-Duration", "0")
+		return resp, nil
+	}
+	status := s.Status
+	if status == 0 {
+		status = http.StatusTooManyRequests
+	}
+	resp := response(req, status, "application/json", []byte(`{"detail":"rate limit exceeded"}`))
+	resp.Header.Set("X-RateLimit-Remaining", "0")
+	if s.RetryAfter > 0 {
+		resp.Header.Set("Retry-After", strconv.Itoa(int(s.RetryAfter/time.Second)))
+	}
+	return resp, nil
+}
+
+// Requests returns the number of requests the storm has received.
+func (s *Storm) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// response returns a response to req with body.
+func response(req *http.Request, status int, contentType string, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {contentType}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// silentWAV is a 16 kHz mono 16-bit WAV without samples.
+var silentWAV = func() []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(36))
+	b.WriteString("WAVEfmt ")
+	_ = binary.Write(&b, binary.LittleEndian, []uint32{16})
+	_ = binary.Write(&b, binary.LittleEndian, []uint16{1, 1})
+	_ = binary.Write(&b, binary.LittleEndian, []uint32{16000, 32000})
+	_ = binary.Write(&b, binary.LittleEndian, []uint16{2, 16})
+	b.WriteString("data")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(0))
+	return b.Bytes()
+}()
+
+// Clock is a typecast.FakeClock that advances itself past each timer
+// created with NewTimer, such as a retry backoff, and records its delay, so
+// a client retrying through a Storm runs instantly. Timers created with
+// AfterFunc, such as those of a TokenBucketLimiter, still wait for Advance.
+// It is meant for one call at a time: advancing for one call's backoff fires
+// the timers of concurrent calls too.
+type Clock struct {
+	*typecast.FakeClock
+
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+// NewClock returns a clock set to start.
+func NewClock(start time.Time) *Clock {
+	return &Clock{FakeClock: typecast.NewFakeClock(start)}
+}
+
+// NewTimer records d and advances the clock by it, so the timer has fired
+// when it is returned.
+func (c *Clock) NewTimer(d time.Duration) typecast.Timer {
+	c.mu.Lock()
+	c.delays = append(c.delays, d)
+	c.mu.Unlock()
+	timer := c.FakeClock.NewTimer(d)
+	c.Advance(d)
+	return timer
+}
+
+// Delays returns the delays of the timers created with NewTimer, in order.
+func (c *Clock) Delays() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.delays...)
+}
+
+// CheckBackoff returns an error unless delays, such as the Delays of a
+// Clock after one call, follow the schedule of backoff, the client's
+// ClientConfig.Backoff or nil for the default. An ExponentialBackoff delay
+// must be within its DelayRange; other Backoffs are taken to have no
+// jitter, as FixedBackoff and FibonacciBackoff do, and their delays must
+// be what Delay returns. Each delay is raised to retryAfter, the
+// Retry-After of the refused responses, such as a Storm's, when it asks
+// for longer.
+func CheckBackoff(delays []time.Duration, backoff typecast.Backoff, retryAfter time.Duration) error {
+	if backoff == nil {
+		backoff = typecast.ExponentialBackoff{}
+	}
+	var previous time.Duration
+	for attempt, delay := range delays {
+		var shortest, longest time.Duration
+		if exponential, ok := backoff.(typecast.ExponentialBackoff); ok {
+			shortest, longest = exponential.DelayRange(attempt)
+		} else {
+			shortest = backoff.Delay(attempt, previous)
+			longest = shortest
+		}
+		previous = longest
+		if shortest < retryAfter {
+			shortest = retryAfter
+		}
+		if longest < retryAfter {
+			longest = retryAfter
+		}
+		if delay < shortest || delay > longest {
+			return fmt.Errorf("backoff %d is %v, want between %v and %v", attempt+1, delay, shortest, longest)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package typecasttest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	typecast "github.com/neosapience/typecast-sdk/typecast-go"
+)
+
+var request = &typecast.TTSRequest{VoiceID: "tc_test", Text: "hello", Model: typecast.ModelSSFMV30}
+
+func newStormClient(storm *Storm, clock *Clock, config typecast.ClientConfig) *typecast.Client {
+	config.APIKey = "test"
+	config.HTTPClient = &http.Client{Transport: storm}
+	config.Clock = clock
+	return typecast.NewClient(&config)
+}
+
+func TestStormOutlastsRetries(t *testing.T) {
+	clock := NewClock(time.Unix(0, 0))
+	storm := &Storm{Limited: 10, RetryAfter: 30 * time.Second}
+	var states []typecast.RateLimitState
+	client := newStormClient(storm, clock, typecast.ClientConfig{
+		MaxRetries:  3,
+		OnRateLimit: func(s typecast.RateLimitState) { states = append(states, s) },
+	})
+
+	_, err := client.TextToSpeech(context.Background(), request)
+	var apiErr *typecast.APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsRateLimited() {
+		t.Fatalf("expected a 429, got %v", err)
+	}
+	if storm.Requests() != 4 || len(clock.Delays()) != 3 {
+		t.Fatalf("%d requests, delays %v", storm.Requests(), clock.Delays())
+	}
+	// Each backoff is raised to the storm's Retry-After.
+	if err := CheckBackoff(clock.Delays(), nil, 30*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	for _, delay := range clock.Delays() {
+		if delay != 30*time.Second {
+			t.Fatalf("expected the Retry-After to be waited for, got delays %v", clock.Delays())
+		}
+	}
+	if err := CheckBackoff(clock.Delays(), nil, 0); err == nil {
+		t.Fatal("expected the delays to exceed the backoff without Retry-After")
+	}
+	var waited time.Duration
+	for _, delay := range clock.Delays() {
+		waited += delay
+	}
+	if elapsed := clock.Now().Sub(time.Unix(0, 0)); elapsed != waited {
+		t.Fatalf("clock advanced %v, want %v", elapsed, waited)
+	}
+	if len(states) != 4 || !states[0].Limited || states[0].Remaining != 0 || states[0].RetryAfter != 30*time.Second {
+		t.Fatalf("unexpected rate limit states %+v", states)
+	}
+}
+
+func TestStormPasses(t *testing.T) {
+	clock := NewClock(time.Unix(0, 0))
+	storm := &Storm{Limited: 2, Status: http.StatusServiceUnavailable}
+	client := newStormClient(storm, clock, typecast.ClientConfig{MaxRetries: 3, VerifyAudioFormat: true})
+
+	resp, err := client.TextToSpeech(context.Background(), request)
+	if err != nil {
+		t.Fatalf("expected the retries to outlast the storm, got %v", err)
+	}
+	if resp.Format != typecast.AudioFormatWAV || storm.Requests() != 3 || len(clock.Delays()) != 2 {
+		t.Fatalf("format %q after %d requests", resp.Format, storm.Requests())
+	}
+	if err := CheckBackoff(clock.Delays(), nil, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStormWithBackoff(t *testing.T) {
+	for _, backoff := range []typecast.Backoff{
+		typecast.ExponentialBackoff{Base: 100 * time.Millisecond, Max: 300 * time.Millisecond},
+		typecast.FibonacciBackoff{Base: 100 * time.Millisecond},
+	} {
+		clock := NewClock(time.Unix(0, 0))
+		storm := &Storm{Limited: 10}
+		client := newStormClient(storm, clock, typecast.ClientConfig{MaxRetries: 4, Backoff: backoff})
+		_, _ = client.TextToSpeech(context.Background(), request)
+		if len(clock.Delays()) != 4 {
+			t.Fatalf("%T: delays %v", backoff, clock.Delays())
+		}
+		if err := CheckBackoff(clock.Delays(), backoff, 0); err != nil {
+			t.Fatalf("%T: %v", backoff, err)
+		}
+		if err := CheckBackoff(clock.Delays(), nil, 0); err == nil {
+			t.Fatalf("%T: expected the default schedule to reject %v", backoff, clock.Delays())
+		}
+	}
+
+	// A Retry-After shorter than the backoff does not change it.
+	clock := NewClock(time.Unix(0, 0))
+	storm := &Storm{Limited: 10, RetryAfter: time.Second}
+	backoff := typecast.FixedBackoff(2 * time.Second)
+	client := newStormClient(storm, clock, typecast.ClientConfig{MaxRetries: 2, Backoff: backoff})
+	_, _ = client.TextToSpeech(context.Background(), request)
+	if err := CheckBackoff(clock.Delays(), backoff, time.Second); err != nil || len(clock.Delays()) != 2 {
+		t.Fatalf("delays %v: %v", clock.Delays(), err)
+	}
+}
+
+func TestStormLimitsByElapsedTimeAndBudget(t *testing.T) {
+	// The second backoff would end past MaxElapsedTime.
+	clock := NewClock(time.Unix(0, 0))
+	storm := &Storm{Limited: 10}
+	client := newStormClient(storm, clock, typecast.ClientConfig{MaxRetries: 5, MaxElapsedTime: 600 * time.Millisecond})
+	if _, err := client.TextToSpeech(context.Background(), request); err == nil {
+		t.Fatal("expected the storm to fail the call")
+	}
+	if storm.Requests() != 2 {
+		t.Fatalf("%d requests, want 2", storm.Requests())
+	}
+
+	// The budget allows one retry across both calls.
+	clock = NewClock(time.Unix(0, 0))
+	storm = &Storm{Limited: 10}
+	client = newStormClient(storm, clock, typecast.ClientConfig{MaxRetries: 5, RetryBudget: typecast.NewRetryBudget(0, 1)})
+	for i := 0; i < 2; i++ {
+		_, _ = client.TextToSpeech(context.Background(), request)
+	}
+	if storm.Requests() != 3 || len(clock.Delays()) != 1 {
+		t.Fatalf("%d requests, delays %v", storm.Requests(), clock.Delays())
+	}
+}
+
+func TestStormNext(t *testing.T) {
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return response(req, http.StatusNotFound, "application/json", []byte(`{"detail":"no such voice"}`)), nil
+	})
+	client := newStormClient(&Storm{Next: next}, NewClock(time.Unix(0, 0)), typecast.ClientConfig{MaxRetries: 3})
+	var apiErr *typecast.APIError
+	if _, err := client.TextToSpeech(context.Background(), request); !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		t.Fatalf("expected Next's 404, got %v", err)
+	}
+}
+
+func TestCheckBackoff(t *testing.T) {
+	if err := CheckBackoff([]time.Duration{300 * time.Millisecond, 900 * time.Millisecond, 1500 * time.Millisecond}, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	err := CheckBackoff([]time.Duration{300 * time.Millisecond, 300 * time.Millisecond}, nil, 0)
+	if err == nil || !strings.Contains(err.Error(), "backoff 2 is 300ms, want between 500ms and 1s") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	err = CheckBackoff([]time.Duration{time.Second, 2 * time.Second}, nil, 2*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "backoff 1 is 1s, want between 2s and 2s") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
@@ -0,0 +1,135 @@
+package typecast
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// WithTenant returns a context that attributes the syntheses made with it
+// to tenant, such as a customer ID, in the records sent to
+// ClientConfig.UsageSink. Platforms serving many customers from one client
+// use it to bill each of them.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey, tenant)
+}
+
+// TenantFromContext returns the tenant attached to ctx with WithTenant, or
+// "".
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantContextKey).(string)
+	return tenant
+}
+
+// UsageSink receives a UsageRecord for each successful synthesis, from the
+// goroutine that made it. Implementations must be safe for concurrent use
+// and should not block, for example by queueing records for a billing
+// store.
+type UsageSink interface {
+	RecordUsage(record UsageRecord)
+}
+
+// UsageRecord is the usage of one successful synthesis.
+type UsageRecord struct {
+	// Tenant is the tenant from WithTenant, or ""
+	Tenant string `json:"tenant"`
+	// Time is when the synthesis completed
+	Time time.Time `json:"time"`
+	// Endpoint is the request path, such as /v1/text-to-speech
+	Endpoint string `json:"endpoint"`
+	// VoiceID is the voice, or "" for composed speech with several
+	VoiceID string `json:"voice_id,omitempty"`
+	// Model is the model, or "" for composed speech
+	Model TTSModel `json:"model,omitempty"`
+	// Characters is the number of characters synthesized, after text
+	// normalization, as charged by the API
+	Characters int `json:"characters"`
+	// AudioSeconds is the duration of the audio; it is 0 for streams, whose
+	// duration is not known when the stream starts
+	AudioSeconds float64 `json:"audio_seconds"`
+	// Tags are the tags from WithTag
+	Tags []string `json:"tags,omitempty"`
+}
+
+// TenantUsage is the usage of one tenant in a MemoryUsageSink.
+type TenantUsage struct {
+	// Tenant is the tenant, or "" for syntheses without one
+	Tenant string `json:"tenant"`
+	// Requests is the number of successful syntheses
+	Requests int `json:"requests"`
+	// Characters is the number of characters synthesized
+	Characters int `json:"characters"`
+	// AudioSeconds is the duration of the audio synthesized
+	AudioSeconds float64 `json:"audio_seconds"`
+}
+
+// MemoryUsageSink is a UsageSink that keeps the records in memory, for
+// tests and for reports from a single process.
+type MemoryUsageSink struct {
+	mu      sync.Mutex
+	records []UsageRecord
+	tenants map[string]*TenantUsage
+}
+
+// NewMemoryUsageSink creates an empty MemoryUsageSink.
+func NewMemoryUsageSink() *MemoryUsageSink {
+	return &MemoryUsageSink{tenants: map[string]*TenantUsage{}}
+}
+
+// RecordUsage adds record.
+func (s *MemoryUsageSink) RecordUsage(record UsageRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	usage, ok := s.tenants[record.Tenant]
+	if !ok {
+		usage = &TenantUsage{Tenant: record.Tenant}
+		s.tenants[record.Tenant] = usage
+	}
+	usage.Requests++
+	usage.Characters += record.Characters
+	usage.AudioSeconds += record.AudioSeconds
+}
+
+// Records returns the records, in the order they were added.
+func (s *MemoryUsageSink) Records() []UsageRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]UsageRecord(nil), s.records...)
+}
+
+// Tenants returns the usage of each tenant, sorted by tenant.
+func (s *MemoryUsageSink) Tenants() []TenantUsage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tenants := make([]TenantUsage, 0, len(s.tenants))
+	for _, usage := range s.tenants {
+		tenants = append(tenants, *usage)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].Tenant < tenants[j].Tenant })
+	return tenants
+}
+
+// recordUsage sends the usage of a successful synthesis of texts to the
+// UsageSink, attributing it to ctx's tenant.
+func (c *Client) recordUsage(ctx context.Context, endpoint, voiceID string, model TTSModel, audioSeconds float64, texts ...string) {
+	if c.usageSink == nil {
+		return
+	}
+	chars := 0
+	for _, text := range texts {
+		chars += utf8.RuneCountInString(text)
+	}
+	c.usageSink.RecordUsage(UsageRecord{
+		Tenant:       TenantFromContext(ctx),
+		Time:         c.clock.Now(),
+		Endpoint:     endpoint,
+		VoiceID:      voiceID,
+		Model:        model,
+		Characters:   chars,
+		AudioSeconds: audioSeconds,
+		Tags:         TagsFromContext(ctx),
+	})
+}
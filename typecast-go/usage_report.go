@@ -0,0 +1,111 @@
+package typecast
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// UsageReport breaks down the usage recorded over a time window by tenant
+// and model, such as a month's consumption to hand to finance.
+type UsageReport struct {
+	// From is the start of the window, inclusive, or zero for none
+	From time.Time `json:"from"`
+	// To is the end of the window, exclusive, or zero for none
+	To time.Time `json:"to"`
+	// Rows are the usage per tenant and model, sorted by tenant and then
+	// model
+	Rows []UsageReportRow `json:"rows"`
+	// Total is the usage of all rows
+	Total UsageReportRow `json:"total"`
+}
+
+// UsageReportRow is the usage of one tenant and model in a UsageReport.
+type UsageReportRow struct {
+	// Tenant is the tenant, or "" for syntheses without one
+	Tenant string `json:"tenant"`
+	// Model is the model, or "" for composed speech
+	Model TTSModel `json:"model"`
+	// Requests is the number of successful syntheses
+	Requests int `json:"requests"`
+	// Characters is the number of characters synthesized
+	Characters int `json:"characters"`
+	// AudioSeconds is the duration of the audio synthesized
+	AudioSeconds float64 `json:"audio_seconds"`
+}
+
+type usageReportKey struct {
+	tenant string
+	model  TTSModel
+}
+
+func (r *UsageReportRow) add(record UsageRecord) {
+	r.Requests++
+	r.Characters += record.Characters
+	r.AudioSeconds += record.AudioSeconds
+}
+
+// NewUsageReport aggregates the records whose Time is in [from, to). A zero
+// from or to leaves that side of the window open.
+func NewUsageReport(records []UsageRecord, from, to time.Time) *UsageReport {
+	report := &UsageReport{From: from, To: to, Rows: []UsageReportRow{}}
+	rows := map[usageReportKey]*UsageReportRow{}
+	for _, record := range records {
+		if (!from.IsZero() && record.Time.Before(from)) || (!to.IsZero() && !record.Time.Before(to)) {
+			continue
+		}
+		key := usageReportKey{record.Tenant, record.Model}
+		row, ok := rows[key]
+		if !ok {
+			row = &UsageReportRow{Tenant: record.Tenant, Model: record.Model}
+			rows[key] = row
+		}
+		row.add(record)
+		report.Total.add(record)
+	}
+	for _, row := range rows {
+		report.Rows = append(report.Rows, *row)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		if report.Rows[i].Tenant != report.Rows[j].Tenant {
+			return report.Rows[i].Tenant < report.Rows[j].Tenant
+		}
+		return report.Rows[i].Model < report.Rows[j].Model
+	})
+	return report
+}
+
+// Report aggregates the records kept by the sink whose Time is in
+// [from, to); see NewUsageReport.
+func (s *MemoryUsageSink) Report(from, to time.Time) *UsageReport {
+	return NewUsageReport(s.Records(), from, to)
+}
+
+// JSON returns the report as indented JSON.
+func (r *UsageReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// usageReportColumns is the header row of UsageReport.CSV.
+var usageReportColumns = []string{"tenant", "model", "requests", "characters", "audio_seconds"}
+
+// CSV returns the rows of the report as CSV with a header row.
+func (r *UsageReport) CSV() ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	_ = w.Write(usageReportColumns)
+	for _, row := range r.Rows {
+		_ = w.Write([]string{
+			row.Tenant,
+			string(row.Model),
+			strconv.Itoa(row.Requests),
+			strconv.Itoa(row.Characters),
+			strconv.FormatFloat(row.AudioSeconds, 'f', 3, 64),
+		})
+	}
+	w.Flush()
+	return b.Bytes(), w.Error()
+}
@@ -0,0 +1,52 @@
+package typecast
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUsageReport(t *testing.T) {
+	may := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	sink := NewMemoryUsageSink()
+	for _, record := range []UsageRecord{
+		{Tenant: "globex", Time: may.Add(time.Hour), Model: ModelSSFMV30, Characters: 10, AudioSeconds: 1},
+		{Tenant: "acme", Time: may, Model: ModelSSFMV30, Characters: 20, AudioSeconds: 2},
+		{Tenant: "acme", Time: may.AddDate(0, 0, 30), Model: ModelSSFMV21, Characters: 5, AudioSeconds: 0.5},
+		{Tenant: "acme", Time: may.AddDate(0, 0, 2), Model: ModelSSFMV30, Characters: 30, AudioSeconds: 3.25},
+		{Tenant: "acme", Time: may.AddDate(0, 1, 0), Model: ModelSSFMV30, Characters: 99},
+		{Tenant: "acme", Time: may.Add(-time.Second), Model: ModelSSFMV30, Characters: 99},
+	} {
+		sink.RecordUsage(record)
+	}
+
+	report := sink.Report(may, may.AddDate(0, 1, 0))
+	csv, err := report.CSV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "tenant,model,requests,characters,audio_seconds\n" +
+		"acme,ssfm-v21,1,5,0.500\n" +
+		"acme,ssfm-v30,2,50,5.250\n" +
+		"globex,ssfm-v30,1,10,1.000\n"
+	if string(csv) != want {
+		t.Fatalf("CSV = %q, want %q", csv, want)
+	}
+	data, err := report.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded UsageReport
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Total.Requests != 4 || decoded.Total.Characters != 65 || !decoded.To.Equal(may.AddDate(0, 1, 0)) {
+		t.Fatalf("unexpected JSON %s, %v", data, err)
+	}
+
+	// An open window covers every record.
+	if all := NewUsageReport(sink.Records(), time.Time{}, time.Time{}); all.Total.Requests != 6 || all.Total.Characters != 263 {
+		t.Fatalf("unexpected total %+v", all.Total)
+	}
+	if empty, _ := NewUsageReport(nil, may, may).JSON(); !strings.Contains(string(empty), `"rows": []`) {
+		t.Fatalf("expected empty rows, got %s", empty)
+	}
+}
@@ -0,0 +1,71 @@
+package typecast
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUsageSink_RecordsPerTenant(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/text-to-speech/with-timestamps":
+			fmt.Fprint(w, `{"audio":"","audio_format":"wav","audio_duration":2.5}`)
+		default:
+			w.Header().Set("X-Audio-Duration", "1.5")
+			_, _ = w.Write([]byte("audio"))
+		}
+	}))
+	defer srv.Close()
+	clock := NewFakeClock(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC))
+	sink := NewMemoryUsageSink()
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Clock: clock, UsageSink: sink})
+	acme := WithTag(WithTenant(context.Background(), "acme"), "feature=reader")
+	globex := WithTenant(context.Background(), "globex")
+
+	if _, err := c.TextToSpeech(acme, &TTSRequest{VoiceID: "tc_1", Text: "héllo", Model: ModelSSFMV30}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.TextToSpeechWithTimestamps(acme, &TTSRequestWithTimestamps{VoiceID: "tc_1", Text: "hi", Model: ModelSSFMV30}, ""); err != nil {
+		t.Fatal(err)
+	}
+	stream, err := c.TextToSpeechStream(globex, TTSRequestStream{VoiceID: "tc_2", Text: "abc", Model: ModelSSFMV21})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Close()
+	if _, err := c.ComposeSpeech().Defaults(ComposerSettings{VoiceID: "tc_1", Model: ModelSSFMV30}).Say("one").Pause(1).Say("two").Generate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.TextToSpeech(acme, nil); err == nil {
+		t.Fatal("expected a nil request to fail")
+	}
+
+	records := sink.Records()
+	if len(records) != 4 {
+		t.Fatalf("expected 4 records, got %+v", records)
+	}
+	first := records[0]
+	if first.Tenant != "acme" || first.Endpoint != "/v1/text-to-speech" || first.VoiceID != "tc_1" || first.Model != ModelSSFMV30 ||
+		first.Characters != 5 || first.AudioSeconds != 1.5 || !first.Time.Equal(clock.Now()) || len(first.Tags) != 1 {
+		t.Fatalf("unexpected record %+v", first)
+	}
+	if composed := records[3]; composed.Endpoint != "/v1/text-to-speech/compose" || composed.VoiceID != "" || composed.Characters != 6 {
+		t.Fatalf("unexpected composed record %+v", composed)
+	}
+	tenants := sink.Tenants()
+	want := []TenantUsage{
+		{Tenant: "", Requests: 1, Characters: 6, AudioSeconds: 1.5},
+		{Tenant: "acme", Requests: 2, Characters: 7, AudioSeconds: 4},
+		{Tenant: "globex", Requests: 1, Characters: 3},
+	}
+	if fmt.Sprint(tenants) != fmt.Sprint(want) {
+		t.Fatalf("tenants = %+v, want %+v", tenants, want)
+	}
+	if TenantFromContext(context.Background()) != "" {
+		t.Fatal("expected no tenant")
+	}
+}
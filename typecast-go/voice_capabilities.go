@@ -0,0 +1,73 @@
+package typecast
+
+import "strings"
+
+// modelPreference ranks known models from newest to oldest for BestModel.
+var modelPreference = []TTSModel{ModelSSFMV30, ModelSSFMV21}
+
+// model returns the ModelInfo for m, if the voice supports it.
+func (v VoiceV2) model(m TTSModel) (ModelInfo, bool) {
+	for _, info := range v.Models {
+		if info.Version == m {
+			return info, true
+		}
+	}
+	return ModelInfo{}, false
+}
+
+// SupportsModel reports whether the voice can be used with model m.
+func (v VoiceV2) SupportsModel(m TTSModel) bool {
+	_, ok := v.model(m)
+	return ok
+}
+
+// SupportsEmotion reports whether the voice supports emotion e with model m.
+func (v VoiceV2) SupportsEmotion(m TTSModel, e EmotionPreset) bool {
+	info, ok := v.model(m)
+	if !ok {
+		return false
+	}
+	for _, emotion := range info.Emotions {
+		if emotion == string(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// BestModel returns the newest model the voice supports. Voices that only
+// list unknown models return the first one; voices without models return "".
+func (v VoiceV2) BestModel() TTSModel {
+	for _, m := range modelPreference {
+		if v.SupportsModel(m) {
+			return m
+		}
+	}
+	if len(v.Models) > 0 {
+		return v.Models[0].Version
+	}
+	return ""
+}
+
+// SupportsLanguage reports whether the voice lists language, an ISO 639-3
+// code such as "kor". It returns false when the API did not report languages.
+func (v VoiceV2) SupportsLanguage(language string) bool {
+	language = strings.TrimSpace(language)
+	for _, l := range v.Languages {
+		if strings.EqualFold(l, language) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTag reports whether the voice carries tag, ignoring case.
+func (v VoiceV2) HasTag(tag string) bool {
+	tag = strings.TrimSpace(tag)
+	for _, t := range v.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
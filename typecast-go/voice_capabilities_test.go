@@ -0,0 +1,47 @@
+package typecast
+
+import "testing"
+
+func TestVoiceV2_Capabilities(t *testing.T) {
+	voice := VoiceV2{
+		VoiceID: "tc_a",
+		Models: []ModelInfo{
+			{Version: ModelSSFMV21, Emotions: []string{"normal", "happy"}},
+			{Version: ModelSSFMV30, Emotions: []string{"normal", "whisper"}},
+		},
+	}
+	if !voice.SupportsModel(ModelSSFMV21) || !voice.SupportsModel(ModelSSFMV30) || voice.SupportsModel("ssfm-v99") {
+		t.Fatal("unexpected SupportsModel results")
+	}
+	if !voice.SupportsEmotion(ModelSSFMV30, EmotionWhisper) || voice.SupportsEmotion(ModelSSFMV21, EmotionWhisper) {
+		t.Fatal("unexpected SupportsEmotion results for whisper")
+	}
+	if voice.SupportsEmotion("ssfm-v99", EmotionNormal) {
+		t.Fatal("unsupported model must not support any emotion")
+	}
+	if got := voice.BestModel(); got != ModelSSFMV30 {
+		t.Fatalf("BestModel() = %s", got)
+	}
+}
+
+func TestVoiceV2_BestModelFallbacks(t *testing.T) {
+	if got := (VoiceV2{Models: []ModelInfo{{Version: ModelSSFMV21}}}).BestModel(); got != ModelSSFMV21 {
+		t.Fatalf("BestModel() = %s", got)
+	}
+	if got := (VoiceV2{Models: []ModelInfo{{Version: "ssfm-next"}}}).BestModel(); got != "ssfm-next" {
+		t.Fatalf("BestModel() = %s", got)
+	}
+	if got := (VoiceV2{}).BestModel(); got != "" {
+		t.Fatalf("BestModel() = %s", got)
+	}
+}
+
+func TestVoiceV2_SupportsLanguage(t *testing.T) {
+	voice := VoiceV2{Languages: []string{"kor", "eng"}}
+	if !voice.SupportsLanguage("KOR") || !voice.SupportsLanguage(" eng ") || voice.SupportsLanguage("spa") {
+		t.Fatal("unexpected SupportsLanguage results")
+	}
+	if (VoiceV2{}).SupportsLanguage("kor") {
+		t.Fatal("voices without languages must not report support")
+	}
+}
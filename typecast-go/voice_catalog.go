@@ -0,0 +1,139 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// voiceCatalogVersion is the version of the snapshot format written by
+// VoiceCatalog.JSON.
+const voiceCatalogVersion = 1
+
+// VoiceCatalog is a snapshot of the voice catalog. Write it to a file to
+// use the catalog offline, diff it against a later snapshot, or commit a
+// pinned catalog so builds do not depend on the live one. The voices are
+// sorted by ID, so snapshots of the same catalog are identical.
+type VoiceCatalog struct {
+	// Version is the version of the snapshot format
+	Version int `json:"version"`
+	// FetchedAt is when the catalog was fetched
+	FetchedAt time.Time `json:"fetched_at"`
+	// Voices are the voices, sorted by ID
+	Voices []VoiceV2 `json:"voices"`
+}
+
+// FetchVoiceCatalog fetches the full V2 voice catalog as a snapshot.
+func (c *Client) FetchVoiceCatalog(ctx context.Context) (*VoiceCatalog, error) {
+	catalog := &VoiceCatalog{Version: voiceCatalogVersion, Voices: []VoiceV2{}}
+	err := c.EachVoiceV2(ctx, nil, func(voice VoiceV2) error {
+		catalog.Voices = append(catalog.Voices, voice)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	catalog.FetchedAt = c.clock.Now().UTC().Truncate(time.Second)
+	sort.Slice(catalog.Voices, func(i, j int) bool { return catalog.Voices[i].VoiceID < catalog.Voices[j].VoiceID })
+	return catalog, nil
+}
+
+// LoadVoiceCatalog reads a snapshot written by VoiceCatalog.JSON.
+func LoadVoiceCatalog(r io.Reader) (*VoiceCatalog, error) {
+	var catalog VoiceCatalog
+	if err := json.NewDecoder(r).Decode(&catalog); err != nil {
+		return nil, fmt.Errorf("failed to decode voice catalog: %w", err)
+	}
+	if catalog.Version != voiceCatalogVersion {
+		return nil, fmt.Errorf("unsupported voice catalog version %d", catalog.Version)
+	}
+	sort.Slice(catalog.Voices, func(i, j int) bool { return catalog.Voices[i].VoiceID < catalog.Voices[j].VoiceID })
+	return &catalog, nil
+}
+
+// LoadVoiceCatalogFile reads a snapshot from path.
+func LoadVoiceCatalogFile(path string) (*VoiceCatalog, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open voice catalog: %w", err)
+	}
+	defer f.Close()
+	return LoadVoiceCatalog(f)
+}
+
+// JSON returns the snapshot as indented JSON.
+func (v *VoiceCatalog) JSON() ([]byte, error) {
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// WriteFile writes the snapshot to path.
+func (v *VoiceCatalog) WriteFile(path string) error {
+	data, err := v.JSON()
+	if err == nil {
+		err = writeFileAtomic(path, append(data, '\n'))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write voice catalog: %w", err)
+	}
+	return nil
+}
+
+// Voice returns the voice with voiceID.
+func (v *VoiceCatalog) Voice(voiceID string) (VoiceV2, bool) {
+	i := sort.Search(len(v.Voices), func(i int) bool { return v.Voices[i].VoiceID >= voiceID })
+	if i < len(v.Voices) && v.Voices[i].VoiceID == voiceID {
+		return v.Voices[i], true
+	}
+	return VoiceV2{}, false
+}
+
+// VoiceCatalogDiff lists the differences between two catalog snapshots.
+type VoiceCatalogDiff struct {
+	// Added are the voices only in the newer catalog
+	Added []VoiceV2 `json:"added"`
+	// Removed are the voices only in the older catalog
+	Removed []VoiceV2 `json:"removed"`
+	// Changed are the voices in both whose metadata differs
+	Changed []VoiceChange `json:"changed"`
+}
+
+// VoiceChange is a voice whose metadata changed between two catalogs.
+type VoiceChange struct {
+	// Before is the voice in the older catalog
+	Before VoiceV2 `json:"before"`
+	// After is the voice in the newer catalog
+	After VoiceV2 `json:"after"`
+}
+
+// Empty reports whether the catalogs are the same.
+func (d VoiceCatalogDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff returns what changed from v to newer, each list sorted by voice ID.
+func (v *VoiceCatalog) Diff(newer *VoiceCatalog) VoiceCatalogDiff {
+	diff := VoiceCatalogDiff{Added: []VoiceV2{}, Removed: []VoiceV2{}, Changed: []VoiceChange{}}
+	for _, before := range v.Voices {
+		after, ok := newer.Voice(before.VoiceID)
+		if !ok {
+			diff.Removed = append(diff.Removed, before)
+			continue
+		}
+		b, _ := json.Marshal(before)
+		a, _ := json.Marshal(after)
+		if !bytes.Equal(a, b) {
+			diff.Changed = append(diff.Changed, VoiceChange{Before: before, After: after})
+		}
+	}
+	for _, after := range newer.Voices {
+		if _, ok := v.Voice(after.VoiceID); !ok {
+			diff.Added = append(diff.Added, after)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,97 @@
+package typecast
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVoiceCatalog_RoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"voice_id":"tc_2","voice_name":"Ben","models":[]},{"voice_id":"tc_1","voice_name":"Ann","models":[{"version":"ssfm-v30","emotions":["normal"]}]}]`)
+	}))
+	defer srv.Close()
+	clock := NewFakeClock(time.Date(2026, 3, 1, 12, 0, 0, 500, time.UTC))
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, Clock: clock})
+
+	catalog, err := c.FetchVoiceCatalog(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog.Voices) != 2 || catalog.Voices[0].VoiceID != "tc_1" || !catalog.FetchedAt.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected catalog %+v", catalog)
+	}
+	path := filepath.Join(t.TempDir(), "voices.json")
+	if err := catalog.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadVoiceCatalogFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := catalog.JSON()
+	got, _ := loaded.JSON()
+	if !bytes.Equal(got, want) {
+		t.Fatalf("snapshot changed on reload:\n%s\nwant\n%s", got, want)
+	}
+	if voice, ok := loaded.Voice("tc_2"); !ok || voice.VoiceName != "Ben" {
+		t.Fatalf("expected tc_2, got %+v", voice)
+	}
+	if _, ok := loaded.Voice("tc_3"); ok {
+		t.Fatal("expected tc_3 to be missing")
+	}
+	if diff := catalog.Diff(loaded); !diff.Empty() {
+		t.Fatalf("expected no differences, got %+v", diff)
+	}
+
+	srv.Close()
+	if _, err := c.FetchVoiceCatalog(context.Background()); err == nil {
+		t.Fatal("expected a fetch error")
+	}
+}
+
+func TestVoiceCatalog_Diff(t *testing.T) {
+	older, err := LoadVoiceCatalog(strings.NewReader(`{"version":1,"voices":[
+		{"voice_id":"tc_3","voice_name":"Cid"},
+		{"voice_id":"tc_1","voice_name":"Ann"},
+		{"voice_id":"tc_2","voice_name":"Ben","tags":["warm"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newer, err := LoadVoiceCatalog(strings.NewReader(`{"version":1,"voices":[
+		{"voice_id":"tc_2","voice_name":"Ben","tags":["warm","calm"]},
+		{"voice_id":"tc_4","voice_name":"Dee"},
+		{"voice_id":"tc_1","voice_name":"Ann"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := older.Diff(newer)
+	if len(diff.Added) != 1 || diff.Added[0].VoiceID != "tc_4" ||
+		len(diff.Removed) != 1 || diff.Removed[0].VoiceID != "tc_3" ||
+		len(diff.Changed) != 1 || len(diff.Changed[0].After.Tags) != 2 || len(diff.Changed[0].Before.Tags) != 1 || diff.Empty() {
+		t.Fatalf("unexpected diff %+v", diff)
+	}
+}
+
+func TestVoiceCatalog_Errors(t *testing.T) {
+	if _, err := LoadVoiceCatalog(strings.NewReader(`[`)); err == nil || !strings.Contains(err.Error(), "failed to decode voice catalog") {
+		t.Fatalf("expected a decode error, got %v", err)
+	}
+	if _, err := LoadVoiceCatalog(strings.NewReader(`{"version":2}`)); err == nil || !strings.Contains(err.Error(), "unsupported voice catalog version 2") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+	dir := t.TempDir()
+	if _, err := LoadVoiceCatalogFile(filepath.Join(dir, "missing.json")); err == nil || !strings.Contains(err.Error(), "failed to open voice catalog") {
+		t.Fatalf("expected an open error, got %v", err)
+	}
+	catalog := &VoiceCatalog{Version: voiceCatalogVersion}
+	if err := catalog.WriteFile(filepath.Join(dir, "missing", "voices.json")); err == nil || !strings.Contains(err.Error(), "failed to write voice catalog") {
+		t.Fatalf("expected a write error, got %v", err)
+	}
+}
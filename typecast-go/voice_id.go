@@ -0,0 +1,72 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// voiceIDHexLength is the number of hexadecimal characters after a voice
+// ID's tc_ or uc_ prefix.
+const voiceIDHexLength = 24
+
+// ValidateVoiceID checks that id has the format of a Typecast voice ID: tc_
+// (or uc_ for custom voices) followed by 24 lowercase hexadecimal
+// characters. It does not check that the voice exists; see VerifyVoices.
+func ValidateVoiceID(id string) error {
+	hex := strings.TrimPrefix(strings.TrimPrefix(id, "tc_"), "uc_")
+	valid := len(hex) == voiceIDHexLength && len(id) == len(hex)+3
+	for i := 0; valid && i < len(hex); i++ {
+		valid = (hex[i] >= '0' && hex[i] <= '9') || (hex[i] >= 'a' && hex[i] <= 'f')
+	}
+	if !valid {
+		return newValidationError("voice_id", fmt.Sprintf("voice_id %q is not a valid voice ID: expected tc_ or uc_ followed by %d hexadecimal characters", id, voiceIDHexLength))
+	}
+	return nil
+}
+
+// synthesisVoiceID resolves the voice ID or alias of a synthesis request
+// and, with ValidateVoiceIDs, checks it before the request is sent.
+func (c *Client) synthesisVoiceID(voiceID string) (string, error) {
+	voiceID = c.resolveVoiceID(voiceID)
+	if c.validateVoiceIDs {
+		if err := ValidateVoiceID(voiceID); err != nil {
+			return "", err
+		}
+	}
+	return voiceID, nil
+}
+
+// VerifyVoices checks, before a batch run, that each voice ID (or alias) is
+// well formed and exists, with one voice lookup per distinct voice. IDs the
+// API does not know are reported together in one *ValidationError; other
+// failures are returned as is.
+func (c *Client) VerifyVoices(ctx context.Context, voiceIDs []string) error {
+	seen := map[string]bool{}
+	var unknown []string
+	for _, id := range voiceIDs {
+		id = c.resolveVoiceID(id)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if err := ValidateVoiceID(id); err != nil {
+			return err
+		}
+		if _, err := c.GetVoiceV2(ctx, id); err != nil {
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+				return err
+			}
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return newValidationError("voice_id", fmt.Sprintf("unknown voice IDs: %s", strings.Join(unknown, ", ")))
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidateVoiceID(t *testing.T) {
+	valid := []string{"tc_60e5426de8b95f1d3000d7b5", "uc_64a1b2c3d4e5f6a7b8c9d0e1"}
+	invalid := []string{"", "tc_", "60e5426de8b95f1d3000d7b5", "tc_60e5426de8b95f1d3000d7b", "tc_60e5426de8b95f1d3000d7b5a",
+		"tc_60E5426DE8B95F1D3000D7B5", "tc_60e5426de8b95f1d3000d7bz", "xc_60e5426de8b95f1d3000d7b5", "tc_uc_60e5426de8b95f1d3000d7"}
+	for _, id := range valid {
+		if err := ValidateVoiceID(id); err != nil {
+			t.Errorf("ValidateVoiceID(%q) = %v", id, err)
+		}
+	}
+	for _, id := range invalid {
+		var validationErr *ValidationError
+		if err := ValidateVoiceID(id); !errors.As(err, &validationErr) || validationErr.Field != "voice_id" {
+			t.Errorf("ValidateVoiceID(%q): expected a voice_id error, got %v", id, err)
+		}
+	}
+}
+
+func TestValidateVoiceIDsOption(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte("RIFF"))
+	}))
+	defer srv.Close()
+	registry, _ := NewVoiceRegistry(map[string]string{"narrator": "tc_60e5426de8b95f1d3000d7b5"})
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, ValidateVoiceIDs: true, VoiceAliases: registry})
+
+	if _, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "tc_60e5426de8b95f1d3000d7", Text: "hi", Model: ModelSSFMV30}); err == nil || calls != 0 {
+		t.Fatalf("expected a local error, got %v after %d calls", err, calls)
+	}
+	if _, err := c.TextToSpeech(context.Background(), &TTSRequest{VoiceID: "narrator", Text: "hi", Model: ModelSSFMV30}); err != nil || calls != 1 {
+		t.Fatalf("expected an alias of a valid ID to be sent, got %v after %d calls", err, calls)
+	}
+
+	// Every synthesis endpoint checks the voice ID before sending.
+	ctx, bad := context.Background(), "tc_60e5426de8b95f1d3000d7"
+	var validationErr *ValidationError
+	if _, err := c.TextToSpeechWithTimestamps(ctx, &TTSRequestWithTimestamps{VoiceID: bad, Text: "hi", Model: ModelSSFMV30}, ""); !errors.As(err, &validationErr) {
+		t.Fatalf("timestamps: expected a validation error, got %v", err)
+	}
+	if _, err := c.TextToSpeechStream(ctx, TTSRequestStream{VoiceID: bad, Text: "hi", Model: ModelSSFMV30}); !errors.As(err, &validationErr) {
+		t.Fatalf("stream: expected a validation error, got %v", err)
+	}
+	if _, err := c.ComposeSpeech().Defaults(ComposerSettings{VoiceID: bad, Model: ModelSSFMV30}).Say("hi").Generate(ctx); !errors.As(err, &validationErr) {
+		t.Fatalf("composer: expected a validation error, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected no more requests, got %d", calls)
+	}
+}
+
+// verifyServer knows the voices in known and fails lookups of broken.
+func verifyServer(t *testing.T, known, broken string) (*httptest.Server, *int) {
+	lookups := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/v2/voices/")
+		if r.URL.Path == "/v1/text-to-speech" {
+			_, _ = w.Write([]byte("RIFF"))
+			return
+		}
+		lookups++
+		switch {
+		case strings.Contains(known, id):
+			_, _ = w.Write([]byte(`{"voice_id":"` + id + `"}`))
+		case id == broken:
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &lookups
+}
+
+func TestVerifyVoices(t *testing.T) {
+	const (
+		a = "tc_aaaaaaaaaaaaaaaaaaaaaaaa"
+		b = "tc_bbbbbbbbbbbbbbbbbbbbbbbb"
+		x = "tc_ffffffffffffffffffffffff"
+		y = "uc_eeeeeeeeeeeeeeeeeeeeeeee"
+		z = "tc_dddddddddddddddddddddddd"
+	)
+	srv, lookups := verifyServer(t, a+b, z)
+	c := newTestClient(srv, "k")
+	ctx := context.Background()
+
+	if err := c.VerifyVoices(ctx, []string{a, b, a}); err != nil || *lookups != 2 {
+		t.Fatalf("expected each voice to be looked up once, got %v after %d lookups", err, *lookups)
+	}
+	var validationErr *ValidationError
+	err := c.VerifyVoices(ctx, []string{y, a, x})
+	if !errors.As(err, &validationErr) || validationErr.Message != "unknown voice IDs: "+x+", "+y {
+		t.Fatalf("expected the unknown voices to be listed, got %v", err)
+	}
+	if err := c.VerifyVoices(ctx, []string{"tc_typo"}); !errors.As(err, &validationErr) || !strings.Contains(err.Error(), "not a valid voice ID") {
+		t.Fatalf("expected a format error, got %v", err)
+	}
+	var apiErr *APIError
+	if err := c.VerifyVoices(ctx, []string{z}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected the lookup error, got %v", err)
+	}
+}
+
+func TestRunBatchVerifyVoices(t *testing.T) {
+	srv, _ := verifyServer(t, "tc_aaaaaaaaaaaaaaaaaaaaaaaa", "")
+	c := newTestClient(srv, "k")
+	items := []BatchItem{
+		{ID: "ok", Request: &TTSRequest{VoiceID: "tc_aaaaaaaaaaaaaaaaaaaaaaaa", Text: "hi", Model: ModelSSFMV30}},
+		{ID: "nil"},
+	}
+	results := c.RunBatch(context.Background(), items, &BatchOptions{VerifyVoices: true})
+	if results[0].Err != nil {
+		t.Fatalf("expected the verified batch to run, got %v", results[0].Err)
+	}
+
+	items = append(items, BatchItem{ID: "typo", Request: &TTSRequest{VoiceID: "tc_bbbbbbbbbbbbbbbbbbbbbbbb", Text: "hi", Model: ModelSSFMV30}})
+	for _, r := range c.RunBatch(context.Background(), items, &BatchOptions{VerifyVoices: true}) {
+		if r.Err == nil || !strings.Contains(r.Err.Error(), "unknown voice IDs") {
+			t.Fatalf("expected every item to fail verification, got %v", r.Err)
+		}
+	}
+}
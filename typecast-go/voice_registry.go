@@ -0,0 +1,107 @@
+package typecast
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// VoiceRegistry maps friendly aliases (e.g., "narrator", "support-agent-ko")
+// to voice IDs. Aliases are matched case-insensitively. A VoiceRegistry is
+// safe for concurrent use.
+type VoiceRegistry struct {
+	mu      sync.RWMutex
+	aliases map[string]string
+}
+
+// NewVoiceRegistry creates a registry from an alias-to-voice-ID map.
+func NewVoiceRegistry(aliases map[string]string) (*VoiceRegistry, error) {
+	r := &VoiceRegistry{aliases: map[string]string{}}
+	for alias, voiceID := range aliases {
+		if err := r.Register(alias, voiceID); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// LoadVoiceRegistry reads a JSON object of alias-to-voice-ID pairs, e.g.
+// {"narrator": "tc_672c5f5ce59fac2a48faeaee"}.
+func LoadVoiceRegistry(r io.Reader) (*VoiceRegistry, error) {
+	var aliases map[string]string
+	if err := json.NewDecoder(r).Decode(&aliases); err != nil {
+		return nil, fmt.Errorf("failed to decode voice aliases: %w", err)
+	}
+	return NewVoiceRegistry(aliases)
+}
+
+// LoadVoiceRegistryFile reads a JSON alias file from path.
+func LoadVoiceRegistryFile(path string) (*VoiceRegistry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open voice aliases: %w", err)
+	}
+	defer f.Close()
+	return LoadVoiceRegistry(f)
+}
+
+// Register adds or replaces an alias.
+func (r *VoiceRegistry) Register(alias, voiceID string) error {
+	key := normalizeAlias(alias)
+	if key == "" {
+		return fmt.Errorf("alias cannot be empty")
+	}
+	voiceID = strings.TrimSpace(voiceID)
+	if voiceID == "" {
+		return fmt.Errorf("voice_id for alias %q cannot be empty", alias)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aliases[key] = voiceID
+	return nil
+}
+
+// Lookup returns the voice ID registered for alias.
+func (r *VoiceRegistry) Lookup(alias string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	voiceID, ok := r.aliases[normalizeAlias(alias)]
+	return voiceID, ok
+}
+
+// Resolve returns the voice ID for nameOrID when it is a registered alias,
+// and nameOrID unchanged otherwise.
+func (r *VoiceRegistry) Resolve(nameOrID string) string {
+	if voiceID, ok := r.Lookup(nameOrID); ok {
+		return voiceID
+	}
+	return nameOrID
+}
+
+// Aliases returns the registered aliases sorted by name, for auditing.
+func (r *VoiceRegistry) Aliases() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.aliases))
+	for alias := range r.aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func normalizeAlias(alias string) string {
+	return strings.ToLower(strings.TrimSpace(alias))
+}
+
+// resolveVoiceID maps a configured alias to its voice ID.
+func (c *Client) resolveVoiceID(voiceID string) string {
+	if c.voiceAliases == nil {
+		return voiceID
+	}
+	return c.voiceAliases.Resolve(voiceID)
+}
@@ -0,0 +1,140 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVoiceRegistry_RegisterLookupResolve(t *testing.T) {
+	r, err := NewVoiceRegistry(map[string]string{"Narrator": " tc_narrator "})
+	if err != nil {
+		t.Fatalf("NewVoiceRegistry() error = %v", err)
+	}
+	if err := r.Register("support-agent-ko", "tc_agent"); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if id, ok := r.Lookup(" NARRATOR "); !ok || id != "tc_narrator" {
+		t.Fatalf("Lookup() = %q, %v", id, ok)
+	}
+	if got := r.Resolve("support-agent-ko"); got != "tc_agent" {
+		t.Fatalf("Resolve(alias) = %q", got)
+	}
+	if got := r.Resolve("tc_raw"); got != "tc_raw" {
+		t.Fatalf("Resolve(id) = %q", got)
+	}
+	if got := r.Aliases(); !reflect.DeepEqual(got, []string{"narrator", "support-agent-ko"}) {
+		t.Fatalf("Aliases() = %v", got)
+	}
+}
+
+func TestVoiceRegistry_RegisterErrors(t *testing.T) {
+	if _, err := NewVoiceRegistry(map[string]string{" ": "tc_a"}); err == nil || !strings.Contains(err.Error(), "alias cannot be empty") {
+		t.Fatalf("expected empty alias error, got %v", err)
+	}
+	if _, err := NewVoiceRegistry(map[string]string{"narrator": ""}); err == nil || !strings.Contains(err.Error(), "cannot be empty") {
+		t.Fatalf("expected empty voice_id error, got %v", err)
+	}
+}
+
+func TestLoadVoiceRegistry(t *testing.T) {
+	r, err := LoadVoiceRegistry(strings.NewReader(`{"narrator":"tc_narrator"}`))
+	if err != nil {
+		t.Fatalf("LoadVoiceRegistry() error = %v", err)
+	}
+	if got := r.Resolve("narrator"); got != "tc_narrator" {
+		t.Fatalf("Resolve() = %q", got)
+	}
+	if _, err := LoadVoiceRegistry(strings.NewReader(`[]`)); err == nil || !strings.Contains(err.Error(), "failed to decode voice aliases") {
+		t.Fatalf("expected decode error, got %v", err)
+	}
+}
+
+func TestLoadVoiceRegistryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "voices.json")
+	if err := os.WriteFile(path, []byte(`{"host":"tc_host"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := LoadVoiceRegistryFile(path)
+	if err != nil {
+		t.Fatalf("LoadVoiceRegistryFile() error = %v", err)
+	}
+	if got := r.Resolve("host"); got != "tc_host" {
+		t.Fatalf("Resolve() = %q", got)
+	}
+	if _, err := LoadVoiceRegistryFile(filepath.Join(t.TempDir(), "missing.json")); err == nil || !strings.Contains(err.Error(), "failed to open voice aliases") {
+		t.Fatalf("expected open error, got %v", err)
+	}
+}
+
+func TestClient_ResolvesVoiceAliases(t *testing.T) {
+	var voiceIDs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/voices/tc_narrator":
+			voiceIDs = append(voiceIDs, "tc_narrator")
+			_ = json.NewEncoder(w).Encode(VoiceV2{VoiceID: "tc_narrator"})
+			return
+		case "/v1/text-to-speech/compose":
+			var body struct {
+				Segments []struct {
+					VoiceID string `json:"voice_id"`
+				} `json:"segments"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			voiceIDs = append(voiceIDs, body.Segments[0].VoiceID)
+		default:
+			var body struct {
+				VoiceID string `json:"voice_id"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			voiceIDs = append(voiceIDs, body.VoiceID)
+		}
+		if r.URL.Path == "/v1/text-to-speech/with-timestamps" {
+			_ = json.NewEncoder(w).Encode(TTSWithTimestampsResponse{})
+			return
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte("WAV"))
+	}))
+	defer srv.Close()
+
+	registry, _ := NewVoiceRegistry(map[string]string{"narrator": "tc_narrator"})
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: srv.URL, VoiceAliases: registry})
+	ctx := context.Background()
+
+	request := &TTSRequest{VoiceID: "narrator", Text: "hi", Model: ModelSSFMV30}
+	if _, err := c.TextToSpeech(ctx, request); err != nil {
+		t.Fatalf("TextToSpeech() error = %v", err)
+	}
+	if request.VoiceID != "narrator" {
+		t.Fatalf("caller request was mutated: %q", request.VoiceID)
+	}
+	if _, err := c.TextToSpeechWithTimestamps(ctx, &TTSRequestWithTimestamps{VoiceID: "narrator", Text: "hi", Model: ModelSSFMV30}, ""); err != nil {
+		t.Fatalf("TextToSpeechWithTimestamps() error = %v", err)
+	}
+	stream, err := c.TextToSpeechStream(ctx, TTSRequestStream{VoiceID: "narrator", Text: "hi", Model: ModelSSFMV30})
+	if err != nil {
+		t.Fatalf("TextToSpeechStream() error = %v", err)
+	}
+	_, _ = io.ReadAll(stream)
+	stream.Close()
+	if _, err := c.GetVoiceV2(ctx, "narrator"); err != nil {
+		t.Fatalf("GetVoiceV2() error = %v", err)
+	}
+	if _, err := c.ComposeSpeech().SayWith("hi", ComposerSettings{VoiceID: "narrator", Model: ModelSSFMV30}).Generate(ctx); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	want := []string{"tc_narrator", "tc_narrator", "tc_narrator", "tc_narrator", "tc_narrator"}
+	if !reflect.DeepEqual(voiceIDs, want) {
+		t.Fatalf("voice IDs sent = %v, want %v", voiceIDs, want)
+	}
+}
@@ -0,0 +1,146 @@
+package typecast
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minVoiceMatchScore is the lowest fuzzy score FindVoiceByName returns.
+const minVoiceMatchScore = 0.5
+
+// VoiceMatch is a voice ranked by how well its name matches a query.
+type VoiceMatch struct {
+	// Voice is the matched voice
+	Voice VoiceV2
+	// Score is the match quality from 0 to 1; 1 is an exact match
+	Score float64
+}
+
+// diacriticFolds maps accented Latin letters to their base letters.
+var diacriticFolds = map[rune]string{}
+
+func init() {
+	for base, accented := range map[string]string{
+		"a": "àáâãäåāăą", "c": "çćĉċč", "d": "ďđ", "e": "èéêëēĕėęě",
+		"g": "ĝğġģ", "h": "ĥħ", "i": "ìíîïĩīĭįı", "j": "ĵ", "k": "ķ",
+		"l": "ĺļľŀł", "n": "ñńņňŉ", "o": "òóôõöøōŏő", "r": "ŕŗř",
+		"s": "śŝşšș", "t": "ţťŧț", "u": "ùúûüũūŭůűų", "w": "ŵ",
+		"y": "ýÿŷ", "z": "źżž", "ae": "æ", "oe": "œ", "ss": "ß",
+	} {
+		for _, r := range accented {
+			diacriticFolds[r] = base
+		}
+	}
+}
+
+// foldName lowercases s, strips diacritics and combining marks, and
+// collapses runs of non-alphanumeric characters into single spaces.
+func foldName(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case diacriticFolds[r] != "":
+			b.WriteString(diacriticFolds[r])
+			space = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			space = false
+		default:
+			if !space && b.Len() > 0 {
+				b.WriteByte(' ')
+				space = true
+			}
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// FindVoiceByName ranks the voices in catalog whose display name matches
+// query, ignoring case and diacritics and tolerating typos. Exact matches
+// score 1, followed by prefix, word-prefix and substring matches, then
+// edit-distance similarity. Results are sorted best first.
+func FindVoiceByName(catalog []VoiceV2, query string) []VoiceMatch {
+	q := foldName(query)
+	if q == "" {
+		return nil
+	}
+	var matches []VoiceMatch
+	for _, voice := range catalog {
+		if score := nameScore(foldName(voice.VoiceName), q); score >= minVoiceMatchScore {
+			matches = append(matches, VoiceMatch{Voice: voice, Score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return foldName(matches[i].Voice.VoiceName) < foldName(matches[j].Voice.VoiceName)
+	})
+	return matches
+}
+
+func nameScore(name, query string) float64 {
+	switch {
+	case name == "":
+		return 0
+	case name == query:
+		return 1
+	case strings.HasPrefix(name, query):
+		return 0.9
+	case strings.Contains(" "+name, " "+query):
+		return 0.85
+	case strings.Contains(name, query):
+		return 0.8
+	}
+	best := similarity(name, query)
+	for _, word := range strings.Fields(name) {
+		if s := similarity(word, query); s > best {
+			best = s
+		}
+	}
+	return best * 0.75
+}
+
+// similarity is 1 minus the edit distance (counting adjacent
+// transpositions as one edit) normalized by the longer string's length.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	var prev2 []int
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = minInt(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev = prev, cur
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
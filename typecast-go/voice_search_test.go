@@ -0,0 +1,72 @@
+package typecast
+
+import "testing"
+
+func searchCatalog() []VoiceV2 {
+	names := []string{"José Martínez", "Jose", "Josephine", "Anna-Lena Grün", "Marie Joseph", "Ødegaard", "", "Chloé"}
+	catalog := make([]VoiceV2, len(names))
+	for i, name := range names {
+		catalog[i] = VoiceV2{VoiceID: "tc_" + name, VoiceName: name}
+	}
+	return catalog
+}
+
+func matchNames(matches []VoiceMatch) []string {
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.Voice.VoiceName
+	}
+	return names
+}
+
+func TestFindVoiceByName_RanksMatches(t *testing.T) {
+	matches := FindVoiceByName(searchCatalog(), "JOSE")
+	got := matchNames(matches)
+	want := []string{"Jose", "José Martínez", "Josephine", "Marie Joseph"}
+	if len(got) != len(want) {
+		t.Fatalf("matches = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("matches = %q, want %q", got, want)
+		}
+	}
+	if matches[0].Score != 1 || matches[1].Score != 0.9 || matches[3].Score != 0.85 {
+		t.Fatalf("unexpected scores: %+v", matches)
+	}
+}
+
+func TestFindVoiceByName_FoldsDiacriticsAndPunctuation(t *testing.T) {
+	cases := map[string]string{
+		"anna lena grun": "Anna-Lena Grün",
+		"odegaard":       "Ødegaard",
+		"Chloé":         "Chloé", // combining accent in the query
+		"martinez":       "José Martínez",
+		"lena":           "Anna-Lena Grün",
+	}
+	for query, want := range cases {
+		matches := FindVoiceByName(searchCatalog(), query)
+		if len(matches) == 0 || matches[0].Voice.VoiceName != want {
+			t.Errorf("FindVoiceByName(%q) = %q, want %q first", query, matchNames(matches), want)
+		}
+	}
+}
+
+func TestFindVoiceByName_ToleratesTypos(t *testing.T) {
+	matches := FindVoiceByName(searchCatalog(), "Josphine")
+	if len(matches) == 0 || matches[0].Voice.VoiceName != "Josephine" || matches[0].Score >= 0.8 {
+		t.Fatalf("unexpected matches: %+v", matches)
+	}
+	if matches := FindVoiceByName(searchCatalog(), "Chleo"); len(matches) == 0 || matches[0].Voice.VoiceName != "Chloé" {
+		t.Fatalf("expected transposition match, got %q", matchNames(matches))
+	}
+	if matches := FindVoiceByName(searchCatalog(), "ephin"); len(matches) == 0 || matches[0].Score != 0.8 {
+		t.Fatalf("expected substring match, got %+v", matches)
+	}
+	if matches := FindVoiceByName(searchCatalog(), "xyzzy"); len(matches) != 0 {
+		t.Fatalf("expected no matches, got %q", matchNames(matches))
+	}
+	if matches := FindVoiceByName(searchCatalog(), " -- "); matches != nil {
+		t.Fatalf("expected nil for an empty query, got %q", matchNames(matches))
+	}
+}
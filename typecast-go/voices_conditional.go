@@ -0,0 +1,126 @@
+package typecast
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Validators identify a version of a response, for conditional requests
+// that only transfer it again when it changed.
+type Validators struct {
+	// ETag is the response's ETag header
+	ETag string
+	// LastModified is the response's Last-Modified header
+	LastModified string
+}
+
+// header returns the If-None-Match and If-Modified-Since headers for v.
+func (v Validators) header() http.Header {
+	header := http.Header{}
+	if v.ETag != "" {
+		header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		header.Set("If-Modified-Since", v.LastModified)
+	}
+	return header
+}
+
+// validatorsOf returns the validators of resp, or since when resp has none,
+// as a 304 response may omit them.
+func validatorsOf(resp *http.Response, since Validators) Validators {
+	v := Validators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	if v.ETag == "" && v.LastModified == "" {
+		return since
+	}
+	return v
+}
+
+// ConditionalVoiceV2 is the result of GetVoiceV2Conditional.
+type ConditionalVoiceV2 struct {
+	// Voice is the voice, or nil when NotModified
+	Voice *VoiceV2
+	// NotModified reports that the voice is unchanged since the validators
+	// that were sent
+	NotModified bool
+	// Validators identify this version of the voice; send them with the
+	// next request
+	Validators Validators
+	// Stale reports that the API could not be reached and the voice is
+	// the last one cached by ResponseCache with StaleIfError
+	Stale bool
+}
+
+// ConditionalVoicesV2 is the result of GetVoicesV2Conditional.
+type ConditionalVoicesV2 struct {
+	// Voices are the voices, or nil when NotModified
+	Voices []VoiceV2
+	// NotModified reports that the catalog is unchanged since the
+	// validators that were sent
+	NotModified bool
+	// Validators identify this version of the catalog; send them with the
+	// next request
+	Validators Validators
+	// Stale reports that the API could not be reached and the catalog is
+	// the last one cached by ResponseCache with StaleIfError
+	Stale bool
+}
+
+// GetVoiceV2Conditional is GetVoiceV2 sent with If-None-Match and
+// If-Modified-Since from since. When the voice is unchanged, the result is
+// NotModified and carries no voice, so periodic refreshes stay cheap. Pass
+// zero Validators on the first request.
+func (c *Client) GetVoiceV2Conditional(ctx context.Context, voiceID string, since Validators) (*ConditionalVoiceV2, error) {
+	path := fmt.Sprintf("/v2/voices/%s", c.resolveVoiceID(voiceID))
+
+	resp, err := c.doRequestWithHeader(ctx, http.MethodGet, path, nil, since.header())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &ConditionalVoiceV2{Validators: validatorsOf(resp, since), Stale: isStale(resp)}
+	if resp.StatusCode == http.StatusNotModified {
+		result.NotModified = true
+		return result, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var voice VoiceV2
+	if err := decodeJSON(resp, "voice response", &voice); err != nil {
+		return nil, err
+	}
+	result.Voice = &voice
+	return result, nil
+}
+
+// GetVoicesV2Conditional is GetVoicesV2 sent with If-None-Match and
+// If-Modified-Since from since. When the catalog is unchanged, the result is
+// NotModified and carries no voices. Pass zero Validators on the first
+// request.
+func (c *Client) GetVoicesV2Conditional(ctx context.Context, filter *VoicesV2Filter, since Validators) (*ConditionalVoicesV2, error) {
+	var voices []VoiceV2
+	resp, err := c.eachVoiceV2(ctx, filter, since.header(), func(voice VoiceV2) error {
+		voices = append(voices, voice)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ConditionalVoicesV2{
+		Voices:      voices,
+		NotModified: resp.StatusCode == http.StatusNotModified,
+		Validators:  validatorsOf(resp, since),
+		Stale:       isStale(resp),
+	}, nil
+}
+
+// isStale reports whether resp is a stale response served by the
+// ResponseCache.
+func isStale(resp *http.Response) bool {
+	return strings.HasPrefix(resp.Header.Get("Warning"), "110 ")
+}
@@ -0,0 +1,109 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// conditionalServer serves body with an ETag, and 304 to requests that send
+// it back.
+func conditionalServer(t *testing.T, body string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.RawQuery, "gender=male&gender=female") {
+			w.WriteHeader(http.StatusBadRequest) // no repeated parameters
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` || r.Header.Get("If-Modified-Since") == "Mon, 05 Oct 2026 00:00:00 GMT" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 05 Oct 2026 00:00:00 GMT")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGetVoiceV2Conditional(t *testing.T) {
+	srv := conditionalServer(t, `{"voice_id":"tc_1","voice_name":"One"}`)
+	c := newTestClient(srv, "k")
+
+	first, err := c.GetVoiceV2Conditional(context.Background(), "tc_1", Validators{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.NotModified || first.Voice == nil || first.Voice.VoiceName != "One" || first.Validators.ETag != `"v1"` {
+		t.Fatalf("unexpected result %+v", first)
+	}
+
+	second, err := c.GetVoiceV2Conditional(context.Background(), "tc_1", first.Validators)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.NotModified || second.Voice != nil || second.Validators != first.Validators {
+		t.Fatalf("expected not modified, got %+v", second)
+	}
+
+	byDate, err := c.GetVoiceV2Conditional(context.Background(), "tc_1", Validators{LastModified: first.Validators.LastModified})
+	if err != nil || !byDate.NotModified {
+		t.Fatalf("expected not modified by date, got %+v, %v", byDate, err)
+	}
+
+	if voice, err := c.GetVoiceV2(context.Background(), "tc_1"); err != nil || voice.VoiceID != "tc_1" {
+		t.Fatalf("GetVoiceV2() = %+v, %v", voice, err)
+	}
+}
+
+func TestGetVoiceV2Conditional_Errors(t *testing.T) {
+	c := newTestClient(voicesServer(t, `{"voice_id":`), "k")
+	if _, err := c.GetVoiceV2Conditional(context.Background(), "tc_1", Validators{}); err == nil || !strings.Contains(err.Error(), "failed to decode voice response") {
+		t.Fatalf("expected a decode error, got %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"detail":"voice not found"}`))
+	}))
+	defer srv.Close()
+	var apiErr *APIError
+	if _, err := newTestClient(srv, "k").GetVoiceV2Conditional(context.Background(), "tc_1", Validators{}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected a 404 API error, got %v", err)
+	}
+
+	closed := newTestClient(srv, "k")
+	srv.Close()
+	if _, err := closed.GetVoiceV2Conditional(context.Background(), "tc_1", Validators{}); err == nil {
+		t.Fatal("expected a connection error")
+	}
+	if _, err := closed.GetVoicesV2Conditional(context.Background(), nil, Validators{}); err == nil {
+		t.Fatal("expected a connection error")
+	}
+}
+
+func TestGetVoicesV2Conditional(t *testing.T) {
+	srv := conditionalServer(t, `[{"voice_id":"a","gender":"male"},{"voice_id":"b","gender":"female"}]`)
+	c := newTestClient(srv, "k")
+
+	first, err := c.GetVoicesV2Conditional(context.Background(), nil, Validators{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.NotModified || len(first.Voices) != 2 || first.Validators.LastModified == "" {
+		t.Fatalf("unexpected result %+v", first)
+	}
+
+	// The validators are sent again when repeated parameters fall back.
+	filter := &VoicesV2Filter{Genders: []GenderEnum{GenderMale, GenderFemale}}
+	second, err := c.GetVoicesV2Conditional(context.Background(), filter, first.Validators)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.NotModified || second.Voices != nil || second.Validators != first.Validators {
+		t.Fatalf("expected not modified, got %+v", second)
+	}
+}
@@ -0,0 +1,138 @@
+package typecast
+
+import (
+	"net/http"
+	"strings"
+)
+
+// filterValues returns single followed by multi, without empty values or
+// duplicates.
+func filterValues(single string, multi []string) []string {
+	var values []string
+	seen := map[string]bool{}
+	for _, v := range append([]string{single}, multi...) {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// queryValues returns the values sent for each query parameter.
+func (f *VoicesV2Filter) queryValues() map[string][]string {
+	models := make([]string, len(f.Models))
+	for i, m := range f.Models {
+		models[i] = string(m)
+	}
+	genders := make([]string, len(f.Genders))
+	for i, g := range f.Genders {
+		genders[i] = string(g)
+	}
+	ages := make([]string, len(f.Ages))
+	for i, a := range f.Ages {
+		ages[i] = string(a)
+	}
+	useCases := make([]string, len(f.UseCasesAnyOf))
+	for i, u := range f.UseCasesAnyOf {
+		useCases[i] = string(u)
+	}
+	return map[string][]string{
+		"model":     filterValues(string(f.Model), models),
+		"gender":    filterValues(string(f.Gender), genders),
+		"age":       filterValues(string(f.Age), ages),
+		"use_cases": filterValues(string(f.UseCases), useCases),
+		"language":  filterValues(strings.ToLower(strings.TrimSpace(f.Language)), nil),
+	}
+}
+
+// clientSide reports whether voices must be checked with Matches after
+// decoding: for tag filters, which the API does not evaluate, and for
+// repeated parameters the API may not honor.
+func (f *VoicesV2Filter) clientSide() bool {
+	return f != nil && (len(f.Tags) > 0 || f.multiValued())
+}
+
+// multiValued reports whether any parameter has more than one value.
+func (f *VoicesV2Filter) multiValued() bool {
+	if f == nil {
+		return false
+	}
+	for _, values := range f.queryValues() {
+		if len(values) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// singleValued returns a copy of f keeping only parameters with one value,
+// for servers that reject repeated query parameters.
+func (f *VoicesV2Filter) singleValued() *VoicesV2Filter {
+	single := &VoicesV2Filter{Tags: f.Tags}
+	values := f.queryValues()
+	if v := values["model"]; len(v) == 1 {
+		single.Model = TTSModel(v[0])
+	}
+	if v := values["gender"]; len(v) == 1 {
+		single.Gender = GenderEnum(v[0])
+	}
+	if v := values["age"]; len(v) == 1 {
+		single.Age = AgeEnum(v[0])
+	}
+	if v := values["use_cases"]; len(v) == 1 {
+		single.UseCases = UseCaseEnum(v[0])
+	}
+	if v := values["language"]; len(v) == 1 {
+		single.Language = v[0]
+	}
+	return single
+}
+
+// Matches reports whether voice satisfies the filter. Within a parameter any
+// listed value matches; all parameters must match. Voices that do not list
+// their languages are assumed to match a Language filter.
+func (f *VoicesV2Filter) Matches(voice VoiceV2) bool {
+	if f == nil {
+		return true
+	}
+	values := f.queryValues()
+	if models := values["model"]; len(models) > 0 && !anyOf(models, func(m string) bool { return voice.SupportsModel(TTSModel(m)) }) {
+		return false
+	}
+	if genders := values["gender"]; len(genders) > 0 && (voice.Gender == nil || !anyOf(genders, func(g string) bool { return g == string(*voice.Gender) })) {
+		return false
+	}
+	if ages := values["age"]; len(ages) > 0 && (voice.Age == nil || !anyOf(ages, func(a string) bool { return a == string(*voice.Age) })) {
+		return false
+	}
+	if useCases := values["use_cases"]; len(useCases) > 0 && !anyOf(useCases, func(u string) bool {
+		return anyOf(voice.UseCases, func(v string) bool { return strings.EqualFold(u, v) })
+	}) {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !voice.HasTag(tag) {
+			return false
+		}
+	}
+	if languages := values["language"]; len(languages) > 0 && len(voice.Languages) > 0 && !voice.SupportsLanguage(languages[0]) {
+		return false
+	}
+	return true
+}
+
+func anyOf(values []string, match func(string) bool) bool {
+	for _, v := range values {
+		if match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectsRepeatedParams reports whether a voices response looks like the
+// API refusing repeated query parameters.
+func rejectsRepeatedParams(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnprocessableEntity
+}
@@ -0,0 +1,201 @@
+package typecast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const filterCatalog = `[
+	{"voice_id":"a","gender":"male","age":"elder","use_cases":["Audiobook"],"models":[{"version":"ssfm-v21"}]},
+	{"voice_id":"b","gender":"female","age":"child","use_cases":["Game","Anime"],"models":[{"version":"ssfm-v30"}]},
+	{"voice_id":"c","gender":"female","age":"elder","use_cases":["News"],"models":[{"version":"ssfm-v30"}]},
+	{"voice_id":"d","models":[{"version":"ssfm-v30"}]}
+]`
+
+func TestVoicesV2Path_RepeatsMultiValues(t *testing.T) {
+	path := voicesV2Path(&VoicesV2Filter{
+		Gender:        GenderMale,
+		Genders:       []GenderEnum{GenderFemale, GenderMale},
+		Ages:          []AgeEnum{AgeChild},
+		UseCasesAnyOf: []UseCaseEnum{UseCaseGame, UseCaseNews},
+		Models:        []TTSModel{ModelSSFMV30},
+	})
+	want := "/v2/voices?age=child&gender=male&gender=female&model=ssfm-v30&use_cases=Game&use_cases=News"
+	if path != want {
+		t.Fatalf("voicesV2Path() = %q, want %q", path, want)
+	}
+}
+
+func TestVoicesV2Filter_Matches(t *testing.T) {
+	male, elder := GenderMale, AgeElder
+	voice := VoiceV2{Gender: &male, Age: &elder, UseCases: []string{"audiobook"}, Models: []ModelInfo{{Version: ModelSSFMV21}}}
+	cases := []struct {
+		filter *VoicesV2Filter
+		want   bool
+	}{
+		{nil, true},
+		{&VoicesV2Filter{}, true},
+		{&VoicesV2Filter{Genders: []GenderEnum{GenderFemale, GenderMale}, Ages: []AgeEnum{AgeElder}}, true},
+		{&VoicesV2Filter{UseCasesAnyOf: []UseCaseEnum{UseCaseNews, UseCaseAudiobook}}, true},
+		{&VoicesV2Filter{Models: []TTSModel{ModelSSFMV30}}, false},
+		{&VoicesV2Filter{Genders: []GenderEnum{GenderFemale}}, false},
+		{&VoicesV2Filter{Ages: []AgeEnum{AgeChild, AgeTeenager}}, false},
+		{&VoicesV2Filter{UseCasesAnyOf: []UseCaseEnum{UseCaseGame}}, false},
+	}
+	for i, tc := range cases {
+		if got := tc.filter.Matches(voice); got != tc.want {
+			t.Errorf("case %d: Matches() = %v, want %v", i, got, tc.want)
+		}
+	}
+	if (&VoicesV2Filter{Gender: GenderMale}).Matches(VoiceV2{}) || (&VoicesV2Filter{Age: AgeElder}).Matches(VoiceV2{}) {
+		t.Fatal("voices without gender or age must not match those filters")
+	}
+}
+
+func TestGetVoicesV2_MultiValueFilterAcceptedByAPI(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		// A server that ignores repeats returns extra voices; they are filtered out.
+		_, _ = w.Write([]byte(filterCatalog))
+	}))
+	defer srv.Close()
+
+	voices, err := newTestClient(srv, "k").GetVoicesV2(context.Background(), &VoicesV2Filter{
+		Model: ModelSSFMV30,
+		Ages:  []AgeEnum{AgeChild, AgeElder},
+	})
+	if err != nil {
+		t.Fatalf("GetVoicesV2() error = %v", err)
+	}
+	if len(queries) != 1 || queries[0] != "age=child&age=elder&model=ssfm-v30" {
+		t.Fatalf("unexpected queries: %v", queries)
+	}
+	if got := voiceIDs(voices); got != "b,c" {
+		t.Fatalf("voices = %s", got)
+	}
+}
+
+func TestGetVoicesV2_MultiValueFilterFallsBackToClientSide(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if len(r.URL.Query()["gender"]) > 1 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"detail":"gender must be a single value"}`))
+			return
+		}
+		_, _ = w.Write([]byte(filterCatalog))
+	}))
+	defer srv.Close()
+
+	voices, err := newTestClient(srv, "k").GetVoicesV2(context.Background(), &VoicesV2Filter{
+		Genders:       []GenderEnum{GenderFemale, GenderMale},
+		UseCasesAnyOf: []UseCaseEnum{UseCaseGame},
+		Age:           AgeChild,
+	})
+	if err != nil {
+		t.Fatalf("GetVoicesV2() error = %v", err)
+	}
+	if len(queries) != 2 || queries[1] != "age=child&use_cases=Game" {
+		t.Fatalf("unexpected queries: %v", queries)
+	}
+	if got := voiceIDs(voices); got != "b" {
+		t.Fatalf("voices = %s", got)
+	}
+}
+
+func TestGetVoicesV2_FallbackRequestError(t *testing.T) {
+	calls := 0
+	c := NewClient(&ClientConfig{APIKey: "k", BaseURL: "http://x", HTTPClient: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return &http.Response{StatusCode: http.StatusBadRequest, Body: http.NoBody, Header: http.Header{}}, nil
+		}
+		return nil, errors.New("boom")
+	})}})
+	_, err := c.GetVoicesV2(context.Background(), &VoicesV2Filter{Ages: []AgeEnum{AgeChild, AgeElder}})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected fallback error, got %v", err)
+	}
+}
+
+func voiceIDs(voices []VoiceV2) string {
+	ids := make([]string, len(voices))
+	for i, v := range voices {
+		ids[i] = v.VoiceID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestVoicesV2Filter_SingleValued(t *testing.T) {
+	single := (&VoicesV2Filter{
+		Models:  []TTSModel{ModelSSFMV30},
+		Genders: []GenderEnum{GenderFemale},
+		Ages:    []AgeEnum{AgeChild, AgeElder},
+	}).singleValued()
+	if !reflect.DeepEqual(single, &VoicesV2Filter{Model: ModelSSFMV30, Gender: GenderFemale}) {
+		t.Fatalf("singleValued() = %+v", single)
+	}
+}
+
+func TestVoicesV2Filter_Language(t *testing.T) {
+	if path := voicesV2Path(&VoicesV2Filter{Language: " KOR "}); path != "/v2/voices?language=kor" {
+		t.Fatalf("voicesV2Path() = %q", path)
+	}
+	filter := &VoicesV2Filter{Language: "kor"}
+	if !filter.Matches(VoiceV2{Languages: []string{"kor"}}) || filter.Matches(VoiceV2{Languages: []string{"spa"}}) {
+		t.Fatal("unexpected language matches")
+	}
+	if !filter.Matches(VoiceV2{}) {
+		t.Fatal("voices without languages must match")
+	}
+	filter.Ages = []AgeEnum{AgeChild, AgeElder}
+	if single := filter.singleValued(); single.Language != "kor" || single.Age != "" {
+		t.Fatalf("singleValued() = %+v", single)
+	}
+}
+
+func TestVoicesV2Filter_Tags(t *testing.T) {
+	filter := &VoicesV2Filter{Tags: []string{"Warm", " calm "}}
+	if !filter.Matches(VoiceV2{Tags: []string{"warm", "calm", "narration"}}) {
+		t.Fatal("expected voice with both tags to match")
+	}
+	if filter.Matches(VoiceV2{Tags: []string{"warm"}}) || filter.Matches(VoiceV2{}) {
+		t.Fatal("voices missing a tag must not match")
+	}
+	if path := voicesV2Path(filter); path != "/v2/voices" {
+		t.Fatalf("tags must not be sent to the API, got %q", path)
+	}
+	if single := filter.singleValued(); !reflect.DeepEqual(single.Tags, filter.Tags) {
+		t.Fatalf("singleValued() dropped tags: %+v", single)
+	}
+}
+
+func TestGetVoicesV2_TagFilterAppliedClientSide(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"voice_id":"a","tags":["warm","authoritative"]},
+			{"voice_id":"b","tags":["bright"]},
+			{"voice_id":"c"}
+		]`))
+	}))
+	defer srv.Close()
+
+	voices, err := newTestClient(srv, "k").GetVoicesV2(context.Background(), &VoicesV2Filter{Tags: []string{"authoritative"}})
+	if err != nil {
+		t.Fatalf("GetVoicesV2() error = %v", err)
+	}
+	if ids := voiceIDs(voices); ids != "a" {
+		t.Fatalf("voices = %q, want a", ids)
+	}
+	if !reflect.DeepEqual(voices[0].Tags, []string{"warm", "authoritative"}) {
+		t.Fatalf("Tags = %v", voices[0].Tags)
+	}
+}
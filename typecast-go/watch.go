@@ -0,0 +1,264 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultWatchInterval is the time between polls of WatchConfig.InputDir.
+const defaultWatchInterval = 2 * time.Second
+
+// statusSuffix ends the name of a status sidecar file.
+const statusSuffix = ".status.json"
+
+// WatchConfig configures WatchFolder.
+type WatchConfig struct {
+	// InputDir is polled for .txt scripts and .json job files (required)
+	InputDir string
+	// OutputDir receives each input file's audio and a .status.json sidecar
+	// (required). It may be InputDir.
+	OutputDir string
+	// Profile holds the voice and synthesis settings of .txt scripts, and
+	// the defaults of .json job files (required)
+	Profile VoiceProfile
+	// MaxChunkChars caps the characters per request (optional, defaults to
+	// 2000)
+	MaxChunkChars int
+	// Interval is the time between polls (optional, defaults to 2s)
+	Interval time.Duration
+	// OnStatus is called after each status change (optional)
+	OnStatus func(WatchStatus)
+}
+
+// WatchJob is the content of a .json job file. Fields left empty fall back
+// to WatchConfig.Profile.
+type WatchJob struct {
+	// Text is the narration (required)
+	Text string `json:"text"`
+	// VoiceID is the voice identifier (optional)
+	VoiceID string `json:"voice_id,omitempty"`
+	// Model is the TTS model to use (optional)
+	Model TTSModel `json:"model,omitempty"`
+	// Language is the ISO 639-3 language code (optional)
+	Language string `json:"language,omitempty"`
+	// EmotionPreset is the emotion preset (optional)
+	EmotionPreset EmotionPreset `json:"emotion_preset,omitempty"`
+	// EmotionIntensity is the emotion strength, 0.0 to 2.0 (optional)
+	EmotionIntensity *float64 `json:"emotion_intensity,omitempty"`
+	// AudioTempo is the speech speed, 0.5 to 2.0 (optional)
+	AudioTempo *float64 `json:"audio_tempo,omitempty"`
+	// AudioPitch is the pitch in semitones, -12 to +12 (optional)
+	AudioPitch *int `json:"audio_pitch,omitempty"`
+	// AudioFormat is the output format, wav or mp3 (optional)
+	AudioFormat AudioFormat `json:"audio_format,omitempty"`
+}
+
+// WatchState is the state of a watched file.
+type WatchState string
+
+const (
+	// WatchProcessing means the file is being synthesized. A sidecar left in
+	// this state by a crash is processed again.
+	WatchProcessing WatchState = "processing"
+	// WatchDone means the audio was written.
+	WatchDone WatchState = "done"
+	// WatchFailed means synthesis failed; see WatchStatus.Error.
+	WatchFailed WatchState = "failed"
+)
+
+// WatchStatus is the content of a status sidecar file.
+type WatchStatus struct {
+	// Source is the input file's name
+	Source string `json:"source"`
+	// State is the processing state
+	State WatchState `json:"state"`
+	// Audio is the output file's name, once done
+	Audio string `json:"audio,omitempty"`
+	// Duration is the audio's duration in seconds, once done
+	Duration float64 `json:"duration,omitempty"`
+	// Segments is the number of requests made, once done
+	Segments int `json:"segments,omitempty"`
+	// Error describes the failure, if any
+	Error string `json:"error,omitempty"`
+	// SourceModified is the input file's modification time when it was
+	// processed; a file modified since is processed again
+	SourceModified time.Time `json:"source_modified"`
+	// Updated is when the status was written
+	Updated time.Time `json:"updated"`
+}
+
+// watchStamp identifies a version of an input file.
+type watchStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// WatchFolder polls cfg.InputDir for .txt scripts and .json job files and
+// synthesizes each with LongFormSynthesize into cfg.OutputDir as
+// <name>.wav or <name>.mp3, next to a <name>.status.json sidecar recording
+// its progress. A file is picked up once its size and modification time are
+// unchanged between two polls, so files still being copied are left alone,
+// and it is processed again when modified. A failed file is recorded in its
+// sidecar and does not stop the watch. WatchFolder runs until ctx is done or
+// a directory cannot be read or written.
+func (c *Client) WatchFolder(ctx context.Context, cfg WatchConfig) error {
+	if cfg.InputDir == "" {
+		return newValidationError("input_dir", "input_dir is required")
+	}
+	if cfg.OutputDir == "" {
+		return newValidationError("output_dir", "output_dir is required")
+	}
+	if err := cfg.Profile.Validate(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		return err
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	seen := map[string]watchStamp{}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.pollWatchFolder(ctx, cfg, seen); err != nil {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// pollWatchFolder processes the input files that are stable since the last
+// poll and have no up-to-date sidecar.
+func (c *Client) pollWatchFolder(ctx context.Context, cfg WatchConfig, seen map[string]watchStamp) error {
+	entries, err := os.ReadDir(cfg.InputDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := filepath.Ext(name)
+		if entry.IsDir() || (ext != ".txt" && ext != ".json") || strings.HasSuffix(name, statusSuffix) {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(cfg.InputDir, name))
+		if err != nil {
+			continue // removed since the directory was read
+		}
+		stamp := watchStamp{size: info.Size(), modTime: info.ModTime()}
+		if prev, ok := seen[name]; !ok || prev != stamp {
+			seen[name] = stamp // wait for the file to settle
+			continue
+		}
+		statusPath := filepath.Join(cfg.OutputDir, strings.TrimSuffix(name, ext)+statusSuffix)
+		if b, err := os.ReadFile(statusPath); err == nil {
+			var status WatchStatus
+			if json.Unmarshal(b, &status) == nil && status.State != WatchProcessing && status.SourceModified.Equal(stamp.modTime) {
+				continue
+			}
+		}
+		if err := c.processWatchFile(ctx, cfg, name, stamp.modTime, statusPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processWatchFile synthesizes one input file and records its status.
+func (c *Client) processWatchFile(ctx context.Context, cfg WatchConfig, name string, modTime time.Time, statusPath string) error {
+	status := WatchStatus{Source: name, State: WatchProcessing, SourceModified: modTime}
+	if err := writeWatchStatus(cfg, statusPath, status); err != nil {
+		return err
+	}
+	audio, result, err := c.synthesizeWatchFile(ctx, cfg, name)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err() // left processing, so it is retried on restart
+		}
+		status.State, status.Error = WatchFailed, err.Error()
+		return writeWatchStatus(cfg, statusPath, status)
+	}
+	if err := writeFileAtomic(filepath.Join(cfg.OutputDir, audio), result.AudioData); err != nil {
+		return err
+	}
+	status.State, status.Audio, status.Duration, status.Segments = WatchDone, audio, result.Duration, len(result.Segments)
+	return writeWatchStatus(cfg, statusPath, status)
+}
+
+// synthesizeWatchFile reads and synthesizes one input file. It returns the
+// name of the audio file to write and the result.
+func (c *Client) synthesizeWatchFile(ctx context.Context, cfg WatchConfig, name string) (string, *LongFormResult, error) {
+	b, err := os.ReadFile(filepath.Join(cfg.InputDir, name))
+	if err != nil {
+		return "", nil, err
+	}
+	profile, text := cfg.Profile, string(b)
+	if filepath.Ext(name) == ".json" {
+		var job WatchJob
+		if err := json.Unmarshal(b, &job); err != nil {
+			return "", nil, fmt.Errorf("invalid job file: %w", err)
+		}
+		profile, text = job.profile(profile), job.Text
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", nil, newValidationError("text", "text is required")
+	}
+	result, err := c.LongFormSynthesize(ctx, LongFormRequest{Profile: profile, Text: text, MaxChunkChars: cfg.MaxChunkChars})
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name)) + "." + string(result.Format), result, nil
+}
+
+// profile returns defaults overridden by the job's fields.
+func (j WatchJob) profile(defaults VoiceProfile) VoiceProfile {
+	p := defaults
+	if j.VoiceID != "" {
+		p.VoiceID = j.VoiceID
+	}
+	if j.Model != "" {
+		p.Model = j.Model
+	}
+	if j.Language != "" {
+		p.Language = j.Language
+	}
+	if j.EmotionPreset != "" {
+		p.EmotionPreset = j.EmotionPreset
+	}
+	if j.EmotionIntensity != nil {
+		p.EmotionIntensity = j.EmotionIntensity
+	}
+	if j.AudioTempo != nil {
+		p.AudioTempo = j.AudioTempo
+	}
+	if j.AudioPitch != nil {
+		p.AudioPitch = j.AudioPitch
+	}
+	if j.AudioFormat != "" {
+		p.AudioFormat = j.AudioFormat
+	}
+	return p
+}
+
+func writeWatchStatus(cfg WatchConfig, path string, status WatchStatus) error {
+	status.Updated = time.Now().UTC()
+	b, _ := json.MarshalIndent(status, "", "  ")
+	if err := writeFileAtomic(path, b); err != nil {
+		return err
+	}
+	if cfg.OnStatus != nil {
+		cfg.OnStatus(status)
+	}
+	return nil
+}
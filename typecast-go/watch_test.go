@@ -0,0 +1,190 @@
+package typecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readWatchStatus(t *testing.T, path string) WatchStatus {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var status WatchStatus
+	if err := json.Unmarshal(b, &status); err != nil {
+		t.Fatal(err)
+	}
+	return status
+}
+
+func TestPollWatchFolder(t *testing.T) {
+	srv, texts := countingTTSServer("fail.")
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	in, out := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		"a.txt":         "Chapter one.",
+		"b.json":        `{"text": "From a job.", "voice_id": "job-voice", "model": "ssfm-v21", "language": "eng", "emotion_preset": "happy", "emotion_intensity": 1.2, "audio_tempo": 1.1, "audio_pitch": 2, "audio_format": "wav"}`,
+		"c.json":        `{not json`,
+		"d.txt":         " \n",
+		"e.txt":         "fail.",
+		"notes.md":      "ignored",
+		"x.status.json": `{}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(in, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = os.Mkdir(filepath.Join(in, "sub.txt"), 0755)
+	_ = os.Symlink(filepath.Join(in, "missing"), filepath.Join(in, "broken.txt"))
+	_ = os.Symlink(t.TempDir(), filepath.Join(in, "dir.txt"))
+
+	var statuses []WatchStatus
+	cfg := WatchConfig{InputDir: in, OutputDir: out, Profile: VoiceProfile{VoiceID: "v"}, OnStatus: func(s WatchStatus) { statuses = append(statuses, s) }}
+	seen := map[string]watchStamp{}
+	if err := c.pollWatchFolder(context.Background(), cfg, seen); err != nil || len(statuses) != 0 {
+		t.Fatalf("first poll processed %d files: %v", len(statuses), err)
+	}
+	if err := c.pollWatchFolder(context.Background(), cfg, seen); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(texts(), "|"); got != "Chapter one.|From a job.|fail." {
+		t.Fatalf("unexpected requests %q", got)
+	}
+
+	a := readWatchStatus(t, filepath.Join(out, "a.status.json"))
+	if a.State != WatchDone || a.Audio != "a.wav" || a.Segments != 1 || a.Source != "a.txt" || a.Updated.IsZero() {
+		t.Fatalf("unexpected status %+v", a)
+	}
+	if _, err := os.Stat(filepath.Join(out, "a.wav")); err != nil {
+		t.Fatal(err)
+	}
+	failures := map[string]string{"c": "invalid job file", "d": "text is required", "e": "segment 0", "dir": "is a directory"}
+	for base, want := range failures {
+		status := readWatchStatus(t, filepath.Join(out, base+".status.json"))
+		if status.State != WatchFailed || !strings.Contains(status.Error, want) {
+			t.Fatalf("%s: unexpected status %+v", base, status)
+		}
+	}
+	if len(statuses) != 12 || statuses[0].State != WatchProcessing {
+		t.Fatalf("unexpected status changes %+v", statuses)
+	}
+
+	// Up-to-date sidecars are skipped; modified files and crashed jobs are
+	// processed again.
+	statuses = nil
+	if err := c.pollWatchFolder(context.Background(), cfg, seen); err != nil || len(statuses) != 0 {
+		t.Fatalf("processed %d files again: %v", len(statuses), err)
+	}
+	later := time.Now().Add(time.Minute)
+	_ = os.Chtimes(filepath.Join(in, "a.txt"), later, later)
+	b := readWatchStatus(t, filepath.Join(out, "b.status.json"))
+	b.State = WatchProcessing
+	data, _ := json.Marshal(b)
+	_ = os.WriteFile(filepath.Join(out, "b.status.json"), data, 0644)
+	_ = c.pollWatchFolder(context.Background(), cfg, seen)
+	_ = c.pollWatchFolder(context.Background(), cfg, seen)
+	if len(statuses) != 4 || statuses[1].Source != "b.json" || statuses[3].Source != "a.txt" || statuses[3].State != WatchDone {
+		t.Fatalf("unexpected status changes %+v", statuses)
+	}
+}
+
+func TestPollWatchFolder_Errors(t *testing.T) {
+	srv, _ := countingTTSServer("")
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	in := t.TempDir()
+	_ = os.WriteFile(filepath.Join(in, "a.txt"), []byte("Hello."), 0644)
+	settled := func() map[string]watchStamp {
+		info, _ := os.Stat(filepath.Join(in, "a.txt"))
+		return map[string]watchStamp{"a.txt": {size: info.Size(), modTime: info.ModTime()}}
+	}
+
+	// Missing directories.
+	if err := c.pollWatchFolder(context.Background(), WatchConfig{InputDir: filepath.Join(in, "missing")}, settled()); err == nil {
+		t.Fatal("expected an error for a missing input directory")
+	}
+	cfg := WatchConfig{InputDir: in, OutputDir: filepath.Join(in, "missing"), Profile: VoiceProfile{VoiceID: "v"}}
+	if err := c.pollWatchFolder(context.Background(), cfg, settled()); err == nil {
+		t.Fatal("expected an error for a missing output directory")
+	}
+
+	// The audio cannot be written over a directory.
+	cfg.OutputDir = t.TempDir()
+	_ = os.Mkdir(filepath.Join(cfg.OutputDir, "a.wav"), 0755)
+	if err := c.pollWatchFolder(context.Background(), cfg, settled()); err == nil {
+		t.Fatal("expected an error writing the audio")
+	}
+
+	// Canceled jobs stay processing.
+	cfg.OutputDir = t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.pollWatchFolder(ctx, cfg, settled()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if status := readWatchStatus(t, filepath.Join(cfg.OutputDir, "a.status.json")); status.State != WatchProcessing {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
+
+func TestWatchFolder(t *testing.T) {
+	srv, _ := countingTTSServer("")
+	defer srv.Close()
+	c := newTestClient(srv, "k")
+	in := t.TempDir()
+	out := filepath.Join(t.TempDir(), "audio")
+	_ = os.WriteFile(filepath.Join(in, "a.txt"), []byte("Hello."), 0644)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := c.WatchFolder(ctx, WatchConfig{
+		InputDir:  in,
+		OutputDir: out,
+		Profile:   VoiceProfile{VoiceID: "v"},
+		Interval:  time.Millisecond,
+		OnStatus: func(s WatchStatus) {
+			if s.State == WatchDone {
+				cancel()
+			}
+		},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "a.wav")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.WatchFolder(context.Background(), WatchConfig{InputDir: filepath.Join(in, "missing"), OutputDir: out, Profile: VoiceProfile{VoiceID: "v"}}); err == nil {
+		t.Fatal("expected an error for a missing input directory")
+	}
+}
+
+func TestWatchFolder_Validation(t *testing.T) {
+	c := NewClient(&ClientConfig{APIKey: "k"})
+	file := filepath.Join(t.TempDir(), "file")
+	_ = os.WriteFile(file, nil, 0644)
+	tests := []struct {
+		cfg  WatchConfig
+		want string
+	}{
+		{WatchConfig{}, "input_dir"},
+		{WatchConfig{InputDir: "in"}, "output_dir"},
+		{WatchConfig{InputDir: "in", OutputDir: "out"}, "voice_id"},
+		{WatchConfig{InputDir: "in", OutputDir: filepath.Join(file, "out"), Profile: VoiceProfile{VoiceID: "v"}}, "not a directory"},
+	}
+	for _, tt := range tests {
+		if err := c.WatchFolder(context.Background(), tt.cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("expected %q error, got %v", tt.want, err)
+		}
+	}
+}